@@ -0,0 +1,69 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alibaba/opensandbox/egress/pkg/log"
+	"github.com/alibaba/opensandbox/egress/pkg/mitmproxy"
+	"github.com/alibaba/opensandbox/egress/pkg/telemetry"
+	"github.com/alibaba/opensandbox/internal/safego"
+)
+
+// startAdminServer: unauthenticated GET /healthz (liveness), /readyz (503 until the whole stack is up)
+// and /metrics (Prometheus text) for kubelet probes and scrapers, apart from the policy API.
+func startAdminServer(addr string, gate *mitmproxy.HealthGate) (*http.Server, error) {
+	srv := &http.Server{Addr: addr, Handler: newAdminMux(gate), ReadHeaderTimeout: 5 * time.Second}
+	errCh := make(chan error, 1)
+	safego.Go(func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	})
+
+	select {
+	case err := <-errCh:
+		return nil, err
+	case <-time.After(200 * time.Millisecond):
+		safego.Go(func() {
+			if err := <-errCh; err != nil {
+				log.Errorf("admin server error: %v", err)
+			}
+		})
+		return srv, nil
+	}
+}
+
+func newAdminMux(gate *mitmproxy.HealthGate) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !gate.StackReady() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("egress stack not ready\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.Handle("/metrics", telemetry.MetricsHandler())
+	return mux
+}
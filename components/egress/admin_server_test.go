@@ -0,0 +1,41 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alibaba/opensandbox/egress/pkg/mitmproxy"
+)
+
+func TestAdminMux(t *testing.T) {
+	gate := mitmproxy.NewHealthGate()
+	mux := newAdminMux(gate)
+	get := func(path string) int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz"), "liveness does not wait for the stack")
+	assert.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+	gate.MarkStackReady()
+	assert.Equal(t, http.StatusOK, get("/readyz"))
+	assert.Equal(t, http.StatusNotFound, get("/policy"), "the policy API is not served on the admin port")
+}
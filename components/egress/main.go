@@ -36,7 +36,9 @@ import (
 	"github.com/alibaba/opensandbox/egress/pkg/policy"
 	"github.com/alibaba/opensandbox/egress/pkg/startup"
 	"github.com/alibaba/opensandbox/egress/pkg/telemetry"
+	"github.com/alibaba/opensandbox/internal/k8sauth"
 	slogger "github.com/alibaba/opensandbox/internal/logger"
+	"github.com/alibaba/opensandbox/internal/recording"
 	"github.com/alibaba/opensandbox/internal/safego"
 	"github.com/alibaba/opensandbox/internal/version"
 )
@@ -63,6 +65,14 @@ func main() {
 		}()
 	}
 
+	mitmGate := mitmproxy.NewHealthGate()
+	adminAddr := envOrDefault(constants.EnvEgressAdminAddr, constants.DefaultEgressAdminAddr)
+	adminSrv, err := startAdminServer(adminAddr, mitmGate)
+	if err != nil {
+		log.Fatalf("failed to start admin server: %v", err)
+	}
+	log.Infof("admin server listening on %s (GET /healthz, /readyz, /metrics)", adminAddr)
+
 	initialRules, _, err := policy.LoadInitialPolicyDetailed(os.Getenv(constants.EnvEgressPolicyFile), constants.EnvEgressRules)
 	if err != nil {
 		log.Fatalf("failed to load initial egress policy: %v", err)
@@ -82,10 +92,41 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to init dns proxy: %v", err)
 	}
+	telemetry.SetRuleStatsSource(proxy.RuleStats)
+	extraListen, err := dnsproxy.ExtraListenAddrsFromEnv()
+	if err != nil {
+		log.Fatalf("invalid dns listen addresses: %v", err)
+	}
+	proxy.SetExtraListenAddrs(extraListen)
+	identities, err := dnsproxy.LoadIdentities(os.Getenv(constants.EnvEgressIdentitiesFile))
+	if err != nil {
+		log.Fatalf("failed to load egress identities: %v", err)
+	}
+	if len(identities) > 0 {
+		if err := proxy.SetIdentities(identities); err != nil {
+			log.Fatalf("failed to apply egress identities: %v", err)
+		}
+		log.Infof("multi-policy mode enabled with %d identities: %v", len(identities), proxy.IdentityNames())
+	}
+	if prefix := dnsproxy.DNS64PrefixFromEnv(); prefix.IsValid() {
+		proxy.SetDNS64Prefix(prefix)
+		log.Infof("DNS64 enabled with NAT64 prefix %s", prefix)
+	}
+	recorder, err := recording.FromEnv("egress")
+	if err != nil {
+		log.Fatalf("failed to init session recording: %v", err)
+	}
+	if recorder != nil {
+		proxy.SetRecorder(recorder)
+		log.Infof("session recording enabled")
+	}
 	if err := proxy.Start(ctx); err != nil {
 		log.Fatalf("failed to start dns proxy: %v", err)
 	}
 	log.Infof("dns proxy started on 127.0.0.1:15353")
+	if len(extraListen) > 0 {
+		log.Infof("dns proxy also listening on %v", extraListen)
+	}
 
 	if blockWebhookURL := strings.TrimSpace(os.Getenv(constants.EnvBlockedWebhook)); blockWebhookURL != "" {
 		blockedBroadcaster := events.NewBroadcaster(ctx, events.BroadcasterConfig{QueueSize: 256})
@@ -107,8 +148,11 @@ func main() {
 	setupNft(ctx, nftMgr, initialRules, proxy, allowIPs, alwaysDeny, alwaysAllow)
 
 	httpAddr := envOrDefault(constants.EnvEgressHTTPAddr, constants.DefaultEgressServerAddr)
-	mitmGate := mitmproxy.NewHealthGate()
-	policySrv, err := startPolicyServer(proxy, nftMgr, mode, httpAddr, os.Getenv(constants.EnvEgressToken), allowIPs, os.Getenv(constants.EnvEgressPolicyFile), alwaysDeny, alwaysAllow, mitmGate)
+	auth, err := k8sauth.New(k8sauth.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("failed to set up service account token auth: %v", err)
+	}
+	policySrv, err := startPolicyServer(proxy, nftMgr, mode, httpAddr, os.Getenv(constants.EnvEgressToken), auth, allowIPs, os.Getenv(constants.EnvEgressPolicyFile), alwaysDeny, alwaysAllow, mitmGate)
 	if err != nil {
 		log.Fatalf("failed to start policy server: %v", err)
 	}
@@ -124,15 +168,15 @@ func main() {
 		log.Errorf("startup hooks (post) error: %v", err)
 	}
 
-	waitForShutdown(ctx, proxy, policySrv, exemptDst, nftMgr, mitm)
+	waitForShutdown(ctx, proxy, policySrv, adminSrv, exemptDst, nftMgr, mitm)
 }
 
 func withLogger(ctx context.Context) context.Context {
 	level := envOrDefault(constants.EnvEgressLogLevel, "info")
 	cfg := slogger.Config{Level: level}
 	base := slogger.MustNew(cfg)
-	// Baseline log fields (e.g. sandbox_id, OPENSANDBOX_EGRESS_METRICS_EXTRA_ATTRS) for every line.
-	if extra := telemetry.EgressLogFields(); len(extra) > 0 {
+	// Baseline log fields (pod, sandbox_id, OPENSANDBOX_EGRESS_METRICS_EXTRA_ATTRS) for every line.
+	if extra := append(slogger.PodFields(), telemetry.EgressLogFields()...); len(extra) > 0 {
 		base = base.With(extra...)
 	}
 	logger := base.Named("opensandbox.egress")
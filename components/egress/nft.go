@@ -40,6 +40,9 @@ func createNftManager(mode string) nftApplier {
 func setupNft(ctx context.Context, nftMgr nftApplier, initialPolicy *policy.NetworkPolicy, proxy *dnsproxy.Proxy, nameserverIPs []netip.Addr, alwaysDeny, alwaysAllow []policy.EgressRule) {
 	if nftMgr == nil {
 		log.Warnf("nftables disabled (dns-only mode)")
+		if initialPolicy.BlocksUDP() {
+			log.Warnf("udp.block in policy is not enforced without dns+nft mode")
+		}
 		return
 	}
 
@@ -58,7 +61,7 @@ func setupNft(ctx context.Context, nftMgr nftApplier, initialPolicy *policy.Netw
 }
 
 func parseNftOptions() nftables.Options {
-	opts := nftables.Options{BlockDoT: true}
+	opts := nftables.Options{BlockDoT: true, DNS64Prefix: dnsproxy.DNS64PrefixFromEnv()}
 	if constants.IsTruthy(os.Getenv(constants.EnvBlockDoH443)) {
 		opts.BlockDoH443 = true
 	}
@@ -25,6 +25,7 @@ const (
 	EnvDoHBlocklist            = "OPENSANDBOX_EGRESS_DOH_BLOCKLIST"
 	EnvEgressMode              = "OPENSANDBOX_EGRESS_MODE"
 	EnvEgressHTTPAddr          = "OPENSANDBOX_EGRESS_HTTP_ADDR"
+	EnvEgressAdminAddr         = "OPENSANDBOX_EGRESS_ADMIN_ADDR"
 	EnvEgressToken             = "OPENSANDBOX_EGRESS_TOKEN"
 	EnvEgressRules             = "OPENSANDBOX_EGRESS_RULES"
 	EnvEgressPolicyFile        = "OPENSANDBOX_EGRESS_POLICY_FILE"
@@ -48,6 +49,12 @@ const (
 	EnvDNSUpstreamTimeout          = "OPENSANDBOX_EGRESS_DNS_UPSTREAM_TIMEOUT"
 	EnvDNSUpstreamProbe            = "OPENSANDBOX_EGRESS_DNS_UPSTREAM_PROBE"
 	EnvDNSUpstreamProbeIntervalSec = "OPENSANDBOX_EGRESS_DNS_UPSTREAM_PROBE_INTERVAL_SEC"
+	// NAT64 prefix (IPv6 /96, or "wkp" for 64:ff9b::/96) for DNS64 synthesis in IPv6-only clusters.
+	EnvDNS64Prefix = "OPENSANDBOX_EGRESS_DNS64_PREFIX"
+	// Comma-separated extra DNS listen addresses (literal IP, optional :port, default 53), e.g. the pod IP.
+	EnvDNSListen = "OPENSANDBOX_EGRESS_DNS_LISTEN"
+	// JSON file of identities for multi-policy mode (one policy per container identity).
+	EnvEgressIdentitiesFile = "OPENSANDBOX_EGRESS_IDENTITIES_FILE"
 )
 
 const (
@@ -58,6 +65,7 @@ const (
 const (
 	DefaultEgressServerAddr      = ":18080"
 	DefaultMitmproxyPort         = 18081
+	DefaultEgressAdminAddr       = ":18082"
 	ResolvNameserverCap          = 10
 	DefaultMaxEgressRules        = 4096
 	DefaultDNSUpstreamTimeoutSec = 5
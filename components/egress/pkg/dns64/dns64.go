@@ -0,0 +1,75 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dns64 implements the address mapping of DNS64/NAT64 (RFC 6052, RFC 6147): in IPv6-only
+// clusters, IPv4 destinations are reached through a NAT64 gateway at IPv6 addresses that embed the IPv4
+// address in a NAT64 prefix. Only /96 prefixes (e.g. the well-known 64:ff9b::/96) are supported; they are
+// what NAT64 gateways use in practice and keep IPv4 CIDRs contiguous once mapped.
+package dns64
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// WellKnownPrefix is the RFC 6052 well-known NAT64 prefix.
+var WellKnownPrefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// ipv4MappedPrefix holds IPv4-mapped IPv6 addresses, which RFC 6147 excludes from AAAA answers.
+var ipv4MappedPrefix = netip.MustParsePrefix("::ffff:0:0/96")
+
+// ParsePrefix parses a NAT64 prefix; "wkp" selects WellKnownPrefix.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "wkp") {
+		return WellKnownPrefix, nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid DNS64 prefix %q: %w", s, err)
+	}
+	if !p.Addr().Is6() || p.Addr().Is4In6() || p.Bits() != 96 {
+		return netip.Prefix{}, fmt.Errorf("invalid DNS64 prefix %q: must be an IPv6 /96 prefix", s)
+	}
+	return p.Masked(), nil
+}
+
+// Synthesize embeds an IPv4 address in the prefix.
+func Synthesize(prefix netip.Prefix, v4 netip.Addr) netip.Addr {
+	b := prefix.Addr().As16()
+	v := v4.As4()
+	copy(b[12:], v[:])
+	return netip.AddrFrom16(b)
+}
+
+// Extract returns the IPv4 address embedded in addr if addr is inside the prefix.
+func Extract(prefix netip.Prefix, addr netip.Addr) (netip.Addr, bool) {
+	if !addr.Is6() || !prefix.Contains(addr) {
+		return netip.Addr{}, false
+	}
+	b := addr.As16()
+	return netip.AddrFrom4([4]byte(b[12:])), true
+}
+
+// MapPrefix maps an IPv4 CIDR onto the IPv6 addresses its hosts have behind NAT64.
+func MapPrefix(prefix netip.Prefix, v4 netip.Prefix) netip.Prefix {
+	return netip.PrefixFrom(Synthesize(prefix, v4.Masked().Addr()), 96+v4.Bits())
+}
+
+// Excluded reports whether an AAAA answer must be ignored when deciding whether to synthesize: RFC 6147
+// treats IPv4-mapped addresses like the absence of AAAA records.
+func Excluded(addr netip.Addr) bool {
+	return ipv4MappedPrefix.Contains(addr)
+}
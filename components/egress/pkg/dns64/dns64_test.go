@@ -0,0 +1,63 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns64
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrefix(t *testing.T) {
+	p, err := ParsePrefix("wkp")
+	require.NoError(t, err)
+	require.Equal(t, WellKnownPrefix, p)
+
+	p, err = ParsePrefix(" 2001:db8:64::1/96 ")
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8:64::/96", p.String())
+
+	for _, bad := range []string{"", "64:ff9b::", "64:ff9b::/64", "10.0.0.0/8", "::ffff:0:0/96"} {
+		_, err := ParsePrefix(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestSynthesizeAndExtract(t *testing.T) {
+	v4 := netip.MustParseAddr("192.0.2.33")
+	v6 := Synthesize(WellKnownPrefix, v4)
+	require.Equal(t, "64:ff9b::c000:221", v6.String())
+
+	got, ok := Extract(WellKnownPrefix, v6)
+	require.True(t, ok)
+	require.Equal(t, v4, got)
+
+	_, ok = Extract(WellKnownPrefix, netip.MustParseAddr("2001:db8::c000:221"))
+	require.False(t, ok)
+	_, ok = Extract(WellKnownPrefix, v4)
+	require.False(t, ok)
+}
+
+func TestMapPrefix(t *testing.T) {
+	require.Equal(t, "64:ff9b::a00:0/104", MapPrefix(WellKnownPrefix, netip.MustParsePrefix("10.0.0.0/8")).String())
+	require.Equal(t, "64:ff9b::102:304/128", MapPrefix(WellKnownPrefix, netip.MustParsePrefix("1.2.3.4/32")).String())
+	require.Equal(t, "64:ff9b::c000:200/120", MapPrefix(WellKnownPrefix, netip.MustParsePrefix("192.0.2.7/24")).String())
+}
+
+func TestExcluded(t *testing.T) {
+	require.True(t, Excluded(netip.MustParseAddr("::ffff:192.0.2.1")))
+	require.False(t, Excluded(netip.MustParseAddr("2001:db8::1")))
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnsproxy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot is the procfs mount read for socket attribution (overridden in tests).
+var procRoot = "/proc"
+
+const tcpListenState = "0A"
+
+// socketOwner finds the client socket of a query from the pod's network namespace in the procfs socket
+// tables by its source address and port, and returns the owner UID and socket inode. It sees all sockets
+// of the network namespace, whichever container opened them.
+func socketOwner(network string, src netip.AddrPort) (uid uint32, inode uint64, ok bool) {
+	tables := []string{"udp", "udp6"}
+	if network == "tcp" {
+		tables = []string{"tcp", "tcp6"}
+	}
+	for _, table := range tables {
+		f, err := os.Open(filepath.Join(procRoot, "net", table))
+		if err != nil {
+			continue
+		}
+		uid, inode, ok = findSocket(f, network == "tcp", src)
+		_ = f.Close()
+		if ok {
+			return uid, inode, true
+		}
+	}
+	return 0, 0, false
+}
+
+// findSocket scans one /proc/net/{udp,tcp}[6] table for the socket bound to src; a socket bound to the
+// wildcard address matches any source address with its port.
+func findSocket(f *os.File, tcp bool, src netip.AddrPort) (uint32, uint64, bool) {
+	want := src.Addr().Unmap()
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		if tcp && fields[3] == tcpListenState {
+			continue
+		}
+		local, err := parseProcSocketAddr(fields[1])
+		if err != nil || local.Port() != src.Port() {
+			continue
+		}
+		if addr := local.Addr().Unmap(); !addr.IsUnspecified() && addr != want {
+			continue
+		}
+		uid, err := strconv.ParseUint(fields[7], 10, 32)
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		return uint32(uid), inode, true
+	}
+	return 0, 0, false
+}
+
+// parseProcSocketAddr decodes "0100007F:0035": the address is hex in 32-bit words of host byte order,
+// the port is big-endian hex.
+func parseProcSocketAddr(s string) (netip.AddrPort, error) {
+	host, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return netip.AddrPort{}, fmt.Errorf("invalid socket address %q", s)
+	}
+	raw, err := hex.DecodeString(host)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return netip.AddrPort{}, fmt.Errorf("invalid socket address %q", s)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(raw[i:], binary.NativeEndian.Uint32(raw[i:]))
+	}
+	p, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid socket port %q", s)
+	}
+	addr, _ := netip.AddrFromSlice(raw)
+	return netip.AddrPortFrom(addr, uint16(p)), nil
+}
+
+// socketCgroup returns the cgroup of a process holding the socket inode, scanning the fds of every
+// process in procfs; "" if none is visible (e.g. without a shared PID namespace).
+func socketCgroup(inode uint64) string {
+	target := "socket:[" + strconv.FormatUint(inode, 10) + "]"
+	procs, err := os.ReadDir(procRoot)
+	if err != nil {
+		return ""
+	}
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && link == target {
+				data, err := os.ReadFile(filepath.Join(procRoot, proc.Name(), "cgroup"))
+				if err != nil {
+					return ""
+				}
+				return strings.TrimSpace(string(data))
+			}
+		}
+	}
+	return ""
+}
+
+// querySourceOf describes the client of a query; local is true for the listener that iptables redirects
+// the pod's own DNS traffic to.
+func (p *Proxy) querySourceOf(remote net.Addr, local bool) querySource {
+	var src netip.AddrPort
+	switch a := remote.(type) {
+	case *net.UDPAddr:
+		src = a.AddrPort()
+	case *net.TCPAddr:
+		src = a.AddrPort()
+	}
+	qs := querySource{addr: src.Addr().Unmap(), local: local}
+	if !local || !p.attributeOwner || !src.IsValid() {
+		return qs
+	}
+	network := "udp"
+	if _, ok := remote.(*net.TCPAddr); ok {
+		network = "tcp"
+	}
+	uid, inode, ok := socketOwner(network, src)
+	if !ok {
+		return qs
+	}
+	qs.uid, qs.owned = uid, true
+	if p.attributeCgroup {
+		qs.cgroup = socketCgroup(inode)
+	}
+	return qs
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnsproxy
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/alibaba/opensandbox/egress/pkg/constants"
+	"github.com/alibaba/opensandbox/egress/pkg/dns64"
+	"github.com/alibaba/opensandbox/egress/pkg/log"
+)
+
+// DNS64PrefixFromEnv returns the NAT64 prefix from OPENSANDBOX_EGRESS_DNS64_PREFIX; the zero Prefix
+// (DNS64 disabled) if unset or invalid.
+func DNS64PrefixFromEnv() netip.Prefix {
+	raw := os.Getenv(constants.EnvDNS64Prefix)
+	if strings.TrimSpace(raw) == "" {
+		return netip.Prefix{}
+	}
+	prefix, err := dns64.ParsePrefix(raw)
+	if err != nil {
+		log.Warnf("[dns] %s: %v; DNS64 disabled", constants.EnvDNS64Prefix, err)
+		return netip.Prefix{}
+	}
+	return prefix
+}
+
+// synthesizeDNS64 answers an allowed AAAA query for a name without usable AAAA records with the NAT64
+// addresses of its A records (RFC 6147). The query name has already passed policy, and the synthesized
+// answer goes through maybeNotifyResolved like any other, so its addresses are pinned in the dynamic
+// IPv6 allow set. Upstream answers are returned unchanged when they already carry AAAA records, including
+// ones synthesized by an upstream DNS64 resolver.
+func (p *Proxy) synthesizeDNS64(r, resp *dns.Msg) *dns.Msg {
+	if resp.Rcode != dns.RcodeSuccess || hasUsableAAAA(resp) {
+		return resp
+	}
+	aReq := r.Copy()
+	aReq.Id = dns.Id()
+	aReq.Question[0].Qtype = dns.TypeA
+	aResp, err := p.forward(aReq)
+	if err != nil || aResp.Rcode != dns.RcodeSuccess {
+		return resp
+	}
+
+	// RFC 6147 5.1.7: the TTL is capped by the negative caching TTL of the AAAA answer.
+	ttlCap, capped := negativeTTL(resp)
+	out := new(dns.Msg)
+	out.SetReply(r)
+	out.RecursionAvailable = aResp.RecursionAvailable
+	synthesized := 0
+	for _, rr := range aResp.Answer {
+		switch v := rr.(type) {
+		case *dns.CNAME, *dns.DNAME:
+			out.Answer = append(out.Answer, dns.Copy(rr))
+		case *dns.A:
+			v4, ok := netip.AddrFromSlice(v.A.To4())
+			if !ok {
+				continue
+			}
+			hdr := v.Hdr
+			hdr.Rrtype = dns.TypeAAAA
+			if capped && hdr.Ttl > ttlCap {
+				hdr.Ttl = ttlCap
+			}
+			addr := dns64.Synthesize(p.dns64Prefix, v4)
+			out.Answer = append(out.Answer, &dns.AAAA{Hdr: hdr, AAAA: net.IP(addr.AsSlice())})
+			synthesized++
+		}
+	}
+	if synthesized == 0 {
+		return resp
+	}
+	return out
+}
+
+func hasUsableAAAA(resp *dns.Msg) bool {
+	for _, rr := range resp.Answer {
+		v, ok := rr.(*dns.AAAA)
+		if !ok {
+			continue
+		}
+		if addr, ok := netip.AddrFromSlice(v.AAAA); ok && !dns64.Excluded(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func negativeTTL(resp *dns.Msg) (uint32, bool) {
+	for _, rr := range resp.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return min(soa.Hdr.Ttl, soa.Minttl), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnsproxy
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/opensandbox/egress/pkg/constants"
+	"github.com/alibaba/opensandbox/egress/pkg/dns64"
+	"github.com/alibaba/opensandbox/egress/pkg/nftables"
+	"github.com/alibaba/opensandbox/egress/pkg/policy"
+)
+
+// recordingWriter captures the message written by serveDNS.
+type recordingWriter struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (w *recordingWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+// startDNS64Upstream serves an IPv4-only name, a dual-stack name and a name answered with an IPv4-mapped AAAA.
+func startDNS64Upstream(t *testing.T) string {
+	t.Helper()
+	rr := func(s string) dns.RR {
+		r, err := dns.NewRR(s)
+		require.NoError(t, err)
+		return r
+	}
+	answers := map[string][]dns.RR{
+		"v4only.test./A":    {rr("v4only.test. 300 IN CNAME edge.v4only.test."), rr("edge.v4only.test. 300 IN A 192.0.2.1")},
+		"dual.test./AAAA":   {rr("dual.test. 60 IN AAAA 2001:db8::1")},
+		"dual.test./A":      {rr("dual.test. 60 IN A 192.0.2.2")},
+		"mapped.test./AAAA": {rr("mapped.test. 60 IN AAAA ::ffff:192.0.2.9")},
+		"mapped.test./A":    {rr("mapped.test. 60 IN A 192.0.2.9")},
+	}
+	soa := rr("test. 3600 IN SOA ns.test. admin.test. 1 3600 600 86400 30")
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		q := r.Question[0]
+		resp := new(dns.Msg)
+		resp.SetReply(r)
+		if q.Name == "missing.test." {
+			resp.Rcode = dns.RcodeNameError
+		} else if ans, ok := answers[q.Name+"/"+dns.TypeToString[q.Qtype]]; ok {
+			resp.Answer = ans
+		} else {
+			resp.Ns = []dns.RR{soa}
+		}
+		_ = w.WriteMsg(resp)
+	})}
+	go func() { _ = srv.ActivateAndServe() }()
+	t.Cleanup(func() { _ = srv.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+func TestProxyDNS64(t *testing.T) {
+	// no SO_MARK on the test upstream
+	t.Setenv(constants.EnvNameserverExempt, "127.0.0.1")
+	resetNameserverExemptCache(t)
+	t.Cleanup(func() { resetNameserverExemptCache(t) })
+
+	upstream := startDNS64Upstream(t)
+	pol, err := policy.ParsePolicy(`{"defaultAction":"allow"}`)
+	require.NoError(t, err)
+	var pinned []nftables.ResolvedIP
+	proxy := &Proxy{
+		upstreams:               []string{upstream},
+		upstreamExchangeTimeout: 2 * time.Second,
+		userPolicy:              pol,
+		effectivePolicy:         pol,
+		onResolved:              func(_ string, ips []nftables.ResolvedIP) { pinned = append(pinned, ips...) },
+	}
+	proxy.SetDNS64Prefix(dns64.WellKnownPrefix)
+
+	query := func(name string, qtype uint16) *dns.Msg {
+		pinned = nil
+		req := new(dns.Msg)
+		req.SetQuestion(name, qtype)
+		w := &recordingWriter{}
+		proxy.serveDNS(w, req)
+		require.NotNil(t, w.msg)
+		return w.msg
+	}
+
+	t.Run("synthesizes AAAA for IPv4-only names", func(t *testing.T) {
+		resp := query("v4only.test.", dns.TypeAAAA)
+		require.Equal(t, dns.RcodeSuccess, resp.Rcode)
+		require.Len(t, resp.Answer, 2)
+		require.IsType(t, &dns.CNAME{}, resp.Answer[0])
+		aaaa := resp.Answer[1].(*dns.AAAA)
+		require.Equal(t, "edge.v4only.test.", aaaa.Hdr.Name)
+		require.Equal(t, "64:ff9b::c000:201", aaaa.AAAA.String())
+		// capped by the SOA minimum of the empty AAAA answer
+		require.Equal(t, uint32(30), aaaa.Hdr.Ttl)
+		require.Equal(t, []nftables.ResolvedIP{{Addr: netip.MustParseAddr("64:ff9b::c000:201"), TTL: 30 * time.Second}}, pinned)
+	})
+
+	t.Run("keeps native AAAA", func(t *testing.T) {
+		resp := query("dual.test.", dns.TypeAAAA)
+		require.Len(t, resp.Answer, 1)
+		require.Equal(t, "2001:db8::1", resp.Answer[0].(*dns.AAAA).AAAA.String())
+	})
+
+	t.Run("ignores IPv4-mapped AAAA", func(t *testing.T) {
+		resp := query("mapped.test.", dns.TypeAAAA)
+		require.Len(t, resp.Answer, 1)
+		require.Equal(t, "64:ff9b::c000:209", resp.Answer[0].(*dns.AAAA).AAAA.String())
+	})
+
+	t.Run("passes through NXDOMAIN and A queries", func(t *testing.T) {
+		require.Equal(t, dns.RcodeNameError, query("missing.test.", dns.TypeAAAA).Rcode)
+		resp := query("dual.test.", dns.TypeA)
+		require.Len(t, resp.Answer, 1)
+		require.IsType(t, &dns.A{}, resp.Answer[0])
+	})
+
+	t.Run("denied names are not synthesized", func(t *testing.T) {
+		deny, err := policy.ParsePolicy(`{"defaultAction":"allow","egress":[{"action":"deny","target":"v4only.test"}]}`)
+		require.NoError(t, err)
+		proxy.UpdatePolicy(deny)
+		t.Cleanup(func() { proxy.UpdatePolicy(pol) })
+		resp := query("v4only.test.", dns.TypeAAAA)
+		require.Equal(t, dns.RcodeNameError, resp.Rcode)
+		require.Empty(t, pinned)
+	})
+}
+
+func TestDNS64PrefixFromEnv(t *testing.T) {
+	t.Setenv(constants.EnvDNS64Prefix, "")
+	require.False(t, DNS64PrefixFromEnv().IsValid())
+
+	t.Setenv(constants.EnvDNS64Prefix, "wkp")
+	require.Equal(t, dns64.WellKnownPrefix, DNS64PrefixFromEnv())
+
+	t.Setenv(constants.EnvDNS64Prefix, "64:ff9b::/64")
+	require.False(t, DNS64PrefixFromEnv().IsValid())
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnsproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alibaba/opensandbox/egress/pkg/constants"
+	"github.com/alibaba/opensandbox/egress/pkg/policy"
+)
+
+// Identity is a container (or remote client) of the pod with its own policy (multi-policy mode). Queries
+// from the pod's network namespace are attributed by the owner of the client socket (UIDs, Cgroups); queries
+// on the additional listeners by client address (Sources). The first matching identity wins; unattributed
+// queries use the pod policy.
+type Identity struct {
+	Name string `json:"name"`
+	// Sources are client IPs/CIDRs of queries arriving on the additional listeners (other network namespaces).
+	Sources []string `json:"sources,omitempty"`
+	// UIDs match the owner of the client socket, e.g. the runAsUser of a container.
+	UIDs []uint32 `json:"uids,omitempty"`
+	// Cgroups match a substring of the cgroup of the process owning the client socket, e.g. a container ID.
+	Cgroups []string `json:"cgroups,omitempty"`
+	// Policy is the initial policy of the identity; default deny-all when unset.
+	Policy json.RawMessage `json:"policy,omitempty"`
+
+	sources []netip.Prefix
+}
+
+// ExtraListenAddrsFromEnv parses OPENSANDBOX_EGRESS_DNS_LISTEN: comma-separated literal IPs with an
+// optional port (default 53).
+func ExtraListenAddrsFromEnv() ([]string, error) {
+	var out []string
+	for _, part := range strings.Split(os.Getenv(constants.EnvDNSListen), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		addr, err := normalizeEnvUpstreamAddr(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", constants.EnvDNSListen, err)
+		}
+		out = append(out, addr)
+	}
+	return out, nil
+}
+
+// querySource is what is known about the client of a query.
+type querySource struct {
+	addr   netip.Addr
+	local  bool   // from the pod's network namespace (the redirected listener)
+	uid    uint32 // owner of the client socket, valid with owned
+	owned  bool
+	cgroup string // cgroup of the process owning the client socket; "" when unknown
+}
+
+func (id *Identity) matches(src querySource) bool {
+	if !src.local {
+		for _, p := range id.sources {
+			if p.Contains(src.addr) {
+				return true
+			}
+		}
+		return false
+	}
+	if src.owned && slices.Contains(id.UIDs, src.uid) {
+		return true
+	}
+	if src.cgroup != "" {
+		for _, c := range id.Cgroups {
+			if strings.Contains(src.cgroup, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadIdentities reads the identities of multi-policy mode from a JSON array; "" → none.
+func LoadIdentities(path string) ([]Identity, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIdentities(data)
+}
+
+// ParseIdentities validates identities: unique non-empty names, parseable sources and policies, and at
+// least one matcher each.
+func ParseIdentities(data []byte) ([]Identity, error) {
+	var ids []Identity
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(ids))
+	for i := range ids {
+		id := &ids[i]
+		id.Name = strings.TrimSpace(id.Name)
+		if id.Name == "" {
+			return nil, fmt.Errorf("identity %d: name is required", i)
+		}
+		if _, dup := seen[id.Name]; dup {
+			return nil, fmt.Errorf("identity %q: duplicate name", id.Name)
+		}
+		seen[id.Name] = struct{}{}
+		if len(id.Sources) == 0 && len(id.UIDs) == 0 && len(id.Cgroups) == 0 {
+			return nil, fmt.Errorf("identity %q: at least one of sources, uids or cgroups is required", id.Name)
+		}
+		for _, s := range id.Sources {
+			prefix, err := parseSource(s)
+			if err != nil {
+				return nil, fmt.Errorf("identity %q: %w", id.Name, err)
+			}
+			id.sources = append(id.sources, prefix)
+		}
+		if slices.Contains(id.Cgroups, "") {
+			return nil, fmt.Errorf("identity %q: empty cgroup", id.Name)
+		}
+		if _, err := policy.ParsePolicy(string(id.Policy)); err != nil {
+			return nil, fmt.Errorf("identity %q: invalid policy: %w", id.Name, err)
+		}
+	}
+	return ids, nil
+}
+
+func parseSource(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid source %q: must be an IP or CIDR", s)
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// identityState is an identity with its policies and hit counters; guarded by Proxy.policyMu.
+type identityState struct {
+	Identity
+	userPolicy      *policy.NetworkPolicy
+	effectivePolicy *policy.NetworkPolicy
+	ruleStats       *policy.RuleStats
+}
+
+// SetIdentities enables multi-policy mode; call before Start. Attribution by UIDs or Cgroups reads the
+// socket tables of procfs, so it is only done when an identity uses them.
+func (p *Proxy) SetIdentities(ids []Identity) error {
+	states := make([]*identityState, 0, len(ids))
+	for _, id := range ids {
+		pol, err := policy.ParsePolicy(string(id.Policy))
+		if err != nil {
+			return fmt.Errorf("identity %q: %w", id.Name, err)
+		}
+		states = append(states, &identityState{Identity: id, userPolicy: pol, ruleStats: policy.NewRuleStats(time.Now())})
+		if len(id.UIDs) > 0 {
+			p.attributeOwner = true
+		}
+		if len(id.Cgroups) > 0 {
+			p.attributeOwner, p.attributeCgroup = true, true
+		}
+	}
+	p.policyMu.Lock()
+	defer p.policyMu.Unlock()
+	p.identities = states
+	for _, st := range states {
+		p.refreshIdentityPolicy(st)
+	}
+	return nil
+}
+
+func (p *Proxy) refreshIdentityPolicy(st *identityState) {
+	st.effectivePolicy = policy.MergeAlwaysOverlay(st.userPolicy, p.alwaysDeny, p.alwaysAllow)
+	st.ruleStats.Retain(st.effectivePolicy)
+}
+
+func (p *Proxy) identityLocked(name string) *identityState {
+	for _, st := range p.identities {
+		if st.Name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+// policyFor returns the effective policy and counters deciding a query from src, and the identity name
+// ("" for the pod policy).
+func (p *Proxy) policyFor(src querySource) (*policy.NetworkPolicy, *policy.RuleStats, string) {
+	p.policyMu.RLock()
+	defer p.policyMu.RUnlock()
+	for _, st := range p.identities {
+		if st.matches(src) {
+			return st.effectivePolicy, st.ruleStats, st.Name
+		}
+	}
+	return p.effectivePolicy, p.ruleStats, ""
+}
+
+// IdentityNames lists the identities of multi-policy mode in match order.
+func (p *Proxy) IdentityNames() []string {
+	p.policyMu.RLock()
+	defer p.policyMu.RUnlock()
+	names := make([]string, 0, len(p.identities))
+	for _, st := range p.identities {
+		names = append(names, st.Name)
+	}
+	return names
+}
+
+// IdentityPolicy is CurrentPolicy of one identity; false if there is no such identity.
+func (p *Proxy) IdentityPolicy(name string) (*policy.NetworkPolicy, bool) {
+	p.policyMu.RLock()
+	defer p.policyMu.RUnlock()
+	st := p.identityLocked(name)
+	if st == nil {
+		return nil, false
+	}
+	return st.userPolicy, true
+}
+
+// UpdateIdentityPolicy is UpdatePolicy of one identity; false if there is no such identity.
+func (p *Proxy) UpdateIdentityPolicy(name string, newPolicy *policy.NetworkPolicy) bool {
+	p.policyMu.Lock()
+	defer p.policyMu.Unlock()
+	st := p.identityLocked(name)
+	if st == nil {
+		return false
+	}
+	st.userPolicy = ensurePolicyDefaults(newPolicy)
+	p.refreshIdentityPolicy(st)
+	return true
+}
+
+// IdentityRuleStats is RuleStats of one identity; false if there is no such identity.
+func (p *Proxy) IdentityRuleStats(name string) (policy.RuleStatsSnapshot, bool) {
+	p.policyMu.RLock()
+	defer p.policyMu.RUnlock()
+	st := p.identityLocked(name)
+	if st == nil {
+		return policy.RuleStatsSnapshot{}, false
+	}
+	return st.ruleStats.Snapshot(st.effectivePolicy), true
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnsproxy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/opensandbox/egress/pkg/constants"
+	"github.com/alibaba/opensandbox/egress/pkg/policy"
+)
+
+// remoteWriter is a recordingWriter that reports the client address.
+type remoteWriter struct {
+	recordingWriter
+	remote net.Addr
+}
+
+func (w *remoteWriter) RemoteAddr() net.Addr {
+	return w.remote
+}
+
+// procSocketAddr encodes an IPv4 address:port like the procfs socket tables.
+func procSocketAddr(ap netip.AddrPort) string {
+	b := ap.Addr().As4()
+	return fmt.Sprintf("%08X:%04X", binary.NativeEndian.Uint32(b[:]), ap.Port())
+}
+
+func TestParseIdentities(t *testing.T) {
+	ids, err := ParseIdentities([]byte(`[
+		{"name":"browser","uids":[1001],"policy":{"defaultAction":"deny","egress":[{"action":"allow","target":"example.com"}]}},
+		{"name":"vm","sources":["10.0.0.0/24","fd00::1"]}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24"), netip.MustParsePrefix("fd00::1/128")}, ids[1].sources)
+
+	for name, raw := range map[string]string{
+		"no name":        `[{"uids":[1]}]`,
+		"duplicate":      `[{"name":"a","uids":[1]},{"name":"a","uids":[2]}]`,
+		"no matcher":     `[{"name":"a"}]`,
+		"bad source":     `[{"name":"a","sources":["example.com"]}]`,
+		"empty cgroup":   `[{"name":"a","cgroups":[""]}]`,
+		"invalid policy": `[{"name":"a","uids":[1],"policy":{"egress":[{"action":"allow","target":""}]}}]`,
+	} {
+		_, err := ParseIdentities([]byte(raw))
+		require.Error(t, err, name)
+	}
+}
+
+func TestExtraListenAddrsFromEnv(t *testing.T) {
+	t.Setenv(constants.EnvDNSListen, "10.1.2.3, [fd00::3]:5353")
+	addrs, err := ExtraListenAddrsFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.1.2.3:53", "[fd00::3]:5353"}, addrs)
+
+	t.Setenv(constants.EnvDNSListen, "pod.local")
+	_, err = ExtraListenAddrsFromEnv()
+	require.Error(t, err)
+}
+
+func TestProxyIdentityPolicies(t *testing.T) {
+	podPolicy, err := policy.ParsePolicy(`{"defaultAction":"deny"}`)
+	require.NoError(t, err)
+	proxy := &Proxy{userPolicy: podPolicy, effectivePolicy: podPolicy, ruleStats: policy.NewRuleStats(time.Now())}
+	ids, err := ParseIdentities([]byte(`[{"name":"vm","sources":["10.0.0.0/24"]}]`))
+	require.NoError(t, err)
+	require.NoError(t, proxy.SetIdentities(ids))
+	require.Equal(t, []string{"vm"}, proxy.IdentityNames())
+
+	vmPolicy, err := policy.ParsePolicy(`{"defaultAction":"allow","egress":[{"action":"deny","target":"*.blocked.test"}]}`)
+	require.NoError(t, err)
+	require.True(t, proxy.UpdateIdentityPolicy("vm", vmPolicy))
+	require.False(t, proxy.UpdateIdentityPolicy("other", vmPolicy))
+
+	query := func(remote string, local bool) int {
+		req := new(dns.Msg)
+		req.SetQuestion("a.blocked.test.", dns.TypeA)
+		w := &remoteWriter{remote: &net.UDPAddr{IP: net.ParseIP(remote), Port: 40000}}
+		if local {
+			proxy.serveDNS(w, req)
+		} else {
+			proxy.serveRemoteDNS(w, req)
+		}
+		return w.msg.Rcode
+	}
+	// The vm identity denies the name by rule, the pod policy by default; local queries never match sources.
+	require.Equal(t, dns.RcodeNameError, query("10.0.0.7", false))
+	require.Equal(t, dns.RcodeNameError, query("10.0.0.7", true))
+	require.Equal(t, dns.RcodeNameError, query("10.9.0.7", false))
+
+	stats, ok := proxy.IdentityRuleStats("vm")
+	require.True(t, ok)
+	require.Len(t, stats.Rules, 1)
+	require.Equal(t, uint64(1), stats.Rules[0].Hits)
+	require.Equal(t, uint64(2), proxy.RuleStats().Default.Hits)
+
+	proxy.ResetRuleStats()
+	stats, _ = proxy.IdentityRuleStats("vm")
+	require.Zero(t, stats.Rules[0].Hits)
+
+	deny, err := policy.ParseValidatedEgressRule(policy.ActionDeny, "always.test")
+	require.NoError(t, err)
+	proxy.UpdateAlwaysRules([]policy.EgressRule{deny}, nil)
+	st := proxy.identityLocked("vm")
+	require.Equal(t, policy.ActionDeny, st.effectivePolicy.Evaluate("always.test."), "always rules apply to identities")
+	require.Equal(t, policy.ActionAllow, st.userPolicy.Evaluate("always.test."))
+}
+
+func TestProxyAttributesLocalQueriesBySocketOwner(t *testing.T) {
+	root := t.TempDir()
+	procRoot = root
+	t.Cleanup(func() { procRoot = "/proc" })
+
+	client := netip.MustParseAddrPort("10.0.0.5:40001")
+	wildcard := netip.MustParseAddrPort("0.0.0.0:40002")
+	server := netip.MustParseAddrPort("127.0.0.1:15353")
+	udp := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n" +
+		fmt.Sprintf("   0: %s %s 01 00000000:00000000 00:00000000 00000000  1001        0 111 2 0 0\n", procSocketAddr(client), procSocketAddr(server)) +
+		fmt.Sprintf("   1: %s 00000000:0000 07 00000000:00000000 00:00000000 00000000  1002        0 222 2 0 0\n", procSocketAddr(wildcard))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "net"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "net", "udp"), []byte(udp), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "42", "fd"), 0o755))
+	require.NoError(t, os.Symlink("socket:[222]", filepath.Join(root, "42", "fd", "3")))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "42", "cgroup"), []byte("0::/kubepods/pod1/cri-containerd-abc123\n"), 0o644))
+
+	uid, inode, ok := socketOwner("udp", client)
+	require.True(t, ok)
+	require.Equal(t, uint32(1001), uid)
+	require.Equal(t, uint64(111), inode)
+	_, _, ok = socketOwner("udp", netip.MustParseAddrPort("10.0.0.5:40003"))
+	require.False(t, ok)
+	require.Equal(t, "0::/kubepods/pod1/cri-containerd-abc123", socketCgroup(222))
+
+	pol, err := policy.ParsePolicy(`{"defaultAction":"deny"}`)
+	require.NoError(t, err)
+	proxy := &Proxy{userPolicy: pol, effectivePolicy: pol, ruleStats: policy.NewRuleStats(time.Now())}
+	ids, err := ParseIdentities([]byte(`[
+		{"name":"agent","uids":[1001],"policy":{"defaultAction":"allow"}},
+		{"name":"browser","cgroups":["abc123"],"policy":{"defaultAction":"allow"}}
+	]`))
+	require.NoError(t, err)
+	require.NoError(t, proxy.SetIdentities(ids))
+
+	for addr, want := range map[netip.AddrPort]string{
+		client: "agent",
+		netip.MustParseAddrPort("10.0.0.5:40002"): "browser",
+		netip.MustParseAddrPort("10.0.0.5:40003"): "",
+	} {
+		src := proxy.querySourceOf(net.UDPAddrFromAddrPort(addr), true)
+		_, _, identity := proxy.policyFor(src)
+		require.Equal(t, want, identity, addr.String())
+	}
+}
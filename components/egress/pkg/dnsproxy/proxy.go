@@ -34,6 +34,7 @@ import (
 	"github.com/alibaba/opensandbox/egress/pkg/policy"
 	"github.com/alibaba/opensandbox/egress/pkg/telemetry"
 	slogger "github.com/alibaba/opensandbox/internal/logger"
+	"github.com/alibaba/opensandbox/internal/recording"
 	"github.com/alibaba/opensandbox/internal/safego"
 )
 
@@ -60,6 +61,18 @@ type Proxy struct {
 	onResolved func(domain string, ips []nftables.ResolvedIP)
 	// Optional: async fan-out for denied lookups (e.g. webhook).
 	blockedBroadcaster *events.Broadcaster
+	// When valid, AAAA lookups of IPv4-only names are answered with NAT64 addresses (see dns64.go).
+	dns64Prefix netip.Prefix
+	// Optional: session record of every policy decision (nil records nothing).
+	recorder *recording.Recorder
+	// Hits per rule of the effective policy, for GET /policy/stats.
+	ruleStats *policy.RuleStats
+	// Additional listeners (e.g. the pod IP) for clients in other network namespaces; see SetExtraListenAddrs.
+	extraListenAddrs []string
+	// Multi-policy mode (see identity.go); empty means one policy for the whole pod.
+	identities      []*identityState
+	attributeOwner  bool // look up the client socket owner of local queries
+	attributeCgroup bool // and the cgroup of its process
 }
 
 // New constructs the DNS proxy: discovers upstreams, default listen 127.0.0.1:15353 if listenAddr is "".
@@ -87,6 +100,7 @@ func New(p *policy.NetworkPolicy, listenAddr string, alwaysDeny, alwaysAllow []p
 		userPolicy:              ensurePolicyDefaults(p),
 		alwaysDeny:              append([]policy.EgressRule(nil), alwaysDeny...),
 		alwaysAllow:             append([]policy.EgressRule(nil), alwaysAllow...),
+		ruleStats:               policy.NewRuleStats(time.Now()),
 	}
 	proxy.refreshEffectivePolicy()
 	return proxy, nil
@@ -94,6 +108,7 @@ func New(p *policy.NetworkPolicy, listenAddr string, alwaysDeny, alwaysAllow []p
 
 func (p *Proxy) refreshEffectivePolicy() {
 	p.effectivePolicy = policy.MergeAlwaysOverlay(p.userPolicy, p.alwaysDeny, p.alwaysAllow)
+	p.ruleStats.Retain(p.effectivePolicy)
 }
 
 func upstreamExchangeTimeoutFromEnv() time.Duration {
@@ -113,10 +128,17 @@ func upstreamExchangeTimeoutFromEnv() time.Duration {
 
 func (p *Proxy) Start(ctx context.Context) error {
 	handler := dns.HandlerFunc(p.serveDNS)
+	remoteHandler := dns.HandlerFunc(p.serveRemoteDNS)
 
 	udpServer := &dns.Server{Addr: p.listenAddr, Net: "udp", Handler: handler}
 	tcpServer := &dns.Server{Addr: p.listenAddr, Net: "tcp", Handler: handler}
 	p.servers = []*dns.Server{udpServer, tcpServer}
+	for _, addr := range p.extraListenAddrs {
+		p.servers = append(p.servers,
+			&dns.Server{Addr: addr, Net: "udp", Handler: remoteHandler},
+			&dns.Server{Addr: addr, Net: "tcp", Handler: remoteHandler},
+		)
+	}
 
 	errCh := make(chan error, len(p.servers))
 	for _, srv := range p.servers {
@@ -155,7 +177,17 @@ func (p *Proxy) Shutdown() error {
 	return outErr
 }
 
+// serveDNS answers the pod's own queries, redirected to the listen address by iptables.
 func (p *Proxy) serveDNS(w dns.ResponseWriter, r *dns.Msg) {
+	p.serveQuery(w, r, true)
+}
+
+// serveRemoteDNS answers queries on the additional listeners, from other network namespaces.
+func (p *Proxy) serveRemoteDNS(w dns.ResponseWriter, r *dns.Msg) {
+	p.serveQuery(w, r, false)
+}
+
+func (p *Proxy) serveQuery(w dns.ResponseWriter, r *dns.Msg, local bool) {
 	if len(r.Question) == 0 {
 		_ = w.WriteMsg(new(dns.Msg))
 		return
@@ -164,10 +196,19 @@ func (p *Proxy) serveDNS(w dns.ResponseWriter, r *dns.Msg) {
 	domain := q.Name
 	host := normalizeDNSHost(domain)
 
-	p.policyMu.RLock()
-	currentPolicy := p.effectivePolicy
-	p.policyMu.RUnlock()
-	if currentPolicy != nil && currentPolicy.Evaluate(domain) == policy.ActionDeny {
+	var src querySource
+	if len(p.identities) > 0 {
+		src = p.querySourceOf(w.RemoteAddr(), local)
+	}
+	currentPolicy, stats, identity := p.policyFor(src)
+	action := policy.ActionAllow
+	if currentPolicy != nil {
+		var rule int
+		action, rule = currentPolicy.Match(domain)
+		stats.Record(currentPolicy, rule, time.Now())
+	}
+	if action == policy.ActionDeny {
+		p.recordDecision(host, q.Qtype, policy.ActionDeny, identity)
 		telemetry.RecordDNSDenied()
 		p.publishBlocked(domain)
 		resp := new(dns.Msg)
@@ -176,6 +217,8 @@ func (p *Proxy) serveDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
+	p.recordDecision(host, q.Qtype, policy.ActionAllow, identity)
+
 	start := time.Now()
 	resp, err := p.forward(r)
 	elapsed := time.Since(start).Seconds()
@@ -189,6 +232,9 @@ func (p *Proxy) serveDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 	telemetry.RecordDNSForward(elapsed)
+	if q.Qtype == dns.TypeAAAA && p.dns64Prefix.IsValid() {
+		resp = p.synthesizeDNS64(r, resp)
+	}
 	logOutboundDNS(host, resolvedIPStrings(resp), "", "")
 	p.maybeNotifyResolved(domain, resp)
 	_ = w.WriteMsg(resp)
@@ -287,6 +333,9 @@ func (p *Proxy) UpdateAlwaysRules(alwaysDeny, alwaysAllow []policy.EgressRule) {
 	p.alwaysDeny = append([]policy.EgressRule(nil), alwaysDeny...)
 	p.alwaysAllow = append([]policy.EgressRule(nil), alwaysAllow...)
 	p.refreshEffectivePolicy()
+	for _, st := range p.identities {
+		p.refreshIdentityPolicy(st)
+	}
 }
 
 // CurrentPolicy is the last user policy from the API, without always file overlay in the struct (overlay is in effectivePolicy).
@@ -297,16 +346,65 @@ func (p *Proxy) CurrentPolicy() *policy.NetworkPolicy {
 	return p.userPolicy
 }
 
+// RuleStats returns the hits of the domain rules of the effective policy, including the always file overlay.
+func (p *Proxy) RuleStats() policy.RuleStatsSnapshot {
+	p.policyMu.RLock()
+	defer p.policyMu.RUnlock()
+
+	return p.ruleStats.Snapshot(p.effectivePolicy)
+}
+
+// ResetRuleStats clears the rule hit counters, including those of the identities.
+func (p *Proxy) ResetRuleStats() {
+	now := time.Now()
+	p.ruleStats.Reset(now)
+	p.policyMu.RLock()
+	defer p.policyMu.RUnlock()
+	for _, st := range p.identities {
+		st.ruleStats.Reset(now)
+	}
+}
+
 // SetOnResolved registers the dns+nft path (nil in dns-only). Invoked on the same goroutine as serveDNS, before WriteMsg.
 func (p *Proxy) SetOnResolved(fn func(domain string, ips []nftables.ResolvedIP)) {
 	p.onResolved = fn
 }
 
+// SetDNS64Prefix enables DNS64 with the given NAT64 prefix; call before Start.
+func (p *Proxy) SetDNS64Prefix(prefix netip.Prefix) {
+	p.dns64Prefix = prefix
+}
+
+// SetExtraListenAddrs adds listeners (host:port) for clients in other network namespaces, e.g. the pod
+// IP on port 53 for containers that do not share the pod network; call before Start. Their queries are not
+// redirected by iptables and are attributed to identities by client address only.
+func (p *Proxy) SetExtraListenAddrs(addrs []string) {
+	p.extraListenAddrs = append([]string(nil), addrs...)
+}
+
 // SetBlockedBroadcaster wires the optional publisher for policy-denied lookups.
 func (p *Proxy) SetBlockedBroadcaster(b *events.Broadcaster) {
 	p.blockedBroadcaster = b
 }
 
+// SetRecorder records every allow/deny decision in the sandbox session record; call before Start.
+func (p *Proxy) SetRecorder(r *recording.Recorder) {
+	p.recorder = r
+}
+
+func (p *Proxy) recordDecision(host string, qtype uint16, action, identity string) {
+	if p.recorder == nil {
+		return
+	}
+	fields := map[string]any{"domain": host, "qtype": dns.TypeToString[qtype], "action": action}
+	if identity != "" {
+		fields["identity"] = identity
+	}
+	if err := p.recorder.Record(recording.TypeEgress, fields); err != nil {
+		log.Warnf("[dns] failed to record egress decision for %s: %v", host, err)
+	}
+}
+
 func (p *Proxy) publishBlocked(domain string) {
 	if p.blockedBroadcaster == nil {
 		return
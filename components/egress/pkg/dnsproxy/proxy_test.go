@@ -15,7 +15,10 @@
 package dnsproxy
 
 import (
+	"bytes"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -24,6 +27,7 @@ import (
 
 	"github.com/alibaba/opensandbox/egress/pkg/nftables"
 	"github.com/alibaba/opensandbox/egress/pkg/policy"
+	"github.com/alibaba/opensandbox/internal/recording"
 )
 
 func TestProxyUpdatePolicy(t *testing.T) {
@@ -56,6 +60,52 @@ func TestProxyAlwaysOverlayPrecedence(t *testing.T) {
 	require.Equal(t, policy.ActionDeny, proxy.effectivePolicy.Evaluate("nope.test."), "effective policy includes always deny")
 }
 
+func TestProxyRecordsDeniedLookups(t *testing.T) {
+	pol, err := policy.ParsePolicy(`{"defaultAction":"deny"}`)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	recorder, err := recording.New(dir, "egress", key)
+	require.NoError(t, err)
+	proxy := &Proxy{userPolicy: pol, effectivePolicy: pol}
+	proxy.SetRecorder(recorder)
+
+	req := new(dns.Msg)
+	req.SetQuestion("blocked.test.", dns.TypeA)
+	w := &recordingWriter{}
+	proxy.serveDNS(w, req)
+	require.Equal(t, dns.RcodeNameError, w.msg.Rcode)
+
+	data, err := os.ReadFile(filepath.Join(dir, "egress.jsonl"))
+	require.NoError(t, err)
+	n, err := recording.Verify(bytes.NewReader(data), key)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Contains(t, string(data), `"action":"deny"`)
+	require.Contains(t, string(data), `"domain":"blocked.test"`)
+}
+
+func TestProxyCountsRuleHits(t *testing.T) {
+	pol, err := policy.ParsePolicy(`{"defaultAction":"allow","egress":[{"action":"deny","target":"*.blocked.test"},{"action":"deny","target":"unused.test"}]}`)
+	require.NoError(t, err)
+	proxy := &Proxy{userPolicy: pol, effectivePolicy: pol, ruleStats: policy.NewRuleStats(time.Now())}
+
+	for range 2 {
+		req := new(dns.Msg)
+		req.SetQuestion("a.blocked.test.", dns.TypeA)
+		proxy.serveDNS(&recordingWriter{}, req)
+	}
+
+	stats := proxy.RuleStats()
+	require.Len(t, stats.Rules, 2)
+	require.Equal(t, uint64(2), stats.Rules[0].Hits)
+	require.NotNil(t, stats.Rules[0].LastHit)
+	require.Zero(t, stats.Rules[1].Hits)
+
+	proxy.ResetRuleStats()
+	require.Zero(t, proxy.RuleStats().Rules[0].Hits)
+}
+
 func TestExtractResolvedIPs(t *testing.T) {
 	msg := new(dns.Msg)
 	msg.Answer = []dns.RR{
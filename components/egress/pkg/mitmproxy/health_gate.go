@@ -21,7 +21,8 @@ import (
 	"github.com/alibaba/opensandbox/egress/pkg/constants"
 )
 
-// HealthGate: /healthz stays 503 until MarkStackReady when transparent mitm is required (env enabled).
+// HealthGate: /healthz stays 503 until MarkStackReady when transparent mitm is required (env enabled);
+// /readyz on the admin port stays 503 until MarkStackReady regardless.
 type HealthGate struct {
 	required bool
 	ready    atomic.Bool
@@ -29,11 +30,7 @@ type HealthGate struct {
 
 func NewHealthGate() *HealthGate {
 	required := constants.IsTruthy(os.Getenv(constants.EnvMitmproxyTransparent))
-	g := &HealthGate{required: required}
-	if !required {
-		g.ready.Store(true)
-	}
-	return g
+	return &HealthGate{required: required}
 }
 
 func (g *HealthGate) MarkStackReady() {
@@ -42,6 +39,11 @@ func (g *HealthGate) MarkStackReady() {
 	}
 }
 
+// StackReady reports whether DNS redirect, enforcement, the policy server and (if required) mitm are up.
+func (g *HealthGate) StackReady() bool {
+	return g != nil && g.ready.Load()
+}
+
 func (g *HealthGate) MitmPending() bool {
 	if g == nil {
 		return false
@@ -26,12 +26,17 @@ func TestHealthGate(t *testing.T) {
 		t.Setenv(constants.EnvMitmproxyTransparent, "1")
 		on := NewHealthGate()
 		require.True(t, on.MitmPending())
+		require.False(t, on.StackReady())
 		on.MarkStackReady()
 		require.False(t, on.MitmPending())
+		require.True(t, on.StackReady())
 	})
 	t.Run("not transparent", func(t *testing.T) {
 		t.Setenv(constants.EnvMitmproxyTransparent, "")
 		off := NewHealthGate()
 		require.False(t, off.MitmPending())
+		require.False(t, off.StackReady())
+		off.MarkStackReady()
+		require.True(t, off.StackReady())
 	})
 }
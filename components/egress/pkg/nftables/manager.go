@@ -17,11 +17,14 @@ package nftables
 import (
 	"context"
 	"fmt"
+	"net/netip"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/alibaba/opensandbox/egress/pkg/constants"
+	"github.com/alibaba/opensandbox/egress/pkg/dns64"
 	"github.com/alibaba/opensandbox/egress/pkg/log"
 	"github.com/alibaba/opensandbox/egress/pkg/policy"
 	"github.com/alibaba/opensandbox/egress/pkg/telemetry"
@@ -30,6 +33,7 @@ import (
 const (
 	tableName     = "opensandbox"
 	chainName     = "egress"
+	udpChainName  = "udp_egress"
 	allowV4Set    = "allow_v4"
 	allowV6Set    = "allow_v6"
 	denyV4Set     = "deny_v4"
@@ -45,6 +49,9 @@ type Options struct {
 	BlockDoH443    bool
 	DoHBlocklistV4 []string
 	DoHBlocklistV6 []string
+	// DNS64Prefix, when valid, mirrors IPv4 allow/deny targets into the IPv6 sets at their NAT64 addresses,
+	// so IP rules keep applying to IPv4 destinations reached through NAT64.
+	DNS64Prefix netip.Prefix
 }
 
 type Manager struct {
@@ -140,6 +147,14 @@ func (m *Manager) RemoveEnforcement(ctx context.Context) error {
 func buildRuleset(p *policy.NetworkPolicy, opts Options) (string, error) {
 	allowV4, allowV6, denyV4, denyV6 := p.StaticIPSets()
 	var err error
+	if opts.DNS64Prefix.IsValid() {
+		if allowV6, err = appendNAT64Targets(allowV6, opts.DNS64Prefix, allowV4); err != nil {
+			return "", err
+		}
+		if denyV6, err = appendNAT64Targets(denyV6, opts.DNS64Prefix, denyV4); err != nil {
+			return "", err
+		}
+	}
 	if allowV4, err = normalizeNFTIntervalSet(allowV4); err != nil {
 		return "", err
 	}
@@ -190,6 +205,11 @@ func buildRuleset(p *policy.NetworkPolicy, opts Options) (string, error) {
 	writeElements(&b, dohBlockV4Set, dohBlockV4)
 	writeElements(&b, dohBlockV6Set, dohBlockV6)
 
+	blockUDP := p.BlocksUDP()
+	if blockUDP {
+		writeUDPChain(&b, p.UDP.Allow, opts.DNS64Prefix)
+	}
+
 	chainPolicy := "drop"
 	if p.DefaultAction == policy.ActionAllow {
 		chainPolicy = "accept"
@@ -198,6 +218,9 @@ func buildRuleset(p *policy.NetworkPolicy, opts Options) (string, error) {
 	fmt.Fprintf(&b, "add rule inet %s %s ct state established,related accept\n", tableName, chainName)
 	fmt.Fprintf(&b, "add rule inet %s %s meta mark %s accept\n", tableName, chainName, constants.MarkHex)
 	fmt.Fprintf(&b, "add rule inet %s %s oifname \"lo\" accept\n", tableName, chainName)
+	if blockUDP {
+		fmt.Fprintf(&b, "add rule inet %s %s meta l4proto udp jump %s\n", tableName, chainName, udpChainName)
+	}
 	if opts.BlockDoT {
 		fmt.Fprintf(&b, "add rule inet %s %s tcp dport 853 drop\n", tableName, chainName)
 		fmt.Fprintf(&b, "add rule inet %s %s udp dport 853 drop\n", tableName, chainName)
@@ -228,6 +251,48 @@ func buildRuleset(p *policy.NetworkPolicy, opts Options) (string, error) {
 	return b.String(), nil
 }
 
+// writeUDPChain adds the chain that drops outbound UDP other than DNS and the allowed destinations. Traffic
+// it lets through returns to the egress chain, so the IP rules and default action still apply.
+func writeUDPChain(b *strings.Builder, allow []policy.UDPRule, dns64Prefix netip.Prefix) {
+	fmt.Fprintf(b, "add chain inet %s %s\n", tableName, udpChainName)
+	// DNS is redirected to the proxy, or goes to an exempt nameserver.
+	fmt.Fprintf(b, "add rule inet %s %s udp dport 53 return\n", tableName, udpChainName)
+	for _, r := range allow {
+		targets := []netip.Prefix{r.Prefix()}
+		if dns64Prefix.IsValid() && r.Prefix().Addr().Is4() {
+			targets = append(targets, dns64.MapPrefix(dns64Prefix, r.Prefix()))
+		}
+		ports := ""
+		if len(r.Ports) > 0 {
+			list := make([]string, len(r.Ports))
+			for i, port := range r.Ports {
+				list[i] = strconv.Itoa(int(port))
+			}
+			ports = fmt.Sprintf(" udp dport { %s }", strings.Join(list, ", "))
+		}
+		for _, target := range targets {
+			family := "ip"
+			if target.Addr().Is6() {
+				family = "ip6"
+			}
+			fmt.Fprintf(b, "add rule inet %s %s %s daddr %s%s return\n", tableName, udpChainName, family, target, ports)
+		}
+	}
+	fmt.Fprintf(b, "add rule inet %s %s counter drop\n", tableName, udpChainName)
+}
+
+// appendNAT64Targets appends the NAT64 addresses of IPv4 set elements to an IPv6 set.
+func appendNAT64Targets(v6 []string, prefix netip.Prefix, v4 []string) ([]string, error) {
+	for _, s := range v4 {
+		p, err := parseAsPrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		v6 = append(v6, dns64.MapPrefix(prefix, p).String())
+	}
+	return v6, nil
+}
+
 func writeElements(b *strings.Builder, setName string, elems []string) {
 	if len(elems) == 0 {
 		return
@@ -59,6 +59,31 @@ func TestApplyStatic_BuildsRuleset_DefaultDeny(t *testing.T) {
 	expectContains(t, rendered, "add rule inet opensandbox egress counter drop")
 }
 
+func TestApplyStatic_DNS64MirrorsIPv4Targets(t *testing.T) {
+	var rendered string
+	m := NewManagerWithRunnerAndOptions(func(_ context.Context, script string) ([]byte, error) {
+		rendered = script
+		return nil, nil
+	}, Options{DNS64Prefix: netip.MustParsePrefix("64:ff9b::/96")})
+
+	p, err := policy.ParsePolicy(`{
+		"defaultAction":"deny",
+		"egress":[
+			{"action":"allow","target":"1.1.1.1"},
+			{"action":"allow","target":"2001:db8::1"},
+			{"action":"deny","target":"10.0.0.0/8"}
+		]
+	}`)
+	require.NoError(t, err, "unexpected parse error")
+
+	require.NoError(t, m.ApplyStatic(context.Background(), p), "ApplyStatic returned error")
+
+	expectContains(t, rendered, "add element inet opensandbox allow_v4 { 1.1.1.1 }")
+	expectContains(t, rendered, "add element inet opensandbox allow_v6 { 2001:db8::1, 64:ff9b::101:101 }")
+	expectContains(t, rendered, "add element inet opensandbox deny_v4 { 10.0.0.0/8 }")
+	expectContains(t, rendered, "add element inet opensandbox deny_v6 { 64:ff9b::a00:0/104 }")
+}
+
 func TestApplyStatic_DefaultAllowUsesAcceptPolicy(t *testing.T) {
 	var rendered string
 	m := NewManagerWithRunner(func(_ context.Context, script string) ([]byte, error) {
@@ -126,6 +151,37 @@ func TestApplyStatic_DoHBlocklist(t *testing.T) {
 	expectContains(t, rendered, "add rule inet opensandbox egress ip6 daddr @doh_block_v6 tcp dport 443 drop")
 }
 
+func TestApplyStatic_UDPBlock(t *testing.T) {
+	var rendered string
+	m := NewManagerWithRunnerAndOptions(func(_ context.Context, script string) ([]byte, error) {
+		rendered = script
+		return nil, nil
+	}, Options{DNS64Prefix: netip.MustParsePrefix("64:ff9b::/96")})
+
+	p, err := policy.ParsePolicy(`{
+		"defaultAction":"allow",
+		"egress":[],
+		"udp":{"block":true,"allow":[
+			{"target":"10.0.0.5","ports":[3478,3479]},
+			{"target":"2001:db8::/32"}
+		]}
+	}`)
+	require.NoError(t, err, "unexpected parse error")
+	require.NoError(t, m.ApplyStatic(context.Background(), p), "ApplyStatic returned error")
+
+	expectContains(t, rendered, "add chain inet opensandbox udp_egress\n")
+	expectContains(t, rendered, "add rule inet opensandbox udp_egress udp dport 53 return")
+	expectContains(t, rendered, "add rule inet opensandbox udp_egress ip daddr 10.0.0.5/32 udp dport { 3478, 3479 } return")
+	expectContains(t, rendered, "add rule inet opensandbox udp_egress ip6 daddr 64:ff9b::a00:5/128 udp dport { 3478, 3479 } return")
+	expectContains(t, rendered, "add rule inet opensandbox udp_egress ip6 daddr 2001:db8::/32 return")
+	expectContains(t, rendered, "add rule inet opensandbox udp_egress counter drop")
+	expectContains(t, rendered, "add rule inet opensandbox egress meta l4proto udp jump udp_egress")
+
+	p, _ = policy.ParsePolicy(`{"defaultAction":"allow","egress":[]}`)
+	require.NoError(t, m.ApplyStatic(context.Background(), p), "ApplyStatic returned error")
+	require.NotContains(t, rendered, "udp_egress")
+}
+
 func TestAddResolvedIPs_BuildsDynamicElements(t *testing.T) {
 	var rendered string
 	m := NewManagerWithRunner(func(_ context.Context, script string) ([]byte, error) {
@@ -60,8 +60,14 @@ func compileDomainIndex(egress []EgressRule) *compiledDomainIndex {
 }
 
 func (idx *compiledDomainIndex) match(domain string) (string, bool) {
+	rule, ok := idx.matchRule(domain)
+	return rule.action, ok
+}
+
+// matchRule returns the first rule of the egress order matching domain.
+func (idx *compiledDomainIndex) matchRule(domain string) (compiledDomainRule, bool) {
 	if idx == nil || domain == "" {
-		return "", false
+		return compiledDomainRule{}, false
 	}
 
 	var best compiledDomainRule
@@ -69,7 +75,7 @@ func (idx *compiledDomainIndex) match(domain string) (string, bool) {
 
 	if rule, ok := idx.exact[domain]; ok {
 		if rule.index == 0 {
-			return rule.action, true
+			return rule, true
 		}
 		best = rule
 		matched = true
@@ -83,7 +89,7 @@ func (idx *compiledDomainIndex) match(domain string) (string, bool) {
 		suffix := cursor[dot:]
 		if rule, ok := idx.wildcard[suffix]; ok {
 			if rule.index == 0 {
-				return rule.action, true
+				return rule, true
 			}
 			if !matched || rule.index < best.index {
 				best = rule
@@ -94,7 +100,7 @@ func (idx *compiledDomainIndex) match(domain string) (string, bool) {
 	}
 
 	if !matched {
-		return "", false
+		return compiledDomainRule{}, false
 	}
-	return best.action, true
+	return best, true
 }
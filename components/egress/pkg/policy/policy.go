@@ -48,6 +48,7 @@ func DefaultDenyPolicy() *NetworkPolicy {
 type NetworkPolicy struct {
 	Egress        []EgressRule `json:"egress"`
 	DefaultAction string       `json:"defaultAction"`
+	UDP           *UDPPolicy   `json:"udp,omitempty"`
 
 	domainIndex *compiledDomainIndex
 }
@@ -61,6 +62,33 @@ type EgressRule struct {
 	prefix     netip.Prefix
 }
 
+// UDPPolicy restricts outbound UDP, so QUIC (HTTP/3) can't reach allowed IPs past the TCP paths the
+// sidecar inspects. Enforced in dns+nft mode only.
+type UDPPolicy struct {
+	// Block drops outbound UDP except DNS and the Allow destinations.
+	Block bool `json:"block"`
+	// Allow exempts destinations from Block; egress rules and defaultAction still apply to them.
+	Allow []UDPRule `json:"allow,omitempty"`
+}
+
+// UDPRule is an IP or CIDR, limited to Ports when set.
+type UDPRule struct {
+	Target string   `json:"target"`
+	Ports  []uint16 `json:"ports,omitempty"`
+
+	prefix netip.Prefix
+}
+
+// Prefix returns the parsed target; a single IP is a full-length prefix.
+func (r UDPRule) Prefix() netip.Prefix {
+	return r.prefix
+}
+
+// BlocksUDP reports whether outbound UDP is limited to DNS and the UDP allow list.
+func (p *NetworkPolicy) BlocksUDP() bool {
+	return p != nil && p.UDP != nil && p.UDP.Block
+}
+
 // ParsePolicy unmarshals JSON; empty/null/{} → default deny. defaultAction defaults to deny if unset in JSON.
 func ParsePolicy(raw string) (*NetworkPolicy, error) {
 	trimmed := strings.TrimSpace(raw)
@@ -80,43 +108,93 @@ func ParsePolicy(raw string) (*NetworkPolicy, error) {
 
 // Evaluate returns allow or deny for a query name (FQDN with or without trailing dot, lowercased).
 func (p *NetworkPolicy) Evaluate(domain string) string {
+	action, _ := p.Match(domain)
+	return action
+}
+
+// Match is Evaluate that also returns the index in Egress of the deciding rule, or -1 when the
+// default action applies.
+func (p *NetworkPolicy) Match(domain string) (string, int) {
 	if p == nil {
-		return ActionDeny
+		return ActionDeny, -1
 	}
 	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
 
 	if p.domainIndex != nil {
-		if action, ok := p.domainIndex.match(domain); ok {
-			if action == "" {
-				return ActionDeny
+		if rule, ok := p.domainIndex.matchRule(domain); ok {
+			if rule.action == "" {
+				return ActionDeny, rule.index
 			}
-			return action
+			return rule.action, rule.index
 		}
 	} else {
 		// Keep compatibility for policies built manually without ParsePolicy/ensureDefaults.
-		if action, ok := p.evaluateLinear(domain); ok {
-			return action
+		if i, ok := p.linearRule(domain); ok {
+			if p.Egress[i].Action == "" {
+				return ActionDeny, i
+			}
+			return p.Egress[i].Action, i
 		}
 	}
 	if p.DefaultAction == "" {
-		return ActionDeny
+		return ActionDeny, -1
 	}
-	return p.DefaultAction
+	return p.DefaultAction, -1
+}
+
+// MatchIP returns allow or deny for a destination IP the way nftables enforces the IP/CIDR rules: a
+// matching deny rule wins over matching allow rules whatever their order. The index in Egress of the
+// deciding rule is -1 when the default action applies. Domain rules are not considered.
+func (p *NetworkPolicy) MatchIP(addr netip.Addr) (string, int) {
+	if p == nil {
+		return ActionDeny, -1
+	}
+	addr = addr.Unmap()
+	allow := -1
+	for i, r := range p.Egress {
+		switch {
+		case r.targetKind == targetIP && r.ip.Unmap() == addr:
+		case r.targetKind == targetCIDR && r.prefix.Contains(addr):
+		default:
+			continue
+		}
+		if r.Action != ActionAllow {
+			return ActionDeny, i
+		}
+		if allow < 0 {
+			allow = i
+		}
+	}
+	if allow >= 0 {
+		return ActionAllow, allow
+	}
+	if p.DefaultAction == "" {
+		return ActionDeny, -1
+	}
+	return p.DefaultAction, -1
 }
 
 func (p *NetworkPolicy) evaluateLinear(domain string) (string, bool) {
-	for _, r := range p.Egress {
+	i, ok := p.linearRule(domain)
+	if !ok {
+		return "", false
+	}
+	if p.Egress[i].Action == "" {
+		return ActionDeny, true
+	}
+	return p.Egress[i].Action, true
+}
+
+func (p *NetworkPolicy) linearRule(domain string) (int, bool) {
+	for i, r := range p.Egress {
 		if r.targetKind != targetDomain {
 			continue
 		}
 		if r.matchesDomain(domain) {
-			if r.Action == "" {
-				return ActionDeny, true
-			}
-			return r.Action, true
+			return i, true
 		}
 	}
-	return "", false
+	return -1, false
 }
 
 func ensureDefaults(p *NetworkPolicy) *NetworkPolicy {
@@ -162,6 +240,29 @@ func normalizePolicy(p *NetworkPolicy) error {
 		}
 		r.targetKind = targetDomain
 	}
+	if p.UDP != nil {
+		return normalizeUDPPolicy(p.UDP)
+	}
+	return nil
+}
+
+func normalizeUDPPolicy(u *UDPPolicy) error {
+	for i := range u.Allow {
+		r := &u.Allow[i]
+		r.Target = strings.TrimSpace(r.Target)
+		if ip, err := netip.ParseAddr(r.Target); err == nil {
+			r.prefix = netip.PrefixFrom(ip, ip.BitLen())
+		} else if prefix, err := netip.ParsePrefix(r.Target); err == nil {
+			r.prefix = prefix.Masked()
+		} else {
+			return fmt.Errorf("udp allow target %q must be an IP or CIDR", r.Target)
+		}
+		for _, port := range r.Ports {
+			if port == 0 {
+				return fmt.Errorf("udp allow target %q: port must be between 1 and 65535", r.Target)
+			}
+		}
+	}
 	return nil
 }
 
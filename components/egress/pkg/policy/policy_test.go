@@ -168,3 +168,63 @@ func TestEvaluate_CompiledIndexKeepsFirstMatchPriority(t *testing.T) {
 func normalizeQueryForTest(domain string) string {
 	return strings.ToLower(strings.TrimSuffix(domain, "."))
 }
+
+func TestParsePolicy_UDP(t *testing.T) {
+	p, err := ParsePolicy(`{
+		"defaultAction":"deny",
+		"egress":[{"action":"allow","target":"example.com"}],
+		"udp":{"block":true,"allow":[
+			{"target":"10.0.0.5","ports":[3478]},
+			{"target":"2001:db8::1/48"}
+		]}
+	}`)
+	require.NoError(t, err)
+	require.True(t, p.BlocksUDP())
+	require.Len(t, p.UDP.Allow, 2)
+	require.Equal(t, netip.MustParsePrefix("10.0.0.5/32"), p.UDP.Allow[0].Prefix())
+	require.Equal(t, []uint16{3478}, p.UDP.Allow[0].Ports)
+	require.Equal(t, netip.MustParsePrefix("2001:db8::/48"), p.UDP.Allow[1].Prefix())
+
+	p, err = ParsePolicy(`{"egress":[{"action":"allow","target":"example.com"}]}`)
+	require.NoError(t, err)
+	require.False(t, p.BlocksUDP())
+}
+
+func TestParsePolicy_UDPInvalid(t *testing.T) {
+	_, err := ParsePolicy(`{"udp":{"block":true,"allow":[{"target":"example.com"}]}}`)
+	require.Error(t, err, "expected error for domain udp target")
+
+	_, err = ParsePolicy(`{"udp":{"block":true,"allow":[{"target":"10.0.0.5","ports":[0]}]}}`)
+	require.Error(t, err, "expected error for port 0")
+}
+
+func TestMatchIP_DenyWinsOverAllow(t *testing.T) {
+	p, err := ParsePolicy(`{"defaultAction":"allow","egress":[
+		{"action":"allow","target":"10.0.0.0/8"},
+		{"action":"deny","target":"10.1.0.0/16"},
+		{"action":"allow","target":"example.com"},
+		{"action":"deny","target":"2001:db8::1"}
+	]}`)
+	require.NoError(t, err)
+
+	cases := []struct {
+		addr   string
+		action string
+		index  int
+	}{
+		{"10.2.3.4", ActionAllow, 0},
+		{"10.1.2.3", ActionDeny, 1},
+		{"::ffff:10.1.2.3", ActionDeny, 1},
+		{"2001:db8::1", ActionDeny, 3},
+		{"192.0.2.1", ActionAllow, -1},
+	}
+	for _, tc := range cases {
+		action, index := p.MatchIP(netip.MustParseAddr(tc.addr))
+		require.Equal(t, tc.action, action, tc.addr)
+		require.Equal(t, tc.index, index, tc.addr)
+	}
+
+	action, index := DefaultDenyPolicy().MatchIP(netip.MustParseAddr("192.0.2.1"))
+	require.Equal(t, ActionDeny, action)
+	require.Equal(t, -1, index)
+}
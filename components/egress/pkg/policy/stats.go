@@ -0,0 +1,160 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// RuleStat is the number of DNS decisions made by a rule and when it last made one. The default action is
+// reported with an empty Target.
+type RuleStat struct {
+	Action  string     `json:"action"`
+	Target  string     `json:"target,omitempty"`
+	Hits    uint64     `json:"hits"`
+	LastHit *time.Time `json:"lastHit,omitempty"`
+}
+
+// RuleStatsSnapshot lists the hits of every domain rule of a policy, in egress order, counted since Since.
+// IP/CIDR rules are enforced by nftables and not counted.
+type RuleStatsSnapshot struct {
+	Since   time.Time  `json:"since"`
+	Rules   []RuleStat `json:"rules"`
+	Default RuleStat   `json:"default"`
+}
+
+type ruleKey struct {
+	action string
+	target string // lowercased, like domain matching
+}
+
+type ruleCounter struct {
+	hits    uint64
+	lastHit time.Time
+}
+
+// RuleStats counts hits per rule. Counters are keyed by action and target, so a rule keeps its counts when
+// the policy is replaced with one that still contains it.
+type RuleStats struct {
+	mu       sync.Mutex
+	since    time.Time
+	counters map[ruleKey]*ruleCounter
+}
+
+func NewRuleStats(now time.Time) *RuleStats {
+	return &RuleStats{since: now, counters: make(map[ruleKey]*ruleCounter)}
+}
+
+// Record counts a decision of rule (an index into p.Egress as returned by Match, -1 for the default action).
+func (s *RuleStats) Record(p *NetworkPolicy, rule int, now time.Time) {
+	if s == nil || p == nil {
+		return
+	}
+	key := defaultRuleKey(p)
+	if rule >= 0 && rule < len(p.Egress) {
+		key = egressRuleKey(p.Egress[rule])
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counters[key]
+	if !ok {
+		c = &ruleCounter{}
+		s.counters[key] = c
+	}
+	c.hits++
+	c.lastHit = now
+}
+
+// Snapshot returns the counts of the domain rules of p; rules listed more than once are reported once.
+func (s *RuleStats) Snapshot(p *NetworkPolicy) RuleStatsSnapshot {
+	if p == nil {
+		p = DefaultDenyPolicy()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := RuleStatsSnapshot{Since: s.since, Rules: []RuleStat{}}
+	seen := make(map[ruleKey]struct{})
+	for _, r := range p.Egress {
+		if r.targetKind != targetDomain {
+			continue
+		}
+		key := egressRuleKey(r)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out.Rules = append(out.Rules, s.stat(key, key.action, r.Target))
+	}
+	def := defaultRuleKey(p)
+	out.Default = s.stat(def, def.action, "")
+	return out
+}
+
+func (s *RuleStats) stat(key ruleKey, action, target string) RuleStat {
+	st := RuleStat{Action: action, Target: target}
+	if c, ok := s.counters[key]; ok {
+		lastHit := c.lastHit
+		st.Hits, st.LastHit = c.hits, &lastHit
+	}
+	return st
+}
+
+// Retain drops the counters of rules no longer in p.
+func (s *RuleStats) Retain(p *NetworkPolicy) {
+	if s == nil || p == nil {
+		return
+	}
+	keep := map[ruleKey]struct{}{defaultRuleKey(p): {}}
+	for _, r := range p.Egress {
+		keep[egressRuleKey(r)] = struct{}{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.counters {
+		if _, ok := keep[key]; !ok {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// Reset clears all counters and restarts counting at now.
+func (s *RuleStats) Reset(now time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.since = now
+	s.counters = make(map[ruleKey]*ruleCounter)
+}
+
+func egressRuleKey(r EgressRule) ruleKey {
+	action := r.Action
+	if action == "" {
+		action = ActionDeny
+	}
+	return ruleKey{action: action, target: strings.ToLower(strings.TrimSpace(r.Target))}
+}
+
+// defaultRuleKey has an empty target, which no egress rule can have.
+func defaultRuleKey(p *NetworkPolicy) ruleKey {
+	action := p.DefaultAction
+	if action == "" {
+		action = ActionDeny
+	}
+	return ruleKey{action: action}
+}
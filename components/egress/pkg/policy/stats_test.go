@@ -0,0 +1,73 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleStats(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	p, err := ParsePolicy(`{
+		"defaultAction":"deny",
+		"egress":[
+			{"action":"allow","target":"*.Example.com"},
+			{"action":"allow","target":"api.test"},
+			{"action":"allow","target":"10.0.0.0/8"}
+		]
+	}`)
+	require.NoError(t, err)
+
+	stats := NewRuleStats(start)
+	action, rule := p.Match("www.example.com.")
+	require.Equal(t, ActionAllow, action)
+	require.Equal(t, 0, rule)
+	stats.Record(p, rule, start.Add(time.Minute))
+	stats.Record(p, rule, start.Add(2*time.Minute))
+	action, rule = p.Match("other.test")
+	require.Equal(t, ActionDeny, action)
+	require.Equal(t, -1, rule)
+	stats.Record(p, rule, start.Add(3*time.Minute))
+
+	snap := stats.Snapshot(p)
+	require.Equal(t, start, snap.Since)
+	require.Len(t, snap.Rules, 2, "IP/CIDR rules are not counted")
+	require.Equal(t, "*.Example.com", snap.Rules[0].Target)
+	require.Equal(t, uint64(2), snap.Rules[0].Hits)
+	require.Equal(t, start.Add(2*time.Minute), *snap.Rules[0].LastHit)
+	require.Zero(t, snap.Rules[1].Hits)
+	require.Nil(t, snap.Rules[1].LastHit)
+	require.Equal(t, RuleStat{Action: ActionDeny, Hits: 1, LastHit: ptrTime(start.Add(3 * time.Minute))}, snap.Default)
+
+	// A replaced policy keeps the counts of the rules it still contains.
+	next, err := ParsePolicy(`{"defaultAction":"allow","egress":[{"action":"allow","target":"*.example.com"}]}`)
+	require.NoError(t, err)
+	stats.Retain(next)
+	snap = stats.Snapshot(next)
+	require.Equal(t, uint64(2), snap.Rules[0].Hits)
+	require.Zero(t, snap.Default.Hits)
+
+	stats.Reset(start.Add(time.Hour))
+	snap = stats.Snapshot(next)
+	require.Equal(t, start.Add(time.Hour), snap.Since)
+	require.Zero(t, snap.Rules[0].Hits)
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
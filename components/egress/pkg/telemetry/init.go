@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 
 	"github.com/alibaba/opensandbox/egress/pkg/constants"
 	inttelemetry "github.com/alibaba/opensandbox/internal/telemetry"
@@ -37,5 +38,6 @@ func Init(ctx context.Context) (shutdown func(context.Context) error, err error)
 		ServiceName:        serviceName + "-" + version.Version,
 		ResourceAttributes: attrs,
 		RegisterMetrics:    registerEgressMetrics,
+		Readers:            []sdkmetric.Reader{scrapeReader},
 	})
 }
@@ -37,6 +37,7 @@ var (
 	nftUpdates   metric.Int64Counter
 
 	lastNftRuleCount atomic.Int64
+	ruleStatsSource  atomic.Pointer[func() policy.RuleStatsSnapshot]
 )
 
 var egressSharedAttrs = sync.OnceValue(func() []attribute.KeyValue {
@@ -52,18 +53,7 @@ var egressMetricOpt = sync.OnceValue(func() metric.MeasurementOption {
 })
 
 func EgressLogFields() []slogger.Field {
-	kvs := egressSharedAttrs()
-	out := make([]slogger.Field, 0, len(kvs))
-	for _, kv := range kvs {
-		var v string
-		if kv.Value.Type() == attribute.STRING {
-			v = kv.Value.AsString()
-		} else {
-			v = kv.Value.Emit()
-		}
-		out = append(out, slogger.Field{Key: string(kv.Key), Value: v})
-	}
-	return out
+	return inttelemetry.LogFields(egressSharedAttrs())
 }
 
 func registerEgressMetrics() error {
@@ -106,6 +96,29 @@ func registerEgressMetrics() error {
 		return err
 	}
 
+	_, err = meter.Int64ObservableCounter(
+		"egress.policy.rule.hits",
+		metric.WithDescription("DNS decisions per domain rule and default action since the last stats reset"),
+		metric.WithInt64Callback(func(ctx context.Context, obs metric.Int64Observer) error {
+			src := ruleStatsSource.Load()
+			if src == nil {
+				return nil
+			}
+			snap := (*src)()
+			for _, r := range append(snap.Rules, snap.Default) {
+				attrs := append([]attribute.KeyValue{
+					attribute.String("action", r.Action),
+					attribute.String("target", r.Target),
+				}, egressSharedAttrs()...)
+				obs.Observe(int64(r.Hits), metric.WithAttributes(attrs...))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
 	_, err = meter.Int64ObservableGauge(
 		"egress.system.memory.usage_bytes",
 		metric.WithDescription("System RAM used bytes from gopsutil on Linux (non-Linux build: 0)."),
@@ -154,6 +167,11 @@ func RecordDNSDenied() {
 	policyDenied.Add(context.Background(), 1, egressMetricOpt())
 }
 
+// SetRuleStatsSource reports the rule hits returned by fn as egress.policy.rule.hits.
+func SetRuleStatsSource(fn func() policy.RuleStatsSnapshot) {
+	ruleStatsSource.Store(&fn)
+}
+
 func SetNftablesRuleCount(n int64) {
 	lastNftRuleCount.Store(n)
 }
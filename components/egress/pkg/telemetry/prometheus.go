@@ -0,0 +1,184 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// scrapeReader backs MetricsHandler; it is registered with the meter provider by Init.
+var scrapeReader = sdkmetric.NewManualReader()
+
+// MetricsHandler serves the egress metrics in the Prometheus text format, whether or not they are
+// exported over OTLP as well.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rm metricdata.ResourceMetrics
+		if err := scrapeReader.Collect(r.Context(), &rm); err != nil {
+			http.Error(w, fmt.Sprintf("metrics unavailable: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		var buf bytes.Buffer
+		writePrometheus(&buf, &rm)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// writePrometheus renders the metrics in the Prometheus text format. Names follow the OpenTelemetry
+// conventions: dots become underscores, the unit is appended and monotonic sums end in _total, so
+// egress.dns.query.duration is served as egress_dns_query_duration_seconds.
+func writePrometheus(w io.Writer, rm *metricdata.ResourceMetrics) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				writeSum(w, m, data.IsMonotonic, data.DataPoints)
+			case metricdata.Sum[float64]:
+				writeSum(w, m, data.IsMonotonic, data.DataPoints)
+			case metricdata.Gauge[int64]:
+				writeSamples(w, m, promName(m.Name, m.Unit, false), "gauge", data.DataPoints)
+			case metricdata.Gauge[float64]:
+				writeSamples(w, m, promName(m.Name, m.Unit, false), "gauge", data.DataPoints)
+			case metricdata.Histogram[int64]:
+				writeHistogram(w, m, data.DataPoints)
+			case metricdata.Histogram[float64]:
+				writeHistogram(w, m, data.DataPoints)
+			}
+		}
+	}
+}
+
+func writeSum[N int64 | float64](w io.Writer, m metricdata.Metrics, monotonic bool, points []metricdata.DataPoint[N]) {
+	if monotonic {
+		writeSamples(w, m, promName(m.Name, m.Unit, true), "counter", points)
+		return
+	}
+	writeSamples(w, m, promName(m.Name, m.Unit, false), "gauge", points)
+}
+
+func writeSamples[N int64 | float64](w io.Writer, m metricdata.Metrics, name, kind string, points []metricdata.DataPoint[N]) {
+	writeHeader(w, name, m.Description, kind)
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		lines = append(lines, name+promLabels(p.Attributes, "", "")+" "+promValue(float64(p.Value)))
+	}
+	writeLines(w, lines)
+}
+
+func writeHistogram[N int64 | float64](w io.Writer, m metricdata.Metrics, points []metricdata.HistogramDataPoint[N]) {
+	name := promName(m.Name, m.Unit, false)
+	writeHeader(w, name, m.Description, "histogram")
+	slices.SortFunc(points, func(a, b metricdata.HistogramDataPoint[N]) int {
+		return strings.Compare(promLabels(a.Attributes, "", ""), promLabels(b.Attributes, "", ""))
+	})
+	for _, p := range points {
+		cumulative := uint64(0)
+		for i, bound := range p.Bounds {
+			cumulative += p.BucketCounts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, promLabels(p.Attributes, "le", promValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, promLabels(p.Attributes, "le", "+Inf"), p.Count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, promLabels(p.Attributes, "", ""), promValue(float64(p.Sum)))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, promLabels(p.Attributes, "", ""), p.Count)
+	}
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func writeLines(w io.Writer, lines []string) {
+	slices.Sort(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// promUnits maps the units of the egress metrics to their Prometheus suffix; annotations like
+// {element} have none.
+var promUnits = map[string]string{
+	"s":  "seconds",
+	"ms": "milliseconds",
+	"By": "bytes",
+	"1":  "ratio",
+}
+
+func promName(name, unit string, counter bool) string {
+	name = sanitize(name)
+	if suffix := promUnits[unit]; suffix != "" && !strings.HasSuffix(name, "_"+suffix) {
+		name += "_" + suffix
+	}
+	if counter && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	return name
+}
+
+// promLabels renders the attributes as a label set, with the extra label when its name is set.
+func promLabels(attrs attribute.Set, extraName, extraValue string) string {
+	var labels []string
+	for _, kv := range attrs.ToSlice() {
+		labels = append(labels, sanitize(string(kv.Key))+"="+quoteLabel(kv.Value.Emit()))
+	}
+	if extraName != "" {
+		labels = append(labels, extraName+"="+quoteLabel(extraValue))
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	m := provider.Meter("test")
+
+	denied, err := m.Int64Counter("egress.policy.denied_total", metric.WithDescription("DNS policy denials"))
+	require.NoError(t, err)
+	denied.Add(ctx, 2, metric.WithAttributes(attribute.String("sandbox_id", "sbx\"1")))
+	dur, err := m.Float64Histogram("egress.dns.query.duration", metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.1))
+	require.NoError(t, err)
+	dur.Record(ctx, 0.05)
+	dur.Record(ctx, 0.5)
+	_, err = m.Int64ObservableGauge("egress.nftables.rules.count", metric.WithUnit("{element}"),
+		metric.WithInt64Callback(func(_ context.Context, obs metric.Int64Observer) error {
+			obs.Observe(7)
+			return nil
+		}))
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	var buf bytes.Buffer
+	writePrometheus(&buf, &rm)
+	out := buf.String()
+
+	assert.Contains(t, out, "# HELP egress_policy_denied_total DNS policy denials\n# TYPE egress_policy_denied_total counter\n")
+	assert.Contains(t, out, "egress_policy_denied_total{sandbox_id=\"sbx\\\"1\"} 2\n")
+	assert.Contains(t, out, "# TYPE egress_dns_query_duration_seconds histogram\n")
+	assert.Contains(t, out, "egress_dns_query_duration_seconds_bucket{le=\"0.01\"} 0\n")
+	assert.Contains(t, out, "egress_dns_query_duration_seconds_bucket{le=\"0.1\"} 1\n")
+	assert.Contains(t, out, "egress_dns_query_duration_seconds_bucket{le=\"+Inf\"} 2\n")
+	assert.Contains(t, out, "egress_dns_query_duration_seconds_sum 0.55\n")
+	assert.Contains(t, out, "egress_dns_query_duration_seconds_count 2\n")
+	assert.Contains(t, out, "# TYPE egress_nftables_rules_count gauge\negress_nftables_rules_count 7\n")
+}
+
+func TestPromName(t *testing.T) {
+	assert.Equal(t, "egress_nftables_updates_count_total", promName("egress.nftables.updates.count", "", true))
+	assert.Equal(t, "egress_system_memory_usage_bytes", promName("egress.system.memory.usage_bytes", "By", false))
+	assert.Equal(t, "egress_system_cpu_utilization_ratio", promName("egress.system.cpu.utilization", "1", false))
+	assert.Equal(t, "egress_policy_rule_hits_total", promName("egress.policy.rule.hits", "", true))
+}
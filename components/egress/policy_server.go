@@ -31,6 +31,8 @@ import (
 	"github.com/alibaba/opensandbox/egress/pkg/mitmproxy"
 	"github.com/alibaba/opensandbox/egress/pkg/nftables"
 	"github.com/alibaba/opensandbox/egress/pkg/policy"
+	"github.com/alibaba/opensandbox/internal/k8sauth"
+	slogger "github.com/alibaba/opensandbox/internal/logger"
 	"github.com/alibaba/opensandbox/internal/safego"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -39,6 +41,12 @@ type policyUpdater interface {
 	CurrentPolicy() *policy.NetworkPolicy
 	UpdatePolicy(*policy.NetworkPolicy)
 	UpdateAlwaysRules(alwaysDeny, alwaysAllow []policy.EgressRule)
+	RuleStats() policy.RuleStatsSnapshot
+	ResetRuleStats()
+	// Multi-policy mode: the policies of the identities, addressed by ?identity=<name>.
+	IdentityPolicy(name string) (*policy.NetworkPolicy, bool)
+	UpdateIdentityPolicy(name string, p *policy.NetworkPolicy) bool
+	IdentityRuleStats(name string) (policy.RuleStatsSnapshot, bool)
 }
 
 // nftApplier: static allow/deny sets plus dynamic DNS-learned entries; teardown on shutdown.
@@ -48,9 +56,10 @@ type nftApplier interface {
 	RemoveEnforcement(context.Context) error
 }
 
-// startPolicyServer: runtime POST/GET /policy, GET /healthz. nameserverIPs are merged into every nft
+// startPolicyServer: runtime POST/GET /policy, GET/DELETE /policy/stats, POST /policy/test, GET /healthz; ?identity=<name> selects the
+// policy of an identity in multi-policy mode. nameserverIPs are merged into every nft
 // static apply so the pod’s resolv / private DNS still works alongside user egress rules.
-func startPolicyServer(proxy policyUpdater, nft nftApplier, enforcementMode string, addr string, token string, nameserverIPs []netip.Addr, policyFile string, alwaysDeny, alwaysAllow []policy.EgressRule, mitmGate *mitmproxy.HealthGate) (*http.Server, error) {
+func startPolicyServer(proxy policyUpdater, nft nftApplier, enforcementMode string, addr string, token string, auth *k8sauth.Authenticator, nameserverIPs []netip.Addr, policyFile string, alwaysDeny, alwaysAllow []policy.EgressRule, mitmGate *mitmproxy.HealthGate) (*http.Server, error) {
 	maxEgressRules := maxEgressRulesFromEnv()
 	if maxEgressRules > 0 {
 		log.Infof("policy API: max egress rules per policy (POST/PATCH) = %d (set %s=0 to disable)", maxEgressRules, constants.EnvMaxEgressRules)
@@ -61,6 +70,7 @@ func startPolicyServer(proxy policyUpdater, nft nftApplier, enforcementMode stri
 		proxy:            proxy,
 		nft:              nft,
 		token:            token,
+		auth:             auth,
 		enforcementMode:  enforcementMode,
 		nameserverIPs:    nameserverIPs,
 		policyFile:       strings.TrimSpace(policyFile),
@@ -71,6 +81,9 @@ func startPolicyServer(proxy policyUpdater, nft nftApplier, enforcementMode stri
 	handler.setAlwaysRules(alwaysDeny, alwaysAllow)
 
 	mux.HandleFunc("/policy", handler.handlePolicy)
+	mux.HandleFunc("/policy/stats", handler.handlePolicyStats)
+	mux.HandleFunc("/policy/test", handler.handlePolicyTest)
+	mux.HandleFunc("/loglevel", handler.handleLogLevel)
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		if mitmGate != nil && mitmGate.MitmPending() {
 			w.WriteHeader(http.StatusServiceUnavailable)
@@ -117,6 +130,7 @@ type policyServer struct {
 	nft             nftApplier
 	server          *http.Server
 	token           string
+	auth            *k8sauth.Authenticator // if set, projected service-account bearer tokens are accepted too
 	enforcementMode string
 	nameserverIPs   []netip.Addr
 	policyFile      string     // if set, successful /policy changes persist (truncate+write+fsync)
@@ -132,39 +146,222 @@ type policyStatusResponse struct {
 	Mode            string `json:"mode,omitempty"`
 	EnforcementMode string `json:"enforcementMode,omitempty"`
 	Reason          string `json:"reason,omitempty"`
+	Identity        string `json:"identity,omitempty"`
 	Policy          any    `json:"policy,omitempty"`
 }
 
 func (s *policyServer) handlePolicy(w http.ResponseWriter, r *http.Request) {
-	if !s.authorize(r) {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	if status, ok := s.authorize(r); !ok {
+		http.Error(w, strings.ToLower(http.StatusText(status)), status)
+		return
+	}
+	identity := r.URL.Query().Get("identity")
+	if _, ok := s.currentPolicy(identity); !ok {
+		http.Error(w, fmt.Sprintf("unknown identity %q", identity), http.StatusNotFound)
 		return
 	}
 	switch r.Method {
 	case http.MethodGet:
-		s.handleGet(w)
+		s.handleGet(w, identity)
 	case http.MethodPost, http.MethodPut:
-		s.handlePost(w, r)
+		s.handlePost(w, r, identity)
 	case http.MethodPatch:
-		s.handlePatch(w, r)
+		s.handlePatch(w, r, identity)
 	default:
 		w.Header().Set("Allow", "GET, POST, PUT, PATCH")
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func (s *policyServer) handleGet(w http.ResponseWriter) {
-	current := s.proxy.CurrentPolicy()
+// handlePolicyStats reports (GET) or resets (DELETE) the per-rule hit counters of the DNS proxy.
+func (s *policyServer) handlePolicyStats(w http.ResponseWriter, r *http.Request) {
+	if status, ok := s.authorize(r); !ok {
+		http.Error(w, strings.ToLower(http.StatusText(status)), status)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if identity := r.URL.Query().Get("identity"); identity != "" {
+			stats, ok := s.proxy.IdentityRuleStats(identity)
+			if !ok {
+				http.Error(w, fmt.Sprintf("unknown identity %q", identity), http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, stats)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.proxy.RuleStats())
+	case http.MethodDelete:
+		s.proxy.ResetRuleStats()
+		log.Infof("policy API: rule stats reset by %s", r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+type policyTestRequest struct {
+	Targets []string `json:"targets"`
+	// Policy is a candidate policy tested instead of the loaded one; absent or null tests the loaded policy.
+	Policy json.RawMessage `json:"policy,omitempty"`
+}
+
+type policyTestResult struct {
+	Target string `json:"target"`
+	Action string `json:"action"`
+	// Source is what decided: policy (RuleIndex in its egress), alwaysDeny, alwaysAllow, nameserver or default.
+	Source    string             `json:"source"`
+	Rule      *policy.EgressRule `json:"rule,omitempty"`
+	RuleIndex *int               `json:"ruleIndex,omitempty"`
+	// Enforced is false for IPs when IP/CIDR rules are not enforced (no dns+nft mode).
+	Enforced bool `json:"enforced"`
+}
+
+type policyTestResponse struct {
+	Status          string             `json:"status"`
+	Policy          string             `json:"policy"` // current or candidate
+	EnforcementMode string             `json:"enforcementMode,omitempty"`
+	Identity        string             `json:"identity,omitempty"`
+	Results         []policyTestResult `json:"results"`
+}
+
+// handlePolicyTest returns the verdict for each domain or IP of the body under the loaded policy, or under
+// a candidate policy of the body, with the always rules applied. Nothing is changed.
+func (s *policyServer) handlePolicyTest(w http.ResponseWriter, r *http.Request) {
+	if status, ok := s.authorize(r); !ok {
+		http.Error(w, strings.ToLower(http.StatusText(status)), status)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+	identity := r.URL.Query().Get("identity")
+	current, ok := s.currentPolicy(identity)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown identity %q", identity), http.StatusNotFound)
+		return
+	}
+
+	raw, err := readPolicyRequestBody(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+	var req policyTestRequest
+	if err := json.Unmarshal([]byte(raw), &req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Targets) == 0 {
+		http.Error(w, "invalid request: no targets", http.StatusBadRequest)
+		return
+	}
+	tested, source := current, "current"
+	if candidate := strings.TrimSpace(string(req.Policy)); candidate != "" && candidate != "null" {
+		if tested, err = policy.ParsePolicy(candidate); err != nil {
+			http.Error(w, fmt.Sprintf("invalid policy: %v", err), http.StatusBadRequest)
+			return
+		}
+		source = "candidate"
+	}
+	if tested == nil {
+		tested = policy.DefaultDenyPolicy()
+	}
+
+	// Same order as the DNS proxy and nftables: always deny, always allow, the policy, then the
+	// nameservers nftables lets through for the pod policy.
+	alwaysDeny, alwaysAllow := s.currentAlwaysRules()
+	effective := policy.MergeAlwaysOverlay(tested, alwaysDeny, alwaysAllow)
+	if identity == "" && s.nft != nil {
+		effective = effective.WithExtraAllowIPs(s.nameserverIPs)
+	}
+	results := make([]policyTestResult, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		target = strings.TrimSpace(target)
+		result := policyTestResult{Target: target, Enforced: true}
+		index := -1
+		if addr, err := netip.ParseAddr(target); err == nil {
+			result.Action, index = effective.MatchIP(addr)
+			result.Enforced = s.nft != nil && identity == ""
+		} else if target == "" || strings.ContainsAny(target, "/: \t") {
+			http.Error(w, fmt.Sprintf("invalid target %q: want a domain or IP", target), http.StatusBadRequest)
+			return
+		} else {
+			result.Action, index = effective.Match(target)
+		}
+
+		userStart := len(alwaysDeny) + len(alwaysAllow)
+		switch {
+		case index < 0:
+			result.Source = "default"
+		case index < len(alwaysDeny):
+			result.Source = "alwaysDeny"
+		case index < userStart:
+			result.Source = "alwaysAllow"
+		case index < userStart+len(tested.Egress):
+			result.Source = "policy"
+			ruleIndex := index - userStart
+			result.RuleIndex = &ruleIndex
+		default:
+			result.Source = "nameserver"
+		}
+		if index >= 0 {
+			rule := effective.Egress[index]
+			result.Rule = &rule
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, policyTestResponse{
+		Status:          "ok",
+		Policy:          source,
+		EnforcementMode: s.enforcementMode,
+		Identity:        identity,
+		Results:         results,
+	})
+}
+
+// handleLogLevel reports (GET) or changes (PUT {"level":"debug"}) the log level at runtime.
+func (s *policyServer) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	if status, ok := s.authorize(r); !ok {
+		http.Error(w, strings.ToLower(http.StatusText(status)), status)
+		return
+	}
+	h, ok := slogger.LevelHandler(log.Logger)
+	if !ok {
+		http.Error(w, "log level is not adjustable", http.StatusNotImplemented)
+		return
+	}
+	if r.Method == http.MethodPut {
+		log.Infof("log level change requested by %s", r.RemoteAddr)
+	}
+	h.ServeHTTP(w, r)
+}
+
+// currentPolicy is the user policy of the pod (identity "") or of an identity; false for unknown identities.
+func (s *policyServer) currentPolicy(identity string) (*policy.NetworkPolicy, bool) {
+	if identity == "" {
+		return s.proxy.CurrentPolicy(), true
+	}
+	return s.proxy.IdentityPolicy(identity)
+}
+
+func (s *policyServer) handleGet(w http.ResponseWriter, identity string) {
+	current, _ := s.currentPolicy(identity)
 	mode := modeFromPolicy(current)
 	writeJSON(w, http.StatusOK, policyStatusResponse{
 		Status:          "ok",
 		Mode:            mode,
 		EnforcementMode: s.enforcementMode,
+		Identity:        identity,
 		Policy:          current,
 	})
 }
 
-func (s *policyServer) handlePost(w http.ResponseWriter, r *http.Request) {
+func (s *policyServer) handlePost(w http.ResponseWriter, r *http.Request, identity string) {
 	defer r.Body.Close()
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -179,15 +376,16 @@ func (s *policyServer) handlePost(w http.ResponseWriter, r *http.Request) {
 	if raw == "" {
 		log.Infof("policy API: reset to default deny-all")
 		def := policy.DefaultDenyPolicy()
-		if !s.commitPolicy(r.Context(), w, def, "reset") {
+		if !s.commitPolicy(r.Context(), w, def, "reset", identity) {
 			return
 		}
 		logEgressUpdated(def.DefaultAction, nil)
 		log.Infof("policy API: proxy and nftables updated to deny_all")
 		writeJSON(w, http.StatusOK, policyStatusResponse{
-			Status: "ok",
-			Mode:   "deny_all",
-			Reason: "policy reset to default deny-all",
+			Status:   "ok",
+			Mode:     "deny_all",
+			Reason:   "policy reset to default deny-all",
+			Identity: identity,
 		})
 		return
 	}
@@ -204,7 +402,7 @@ func (s *policyServer) handlePost(w http.ResponseWriter, r *http.Request) {
 
 	mode := modeFromPolicy(pol)
 	log.Infof("policy API: updating policy to mode=%s, enforcement=%s", mode, s.enforcementMode)
-	if !s.commitPolicy(r.Context(), w, pol, "post") {
+	if !s.commitPolicy(r.Context(), w, pol, "post", identity) {
 		return
 	}
 	logEgressUpdated(pol.DefaultAction, pol.Egress)
@@ -213,10 +411,11 @@ func (s *policyServer) handlePost(w http.ResponseWriter, r *http.Request) {
 		Status:          "ok",
 		Mode:            mode,
 		EnforcementMode: s.enforcementMode,
+		Identity:        identity,
 	})
 }
 
-func (s *policyServer) handlePatch(w http.ResponseWriter, r *http.Request) {
+func (s *policyServer) handlePatch(w http.ResponseWriter, r *http.Request, identity string) {
 	defer r.Body.Close()
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -244,7 +443,8 @@ func (s *policyServer) handlePatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newPolicy, err := patchMergedPolicy(s.proxy.CurrentPolicy(), patchRules)
+	current, _ := s.currentPolicy(identity)
+	newPolicy, err := patchMergedPolicy(current, patchRules)
 	if err != nil {
 		logEgressUpdateFailedWarn(fmt.Sprintf("invalid merged policy: %v", err))
 		http.Error(w, fmt.Sprintf("invalid merged policy: %v", err), http.StatusBadRequest)
@@ -256,7 +456,7 @@ func (s *policyServer) handlePatch(w http.ResponseWriter, r *http.Request) {
 
 	mode := modeFromPolicy(newPolicy)
 	log.Infof("policy API: patching policy with %d new rule(s), mode=%s, enforcement=%s", len(patchRules), mode, s.enforcementMode)
-	if !s.commitPolicy(r.Context(), w, newPolicy, "patch") {
+	if !s.commitPolicy(r.Context(), w, newPolicy, "patch", identity) {
 		return
 	}
 	logEgressUpdated(newPolicy.DefaultAction, patchRules)
@@ -265,12 +465,27 @@ func (s *policyServer) handlePatch(w http.ResponseWriter, r *http.Request) {
 		Status:          "ok",
 		Mode:            mode,
 		EnforcementMode: s.enforcementMode,
+		Identity:        identity,
 	})
 }
 
 // commitPolicy applies one logical change: optional disk persist → merge always file rules → nft
 // static (with nameserver allow-IPs) → then update in-memory user policy (POST/PATCH/GET view).
-func (s *policyServer) commitPolicy(ctx context.Context, w http.ResponseWriter, pol *policy.NetworkPolicy, op string) bool {
+// Identity policies decide DNS answers only: they are neither persisted nor applied to nft, whose static
+// sets belong to the pod policy.
+func (s *policyServer) commitPolicy(ctx context.Context, w http.ResponseWriter, pol *policy.NetworkPolicy, op string, identity string) bool {
+	if identity != "" {
+		if s.nft != nil {
+			if allowV4, allowV6, denyV4, denyV6 := pol.StaticIPSets(); len(allowV4)+len(allowV6)+len(denyV4)+len(denyV6) > 0 || pol.BlocksUDP() {
+				log.Warnf("policy API: IP/CIDR rules and udp of identity %q are not enforced by nftables", identity)
+			}
+		}
+		if !s.proxy.UpdateIdentityPolicy(identity, pol) {
+			http.Error(w, fmt.Sprintf("unknown identity %q", identity), http.StatusNotFound)
+			return false
+		}
+		return true
+	}
 	if err := s.persistPolicy(pol); err != nil {
 		logEgressUpdateFailedError(fmt.Sprintf("persist policy: %v", err))
 		log.Errorf("policy API: persist policy failed: %v", err)
@@ -286,6 +501,8 @@ func (s *policyServer) commitPolicy(ctx context.Context, w http.ResponseWriter,
 			http.Error(w, fmt.Sprintf("failed to apply nftables policy: %v", err), http.StatusInternalServerError)
 			return false
 		}
+	} else if pol.BlocksUDP() {
+		log.Warnf("policy API: udp.block is not enforced without dns+nft mode")
 	}
 	s.proxy.UpdatePolicy(pol)
 	return true
@@ -347,18 +564,32 @@ func (s *policyServer) currentAlwaysRules() (deny, allow []policy.EgressRule) {
 	return s.alwaysLoader.CurrentRules()
 }
 
-func (s *policyServer) authorize(r *http.Request) bool {
-	if s.token == "" {
-		return true
+// authorize accepts the shared token header or, when auth is set, a projected service-account
+// bearer token. On rejection it returns the status to answer with.
+func (s *policyServer) authorize(r *http.Request) (int, bool) {
+	if s.token == "" && s.auth == nil {
+		return http.StatusOK, true
 	}
-	provided := r.Header.Get(constants.EgressAuthTokenHeader)
-	if provided == "" {
-		return false
+	if s.token != "" {
+		provided := r.Header.Get(constants.EgressAuthTokenHeader)
+		if len(provided) == len(s.token) && subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) == 1 {
+			return http.StatusOK, true
+		}
 	}
-	if len(provided) != len(s.token) {
-		return false
+	if s.auth == nil {
+		return http.StatusUnauthorized, false
+	}
+	if _, err := s.auth.Authenticate(r); err != nil {
+		if errors.Is(err, k8sauth.ErrForbidden) {
+			log.Warnf("policy API: rejected service account token: %v", err)
+			return http.StatusForbidden, false
+		}
+		if !errors.Is(err, k8sauth.ErrMissingToken) {
+			log.Warnf("policy API: rejected service account token: %v", err)
+		}
+		return http.StatusUnauthorized, false
 	}
-	return subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) == 1
+	return http.StatusOK, true
 }
 
 func (s *policyServer) enforceEgressRuleLimit(w http.ResponseWriter, egressCount int) bool {
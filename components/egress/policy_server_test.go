@@ -16,17 +16,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/alibaba/opensandbox/egress/pkg/constants"
+	"github.com/alibaba/opensandbox/egress/pkg/log"
 	"github.com/alibaba/opensandbox/egress/pkg/nftables"
 	"github.com/alibaba/opensandbox/egress/pkg/policy"
+	"github.com/alibaba/opensandbox/internal/k8sauth"
+	slogger "github.com/alibaba/opensandbox/internal/logger"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,6 +39,10 @@ type stubProxy struct {
 	updated *policy.NetworkPolicy
 	deny    []policy.EgressRule
 	allow   []policy.EgressRule
+	stats   policy.RuleStatsSnapshot
+	resets  int
+
+	identities map[string]*policy.NetworkPolicy
 }
 
 func (s *stubProxy) CurrentPolicy() *policy.NetworkPolicy {
@@ -49,6 +58,32 @@ func (s *stubProxy) UpdateAlwaysRules(alwaysDeny, alwaysAllow []policy.EgressRul
 	s.allow = append([]policy.EgressRule(nil), alwaysAllow...)
 }
 
+func (s *stubProxy) RuleStats() policy.RuleStatsSnapshot {
+	return s.stats
+}
+
+func (s *stubProxy) ResetRuleStats() {
+	s.resets++
+}
+
+func (s *stubProxy) IdentityPolicy(name string) (*policy.NetworkPolicy, bool) {
+	p, ok := s.identities[name]
+	return p, ok
+}
+
+func (s *stubProxy) UpdateIdentityPolicy(name string, p *policy.NetworkPolicy) bool {
+	if _, ok := s.identities[name]; !ok {
+		return false
+	}
+	s.identities[name] = p
+	return true
+}
+
+func (s *stubProxy) IdentityRuleStats(name string) (policy.RuleStatsSnapshot, bool) {
+	_, ok := s.identities[name]
+	return s.stats, ok
+}
+
 type stubNft struct {
 	err     error
 	calls   int
@@ -176,6 +211,22 @@ func TestHandlePatch_MergesAndApplies(t *testing.T) {
 	require.Equal(t, "*.example.com", proxy.updated.Egress[2].Target, "base wildcard rule target mismatch")
 }
 
+func TestHandlePatch_KeepsUDPPolicy(t *testing.T) {
+	initial, err := policy.ParsePolicy(`{"defaultAction":"deny","egress":[],"udp":{"block":true,"allow":[{"target":"10.0.0.5","ports":[3478]}]}}`)
+	require.NoError(t, err)
+	proxy := &stubProxy{updated: initial}
+	srv := &policyServer{proxy: proxy, nft: &stubNft{}, enforcementMode: "dns+nft"}
+
+	req := httptest.NewRequest(http.MethodPatch, "/policy", strings.NewReader(`[{"action":"allow","target":"example.com"}]`))
+	w := httptest.NewRecorder()
+	srv.handlePolicy(w, req)
+
+	require.Equal(t, http.StatusOK, w.Result().StatusCode, "expected 200")
+	require.True(t, proxy.updated.BlocksUDP(), "udp block should be preserved")
+	require.Len(t, proxy.updated.UDP.Allow, 1)
+	require.Equal(t, "10.0.0.5/32", proxy.updated.UDP.Allow[0].Prefix().String())
+}
+
 func TestHandlePatch_DomainCaseOverride(t *testing.T) {
 	initial := &policy.NetworkPolicy{
 		DefaultAction: policy.ActionDeny,
@@ -261,3 +312,193 @@ func TestHandlePost_RejectsWhenOverMaxEgressRules(t *testing.T) {
 	require.Nil(t, proxy.updated, "policy should not update")
 	require.Equal(t, 0, nft.calls, "nft should not apply")
 }
+
+type stubVerifier map[string]*k8sauth.Identity
+
+func (s stubVerifier) Verify(_ context.Context, token string) (*k8sauth.Identity, error) {
+	if id, ok := s[token]; ok {
+		return id, nil
+	}
+	return nil, errors.New("token rejected")
+}
+
+func TestHandlePolicy_Authorize(t *testing.T) {
+	auth := k8sauth.NewAuthenticator(stubVerifier{
+		"controller": {Username: "system:serviceaccount:opensandbox-system:controller"},
+		"other":      {Username: "system:serviceaccount:default:other"},
+	}, []string{"system:serviceaccount:opensandbox-system:controller"})
+	srv := &policyServer{proxy: &stubProxy{}, enforcementMode: "dns", token: "shared", auth: auth}
+
+	cases := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{name: "shared token", header: map[string]string{constants.EgressAuthTokenHeader: "shared"}, want: http.StatusOK},
+		{name: "service account token", header: map[string]string{"Authorization": "Bearer controller"}, want: http.StatusOK},
+		{name: "disallowed service account", header: map[string]string{"Authorization": "Bearer other"}, want: http.StatusForbidden},
+		{name: "invalid token", header: map[string]string{"Authorization": "Bearer forged"}, want: http.StatusUnauthorized},
+		{name: "wrong shared token", header: map[string]string{constants.EgressAuthTokenHeader: "guess"}, want: http.StatusUnauthorized},
+		{name: "no credentials", want: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/policy", nil)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			srv.handlePolicy(w, req)
+			require.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestHandleLogLevel(t *testing.T) {
+	prev := log.Logger
+	t.Cleanup(func() { log.Logger = prev })
+	log.Logger = slogger.MustNew(slogger.Config{Level: "info"})
+	srv := &policyServer{proxy: &stubProxy{}, enforcementMode: "dns", token: "shared"}
+
+	req := httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`))
+	w := httptest.NewRecorder()
+	srv.handleLogLevel(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set(constants.EgressAuthTokenHeader, "shared")
+	w = httptest.NewRecorder()
+	srv.handleLogLevel(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/loglevel", nil)
+	req.Header.Set(constants.EgressAuthTokenHeader, "shared")
+	w = httptest.NewRecorder()
+	srv.handleLogLevel(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"debug"`)
+}
+
+func TestHandlePolicyStats(t *testing.T) {
+	proxy := &stubProxy{stats: policy.RuleStatsSnapshot{
+		Rules:   []policy.RuleStat{{Action: policy.ActionAllow, Target: "example.com", Hits: 3}},
+		Default: policy.RuleStat{Action: policy.ActionDeny, Hits: 1},
+	}}
+	srv := &policyServer{proxy: proxy}
+
+	w := httptest.NewRecorder()
+	srv.handlePolicyStats(w, httptest.NewRequest(http.MethodGet, "/policy/stats", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `{"action":"allow","target":"example.com","hits":3}`)
+	require.Contains(t, w.Body.String(), `"default":{"action":"deny","hits":1}`)
+
+	w = httptest.NewRecorder()
+	srv.handlePolicyStats(w, httptest.NewRequest(http.MethodDelete, "/policy/stats", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, 1, proxy.resets)
+
+	w = httptest.NewRecorder()
+	srv.handlePolicyStats(w, httptest.NewRequest(http.MethodPost, "/policy/stats", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestHandlePolicy_Identity(t *testing.T) {
+	proxy := &stubProxy{identities: map[string]*policy.NetworkPolicy{"browser": policy.DefaultDenyPolicy()}}
+	nft := &stubNft{}
+	srv := &policyServer{proxy: proxy, nft: nft, enforcementMode: "dns+nft", policyFile: t.TempDir() + "/policy.json"}
+
+	body := `{"defaultAction":"deny","egress":[{"action":"allow","target":"example.com"}]}`
+	w := httptest.NewRecorder()
+	srv.handlePolicy(w, httptest.NewRequest(http.MethodPost, "/policy?identity=browser", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Zero(t, nft.calls, "identity policies are not applied to nft")
+	require.Nil(t, proxy.updated, "the pod policy is unchanged")
+	require.Len(t, proxy.identities["browser"].Egress, 1)
+	_, err := os.Stat(srv.policyFile)
+	require.True(t, os.IsNotExist(err), "identity policies are not persisted")
+
+	w = httptest.NewRecorder()
+	srv.handlePolicy(w, httptest.NewRequest(http.MethodPatch, "/policy?identity=browser", strings.NewReader(`[{"action":"allow","target":"pypi.org"}]`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, proxy.identities["browser"].Egress, 2)
+
+	w = httptest.NewRecorder()
+	srv.handlePolicy(w, httptest.NewRequest(http.MethodGet, "/policy?identity=browser", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"identity":"browser"`)
+	require.Contains(t, w.Body.String(), "pypi.org")
+
+	w = httptest.NewRecorder()
+	srv.handlePolicy(w, httptest.NewRequest(http.MethodGet, "/policy?identity=unknown", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	srv.handlePolicyStats(w, httptest.NewRequest(http.MethodGet, "/policy/stats?identity=browser", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	srv.handlePolicyStats(w, httptest.NewRequest(http.MethodGet, "/policy/stats?identity=unknown", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlePolicyTest(t *testing.T) {
+	current, err := policy.ParsePolicy(`{"defaultAction":"deny","egress":[{"action":"allow","target":"*.example.com"},{"action":"allow","target":"10.0.0.0/8"}]}`)
+	require.NoError(t, err)
+	deny, err := policy.ParseValidatedEgressRule(policy.ActionDeny, "bad.example.com")
+	require.NoError(t, err)
+	proxy := &stubProxy{updated: current}
+	nft := &stubNft{}
+	srv := &policyServer{proxy: proxy, nft: nft, enforcementMode: "dns+nft", nameserverIPs: []netip.Addr{netip.MustParseAddr("192.0.2.53")}}
+	srv.setAlwaysRules([]policy.EgressRule{deny}, nil)
+
+	test := func(body string) (int, policyTestResponse) {
+		w := httptest.NewRecorder()
+		srv.handlePolicyTest(w, httptest.NewRequest(http.MethodPost, "/policy/test", strings.NewReader(body)))
+		var resp policyTestResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	code, resp := test(`{"targets":["api.example.com","bad.example.com","other.org","10.1.2.3","192.0.2.53"]}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "current", resp.Policy)
+	require.Len(t, resp.Results, 5)
+	require.Equal(t, policy.ActionAllow, resp.Results[0].Action)
+	require.Equal(t, "policy", resp.Results[0].Source)
+	require.Equal(t, 0, *resp.Results[0].RuleIndex)
+	require.Equal(t, "*.example.com", resp.Results[0].Rule.Target)
+	require.Equal(t, policy.ActionDeny, resp.Results[1].Action)
+	require.Equal(t, "alwaysDeny", resp.Results[1].Source)
+	require.Equal(t, policy.ActionDeny, resp.Results[2].Action)
+	require.Equal(t, "default", resp.Results[2].Source)
+	require.Nil(t, resp.Results[2].Rule)
+	require.Equal(t, policy.ActionAllow, resp.Results[3].Action)
+	require.Equal(t, 1, *resp.Results[3].RuleIndex)
+	require.True(t, resp.Results[3].Enforced)
+	require.Equal(t, "nameserver", resp.Results[4].Source)
+
+	code, resp = test(`{"targets":["api.example.com","other.org"],"policy":{"defaultAction":"allow","egress":[{"action":"deny","target":"api.example.com"}]}}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "candidate", resp.Policy)
+	require.Equal(t, policy.ActionDeny, resp.Results[0].Action)
+	require.Equal(t, policy.ActionAllow, resp.Results[1].Action)
+	require.Same(t, current, proxy.updated, "testing a candidate does not change the policy")
+	require.Zero(t, nft.calls)
+
+	for _, body := range []string{`{"targets":[]}`, `{"targets":["10.0.0.0/8"]}`, `{"targets":["https://example.com"]}`, `{"targets":["a.com"],"policy":{"egress":[{"action":"maybe","target":"a.com"}]}}`, `not json`} {
+		code, _ = test(body)
+		require.Equal(t, http.StatusBadRequest, code, body)
+	}
+
+	w := httptest.NewRecorder()
+	srv.handlePolicyTest(w, httptest.NewRequest(http.MethodGet, "/policy/test", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	w = httptest.NewRecorder()
+	srv.handlePolicyTest(w, httptest.NewRequest(http.MethodPost, "/policy/test?identity=unknown", strings.NewReader(`{"targets":["a.com"]}`)))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	srv.nft = nil
+	_, resp = test(`{"targets":["10.1.2.3"]}`)
+	require.False(t, resp.Results[0].Enforced, "IP rules are not enforced in dns mode")
+}
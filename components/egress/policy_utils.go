@@ -49,6 +49,7 @@ func patchMergedPolicy(base *policy.NetworkPolicy, patchRules []policy.EgressRul
 	rawMerged, err := json.Marshal(policy.NetworkPolicy{
 		DefaultAction: baseCopy.DefaultAction,
 		Egress:        merged,
+		UDP:           baseCopy.UDP,
 	})
 	if err != nil {
 		return nil, err
@@ -34,7 +34,7 @@ const (
 	defaultMitmShutdownTimeout   = 5 * time.Second
 )
 
-func waitForShutdown(ctx context.Context, proxy *dnsproxy.Proxy, policySrv *http.Server, exemptDst []netip.Addr, applier nftApplier, mitm *mitmTransparent) {
+func waitForShutdown(ctx context.Context, proxy *dnsproxy.Proxy, policySrv, adminSrv *http.Server, exemptDst []netip.Addr, applier nftApplier, mitm *mitmTransparent) {
 	<-ctx.Done()
 	log.Infof("received shutdown signal; beginning graceful shutdown")
 
@@ -46,6 +46,11 @@ func waitForShutdown(ctx context.Context, proxy *dnsproxy.Proxy, policySrv *http
 			log.Errorf("policy server shutdown error: %v", err)
 		}
 	}
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(policyShutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("admin server shutdown error: %v", err)
+		}
+	}
 	if err := proxy.Shutdown(); err != nil {
 		log.Errorf("dns proxy shutdown error: %v", err)
 	}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/alibaba/opensandbox/internal/k8sauth"
 	"github.com/alibaba/opensandbox/internal/version"
 
 	_ "github.com/alibaba/opensandbox/internal/safego"
@@ -42,6 +43,7 @@ func main() {
 	flag.InitFlags()
 
 	log.Init(flag.ServerLogLevel)
+	log.AddFields(telemetry.ExecdLogFields()...)
 	if clone3Compat {
 		log.Warn("execd running with clone3 compatibility (seccomp returns ENOSYS for clone3)")
 	}
@@ -59,7 +61,16 @@ func main() {
 	}
 
 	controller.InitCodeRunner()
-	engine := web.NewRouter(flag.ServerAccessToken)
+	if err := controller.InitSessionRecording(); err != nil {
+		log.Error("failed to set up session recording: %v", err)
+		os.Exit(1)
+	}
+	auth, err := k8sauth.New(k8sauth.ConfigFromEnv())
+	if err != nil {
+		log.Error("failed to set up service account token auth: %v", err)
+		os.Exit(1)
+	}
+	engine := web.NewRouter(flag.ServerAccessToken, auth)
 	addr := fmt.Sprintf(":%d", flag.ServerPort)
 	listener, err := net.Listen("tcp4", addr)
 	if err != nil {
@@ -32,6 +32,10 @@ var (
 	// ServerAccessToken guards API entrypoints when set.
 	ServerAccessToken string
 
+	// CallbackSecret signs the completion callbacks of asynchronous executions; the access token is used
+	// when unset.
+	CallbackSecret string
+
 	// ApiGracefulShutdownTimeout waits before tearing down SSE streams.
 	ApiGracefulShutdownTimeout time.Duration
 
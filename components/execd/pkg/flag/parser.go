@@ -29,6 +29,7 @@ const (
 	jupyterTokenEnv            = "JUPYTER_TOKEN"
 	gracefulShutdownTimeoutEnv = "EXECD_API_GRACE_SHUTDOWN"
 	jupyterIdlePollIntervalEnv = "EXECD_JUPYTER_IDLE_POLL_INTERVAL"
+	callbackSecretEnv          = "EXECD_CALLBACK_SECRET"
 )
 
 // InitFlags registers CLI flags and env overrides.
@@ -52,12 +53,15 @@ func InitFlags() {
 		JupyterServerToken = jupyterTokenFromEnv
 	}
 
+	CallbackSecret = os.Getenv(callbackSecretEnv)
+
 	// Then define flags with current values as defaults
 	flag.StringVar(&JupyterServerHost, "jupyter-host", JupyterServerHost, "Jupyter server host address (e.g., http://localhost, http://192.168.1.100)")
 	flag.StringVar(&JupyterServerToken, "jupyter-token", JupyterServerToken, "Jupyter server authentication token")
 	flag.IntVar(&ServerPort, "port", ServerPort, "Server listening port (default: 44772)")
 	flag.IntVar(&ServerLogLevel, "log-level", ServerLogLevel, "Server log level (0=LevelEmergency, 1=LevelAlert, 2=LevelCritical, 3=LevelError, 4=LevelWarning, 5=LevelNotice, 6=LevelInformational, 7=LevelDebug, default: 6)")
 	flag.StringVar(&ServerAccessToken, "access-token", ServerAccessToken, "Server access token for API authentication")
+	flag.StringVar(&CallbackSecret, "callback-secret", CallbackSecret, "HMAC secret signing the callbacks of asynchronous executions (default: the access token)")
 
 	if graceShutdownTimeout := os.Getenv(gracefulShutdownTimeoutEnv); graceShutdownTimeout != "" {
 		duration, err := time.ParseDuration(graceShutdownTimeout)
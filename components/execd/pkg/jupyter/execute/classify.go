@@ -0,0 +1,168 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execute
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is a machine-readable class of an execution error, so callers can decide to retry or
+// repair without parsing messages.
+type ErrorCode string
+
+const (
+	ErrorCodeModuleNotFound   ErrorCode = "MODULE_NOT_FOUND"
+	ErrorCodeImportError      ErrorCode = "IMPORT_ERROR"
+	ErrorCodeOutOfMemory      ErrorCode = "OUT_OF_MEMORY"
+	ErrorCodeSyntaxError      ErrorCode = "SYNTAX_ERROR"
+	ErrorCodeNameError        ErrorCode = "NAME_ERROR"
+	ErrorCodeTypeError        ErrorCode = "TYPE_ERROR"
+	ErrorCodeValueError       ErrorCode = "VALUE_ERROR"
+	ErrorCodeAttributeError   ErrorCode = "ATTRIBUTE_ERROR"
+	ErrorCodeLookupError      ErrorCode = "LOOKUP_ERROR"
+	ErrorCodeZeroDivision     ErrorCode = "ZERO_DIVISION"
+	ErrorCodeFileNotFound     ErrorCode = "FILE_NOT_FOUND"
+	ErrorCodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	ErrorCodeConnectionError  ErrorCode = "CONNECTION_ERROR"
+	ErrorCodeTimeout          ErrorCode = "TIMEOUT"
+	ErrorCodeRecursionLimit   ErrorCode = "RECURSION_LIMIT"
+	ErrorCodeInterrupted      ErrorCode = "INTERRUPTED"
+	ErrorCodeCommandFailed    ErrorCode = "COMMAND_FAILED"
+	ErrorCodeSQLError         ErrorCode = "SQL_ERROR"
+	ErrorCodeUnclassified     ErrorCode = "UNCLASSIFIED"
+)
+
+// errorCodes maps error names, without module prefix, to their class.
+var errorCodes = map[string]ErrorCode{
+	"ModuleNotFoundError":    ErrorCodeModuleNotFound,
+	"ImportError":            ErrorCodeImportError,
+	"MemoryError":            ErrorCodeOutOfMemory,
+	"SyntaxError":            ErrorCodeSyntaxError,
+	"IndentationError":       ErrorCodeSyntaxError,
+	"TabError":               ErrorCodeSyntaxError,
+	"NameError":              ErrorCodeNameError,
+	"UnboundLocalError":      ErrorCodeNameError,
+	"TypeError":              ErrorCodeTypeError,
+	"ValueError":             ErrorCodeValueError,
+	"UnicodeDecodeError":     ErrorCodeValueError,
+	"UnicodeEncodeError":     ErrorCodeValueError,
+	"JSONDecodeError":        ErrorCodeValueError,
+	"AttributeError":         ErrorCodeAttributeError,
+	"KeyError":               ErrorCodeLookupError,
+	"IndexError":             ErrorCodeLookupError,
+	"ZeroDivisionError":      ErrorCodeZeroDivision,
+	"FileNotFoundError":      ErrorCodeFileNotFound,
+	"IsADirectoryError":      ErrorCodeFileNotFound,
+	"NotADirectoryError":     ErrorCodeFileNotFound,
+	"PermissionError":        ErrorCodePermissionDenied,
+	"ConnectionError":        ErrorCodeConnectionError,
+	"ConnectionRefusedError": ErrorCodeConnectionError,
+	"ConnectionResetError":   ErrorCodeConnectionError,
+	"ConnectionAbortedError": ErrorCodeConnectionError,
+	"BrokenPipeError":        ErrorCodeConnectionError,
+	"gaierror":               ErrorCodeConnectionError,
+	"TimeoutError":           ErrorCodeTimeout,
+	"Timeout":                ErrorCodeTimeout,
+	"ReadTimeout":            ErrorCodeTimeout,
+	"ConnectTimeout":         ErrorCodeTimeout,
+	"timeout":                ErrorCodeTimeout,
+	"RecursionError":         ErrorCodeRecursionLimit,
+	"KeyboardInterrupt":      ErrorCodeInterrupted,
+	"ContextCancelled":       ErrorCodeInterrupted,
+	"CommandExecError":       ErrorCodeCommandFailed,
+	"DBInitError":            ErrorCodeSQLError,
+	"DBPingError":            ErrorCodeSQLError,
+	"DBQueryError":           ErrorCodeSQLError,
+	"DBExecError":            ErrorCodeSQLError,
+	"RowScanError":           ErrorCodeSQLError,
+	"RowIterationError":      ErrorCodeSQLError,
+}
+
+// Frame is a stack frame parsed from a traceback.
+type Frame struct {
+	// File is the source file, or the cell ("In [3]") for code run in a notebook.
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+	// File "/app/main.py", line 3, in main
+	pythonFrame = regexp.MustCompile(`^\s*File "(.+)", line (\d+)(?:, in (.+))?$`)
+	// File /usr/lib/python3/json/decoder.py:355, in JSONDecoder.raw_decode(self, s, idx)
+	ipythonFrame = regexp.MustCompile(`^File (.+):(\d+)(?:, in ([^(\s]+))?`)
+	// Cell In[3], line 2, in f()
+	ipythonCell = regexp.MustCompile(`^Cell (In\s*\[\d+\]), line (\d+)(?:, in ([^(\s]+))?`)
+	// ModuleNotFoundError: No module named 'pandas'
+	exceptionLine = regexp.MustCompile(`^([A-Za-z_][\w.]*):(?:\s+(.*))?$`)
+	// MemoryError, which Python prints without a message
+	bareExceptionLine = regexp.MustCompile(`^([A-Za-z_][\w.]*(?:Error|Exception|Interrupt|Exit))()$`)
+)
+
+// Classify parses the stack frames out of the traceback and sets the error code. Errors without a
+// name, such as raw Python output, get the name and message from the last line of the traceback.
+// Calling it again recomputes the same fields.
+func Classify(e *ErrorOutput) {
+	if e == nil {
+		return
+	}
+	var lines []string
+	for _, entry := range e.Traceback {
+		lines = append(lines, strings.Split(ansiEscape.ReplaceAllString(entry, ""), "\n")...)
+	}
+
+	e.Frames = nil
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \r")
+		for _, pattern := range []*regexp.Regexp{pythonFrame, ipythonFrame, ipythonCell} {
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				lineNo, _ := strconv.Atoi(m[2])
+				e.Frames = append(e.Frames, Frame{File: m[1], Line: lineNo, Function: m[3]})
+				break
+			}
+		}
+	}
+
+	if e.EName == "" {
+		for i := len(lines) - 1; i >= 0; i-- {
+			line := strings.TrimSpace(lines[i])
+			m := exceptionLine.FindStringSubmatch(line)
+			if m == nil {
+				m = bareExceptionLine.FindStringSubmatch(line)
+			}
+			if m != nil {
+				e.EName, e.EValue = m[1], m[2]
+				break
+			}
+		}
+	}
+	e.Code = classifyName(e.EName)
+}
+
+func classifyName(name string) ErrorCode {
+	if name == "" {
+		return ""
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if code, ok := errorCodes[name]; ok {
+		return code
+	}
+	return ErrorCodeUnclassified
+}
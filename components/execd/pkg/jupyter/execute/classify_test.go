@@ -0,0 +1,82 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify_IPythonTraceback(t *testing.T) {
+	e := &ErrorOutput{
+		EName:  "ModuleNotFoundError",
+		EValue: "No module named 'pandas'",
+		Traceback: []string{
+			"\x1b[0;31m---------------------------------------------------------------------------\x1b[0m",
+			"\x1b[0;31mModuleNotFoundError\x1b[0m                       Traceback (most recent call last)",
+			"Cell \x1b[0;32mIn[2], line 3\x1b[0m\n\x1b[1;32m      1\x1b[0m x = 1\n\x1b[0;32m----> 3\x1b[0m \x1b[38;5;28;01mimport\x1b[39;00m \x1b[38;5;21;01mpandas\x1b[39;00m",
+			"File \x1b[0;32m/opt/lib/loader.py:42\x1b[0m, in \x1b[0;36mLoader.load\x1b[1;34m(self, name)\x1b[0m",
+			"\x1b[0;31mModuleNotFoundError\x1b[0m: No module named 'pandas'",
+		},
+	}
+	Classify(e)
+	require.Equal(t, ErrorCodeModuleNotFound, e.Code)
+	require.Equal(t, []Frame{
+		{File: "In[2]", Line: 3},
+		{File: "/opt/lib/loader.py", Line: 42, Function: "Loader.load"},
+	}, e.Frames)
+
+	// Classifying again yields the same result.
+	Classify(e)
+	require.Len(t, e.Frames, 2)
+}
+
+func TestClassify_PythonTraceback(t *testing.T) {
+	e := &ErrorOutput{Traceback: []string{
+		"Traceback (most recent call last):",
+		`  File "/app/main.py", line 10, in <module>`,
+		"    main()",
+		`  File "/app/main.py", line 7, in main`,
+		"    data = [0] * (1 << 40)",
+		"MemoryError: ",
+	}}
+	Classify(e)
+	require.Equal(t, "MemoryError", e.EName)
+	require.Equal(t, ErrorCodeOutOfMemory, e.Code)
+	require.Equal(t, []Frame{
+		{File: "/app/main.py", Line: 10, Function: "<module>"},
+		{File: "/app/main.py", Line: 7, Function: "main"},
+	}, e.Frames)
+}
+
+func TestClassify_Names(t *testing.T) {
+	for name, code := range map[string]ErrorCode{
+		"requests.exceptions.ConnectionError": ErrorCodeConnectionError,
+		"IndentationError":                    ErrorCodeSyntaxError,
+		"KeyError":                            ErrorCodeLookupError,
+		"CommandExecError":                    ErrorCodeCommandFailed,
+		"ContextCancelled":                    ErrorCodeInterrupted,
+		"DBQueryError":                        ErrorCodeSQLError,
+		"MyCustomError":                       ErrorCodeUnclassified,
+		"":                                    "",
+	} {
+		e := &ErrorOutput{EName: name}
+		Classify(e)
+		require.Equal(t, code, e.Code, name)
+		require.Empty(t, e.Frames)
+	}
+	Classify(nil)
+}
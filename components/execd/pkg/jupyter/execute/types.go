@@ -208,6 +208,12 @@ type ErrorOutput struct {
 
 	// Traceback is the traceback of the error
 	Traceback []string `json:"traceback"`
+
+	// Code is the machine-readable class of the error, set by Classify
+	Code ErrorCode `json:"code,omitempty"`
+
+	// Frames are the stack frames parsed from the traceback by Classify, outermost first
+	Frames []Frame `json:"frames,omitempty"`
 }
 
 func (e *ErrorOutput) String() string {
@@ -16,6 +16,7 @@ package log
 
 import (
 	"context"
+	"net/http"
 	"os"
 
 	slogger "github.com/alibaba/opensandbox/internal/logger"
@@ -56,7 +57,30 @@ func newLogger(level string) slogger.Logger {
 		cfg.OutputPaths = []string{logFile}
 		cfg.ErrorOutputPaths = cfg.OutputPaths
 	}
-	return slogger.MustNew(cfg)
+	l := slogger.MustNew(cfg)
+	if fields := slogger.PodFields(); len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	return l
+}
+
+// AddFields attaches fields to every following log line, e.g. the sandbox ID
+// once telemetry attributes are known.
+func AddFields(fields ...slogger.Field) {
+	current = getLogger().With(fields...)
+}
+
+// LevelHandler serves GET/PUT {"level":"..."} to inspect or change the log level at runtime.
+func LevelHandler() http.Handler {
+	if h, ok := slogger.LevelHandler(getLogger()); ok {
+		return h
+	}
+	return http.NotFoundHandler()
+}
+
+// ForRequest returns the logger with the trace ID of r attached.
+func ForRequest(r *http.Request) slogger.Logger {
+	return slogger.WithTrace(getLogger(), r)
 }
 
 func getLogger() slogger.Logger {
@@ -118,7 +118,7 @@ func newBashSession(cwd string) *bashSession {
 	}
 
 	env := make(map[string]string)
-	for _, kv := range os.Environ() {
+	for _, kv := range executionEnviron() {
 		if k, v, ok := splitEnvPair(kv); ok {
 			env[k] = v
 		}
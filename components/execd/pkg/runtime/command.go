@@ -126,9 +126,21 @@ func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest
 
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
-	cmd.Env = mergeEnvs(os.Environ(), extraEnv)
+	cmd.Env = mergeEnvs(executionEnviron(), extraEnv)
 	cmd.Dir = cwd
 
+	var stdin *commandStdin
+	var stdinR *os.File
+	if request.Interactive {
+		var stdinW *os.File
+		if stdinR, stdinW, err = os.Pipe(); err != nil {
+			return fmt.Errorf("failed to create stdin pipe: %w", err)
+		}
+		cmd.Stdin = stdinR
+		stdin = newCommandStdin(stdinW, request.Hooks.OnExecuteStdin)
+		defer stdin.close()
+	}
+
 	done := make(chan struct{}, 1)
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -142,6 +154,9 @@ func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest
 	})
 
 	err = cmd.Start()
+	if stdinR != nil {
+		_ = stdinR.Close()
+	}
 	if err != nil {
 		close(done)
 		wg.Wait()
@@ -163,6 +178,7 @@ func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest
 		running:      true,
 		content:      request.Code,
 		isBackground: false,
+		stdin:        stdin,
 	}
 	c.storeCommandKernel(session, kernel)
 	request.Hooks.OnExecuteInit(session)
@@ -176,8 +192,9 @@ func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest
 				if sig == nil {
 					continue
 				}
-				// DO NOT forward syscall.SIGURG to children processes.
-				if sig != syscall.SIGCHLD && sig != syscall.SIGURG {
+				// DO NOT forward syscall.SIGURG to children processes, nor SIGPIPE raised by
+				// writes of execd itself, e.g. to the stdin of an interactive command.
+				if sig != syscall.SIGCHLD && sig != syscall.SIGURG && sig != syscall.SIGPIPE {
 					_ = syscall.Kill(-cmd.Process.Pid, sig.(syscall.Signal))
 				}
 			}
@@ -263,11 +280,20 @@ func (c *Controller) runBackgroundCommand(ctx context.Context, cancel context.Ca
 
 	cmd.Stdout = pipe
 	cmd.Stderr = pipe
-	cmd.Env = mergeEnvs(os.Environ(), extraEnv)
+	cmd.Env = mergeEnvs(executionEnviron(), extraEnv)
 
-	// use DevNull as stdin so interactive programs exit immediately.
-	devNull, err := os.Open(os.DevNull)
-	if err == nil {
+	// use DevNull as stdin so interactive programs exit immediately, unless input is expected.
+	var stdin *commandStdin
+	if request.Interactive {
+		stdinR, stdinW, err := os.Pipe()
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to create stdin pipe: %w", err)
+		}
+		cmd.Stdin = stdinR
+		defer stdinR.Close()
+		stdin = newCommandStdin(stdinW, nil)
+	} else if devNull, err := os.Open(os.DevNull); err == nil {
 		cmd.Stdin = devNull
 		defer devNull.Close()
 	}
@@ -281,9 +307,13 @@ func (c *Controller) runBackgroundCommand(ctx context.Context, cancel context.Ca
 		running:      true,
 		content:      request.Code,
 		isBackground: true,
+		stdin:        stdin,
 	}
 	if err != nil {
 		cancel()
+		if stdin != nil {
+			stdin.close()
+		}
 		log.Error("CommandExecError: error starting commands: %v", err)
 		kernel.running = false
 		c.storeCommandKernel(session, kernel)
@@ -293,6 +323,9 @@ func (c *Controller) runBackgroundCommand(ctx context.Context, cancel context.Ca
 
 	safego.Go(func() {
 		defer pipe.Close()
+		if stdin != nil {
+			defer stdin.close()
+		}
 
 		kernel.running = true
 		kernel.pid = cmd.Process.Pid
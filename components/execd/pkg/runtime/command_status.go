@@ -30,6 +30,8 @@ type CommandStatus struct {
 	StartedAt  time.Time  `json:"started_at,omitempty"`
 	FinishedAt *time.Time `json:"finished_at,omitempty"`
 	Content    string     `json:"content,omitempty"`
+	// Interactive is set for commands that accept input on stdin.
+	Interactive bool `json:"interactive,omitempty"`
 }
 
 // CommandOutput contains non-streamed stdout/stderr plus status.
@@ -60,13 +62,14 @@ func (c *Controller) GetCommandStatus(session string) (*CommandStatus, error) {
 	}
 
 	status := &CommandStatus{
-		Session:    session,
-		Running:    kernel.running,
-		ExitCode:   kernel.exitCode,
-		Error:      kernel.errMsg,
-		StartedAt:  kernel.startedAt,
-		FinishedAt: kernel.finishedAt,
-		Content:    kernel.content,
+		Session:     session,
+		Running:     kernel.running,
+		ExitCode:    kernel.exitCode,
+		Error:       kernel.errMsg,
+		StartedAt:   kernel.startedAt,
+		FinishedAt:  kernel.finishedAt,
+		Content:     kernel.content,
+		Interactive: kernel.stdin != nil,
 	}
 	return status, nil
 }
@@ -0,0 +1,88 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// stdinWriteTimeout bounds a write to a command that does not read its stdin.
+const stdinWriteTimeout = 10 * time.Second
+
+// commandStdin is the write end of the stdin pipe of an interactive command.
+type commandStdin struct {
+	mu     sync.Mutex
+	w      *os.File
+	closed bool
+	// onWrite echoes the written data to the output stream of the command, if it has one.
+	onWrite func(text string)
+}
+
+func newCommandStdin(w *os.File, onWrite func(text string)) *commandStdin {
+	return &commandStdin{w: w, onWrite: onWrite}
+}
+
+// write sends data to the command and closes its stdin afterwards when eof is set.
+func (s *commandStdin) write(data []byte, eof bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrCommandStdinClosed
+	}
+	if len(data) > 0 {
+		_ = s.w.SetWriteDeadline(time.Now().Add(stdinWriteTimeout))
+		if _, err := s.w.Write(data); err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return fmt.Errorf("command is not reading its stdin: %w", err)
+			}
+			return fmt.Errorf("%w: %v", ErrCommandStdinClosed, err)
+		}
+		if s.onWrite != nil {
+			s.onWrite(string(data))
+		}
+	}
+	if eof {
+		s.closed = true
+		return s.w.Close()
+	}
+	return nil
+}
+
+// close closes the stdin of the command once it has exited, failing later writes.
+func (s *commandStdin) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		_ = s.w.Close()
+	}
+}
+
+// WriteCommandStdin sends data to the stdin of a running command started in interactive mode, and
+// closes the stdin after the data when eof is set.
+func (c *Controller) WriteCommandStdin(session string, data []byte, eof bool) error {
+	kernel := c.commandSnapshot(session)
+	if kernel == nil {
+		return fmt.Errorf("%w: %s", ErrCommandNotFound, session)
+	}
+	if kernel.stdin == nil {
+		return fmt.Errorf("%w: %s", ErrCommandNotInteractive, session)
+	}
+	return kernel.stdin.write(data, eof)
+}
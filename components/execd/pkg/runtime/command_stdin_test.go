@@ -0,0 +1,125 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	goruntime "runtime"
+
+	"github.com/alibaba/opensandbox/execd/pkg/jupyter/execute"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommand_InteractiveStdin(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("interactive commands are not supported on windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not found in PATH")
+	}
+
+	c := NewController("", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		mu          sync.Mutex
+		sessionID   string
+		stdoutLines []string
+		stdinEvents []string
+		writeErrs   = make(chan error, 1)
+	)
+
+	req := &ExecuteCodeRequest{
+		Code:        `read -r -p "continue? " answer; echo "answer=$answer"; cat`,
+		Cwd:         t.TempDir(),
+		Timeout:     5 * time.Second,
+		Interactive: true,
+		Hooks: ExecuteResultHook{
+			OnExecuteInit: func(s string) {
+				sessionID = s
+				go func() {
+					if err := c.WriteCommandStdin(s, []byte("yes\n"), false); err != nil {
+						writeErrs <- err
+						return
+					}
+					writeErrs <- c.WriteCommandStdin(s, []byte("rest\n"), true)
+				}()
+			},
+			OnExecuteStdout: func(s string) {
+				mu.Lock()
+				defer mu.Unlock()
+				stdoutLines = append(stdoutLines, s)
+			},
+			OnExecuteStdin: func(s string) {
+				mu.Lock()
+				defer mu.Unlock()
+				stdinEvents = append(stdinEvents, s)
+			},
+			OnExecuteStderr: func(string) {},
+			OnExecuteError: func(err *execute.ErrorOutput) {
+				require.Failf(t, "unexpected error hook", "%+v", err)
+			},
+			OnExecuteComplete: func(_ time.Duration) {},
+		},
+	}
+
+	// The command only exits once stdin is closed.
+	require.NoError(t, c.runCommand(ctx, req))
+	require.NoError(t, <-writeErrs)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"answer=yes", "rest"}, stdoutLines)
+	require.Equal(t, []string{"yes\n", "rest\n"}, stdinEvents)
+
+	status, err := c.GetCommandStatus(sessionID)
+	require.NoError(t, err)
+	require.True(t, status.Interactive)
+	require.ErrorIs(t, c.WriteCommandStdin(sessionID, []byte("late\n"), false), ErrCommandStdinClosed)
+}
+
+func TestWriteCommandStdin_Errors(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("bash not available on windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not found in PATH")
+	}
+
+	c := NewController("", "")
+	require.ErrorIs(t, c.WriteCommandStdin("missing", []byte("x"), false), ErrCommandNotFound)
+
+	var sessionID string
+	req := &ExecuteCodeRequest{
+		Code:    `true`,
+		Cwd:     t.TempDir(),
+		Timeout: 5 * time.Second,
+		Hooks: ExecuteResultHook{
+			OnExecuteInit:     func(s string) { sessionID = s },
+			OnExecuteStdout:   func(string) {},
+			OnExecuteStderr:   func(string) {},
+			OnExecuteComplete: func(time.Duration) {},
+		},
+	}
+	require.NoError(t, c.runCommand(context.Background(), req))
+	require.ErrorIs(t, c.WriteCommandStdin(sessionID, []byte("x"), false), ErrCommandNotInteractive)
+}
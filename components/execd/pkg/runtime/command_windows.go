@@ -32,8 +32,13 @@ import (
 	"github.com/alibaba/opensandbox/internal/safego"
 )
 
+var errInteractiveUnsupported = errors.New("interactive commands are not supported on Windows")
+
 // runCommand executes shell commands and streams their output on Windows.
 func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest) error {
+	if request.Interactive {
+		return errInteractiveUnsupported
+	}
 	session := c.newContextID()
 	request.Hooks.OnExecuteInit(session)
 
@@ -54,7 +59,7 @@ func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	cmd.Dir = cwd
-	cmd.Env = mergeEnvs(os.Environ(), extraEnv)
+	cmd.Env = mergeEnvs(executionEnviron(), extraEnv)
 
 	done := make(chan struct{}, 1)
 	safego.Go(func() {
@@ -110,6 +115,10 @@ func (c *Controller) runCommand(ctx context.Context, request *ExecuteCodeRequest
 
 // runBackgroundCommand executes shell commands in detached mode on Windows.
 func (c *Controller) runBackgroundCommand(ctx context.Context, cancel context.CancelFunc, request *ExecuteCodeRequest) error {
+	if request.Interactive {
+		cancel()
+		return errInteractiveUnsupported
+	}
 	session := c.newContextID()
 	request.Hooks.OnExecuteInit(session)
 
@@ -132,7 +141,7 @@ func (c *Controller) runBackgroundCommand(ctx context.Context, cancel context.Ca
 	cmd.Dir = cwd
 	cmd.Stdout = pipe
 	cmd.Stderr = pipe
-	cmd.Env = mergeEnvs(os.Environ(), extraEnv)
+	cmd.Env = mergeEnvs(executionEnviron(), extraEnv)
 
 	devNull, _ := os.OpenFile(os.DevNull, os.O_RDWR, 0) // best-effort, ignore error
 	cmd.Stdin = devNull
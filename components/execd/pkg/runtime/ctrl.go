@@ -65,6 +65,8 @@ type commandKernel struct {
 	running      bool
 	isBackground bool
 	content      string
+	// stdin is set for commands started in interactive mode.
+	stdin *commandStdin
 }
 
 // NewController creates a runtime controller.
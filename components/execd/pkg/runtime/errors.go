@@ -16,4 +16,10 @@ package runtime
 
 import "errors"
 
-var ErrContextNotFound = errors.New("context not found")
+var (
+	ErrContextNotFound = errors.New("context not found")
+
+	ErrCommandNotFound       = errors.New("command not found")
+	ErrCommandNotInteractive = errors.New("command was not started in interactive mode")
+	ErrCommandStdinClosed    = errors.New("command stdin is closed")
+)
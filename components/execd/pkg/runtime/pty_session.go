@@ -167,7 +167,7 @@ func (s *ptySession) StartPTY() error {
 	}
 
 	cmd := exec.Command("bash", "--norc", "--noprofile")
-	cmd.Env = os.Environ()
+	cmd.Env = executionEnviron()
 	if s.cwd != "" {
 		cmd.Dir = s.cwd
 	}
@@ -224,7 +224,7 @@ func (s *ptySession) StartPipe() error {
 	}
 
 	cmd := exec.Command("bash", "--norc", "--noprofile")
-	cmd.Env = os.Environ()
+	cmd.Env = executionEnviron()
 	if s.cwd != "" {
 		cmd.Dir = s.cwd
 	}
@@ -0,0 +1,161 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy of the sandbox is mounted.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// parallelismEnvKeys size the thread pools of common numeric and parallel runtimes.
+var parallelismEnvKeys = []string{
+	"OMP_NUM_THREADS",
+	"OPENBLAS_NUM_THREADS",
+	"MKL_NUM_THREADS",
+	"NUMEXPR_NUM_THREADS",
+	"VECLIB_MAXIMUM_THREADS",
+	"NUMBA_NUM_THREADS",
+	"RAYON_NUM_THREADS",
+}
+
+// ResourceLimits are the CPU and memory limits of the sandbox, read from its cgroup v2.
+type ResourceLimits struct {
+	// CgroupV2 is set when the limits were read from a cgroup v2 hierarchy.
+	CgroupV2 bool `json:"cgroup_v2"`
+	// HostCPUs is the number of CPUs of the host.
+	HostCPUs int `json:"host_cpus"`
+	// CPULimit is the CPU quota in cores (cpu.max), 0 when unlimited.
+	CPULimit float64 `json:"cpu_limit,omitempty"`
+	// CPUSet is the number of CPUs the sandbox may run on (cpuset.cpus.effective), 0 when unknown.
+	CPUSet int `json:"cpuset,omitempty"`
+	// MemoryLimitBytes is the memory limit (memory.max), 0 when unlimited.
+	MemoryLimitBytes int64 `json:"memory_limit_bytes,omitempty"`
+	// Parallelism is the number of workers code should use: the CPUs the limits allow, at least 1.
+	Parallelism int `json:"parallelism"`
+}
+
+var (
+	resourceLimitsOnce sync.Once
+	resourceLimits     ResourceLimits
+)
+
+// DetectResourceLimits returns the limits of the sandbox. They are read once; the cgroup of a
+// sandbox is not expected to change while execd runs.
+func DetectResourceLimits() ResourceLimits {
+	resourceLimitsOnce.Do(func() {
+		resourceLimits = readResourceLimits(cgroupRoot, goruntime.NumCPU())
+	})
+	return resourceLimits
+}
+
+// ParallelismEnvs returns the thread pool sizes matching the limits, or nil when the sandbox may use
+// all CPUs of the host.
+func (l ResourceLimits) ParallelismEnvs() map[string]string {
+	if l.Parallelism <= 0 || l.Parallelism >= l.HostCPUs {
+		return nil
+	}
+	value := strconv.Itoa(l.Parallelism)
+	envs := make(map[string]string, len(parallelismEnvKeys))
+	for _, key := range parallelismEnvKeys {
+		envs[key] = value
+	}
+	return envs
+}
+
+func readResourceLimits(root string, hostCPUs int) ResourceLimits {
+	limits := ResourceLimits{HostCPUs: hostCPUs, Parallelism: hostCPUs}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return limits
+	}
+	limits.CgroupV2 = true
+
+	if fields := strings.Fields(readCgroupFile(root, "cpu.max")); len(fields) == 2 && fields[0] != "max" {
+		quota, qerr := strconv.ParseFloat(fields[0], 64)
+		period, perr := strconv.ParseFloat(fields[1], 64)
+		if qerr == nil && perr == nil && quota > 0 && period > 0 {
+			limits.CPULimit = quota / period
+		}
+	}
+	limits.CPUSet = countCPUs(readCgroupFile(root, "cpuset.cpus.effective"))
+	if value := readCgroupFile(root, "memory.max"); value != "" && value != "max" {
+		if bytes, err := strconv.ParseInt(value, 10, 64); err == nil {
+			limits.MemoryLimitBytes = bytes
+		}
+	}
+
+	if limits.CPUSet > 0 && limits.CPUSet < limits.Parallelism {
+		limits.Parallelism = limits.CPUSet
+	}
+	if limits.CPULimit > 0 {
+		limits.Parallelism = min(limits.Parallelism, int(math.Ceil(limits.CPULimit)))
+	}
+	limits.Parallelism = max(limits.Parallelism, 1)
+	return limits
+}
+
+func readCgroupFile(root, name string) string {
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// countCPUs counts the CPUs of a cpuset list such as "0-3,6".
+func countCPUs(list string) int {
+	count := 0
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			hi = lo
+		}
+		first, ferr := strconv.Atoi(lo)
+		last, lerr := strconv.Atoi(hi)
+		if ferr != nil || lerr != nil || last < first {
+			return 0
+		}
+		count += last - first + 1
+	}
+	return count
+}
+
+// executionEnviron is the environment executions start from: the environment of execd, plus the
+// thread pool sizes of DetectResourceLimits for variables it does not set.
+func executionEnviron() []string {
+	environ := os.Environ()
+	envs := DetectResourceLimits().ParallelismEnvs()
+	for _, key := range parallelismEnvKeys {
+		value, ok := envs[key]
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(key); !set {
+			environ = append(environ, key+"="+value)
+		}
+	}
+	return environ
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files["cgroup.controllers"] = "cpuset cpu io memory pids"
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content+"\n"), 0o644))
+	}
+	return root
+}
+
+func TestReadResourceLimitsFromCgroupV2(t *testing.T) {
+	root := writeCgroupFiles(t, map[string]string{
+		"cpu.max":               "150000 100000",
+		"cpuset.cpus.effective": "0-63",
+		"memory.max":            "4294967296",
+	})
+
+	limits := readResourceLimits(root, 64)
+	require.True(t, limits.CgroupV2)
+	require.InDelta(t, 1.5, limits.CPULimit, 1e-9)
+	require.Equal(t, 64, limits.CPUSet)
+	require.Equal(t, int64(4294967296), limits.MemoryLimitBytes)
+	require.Equal(t, 2, limits.Parallelism, "a fractional quota rounds up")
+
+	envs := limits.ParallelismEnvs()
+	require.Equal(t, "2", envs["OMP_NUM_THREADS"])
+	require.Len(t, envs, len(parallelismEnvKeys))
+}
+
+func TestReadResourceLimitsUnlimited(t *testing.T) {
+	root := writeCgroupFiles(t, map[string]string{
+		"cpu.max":    "max 100000",
+		"memory.max": "max",
+	})
+
+	limits := readResourceLimits(root, 8)
+	require.Zero(t, limits.CPULimit)
+	require.Zero(t, limits.MemoryLimitBytes)
+	require.Equal(t, 8, limits.Parallelism)
+	require.Nil(t, limits.ParallelismEnvs(), "no defaults when all host CPUs can be used")
+}
+
+func TestReadResourceLimitsCPUSet(t *testing.T) {
+	root := writeCgroupFiles(t, map[string]string{"cpuset.cpus.effective": "0-1,4"})
+	require.Equal(t, 3, readResourceLimits(root, 16).Parallelism)
+}
+
+func TestReadResourceLimitsWithoutCgroupV2(t *testing.T) {
+	limits := readResourceLimits(t.TempDir(), 4)
+	require.False(t, limits.CgroupV2)
+	require.Equal(t, 4, limits.Parallelism)
+}
+
+func TestCountCPUs(t *testing.T) {
+	require.Equal(t, 4, countCPUs("0-3"))
+	require.Equal(t, 5, countCPUs("0-1,4,6-7"))
+	require.Equal(t, 0, countCPUs(""))
+	require.Equal(t, 0, countCPUs("3-1"))
+}
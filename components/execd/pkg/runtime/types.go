@@ -29,6 +29,7 @@ type ExecuteResultHook struct {
 	OnExecuteStatus   func(status string)
 	OnExecuteStdout   func(stdout string) //nolint:predeclared
 	OnExecuteStderr   func(stderr string) //nolint:predeclared
+	OnExecuteStdin    func(stdin string)
 	OnExecuteError    func(err *execute.ErrorOutput)
 	OnExecuteComplete func(executionTime time.Duration)
 }
@@ -43,7 +44,9 @@ type ExecuteCodeRequest struct {
 	Envs     map[string]string `json:"envs"`
 	Uid      *uint32           `json:"uid,omitempty"`
 	Gid      *uint32           `json:"gid,omitempty"`
-	Hooks    ExecuteResultHook
+	// Interactive keeps the stdin of a command open for WriteCommandStdin.
+	Interactive bool `json:"interactive,omitempty"`
+	Hooks       ExecuteResultHook
 }
 
 // SetDefaultHooks installs stdout logging fallbacks for unset hooks.
@@ -60,6 +63,9 @@ func (req *ExecuteCodeRequest) SetDefaultHooks() {
 	if req.Hooks.OnExecuteStderr == nil {
 		req.Hooks.OnExecuteStderr = func(stderr string) { fmt.Printf("OnExecuteStderr: %s\n", stderr) }
 	}
+	if req.Hooks.OnExecuteStdin == nil {
+		req.Hooks.OnExecuteStdin = func(stdin string) { fmt.Printf("OnExecuteStdin: %s\n", stdin) }
+	}
 	if req.Hooks.OnExecuteError == nil {
 		req.Hooks.OnExecuteError = func(err *execute.ErrorOutput) { fmt.Printf("OnExecuteError: %++v\n", err) }
 	}
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"sync"
 
+	slogger "github.com/alibaba/opensandbox/internal/logger"
 	inttelemetry "github.com/alibaba/opensandbox/internal/telemetry"
 	"github.com/alibaba/opensandbox/internal/version"
 	"go.opentelemetry.io/otel"
@@ -151,6 +152,11 @@ func registerExecdMetrics() error {
 	return err
 }
 
+// ExecdLogFields returns the sandbox and extra attributes as log fields.
+func ExecdLogFields() []slogger.Field {
+	return inttelemetry.LogFields(execdSharedAttrs())
+}
+
 var execdSharedAttrs = sync.OnceValue(func() []attribute.KeyValue {
 	return inttelemetry.SharedAttrsFromEnv(inttelemetry.SharedAttrsEnvConfig{
 		SandboxIDEnv:  envSandboxID,
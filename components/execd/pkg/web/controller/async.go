@@ -0,0 +1,341 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alibaba/opensandbox/internal/safego"
+	"github.com/google/uuid"
+
+	"github.com/alibaba/opensandbox/execd/pkg/flag"
+	"github.com/alibaba/opensandbox/execd/pkg/jupyter/execute"
+	"github.com/alibaba/opensandbox/execd/pkg/log"
+	"github.com/alibaba/opensandbox/execd/pkg/runtime"
+	"github.com/alibaba/opensandbox/execd/pkg/telemetry"
+	"github.com/alibaba/opensandbox/execd/pkg/web/model"
+)
+
+const (
+	// asyncExecutionRetention is how long a finished asynchronous execution can still be fetched.
+	asyncExecutionRetention = time.Hour
+	// asyncOutputLimit caps the stdout and stderr kept per execution; later output is dropped.
+	asyncOutputLimit = 1 << 20
+
+	callbackAttempts = 3
+	callbackTimeout  = 10 * time.Second
+)
+
+var (
+	asyncExecutions = newAsyncExecutionStore()
+
+	callbackClient        = &http.Client{Timeout: callbackTimeout}
+	callbackRetryInterval = time.Second
+)
+
+// RunCodeAsync starts executing code in the background and returns its handle right away. The result
+// can be fetched with GetAsyncExecution and, when a callback URL is given, is POSTed to it once the
+// execution ends.
+func (c *CodeInterpretingController) RunCodeAsync() {
+	var request model.RunCodeAsyncRequest
+	if err := c.bindJSON(&request); err != nil {
+		c.RespondError(
+			http.StatusBadRequest,
+			model.ErrorCodeInvalidRequest,
+			fmt.Sprintf("error parsing request, MAYBE invalid body format. %v", err),
+		)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.RespondError(
+			http.StatusBadRequest,
+			model.ErrorCodeInvalidRequest,
+			fmt.Sprintf("invalid request, validation error %v", err),
+		)
+		return
+	}
+	if request.CallbackURL != "" && callbackSecret() == "" {
+		c.RespondError(
+			http.StatusBadRequest,
+			model.ErrorCodeInvalidRequest,
+			"callback_url requires a signing secret, set --callback-secret or --access-token",
+		)
+		return
+	}
+
+	recordCommand("code_async", request.Code, request.Context.Cwd, map[string]any{
+		"language": request.Context.Language,
+		"context":  request.Context.ID,
+	})
+
+	execution := asyncExecutions.start(request.CallbackURL)
+	runCodeRequest := c.buildExecuteCodeRequest(request.RunCodeRequest)
+	safego.Go(func() { runAsyncExecution(execution.ID, runCodeRequest) })
+
+	c.ctx.JSON(http.StatusAccepted, execution)
+}
+
+// GetAsyncExecution returns the state of an asynchronous execution by id.
+func (c *CodeInterpretingController) GetAsyncExecution() {
+	id := c.ctx.Param("id")
+	if id == "" {
+		c.RespondError(http.StatusBadRequest, model.ErrorCodeMissingQuery, "missing path parameter 'id'")
+		return
+	}
+
+	execution, ok := asyncExecutions.get(id)
+	if !ok {
+		c.RespondError(http.StatusNotFound, model.ErrorCodeInvalidRequest, fmt.Sprintf("execution %s not found", id))
+		return
+	}
+
+	c.RespondSuccess(execution)
+}
+
+// runAsyncExecution executes the request, records its outcome and delivers the callback.
+func runAsyncExecution(id string, request *runtime.ExecuteCodeRequest) {
+	ctx := context.Background()
+	execStart := time.Now()
+
+	completeCh := make(chan struct{})
+	var completeOnce sync.Once
+	signalComplete := func() {
+		completeOnce.Do(func() {
+			close(completeCh)
+		})
+	}
+	request.Hooks = asyncExecutions.hooks(id, signalComplete)
+
+	if err := codeRunner.Execute(request); err != nil {
+		asyncExecutions.update(id, func(execution *model.AsyncExecution) {
+			execution.Error = &execute.ErrorOutput{EName: "RuntimeError", EValue: err.Error()}
+		})
+		signalComplete()
+	}
+	waitForExecutionComplete(ctx, completeCh)
+
+	execution := asyncExecutions.finish(id)
+	result := "success"
+	if execution.Status == model.AsyncExecutionFailed {
+		result = "failure"
+	}
+	telemetry.RecordExecutionDuration(
+		ctx,
+		"run_code_async",
+		result,
+		float64(time.Since(execStart))/float64(time.Millisecond),
+	)
+
+	if execution.CallbackURL == "" {
+		return
+	}
+	if err := deliverCallback(ctx, execution); err != nil {
+		log.Error("failed to deliver callback of execution %s: %v", id, err)
+		asyncExecutions.update(id, func(execution *model.AsyncExecution) {
+			execution.CallbackError = err.Error()
+		})
+	}
+}
+
+// callbackSecret is the key signing callbacks.
+func callbackSecret() string {
+	if flag.CallbackSecret != "" {
+		return flag.CallbackSecret
+	}
+	return flag.ServerAccessToken
+}
+
+// signCallback returns the signature header value of a callback body sent at timestamp. The
+// timestamp is signed with the body so that receivers can reject replayed callbacks.
+func signCallback(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverCallback POSTs the finished execution to its callback URL, retrying failed attempts.
+func deliverCallback(ctx context.Context, execution model.AsyncExecution) error {
+	body, err := json.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		err = postCallback(ctx, execution, body, callbackSecret())
+		if err == nil || attempt == callbackAttempts {
+			return err
+		}
+		log.Warn("callback of execution %s failed (attempt %d/%d): %v", execution.ID, attempt, callbackAttempts, err)
+		time.Sleep(time.Duration(attempt) * callbackRetryInterval)
+	}
+}
+
+func postCallback(ctx context.Context, execution model.AsyncExecution, body []byte, secret string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, execution.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.CallbackTimestampHeader, timestamp)
+	req.Header.Set(model.CallbackSignatureHeader, signCallback(secret, timestamp, body))
+	req.Header.Set(model.CallbackExecutionIDHeader, execution.ID)
+
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// asyncExecutionStore keeps asynchronous executions in memory until asyncExecutionRetention after they
+// finish.
+type asyncExecutionStore struct {
+	mu         sync.Mutex
+	executions map[string]*model.AsyncExecution
+}
+
+func newAsyncExecutionStore() *asyncExecutionStore {
+	return &asyncExecutionStore{executions: make(map[string]*model.AsyncExecution)}
+}
+
+func (s *asyncExecutionStore) start(callbackURL string) model.AsyncExecution {
+	execution := &model.AsyncExecution{
+		ID:          uuid.New().String(),
+		Status:      model.AsyncExecutionRunning,
+		StartedAt:   time.Now(),
+		CallbackURL: callbackURL,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executions[execution.ID] = execution
+	return *execution
+}
+
+func (s *asyncExecutionStore) get(id string) (model.AsyncExecution, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	execution, ok := s.executions[id]
+	if !ok {
+		return model.AsyncExecution{}, false
+	}
+	return *execution, true
+}
+
+func (s *asyncExecutionStore) update(id string, mutate func(execution *model.AsyncExecution)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if execution, ok := s.executions[id]; ok {
+		mutate(execution)
+	}
+}
+
+// finish sets the final status of the execution and schedules its removal.
+func (s *asyncExecutionStore) finish(id string) model.AsyncExecution {
+	var finished model.AsyncExecution
+	s.update(id, func(execution *model.AsyncExecution) {
+		now := time.Now()
+		execution.FinishedAt = &now
+		execution.Status = model.AsyncExecutionSucceeded
+		if execution.Error != nil {
+			execution.Status = model.AsyncExecutionFailed
+		}
+		finished = *execution
+	})
+	time.AfterFunc(asyncExecutionRetention, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.executions, id)
+	})
+	return finished
+}
+
+// hooks collects the output of the execution into the store; signalComplete is called when the runtime
+// reports the end of the execution.
+func (s *asyncExecutionStore) hooks(id string, signalComplete func()) runtime.ExecuteResultHook {
+	// truncated marks the outputs that reached the limit, so that later output that would still fit
+	// is not appended after the gap. Only accessed under s.mu.
+	truncated := make(map[*string]bool)
+	appendOutput := func(output *string, text string) {
+		if truncated[output] {
+			return
+		}
+		if room := asyncOutputLimit - len(*output); len(text) > room {
+			// Do not cut a multi-byte character in half.
+			for room > 0 && !utf8.RuneStart(text[room]) {
+				room--
+			}
+			text = text[:room]
+			truncated[output] = true
+		}
+		*output += text
+	}
+
+	return runtime.ExecuteResultHook{
+		OnExecuteInit: func(session string) {
+			s.update(id, func(execution *model.AsyncExecution) { execution.Context = session })
+		},
+		OnExecuteResult: func(result map[string]any, count int) {
+			s.update(id, func(execution *model.AsyncExecution) {
+				if count > 0 {
+					execution.ExecutionCount = count
+				}
+				if mutated := normalizeResult(result); len(mutated) > 0 {
+					execution.Results = append(execution.Results, mutated)
+				}
+			})
+		},
+		OnExecuteStatus: func(string) {},
+		OnExecuteStdout: func(text string) {
+			s.update(id, func(execution *model.AsyncExecution) { appendOutput(&execution.Stdout, text) })
+		},
+		OnExecuteStderr: func(text string) {
+			s.update(id, func(execution *model.AsyncExecution) { appendOutput(&execution.Stderr, text) })
+		},
+		OnExecuteStdin: func(string) {},
+		OnExecuteError: func(err *execute.ErrorOutput) {
+			if err != nil {
+				execute.Classify(err)
+				s.update(id, func(execution *model.AsyncExecution) { execution.Error = err })
+			}
+			signalComplete()
+		},
+		OnExecuteComplete: func(executionTime time.Duration) {
+			s.update(id, func(execution *model.AsyncExecution) {
+				execution.ExecutionTime = executionTime.Milliseconds()
+			})
+			signalComplete()
+		},
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/opensandbox/execd/pkg/flag"
+	"github.com/alibaba/opensandbox/execd/pkg/jupyter/execute"
+	"github.com/alibaba/opensandbox/execd/pkg/runtime"
+	"github.com/alibaba/opensandbox/execd/pkg/web/model"
+)
+
+func useAsyncTestRunner(t *testing.T, runner *fakeCodeRunner, secret string) {
+	t.Helper()
+	previousRunner := codeRunner
+	previousSecret := flag.CallbackSecret
+	previousInterval := callbackRetryInterval
+	codeRunner = runner
+	flag.CallbackSecret = secret
+	callbackRetryInterval = time.Millisecond
+	t.Cleanup(func() {
+		codeRunner = previousRunner
+		flag.CallbackSecret = previousSecret
+		callbackRetryInterval = previousInterval
+	})
+}
+
+func startAsyncExecution(t *testing.T, body string) model.AsyncExecution {
+	t.Helper()
+	ctx, w := newTestContext(http.MethodPost, "/code/async", []byte(body))
+	NewCodeInterpretingController(ctx).RunCodeAsync()
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var execution model.AsyncExecution
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &execution))
+	require.NotEmpty(t, execution.ID)
+	return execution
+}
+
+func TestRunCodeAsyncDeliversSignedCallback(t *testing.T) {
+	release := make(chan struct{})
+	useAsyncTestRunner(t, &fakeCodeRunner{
+		execute: func(request *runtime.ExecuteCodeRequest) error {
+			<-release
+			request.Hooks.OnExecuteInit("ctx-1")
+			request.Hooks.OnExecuteStdout("hello\n")
+			request.Hooks.OnExecuteResult(map[string]any{"text/plain": "4"}, 1)
+			request.Hooks.OnExecuteComplete(5 * time.Millisecond)
+			return nil
+		},
+	}, "s3cret")
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	execution := startAsyncExecution(t, `{"code":"print(4)","context":{"language":"python"},"callback_url":"`+server.URL+`"}`)
+	require.Equal(t, model.AsyncExecutionRunning, execution.Status, "the handle is returned before the execution ends")
+	close(release)
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not delivered")
+	}
+	body := <-bodies
+	timestamp := req.Header.Get(model.CallbackTimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), time.Unix(sent, 0), time.Minute)
+	require.Equal(t, signCallback("s3cret", timestamp, body), req.Header.Get(model.CallbackSignatureHeader))
+	require.NotEqual(t, signCallback("s3cret", strconv.FormatInt(sent+1, 10), body), req.Header.Get(model.CallbackSignatureHeader),
+		"the timestamp is covered by the signature")
+	require.Equal(t, execution.ID, req.Header.Get(model.CallbackExecutionIDHeader))
+
+	var result model.AsyncExecution
+	require.NoError(t, json.Unmarshal(body, &result))
+	require.Equal(t, model.AsyncExecutionSucceeded, result.Status)
+	require.Equal(t, "ctx-1", result.Context)
+	require.Equal(t, "hello\n", result.Stdout)
+	require.Equal(t, []map[string]any{{"text": "4"}}, result.Results)
+	require.Equal(t, 1, result.ExecutionCount)
+	require.NotNil(t, result.FinishedAt)
+}
+
+func TestRunCodeAsyncRecordsErrorAndRetriesCallback(t *testing.T) {
+	useAsyncTestRunner(t, &fakeCodeRunner{
+		execute: func(request *runtime.ExecuteCodeRequest) error {
+			request.Hooks.OnExecuteError(&execute.ErrorOutput{EName: "ValueError", EValue: "boom"})
+			return nil
+		},
+	}, "s3cret")
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	execution := startAsyncExecution(t, `{"code":"raise ValueError('boom')","callback_url":"`+server.URL+`"}`)
+
+	require.Eventually(t, func() bool {
+		got, ok := asyncExecutions.get(execution.ID)
+		return ok && got.CallbackError != ""
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, int32(callbackAttempts), calls.Load())
+
+	ctx, w := newTestContext(http.MethodGet, "/code/async/"+execution.ID, nil)
+	ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: execution.ID})
+	NewCodeInterpretingController(ctx).GetAsyncExecution()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got model.AsyncExecution
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Equal(t, model.AsyncExecutionFailed, got.Status)
+	require.Equal(t, "ValueError", got.Error.EName)
+	require.Contains(t, got.CallbackError, "503")
+}
+
+func TestRunCodeAsyncRequiresSigningSecretForCallback(t *testing.T) {
+	useAsyncTestRunner(t, &fakeCodeRunner{}, "")
+	previousToken := flag.ServerAccessToken
+	flag.ServerAccessToken = ""
+	t.Cleanup(func() { flag.ServerAccessToken = previousToken })
+
+	body := []byte(`{"code":"echo 1","callback_url":"http://127.0.0.1:1/done"}`)
+	ctx, w := newTestContext(http.MethodPost, "/code/async", body)
+	NewCodeInterpretingController(ctx).RunCodeAsync()
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAsyncExecutionOutputLimitKeepsWholeCharacters(t *testing.T) {
+	store := newAsyncExecutionStore()
+	execution := store.start("")
+	hooks := store.hooks(execution.ID, func() {})
+
+	hooks.OnExecuteStdout(strings.Repeat("a", asyncOutputLimit-2))
+	hooks.OnExecuteStdout("世界")
+	hooks.OnExecuteStdout("b")
+
+	got, _ := store.get(execution.ID)
+	require.Equal(t, asyncOutputLimit-2, len(got.Stdout), "the character that does not fit is dropped whole, and so is all later output")
+	require.True(t, utf8.ValidString(got.Stdout))
+}
+
+func TestGetAsyncExecution_NotFound(t *testing.T) {
+	ctx, w := newTestContext(http.MethodGet, "/code/async/missing", nil)
+	ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "missing"})
+	NewCodeInterpretingController(ctx).GetAsyncExecution()
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -57,6 +57,7 @@ type codeExecutionRunner interface {
 	CreateBashSession(req *runtime.CreateContextRequest) (string, error)
 	RunInBashSession(ctx context.Context, req *runtime.ExecuteCodeRequest) error
 	SeekBackgroundCommandOutput(session string, cursor int64) ([]byte, int64, error)
+	WriteCommandStdin(session string, data []byte, eof bool) error
 	DeleteBashSession(sessionID string) error
 	Interrupt(sessionID string) error
 	CreatePTYSession(id, cwd string) (runtime.PTYSession, error)
@@ -130,6 +131,11 @@ func (c *CodeInterpretingController) RunCode() {
 		return
 	}
 
+	recordCommand("code", request.Code, request.Context.Cwd, map[string]any{
+		"language": request.Context.Language,
+		"context":  request.Context.ID,
+	})
+
 	ctx, cancel := context.WithCancel(c.ctx.Request.Context())
 	defer cancel()
 	execStart := time.Now()
@@ -352,6 +358,8 @@ func (c *CodeInterpretingController) RunInSession() {
 		return
 	}
 
+	recordCommand("session", request.Command, request.Cwd, map[string]any{"session": sessionID})
+
 	timeout := time.Duration(request.Timeout) * time.Millisecond
 	runReq := &runtime.ExecuteCodeRequest{
 		Language: runtime.Bash,
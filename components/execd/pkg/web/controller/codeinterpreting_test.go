@@ -81,6 +81,10 @@ func (f *fakeCodeRunner) SeekBackgroundCommandOutput(_ string, _ int64) ([]byte,
 	return nil, 0, nil
 }
 
+func (f *fakeCodeRunner) WriteCommandStdin(_ string, _ []byte, _ bool) error {
+	return nil
+}
+
 func (f *fakeCodeRunner) DeleteBashSession(_ string) error {
 	return nil
 }
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -51,6 +52,15 @@ func (c *CodeInterpretingController) RunCommand() {
 		return
 	}
 
+	fields := map[string]any{"background": request.Background, "interactive": request.Interactive}
+	if request.Uid != nil {
+		fields["uid"] = *request.Uid
+	}
+	if request.Gid != nil {
+		fields["gid"] = *request.Gid
+	}
+	recordCommand("command", request.Command, request.Cwd, fields)
+
 	ctx, cancel := context.WithCancel(c.ctx.Request.Context())
 	defer cancel()
 	execStart := time.Now()
@@ -100,6 +110,38 @@ func (c *CodeInterpretingController) InterruptCommand() {
 	c.interrupt()
 }
 
+// WriteCommandStdin sends input to a running command started in interactive mode. Foreground
+// commands echo the input as a stdin event on their output stream.
+func (c *CodeInterpretingController) WriteCommandStdin() {
+	commandID := c.ctx.Param("id")
+	if commandID == "" {
+		c.RespondError(http.StatusBadRequest, model.ErrorCodeMissingQuery, "missing command execution id")
+		return
+	}
+
+	var request model.CommandStdinRequest
+	if err := c.bindJSON(&request); err != nil {
+		c.RespondError(
+			http.StatusBadRequest,
+			model.ErrorCodeInvalidRequest,
+			fmt.Sprintf("error parsing request, MAYBE invalid body format. %v", err),
+		)
+		return
+	}
+
+	err := codeRunner.WriteCommandStdin(commandID, []byte(request.Data), request.EOF)
+	switch {
+	case err == nil:
+		c.RespondSuccess(nil)
+	case errors.Is(err, runtime.ErrCommandNotFound):
+		c.RespondError(http.StatusNotFound, model.ErrorCodeInvalidRequest, err.Error())
+	case errors.Is(err, runtime.ErrCommandNotInteractive), errors.Is(err, runtime.ErrCommandStdinClosed):
+		c.RespondError(http.StatusConflict, model.ErrorCodeInvalidRequest, err.Error())
+	default:
+		c.RespondError(http.StatusInternalServerError, model.ErrorCodeRuntimeError, err.Error())
+	}
+}
+
 // GetCommandStatus returns command status by id.
 func (c *CodeInterpretingController) GetCommandStatus() {
 	commandID := c.ctx.Param("id")
@@ -115,11 +157,12 @@ func (c *CodeInterpretingController) GetCommandStatus() {
 	}
 
 	resp := model.CommandStatusResponse{
-		ID:       status.Session,
-		Running:  status.Running,
-		ExitCode: status.ExitCode,
-		Error:    status.Error,
-		Content:  status.Content,
+		ID:          status.Session,
+		Running:     status.Running,
+		ExitCode:    status.ExitCode,
+		Error:       status.Error,
+		Content:     status.Content,
+		Interactive: status.Interactive,
 	}
 	if !status.StartedAt.IsZero() {
 		resp.StartedAt = status.StartedAt
@@ -155,23 +198,25 @@ func (c *CodeInterpretingController) buildExecuteCommandRequest(request model.Ru
 	timeout := time.Duration(request.TimeoutMs) * time.Millisecond
 	if request.Background {
 		return &runtime.ExecuteCodeRequest{
-			Language: runtime.BackgroundCommand,
-			Code:     request.Command,
-			Cwd:      request.Cwd,
-			Timeout:  timeout,
-			Gid:      request.Gid,
-			Uid:      request.Uid,
-			Envs:     request.Envs,
+			Language:    runtime.BackgroundCommand,
+			Code:        request.Command,
+			Cwd:         request.Cwd,
+			Timeout:     timeout,
+			Gid:         request.Gid,
+			Uid:         request.Uid,
+			Envs:        request.Envs,
+			Interactive: request.Interactive,
 		}
 	} else {
 		return &runtime.ExecuteCodeRequest{
-			Language: runtime.Command,
-			Code:     request.Command,
-			Cwd:      request.Cwd,
-			Timeout:  timeout,
-			Gid:      request.Gid,
-			Uid:      request.Uid,
-			Envs:     request.Envs,
+			Language:    runtime.Command,
+			Code:        request.Command,
+			Cwd:         request.Cwd,
+			Timeout:     timeout,
+			Gid:         request.Gid,
+			Uid:         request.Uid,
+			Envs:        request.Envs,
+			Interactive: request.Interactive,
 		}
 	}
 }
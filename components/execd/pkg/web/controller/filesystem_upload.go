@@ -156,7 +156,13 @@ func (c *FilesystemController) UploadFile() {
 			return
 		}
 
-		if _, err := io.Copy(dst, file); err != nil {
+		var out io.Writer = dst
+		sum := newFileHash()
+		if sum != nil {
+			out = io.MultiWriter(dst, sum)
+		}
+		written, err := io.Copy(out, file)
+		if err != nil {
 			dst.Close()
 			file.Close()
 			c.RespondError(
@@ -183,6 +189,7 @@ func (c *FilesystemController) UploadFile() {
 			)
 			return
 		}
+		recordFileWrite(resolvedPath, written, sum)
 	}
 
 	rec.MarkSuccess()
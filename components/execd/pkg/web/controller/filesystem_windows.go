@@ -351,6 +351,7 @@ func (c *FilesystemController) ReplaceContent() {
 			c.handleFileError(err)
 			return
 		}
+		recordContentWrite(file, []byte(newContent))
 	}
 
 	rec.MarkSuccess()
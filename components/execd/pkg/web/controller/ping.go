@@ -14,7 +14,12 @@
 
 package controller
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/alibaba/opensandbox/execd/pkg/runtime"
+	"github.com/alibaba/opensandbox/execd/pkg/web/model"
+)
 
 // MainController handles basic server operations.
 type MainController struct {
@@ -34,3 +39,18 @@ func (c *MainController) Ping() {
 func PingHandler(ctx *gin.Context) {
 	NewMainController(ctx).Ping()
 }
+
+// Capabilities reports the CPU and memory limits of the sandbox and the parallelism defaults of
+// executions.
+func (c *MainController) Capabilities() {
+	limits := runtime.DetectResourceLimits()
+	c.RespondSuccess(model.CapabilitiesResponse{
+		Resources:       limits,
+		ParallelismEnvs: limits.ParallelismEnvs(),
+	})
+}
+
+// CapabilitiesHandler is the Gin adapter.
+func CapabilitiesHandler(ctx *gin.Context) {
+	NewMainController(ctx).Capabilities()
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+
+	"github.com/alibaba/opensandbox/internal/recording"
+
+	"github.com/alibaba/opensandbox/execd/pkg/log"
+)
+
+// sessionRecorder keeps the session record of the sandbox; nil when recording is disabled.
+var sessionRecorder *recording.Recorder
+
+// InitSessionRecording enables session recording when OPENSANDBOX_RECORDING_DIR is set.
+func InitSessionRecording() error {
+	r, err := recording.FromEnv("execd")
+	if err != nil {
+		return err
+	}
+	sessionRecorder = r
+	return nil
+}
+
+// recordCommand records code or a command before it is executed.
+func recordCommand(kind, command, cwd string, fields map[string]any) {
+	if sessionRecorder == nil {
+		return
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	fields["kind"] = kind
+	fields["command"] = command
+	if cwd != "" {
+		fields["cwd"] = cwd
+	}
+	if err := sessionRecorder.Record(recording.TypeCommand, fields); err != nil {
+		log.Error("failed to record %s: %v", kind, err)
+	}
+}
+
+// newFileHash returns the hash that uploaded file contents are written through, or nil when recording
+// is disabled.
+func newFileHash() hash.Hash {
+	if sessionRecorder == nil {
+		return nil
+	}
+	return sha256.New()
+}
+
+// recordFileWrite records a file written through the API with the hash of its contents.
+func recordFileWrite(path string, size int64, sum hash.Hash) {
+	if sessionRecorder == nil || sum == nil {
+		return
+	}
+	fields := map[string]any{"path": path, "size": size, "sha256": hex.EncodeToString(sum.Sum(nil))}
+	if err := sessionRecorder.Record(recording.TypeFileWrite, fields); err != nil {
+		log.Error("failed to record write of %s: %v", path, err)
+	}
+}
+
+// recordContentWrite records a file whose whole contents were written at once.
+func recordContentWrite(path string, data []byte) {
+	sum := newFileHash()
+	if sum == nil {
+		return
+	}
+	sum.Write(data)
+	recordFileWrite(path, int64(len(data)), sum)
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alibaba/opensandbox/internal/recording"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRecording(t *testing.T) {
+	require.Nil(t, newFileHash(), "recording is disabled by default")
+	recordCommand("command", "ls", "/", nil)
+
+	dir := t.TempDir()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+	t.Setenv(recording.EnvDir, dir)
+	t.Setenv(recording.EnvKeyFile, keyFile)
+	require.NoError(t, InitSessionRecording())
+	t.Cleanup(func() { sessionRecorder = nil })
+
+	recordCommand("command", "touch a.txt", "/workspace", map[string]any{"background": false})
+	recordContentWrite("/workspace/a.txt", []byte("hi\n"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "execd.jsonl"))
+	require.NoError(t, err)
+	n, err := recording.Verify(bytes.NewReader(data), key)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Contains(t, string(data), `"command":"touch a.txt"`)
+	// sha256 of "hi\n"
+	require.Contains(t, string(data), `"sha256":"98ea6e4f216f2fb4b69fff9b3a44842c38686ca685f3f55dc48c5d3fb1107be4"`)
+}
@@ -60,18 +60,7 @@ func (c *CodeInterpretingController) setServerEventsHandler(ctx context.Context)
 			safego.Go(func() { c.ping(ctx) })
 		},
 		OnExecuteResult: func(result map[string]any, count int) {
-			var mutated map[string]any
-			if len(result) > 0 {
-				mutated = make(map[string]any)
-				for k, v := range result {
-					switch k {
-					case "text/plain":
-						mutated["text"] = v
-					default:
-						mutated[k] = v
-					}
-				}
-			}
+			mutated := normalizeResult(result)
 
 			if count > 0 {
 				event := model.ServerStreamEvent{
@@ -105,6 +94,7 @@ func (c *CodeInterpretingController) setServerEventsHandler(ctx context.Context)
 			if err == nil {
 				return
 			}
+			execute.Classify(err)
 
 			event := model.ServerStreamEvent{
 				Type:      model.StreamEventTypeError,
@@ -149,7 +139,33 @@ func (c *CodeInterpretingController) setServerEventsHandler(ctx context.Context)
 			payload := event.ToJSON()
 			c.writeSingleEvent("OnExecuteStderr", payload, true, event.Summary())
 		},
+		OnExecuteStdin: func(text string) {
+			event := model.ServerStreamEvent{
+				Type:      model.StreamEventTypeStdin,
+				Text:      text,
+				Timestamp: time.Now().UnixMilli(),
+			}
+			payload := event.ToJSON()
+			c.writeSingleEvent("OnExecuteStdin", payload, true, event.Summary())
+		},
+	}
+}
+
+// normalizeResult renames the text/plain entry of an execution result to text.
+func normalizeResult(result map[string]any) map[string]any {
+	if len(result) == 0 {
+		return nil
+	}
+	mutated := make(map[string]any, len(result))
+	for k, v := range result {
+		switch k {
+		case "text/plain":
+			mutated["text"] = v
+		default:
+			mutated[k] = v
+		}
 	}
+	return mutated
 }
 
 // writeSingleEvent serializes one SSE frame.
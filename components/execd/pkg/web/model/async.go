@@ -0,0 +1,72 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/alibaba/opensandbox/execd/pkg/jupyter/execute"
+)
+
+const (
+	// CallbackSignatureHeader carries the hex HMAC-SHA256 of the timestamp, a ".", and the callback
+	// body, prefixed with "sha256=".
+	CallbackSignatureHeader = "X-EXECD-SIGNATURE"
+	// CallbackTimestampHeader carries the Unix time in seconds the callback was sent at.
+	CallbackTimestampHeader = "X-EXECD-TIMESTAMP"
+	// CallbackExecutionIDHeader carries the id of the execution the callback reports.
+	CallbackExecutionIDHeader = "X-EXECD-EXECUTION-ID"
+)
+
+// RunCodeAsyncRequest starts a code execution that runs detached from the request.
+type RunCodeAsyncRequest struct {
+	RunCodeRequest `json:",inline"`
+	// CallbackURL receives the AsyncExecution as a signed POST once the execution ends.
+	CallbackURL string `json:"callback_url,omitempty" validate:"omitempty,http_url"`
+}
+
+func (r *RunCodeAsyncRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type AsyncExecutionStatus string
+
+const (
+	AsyncExecutionRunning   AsyncExecutionStatus = "running"
+	AsyncExecutionSucceeded AsyncExecutionStatus = "succeeded"
+	AsyncExecutionFailed    AsyncExecutionStatus = "failed"
+)
+
+// AsyncExecution is the handle and, once finished, the structured result of an asynchronous execution.
+type AsyncExecution struct {
+	ID     string               `json:"id"`
+	Status AsyncExecutionStatus `json:"status"`
+	// Context is the execution context the code runs in, as reported by the runtime.
+	Context        string               `json:"context,omitempty"`
+	Stdout         string               `json:"stdout,omitempty"`
+	Stderr         string               `json:"stderr,omitempty"`
+	Results        []map[string]any     `json:"results,omitempty"`
+	ExecutionCount int                  `json:"execution_count,omitempty"`
+	ExecutionTime  int64                `json:"execution_time,omitempty"`
+	Error          *execute.ErrorOutput `json:"error,omitempty"`
+	StartedAt      time.Time            `json:"started_at"`
+	FinishedAt     *time.Time           `json:"finished_at,omitempty"`
+	CallbackURL    string               `json:"callback_url,omitempty"`
+	// CallbackError is the last error delivering the callback, if it could not be delivered.
+	CallbackError string `json:"callback_error,omitempty"`
+}
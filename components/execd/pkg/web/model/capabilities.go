@@ -0,0 +1,26 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "github.com/alibaba/opensandbox/execd/pkg/runtime"
+
+// CapabilitiesResponse advertises the resources of the sandbox, so callers can size the parallelism of
+// the code they run.
+type CapabilitiesResponse struct {
+	Resources runtime.ResourceLimits `json:"resources"`
+	// ParallelismEnvs are the thread pool sizes set in the environment of commands and bash sessions
+	// unless the environment of execd or the request sets them.
+	ParallelismEnvs map[string]string `json:"parallelism_envs,omitempty"`
+}
@@ -53,6 +53,8 @@ type RunCommandRequest struct {
 	Command    string `json:"command" validate:"required"`
 	Cwd        string `json:"cwd,omitempty"`
 	Background bool   `json:"background,omitempty"`
+	// Interactive keeps stdin open for input sent to /command/{id}/stdin.
+	Interactive bool `json:"interactive,omitempty"`
 	// TimeoutMs caps execution duration; 0 uses server default.
 	TimeoutMs int64 `json:"timeout,omitempty" validate:"omitempty,gte=1"`
 
@@ -80,6 +82,7 @@ const (
 	StreamEventTypeError    ServerStreamEventType = "error"
 	StreamEventTypeStdout   ServerStreamEventType = "stdout"
 	StreamEventTypeStderr   ServerStreamEventType = "stderr"
+	StreamEventTypeStdin    ServerStreamEventType = "stdin"
 	StreamEventTypeResult   ServerStreamEventType = "result"
 	StreamEventTypeComplete ServerStreamEventType = "execution_complete"
 	StreamEventTypeCount    ServerStreamEventType = "execution_count"
@@ -25,4 +25,13 @@ type CommandStatusResponse struct {
 	Error      string     `json:"error,omitempty"`
 	StartedAt  time.Time  `json:"started_at,omitempty"`
 	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Interactive is set for commands that accept input on stdin.
+	Interactive bool `json:"interactive,omitempty"`
+}
+
+// CommandStdinRequest sends input to a command started in interactive mode.
+type CommandStdinRequest struct {
+	Data string `json:"data,omitempty"`
+	// EOF closes stdin after Data has been written.
+	EOF bool `json:"eof,omitempty"`
 }
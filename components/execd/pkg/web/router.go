@@ -15,6 +15,7 @@
 package web
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -22,16 +23,21 @@ import (
 	"github.com/alibaba/opensandbox/execd/pkg/log"
 	"github.com/alibaba/opensandbox/execd/pkg/web/controller"
 	"github.com/alibaba/opensandbox/execd/pkg/web/model"
+	"github.com/alibaba/opensandbox/internal/k8sauth"
 )
 
-// NewRouter builds a Gin engine with all execd routes.
-func NewRouter(accessToken string) *gin.Engine {
+// NewRouter builds a Gin engine with all execd routes. Requests are accepted with the access token,
+// or with a projected service-account token when auth is set.
+func NewRouter(accessToken string, auth *k8sauth.Authenticator) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
-	r.Use(logMiddleware(), otelHTTPMetricsMiddleware(), accessTokenMiddleware(accessToken), ProxyMiddleware())
+	r.Use(logMiddleware(), otelHTTPMetricsMiddleware(), accessTokenMiddleware(accessToken, auth), ProxyMiddleware())
 
 	r.GET("/ping", controller.PingHandler)
+	r.GET("/capabilities", controller.CapabilitiesHandler)
+	r.GET("/loglevel", gin.WrapH(log.LevelHandler()))
+	r.PUT("/loglevel", gin.WrapH(log.LevelHandler()))
 
 	files := r.Group("/files")
 	{
@@ -55,6 +61,8 @@ func NewRouter(accessToken string) *gin.Engine {
 	{
 		code.POST("", withCode(func(c *controller.CodeInterpretingController) { c.RunCode() }))
 		code.DELETE("", withCode(func(c *controller.CodeInterpretingController) { c.InterruptCode() }))
+		code.POST("/async", withCode(func(c *controller.CodeInterpretingController) { c.RunCodeAsync() }))
+		code.GET("/async/:id", withCode(func(c *controller.CodeInterpretingController) { c.GetAsyncExecution() }))
 		code.POST("/context", withCode(func(c *controller.CodeInterpretingController) { c.CreateContext() }))
 		code.GET("/contexts", withCode(func(c *controller.CodeInterpretingController) { c.ListContexts() }))
 		code.DELETE("/contexts", withCode(func(c *controller.CodeInterpretingController) { c.DeleteContextsByLanguage() }))
@@ -75,6 +83,7 @@ func NewRouter(accessToken string) *gin.Engine {
 		command.DELETE("", withCode(func(c *controller.CodeInterpretingController) { c.InterruptCommand() }))
 		command.GET("/status/:id", withCode(func(c *controller.CodeInterpretingController) { c.GetCommandStatus() }))
 		command.GET("/:id/logs", withCode(func(c *controller.CodeInterpretingController) { c.GetBackgroundCommandOutput() }))
+		command.POST("/:id/stdin", withCode(func(c *controller.CodeInterpretingController) { c.WriteCommandStdin() }))
 	}
 
 	metric := r.Group("/metrics")
@@ -118,28 +127,46 @@ func withPTY(fn func(*controller.PTYController)) gin.HandlerFunc {
 	}
 }
 
-func accessTokenMiddleware(token string) gin.HandlerFunc {
+func accessTokenMiddleware(token string, auth *k8sauth.Authenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if token == "" {
+		if token == "" && auth == nil {
 			ctx.Next()
 			return
 		}
 
 		requestedToken := ctx.GetHeader(model.ApiAccessTokenHeader)
-		if requestedToken == "" || requestedToken != token {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, map[string]any{
-				"error": "Unauthorized: invalid or missing header " + model.ApiAccessTokenHeader,
+		if token != "" && requestedToken == token {
+			ctx.Next()
+			return
+		}
+		// The access token takes precedence, so an Authorization header meant for a proxied
+		// service is only inspected when no valid access token was sent.
+		if auth != nil && k8sauth.BearerToken(ctx.Request) != "" {
+			_, err := auth.Authenticate(ctx.Request)
+			if err == nil {
+				ctx.Next()
+				return
+			}
+			status := http.StatusUnauthorized
+			if errors.Is(err, k8sauth.ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			log.Warn("rejected service account token: %v", err)
+			ctx.AbortWithStatusJSON(status, map[string]any{
+				"error": http.StatusText(status) + ": " + err.Error(),
 			})
 			return
 		}
 
-		ctx.Next()
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, map[string]any{
+			"error": "Unauthorized: invalid or missing header " + model.ApiAccessTokenHeader,
+		})
 	}
 }
 
 func logMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		log.Info("Requested: %v - %v", ctx.Request.Method, ctx.Request.URL.String())
+		log.ForRequest(ctx.Request).Infof("Requested: %v - %v", ctx.Request.Method, ctx.Request.URL.String())
 		ctx.Next()
 	}
 }
@@ -0,0 +1,237 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sauth authenticates callers of the sandbox sidecars with Kubernetes projected
+// service-account tokens.
+//
+// A caller mounts a token projected for the sidecar audience and sends it as a bearer token.
+// The sidecar verifies it either with a TokenReview against the API server, which also
+// rejects tokens of deleted pods and service accounts, or locally against the cluster's JWKS,
+// which needs no API server call per token. Controllers and users thereby authenticate with
+// the identities they already have instead of shared secrets.
+package k8sauth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/jwks"
+)
+
+const (
+	// ModeTokenReview verifies tokens with the TokenReview API.
+	ModeTokenReview = "tokenreview"
+	// ModeJWKS verifies token signatures locally against the issuer's JWKS.
+	ModeJWKS = "jwks"
+
+	// DefaultAudience is the audience sidecar tokens are projected for.
+	DefaultAudience = "opensandbox-sidecar"
+
+	EnvAuthMode            = "OPENSANDBOX_AUTH_MODE"
+	EnvAuthAudience        = "OPENSANDBOX_AUTH_AUDIENCE"
+	EnvAuthIssuer          = "OPENSANDBOX_AUTH_ISSUER"
+	EnvAuthJWKSURL         = "OPENSANDBOX_AUTH_JWKS_URL"
+	EnvAuthAllowedSubjects = "OPENSANDBOX_AUTH_ALLOWED_SUBJECTS"
+
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+var (
+	// ErrMissingToken is returned when the request carries no bearer token.
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrForbidden is returned when the token is valid but its subject is not allowed.
+	ErrForbidden = errors.New("subject is not allowed")
+)
+
+// Identity is the authenticated caller.
+type Identity = jwks.Identity
+
+// Verifier checks a bearer token and returns its identity.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Identity, error)
+}
+
+// Config selects how tokens are verified and who may call.
+type Config struct {
+	// Mode is ModeTokenReview or ModeJWKS. Empty disables token authentication.
+	Mode string
+	// Audience the token must be issued for. Defaults to DefaultAudience.
+	Audience string
+	// Issuer the token must be issued by, checked in ModeJWKS. Empty skips the check.
+	Issuer string
+	// JWKSURL is where ModeJWKS fetches keys. Defaults to the API server's /openid/v1/jwks.
+	JWKSURL string
+	// AllowedSubjects restricts callers to these usernames, or groups written as "group:<name>".
+	// It must not be empty when Mode is set.
+	AllowedSubjects []string
+}
+
+// ConfigFromEnv reads the OPENSANDBOX_AUTH_* variables.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Mode:     strings.ToLower(strings.TrimSpace(os.Getenv(EnvAuthMode))),
+		Audience: strings.TrimSpace(os.Getenv(EnvAuthAudience)),
+		Issuer:   strings.TrimSpace(os.Getenv(EnvAuthIssuer)),
+		JWKSURL:  strings.TrimSpace(os.Getenv(EnvAuthJWKSURL)),
+	}
+	for _, s := range strings.Split(os.Getenv(EnvAuthAllowedSubjects), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.AllowedSubjects = append(cfg.AllowedSubjects, s)
+		}
+	}
+	return cfg
+}
+
+// Authenticator verifies bearer tokens of incoming requests.
+type Authenticator struct {
+	verifier Verifier
+	allowed  []string
+}
+
+// New builds an Authenticator for cfg using the in-cluster API server. It returns nil when
+// cfg.Mode is empty, and an error when cfg allows no subject.
+func New(cfg Config) (*Authenticator, error) {
+	if cfg.Mode == "" {
+		return nil, nil
+	}
+	if len(cfg.AllowedSubjects) == 0 {
+		return nil, fmt.Errorf("auth mode %q requires at least one allowed subject in %s", cfg.Mode, EnvAuthAllowedSubjects)
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = DefaultAudience
+	}
+	apiServer, err := inClusterAPIServer()
+	if err != nil {
+		return nil, err
+	}
+	var v Verifier
+	switch cfg.Mode {
+	case ModeTokenReview:
+		v = NewTokenReviewVerifier(apiServer, cfg.Audience)
+	case ModeJWKS:
+		url := cfg.JWKSURL
+		if url == "" {
+			url = apiServer.Host + "/openid/v1/jwks"
+		}
+		v = NewJWKSVerifier(apiServer, url, cfg.Issuer, cfg.Audience)
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q, want %q or %q", cfg.Mode, ModeTokenReview, ModeJWKS)
+	}
+	return NewAuthenticator(v, cfg.AllowedSubjects), nil
+}
+
+// NewJWKSVerifier verifies tokens against the key set at url. The sidecar's own token
+// authenticates the fetch only when url is served by the API server.
+func NewJWKSVerifier(apiServer *APIServer, url, issuer, audience string) *jwks.Verifier {
+	var token jwks.TokenSource
+	if jwks.SameOrigin(url, apiServer.Host) {
+		token = apiServer.token
+	}
+	return jwks.NewVerifier(apiServer.Client, url, issuer, audience, token)
+}
+
+// NewAuthenticator wraps a verifier with a subject allowlist. An empty allowlist admits nobody.
+func NewAuthenticator(v Verifier, allowedSubjects []string) *Authenticator {
+	return &Authenticator{verifier: v, allowed: allowedSubjects}
+}
+
+// Authenticate verifies the bearer token of r and checks its subject against the allowlist.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token := BearerToken(r)
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	id, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allows(id) {
+		return id, fmt.Errorf("%w: %s", ErrForbidden, id.Username)
+	}
+	return id, nil
+}
+
+func (a *Authenticator) allows(id *Identity) bool {
+	for _, s := range a.allowed {
+		if group, ok := strings.CutPrefix(s, "group:"); ok {
+			if slices.Contains(id.Groups, group) {
+				return true
+			}
+		} else if s == id.Username {
+			return true
+		}
+	}
+	return false
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or "".
+func BearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// APIServer is how verifiers reach the Kubernetes API server.
+type APIServer struct {
+	// Host is the base URL, e.g. https://10.96.0.1:443.
+	Host string
+	// Client trusts the cluster CA.
+	Client *http.Client
+	// TokenFile holds the sidecar's own service-account token. It is re-read on every use
+	// because the kubelet rotates it.
+	TokenFile string
+}
+
+func (s *APIServer) token() (string, error) {
+	b, err := os.ReadFile(s.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func inClusterAPIServer() (*APIServer, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST or KUBERNETES_SERVICE_PORT is unset")
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	pool.AppendCertsFromPEM(ca)
+	return &APIServer{
+		Host: "https://" + net.JoinHostPort(host, port),
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		},
+		TokenFile: serviceAccountDir + "/token",
+	}, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testAPIServer(t *testing.T, h http.Handler) *APIServer {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("sidecar-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &APIServer{Host: srv.URL, Client: srv.Client(), TokenFile: tokenFile}
+}
+
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": alg, "kid": kid}) + "." + enc(claims)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func saClaims(aud any, exp time.Time) map[string]any {
+	return map[string]any{
+		"iss": "https://kubernetes.default.svc",
+		"sub": "system:serviceaccount:opensandbox-system:controller",
+		"aud": aud,
+		"exp": exp.Unix(),
+		"kubernetes.io": map[string]any{
+			"namespace":      "opensandbox-system",
+			"serviceaccount": map[string]string{"name": "controller", "uid": "sa-uid"},
+		},
+	}
+}
+
+func TestTokenReviewVerifier(t *testing.T) {
+	var calls atomic.Int32
+	api := testAPIServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/apis/authentication.k8s.io/v1/tokenreviews" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sidecar-token" {
+			t.Errorf("unexpected reviewer credentials %q", got)
+		}
+		review := &tokenReview{}
+		_ = json.NewDecoder(r.Body).Decode(review)
+		if len(review.Spec.Audiences) != 1 || review.Spec.Audiences[0] != DefaultAudience {
+			t.Errorf("unexpected audiences %v", review.Spec.Audiences)
+		}
+		switch review.Spec.Token {
+		case "good":
+			review.Status.Authenticated = true
+			review.Status.Audiences = review.Spec.Audiences
+			review.Status.User.Username = "system:serviceaccount:opensandbox-system:controller"
+			review.Status.User.Groups = []string{"system:serviceaccounts"}
+		case "wrong-audience":
+			review.Status.Authenticated = true
+			review.Status.Audiences = []string{"https://kubernetes.default.svc"}
+		default:
+			review.Status.Error = "invalid bearer token"
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+	v := NewTokenReviewVerifier(api, DefaultAudience)
+	now := time.Unix(1000, 0)
+	v.now = func() time.Time { return now }
+
+	id, err := v.Verify(context.Background(), "good")
+	if err != nil || id.Username != "system:serviceaccount:opensandbox-system:controller" {
+		t.Fatalf("Verify(good) = %v, %v", id, err)
+	}
+	if _, err := v.Verify(context.Background(), "good"); err != nil || calls.Load() != 1 {
+		t.Fatalf("expected the second review to be cached, calls=%d err=%v", calls.Load(), err)
+	}
+	now = now.Add(tokenReviewCacheTTL + time.Second)
+	if _, err := v.Verify(context.Background(), "good"); err != nil || calls.Load() != 2 {
+		t.Fatalf("expected the review to be repeated after the TTL, calls=%d err=%v", calls.Load(), err)
+	}
+	if _, err := v.Verify(context.Background(), "wrong-audience"); err == nil {
+		t.Fatal("expected a token for another audience to be rejected")
+	}
+	if _, err := v.Verify(context.Background(), "bad"); err == nil || !strings.Contains(err.Error(), "invalid bearer token") {
+		t.Fatalf("Verify(bad) err = %v", err)
+	}
+}
+
+func TestNewJWKSVerifier(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	var credentials atomic.Value
+	serveKeys := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credentials.Store(r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())},
+		}})
+	})
+	api := testAPIServer(t, serveKeys)
+	issuer := httptest.NewServer(serveKeys)
+	t.Cleanup(issuer.Close)
+	token := signJWT(t, "RS256", "rsa", key, saClaims(DefaultAudience, time.Now().Add(time.Hour)))
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "api server", url: api.Host + "/openid/v1/jwks", want: "Bearer sidecar-token"},
+		{name: "third-party issuer", url: issuer.URL + "/keys", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentials.Store("unset")
+			id, err := NewJWKSVerifier(api, tt.url, "", DefaultAudience).Verify(context.Background(), token)
+			if err != nil || id.UID != "sa-uid" {
+				t.Fatalf("Verify() = %+v, %v", id, err)
+			}
+			if got := credentials.Load(); got != tt.want {
+				t.Fatalf("JWKS fetch credentials = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type staticVerifier map[string]*Identity
+
+func (s staticVerifier) Verify(_ context.Context, token string) (*Identity, error) {
+	if id, ok := s[token]; ok {
+		return id, nil
+	}
+	return nil, errors.New("token rejected")
+}
+
+func TestAuthenticator(t *testing.T) {
+	v := staticVerifier{
+		"controller": {Username: "system:serviceaccount:opensandbox-system:controller"},
+		"user":       {Username: "alice", Groups: []string{"sandbox-admins"}},
+		"other":      {Username: "bob"},
+	}
+	a := NewAuthenticator(v, []string{"system:serviceaccount:opensandbox-system:controller", "group:sandbox-admins"})
+	request := func(header string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		return r
+	}
+	if _, err := a.Authenticate(request("")); !errors.Is(err, ErrMissingToken) {
+		t.Errorf("missing token err = %v", err)
+	}
+	if _, err := a.Authenticate(request("Basic controller")); !errors.Is(err, ErrMissingToken) {
+		t.Errorf("non-bearer err = %v", err)
+	}
+	if _, err := a.Authenticate(request("Bearer controller")); err != nil {
+		t.Errorf("allowed user err = %v", err)
+	}
+	if _, err := a.Authenticate(request("Bearer user")); err != nil {
+		t.Errorf("allowed group err = %v", err)
+	}
+	if _, err := a.Authenticate(request("Bearer other")); !errors.Is(err, ErrForbidden) {
+		t.Errorf("disallowed subject err = %v", err)
+	}
+	if _, err := NewAuthenticator(v, nil).Authenticate(request("Bearer controller")); !errors.Is(err, ErrForbidden) {
+		t.Errorf("empty allowlist err = %v", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvAuthMode, " JWKS ")
+	t.Setenv(EnvAuthAllowedSubjects, "alice, group:admins,,")
+	cfg := ConfigFromEnv()
+	if cfg.Mode != ModeJWKS || len(cfg.AllowedSubjects) != 2 || cfg.AllowedSubjects[1] != "group:admins" {
+		t.Fatalf("ConfigFromEnv() = %+v", cfg)
+	}
+	t.Setenv(EnvAuthAllowedSubjects, "")
+	if _, err := New(ConfigFromEnv()); err == nil || !strings.Contains(err.Error(), EnvAuthAllowedSubjects) {
+		t.Fatalf("New() without allowed subjects err = %v", err)
+	}
+	t.Setenv(EnvAuthMode, "")
+	if a, err := New(ConfigFromEnv()); a != nil || err != nil {
+		t.Fatalf("New() with auth disabled = %v, %v", a, err)
+	}
+}
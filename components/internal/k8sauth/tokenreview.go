@@ -0,0 +1,155 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	tokenReviewCacheTTL  = 10 * time.Second
+	tokenReviewCacheSize = 1024
+)
+
+// TokenReviewVerifier verifies tokens with the TokenReview API. The sidecar's service account
+// needs create on tokenreviews, e.g. through the system:auth-delegator ClusterRole.
+// Successful reviews are cached briefly so a burst of requests costs one API call.
+type TokenReviewVerifier struct {
+	apiServer *APIServer
+	audience  string
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedReview
+	now   func() time.Time
+}
+
+type cachedReview struct {
+	id      *Identity
+	expires time.Time
+}
+
+func NewTokenReviewVerifier(apiServer *APIServer, audience string) *TokenReviewVerifier {
+	return &TokenReviewVerifier{
+		apiServer: apiServer,
+		audience:  audience,
+		cache:     make(map[[sha256.Size]byte]cachedReview),
+		now:       time.Now,
+	}
+}
+
+type tokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       tokenReviewSpec   `json:"spec"`
+	Status     tokenReviewStatus `json:"status,omitempty"`
+}
+
+type tokenReviewSpec struct {
+	Token     string   `json:"token"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+type tokenReviewStatus struct {
+	Authenticated bool     `json:"authenticated"`
+	Audiences     []string `json:"audiences,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	User          struct {
+		Username string   `json:"username"`
+		UID      string   `json:"uid"`
+		Groups   []string `json:"groups"`
+	} `json:"user"`
+}
+
+func (v *TokenReviewVerifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	key := sha256.Sum256([]byte(token))
+	now := v.now()
+	v.mu.Lock()
+	if c, ok := v.cache[key]; ok && now.Before(c.expires) {
+		v.mu.Unlock()
+		return c.id, nil
+	}
+	v.mu.Unlock()
+
+	review, err := v.review(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, fmt.Errorf("token rejected: %s", review.Status.Error)
+		}
+		return nil, errors.New("token rejected")
+	}
+	if !slices.Contains(review.Status.Audiences, v.audience) {
+		return nil, fmt.Errorf("token is not issued for audience %q", v.audience)
+	}
+	id := &Identity{
+		Username: review.Status.User.Username,
+		UID:      review.Status.User.UID,
+		Groups:   review.Status.User.Groups,
+	}
+
+	v.mu.Lock()
+	if len(v.cache) >= tokenReviewCacheSize {
+		clear(v.cache)
+	}
+	v.cache[key] = cachedReview{id: id, expires: now.Add(tokenReviewCacheTTL)}
+	v.mu.Unlock()
+	return id, nil
+}
+
+func (v *TokenReviewVerifier) review(ctx context.Context, token string) (*tokenReview, error) {
+	body, err := json.Marshal(tokenReview{
+		APIVersion: "authentication.k8s.io/v1",
+		Kind:       "TokenReview",
+		Spec:       tokenReviewSpec{Token: token, Audiences: []string{v.audience}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		v.apiServer.Host+"/apis/authentication.k8s.io/v1/tokenreviews", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	self, err := v.apiServer.token()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+self)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := v.apiServer.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to review token: unexpected status %d", resp.StatusCode)
+	}
+	review := &tokenReview{}
+	if err := json.NewDecoder(resp.Body).Decode(review); err != nil {
+		return nil, fmt.Errorf("failed to decode token review: %w", err)
+	}
+	return review, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field keys shared by all components, so log lines of the sidecars in one
+// pod can be joined on them. The task-executor uses the same keys.
+const (
+	FieldPod       = "pod"
+	FieldNamespace = "namespace"
+	FieldSandbox   = "sandbox_id"
+	FieldTask      = "task"
+	FieldTraceID   = "trace_id"
+)
+
+const (
+	// EnvPodName and EnvPodNamespace are expected to come from the downward API.
+	EnvPodName      = "POD_NAME"
+	EnvPodNamespace = "POD_NAMESPACE"
+
+	traceparentHeader = "traceparent"
+)
+
+// PodFields returns the pod and namespace fields for the set of EnvPodName
+// and EnvPodNamespace.
+func PodFields() []Field {
+	var fields []Field
+	if v := strings.TrimSpace(os.Getenv(EnvPodName)); v != "" {
+		fields = append(fields, Field{Key: FieldPod, Value: v})
+	}
+	if v := strings.TrimSpace(os.Getenv(EnvPodNamespace)); v != "" {
+		fields = append(fields, Field{Key: FieldNamespace, Value: v})
+	}
+	return fields
+}
+
+// TraceID returns the trace ID of r: the one of the span in its context if
+// there is one, otherwise the one in its W3C traceparent header.
+func TraceID(r *http.Request) string {
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return parseTraceparent(r.Header.Get(traceparentHeader))
+}
+
+// WithTrace returns l with the trace ID of r attached, or l when r has none.
+func WithTrace(l Logger, r *http.Request) Logger {
+	if id := TraceID(r); id != "" {
+		return l.With(Field{Key: FieldTraceID, Value: id})
+	}
+	return l
+}
+
+// parseTraceparent extracts the trace ID from a traceparent header of the
+// form version-traceid-parentid-flags.
+func parseTraceparent(v string) string {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ""
+	}
+	id, err := trace.TraceIDFromHex(parts[1])
+	if err != nil || !id.IsValid() {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelHandler returns an http.Handler for changing the level of l at runtime.
+// GET reports the current level as {"level":"info"}; PUT with the same body
+// changes it. Loggers derived from l with With or Named follow the change.
+// It returns false when l was not created by this package.
+func LevelHandler(l Logger) (http.Handler, bool) {
+	zl, ok := l.(*zapLogger)
+	if !ok {
+		return nil, false
+	}
+	return zl.level, true
+}
+
+// SetLevel changes the level of l and of every logger derived from it.
+func SetLevel(l Logger, level string) error {
+	zl, ok := l.(*zapLogger)
+	if !ok {
+		return fmt.Errorf("logger %T does not support level changes", l)
+	}
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	zl.level.SetLevel(lvl)
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readLines(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	defer f.Close()
+	var lines []map[string]any
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestLevelHandlerChangesDerivedLoggers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	base := MustNew(Config{Level: "info", OutputPaths: []string{path}})
+	named := base.Named("child").With(Field{Key: FieldPod, Value: "p-0"})
+
+	named.Debugf("hidden")
+	h, ok := LevelHandler(base)
+	if !ok {
+		t.Fatal("LevelHandler not supported")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	named.Debugf("shown")
+	_ = base.Sync()
+
+	lines := readLines(t, path)
+	if len(lines) != 1 || lines[0]["msg"] != "shown" || lines[0][FieldPod] != "p-0" {
+		t.Fatalf("unexpected lines: %v", lines)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+	if !strings.Contains(rec.Body.String(), `"debug"`) {
+		t.Fatalf("GET body = %s", rec.Body.String())
+	}
+
+	if err := SetLevel(named, "error"); err != nil {
+		t.Fatalf("SetLevel: %v", err)
+	}
+	if err := SetLevel(base, "loud"); err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"garbage", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			r.Header.Set("traceparent", tt.header)
+		}
+		if got := TraceID(r); got != tt.want {
+			t.Errorf("TraceID(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestPodFields(t *testing.T) {
+	t.Setenv(EnvPodName, "sbx-0")
+	t.Setenv(EnvPodNamespace, "")
+
+	fields := PodFields()
+	if len(fields) != 1 || fields[0].Key != FieldPod || fields[0].Value != "sbx-0" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
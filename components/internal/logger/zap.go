@@ -148,7 +148,7 @@ func newWithRotate(cfg Config, extra ...zapcore.Core) (Logger, error) {
 			return zapcore.NewSamplerWithOptions(c, time.Second, 100, 100)
 		}),
 	)
-	return &zapLogger{base: base, sugar: base.Sugar()}, nil
+	return &zapLogger{base: base, sugar: base.Sugar(), level: atom}, nil
 }
 
 // validateOutputPath fails fast on bad paths/permissions by trying to open the
@@ -210,6 +210,8 @@ func AsZapSugared(l Logger) (*zap.SugaredLogger, bool) {
 type zapLogger struct {
 	base  *zap.Logger
 	sugar *zap.SugaredLogger
+	// level is shared by every logger derived with With or Named.
+	level zap.AtomicLevel
 }
 
 func (l *zapLogger) Debugf(template string, args ...any) {
@@ -237,12 +239,12 @@ func (l *zapLogger) With(fields ...Field) Logger {
 		zfs = append(zfs, zap.Any(f.Key, f.Value))
 	}
 	nb := l.base.With(zfs...)
-	return &zapLogger{base: nb, sugar: nb.Sugar()}
+	return &zapLogger{base: nb, sugar: nb.Sugar(), level: l.level}
 }
 
 func (l *zapLogger) Named(name string) Logger {
 	nb := l.base.Named(name)
-	return &zapLogger{base: nb, sugar: nb.Sugar()}
+	return &zapLogger{base: nb, sugar: nb.Sugar(), level: l.level}
 }
 
 func (l *zapLogger) Sync() error {
@@ -0,0 +1,251 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recording keeps the session record of a sandbox: an append-only log of the commands run, the
+// files written and the egress decisions taken while the sandbox is allocated.
+//
+// Every component writes <dir>/<source>.jsonl, one Entry per line. Each entry carries an HMAC-SHA256 of
+// itself, which covers the HMAC of the entry before it, so removing or editing a line breaks the chain
+// and is detected by Verify. The HMAC is keyed with the recording key, read from a file such as a mounted
+// Secret that the workload cannot read: the record directory is writable by the workload, and an unkeyed
+// hash could simply be recomputed after an edit. The task-executor packages and uploads the directory
+// when the sandbox is released.
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvDir names the directory, shared by all containers of the sandbox, that records are written to.
+// Recording is disabled when it is unset.
+const EnvDir = "OPENSANDBOX_RECORDING_DIR"
+
+// EnvKeyFile names the file holding the recording key. It is required when EnvDir is set.
+const EnvKeyFile = "OPENSANDBOX_RECORDING_KEY_FILE"
+
+// MinKeySize is the minimum length of the recording key.
+const MinKeySize = 32
+
+// Entry types written by the sandbox components.
+const (
+	TypeCommand   = "command"
+	TypeFileWrite = "file_write"
+	TypeEgress    = "egress"
+	TypeTask      = "task"
+)
+
+// ErrBrokenChain is returned by Verify when an entry does not follow the one before it.
+var ErrBrokenChain = errors.New("recording: hash chain is broken")
+
+// Entry is one line of a record file.
+type Entry struct {
+	Seq    uint64         `json:"seq"`
+	Time   time.Time      `json:"time"`
+	Source string         `json:"source"`
+	Type   string         `json:"type"`
+	Fields map[string]any `json:"fields,omitempty"`
+	// Prev is the hash of the previous entry, empty for the first one.
+	Prev string `json:"prev"`
+	// Hash is the hex HMAC-SHA256 of the entry encoded without Hash, keyed with the recording key.
+	Hash string `json:"hash,omitempty"`
+}
+
+func (e Entry) sum(key []byte) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// LoadKey reads the recording key from path. Surrounding whitespace is ignored.
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("recording: read key: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("recording: key in %s is shorter than %d bytes", path, MinKeySize)
+	}
+	return key, nil
+}
+
+// Recorder appends entries of one source. A nil Recorder records nothing, so callers do not need to
+// check whether recording is enabled.
+//
+// The file is opened for every entry instead of being held open: the task-executor moves it away when
+// it seals the record, and the next entry starts a new file that continues the chain.
+type Recorder struct {
+	mu     sync.Mutex
+	source string
+	path   string
+	key    []byte
+	seq    uint64
+	prev   string
+	now    func() time.Time
+}
+
+// New returns a Recorder writing <dir>/<source>.jsonl, keyed with key. It continues the chain of an
+// existing file, so a restarted component does not start over.
+func New(dir, source string, key []byte) (*Recorder, error) {
+	if source == "" || strings.ContainsAny(source, `/\`) {
+		return nil, fmt.Errorf("recording: invalid source %q", source)
+	}
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("recording: key is shorter than %d bytes", MinKeySize)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("recording: create %s: %w", dir, err)
+	}
+	r := &Recorder{source: source, path: filepath.Join(dir, source+".jsonl"), key: key, now: time.Now}
+	last, err := lastEntry(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		r.seq, r.prev = last.Seq, last.Hash
+	}
+	return r, nil
+}
+
+// FromEnv returns a Recorder for source in the directory named by EnvDir, keyed with the key in the file
+// named by EnvKeyFile, or nil when EnvDir is unset.
+func FromEnv(source string) (*Recorder, error) {
+	dir := strings.TrimSpace(os.Getenv(EnvDir))
+	if dir == "" {
+		return nil, nil
+	}
+	keyFile := strings.TrimSpace(os.Getenv(EnvKeyFile))
+	if keyFile == "" {
+		return nil, fmt.Errorf("recording: %s is required with %s", EnvKeyFile, EnvDir)
+	}
+	key, err := LoadKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return New(dir, source, key)
+}
+
+// Record appends an entry of the given type.
+func (r *Recorder) Record(entryType string, fields map[string]any) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := Entry{
+		Seq:    r.seq + 1,
+		Time:   r.now().UTC(),
+		Source: r.source,
+		Type:   entryType,
+		Fields: fields,
+		Prev:   r.prev,
+	}
+	hash, err := entry.sum(r.key)
+	if err != nil {
+		return fmt.Errorf("recording: encode entry: %w", err)
+	}
+	entry.Hash = hash
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("recording: encode entry: %w", err)
+	}
+
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("recording: open %s: %w", r.path, err)
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("recording: write %s: %w", r.path, err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("recording: close %s: %w", r.path, err)
+	}
+	r.seq, r.prev = entry.Seq, entry.Hash
+	return nil
+}
+
+// Verify reads a record file and checks that the HMAC of every entry under key matches its Hash and
+// that it follows the entry before it. The first entry may continue a chain started in an earlier file.
+// It returns the number of entries read.
+func Verify(rd io.Reader, key []byte) (int, error) {
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var (
+		count int
+		prev  *Entry
+	)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return count, fmt.Errorf("recording: entry %d: %w", count+1, err)
+		}
+		hash, err := entry.sum(key)
+		if err != nil {
+			return count, err
+		}
+		if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+			return count, fmt.Errorf("%w: entry %d does not match its hash", ErrBrokenChain, entry.Seq)
+		}
+		if prev != nil && (entry.Prev != prev.Hash || entry.Seq != prev.Seq+1) {
+			return count, fmt.Errorf("%w: entry %d does not follow entry %d", ErrBrokenChain, entry.Seq, prev.Seq)
+		}
+		prev = &entry
+		count++
+	}
+	return count, scanner.Err()
+}
+
+func lastEntry(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("recording: read %s: %w", path, err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) == 0 {
+		return nil, nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(last, &entry); err != nil {
+		return nil, fmt.Errorf("recording: last entry of %s: %w", path, err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func readRecord(t *testing.T, dir, source string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, source+".jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRecorderChain(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(dir, "execd", testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(TypeCommand, map[string]any{"command": "ls -la", "cwd": "/workspace"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(TypeFileWrite, map[string]any{"path": "/workspace/a.txt", "sha256": "abc", "size": 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted component continues the chain of the existing file.
+	r, err = New(dir, "execd", testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(TypeCommand, map[string]any{"command": "cat a.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Verify(bytes.NewReader(readRecord(t, dir, "execd")), testKey)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("Verify() read %d entries, want 3", n)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(dir, "egress", testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, domain := range []string{"a.example.com.", "b.example.com.", "c.example.com."} {
+		if err := r.Record(TypeEgress, map[string]any{"domain": domain, "action": "deny"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.SplitAfter(string(readRecord(t, dir, "egress")), "\n")
+
+	edited := strings.Replace(strings.Join(lines, ""), "b.example.com.", "x.example.com.", 1)
+	if _, err := Verify(strings.NewReader(edited), testKey); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("Verify() of an edited entry error = %v, want ErrBrokenChain", err)
+	}
+
+	removed := lines[0] + lines[2]
+	if _, err := Verify(strings.NewReader(removed), testKey); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("Verify() with a removed entry error = %v, want ErrBrokenChain", err)
+	}
+
+	// Rewriting the chain without the key does not verify.
+	forged, err := New(t.TempDir(), "egress", []byte("fedcba9876543210fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := forged.Record(TypeEgress, map[string]any{"domain": "a.example.com.", "action": "deny"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(forged.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(bytes.NewReader(data), testKey); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("Verify() of a chain written with another key error = %v, want ErrBrokenChain", err)
+	}
+
+	// A file that starts after a sealed one is still valid on its own.
+	if n, err := Verify(strings.NewReader(lines[1] + lines[2]), testKey); err != nil || n != 2 {
+		t.Fatalf("Verify() of a continued chain = %d, %v", n, err)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder
+	if err := r.Record(TypeCommand, nil); err != nil {
+		t.Fatalf("nil Recorder.Record() error = %v", err)
+	}
+
+	t.Setenv(EnvDir, "")
+	r, err := FromEnv("execd")
+	if err != nil || r != nil {
+		t.Fatalf("FromEnv() without %s = %v, %v, want nil", EnvDir, r, err)
+	}
+}
+
+func TestFromEnvRequiresKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvDir, dir)
+	t.Setenv(EnvKeyFile, "")
+	if _, err := FromEnv("execd"); err == nil {
+		t.Fatalf("FromEnv() without %s succeeded, want error", EnvKeyFile)
+	}
+
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("short\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvKeyFile, keyFile)
+	if _, err := FromEnv("execd"); err == nil {
+		t.Fatal("FromEnv() with a short key succeeded, want error")
+	}
+
+	if err := os.WriteFile(keyFile, append(testKey, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := FromEnv("execd")
+	if err != nil || r == nil {
+		t.Fatalf("FromEnv() = %v, %v", r, err)
+	}
+}
+
+func TestNewRejectsInvalidSource(t *testing.T) {
+	for _, source := range []string{"", "../execd", `a\b`} {
+		if _, err := New(t.TempDir(), source, testKey); err == nil {
+			t.Errorf("New(%q) succeeded, want error", source)
+		}
+	}
+}
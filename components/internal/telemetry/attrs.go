@@ -19,6 +19,8 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/alibaba/opensandbox/internal/logger"
 )
 
 type SharedAttrsEnvConfig struct {
@@ -63,3 +65,19 @@ func AppendAttrsFromKeyValuePairs(kvs []attribute.KeyValue, raw string) []attrib
 	}
 	return kvs
 }
+
+// LogFields converts attributes into logger fields, so log lines carry the
+// same sandbox and extra attributes as the metrics.
+func LogFields(kvs []attribute.KeyValue) []logger.Field {
+	out := make([]logger.Field, 0, len(kvs))
+	for _, kv := range kvs {
+		var v string
+		if kv.Value.Type() == attribute.STRING {
+			v = kv.Value.AsString()
+		} else {
+			v = kv.Value.Emit()
+		}
+		out = append(out, logger.Field{Key: string(kv.Key), Value: v})
+	}
+	return out
+}
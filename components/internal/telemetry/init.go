@@ -36,6 +36,9 @@ type Config struct {
 	ServiceName        string
 	ResourceAttributes []attribute.KeyValue
 	RegisterMetrics    func() error
+	// Readers are read by the component itself, e.g. to serve a scrape endpoint. Metrics are recorded
+	// when any are set, with or without an OTLP endpoint.
+	Readers []sdkmetric.Reader
 }
 
 // Init sets a noop TracerProvider, optionally MeterProvider with OTLP HTTP exporter.
@@ -57,16 +60,19 @@ func Init(ctx context.Context, cfg Config) (shutdown func(context.Context) error
 		shutdownFuncs []func(context.Context) error
 	)
 
-	if metricsEnabled() {
-		mexp, err := otlpmetrichttp.New(ctx)
-		if err != nil {
-			return nil, err
+	if metricsEnabled() || len(cfg.Readers) > 0 {
+		opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+		if metricsEnabled() {
+			mexp, err := otlpmetrichttp.New(ctx)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(mexp)))
+		}
+		for _, reader := range cfg.Readers {
+			opts = append(opts, sdkmetric.WithReader(reader))
 		}
-		reader := sdkmetric.NewPeriodicReader(mexp)
-		mp = sdkmetric.NewMeterProvider(
-			sdkmetric.WithResource(res),
-			sdkmetric.WithReader(reader),
-		)
+		mp = sdkmetric.NewMeterProvider(opts...)
 		otel.SetMeterProvider(mp)
 		shutdownFuncs = append(shutdownFuncs, mp.Shutdown)
 		if cfg.RegisterMetrics != nil {
@@ -0,0 +1,68 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationAllocStore marks a BatchSandbox whose allocation is kept in the Allocation of the same name
+	// instead of the alloc-status and alloc-released annotations. Its only value is AllocStoreResource.
+	AnnotationAllocStore = "sandbox.opensandbox.io/alloc-store"
+	// AllocStoreResource is the value of AnnotationAllocStore.
+	AllocStoreResource = "allocation"
+)
+
+// AllocationSpec is the allocation of a pooled BatchSandbox.
+type AllocationSpec struct {
+	// PoolRef is the pool the pods are allocated from.
+	// +optional
+	PoolRef string `json:"poolRef,omitempty"`
+	// Pods are the pool pods allocated to the BatchSandbox, in allocation order. Was the alloc-status annotation.
+	// +optional
+	Pods []string `json:"pods,omitempty"`
+	// Released are the allocated pods the pool has reclaimed. Was the alloc-released annotation.
+	// +optional
+	Released []string `json:"released,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=alloc
+// +kubebuilder:printcolumn:name="POOL",type="string",JSONPath=".spec.poolRef"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Allocation records the pool pods allocated to the BatchSandbox of the same name, which owns it. The pool
+// controller writes it instead of annotations when the manager runs with --allocation-store=resource, so
+// large allocations neither hit the annotation size limit nor conflict with other writes to the BatchSandbox.
+type Allocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AllocationSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AllocationList contains a list of Allocation.
+type AllocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Allocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Allocation{}, &AllocationList{})
+}
@@ -0,0 +1,32 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// Annotations v1alpha1 uses to carry allocation data as JSON strings. v1alpha2 exposes them as typed
+// status fields.
+const (
+	// AnnotationAllocStatus holds the pool pods allocated to a BatchSandbox: {"pods":[...]}.
+	AnnotationAllocStatus = "sandbox.opensandbox.io/alloc-status"
+	// AnnotationAllocRelease holds the pods a BatchSandbox hands back to its pool: {"pods":[...]}.
+	AnnotationAllocRelease = "sandbox.opensandbox.io/alloc-release"
+	// AnnotationAllocReleased holds the pods the pool has reclaimed: {"pods":[...]}.
+	AnnotationAllocReleased = "sandbox.opensandbox.io/alloc-released"
+	// AnnotationEndpoints holds the BatchSandbox pod IPs: ["10.244.1.5", ...].
+	AnnotationEndpoints = "sandbox.opensandbox.io/endpoints"
+)
+
+// Hub marks v1alpha1 as the conversion hub. It stays the storage version while the controllers still
+// write allocation data to annotations.
+func (*BatchSandbox) Hub() {}
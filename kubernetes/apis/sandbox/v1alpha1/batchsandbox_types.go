@@ -41,7 +41,7 @@ const (
 )
 
 // BatchSandboxConditionType represents the type of BatchSandbox condition.
-// +kubebuilder:validation:Enum=Ready;Progressing;Paused;PauseFailed;ResumeFailed;PodFailed
+// +kubebuilder:validation:Enum=Ready;Progressing;Paused;PauseFailed;ResumeFailed;PodFailed;PoolExhausted;PoolSaturated;Frozen;Unschedulable
 type BatchSandboxConditionType string
 
 const (
@@ -57,6 +57,20 @@ const (
 	BatchSandboxConditionResumeFailed BatchSandboxConditionType = "ResumeFailed"
 	// BatchSandboxConditionPodFailed is set when the sandbox pod enters a failed state.
 	BatchSandboxConditionPodFailed BatchSandboxConditionType = "PodFailed"
+	// BatchSandboxConditionPoolExhausted is set when a fail-fast pooled sandbox could not get all of its
+	// pods from the pool within the allocation timeout.
+	BatchSandboxConditionPoolExhausted BatchSandboxConditionType = "PoolExhausted"
+	// BatchSandboxConditionPoolSaturated is set by the pool controller while the pool cannot create the pods
+	// the sandbox waits for because it is at capacitySpec.poolMax.
+	BatchSandboxConditionPoolSaturated BatchSandboxConditionType = "PoolSaturated"
+	// BatchSandboxConditionFrozen is set while the tasks of every pod are frozen through spec.freeze.
+	BatchSandboxConditionFrozen BatchSandboxConditionType = "Frozen"
+	// BatchSandboxConditionUnschedulable is set when a pooled sandbox was not allocated all of its pods
+	// within spec.allocationDeadlineSeconds.
+	BatchSandboxConditionUnschedulable BatchSandboxConditionType = "Unschedulable"
+	// BatchSandboxConditionDebugHold is True while the replicas of failed tasks are held under
+	// spec.debugPolicy.keepFailed, and False once the hold has ended.
+	BatchSandboxConditionDebugHold BatchSandboxConditionType = "DebugHold"
 )
 
 // BatchSandboxCondition represents a condition of a BatchSandbox
@@ -91,6 +105,25 @@ type BatchSandboxSpec struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	PoolRef string `json:"poolRef,omitempty"`
+	// FallbackPoolRefs are the pools tried in order once the pool of PoolRef is saturated. The replicas it
+	// cannot supply are taken by a BatchSandbox owned by this one, named <name>-fallback, that allocates
+	// from the first fallback pool and falls back to the rest in turn. Its pods serve this sandbox and are
+	// released to their own pool.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:Optional
+	FallbackPoolRefs []string `json:"fallbackPoolRefs,omitempty"`
+	// Flavor selects a flavor of the pool of PoolRef to allocate pods of. Empty selects the pod template
+	// of the pool itself.
+	// +optional
+	// +kubebuilder:validation:Optional
+	Flavor string `json:"flavor,omitempty"`
+	// ReservationRef names a Reservation of the pool of PoolRef, in the namespace of the BatchSandbox, to
+	// take pods from before any other idle pod of the pool. Without pods left in the reservation, e.g.
+	// once it has expired, the sandbox is allocated pods like any other.
+	// +optional
+	// +kubebuilder:validation:Optional
+	ReservationRef string `json:"reservationRef,omitempty"`
 	// +optional
 	// Template describes the pods that will be created.
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -109,6 +142,13 @@ type BatchSandboxSpec struct {
 	// +kubebuilder:validation:Format="date-time"
 	// +kubebuilder:validation:Optional
 	ExpireTime *metav1.Time `json:"expireTime,omitempty"`
+	// HeartbeatTimeoutSeconds makes ExpireTime refreshable. Whenever the sandbox.opensandbox.io/heartbeat
+	// annotation is set to a newer RFC3339 timestamp, the controller moves ExpireTime to the heartbeat plus
+	// this duration. ExpireTime is never moved backwards, so sandboxes that stop sending heartbeats still expire.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	HeartbeatTimeoutSeconds *int32 `json:"heartbeatTimeoutSeconds,omitempty"`
 	// Task is a custom task spec that is automatically dispatched after the sandbox is successfully created.
 	// The Sandbox is responsible for managing the lifecycle of the task.
 	// +optional
@@ -129,6 +169,44 @@ type BatchSandboxSpec struct {
 	// +kubebuilder:default=Retain
 	// +kubebuilder:validation:Optional
 	TaskResourcePolicyWhenCompleted *TaskResourcePolicy `json:"taskResourcePolicyWhenCompleted,omitempty"`
+	// TaskPlacementPolicy decides which tasks get pods first when fewer pods are allocated than there are tasks.
+	// - IndexOrder: tasks are placed in index order.
+	// - ShortestFirst: tasks with the smallest taskTemplate.spec.estimatedDurationSeconds are placed first,
+	//   tasks without an estimate last.
+	// - Priority: tasks with the highest taskTemplate.spec.priority are placed first.
+	// Ties keep index order. Per-task values are set through ShardTaskPatches.
+	// +optional
+	// +kubebuilder:default=IndexOrder
+	// +kubebuilder:validation:Enum=IndexOrder;ShortestFirst;Priority
+	// +kubebuilder:validation:Optional
+	TaskPlacementPolicy *TaskPlacementPolicy `json:"taskPlacementPolicy,omitempty"`
+	// DebugPolicy keeps the replicas of failed tasks around for inspection before they are released.
+	// +optional
+	// +kubebuilder:validation:Optional
+	DebugPolicy *DebugPolicy `json:"debugPolicy,omitempty"`
+	// AllocationPolicy controls how a pooled sandbox waits for pods from its pool.
+	// +optional
+	// +kubebuilder:validation:Optional
+	AllocationPolicy *AllocationPolicy `json:"allocationPolicy,omitempty"`
+	// AllocationConstraints restrict the pods of the pool a pooled sandbox may be allocated, for workloads
+	// pinned to specific hardware. Without them any available pod of the pool will do.
+	// +optional
+	// +kubebuilder:validation:Optional
+	AllocationConstraints *AllocationConstraints `json:"allocationConstraints,omitempty"`
+	// AllocationDeadlineSeconds is how long, counted from creation, a pooled sandbox may wait for its pool
+	// to allocate all replicas. A sandbox still short of pods at the deadline moves to the Failed phase with
+	// the Unschedulable condition and is allocated no more pods. Unlike allocationPolicy.timeoutSeconds it
+	// applies whether or not the sandbox waits for its pool. Unset waits indefinitely.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	AllocationDeadlineSeconds *int32 `json:"allocationDeadlineSeconds,omitempty"`
+	// AllocationDeadlineAction is what happens to a sandbox that missed its allocation deadline: Fail keeps
+	// it with the Unschedulable condition, Delete deletes it as well. Defaults to Fail.
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Fail;Delete
+	AllocationDeadlineAction AllocationDeadlineAction `json:"allocationDeadlineAction,omitempty"`
 
 	// Pause is the pause/resume intent written by Server and executed by Controller.
 	// nil = no operation / server retry bridge
@@ -137,6 +215,13 @@ type BatchSandboxSpec struct {
 	// Controller never clears this field; Server may temporarily patch nil to force a new generation for retries.
 	// +optional
 	Pause *bool `json:"pause,omitempty"`
+
+	// Freeze suspends the task processes of every pod in place (SIGSTOP of the task process group)
+	// while the pods stay allocated, so an idle session stops using CPU and continues with its memory
+	// intact once Freeze is cleared. Unlike Pause it takes no snapshot and works with any replica count.
+	// Freeze is ignored while the sandbox is paused through Pause.
+	// +optional
+	Freeze *bool `json:"freeze,omitempty"`
 }
 
 type TaskResourcePolicy string
@@ -146,6 +231,160 @@ const (
 	TaskResourcePolicyRelease TaskResourcePolicy = "Release"
 )
 
+// DebugPolicy holds failed replicas so their pods can be inspected, e.g. with kubectl exec.
+type DebugPolicy struct {
+	// KeepFailed holds the pods of failed tasks, and their pool allocation, from the first task failure
+	// for HoldSeconds: they are not released under taskResourcePolicyWhenCompleted=Release, and the sandbox
+	// is not deleted at its expireTime until the hold ends. The DebugHold condition is True meanwhile.
+	// Deleting the sandbox ends the hold.
+	// +optional
+	KeepFailed bool `json:"keepFailed,omitempty"`
+	// HoldSeconds is how long failed replicas are held. Defaults to 3600.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	HoldSeconds *int32 `json:"holdSeconds,omitempty"`
+}
+
+type TaskPlacementPolicy string
+
+const (
+	TaskPlacementPolicyIndexOrder    TaskPlacementPolicy = "IndexOrder"
+	TaskPlacementPolicyShortestFirst TaskPlacementPolicy = "ShortestFirst"
+	TaskPlacementPolicyPriority      TaskPlacementPolicy = "Priority"
+)
+
+// AllocationPolicy controls how a pooled BatchSandbox waits for pods from its pool.
+type AllocationPolicy struct {
+	// WaitForPool keeps the sandbox pending until the pool can supply all replicas. When false, the sandbox
+	// fails with the PoolExhausted condition if not all replicas are allocated within TimeoutSeconds.
+	// Pods that were already allocated stay with the sandbox until it is deleted.
+	// +optional
+	// +kubebuilder:default=true
+	// +kubebuilder:validation:Optional
+	WaitForPool *bool `json:"waitForPool,omitempty"`
+	// TimeoutSeconds is how long, counted from creation, a sandbox with WaitForPool=false waits for its
+	// pods before it fails. Defaults to 60.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// Gang allocates pods all or nothing: the sandbox receives no pods until the pool can supply all
+	// replicas it is missing at once, and the pool is asked for the missing pods meanwhile. Tightly
+	// coupled workers that are useless on their own then don't hold pool pods while they wait.
+	// +optional
+	// +kubebuilder:validation:Optional
+	Gang *bool `json:"gang,omitempty"`
+	// Revision selects which pool pods the sandbox may be allocated: Any pod, or only pods of the Latest
+	// revision of the pool template, so that sandboxes created during a rollout start on the new template.
+	// Defaults to Any.
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Latest;Any
+	Revision AllocationRevision `json:"revision,omitempty"`
+	// Priority orders the sandboxes waiting for pods of the same pool: higher values are served first, and
+	// sandboxes of equal priority in creation order. Waiting raises the priority by one every minute, so
+	// sandboxes of lower priority are not starved. Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Optional
+	Priority *int32 `json:"priority,omitempty"`
+	// Strategy places the pods of the sandbox on the nodes of the pool: Pack onto the fewest nodes, or spread
+	// across nodes (SpreadNodes) or zones and then nodes (SpreadZones) so that losing a node or zone takes
+	// as few replicas as possible. Overrides allocationStrategy of the pool. When neither is set, pods are
+	// allocated in pool order.
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Pack;SpreadNodes;SpreadZones
+	Strategy AllocationStrategy `json:"strategy,omitempty"`
+	// PreemptionPolicy lets the sandbox reclaim pods from sandboxes of lower priority when the pool is at
+	// poolMax and has no pod left for it: PreemptLowerPriority, or Never. Priorities are compared as set,
+	// without the raise for waiting. Defaults to Never.
+	// +optional
+	// +kubebuilder:default=Never
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=PreemptLowerPriority;Never
+	PreemptionPolicy AllocationPreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	// PreemptionGracePeriodSeconds is how long pods of this sandbox are kept after they are chosen to be
+	// preempted, so that their work can be checkpointed. Defaults to 30.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	PreemptionGracePeriodSeconds *int32 `json:"preemptionGracePeriodSeconds,omitempty"`
+	// OverflowPolicy decides what a sandbox does when the pool is at poolMax and cannot supply its
+	// replicas: Wait for pool pods, or create the missing pods OnDemand from the pool template. On-demand
+	// pods belong to the sandbox rather than the pool and are deleted when released. Defaults to Wait.
+	// +optional
+	// +kubebuilder:default=Wait
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Wait;OnDemand
+	OverflowPolicy AllocationOverflowPolicy `json:"overflowPolicy,omitempty"`
+}
+
+// AllocationDeadlineAction is what happens to a sandbox that missed its allocation deadline.
+type AllocationDeadlineAction string
+
+const (
+	// AllocationDeadlineFail fails the sandbox and keeps it, along with the pods it was allocated.
+	AllocationDeadlineFail AllocationDeadlineAction = "Fail"
+	// AllocationDeadlineDelete fails the sandbox and deletes it.
+	AllocationDeadlineDelete AllocationDeadlineAction = "Delete"
+)
+
+// AllocationConstraints restrict the pool pods allocated to a sandbox. A pod must meet all of them.
+type AllocationConstraints struct {
+	// PodSelector selects the pool pods by their labels.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// NodeSelector requires the node of a pod to have all of these labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Zones are the topology.kubernetes.io/zone label values the node of a pod may be in.
+	// +optional
+	// +listType=set
+	Zones []string `json:"zones,omitempty"`
+}
+
+// AllocationPreemptionPolicy decides whether a sandbox may take pods from sandboxes of lower priority.
+type AllocationPreemptionPolicy string
+
+const (
+	AllocationPreemptLowerPriority AllocationPreemptionPolicy = "PreemptLowerPriority"
+	AllocationPreemptNever         AllocationPreemptionPolicy = "Never"
+)
+
+// AllocationOverflowPolicy decides how a sandbox gets the pods a saturated pool cannot supply.
+type AllocationOverflowPolicy string
+
+const (
+	// AllocationOverflowWait waits for the pool to have pods again.
+	AllocationOverflowWait AllocationOverflowPolicy = "Wait"
+	// AllocationOverflowOnDemand creates the missing pods from the pool template.
+	AllocationOverflowOnDemand AllocationOverflowPolicy = "OnDemand"
+)
+
+// AllocationRevision selects the pool revisions a sandbox may be allocated pods of.
+type AllocationRevision string
+
+const (
+	// AllocationRevisionAny allocates pods of any revision of the pool.
+	AllocationRevisionAny AllocationRevision = "Any"
+	// AllocationRevisionLatest allocates only pods of the latest revision of the pool.
+	AllocationRevisionLatest AllocationRevision = "Latest"
+)
+
+// AllocationStrategy is how the pods allocated to a sandbox are placed on the nodes of the pool.
+type AllocationStrategy string
+
+const (
+	// AllocationStrategyPack allocates pods on the fewest nodes, preferring the nodes the sandbox already
+	// has pods on and then the nodes with the most available pods.
+	AllocationStrategyPack AllocationStrategy = "Pack"
+	// AllocationStrategySpreadNodes allocates pods on the nodes the sandbox has the fewest pods on.
+	AllocationStrategySpreadNodes AllocationStrategy = "SpreadNodes"
+	// AllocationStrategySpreadZones allocates pods in the zones the sandbox has the fewest pods in, and
+	// within a zone on the nodes it has the fewest pods on.
+	AllocationStrategySpreadZones AllocationStrategy = "SpreadZones"
+)
+
 // BatchSandboxStatus defines the observed state of BatchSandbox.
 type BatchSandboxStatus struct {
 	// ObservedGeneration is the most recent generation observed for this BatchSandbox. It corresponds to the
@@ -173,6 +412,10 @@ type BatchSandboxStatus struct {
 	// +optional
 	Phase BatchSandboxPhase `json:"phase,omitempty"`
 
+	// Frozen is the number of pods whose tasks are frozen through spec.freeze.
+	// +optional
+	Frozen int32 `json:"frozen,omitempty"`
+
 	// PauseObservedGeneration is the generation most recently ACKed by the Controller
 	// when entering pause/resume dispatch logic. Written immediately to prevent reentry (idempotent gating).
 	// +optional
@@ -183,6 +426,65 @@ type BatchSandboxStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []BatchSandboxCondition `json:"conditions,omitempty"`
+
+	// Usage records which pods served the BatchSandbox and for how long, for chargeback of shared pools.
+	// +optional
+	Usage *BatchSandboxUsage `json:"usage,omitempty"`
+
+	// PodRevisions records the pool and pool revision of each pod allocated from a pool.
+	// +optional
+	// +listType=map
+	// +listMapKey=pod
+	PodRevisions []PodRevision `json:"podRevisions,omitempty"`
+	// PodEndpoints records the named endpoints of each pod that declares ports in the
+	// sandbox.opensandbox.io/endpoint-ports annotation and has an IP.
+	// +optional
+	// +listType=map
+	// +listMapKey=pod
+	PodEndpoints []PodEndpoints `json:"podEndpoints,omitempty"`
+}
+
+// PodRevision is the pool revision of a pod.
+type PodRevision struct {
+	// Pod is the name of the pod.
+	Pod string `json:"pod"`
+	// Revision is the revision of the pool template the pod was created from.
+	Revision string `json:"revision"`
+	// Pool is the pool the pod was allocated from, which differs from PoolRef for pods of a fallback pool.
+	// +optional
+	Pool string `json:"pool,omitempty"`
+}
+
+// PodEndpoints are the named endpoints of a pod.
+type PodEndpoints struct {
+	// Pod is the name of the pod.
+	Pod string `json:"pod"`
+	// Endpoints maps the names of the ports the pod declares to host:port.
+	Endpoints map[string]string `json:"endpoints"`
+}
+
+// BatchSandboxUsage records the resources a BatchSandbox held. The manager API turns it into pod-seconds
+// and resource-seconds.
+type BatchSandboxUsage struct {
+	// Pods records every period a pod served the sandbox.
+	// +optional
+	// +listType=atomic
+	Pods []PodUsage `json:"pods,omitempty"`
+}
+
+// PodUsage is a period in which a pod served a BatchSandbox.
+type PodUsage struct {
+	// Name is the name of the pod.
+	Name string `json:"name"`
+	// Requests are the resource requests of the pod: the sum of its containers plus the pod overhead.
+	// +optional
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	// Since is when the pod started serving the sandbox: its creation for pods of the sandbox, or when it
+	// was first seen allocated for pool pods.
+	Since metav1.Time `json:"since"`
+	// Until is when the pod stopped serving the sandbox, unset while it still does.
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
 }
 
 // +genclient
@@ -190,6 +492,7 @@ type BatchSandboxStatus struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=bsbx
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="DESIRED",type="integer",JSONPath=".spec.replicas",description="The desired number of pods."
 // +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.replicas",description="The number of currently all pods."
 // +kubebuilder:printcolumn:name="ALLOCATED",type="integer",JSONPath=".status.allocated",description="The number of currently all allocated pods."
@@ -235,6 +538,12 @@ type TaskSpec struct {
 	// If exceeded, the task executor should terminate the task.
 	// +optional
 	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+	// EstimatedDurationSeconds is the expected run time of the task, used by the ShortestFirst placement policy.
+	// +optional
+	EstimatedDurationSeconds *int64 `json:"estimatedDurationSeconds,omitempty"`
+	// Priority is used by the Priority placement policy. Higher values are placed first. Defaults to 0.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
 }
 
 type ProcessTask struct {
@@ -252,6 +561,38 @@ type ProcessTask struct {
 	// WorkingDir task working directory.
 	// +optional
 	WorkingDir string `json:"workingDir,omitempty"`
+	// Image is an OCI image whose root filesystem the task runs in. The task-executor pulls the image
+	// and runs the command chrooted into it inside the main container, so tools can be pinned to an
+	// image without changing the pod. Only anonymous pulls are supported.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// CaptureOutputBytes inlines up to this many bytes of the end of stdout and stderr in the terminated
+	// status of the task, so the output of short commands needs no separate logs call.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=32768
+	CaptureOutputBytes *int32 `json:"captureOutputBytes,omitempty"`
+	// Umask is the file mode creation mask of the task in octal, e.g. "0027". Unset keeps the mask of the
+	// task-executor.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^0?[0-7]{3}$`
+	Umask string `json:"umask,omitempty"`
+	// MaxOpenFiles sets RLIMIT_NOFILE of the task, both soft and hard, for workloads that outgrow the
+	// default limit on open files. Unset keeps the limit of the task-executor.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxOpenFiles *int64 `json:"maxOpenFiles,omitempty"`
+	// Nice is the niceness of the task, from -20, the highest priority, to 19. Unset keeps the niceness of
+	// the task-executor.
+	// +optional
+	// +kubebuilder:validation:Minimum=-20
+	// +kubebuilder:validation:Maximum=19
+	Nice *int32 `json:"nice,omitempty"`
+	// IOClass is the I/O scheduling class of the task: RealTime, BestEffort or Idle. Idle keeps a task
+	// heavy on disk from starving the sidecars of the pod. Unset keeps the class of the task-executor.
+	// +optional
+	// +kubebuilder:validation:Enum=RealTime;BestEffort;Idle
+	IOClass string `json:"ioClass,omitempty"`
 }
 
 // TaskStatus task status
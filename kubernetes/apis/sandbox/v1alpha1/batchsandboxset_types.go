@@ -0,0 +1,171 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BatchSandboxSetPhase is the aggregate phase of the BatchSandboxes of a set.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type BatchSandboxSetPhase string
+
+const (
+	// BatchSandboxSetPhasePending means no BatchSandbox of the set has been created yet.
+	BatchSandboxSetPhasePending BatchSandboxSetPhase = "Pending"
+	// BatchSandboxSetPhaseRunning means some BatchSandboxes have not finished.
+	BatchSandboxSetPhaseRunning BatchSandboxSetPhase = "Running"
+	// BatchSandboxSetPhaseSucceeded means every BatchSandbox finished and none failed.
+	BatchSandboxSetPhaseSucceeded BatchSandboxSetPhase = "Succeeded"
+	// BatchSandboxSetPhaseFailed means every BatchSandbox finished and at least one failed.
+	// BatchSandboxes without a task template only finish by failing, so a set of them stays Running.
+	BatchSandboxSetPhaseFailed BatchSandboxSetPhase = "Failed"
+)
+
+// BatchSandboxSetParameterPhase is the phase of the BatchSandbox of a parameter.
+// +kubebuilder:validation:Enum=Created;Ready;Succeeded;Failed
+type BatchSandboxSetParameterPhase string
+
+const (
+	// BatchSandboxSetParameterCreated means the BatchSandbox exists but not all of its replicas are ready.
+	BatchSandboxSetParameterCreated BatchSandboxSetParameterPhase = "Created"
+	// BatchSandboxSetParameterReady means all replicas of the BatchSandbox are ready.
+	BatchSandboxSetParameterReady BatchSandboxSetParameterPhase = "Ready"
+	// BatchSandboxSetParameterSucceeded means the task of every replica succeeded.
+	BatchSandboxSetParameterSucceeded BatchSandboxSetParameterPhase = "Succeeded"
+	// BatchSandboxSetParameterFailed means the BatchSandbox failed, a task failed, or the BatchSandbox was
+	// deleted before it finished.
+	BatchSandboxSetParameterFailed BatchSandboxSetParameterPhase = "Failed"
+)
+
+// BatchSandboxTemplateSpec describes the BatchSandboxes created for every parameter of a set.
+type BatchSandboxTemplateSpec struct {
+	// Labels are added to every BatchSandbox, along with the labels identifying the set.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to every BatchSandbox.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Spec is the BatchSandbox spec. "$(key)" references in any string of the template are replaced with
+	// the value of key in the parameter; references to unknown keys are left as they are.
+	Spec BatchSandboxSpec `json:"spec"`
+}
+
+// BatchSandboxSetParameter is one expansion of the template.
+type BatchSandboxSetParameter struct {
+	// Name identifies the BatchSandbox of the parameter, named <set>-<name>. It is available to the
+	// template as $(name) unless values defines name.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Values are substituted for the $(key) references of the template.
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// BatchSandboxSetSpec defines the desired state of BatchSandboxSet.
+type BatchSandboxSetSpec struct {
+	// Template is expanded once per parameter.
+	// +kubebuilder:validation:Required
+	Template BatchSandboxTemplateSpec `json:"template"`
+	// Parameters lists the BatchSandboxes of the set. Removing a parameter deletes its BatchSandbox; template
+	// changes only apply to BatchSandboxes created afterwards.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MinItems=1
+	Parameters []BatchSandboxSetParameter `json:"parameters"`
+	// Parallelism is the maximum number of unfinished BatchSandboxes at a time. The remaining ones are created
+	// as earlier ones finish, in parameter order. Unset creates all of them at once.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+	// TTLSecondsAfterFinished deletes the set, and with it every BatchSandbox, that long after all of them
+	// finished. Unset keeps the set until it is deleted.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// BatchSandboxSetParameterStatus is the observed state of the BatchSandbox of a parameter.
+type BatchSandboxSetParameterStatus struct {
+	// Name is the parameter name.
+	Name string `json:"name"`
+	// Phase is the phase of the BatchSandbox. Succeeded and Failed are final.
+	Phase BatchSandboxSetParameterPhase `json:"phase"`
+}
+
+// BatchSandboxSetStatus defines the observed state of BatchSandboxSet.
+type BatchSandboxSetStatus struct {
+	// ObservedGeneration is the most recent generation observed for this BatchSandboxSet.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is the aggregate phase of the BatchSandboxes.
+	// +optional
+	Phase BatchSandboxSetPhase `json:"phase,omitempty"`
+	// Total is the number of parameters.
+	Total int32 `json:"total"`
+	// Created is the number of parameters whose BatchSandbox has been created.
+	Created int32 `json:"created"`
+	// Ready is the number of BatchSandboxes whose replicas are all ready.
+	Ready int32 `json:"ready"`
+	// Succeeded is the number of BatchSandboxes whose tasks all succeeded.
+	Succeeded int32 `json:"succeeded"`
+	// Failed is the number of BatchSandboxes that failed, have a failed task, or were deleted before they
+	// finished.
+	Failed int32 `json:"failed"`
+	// CompletionTime is when all BatchSandboxes had finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Parameters records the parameters whose BatchSandbox has been created. The BatchSandbox of a recorded
+	// parameter is never created again, so that one deleted by other means does not come back.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Parameters []BatchSandboxSetParameterStatus `json:"parameters,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=bsbxset
+// +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.total"
+// +kubebuilder:printcolumn:name="READY",type="integer",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="SUCCEEDED",type="integer",JSONPath=".status.succeeded"
+// +kubebuilder:printcolumn:name="FAILED",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// BatchSandboxSet expands a BatchSandbox template over a list of parameters and tracks the resulting
+// BatchSandboxes as a unit.
+type BatchSandboxSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BatchSandboxSetSpec   `json:"spec,omitempty"`
+	Status BatchSandboxSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BatchSandboxSetList contains a list of BatchSandboxSet.
+type BatchSandboxSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BatchSandboxSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BatchSandboxSet{}, &BatchSandboxSetList{})
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EgressRule allows or denies egress to a single target.
+type EgressRule struct {
+	// Action is applied to traffic to the target.
+	// +kubebuilder:validation:Enum=allow;deny
+	// +kubebuilder:validation:Required
+	Action string `json:"action"`
+	// Target is a domain (e.g. api.github.com), a wildcard domain (e.g. *.pypi.org), an IP or a CIDR.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Target string `json:"target"`
+}
+
+// ClusterEgressPolicySpec is an egress policy in the format accepted by the egress sidecar.
+type ClusterEgressPolicySpec struct {
+	// DefaultAction is applied to traffic that matches no rule.
+	// +kubebuilder:validation:Enum=allow;deny
+	// +kubebuilder:default=deny
+	// +optional
+	DefaultAction string `json:"defaultAction,omitempty"`
+	// Egress rules, evaluated by the egress sidecar.
+	// +optional
+	Egress []EgressRule `json:"egress,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cegress
+// +kubebuilder:printcolumn:name="DEFAULT",type="string",JSONPath=".spec.defaultAction"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterEgressPolicy is the default egress policy of a namespace tier. Namespaces select a tier by name with
+// the sandbox.opensandbox.io/egress-tier label; unlabeled namespaces use the policy named "default". The
+// controller pushes the policy to the egress sidecar of every allocated sandbox pod that doesn't declare
+// a policy of its own.
+type ClusterEgressPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterEgressPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterEgressPolicyList contains a list of ClusterEgressPolicy.
+type ClusterEgressPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterEgressPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterEgressPolicy{}, &ClusterEgressPolicyList{})
+}
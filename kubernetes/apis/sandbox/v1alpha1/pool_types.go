@@ -17,6 +17,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -43,15 +44,40 @@ type RecycleStrategy struct {
 	// +kubebuilder:default=Delete
 	// +optional
 	Type RecycleType `json:"type,omitempty"`
+	// Sanitize runs a cleanup command in a released pod before the Noop or
+	// Restart recycle, so that the next sandbox does not inherit the files,
+	// processes and environment of the previous one. It is not run for the
+	// Delete type.
+	// +optional
+	Sanitize *SanitizeAction `json:"sanitize,omitempty"`
+}
+
+// SanitizeAction is a command run in a pod returned to the pool.
+type SanitizeAction struct {
+	// Command is run by the task-executor of the pod, which the pod template
+	// must include. A pod whose command exits with a non-zero code or times
+	// out is deleted instead of being returned to the pool.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// TimeoutSeconds caps the run time of the command. Defaults to 60.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
 }
 
 // PoolSpec defines the desired state of Pool.
 type PoolSpec struct {
 	// Pod Template used to create pre-warmed nodes in the pool.
+	// Exactly one of Template and TemplateFrom must be set.
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:validation:Optional
 	Template *corev1.PodTemplateSpec `json:"template"`
+	// TemplateFrom sources the pod template from outside the Pool. The pool
+	// revision is computed from the resolved template, so editing the source
+	// rolls the pool like editing Template does.
+	// +optional
+	TemplateFrom *PoolTemplateSource `json:"templateFrom,omitempty"`
 	// CapacitySpec controls the size of the resource pool.
 	// +kubebuilder:validation:Required
 	CapacitySpec CapacitySpec `json:"capacitySpec"`
@@ -61,11 +87,325 @@ type PoolSpec struct {
 	// UpdateStrategy controls how pool pods are updated when the template changes.
 	// +optional
 	UpdateStrategy *UpdateStrategy `json:"updateStrategy,omitempty"`
+	// RevisionHistoryLimit is the number of past template revisions kept as
+	// ControllerRevisions, so the pool can be rolled back to one of them.
+	// Revisions still used by pods are kept beyond the limit. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 	// RecycleStrategy controls how pods are handled when returned to the pool.
 	// Default is Delete, which deletes the pod.
 	// Restart strategy restarts the pod containers instead of deleting.
 	// +optional
 	RecycleStrategy *RecycleStrategy `json:"recycleStrategy,omitempty"`
+	// AllocationQuota limits how many pool pods each tenant may hold at the same time.
+	// Requests beyond the quota stay pending until the tenant releases pods.
+	// +optional
+	AllocationQuota *AllocationQuota `json:"allocationQuota,omitempty"`
+	// AllocationStrategy places the pods allocated to each BatchSandbox on
+	// the nodes of the pool: Pack onto the fewest nodes, or SpreadNodes and
+	// SpreadZones across nodes or zones. A BatchSandbox overrides it with
+	// allocationPolicy.strategy. Unset allocates pods in pool order.
+	// +optional
+	// +kubebuilder:validation:Enum=Pack;SpreadNodes;SpreadZones
+	AllocationStrategy AllocationStrategy `json:"allocationStrategy,omitempty"`
+	// SessionRecording uploads the session record of every allocation when its
+	// pods are released, before they are recycled.
+	// +optional
+	SessionRecording *SessionRecording `json:"sessionRecording,omitempty"`
+	// PodNamingStrategy controls how the pool names the pods it creates.
+	// GenerateName (the default) appends a random suffix to the pool name.
+	// Ordinal names pods <pool>-0..N, reusing the lowest ordinal freed by a
+	// deleted pod, and labels each pod with its ordinal. Changing the strategy
+	// only affects pods created afterwards.
+	// +optional
+	// +kubebuilder:default=GenerateName
+	// +kubebuilder:validation:Enum=GenerateName;Ordinal
+	PodNamingStrategy PodNamingStrategy `json:"podNamingStrategy,omitempty"`
+	// DeletionPolicy controls what happens to BatchSandboxes that hold pods of
+	// the pool when the pool is deleted.
+	// Block (the default) keeps the pool until every allocation is released.
+	// Cascade deletes the BatchSandboxes holding pods and removes the pool once
+	// their pods are released. Orphan deletes the pool right away and leaves
+	// the allocations behind.
+	// +optional
+	// +kubebuilder:default=Block
+	// +kubebuilder:validation:Enum=Block;Cascade;Orphan
+	DeletionPolicy PoolDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Readiness tightens when an idle pod may be allocated. A pod is always
+	// required to be Running with the Ready condition True, which already
+	// covers the readiness probes of every container.
+	// +optional
+	Readiness *PoolReadiness `json:"readiness,omitempty"`
+	// MaxPodAge recreates idle pods once they are older than this duration,
+	// so the buffer does not keep stale caches and leaked temporary files.
+	// Allocated pods are left alone. Pods are replaced within the
+	// maxUnavailable budget of the update strategy.
+	// +optional
+	MaxPodAge *metav1.Duration `json:"maxPodAge,omitempty"`
+	// MaxAllocations retires a pod once it has been allocated this many
+	// times: on its last release it is deleted instead of being recycled back
+	// into the buffer, so long-reused pods do not accumulate drift. Unset
+	// reuses pods without limit.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAllocations *int32 `json:"maxAllocations,omitempty"`
+	// UnhealthyPodTimeout is how long a running idle pod may stay NotReady
+	// before it is replaced. Idle pods that failed, were evicted or are in
+	// CrashLoopBackOff are replaced right away. Zero only disables the
+	// replacement of NotReady pods.
+	// +optional
+	// +kubebuilder:default="5m"
+	UnhealthyPodTimeout *metav1.Duration `json:"unhealthyPodTimeout,omitempty"`
+	// Hibernation lowers the resource requests of idle pods in place, so the
+	// buffer holds less of the cluster while it waits, and restores them when
+	// the pods are allocated. Unset keeps idle pods at their full requests.
+	// +optional
+	Hibernation *PoolHibernation `json:"hibernation,omitempty"`
+	// TopologySpreadConstraints spread the pods of the pool across zones or
+	// nodes. They are added to the pod template, so changing them rolls the
+	// pool like a template change, and a constraint without a label selector
+	// selects the pods of the pool. Scale-in deletes idle pods from the most
+	// populated domains first to keep the buffer spread out.
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	// +listMapKey=whenUnsatisfiable
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// Priority ranks the pool against other pools sharing the same nodes.
+	// +optional
+	Priority *PoolPriority `json:"priority,omitempty"`
+	// Paused freezes the pods of the pool: no pod is created, scaled in,
+	// updated to a new template, recreated for its age or health, hibernated
+	// or reclaimed for a higher priority pool. Allocations, releases,
+	// recycling, waking hibernated pods and status updates go on, so
+	// sandboxes holding pods are unaffected.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Flavors are named variants of the pod template, each with a buffer of
+	// its own, so that one pool can serve sandboxes of several sizes. A
+	// BatchSandbox picks a flavor through spec.flavor; BatchSandboxes without
+	// one get pods of the template itself, whose buffer is set by
+	// capacitySpec. poolMax caps the pods of all flavors together. Changing
+	// the flavors rolls the pool like a template change.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Flavors []PoolFlavor `json:"flavors,omitempty"`
+	// VolumeClaimTemplates are PersistentVolumeClaims that every pod of the
+	// pool gets a claim of its own from, named <template>-<pod>. Each claim
+	// is mounted through a pod volume named after its template, replacing a
+	// template volume of the same name, so containers reference it in their
+	// volumeMounts. Changes only affect pods created afterwards.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	// VolumeClaimRetentionPolicy controls what happens to the claims of a pod
+	// when the pod is deleted. Delete (the default) deletes them with the
+	// pod. Retain keeps them until the pool is deleted; with Ordinal pod
+	// names, the pod that takes over a name mounts the retained claims.
+	// +optional
+	// +kubebuilder:default=Delete
+	// +kubebuilder:validation:Enum=Delete;Retain
+	VolumeClaimRetentionPolicy PoolVolumeClaimRetentionPolicy `json:"volumeClaimRetentionPolicy,omitempty"`
+	// DisruptionBudget protects the pods allocated to BatchSandboxes from
+	// voluntary disruptions such as node drains: the pool maintains a
+	// PodDisruptionBudget of the same name selecting its allocated pods.
+	// Idle pods are not selected and stay freely evictable. Unset, the pool
+	// has no budget and deletes the one it created.
+	// +optional
+	DisruptionBudget *PoolDisruptionBudget `json:"disruptionBudget,omitempty"`
+	// Adoption lets the pool take over running pods that it did not create,
+	// so an existing warm fleet is brought under the pool without being
+	// recreated.
+	// +optional
+	Adoption *PoolAdoption `json:"adoption,omitempty"`
+}
+
+// PoolHibernation resizes idle pods down to smaller requests with in-place pod resize.
+type PoolHibernation struct {
+	// IdleSeconds is how long a pod stays idle before it hibernates. Defaults to 300.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	IdleSeconds *int32 `json:"idleSeconds,omitempty"`
+	// Requests are the requests of every container of a hibernated pod, usually cpu and memory.
+	// Only requests the container sets and that are larger are lowered.
+	Requests corev1.ResourceList `json:"requests"`
+}
+
+// PoolDisruptionBudget configures the PodDisruptionBudget of the allocated pods of a pool.
+type PoolDisruptionBudget struct {
+	// MaxUnavailable is how many allocated pods may be evicted at the same
+	// time. Can be an absolute number (ex: 1) or a percentage of the
+	// allocated pods (ex: "10%"). Defaults to 0, which blocks evictions of
+	// allocated pods until their sandboxes release them.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// PoolAdoption selects the pods a pool adopts.
+type PoolAdoption struct {
+	// Selector selects the pods in the namespace of the pool to adopt. Only
+	// pods without a controller that are not terminating or terminated are
+	// adopted, as long as the pool stays within capacitySpec.poolMax. An
+	// adopted pod gets the pool as its controller and the labels of the
+	// current revision, so it counts as up to date until the template
+	// changes. Pods matching the template are the caller's responsibility.
+	// +kubebuilder:validation:Required
+	Selector *metav1.LabelSelector `json:"selector"`
+}
+
+// PoolFlavor is a named variant of the pod template of a pool.
+type PoolFlavor struct {
+	// Name is the name BatchSandboxes request the flavor by.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Patch is a strategic merge patch applied to the pod template of the
+	// pool for the pods of the flavor, e.g. to change container resources.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Patch runtime.RawExtension `json:"patch,omitempty"`
+	// BufferMax is the maximum number of idle pods of the flavor.
+	// +kubebuilder:validation:Minimum=0
+	BufferMax int32 `json:"bufferMax"`
+	// BufferMin is the minimum number of idle pods of the flavor.
+	// +kubebuilder:validation:Minimum=0
+	BufferMin int32 `json:"bufferMin"`
+}
+
+// PoolPriority gives the pods of a pool precedence over those of lower
+// priority pools when nodes run out of room.
+type PoolPriority struct {
+	// ClassName is the PriorityClass set on the pods of the pool, replacing
+	// the priorityClassName of the template, so the scheduler places pending
+	// pods of higher classes first. Use a class with preemptionPolicy Never
+	// to leave preemption to the pool controller, which only reclaims idle
+	// pods.
+	// +optional
+	ClassName string `json:"className,omitempty"`
+	// Value orders pools: while pods of this pool cannot be scheduled, the
+	// controller deletes idle pods of pools with a lower value, lowest value
+	// first, to make room. Allocated pods are never reclaimed. Pools without
+	// a priority have value 0.
+	// +optional
+	Value int32 `json:"value,omitempty"`
+}
+
+// PoolReadiness lists the extra criteria a pod must meet before it is handed to a BatchSandbox.
+type PoolReadiness struct {
+	// Conditions are pod condition types that must also be True, such as a
+	// readiness gate set by an agent inside the sandbox. Pods missing one of
+	// them are neither allocated nor counted as available.
+	// +optional
+	// +listType=set
+	Conditions []corev1.PodConditionType `json:"conditions,omitempty"`
+}
+
+// PoolDeletionPolicy is how a pool is deleted while BatchSandboxes hold its pods.
+type PoolDeletionPolicy string
+
+const (
+	PoolDeletionPolicyBlock   PoolDeletionPolicy = "Block"
+	PoolDeletionPolicyCascade PoolDeletionPolicy = "Cascade"
+	PoolDeletionPolicyOrphan  PoolDeletionPolicy = "Orphan"
+)
+
+// PoolVolumeClaimRetentionPolicy is what happens to the claims of a deleted pool pod.
+type PoolVolumeClaimRetentionPolicy string
+
+const (
+	PoolVolumeClaimRetentionPolicyDelete PoolVolumeClaimRetentionPolicy = "Delete"
+	PoolVolumeClaimRetentionPolicyRetain PoolVolumeClaimRetentionPolicy = "Retain"
+)
+
+// PodNamingStrategy is how a pool names the pods it creates.
+type PodNamingStrategy string
+
+const (
+	PodNamingStrategyGenerateName PodNamingStrategy = "GenerateName"
+	PodNamingStrategyOrdinal      PodNamingStrategy = "Ordinal"
+)
+
+// SessionRecording configures how the session records of released pods are handled.
+// The pod template has to run the task-executor with a recording directory and
+// upload URL, and share the directory with execd and egress.
+type SessionRecording struct {
+	// Enabled seals and uploads the record of each pod before it is recycled.
+	// A pod whose record cannot be uploaded is not recycled until the upload
+	// succeeds or UploadTimeoutSeconds pass.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// UploadTimeoutSeconds is how long the upload of a released pod's record
+	// is retried before UploadFailurePolicy applies. Defaults to 600.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	UploadTimeoutSeconds *int64 `json:"uploadTimeoutSeconds,omitempty"`
+	// UploadFailurePolicy is what happens to a pod whose record could not be
+	// uploaded in time. Delete (the default) deletes the pod together with its
+	// record. Keep recycles the pod anyway; the archive stays in the pod and is
+	// uploaded with the record of the next allocation.
+	// +kubebuilder:default=Delete
+	// +kubebuilder:validation:Enum=Delete;Keep
+	// +optional
+	UploadFailurePolicy RecordingUploadFailurePolicy `json:"uploadFailurePolicy,omitempty"`
+}
+
+// RecordingUploadFailurePolicy is what happens to a pod whose session record could not be uploaded.
+type RecordingUploadFailurePolicy string
+
+const (
+	RecordingUploadFailurePolicyDelete RecordingUploadFailurePolicy = "Delete"
+	RecordingUploadFailurePolicyKeep   RecordingUploadFailurePolicy = "Keep"
+)
+
+// PoolTemplateSource references a pod template stored outside the Pool.
+type PoolTemplateSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap in the Pool namespace whose
+	// value is a PodTemplateSpec in YAML or JSON.
+	// +kubebuilder:validation:Required
+	ConfigMapKeyRef ConfigMapKeyReference `json:"configMapKeyRef"`
+}
+
+// ConfigMapKeyReference selects a key of a ConfigMap in the same namespace.
+type ConfigMapKeyReference struct {
+	// Name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key of the pod template in the ConfigMap data.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// AllocationQuota limits simultaneous pod allocations per tenant of a shared pool.
+type AllocationQuota struct {
+	// TenantLabelKey is the BatchSandbox label whose value identifies the tenant.
+	// BatchSandboxes without the label are accounted to the empty tenant "".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TenantLabelKey string `json:"tenantLabelKey"`
+	// DefaultMaxAllocated is the quota of tenants not listed in Tenants.
+	// Unset means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DefaultMaxAllocated *int32 `json:"defaultMaxAllocated,omitempty"`
+	// Tenants overrides the quota of specific tenants.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Tenants []TenantQuota `json:"tenants,omitempty"`
+}
+
+// TenantQuota is the allocation quota of a single tenant.
+type TenantQuota struct {
+	// Name is the tenant label value.
+	Name string `json:"name"`
+	// MaxAllocated is the maximum number of pods the tenant may hold at the same time.
+	// +kubebuilder:validation:Minimum=0
+	MaxAllocated int32 `json:"maxAllocated"`
 }
 
 type CapacitySpec struct {
@@ -85,6 +425,104 @@ type CapacitySpec struct {
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Required
 	PoolMin int32 `json:"poolMin"`
+	// Replicas is the desired number of schedulable pods, usually written
+	// through the scale subresource by a HorizontalPodAutoscaler or another
+	// external autoscaler. When set it replaces the buffer sizing: the pool
+	// keeps Replicas pods, but never fewer than allocated and requested ones,
+	// within PoolMin and PoolMax.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Schedule overrides the capacity during recurring time windows, for
+	// example to grow the warm pool before business hours.
+	// +optional
+	Schedule *CapacitySchedule `json:"schedule,omitempty"`
+	// Autoscaling sizes the buffer between BufferMin and BufferMax from the
+	// observed allocation rate instead of converging to their midpoint.
+	// +optional
+	Autoscaling *BufferAutoscaling `json:"autoscaling,omitempty"`
+	// Zones keeps a minimum of available idle pods in each listed zone, on
+	// top of the buffer of the whole pool. Missing pods are created pinned
+	// to their zone, and scale-in keeps the minimum of every zone.
+	// +listType=map
+	// +listMapKey=zone
+	// +optional
+	Zones []ZoneCapacity `json:"zones,omitempty"`
+	// PendingTimeout is how long a pod of the pool may wait to be scheduled
+	// onto a node. Idle pods still unscheduled by then are deleted and
+	// recreated, and the SchedulingFailed condition is set while pods keep
+	// failing to schedule. Unset leaves pending pods alone.
+	// +optional
+	PendingTimeout *metav1.Duration `json:"pendingTimeout,omitempty"`
+}
+
+// ZoneCapacity is the warm capacity a pool keeps in one zone.
+type ZoneCapacity struct {
+	// Zone is the topology.kubernetes.io/zone label value of the nodes in
+	// the zone.
+	// +kubebuilder:validation:MinLength=1
+	Zone string `json:"zone"`
+	// BufferMin is the minimum number of available idle pods in the zone.
+	// +kubebuilder:validation:Minimum=0
+	BufferMin int32 `json:"bufferMin"`
+}
+
+// BufferAutoscaling keeps enough pods in the buffer to serve the allocations
+// expected while replacements start up.
+type BufferAutoscaling struct {
+	// Window is the sliding window the allocation rate is measured over.
+	// Defaults to 10m.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// LeadTime is how long the buffer has to serve allocations on its own,
+	// typically the time a new pod needs to become ready. Defaults to the
+	// observed P90 pod startup latency of the pool, or the window before any
+	// startup has been observed.
+	// +optional
+	LeadTime *metav1.Duration `json:"leadTime,omitempty"`
+}
+
+// CapacitySchedule lists time windows with their own capacity.
+type CapacitySchedule struct {
+	// TimeZone is the IANA time zone the window start expressions are
+	// evaluated in. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows are the scheduled capacity windows. When several windows are
+	// active at the same time the first one listed applies.
+	// +listType=map
+	// +listMapKey=name
+	Windows []CapacityWindow `json:"windows"`
+}
+
+// CapacityWindow overrides the pool capacity from each start of the window
+// for its duration. Fields left unset keep the values of the CapacitySpec.
+type CapacityWindow struct {
+	// Name identifies the window in the pool status.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Start is a five-field cron expression ("minute hour day-of-month month
+	// day-of-week") at which the window opens, e.g. "0 8 * * MON-FRI".
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+	// Duration is how long the window stays open after each start.
+	Duration metav1.Duration `json:"duration"`
+	// BufferMax overrides capacitySpec.bufferMax while the window is open.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BufferMax *int32 `json:"bufferMax,omitempty"`
+	// BufferMin overrides capacitySpec.bufferMin while the window is open.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BufferMin *int32 `json:"bufferMin,omitempty"`
+	// PoolMax overrides capacitySpec.poolMax while the window is open.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PoolMax *int32 `json:"poolMax,omitempty"`
+	// PoolMin overrides capacitySpec.poolMin while the window is open.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PoolMin *int32 `json:"poolMin,omitempty"`
 }
 
 // ScaleStrategy controls the pace of scaling operations.
@@ -94,17 +532,100 @@ type ScaleStrategy struct {
 	// Defaults to 25%.
 	// +optional
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// MaxScaleIn is the most idle pods a scale-in may delete at once without confirmation.
+	// Can be an absolute number (ex: 50) or a percentage of the pool's pods (ex: "50%"),
+	// rounded up. A larger scale-in, such as after poolMax is mistyped, is held with the
+	// NeedsConfirmation condition until the pool is annotated with
+	// pool.sandbox.opensandbox.io/confirm-scale-in set to its generation. Pods deleted by
+	// upgrades and recycling are not counted. Unset scales in without confirmation.
+	// +optional
+	MaxScaleIn *intstr.IntOrString `json:"maxScaleIn,omitempty"`
 }
 
 // UpdateStrategy controls how pool pods are updated when the pool template changes.
 type UpdateStrategy struct {
 	// MaxUnavailable is the maximum number of pods that can be unavailable during an update.
 	// Can be an absolute number (ex: 5) or a percentage of desired pods (ex: "20%").
-	// Defaults to 25%.
+	// Defaults to 25%. Zero is raised to 1 unless MaxSurge is set.
 	// +optional
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// MaxSurge is the maximum number of extra pods of the new revision created
+	// ahead of the idle pods they replace. A replaced pod keeps serving
+	// allocations until a new pod is available, then it is deleted.
+	// Can be an absolute number (ex: 5) or a percentage of the pool's pods
+	// (ex: "20%"), rounded up. Defaults to 0. Surge pods count against poolMax.
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+	// Partition is the number of pods kept at a previous revision. The update
+	// stops once only that many old pods remain, so a new template can be
+	// tried on part of the pool first. Defaults to 0, which updates every pod.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+	// PrePull pulls the images a new template adds onto the nodes the pool's
+	// pods can run on before idle pods are replaced, so the replacements
+	// start without waiting for image pulls. Unset replaces pods at once.
+	// +optional
+	PrePull *ImagePrePull `json:"prePull,omitempty"`
+}
+
+// ImagePrePull configures the pre-pull of the images of a new pool template.
+type ImagePrePull struct {
+	// TimeoutSeconds bounds how long the update waits for the images to be
+	// pulled. Afterwards idle pods are replaced whether or not every node
+	// has pulled them. Defaults to 600.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// ImagePrePullPhase is the phase of the pre-pull of the images of a pool revision.
+type ImagePrePullPhase string
+
+const (
+	// ImagePrePullPhasePulling means nodes are still pulling the images; idle pods are not replaced yet.
+	ImagePrePullPhasePulling ImagePrePullPhase = "Pulling"
+	// ImagePrePullPhaseComplete means every node has pulled the images.
+	ImagePrePullPhaseComplete ImagePrePullPhase = "Complete"
+	// ImagePrePullPhaseTimedOut means not every node pulled the images within the timeout.
+	ImagePrePullPhaseTimedOut ImagePrePullPhase = "TimedOut"
+)
+
+// ImagePrePullStatus is the progress of pulling the images of a pool revision onto the nodes of the pool.
+type ImagePrePullStatus struct {
+	// Revision is the pool revision whose images are pulled.
+	Revision string `json:"revision"`
+	// Images are the images of the revision that the pods it replaces do not use.
+	// +listType=atomic
+	// +optional
+	Images []string `json:"images,omitempty"`
+	// Phase is the phase of the pre-pull.
+	Phase ImagePrePullPhase `json:"phase"`
+	// Nodes is the number of nodes the images are pulled onto.
+	Nodes int32 `json:"nodes"`
+	// Pulled is the number of nodes that have pulled all the images.
+	Pulled int32 `json:"pulled"`
+	// StartTime is when the pre-pull started.
+	StartTime metav1.Time `json:"startTime"`
 }
 
+// Condition types of PoolStatus.Conditions.
+const (
+	// PoolConditionBufferSatisfied is True while the pool has at least its minimum buffer of available pods.
+	PoolConditionBufferSatisfied = "BufferSatisfied"
+	// PoolConditionCapacityExhausted is True while the pool is at poolMax and cannot add pods to restore
+	// its buffer.
+	PoolConditionCapacityExhausted = "CapacityExhausted"
+	// PoolConditionTemplateRollingOut is True while pods of a previous revision remain.
+	PoolConditionTemplateRollingOut = "TemplateRollingOut"
+	// PoolConditionSchedulingFailed is True while pods of the pool stay unscheduled past
+	// capacitySpec.pendingTimeout. It is only reported when pendingTimeout is set.
+	PoolConditionSchedulingFailed = "SchedulingFailed"
+	// PoolConditionNeedsConfirmation is True while a scale-in beyond scaleStrategy.maxScaleIn waits for
+	// confirmation. It is only reported when maxScaleIn is set.
+	PoolConditionNeedsConfirmation = "NeedsConfirmation"
+)
+
 // PoolStatus defines the observed state of Pool.
 type PoolStatus struct {
 	// ObservedGeneration is the most recent generation observed for this BatchSandbox. It corresponds to the
@@ -120,16 +641,101 @@ type PoolStatus struct {
 	Available int32 `json:"available"`
 	// Updated is the number of nodes that have been updated to the latest revision.
 	Updated int32 `json:"updated,omitempty"`
+	// Hibernated is the number of idle pods running with the requests of spec.hibernation.
+	Hibernated int32 `json:"hibernated,omitempty"`
+	// QuotaUsage reports per-tenant allocation usage when AllocationQuota is set.
+	// +listType=map
+	// +listMapKey=tenant
+	// +optional
+	QuotaUsage []TenantQuotaStatus `json:"quotaUsage,omitempty"`
+	// DeletionBlockedBy lists the BatchSandboxes that still hold pods of the
+	// pool while it is being deleted.
+	// +optional
+	DeletionBlockedBy []string `json:"deletionBlockedBy,omitempty"`
+	// ActiveWindow is the name of the capacity schedule window in effect, if
+	// any.
+	// +optional
+	ActiveWindow string `json:"activeWindow,omitempty"`
+	// TargetBuffer is the buffer size chosen by the autoscaler, set when
+	// autoscaling is enabled.
+	// +optional
+	TargetBuffer *int32 `json:"targetBuffer,omitempty"`
+	// Zones breaks the pods of the pool down by the zone of their node.
+	// Pods not bound to a node with a zone are left out.
+	// +listType=map
+	// +listMapKey=zone
+	// +optional
+	Zones []PoolZoneStatus `json:"zones,omitempty"`
+	// Flavors breaks the pods of the pool down by flavor, for pools with
+	// flavors.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Flavors []PoolFlavorStatus `json:"flavors,omitempty"`
+	// PrePull is the progress of pulling the images of the latest revision
+	// onto the nodes of the pool, set when updateStrategy.prePull is.
+	// +optional
+	PrePull *ImagePrePullStatus `json:"prePull,omitempty"`
+	// Selector is the label selector of the pool pods, reported through the
+	// scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// Conditions are the BufferSatisfied, CapacityExhausted, TemplateRollingOut and SchedulingFailed
+	// conditions of the pool.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// TenantQuotaStatus is the observed allocation usage of a single tenant.
+type TenantQuotaStatus struct {
+	// Tenant is the tenant label value.
+	Tenant string `json:"tenant"`
+	// Allocated is the number of pods currently allocated to the tenant.
+	Allocated int32 `json:"allocated"`
+	// Pending is the number of requested pods held back by the quota.
+	Pending int32 `json:"pending,omitempty"`
+	// MaxAllocated is the effective quota of the tenant. Unset means unlimited.
+	// +optional
+	MaxAllocated *int32 `json:"maxAllocated,omitempty"`
+}
+
+// PoolZoneStatus is the observed state of the pods of a pool in one zone.
+type PoolZoneStatus struct {
+	// Zone is the topology.kubernetes.io/zone label value of the nodes.
+	Zone string `json:"zone"`
+	// Total is the number of pods in the zone.
+	Total int32 `json:"total"`
+	// Allocated is the number of pods in the zone allocated to sandboxes.
+	Allocated int32 `json:"allocated"`
+	// Available is the number of idle pods in the zone ready to be allocated.
+	Available int32 `json:"available"`
+}
+
+// PoolFlavorStatus is the observed state of the pods of one flavor of a pool.
+type PoolFlavorStatus struct {
+	// Name is the name of the flavor.
+	Name string `json:"name"`
+	// Total is the number of pods of the flavor.
+	Total int32 `json:"total"`
+	// Allocated is the number of pods of the flavor allocated to sandboxes.
+	Allocated int32 `json:"allocated"`
+	// Available is the number of idle pods of the flavor ready to be allocated.
+	Available int32 `json:"available"`
 }
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.capacitySpec.replicas,statuspath=.status.total,selectorpath=.status.selector
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.total",description="The number of all nodes in pool."
 // +kubebuilder:printcolumn:name="ALLOCATED",type="integer",JSONPath=".status.allocated",description="The number of allocated nodes in pool."
 // +kubebuilder:printcolumn:name="AVAILABLE",type="integer",JSONPath=".status.available",description="The number of available nodes in pool."
 // +kubebuilder:printcolumn:name="UPDATED",type="integer",JSONPath=".status.updated",description="The number of nodes updated to the latest revision."
+// +kubebuilder:printcolumn:name="PAUSED",type="boolean",JSONPath=".spec.paused",priority=1,description="Whether scaling and updates are paused."
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // Pool is the Schema for the pools API.
 type Pool struct {
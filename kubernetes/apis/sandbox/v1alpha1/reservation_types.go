@@ -0,0 +1,108 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReservationSpec defines the desired state of Reservation.
+type ReservationSpec struct {
+	// PoolRef is the pool the pods are reserved in.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	PoolRef string `json:"poolRef"`
+	// Count is the number of pods reserved for the BatchSandboxes referencing the reservation. The pods
+	// they take from it count towards Count, so the reservation holds no pods once they have taken Count.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Count int32 `json:"count"`
+	// ExpireTime is when the reservation stops holding pods. BatchSandboxes referencing it afterwards are
+	// allocated pods like any other. Empty never expires.
+	// +optional
+	// +kubebuilder:validation:Optional
+	ExpireTime *metav1.Time `json:"expireTime,omitempty"`
+}
+
+// ReservationPhase is the phase of a Reservation.
+type ReservationPhase string
+
+const (
+	// ReservationPhasePending means the pool has fewer idle pods than the reservation holds back; it is
+	// scaled up for the rest.
+	ReservationPhasePending ReservationPhase = "Pending"
+	// ReservationPhaseReady means the reservation holds all the pods it has left.
+	ReservationPhaseReady ReservationPhase = "Ready"
+	// ReservationPhaseConsumed means the referencing BatchSandboxes have taken all reserved pods.
+	ReservationPhaseConsumed ReservationPhase = "Consumed"
+	// ReservationPhaseExpired means the reservation is past its ExpireTime and holds no pods.
+	ReservationPhaseExpired ReservationPhase = "Expired"
+)
+
+// ReservationStatus defines the observed state of Reservation.
+type ReservationStatus struct {
+	// ObservedGeneration is the most recent generation observed for this Reservation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is the phase of the reservation.
+	// +optional
+	Phase ReservationPhase `json:"phase,omitempty"`
+	// Pods are the idle pods of the pool held for the reservation. They are not allocated to other
+	// BatchSandboxes, nor deleted by scaling, updates or maxPodAge.
+	// +optional
+	// +listType=atomic
+	Pods []string `json:"pods,omitempty"`
+	// Reserved is the number of Pods.
+	// +optional
+	Reserved int32 `json:"reserved,omitempty"`
+	// Consumed is the number of pods the referencing BatchSandboxes have taken from the reservation.
+	// +optional
+	Consumed int32 `json:"consumed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=rsv
+// +kubebuilder:printcolumn:name="POOL",type="string",JSONPath=".spec.poolRef"
+// +kubebuilder:printcolumn:name="COUNT",type="integer",JSONPath=".spec.count",description="The number of reserved pods."
+// +kubebuilder:printcolumn:name="RESERVED",type="integer",JSONPath=".status.reserved",description="The number of pods held."
+// +kubebuilder:printcolumn:name="CONSUMED",type="integer",JSONPath=".status.consumed",description="The number of pods taken by sandboxes."
+// +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="EXPIRE",type="date",JSONPath=".spec.expireTime",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Reservation holds idle pods of a pool ahead of the BatchSandboxes that will use them. The pool
+// controller keeps the held pods out of other allocations and allocates them to BatchSandboxes whose
+// reservationRef names the reservation, which get pods like any other sandbox once it has expired.
+type Reservation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReservationSpec   `json:"spec,omitempty"`
+	Status ReservationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReservationList contains a list of Reservation.
+type ReservationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Reservation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Reservation{}, &ReservationList{})
+}
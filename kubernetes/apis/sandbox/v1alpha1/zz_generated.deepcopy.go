@@ -20,10 +20,191 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Allocation) DeepCopyInto(out *Allocation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Allocation.
+func (in *Allocation) DeepCopy() *Allocation {
+	if in == nil {
+		return nil
+	}
+	out := new(Allocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Allocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationConstraints) DeepCopyInto(out *AllocationConstraints) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationConstraints.
+func (in *AllocationConstraints) DeepCopy() *AllocationConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationList) DeepCopyInto(out *AllocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Allocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationList.
+func (in *AllocationList) DeepCopy() *AllocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AllocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationPolicy) DeepCopyInto(out *AllocationPolicy) {
+	*out = *in
+	if in.WaitForPool != nil {
+		in, out := &in.WaitForPool, &out.WaitForPool
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Gang != nil {
+		in, out := &in.Gang, &out.Gang
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PreemptionGracePeriodSeconds != nil {
+		in, out := &in.PreemptionGracePeriodSeconds, &out.PreemptionGracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationPolicy.
+func (in *AllocationPolicy) DeepCopy() *AllocationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationQuota) DeepCopyInto(out *AllocationQuota) {
+	*out = *in
+	if in.DefaultMaxAllocated != nil {
+		in, out := &in.DefaultMaxAllocated, &out.DefaultMaxAllocated
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]TenantQuota, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationQuota.
+func (in *AllocationQuota) DeepCopy() *AllocationQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationSpec) DeepCopyInto(out *AllocationSpec) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Released != nil {
+		in, out := &in.Released, &out.Released
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationSpec.
+func (in *AllocationSpec) DeepCopy() *AllocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BatchSandbox) DeepCopyInto(out *BatchSandbox) {
 	*out = *in
@@ -102,6 +283,159 @@ func (in *BatchSandboxList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxSet) DeepCopyInto(out *BatchSandboxSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSet.
+func (in *BatchSandboxSet) DeepCopy() *BatchSandboxSet {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchSandboxSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxSetList) DeepCopyInto(out *BatchSandboxSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BatchSandboxSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSetList.
+func (in *BatchSandboxSetList) DeepCopy() *BatchSandboxSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchSandboxSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxSetParameter) DeepCopyInto(out *BatchSandboxSetParameter) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSetParameter.
+func (in *BatchSandboxSetParameter) DeepCopy() *BatchSandboxSetParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSetParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxSetParameterStatus) DeepCopyInto(out *BatchSandboxSetParameterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSetParameterStatus.
+func (in *BatchSandboxSetParameterStatus) DeepCopy() *BatchSandboxSetParameterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSetParameterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxSetSpec) DeepCopyInto(out *BatchSandboxSetSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]BatchSandboxSetParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSetSpec.
+func (in *BatchSandboxSetSpec) DeepCopy() *BatchSandboxSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxSetStatus) DeepCopyInto(out *BatchSandboxSetStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]BatchSandboxSetParameterStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSetStatus.
+func (in *BatchSandboxSetStatus) DeepCopy() *BatchSandboxSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BatchSandboxSpec) DeepCopyInto(out *BatchSandboxSpec) {
 	*out = *in
@@ -110,6 +444,11 @@ func (in *BatchSandboxSpec) DeepCopyInto(out *BatchSandboxSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.FallbackPoolRefs != nil {
+		in, out := &in.FallbackPoolRefs, &out.FallbackPoolRefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
 		*out = new(v1.PodTemplateSpec)
@@ -122,117 +461,1023 @@ func (in *BatchSandboxSpec) DeepCopyInto(out *BatchSandboxSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ExpireTime != nil {
-		in, out := &in.ExpireTime, &out.ExpireTime
-		*out = (*in).DeepCopy()
+	if in.ExpireTime != nil {
+		in, out := &in.ExpireTime, &out.ExpireTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HeartbeatTimeoutSeconds != nil {
+		in, out := &in.HeartbeatTimeoutSeconds, &out.HeartbeatTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TaskTemplate != nil {
+		in, out := &in.TaskTemplate, &out.TaskTemplate
+		*out = new(TaskTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ShardTaskPatches != nil {
+		in, out := &in.ShardTaskPatches, &out.ShardTaskPatches
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TaskResourcePolicyWhenCompleted != nil {
+		in, out := &in.TaskResourcePolicyWhenCompleted, &out.TaskResourcePolicyWhenCompleted
+		*out = new(TaskResourcePolicy)
+		**out = **in
+	}
+	if in.TaskPlacementPolicy != nil {
+		in, out := &in.TaskPlacementPolicy, &out.TaskPlacementPolicy
+		*out = new(TaskPlacementPolicy)
+		**out = **in
+	}
+	if in.DebugPolicy != nil {
+		in, out := &in.DebugPolicy, &out.DebugPolicy
+		*out = new(DebugPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllocationPolicy != nil {
+		in, out := &in.AllocationPolicy, &out.AllocationPolicy
+		*out = new(AllocationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllocationConstraints != nil {
+		in, out := &in.AllocationConstraints, &out.AllocationConstraints
+		*out = new(AllocationConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllocationDeadlineSeconds != nil {
+		in, out := &in.AllocationDeadlineSeconds, &out.AllocationDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Freeze != nil {
+		in, out := &in.Freeze, &out.Freeze
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSpec.
+func (in *BatchSandboxSpec) DeepCopy() *BatchSandboxSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxStatus) DeepCopyInto(out *BatchSandboxStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]BatchSandboxCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(BatchSandboxUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodRevisions != nil {
+		in, out := &in.PodRevisions, &out.PodRevisions
+		*out = make([]PodRevision, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodEndpoints != nil {
+		in, out := &in.PodEndpoints, &out.PodEndpoints
+		*out = make([]PodEndpoints, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxStatus.
+func (in *BatchSandboxStatus) DeepCopy() *BatchSandboxStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxTemplateSpec) DeepCopyInto(out *BatchSandboxTemplateSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxTemplateSpec.
+func (in *BatchSandboxTemplateSpec) DeepCopy() *BatchSandboxTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSandboxUsage) DeepCopyInto(out *BatchSandboxUsage) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]PodUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxUsage.
+func (in *BatchSandboxUsage) DeepCopy() *BatchSandboxUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchSandboxUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BufferAutoscaling) DeepCopyInto(out *BufferAutoscaling) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LeadTime != nil {
+		in, out := &in.LeadTime, &out.LeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BufferAutoscaling.
+func (in *BufferAutoscaling) DeepCopy() *BufferAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(BufferAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedule) DeepCopyInto(out *CapacitySchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]CapacityWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedule.
+func (in *CapacitySchedule) DeepCopy() *CapacitySchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySpec) DeepCopyInto(out *CapacitySpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(CapacitySchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(BufferAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ZoneCapacity, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingTimeout != nil {
+		in, out := &in.PendingTimeout, &out.PendingTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySpec.
+func (in *CapacitySpec) DeepCopy() *CapacitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityWindow) DeepCopyInto(out *CapacityWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.BufferMax != nil {
+		in, out := &in.BufferMax, &out.BufferMax
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BufferMin != nil {
+		in, out := &in.BufferMin, &out.BufferMin
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PoolMax != nil {
+		in, out := &in.PoolMax, &out.PoolMax
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PoolMin != nil {
+		in, out := &in.PoolMin, &out.PoolMin
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityWindow.
+func (in *CapacityWindow) DeepCopy() *CapacityWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEgressPolicy) DeepCopyInto(out *ClusterEgressPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEgressPolicy.
+func (in *ClusterEgressPolicy) DeepCopy() *ClusterEgressPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEgressPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEgressPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEgressPolicyList) DeepCopyInto(out *ClusterEgressPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterEgressPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEgressPolicyList.
+func (in *ClusterEgressPolicyList) DeepCopy() *ClusterEgressPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEgressPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEgressPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEgressPolicySpec) DeepCopyInto(out *ClusterEgressPolicySpec) {
+	*out = *in
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]EgressRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEgressPolicySpec.
+func (in *ClusterEgressPolicySpec) DeepCopy() *ClusterEgressPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEgressPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyReference) DeepCopyInto(out *ConfigMapKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReference.
+func (in *ConfigMapKeyReference) DeepCopy() *ConfigMapKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerSnapshot) DeepCopyInto(out *ContainerSnapshot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSnapshot.
+func (in *ContainerSnapshot) DeepCopy() *ContainerSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugPolicy) DeepCopyInto(out *DebugPolicy) {
+	*out = *in
+	if in.HoldSeconds != nil {
+		in, out := &in.HoldSeconds, &out.HoldSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugPolicy.
+func (in *DebugPolicy) DeepCopy() *DebugPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressRule) DeepCopyInto(out *EgressRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressRule.
+func (in *EgressRule) DeepCopy() *EgressRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePrePull) DeepCopyInto(out *ImagePrePull) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePrePull.
+func (in *ImagePrePull) DeepCopy() *ImagePrePull {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePrePull)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePrePullStatus) DeepCopyInto(out *ImagePrePullStatus) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePrePullStatus.
+func (in *ImagePrePullStatus) DeepCopy() *ImagePrePullStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePrePullStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodEndpoints) DeepCopyInto(out *PodEndpoints) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodEndpoints.
+func (in *PodEndpoints) DeepCopy() *PodEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(PodEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRevision) DeepCopyInto(out *PodRevision) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRevision.
+func (in *PodRevision) DeepCopy() *PodRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodUsage) DeepCopyInto(out *PodUsage) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	in.Since.DeepCopyInto(&out.Since)
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodUsage.
+func (in *PodUsage) DeepCopy() *PodUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(PodUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Pool) DeepCopyInto(out *Pool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pool.
+func (in *Pool) DeepCopy() *Pool {
+	if in == nil {
+		return nil
+	}
+	out := new(Pool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Pool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolAdoption) DeepCopyInto(out *PoolAdoption) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolAdoption.
+func (in *PoolAdoption) DeepCopy() *PoolAdoption {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolAdoption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolDisruptionBudget) DeepCopyInto(out *PoolDisruptionBudget) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolDisruptionBudget.
+func (in *PoolDisruptionBudget) DeepCopy() *PoolDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolFlavor) DeepCopyInto(out *PoolFlavor) {
+	*out = *in
+	in.Patch.DeepCopyInto(&out.Patch)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolFlavor.
+func (in *PoolFlavor) DeepCopy() *PoolFlavor {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolFlavor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolFlavorStatus) DeepCopyInto(out *PoolFlavorStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolFlavorStatus.
+func (in *PoolFlavorStatus) DeepCopy() *PoolFlavorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolFlavorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolHibernation) DeepCopyInto(out *PoolHibernation) {
+	*out = *in
+	if in.IdleSeconds != nil {
+		in, out := &in.IdleSeconds, &out.IdleSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolHibernation.
+func (in *PoolHibernation) DeepCopy() *PoolHibernation {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolHibernation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolList) DeepCopyInto(out *PoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Pool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolList.
+func (in *PoolList) DeepCopy() *PoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolPriority) DeepCopyInto(out *PoolPriority) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolPriority.
+func (in *PoolPriority) DeepCopy() *PoolPriority {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolPriority)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolReadiness) DeepCopyInto(out *PoolReadiness) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.PodConditionType, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolReadiness.
+func (in *PoolReadiness) DeepCopy() *PoolReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(v1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateFrom != nil {
+		in, out := &in.TemplateFrom, &out.TemplateFrom
+		*out = new(PoolTemplateSource)
+		**out = **in
+	}
+	in.CapacitySpec.DeepCopyInto(&out.CapacitySpec)
+	if in.ScaleStrategy != nil {
+		in, out := &in.ScaleStrategy, &out.ScaleStrategy
+		*out = new(ScaleStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(UpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RecycleStrategy != nil {
+		in, out := &in.RecycleStrategy, &out.RecycleStrategy
+		*out = new(RecycleStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllocationQuota != nil {
+		in, out := &in.AllocationQuota, &out.AllocationQuota
+		*out = new(AllocationQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SessionRecording != nil {
+		in, out := &in.SessionRecording, &out.SessionRecording
+		*out = new(SessionRecording)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(PoolReadiness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPodAge != nil {
+		in, out := &in.MaxPodAge, &out.MaxPodAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxAllocations != nil {
+		in, out := &in.MaxAllocations, &out.MaxAllocations
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnhealthyPodTimeout != nil {
+		in, out := &in.UnhealthyPodTimeout, &out.UnhealthyPodTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Hibernation != nil {
+		in, out := &in.Hibernation, &out.Hibernation
+		*out = new(PoolHibernation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(PoolPriority)
+		**out = **in
+	}
+	if in.Flavors != nil {
+		in, out := &in.Flavors, &out.Flavors
+		*out = make([]PoolFlavor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]v1.PersistentVolumeClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(PoolDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(PoolAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolSpec.
+func (in *PoolSpec) DeepCopy() *PoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolStatus) DeepCopyInto(out *PoolStatus) {
+	*out = *in
+	if in.QuotaUsage != nil {
+		in, out := &in.QuotaUsage, &out.QuotaUsage
+		*out = make([]TenantQuotaStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeletionBlockedBy != nil {
+		in, out := &in.DeletionBlockedBy, &out.DeletionBlockedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.TaskTemplate != nil {
-		in, out := &in.TaskTemplate, &out.TaskTemplate
-		*out = new(TaskTemplateSpec)
+	if in.TargetBuffer != nil {
+		in, out := &in.TargetBuffer, &out.TargetBuffer
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]PoolZoneStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Flavors != nil {
+		in, out := &in.Flavors, &out.Flavors
+		*out = make([]PoolFlavorStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrePull != nil {
+		in, out := &in.PrePull, &out.PrePull
+		*out = new(ImagePrePullStatus)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ShardTaskPatches != nil {
-		in, out := &in.ShardTaskPatches, &out.ShardTaskPatches
-		*out = make([]runtime.RawExtension, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.TaskResourcePolicyWhenCompleted != nil {
-		in, out := &in.TaskResourcePolicyWhenCompleted, &out.TaskResourcePolicyWhenCompleted
-		*out = new(TaskResourcePolicy)
-		**out = **in
-	}
-	if in.Pause != nil {
-		in, out := &in.Pause, &out.Pause
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxSpec.
-func (in *BatchSandboxSpec) DeepCopy() *BatchSandboxSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolStatus.
+func (in *PoolStatus) DeepCopy() *PoolStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(BatchSandboxSpec)
+	out := new(PoolStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BatchSandboxStatus) DeepCopyInto(out *BatchSandboxStatus) {
+func (in *PoolTemplateSource) DeepCopyInto(out *PoolTemplateSource) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]BatchSandboxCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out.ConfigMapKeyRef = in.ConfigMapKeyRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolTemplateSource.
+func (in *PoolTemplateSource) DeepCopy() *PoolTemplateSource {
+	if in == nil {
+		return nil
 	}
+	out := new(PoolTemplateSource)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSandboxStatus.
-func (in *BatchSandboxStatus) DeepCopy() *BatchSandboxStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolZoneStatus) DeepCopyInto(out *PoolZoneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolZoneStatus.
+func (in *PoolZoneStatus) DeepCopy() *PoolZoneStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(BatchSandboxStatus)
+	out := new(PoolZoneStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CapacitySpec) DeepCopyInto(out *CapacitySpec) {
+func (in *ProcessTask) DeepCopyInto(out *ProcessTask) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CaptureOutputBytes != nil {
+		in, out := &in.CaptureOutputBytes, &out.CaptureOutputBytes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOpenFiles != nil {
+		in, out := &in.MaxOpenFiles, &out.MaxOpenFiles
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Nice != nil {
+		in, out := &in.Nice, &out.Nice
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySpec.
-func (in *CapacitySpec) DeepCopy() *CapacitySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessTask.
+func (in *ProcessTask) DeepCopy() *ProcessTask {
 	if in == nil {
 		return nil
 	}
-	out := new(CapacitySpec)
+	out := new(ProcessTask)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ContainerSnapshot) DeepCopyInto(out *ContainerSnapshot) {
+func (in *RecycleStrategy) DeepCopyInto(out *RecycleStrategy) {
 	*out = *in
+	if in.Sanitize != nil {
+		in, out := &in.Sanitize, &out.Sanitize
+		*out = new(SanitizeAction)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSnapshot.
-func (in *ContainerSnapshot) DeepCopy() *ContainerSnapshot {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecycleStrategy.
+func (in *RecycleStrategy) DeepCopy() *RecycleStrategy {
 	if in == nil {
 		return nil
 	}
-	out := new(ContainerSnapshot)
+	out := new(RecycleStrategy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Pool) DeepCopyInto(out *Pool) {
+func (in *Reservation) DeepCopyInto(out *Reservation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pool.
-func (in *Pool) DeepCopy() *Pool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Reservation.
+func (in *Reservation) DeepCopy() *Reservation {
 	if in == nil {
 		return nil
 	}
-	out := new(Pool)
+	out := new(Reservation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Pool) DeepCopyObject() runtime.Object {
+func (in *Reservation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -240,31 +1485,31 @@ func (in *Pool) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PoolList) DeepCopyInto(out *PoolList) {
+func (in *ReservationList) DeepCopyInto(out *ReservationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Pool, len(*in))
+		*out = make([]Reservation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolList.
-func (in *PoolList) DeepCopy() *PoolList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationList.
+func (in *ReservationList) DeepCopy() *ReservationList {
 	if in == nil {
 		return nil
 	}
-	out := new(PoolList)
+	out := new(ReservationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PoolList) DeepCopyObject() runtime.Object {
+func (in *ReservationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -272,84 +1517,40 @@ func (in *PoolList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
+func (in *ReservationSpec) DeepCopyInto(out *ReservationSpec) {
 	*out = *in
-	if in.Template != nil {
-		in, out := &in.Template, &out.Template
-		*out = new(v1.PodTemplateSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	out.CapacitySpec = in.CapacitySpec
-	if in.ScaleStrategy != nil {
-		in, out := &in.ScaleStrategy, &out.ScaleStrategy
-		*out = new(ScaleStrategy)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.UpdateStrategy != nil {
-		in, out := &in.UpdateStrategy, &out.UpdateStrategy
-		*out = new(UpdateStrategy)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.RecycleStrategy != nil {
-		in, out := &in.RecycleStrategy, &out.RecycleStrategy
-		*out = new(RecycleStrategy)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolSpec.
-func (in *PoolSpec) DeepCopy() *PoolSpec {
-	if in == nil {
-		return nil
+	if in.ExpireTime != nil {
+		in, out := &in.ExpireTime, &out.ExpireTime
+		*out = (*in).DeepCopy()
 	}
-	out := new(PoolSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PoolStatus) DeepCopyInto(out *PoolStatus) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolStatus.
-func (in *PoolStatus) DeepCopy() *PoolStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationSpec.
+func (in *ReservationSpec) DeepCopy() *ReservationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PoolStatus)
+	out := new(ReservationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProcessTask) DeepCopyInto(out *ProcessTask) {
+func (in *ReservationStatus) DeepCopyInto(out *ReservationStatus) {
 	*out = *in
-	if in.Command != nil {
-		in, out := &in.Command, &out.Command
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessTask.
-func (in *ProcessTask) DeepCopy() *ProcessTask {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationStatus.
+func (in *ReservationStatus) DeepCopy() *ReservationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ProcessTask)
+	out := new(ReservationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -475,16 +1676,26 @@ func (in *SandboxSnapshotStatus) DeepCopy() *SandboxSnapshotStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RecycleStrategy) DeepCopyInto(out *RecycleStrategy) {
+func (in *SanitizeAction) DeepCopyInto(out *SanitizeAction) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecycleStrategy.
-func (in *RecycleStrategy) DeepCopy() *RecycleStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SanitizeAction.
+func (in *SanitizeAction) DeepCopy() *SanitizeAction {
 	if in == nil {
 		return nil
 	}
-	out := new(RecycleStrategy)
+	out := new(SanitizeAction)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -497,6 +1708,11 @@ func (in *ScaleStrategy) DeepCopyInto(out *ScaleStrategy) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MaxScaleIn != nil {
+		in, out := &in.MaxScaleIn, &out.MaxScaleIn
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleStrategy.
@@ -509,6 +1725,26 @@ func (in *ScaleStrategy) DeepCopy() *ScaleStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionRecording) DeepCopyInto(out *SessionRecording) {
+	*out = *in
+	if in.UploadTimeoutSeconds != nil {
+		in, out := &in.UploadTimeoutSeconds, &out.UploadTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionRecording.
+func (in *SessionRecording) DeepCopy() *SessionRecording {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionRecording)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 	*out = *in
@@ -522,6 +1758,16 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.EstimatedDurationSeconds != nil {
+		in, out := &in.EstimatedDurationSeconds, &out.EstimatedDurationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskSpec.
@@ -645,6 +1891,41 @@ func (in *TaskTemplateSpec) DeepCopy() *TaskTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantQuota) DeepCopyInto(out *TenantQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantQuota.
+func (in *TenantQuota) DeepCopy() *TenantQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantQuotaStatus) DeepCopyInto(out *TenantQuotaStatus) {
+	*out = *in
+	if in.MaxAllocated != nil {
+		in, out := &in.MaxAllocated, &out.MaxAllocated
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantQuotaStatus.
+func (in *TenantQuotaStatus) DeepCopy() *TenantQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpdateStrategy) DeepCopyInto(out *UpdateStrategy) {
 	*out = *in
@@ -653,6 +1934,21 @@ func (in *UpdateStrategy) DeepCopyInto(out *UpdateStrategy) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PrePull != nil {
+		in, out := &in.PrePull, &out.PrePull
+		*out = new(ImagePrePull)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStrategy.
@@ -664,3 +1960,18 @@ func (in *UpdateStrategy) DeepCopy() *UpdateStrategy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneCapacity) DeepCopyInto(out *ZoneCapacity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneCapacity.
+func (in *ZoneCapacity) DeepCopy() *ZoneCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
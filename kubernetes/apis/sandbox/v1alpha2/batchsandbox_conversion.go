@@ -0,0 +1,134 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"maps"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// podList is the JSON shape of the v1alpha1 allocation annotations.
+type podList struct {
+	Pods []string `json:"pods"`
+}
+
+// ConvertTo converts this BatchSandbox to the hub version, rendering the typed fields back into annotations.
+// The allocation annotations carried by the object take precedence over status.allocation, which is a
+// read-only view of them: an update that drops or edits the status keeps the stored allocation, and a typed
+// field only fills in an annotation that is missing. Annotations without a typed counterpart, such as one
+// that failed to parse on the way in, are kept as they are.
+func (src *BatchSandbox) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.BatchSandbox)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = src.Spec.BatchSandboxSpec
+	dst.Status = src.Status.BatchSandboxStatus
+
+	anno := maps.Clone(src.Annotations)
+	set := func(key string, v any) error {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if anno == nil {
+			anno = map[string]string{}
+		}
+		anno[key] = string(raw)
+		return nil
+	}
+	// fill renders an allocation field into its annotation unless the object carries the annotation.
+	fill := func(key string, pods []string) error {
+		if _, ok := anno[key]; ok || pods == nil {
+			return nil
+		}
+		return set(key, podList{Pods: pods})
+	}
+	if alloc := src.Status.Allocation; alloc != nil {
+		if err := fill(v1alpha1.AnnotationAllocStatus, alloc.Pods); err != nil {
+			return err
+		}
+		if err := fill(v1alpha1.AnnotationAllocRelease, alloc.Releasing); err != nil {
+			return err
+		}
+		if err := fill(v1alpha1.AnnotationAllocReleased, alloc.Released); err != nil {
+			return err
+		}
+	}
+	if src.Status.Endpoints != nil {
+		if err := set(v1alpha1.AnnotationEndpoints, src.Status.Endpoints); err != nil {
+			return err
+		}
+	}
+	dst.Annotations = anno
+	return nil
+}
+
+// ConvertFrom converts from the hub version to this BatchSandbox, copying the allocation annotations into
+// typed status fields and moving the endpoints annotation into status.endpoints. The allocation annotations
+// stay on the object, so that a client writing it back without status.allocation keeps the allocation. A
+// malformed annotation is left in place rather than failing the conversion.
+func (dst *BatchSandbox) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.BatchSandbox)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.BatchSandboxSpec = src.Spec
+	dst.Status.BatchSandboxStatus = src.Status
+	dst.Status.Allocation = nil
+	dst.Status.Endpoints = nil
+
+	anno := maps.Clone(src.Annotations)
+	parse := func(key string, v any) bool {
+		raw, ok := anno[key]
+		return ok && json.Unmarshal([]byte(raw), v) == nil
+	}
+	allocation := func() *BatchSandboxAllocation {
+		if dst.Status.Allocation == nil {
+			dst.Status.Allocation = &BatchSandboxAllocation{}
+		}
+		return dst.Status.Allocation
+	}
+	var pods podList
+	if parse(v1alpha1.AnnotationAllocStatus, &pods) {
+		allocation().Pods = nonNil(pods.Pods)
+	}
+	pods = podList{}
+	if parse(v1alpha1.AnnotationAllocRelease, &pods) {
+		allocation().Releasing = nonNil(pods.Pods)
+	}
+	pods = podList{}
+	if parse(v1alpha1.AnnotationAllocReleased, &pods) {
+		allocation().Released = nonNil(pods.Pods)
+	}
+	var endpoints []string
+	if parse(v1alpha1.AnnotationEndpoints, &endpoints) {
+		dst.Status.Endpoints = nonNil(endpoints)
+		delete(anno, v1alpha1.AnnotationEndpoints)
+	}
+	if len(anno) == 0 {
+		anno = nil
+	}
+	dst.Annotations = anno
+	return nil
+}
+
+// nonNil keeps a parsed but empty list distinguishable from a missing annotation.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestBatchSandboxConversion(t *testing.T) {
+	replicas := int32(2)
+	hub := &v1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sbx",
+			Namespace: "default",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllocStatus:   `{"pods":["pod-a","pod-b"]}`,
+				v1alpha1.AnnotationAllocRelease:  `{"pods":["pod-b"]}`,
+				v1alpha1.AnnotationAllocReleased: `{"pods":[]}`,
+				v1alpha1.AnnotationEndpoints:     `["10.0.0.1","10.0.0.2"]`,
+				"other":                          "kept",
+			},
+		},
+		Spec:   v1alpha1.BatchSandboxSpec{Replicas: &replicas, PoolRef: "pool"},
+		Status: v1alpha1.BatchSandboxStatus{Replicas: 2, Ready: 1},
+	}
+
+	sbx := &BatchSandbox{}
+	assert.NoError(t, sbx.ConvertFrom(hub))
+	assert.Equal(t, map[string]string{
+		v1alpha1.AnnotationAllocStatus:   `{"pods":["pod-a","pod-b"]}`,
+		v1alpha1.AnnotationAllocRelease:  `{"pods":["pod-b"]}`,
+		v1alpha1.AnnotationAllocReleased: `{"pods":[]}`,
+		"other":                          "kept",
+	}, sbx.Annotations, "the allocation annotations stay, the endpoints annotation moves to status")
+	assert.Equal(t, "pool", sbx.Spec.PoolRef)
+	assert.Equal(t, int32(1), sbx.Status.Ready)
+	assert.Equal(t, &BatchSandboxAllocation{
+		Pods:      []string{"pod-a", "pod-b"},
+		Releasing: []string{"pod-b"},
+		Released:  []string{},
+	}, sbx.Status.Allocation)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, sbx.Status.Endpoints)
+	// the hub object is not modified
+	assert.Len(t, hub.Annotations, 5)
+
+	back := &v1alpha1.BatchSandbox{}
+	assert.NoError(t, sbx.ConvertTo(back))
+	assert.Equal(t, hub.Spec, back.Spec)
+	assert.Equal(t, hub.Status, back.Status)
+	assert.Equal(t, map[string]string{
+		v1alpha1.AnnotationAllocStatus:   `{"pods":["pod-a","pod-b"]}`,
+		v1alpha1.AnnotationAllocRelease:  `{"pods":["pod-b"]}`,
+		v1alpha1.AnnotationAllocReleased: `{"pods":[]}`,
+		v1alpha1.AnnotationEndpoints:     `["10.0.0.1","10.0.0.2"]`,
+		"other":                          "kept",
+	}, back.Annotations)
+}
+
+func TestBatchSandboxConversion_MalformedAnnotation(t *testing.T) {
+	hub := &v1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sbx",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllocStatus: `{"pods":`,
+				v1alpha1.AnnotationEndpoints:   `["10.0.0.1"]`,
+			},
+		},
+	}
+
+	sbx := &BatchSandbox{}
+	assert.NoError(t, sbx.ConvertFrom(hub))
+	assert.Nil(t, sbx.Status.Allocation)
+	assert.Equal(t, []string{"10.0.0.1"}, sbx.Status.Endpoints)
+	assert.Equal(t, map[string]string{v1alpha1.AnnotationAllocStatus: `{"pods":`}, sbx.Annotations)
+
+	back := &v1alpha1.BatchSandbox{}
+	assert.NoError(t, sbx.ConvertTo(back))
+	assert.Equal(t, hub.Annotations, back.Annotations)
+}
+
+func TestBatchSandboxConversion_NoAllocation(t *testing.T) {
+	hub := &v1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "sbx"}}
+
+	sbx := &BatchSandbox{}
+	assert.NoError(t, sbx.ConvertFrom(hub))
+	assert.Nil(t, sbx.Status.Allocation)
+	assert.Nil(t, sbx.Status.Endpoints)
+	assert.Nil(t, sbx.Annotations)
+
+	back := &v1alpha1.BatchSandbox{}
+	assert.NoError(t, sbx.ConvertTo(back))
+	assert.Nil(t, back.Annotations)
+}
+
+func TestBatchSandboxConversion_UpdateRoundTrip(t *testing.T) {
+	stored := &v1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sbx",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllocStatus:   `{"pods":["pod-a","pod-b"]}`,
+				v1alpha1.AnnotationAllocRelease:  `{"pods":["pod-b"]}`,
+				v1alpha1.AnnotationAllocReleased: `{"pods":["pod-c"]}`,
+			},
+		},
+	}
+	allocationAnnotations := maps.Clone(stored.Annotations)
+
+	tests := []struct {
+		name   string
+		modify func(sbx *BatchSandbox)
+	}{
+		{name: "unchanged", modify: func(*BatchSandbox) {}},
+		{name: "status dropped", modify: func(sbx *BatchSandbox) { sbx.Status = BatchSandboxStatus{} }},
+		{name: "allocation edited", modify: func(sbx *BatchSandbox) {
+			sbx.Status.Allocation = &BatchSandboxAllocation{Pods: []string{"pod-x"}, Releasing: []string{}}
+		}},
+		{name: "label added", modify: func(sbx *BatchSandbox) { sbx.Labels = map[string]string{"app": "demo"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a v1alpha2 client reads the object, modifies it and PUTs it back
+			sbx := &BatchSandbox{}
+			assert.NoError(t, sbx.ConvertFrom(stored))
+			tt.modify(sbx)
+			back := &v1alpha1.BatchSandbox{}
+			assert.NoError(t, sbx.ConvertTo(back))
+			for key, value := range allocationAnnotations {
+				assert.Equal(t, value, back.Annotations[key], key)
+			}
+		})
+	}
+
+	// an object written without the annotations gets them from status.allocation
+	sbx := &BatchSandbox{Status: BatchSandboxStatus{Allocation: &BatchSandboxAllocation{Pods: []string{"pod-a"}}}}
+	back := &v1alpha1.BatchSandbox{}
+	assert.NoError(t, sbx.ConvertTo(back))
+	assert.Equal(t, map[string]string{v1alpha1.AnnotationAllocStatus: `{"pods":["pod-a"]}`}, back.Annotations)
+}
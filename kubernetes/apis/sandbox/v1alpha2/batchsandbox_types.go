@@ -0,0 +1,87 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// BatchSandboxSpec defines the desired state of BatchSandbox. It is unchanged from v1alpha1.
+type BatchSandboxSpec struct {
+	v1alpha1.BatchSandboxSpec `json:",inline"`
+}
+
+// BatchSandboxStatus defines the observed state of BatchSandbox.
+type BatchSandboxStatus struct {
+	v1alpha1.BatchSandboxStatus `json:",inline"`
+
+	// Allocation records the pool pods held by a pooled BatchSandbox, as read from the
+	// alloc-status, alloc-release and alloc-released annotations of v1alpha1. It is read-only:
+	// the annotations stay on the object and are authoritative, so changing or dropping
+	// this field on update has no effect on the allocation.
+	// +optional
+	Allocation *BatchSandboxAllocation `json:"allocation,omitempty"`
+
+	// Endpoints are the IPs of the BatchSandbox pods. It replaces the endpoints annotation of v1alpha1.
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+}
+
+// BatchSandboxAllocation tracks pool pods through allocation and release.
+type BatchSandboxAllocation struct {
+	// Pods are the pool pods allocated to the BatchSandbox.
+	// +optional
+	Pods []string `json:"pods,omitempty"`
+	// Releasing are the pods the BatchSandbox has handed back to the pool.
+	// +optional
+	Releasing []string `json:"releasing,omitempty"`
+	// Released are the pods the pool has reclaimed.
+	// +optional
+	Released []string `json:"released,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:resource:shortName=bsbx
+// +kubebuilder:printcolumn:name="DESIRED",type="integer",JSONPath=".spec.replicas",description="The desired number of pods."
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.replicas",description="The number of currently all pods."
+// +kubebuilder:printcolumn:name="ALLOCATED",type="integer",JSONPath=".status.allocated",description="The number of currently all allocated pods."
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.ready",description="The number of currently all ready pods."
+// +kubebuilder:printcolumn:name="EXPIRE",type="string",JSONPath=".spec.expireTime",description="sandbox expire time"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// BatchSandbox is the Schema for the batchsandboxes API.
+type BatchSandbox struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BatchSandboxSpec   `json:"spec,omitempty"`
+	Status BatchSandboxStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BatchSandboxList contains a list of BatchSandbox.
+type BatchSandboxList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BatchSandbox `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BatchSandbox{}, &BatchSandboxList{})
+}
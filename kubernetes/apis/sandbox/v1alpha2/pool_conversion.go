@@ -0,0 +1,39 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// ConvertTo converts this Pool to the hub version. Only the version changes.
+func (src *Pool) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Pool)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = src.Spec
+	dst.Status = src.Status
+	return nil
+}
+
+// ConvertFrom converts from the hub version to this Pool.
+func (dst *Pool) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Pool)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = src.Spec
+	dst.Status = src.Status
+	return nil
+}
@@ -29,7 +29,7 @@ if [ "$COMPONENT" == "controller" ]; then
     BUILD_ARG="--build-arg PACKAGE=./cmd/controller"
 elif [ "$COMPONENT" == "task-executor" ]; then
     IMAGE_NAME="task-executor"
-    BUILD_ARG="--build-arg PACKAGE=cmd/task-executor/main.go --build-arg USERID=0"
+    BUILD_ARG="--build-arg PACKAGE=cmd/task-executor/main.go --build-arg USERID=0 --build-arg VERSION=${TAG}"
 else
     echo "Error: Unknown component: $COMPONENT"
     echo "Available components: controller, task-executor"
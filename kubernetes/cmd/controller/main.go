@@ -30,6 +30,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
@@ -40,16 +41,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	sandboxv1alpha2 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha2"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/lifecycle"
 	cryptoutil "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/crypto"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/logging"
+	taskexecutor "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 	// +kubebuilder:scaffold:imports
 )
 
 const (
 	defaultBatchSandboxConcurrency = 32
 	defaultPoolConcurrency         = 16
+
+	inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 )
 
 type ConcurrencyConfig map[string]int
@@ -122,10 +128,56 @@ func getKindFromType(obj runtime.Object) string {
 	return gvks[0].Kind
 }
 
+// newPoolOwnership builds the pool ownership of this replica. The identity is the pod name plus a random
+// suffix, so that a restarted replica never mistakes the leases of its predecessor for its own.
+func newPoolOwnership(mgr ctrl.Manager, namespace string, leaseDuration time.Duration) (*controller.PoolOwnership, error) {
+	if namespace == "" {
+		data, err := os.ReadFile(inClusterNamespacePath)
+		if err != nil {
+			return nil, fmt.Errorf("--pool-lease-namespace is required when not running in a cluster: %w", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	return &controller.PoolOwnership{
+		Client:        mgr.GetClient(),
+		Reader:        mgr.GetAPIReader(),
+		Identity:      strings.ToLower(hostname) + "-" + string(uuid.NewUUID())[:8],
+		Namespace:     namespace,
+		LeaseDuration: leaseDuration,
+	}, nil
+}
+
+// newLifecycleWebhooks builds the notifier posting lifecycle events to the comma-separated urls, signed
+// with the secret in secretFile.
+func newLifecycleWebhooks(urls, secretFile string) (*lifecycle.WebhookNotifier, error) {
+	if secretFile == "" {
+		return nil, fmt.Errorf("--lifecycle-webhook-secret-file is required with --lifecycle-webhook-url")
+	}
+	secret, err := os.ReadFile(secretFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lifecycle webhook secret: %w", err)
+	}
+	var list []string
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			list = append(list, url)
+		}
+	}
+	return lifecycle.NewWebhookNotifier(lifecycle.WebhookOptions{
+		URLs:   list,
+		Secret: []byte(strings.TrimSpace(string(secret))),
+	})
+}
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(sandboxv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(sandboxv1alpha2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -156,6 +208,31 @@ func main() {
 	// Controller concurrency options
 	var concurrencyConfig ConcurrencyConfig
 
+	// Task dispatch API options
+	var managerAPIAddr string
+	var managerAPICertPath, managerAPICertName, managerAPICertKey string
+
+	// Pool ownership options
+	var enablePoolOwnership bool
+	var poolLeaseDuration time.Duration
+	var poolLeaseNamespace string
+
+	// Pool pod deletion options
+	var poolDeleteConcurrency int
+	var poolDeleteQPS float64
+	var poolDeleteBurst int
+
+	// Allocation store options
+	var allocationStore string
+	var allocatorPlugins string
+
+	// Auto pool options
+	var autoPool controller.AutoPoolReconciler
+	var autoPoolBuffer, autoPoolMax int
+
+	// Conversion webhook options
+	var enableConversionWebhook bool
+
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -193,6 +270,44 @@ func main() {
 		"Available controllers: batchsandbox, pool. "+
 		"Example: --concurrency='batchsandbox=32;pool=128'")
 
+	flag.BoolVar(&enablePoolOwnership, "pool-ownership", false,
+		"Let manager replicas split Pools with per-Pool ownership leases instead of reconciling all Pools on the leader. "+
+			"Other controllers keep using leader election.")
+	flag.DurationVar(&poolLeaseDuration, "pool-lease-duration", controller.DefaultPoolLeaseDuration,
+		"How long a Pool ownership lease stays valid without renewal; a failed replica's Pools are taken over after it.")
+	flag.StringVar(&poolLeaseNamespace, "pool-lease-namespace", "",
+		"The namespace of the manager replica membership leases. Defaults to the namespace of the manager pod.")
+	flag.IntVar(&poolDeleteConcurrency, "pool-delete-concurrency", controller.DefaultPoolDeleteConcurrency,
+		"The number of pods of a pool deleted in parallel when it scales in or replaces pods.")
+	flag.Float64Var(&poolDeleteQPS, "pool-delete-qps", 50,
+		"The rate at which the pods of a pool are deleted; deletions beyond it are deferred to a later reconcile. 0 disables the limit.")
+	flag.IntVar(&poolDeleteBurst, "pool-delete-burst", 100, "The burst of pod deletions of a pool above --pool-delete-qps.")
+	flag.StringVar(&allocationStore, "allocation-store", controller.AllocationStoreAnnotation,
+		"Where pool allocations are persisted: \"annotation\" keeps them in BatchSandbox annotations, \"resource\" "+
+			"moves them to Allocation objects, migrating each BatchSandbox on its next allocation change.")
+	flag.StringVar(&allocatorPlugins, "allocator-plugins", "",
+		"Comma-separated allocator plugins placing pool pods, each optionally followed by =<weight> of its scores, "+
+			"e.g. \"NodeLabels,Colocation=2\". Built in: NodeLabels, Colocation. Leave empty to allocate pods in pool order.")
+	flag.IntVar(&autoPool.Threshold, "auto-pool-threshold", 0,
+		"Create a Pool for a BatchSandbox template once this many template BatchSandboxes in a namespace use it "+
+			"within --auto-pool-window, and allocate the following ones from it. Leave as 0 to disable auto pools.")
+	flag.DurationVar(&autoPool.Window, "auto-pool-window", time.Hour,
+		"How far back BatchSandboxes count towards --auto-pool-threshold.")
+	flag.IntVar(&autoPoolBuffer, "auto-pool-buffer", 2, "The buffer of idle pods of an auto pool.")
+	flag.IntVar(&autoPoolMax, "auto-pool-max", 10, "The poolMax of an auto pool.")
+	flag.DurationVar(&autoPool.IdleTimeout, "auto-pool-idle-timeout", time.Hour,
+		"Delete an auto pool once no BatchSandbox with its template has been created for this long. 0 keeps auto pools.")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false,
+		"Serve the /convert webhook between the v1alpha1 and v1alpha2 BatchSandbox and Pool APIs. "+
+			"Requires webhook certificates and the CRD conversion patches in config/crd/patches.")
+	flag.StringVar(&managerAPIAddr, "manager-api-bind-address", "0", "The address the manager API binds to, "+
+		"e.g. :8090. The API submits and syncs BatchSandbox tasks on behalf of callers that can't reach pod IPs "+
+		"and estimates whether pools can satisfy new BatchSandboxes. Leave as 0 to disable it.")
+	flag.StringVar(&managerAPICertPath, "manager-api-cert-path", "",
+		"The directory that contains the manager API server certificate. Required when the manager API is enabled.")
+	flag.StringVar(&managerAPICertName, "manager-api-cert-name", "tls.crt", "The name of the manager API server certificate file.")
+	flag.StringVar(&managerAPICertKey, "manager-api-cert-key", "tls.key", "The name of the manager API server key file.")
+
 	// Image committer
 	var imageCommitterImage string
 	flag.StringVar(&imageCommitterImage, "image-committer-image", "image-committer:dev", "The image used for commit operations (contains nerdctl tool).")
@@ -213,6 +328,17 @@ func main() {
 	var resumePullSecret string
 	flag.StringVar(&resumePullSecret, "resume-pull-secret", "", "K8s Secret name for pulling snapshot images during resume.")
 
+	var sidecarTokenFile string
+	flag.StringVar(&sidecarTokenFile, "sidecar-token-file", "", "A projected service-account token presented as a bearer token "+
+		"to task-executors and egress sidecars that run with OPENSANDBOX_AUTH_MODE. Re-read periodically to follow rotation.")
+
+	var lifecycleWebhookURLs string
+	var lifecycleWebhookSecretFile string
+	flag.StringVar(&lifecycleWebhookURLs, "lifecycle-webhook-url", "", "Comma-separated URLs that receive signed lifecycle events "+
+		"(SandboxAllocated, SandboxReleased, TaskSucceeded, TaskFailed, PoolSaturated). Empty to send none.")
+	flag.StringVar(&lifecycleWebhookSecretFile, "lifecycle-webhook-secret-file", "",
+		"A file holding the secret lifecycle events are signed with. Required with --lifecycle-webhook-url.")
+
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 
@@ -233,6 +359,8 @@ func main() {
 	logger := logging.NewLoggerWithZapOptions(logOpts)
 	ctrl.SetLogger(logger)
 
+	taskexecutor.SetTokenFile(sidecarTokenFile)
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -252,8 +380,8 @@ func main() {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
-	// Create watchers for metrics and webhooks certificates
-	var metricsCertWatcher, webhookCertWatcher *certwatcher.CertWatcher
+	// Create watchers for metrics, webhooks and manager API certificates
+	var metricsCertWatcher, webhookCertWatcher, managerAPICertWatcher *certwatcher.CertWatcher
 
 	// Initial webhook TLS options
 	webhookTLSOpts := tlsOpts
@@ -370,6 +498,54 @@ func main() {
 		})
 	}
 
+	// The manager API receives bearer tokens and is only served over TLS.
+	managerAPITLSOpts := tlsOpts
+	if managerAPIAddr != "0" {
+		if len(managerAPICertPath) == 0 {
+			setupLog.Error(nil, "The manager API requires --manager-api-cert-path")
+			os.Exit(1)
+		}
+		managerAPICertFile := filepath.Join(managerAPICertPath, managerAPICertName)
+		managerAPIKeyFile := filepath.Join(managerAPICertPath, managerAPICertKey)
+		if !allowWeakTLSKeyLengths {
+			if err := cryptoutil.ValidateCertificateKeyPair(managerAPICertFile, managerAPIKeyFile); err != nil {
+				setupLog.Error(err, "Manager API certificate does not meet NIST minimum key/hash requirements",
+					"manager-api-cert-file", managerAPICertFile, "manager-api-key-file", managerAPIKeyFile)
+				os.Exit(1)
+			}
+		}
+
+		setupLog.Info("Initializing manager API certificate watcher using provided certificates",
+			"manager-api-cert-path", managerAPICertPath, "manager-api-cert-name", managerAPICertName,
+			"manager-api-cert-key", managerAPICertKey)
+
+		var err error
+		managerAPICertWatcher, err = certwatcher.New(
+			managerAPICertFile,
+			managerAPIKeyFile,
+		)
+		if err != nil {
+			setupLog.Error(err, "Failed to initialize manager API certificate watcher")
+			os.Exit(1)
+		}
+
+		managerAPITLSOpts = append(managerAPITLSOpts, func(config *tls.Config) {
+			config.GetCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				cert, err := managerAPICertWatcher.GetCertificate(chi)
+				if err != nil {
+					return nil, err
+				}
+				if allowWeakTLSKeyLengths {
+					return cert, nil
+				}
+				if err := cryptoutil.ValidateTLSCertificate(managerAPICertFile, cert); err != nil {
+					return nil, err
+				}
+				return cert, nil
+			}
+		})
+	}
+
 	config := ctrl.GetConfigOrDie()
 	// Set client rate limiter if specified
 	if kubeClientQPS > 0 {
@@ -410,21 +586,92 @@ func main() {
 	poolConcurrency := concurrencyConfig.Get(poolKindName, defaultPoolConcurrency)
 	setupLog.Info("controller concurrency configured", batchSandboxKindName, batchSandboxConcurrency, poolKindName, poolConcurrency)
 
+	var batchSandboxAutoPool *controller.AutoPoolReconciler
+	if autoPool.Threshold > 0 {
+		autoPool.Client = mgr.GetClient()
+		autoPool.Recorder = mgr.GetEventRecorderFor("autopool-controller")
+		autoPool.Buffer = int32(autoPoolBuffer)
+		autoPool.PoolMax = int32(autoPoolMax)
+		if err := autoPool.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "AutoPool")
+			os.Exit(1)
+		}
+		batchSandboxAutoPool = &autoPool
+		setupLog.Info("auto pools enabled", "threshold", autoPool.Threshold, "window", autoPool.Window,
+			"buffer", autoPoolBuffer, "poolMax", autoPoolMax, "idleTimeout", autoPool.IdleTimeout)
+	}
+	var lifecycleNotifier lifecycle.Notifier
+	if lifecycleWebhookURLs != "" {
+		notifier, err := newLifecycleWebhooks(lifecycleWebhookURLs, lifecycleWebhookSecretFile)
+		if err != nil {
+			setupLog.Error(err, "unable to set up lifecycle webhooks")
+			os.Exit(1)
+		}
+		if err := mgr.Add(notifier); err != nil {
+			setupLog.Error(err, "unable to add lifecycle webhooks to manager")
+			os.Exit(1)
+		}
+		lifecycleNotifier = notifier
+		setupLog.Info("lifecycle webhooks enabled", "urls", lifecycleWebhookURLs)
+	}
 	if err := (&controller.BatchSandboxReconciler{
 		Client:           mgr.GetClient(),
 		Scheme:           mgr.GetScheme(),
 		Recorder:         mgr.GetEventRecorderFor("batchsandbox-controller"),
 		ResumePullSecret: resumePullSecret,
+		AutoPool:         batchSandboxAutoPool,
+		Lifecycle:        lifecycleNotifier,
 	}).SetupWithManager(mgr, batchSandboxConcurrency); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BatchSandbox")
 		os.Exit(1)
 	}
+	var poolOwnership *controller.PoolOwnership
+	if enablePoolOwnership {
+		poolOwnership, err = newPoolOwnership(mgr, poolLeaseNamespace, poolLeaseDuration)
+		if err != nil {
+			setupLog.Error(err, "unable to set up pool ownership")
+			os.Exit(1)
+		}
+		if err := mgr.Add(poolOwnership); err != nil {
+			setupLog.Error(err, "unable to add pool ownership to manager")
+			os.Exit(1)
+		}
+		setupLog.Info("pool ownership enabled", "identity", poolOwnership.Identity, "leaseDuration", poolLeaseDuration)
+	}
+	var allocator controller.Allocator
+	switch allocationStore {
+	case controller.AllocationStoreAnnotation:
+		allocator = controller.NewDefaultAllocator(mgr.GetClient(), mgr.GetEventRecorderFor("pool-controller"))
+	case controller.AllocationStoreResource:
+		allocator = controller.NewResourceAllocator(mgr.GetClient(), mgr.GetEventRecorderFor("pool-controller"))
+		setupLog.Info("allocations are stored in Allocation objects")
+	default:
+		setupLog.Error(fmt.Errorf("unknown allocation store %q", allocationStore), "invalid --allocation-store")
+		os.Exit(1)
+	}
+	if allocatorPlugins != "" {
+		configs, err := controller.ParseAllocatorPluginConfigs(allocatorPlugins)
+		if err == nil {
+			var framework *controller.AllocatorFramework
+			if framework, err = controller.NewAllocatorFramework(mgr.GetClient(), configs); err == nil {
+				allocator, err = controller.WithAllocatorPlugins(allocator, framework)
+			}
+		}
+		if err != nil {
+			setupLog.Error(err, "invalid --allocator-plugins")
+			os.Exit(1)
+		}
+		setupLog.Info("allocator plugins enabled", "plugins", allocatorPlugins)
+	}
 	if err := (&controller.PoolReconciler{
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		Recorder:   mgr.GetEventRecorderFor("pool-controller"),
-		Allocator:  controller.NewDefaultAllocator(mgr.GetClient()),
-		RestConfig: mgr.GetConfig(),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("pool-controller"),
+		Allocator:   allocator,
+		RestConfig:  mgr.GetConfig(),
+		Ownership:   poolOwnership,
+		Lifecycle:   lifecycleNotifier,
+		PodDeletion: controller.NewPodDeletionLimiter(poolDeleteConcurrency, poolDeleteQPS, poolDeleteBurst),
 	}).SetupWithManager(mgr, poolConcurrency); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Pool")
 		os.Exit(1)
@@ -442,8 +689,36 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "SandboxSnapshot")
 		os.Exit(1)
 	}
+	if err := (&controller.BatchSandboxSetReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("batchsandboxset-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BatchSandboxSet")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if enableConversionWebhook {
+		for _, obj := range []runtime.Object{&sandboxv1alpha1.BatchSandbox{}, &sandboxv1alpha1.Pool{}} {
+			if err := ctrl.NewWebhookManagedBy(mgr).For(obj).Complete(); err != nil {
+				setupLog.Error(err, "unable to create conversion webhook", "kind", getKindFromType(obj))
+				os.Exit(1)
+			}
+		}
+	}
+
+	if managerAPIAddr != "0" {
+		if err := mgr.Add(&controller.ManagerAPIServer{
+			Client:      mgr.GetClient(),
+			BindAddress: managerAPIAddr,
+			TLSOpts:     managerAPITLSOpts,
+		}); err != nil {
+			setupLog.Error(err, "unable to add manager API server to manager")
+			os.Exit(1)
+		}
+	}
+
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(metricsCertWatcher); err != nil {
@@ -460,6 +735,14 @@ func main() {
 		}
 	}
 
+	if managerAPICertWatcher != nil {
+		setupLog.Info("Adding manager API certificate watcher to manager")
+		if err := mgr.Add(managerAPICertWatcher); err != nil {
+			setupLog.Error(err, "unable to add manager API certificate watcher to manager")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
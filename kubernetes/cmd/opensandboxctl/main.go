@@ -0,0 +1,304 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// opensandboxctl emulates a sandbox on the local machine: it serves the
+// task-executor API in process mode and runs execd next to it, so SDKs and
+// agents can be tested against the same HTTP APIs without a cluster.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/logging"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/manager"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/server"
+	store "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/storage"
+)
+
+const usage = `Usage: opensandboxctl <command> [flags]
+
+Commands:
+  up    Run the task-executor and execd locally until interrupted
+
+Run 'opensandboxctl up -h' for the flags of up.
+`
+
+// readyTimeout is how long up waits for both APIs to answer.
+var readyTimeout = 30 * time.Second
+
+// upOptions are the flags of the up command.
+type upOptions struct {
+	dataDir            string
+	keepData           bool
+	listenAddr         string
+	maxConcurrentTasks int
+	execdBinary        string
+	execdPort          int
+	execdAccessToken   string
+	noExecd            bool
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "up":
+		opts, err := parseUpFlags(os.Args[2:])
+		if err != nil {
+			os.Exit(2)
+		}
+		if err := up(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "opensandboxctl: %v\n", err)
+			os.Exit(1)
+		}
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "opensandboxctl: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+func parseUpFlags(args []string) (*upOptions, error) {
+	opts := &upOptions{}
+	fs := flag.NewFlagSet("up", flag.ContinueOnError)
+	fs.StringVar(&opts.dataDir, "data-dir", "", "directory for tasks, logs and the execd workspace (default: a temporary directory removed on exit)")
+	fs.BoolVar(&opts.keepData, "keep-data", false, "keep the temporary data directory on exit")
+	fs.StringVar(&opts.listenAddr, "listen-addr", "127.0.0.1:5758", "address the task-executor API listens on")
+	fs.IntVar(&opts.maxConcurrentTasks, "max-concurrent-tasks", config.NewConfig().MaxConcurrentTasks, "how many tasks may be active at once")
+	fs.StringVar(&opts.execdBinary, "execd-binary", "execd", "path of the execd binary, looked up in PATH when it has no slash")
+	fs.IntVar(&opts.execdPort, "execd-port", 44772, "port the execd API listens on")
+	fs.StringVar(&opts.execdAccessToken, "execd-access-token", "", "access token required by the execd API")
+	fs.BoolVar(&opts.noExecd, "no-execd", false, "run the task-executor only")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		err := fmt.Errorf("unexpected arguments: %v", fs.Args())
+		fmt.Fprintln(fs.Output(), err)
+		return nil, err
+	}
+	return opts, nil
+}
+
+// prepareDataDir creates the data directory and returns the function that
+// cleans it up on exit. Only temporary directories are ever removed.
+func prepareDataDir(dir string, keep bool) (string, func(), error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", nil, fmt.Errorf("failed to create data dir: %w", err)
+		}
+		return dir, func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "opensandbox-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary data dir: %w", err)
+	}
+	if keep {
+		return dir, func() {}, nil
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// executorConfig is the task-executor configuration of a local sandbox:
+// process mode with all state kept under the data directory.
+func executorConfig(dataDir string, opts *upOptions) *config.Config {
+	cfg := config.NewConfig()
+	cfg.DataDir = filepath.Join(dataDir, "tasks")
+	cfg.LogDir = filepath.Join(dataDir, "logs")
+	cfg.ImageDir = filepath.Join(dataDir, "images")
+	cfg.ListenAddr = opts.listenAddr
+	cfg.EnableSidecarMode = false
+	cfg.LogFormat = logging.FormatText
+	cfg.MaxConcurrentTasks = opts.maxConcurrentTasks
+	return cfg
+}
+
+// execdCommand builds the execd command. It runs in the workspace directory
+// and logs to w.
+func execdCommand(opts *upOptions, workspace string, w io.Writer) *exec.Cmd {
+	args := []string{"--port", strconv.Itoa(opts.execdPort)}
+	if opts.execdAccessToken != "" {
+		args = append(args, "--access-token", opts.execdAccessToken)
+	}
+	cmd := exec.Command(opts.execdBinary, args...)
+	cmd.Dir = workspace
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd
+}
+
+// waitReady polls url until it answers with a status below 500.
+func waitReady(ctx context.Context, url string) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < http.StatusInternalServerError {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s is not ready: %w", url, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func up(opts *upOptions) error {
+	dataDir, cleanup, err := prepareDataDir(opts.dataDir, opts.keepData)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cfg := executorConfig(dataDir, opts)
+	if err := cfg.InitKlog(); err != nil {
+		return fmt.Errorf("failed to init klog: %w", err)
+	}
+	defer klog.Flush()
+
+	taskStore, err := store.NewFileStore(cfg.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to create task store: %w", err)
+	}
+	executor, err := runtime.NewExecutor(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+	taskManager, err := manager.NewTaskManager(cfg, taskStore, executor)
+	if err != nil {
+		return fmt.Errorf("failed to create task manager: %w", err)
+	}
+	taskManager.Start(context.Background())
+	defer taskManager.Stop()
+
+	listener, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.ListenAddr, err)
+	}
+	svr := &http.Server{
+		Handler:      server.NewRouter(server.NewHandler(taskManager, cfg)),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := svr.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = svr.Shutdown(ctx)
+	}()
+	executorURL := "http://" + listener.Addr().String()
+
+	// execd exits with us, whichever way we leave
+	execdExited := make(chan error, 1)
+	var execdURL string
+	if !opts.noExecd {
+		workspace := filepath.Join(dataDir, "workspace")
+		if err := os.MkdirAll(workspace, 0o755); err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+		if err := os.MkdirAll(cfg.LogDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create log dir: %w", err)
+		}
+		logFile, err := os.Create(filepath.Join(cfg.LogDir, "execd.log"))
+		if err != nil {
+			return fmt.Errorf("failed to create execd log: %w", err)
+		}
+		defer logFile.Close()
+		cmd := execdCommand(opts, workspace, logFile)
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start execd: %w", err)
+		}
+		go func() { execdExited <- cmd.Wait() }()
+		defer stopProcess(cmd, execdExited)
+		execdURL = fmt.Sprintf("http://127.0.0.1:%d", opts.execdPort)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	if err := waitReady(ctx, executorURL+"/health"); err != nil {
+		return err
+	}
+	if execdURL != "" {
+		if err := waitReady(ctx, execdURL+"/ping"); err != nil {
+			return fmt.Errorf("%w, see %s", err, filepath.Join(cfg.LogDir, "execd.log"))
+		}
+	}
+
+	fmt.Printf("task-executor: %s\n", executorURL)
+	if execdURL != "" {
+		fmt.Printf("execd:         %s\n", execdURL)
+	}
+	fmt.Printf("data dir:      %s\n", dataDir)
+	fmt.Println("Press Ctrl+C to stop.")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	select {
+	case <-quit:
+		fmt.Println("Stopping...")
+		return nil
+	case err := <-serveErr:
+		return fmt.Errorf("task-executor API failed: %w", err)
+	case err := <-execdExited:
+		// Hand the result back so the deferred stop does not wait for it
+		execdExited <- err
+		return fmt.Errorf("execd exited: %v", err)
+	}
+}
+
+// stopProcess terminates the process and kills it when it has not exited
+// within 10 seconds. exited receives the result of cmd.Wait.
+func stopProcess(cmd *exec.Cmd, exited chan error) {
+	// Signaling an exited process fails harmlessly
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-exited:
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+}
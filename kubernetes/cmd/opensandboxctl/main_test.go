@@ -0,0 +1,130 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrepareDataDirRemovesOnlyTemporaryDirs(t *testing.T) {
+	dir, cleanup, err := prepareDataDir("", false)
+	if err != nil {
+		t.Fatalf("prepareDataDir: %v", err)
+	}
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected temporary dir %s to be removed, stat err %v", dir, err)
+	}
+
+	dir, cleanup, err = prepareDataDir("", true)
+	if err != nil {
+		t.Fatalf("prepareDataDir: %v", err)
+	}
+	kept := dir
+	t.Cleanup(func() { os.RemoveAll(kept) })
+	cleanup()
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expected kept dir %s to exist: %v", dir, err)
+	}
+
+	given := filepath.Join(t.TempDir(), "data")
+	dir, cleanup, err = prepareDataDir(given, false)
+	if err != nil {
+		t.Fatalf("prepareDataDir: %v", err)
+	}
+	cleanup()
+	if dir != given {
+		t.Fatalf("expected %s, got %s", given, dir)
+	}
+	if _, err := os.Stat(given); err != nil {
+		t.Fatalf("expected given dir to be kept: %v", err)
+	}
+}
+
+func TestExecutorConfigKeepsStateUnderDataDir(t *testing.T) {
+	opts, err := parseUpFlags([]string{"--listen-addr", "127.0.0.1:0", "--max-concurrent-tasks", "4"})
+	if err != nil {
+		t.Fatalf("parseUpFlags: %v", err)
+	}
+	cfg := executorConfig("/data", opts)
+
+	if cfg.EnableSidecarMode {
+		t.Fatal("expected process mode")
+	}
+	for name, dir := range map[string]string{"tasks": cfg.DataDir, "logs": cfg.LogDir, "images": cfg.ImageDir} {
+		if dir != filepath.Join("/data", name) {
+			t.Fatalf("expected %s dir under the data dir, got %s", name, dir)
+		}
+	}
+	if cfg.ListenAddr != "127.0.0.1:0" || cfg.MaxConcurrentTasks != 4 {
+		t.Fatalf("flags not applied: %s, %d", cfg.ListenAddr, cfg.MaxConcurrentTasks)
+	}
+}
+
+func TestExecdCommand(t *testing.T) {
+	opts, err := parseUpFlags([]string{"--execd-binary", "/opt/execd", "--execd-port", "9000", "--execd-access-token", "secret"})
+	if err != nil {
+		t.Fatalf("parseUpFlags: %v", err)
+	}
+	cmd := execdCommand(opts, "/data/workspace", io.Discard)
+
+	want := []string{"/opt/execd", "--port", "9000", "--access-token", "secret"}
+	if !reflect.DeepEqual(cmd.Args, want) {
+		t.Fatalf("expected args %v, got %v", want, cmd.Args)
+	}
+	if cmd.Dir != "/data/workspace" {
+		t.Fatalf("expected execd to run in the workspace, got %s", cmd.Dir)
+	}
+}
+
+func TestParseUpFlagsRejectsArguments(t *testing.T) {
+	if _, err := parseUpFlags([]string{"extra"}); err == nil {
+		t.Fatal("expected an error for positional arguments")
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := waitReady(ctx, srv.URL); err != nil {
+		t.Fatalf("waitReady: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 probes, got %d", calls)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := waitReady(ctx, "http://127.0.0.1:1"); err == nil {
+		t.Fatal("expected an error when nothing listens")
+	}
+}
@@ -20,14 +20,19 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"k8s.io/klog/v2"
 
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/auth"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/manager"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/readiness"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/recording"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/selfupdate"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/server"
 	store "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/storage"
 )
@@ -37,19 +42,33 @@ func main() {
 	cfg := config.NewConfig()
 	cfg.LoadFromEnv()
 	cfg.LoadFromFlags()
+	if err := cfg.Reload(); err != nil {
+		fmt.Printf("failed to load config file: %v\n", err)
+		os.Exit(1)
+	}
 	if err := cfg.InitKlog(); err != nil {
-		fmt.Println("failed to init klog")
+		fmt.Printf("failed to init klog: %v\n", err)
 		os.Exit(1)
 	}
 	klog.InfoS("task-executor starting", "dataDir", cfg.DataDir, "listenAddr", cfg.ListenAddr, "sidecarMode", cfg.EnableSidecarMode)
 
 	// Initialize TaskStore
-	taskStore, err := store.NewFileStore(cfg.DataDir)
+	storeKey, err := store.LoadKey(cfg.StoreKey, cfg.StoreKeyFile)
+	if err != nil {
+		klog.ErrorS(err, "invalid task store encryption key")
+		os.Exit(1)
+	}
+	var taskStore store.TaskStore
+	if storeKey != nil {
+		taskStore, err = store.NewEncryptedFileStore(cfg.DataDir, storeKey)
+	} else {
+		taskStore, err = store.NewFileStore(cfg.DataDir)
+	}
 	if err != nil {
 		klog.ErrorS(err, "failed to create task store")
 		os.Exit(1)
 	}
-	klog.InfoS("task store initialized", "dataDir", cfg.DataDir)
+	klog.InfoS("task store initialized", "dataDir", cfg.DataDir, "encrypted", storeKey != nil)
 
 	// Initialize Executor
 	exec, err := runtime.NewExecutor(cfg)
@@ -73,6 +92,68 @@ func main() {
 	handler := server.NewHandler(taskManager, cfg)
 	router := server.NewRouter(handler)
 
+	// Callers authenticate with projected service-account tokens when enabled
+	authenticator, err := auth.NewInClusterAuthenticator(cfg.AuthMode, cfg.AuthAudience, cfg.AuthIssuer, cfg.AuthJWKSURL, auth.SplitSubjects(cfg.AuthAllowedSubjects))
+	if err != nil {
+		klog.ErrorS(err, "failed to create authenticator")
+		os.Exit(1)
+	}
+	if authenticator != nil {
+		router = authenticator.Middleware(router)
+		klog.InfoS("service account token auth enabled", "mode", cfg.AuthMode)
+	}
+
+	// Self-update hands verified binaries back to us for the re-exec
+	staged := make(chan string, 1)
+	if cfg.EnableSelfUpdate {
+		publicKey, err := selfupdate.ParsePublicKey(cfg.SelfUpdatePublicKey)
+		if err != nil {
+			klog.ErrorS(err, "self-update requires a valid public key")
+			os.Exit(1)
+		}
+		token, err := os.ReadFile(cfg.SelfUpdateTokenFile)
+		if err != nil || strings.TrimSpace(string(token)) == "" {
+			klog.ErrorS(err, "self-update requires a token file", "file", cfg.SelfUpdateTokenFile)
+			os.Exit(1)
+		}
+		updater, err := selfupdate.NewUpdater(cfg.DataDir, publicKey, selfupdate.Version)
+		if err != nil {
+			klog.ErrorS(err, "self-update requires a versioned build")
+			os.Exit(1)
+		}
+		handler.EnableSelfUpdate(updater, strings.TrimSpace(string(token)), staged)
+		klog.InfoS("self-update enabled", "version", selfupdate.Version)
+	}
+
+	// Session records are sealed and uploaded when the controller releases the pod
+	if cfg.RecordingDir != "" {
+		if cfg.RecordingUploadURL == "" {
+			klog.ErrorS(nil, "session recording requires an upload URL")
+			os.Exit(1)
+		}
+		token, err := os.ReadFile(cfg.RecordingUploadTokenFile)
+		if err != nil || strings.TrimSpace(string(token)) == "" {
+			klog.ErrorS(err, "session recording requires an upload token file", "file", cfg.RecordingUploadTokenFile)
+			os.Exit(1)
+		}
+		sealer, err := recording.NewSealer(cfg.RecordingDir, cfg.RecordingUploadURL, strings.TrimSpace(string(token)), cfg.PodNamespace, cfg.PodName)
+		if err != nil {
+			klog.ErrorS(err, "invalid session recording upload settings")
+			os.Exit(1)
+		}
+		handler.EnableRecording(sealer)
+		klog.InfoS("session recording enabled", "dir", cfg.RecordingDir)
+	}
+
+	// Readiness is reported on the pod so readinessGates can wait for us
+	var reporter *readiness.Reporter
+	if cfg.ReportExecutorReady {
+		if reporter, err = readiness.NewInClusterReporter(cfg.PodNamespace, cfg.PodName); err != nil {
+			klog.ErrorS(err, "failed to create readiness reporter")
+			os.Exit(1)
+		}
+	}
+
 	// Create HTTP Server
 	svr := &http.Server{
 		Addr:         cfg.ListenAddr,
@@ -81,26 +162,82 @@ func main() {
 		WriteTimeout: cfg.WriteTimeout,
 	}
 
+	// Reuse the listener of the previous binary after a self-update
+	listener, err := selfupdate.Listen(cfg.ListenAddr)
+	if err != nil {
+		klog.ErrorS(err, "failed to listen", "address", cfg.ListenAddr)
+		os.Exit(1)
+	}
+
 	// Start HTTP server in goroutine
 	go func() {
-		klog.InfoS("HTTP server listening", "address", cfg.ListenAddr)
-		if err := svr.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		klog.InfoS("HTTP server listening", "address", listener.Addr().String())
+		if err := svr.Serve(listener); err != nil && err != http.ErrServerClosed {
 			klog.ErrorS(err, "HTTP server error")
 			os.Exit(1)
 		}
 	}()
 
-	// Wait for interrupt signal
+	// Tunables are reloaded from the config file on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := cfg.Reload(); err != nil {
+				klog.ErrorS(err, "failed to reload config file", "file", cfg.ConfigFile)
+				continue
+			}
+			klog.InfoS("config file reloaded", "file", cfg.ConfigFile)
+		}
+	}()
+
+	reportCtx, reportCancel := context.WithCancel(context.Background())
+	defer reportCancel()
+	if reporter != nil {
+		// The listener is bound, so requests are accepted from here on
+		go func() {
+			if err := reporter.SetWithRetry(reportCtx, true, readiness.ReasonServing); err != nil {
+				klog.ErrorS(err, "gave up reporting executor readiness")
+				return
+			}
+			klog.InfoS("executor readiness reported", "pod", cfg.PodName)
+		}()
+	}
+
+	// Wait for interrupt signal or a staged update
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	var binary string
+	select {
+	case <-quit:
+		klog.InfoS("shutting down task-executor gracefully...")
+	case binary = <-staged:
+		klog.InfoS("re-executing task-executor for self-update", "binary", binary)
+	}
 
-	klog.InfoS("shutting down task-executor gracefully...")
+	// Keep the socket open across Shutdown so connections queue in the
+	// backlog until the new binary accepts them
+	listenFD := -1
+	if binary != "" {
+		if listenFD, err = selfupdate.InheritableFD(listener); err != nil {
+			klog.ErrorS(err, "failed to preserve listener for self-update")
+			os.Exit(1)
+		}
+	}
 
 	// Shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// 0. Take the pod out of endpoints unless the new binary takes over the
+	// listener right away
+	reportCancel()
+	if reporter != nil && binary == "" {
+		if err := reporter.Set(shutdownCtx, false, readiness.ReasonStopping); err != nil {
+			klog.ErrorS(err, "failed to clear executor readiness")
+		}
+	}
+
 	// 1. Stop HTTP server first
 	if err := svr.Shutdown(shutdownCtx); err != nil {
 		klog.ErrorS(err, "HTTP server shutdown error")
@@ -112,5 +249,15 @@ func main() {
 	taskManager.Stop()
 	klog.InfoS("task manager stopped")
 
+	// 3. Hand over to the new binary. Tasks keep running since the PID does
+	// not change, and the new task manager recovers them from the store.
+	if binary != "" {
+		klog.Flush()
+		err := selfupdate.Exec(binary, listenFD)
+		klog.ErrorS(err, "failed to re-exec task-executor", "binary", binary)
+		klog.Flush()
+		os.Exit(1)
+	}
+
 	klog.InfoS("task-executor stopped successfully")
 }
@@ -14,6 +14,8 @@
 
 package algorithm
 
+import "time"
+
 // Algorithm determines how available pods are distributed among sandbox requests.
 type Algorithm interface {
 	// Schedule distributes available pods among sandbox requests and returns the allocation action.
@@ -27,6 +29,8 @@ type SandboxRequest struct {
 	CurReleased   []string
 	PodSupplement int32
 	ToRelease     []string
+	// Gang requests receive all of PodSupplement at once or nothing.
+	Gang bool
 }
 
 // AllocAction represents the result of a scheduling decision.
@@ -37,4 +41,40 @@ type AllocAction struct {
 	ToRelease map[string][]string
 	// pod request count
 	PodSupplement int32
+	// pod request count by flavor of the pool, "" for the pool template
+	FlavorSupplement map[string]int32
+	// new preemption state of the sandboxes whose state changes (sandbox -> state)
+	Preemptions map[string]*Preemption
+	// earliest deadline of the pods being preempted, zero if none
+	PreemptionDeadline time.Time
+	// new state of the reservations of the pool (reservation -> state)
+	Reservations map[string]*Reservation
+	// earliest expiry of the reservations holding pods, zero if none
+	ReservationExpiry time.Time
+}
+
+// Preemption is the preemption state of a sandbox whose pods are taken by sandboxes of higher priority.
+type Preemption struct {
+	// pods chosen to be preempted (pod -> mark), released at their deadline
+	Pods map[string]PreemptionMark `json:"pods,omitempty"`
+	// pods released by preemption (pod -> preemptor), which the sandbox is allocated replacements for
+	Preempted map[string]string `json:"preempted,omitempty"`
+}
+
+// PreemptionMark records which sandbox a pod is preempted for and when it is released.
+type PreemptionMark struct {
+	Preemptor string    `json:"preemptor"`
+	Deadline  time.Time `json:"deadline"`
+}
+
+// Reservation is the state of a reservation holding pods of the pool for the sandboxes referencing it.
+type Reservation struct {
+	// idle pods held for the reservation
+	Pods []string
+	// pods the referencing sandboxes have taken from the reservation
+	Consumed int32
+	// pods the reservation still needs but the pool has no idle pods for
+	Shortfall int32
+	// whether the reservation is past its expiry
+	Expired bool
 }
@@ -34,6 +34,10 @@ func (p *PackedSchedule) Schedule(availablePods []string, allRequest []*SandboxR
 		if need <= 0 {
 			continue
 		}
+		if req.Gang && int32(len(availablePods)) < need {
+			action.PodSupplement += need
+			continue
+		}
 		if int32(len(availablePods)) >= need {
 			action.ToAllocate[req.SandboxName] = availablePods[:need]
 			availablePods = availablePods[need:]
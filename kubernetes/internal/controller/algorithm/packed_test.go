@@ -103,6 +103,18 @@ func TestPackedSchedule(t *testing.T) {
 			wantRelease:    map[string][]string{},
 			wantSupplement: 3, // 1 remaining for sbx2 + 2 for sbx3
 		},
+		{
+			name:          "GangAllocation",
+			availablePods: []string{"pod1", "pod2", "pod3"},
+			allRequest: []*SandboxRequest{
+				{SandboxName: "sbx1", PodSupplement: 4, Gang: true},
+				{SandboxName: "sbx2", PodSupplement: 2, Gang: true},
+				{SandboxName: "sbx3", PodSupplement: 2},
+			},
+			wantAllocate:   map[string][]string{"sbx2": {"pod1", "pod2"}, "sbx3": {"pod3"}},
+			wantRelease:    map[string][]string{},
+			wantSupplement: 5, // 4 for sbx1 + 1 remaining for sbx3
+		},
 	}
 
 	for _, tt := range tests {
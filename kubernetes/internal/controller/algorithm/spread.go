@@ -32,6 +32,20 @@ func (s *SpreadSchedule) Schedule(availablePods []string, allRequest []*SandboxR
 		}
 	}
 
+	// Gang requests are served first, in order, since they take all of their pods at once or none.
+	podIdx := 0
+	for _, req := range allRequest {
+		if !req.Gang || req.PodSupplement <= 0 {
+			continue
+		}
+		if int32(len(availablePods)-podIdx) < req.PodSupplement {
+			action.PodSupplement += req.PodSupplement
+			continue
+		}
+		action.ToAllocate[req.SandboxName] = availablePods[podIdx : podIdx+int(req.PodSupplement)]
+		podIdx += int(req.PodSupplement)
+	}
+
 	// Build a list of sandboxes that still need pods, with their remaining counts.
 	type needEntry struct {
 		sandboxName string
@@ -39,13 +53,12 @@ func (s *SpreadSchedule) Schedule(availablePods []string, allRequest []*SandboxR
 	}
 	var needs []needEntry
 	for _, req := range allRequest {
-		if req.PodSupplement > 0 {
+		if req.PodSupplement > 0 && !req.Gang {
 			needs = append(needs, needEntry{sandboxName: req.SandboxName, remaining: req.PodSupplement})
 		}
 	}
 
 	// Round-robin: each round give one pod to each sandbox that still needs pods.
-	podIdx := 0
 	for podIdx < len(availablePods) && len(needs) > 0 {
 		var nextRound []needEntry
 		for _, n := range needs {
@@ -104,6 +104,21 @@ func TestSpreadSchedule(t *testing.T) {
 			wantRelease:    map[string][]string{},
 			wantSupplement: 1, // 1 remaining for sbx1
 		},
+		{
+			name:          "GangAllocation",
+			availablePods: []string{"pod1", "pod2", "pod3", "pod4", "pod5"},
+			allRequest: []*SandboxRequest{
+				{SandboxName: "sbx1", PodSupplement: 2},
+				{SandboxName: "sbx2", PodSupplement: 3, Gang: true},
+				{SandboxName: "sbx3", PodSupplement: 3, Gang: true},
+				{SandboxName: "sbx4", PodSupplement: 1},
+			},
+			// sbx2 takes its gang first; sbx3 cannot be met and gets nothing.
+			// Round 1: sbx1→pod4, sbx4→pod5
+			wantAllocate:   map[string][]string{"sbx1": {"pod4"}, "sbx2": {"pod1", "pod2", "pod3"}, "sbx4": {"pod5"}},
+			wantRelease:    map[string][]string{},
+			wantSupplement: 4, // 1 remaining for sbx1 + 3 for sbx3
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,117 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// defaultAllocationDecodeCacheSize bounds the decoded annotations kept, a few per BatchSandbox.
+const defaultAllocationDecodeCacheSize = 65536
+
+var (
+	allocationDecodeCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opensandbox_allocation_decode_cache_lookups_total",
+		Help: "Lookups of decoded BatchSandbox allocation annotations, by annotation and result (hit or miss).",
+	}, []string{"annotation", "result"})
+	allocationDecodeCacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "opensandbox_allocation_decode_cache_entries",
+		Help: "Decoded BatchSandbox allocation annotations held in the decode cache.",
+	})
+
+	// allocationDecodes is shared by the Pool and BatchSandbox controllers, which read the
+	// allocation annotations of the same BatchSandboxes.
+	allocationDecodes = newAllocationDecodeCache(defaultAllocationDecodeCacheSize)
+)
+
+func init() {
+	metrics.Registry.MustRegister(allocationDecodeCacheLookups, allocationDecodeCacheEntries)
+}
+
+// allocationDecodeCache keeps the pod lists decoded from the allocation annotations of BatchSandboxes.
+// An entry is used as long as the object has the same resourceVersion and annotation value, so the
+// annotations of an unchanged object are unmarshaled once instead of on every reconcile.
+type allocationDecodeCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[allocationDecodeKey]allocationDecodeEntry
+}
+
+type allocationDecodeKey struct {
+	uid        types.UID
+	annotation string
+}
+
+type allocationDecodeEntry struct {
+	resourceVersion string
+	raw             string
+	pods            []string
+}
+
+func newAllocationDecodeCache(maxEntries int) *allocationDecodeCache {
+	return &allocationDecodeCache{
+		maxEntries: maxEntries,
+		entries:    make(map[allocationDecodeKey]allocationDecodeEntry),
+	}
+}
+
+// decodePods returns the pods of the {"pods": [...]} document in the annotation of obj, nil when the
+// annotation is not set. The returned slice is the caller's to modify.
+func (c *allocationDecodeCache) decodePods(obj metav1.Object, annotation string) ([]string, error) {
+	raw := obj.GetAnnotations()[annotation]
+	if raw == "" {
+		return nil, nil
+	}
+	// Objects built in memory have no resourceVersion to tell their versions apart.
+	resourceVersion := obj.GetResourceVersion()
+	key := allocationDecodeKey{uid: obj.GetUID(), annotation: annotation}
+	if resourceVersion != "" {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		// The annotation is compared too: it may have been changed on the object without a write yet.
+		if ok && entry.resourceVersion == resourceVersion && entry.raw == raw {
+			allocationDecodeCacheLookups.WithLabelValues(annotation, "hit").Inc()
+			return slices.Clone(entry.pods), nil
+		}
+		allocationDecodeCacheLookups.WithLabelValues(annotation, "miss").Inc()
+	}
+
+	var doc struct {
+		Pods []string `json:"pods"`
+	}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, err
+	}
+	if resourceVersion == "" {
+		return doc.Pods, nil
+	}
+	c.mu.Lock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		// Entries of deleted objects are never looked up again; start over rather than track them.
+		c.entries = make(map[allocationDecodeKey]allocationDecodeEntry)
+	}
+	c.entries[key] = allocationDecodeEntry{resourceVersion: resourceVersion, raw: raw, pods: doc.Pods}
+	allocationDecodeCacheEntries.Set(float64(len(c.entries)))
+	c.mu.Unlock()
+	return slices.Clone(doc.Pods), nil
+}
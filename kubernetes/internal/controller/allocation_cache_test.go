@@ -0,0 +1,85 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func decodeCacheLookups(t *testing.T, result string) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, allocationDecodeCacheLookups.WithLabelValues(AnnoAllocStatusKey, result).Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestAllocationDecodeCache(t *testing.T) {
+	cache := newAllocationDecodeCache(2)
+	bs := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{
+		UID: "uid-1", ResourceVersion: "1",
+		Annotations: map[string]string{AnnoAllocStatusKey: `{"pods":["a","b"]}`},
+	}}
+	hits, misses := decodeCacheLookups(t, "hit"), decodeCacheLookups(t, "miss")
+
+	pods, err := cache.decodePods(bs, AnnoAllocStatusKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, pods)
+	pods[0] = "changed"
+	pods, err = cache.decodePods(bs, AnnoAllocStatusKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, pods, "callers get their own copy")
+	assert.Equal(t, hits+1, decodeCacheLookups(t, "hit"))
+	assert.Equal(t, misses+1, decodeCacheLookups(t, "miss"))
+
+	// An annotation changed in memory is decoded again even with the same resourceVersion.
+	setSandboxAllocation(bs, SandboxAllocation{Pods: []string{"c"}})
+	pods, err = cache.decodePods(bs, AnnoAllocStatusKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, pods)
+	assert.Equal(t, misses+2, decodeCacheLookups(t, "miss"))
+
+	// Objects without a resourceVersion are not cached.
+	inMemory := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{UID: "uid-2", Annotations: bs.Annotations}}
+	_, err = cache.decodePods(inMemory, AnnoAllocStatusKey)
+	require.NoError(t, err)
+	assert.Len(t, cache.entries, 1)
+
+	pods, err = cache.decodePods(&sandboxv1alpha1.BatchSandbox{}, AnnoAllocStatusKey)
+	require.NoError(t, err)
+	assert.Nil(t, pods)
+	bs.Annotations[AnnoAllocStatusKey] = "{"
+	_, err = cache.decodePods(bs, AnnoAllocStatusKey)
+	assert.Error(t, err)
+}
+
+func TestAllocationDecodeCacheBounded(t *testing.T) {
+	cache := newAllocationDecodeCache(2)
+	for _, uid := range []types.UID{"uid-a", "uid-b", "uid-c"} {
+		bs := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{
+			UID: uid, ResourceVersion: "1",
+			Annotations: map[string]string{AnnoAllocReleaseKey: `{"pods":["p"]}`},
+		}}
+		_, err := cache.decodePods(bs, AnnoAllocReleaseKey)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(cache.entries), 2)
+	}
+}
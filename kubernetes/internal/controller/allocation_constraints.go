@@ -0,0 +1,176 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+// podConstraint decides whether a pod of the pool meets the allocation constraints of a sandbox.
+type podConstraint struct {
+	podSelector  labels.Selector
+	nodeSelector map[string]string
+	zones        []string
+}
+
+// constrainedSchedule serves the sandboxes in the order of the requests: each sandbox with allocation
+// constraints on its own from the available pods that meet them, and each run of other sandboxes together
+// from the pods left with the inner algorithm.
+type constrainedSchedule struct {
+	inner       algorithm.Algorithm
+	constraints map[string]*podConstraint // sandbox -> constraint
+	pods        map[string]*corev1.Pod
+	// nodeLabels holds the labels of the node of each pod that the constraints look at.
+	nodeLabels map[string]map[string]string
+}
+
+// newConstrainedSchedule wraps the algorithm so that it honors the allocation constraints of the sandboxes,
+// or returns it as is when no sandbox has any.
+func newConstrainedSchedule(ctx context.Context, c client.Client, inner algorithm.Algorithm, pods []*corev1.Pod,
+	sandboxes []*sandboxv1alpha1.BatchSandbox) (algorithm.Algorithm, error) {
+	log := logf.FromContext(ctx)
+	constraints := make(map[string]*podConstraint)
+	var nodeKeys []string
+	for _, sandbox := range sandboxes {
+		spec := sandbox.Spec.AllocationConstraints
+		if spec == nil {
+			continue
+		}
+		constraint := &podConstraint{podSelector: labels.Everything(), nodeSelector: spec.NodeSelector, zones: spec.Zones}
+		if spec.PodSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(spec.PodSelector)
+			if err != nil {
+				// An invalid selector matches no pod rather than every pod.
+				log.Error(err, "Invalid allocation pod selector", "sandbox", sandbox.Name)
+				selector = labels.Nothing()
+			}
+			constraint.podSelector = selector
+		}
+		for key := range spec.NodeSelector {
+			if !slices.Contains(nodeKeys, key) {
+				nodeKeys = append(nodeKeys, key)
+			}
+		}
+		if len(spec.Zones) > 0 && !slices.Contains(nodeKeys, corev1.LabelTopologyZone) {
+			nodeKeys = append(nodeKeys, corev1.LabelTopologyZone)
+		}
+		constraints[sandbox.Name] = constraint
+	}
+	if len(constraints) == 0 {
+		return inner, nil
+	}
+
+	s := &constrainedSchedule{
+		inner:       inner,
+		constraints: constraints,
+		pods:        make(map[string]*corev1.Pod, len(pods)),
+		nodeLabels:  make(map[string]map[string]string, len(pods)),
+	}
+	for _, pod := range pods {
+		s.pods[pod.Name] = pod
+	}
+	if len(nodeKeys) > 0 {
+		values, err := podNodeLabels(ctx, c, pods, nodeKeys)
+		if err != nil {
+			return nil, err
+		}
+		for name, podValues := range values {
+			nodeLabels := make(map[string]string, len(nodeKeys))
+			for i, key := range nodeKeys {
+				if podValues[i] != "" {
+					nodeLabels[key] = podValues[i]
+				}
+			}
+			s.nodeLabels[name] = nodeLabels
+		}
+	}
+	return s, nil
+}
+
+func (s *constrainedSchedule) Schedule(availablePods []string, allRequest []*algorithm.SandboxRequest) *algorithm.AllocAction {
+	action := &algorithm.AllocAction{
+		ToAllocate: make(map[string][]string),
+		ToRelease:  make(map[string][]string),
+	}
+	remaining := slices.Clone(availablePods)
+	serve := func(pods []string, requests []*algorithm.SandboxRequest) {
+		// The inner algorithm may return slices of pods, which must not change with remaining.
+		served := s.inner.Schedule(slices.Clone(pods), requests)
+		mergeAllocAction(action, served)
+		for _, picked := range served.ToAllocate {
+			remaining = slices.DeleteFunc(remaining, func(name string) bool { return slices.Contains(picked, name) })
+		}
+	}
+	var batch []*algorithm.SandboxRequest
+	for _, req := range allRequest {
+		constraint, ok := s.constraints[req.SandboxName]
+		if !ok || req.PodSupplement <= 0 {
+			batch = append(batch, req)
+			continue
+		}
+		if len(batch) > 0 {
+			serve(remaining, batch)
+			batch = nil
+		}
+		serve(slices.DeleteFunc(slices.Clone(remaining), func(name string) bool { return !s.meets(name, constraint) }),
+			[]*algorithm.SandboxRequest{req})
+	}
+	if len(batch) > 0 {
+		serve(remaining, batch)
+	}
+	return action
+}
+
+// meets reports whether the pod meets the constraint.
+func (s *constrainedSchedule) meets(podName string, constraint *podConstraint) bool {
+	pod, ok := s.pods[podName]
+	if !ok || !constraint.podSelector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	nodeLabels := s.nodeLabels[podName]
+	for key, value := range constraint.nodeSelector {
+		if actual, ok := nodeLabels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return len(constraint.zones) == 0 || slices.Contains(constraint.zones, nodeLabels[corev1.LabelTopologyZone])
+}
+
+// mergeAllocAction adds the allocations, releases and supplements of src to dst.
+func mergeAllocAction(dst, src *algorithm.AllocAction) {
+	for name, pods := range src.ToAllocate {
+		dst.ToAllocate[name] = pods
+	}
+	for name, pods := range src.ToRelease {
+		dst.ToRelease[name] = pods
+	}
+	dst.PodSupplement += src.PodSupplement
+	if len(src.FlavorSupplement) > 0 && dst.FlavorSupplement == nil {
+		dst.FlavorSupplement = make(map[string]int32, len(src.FlavorSupplement))
+	}
+	for flavor, supplement := range src.FlavorSupplement {
+		dst.FlavorSupplement[flavor] += supplement
+	}
+}
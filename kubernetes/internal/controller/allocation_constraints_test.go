@@ -0,0 +1,110 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+func constrainedSandbox(name string, constraints *sandboxv1alpha1.AllocationConstraints) *sandboxv1alpha1.BatchSandbox {
+	return &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{AllocationConstraints: constraints},
+	}
+}
+
+func TestNewConstrainedSchedule_WithoutConstraints(t *testing.T) {
+	inner := &algorithm.PackedSchedule{}
+	algo, err := newConstrainedSchedule(context.Background(), nil, inner, nil, []*sandboxv1alpha1.BatchSandbox{constrainedSandbox("plain", nil)})
+	require.NoError(t, err)
+	assert.Same(t, inner, algo)
+}
+
+func TestConstrainedSchedule(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		pluginTestNode("a100-node", map[string]string{"gpu": "a100", corev1.LabelTopologyZone: "zone-a"}),
+		pluginTestNode("cpu-node", map[string]string{corev1.LabelTopologyZone: "zone-b"}),
+	).Build()
+	pods := []*corev1.Pod{
+		pluginTestPod("cpu-1", "cpu-node", map[string]string{"tier": "standard"}),
+		pluginTestPod("gpu-1", "a100-node", map[string]string{"tier": "premium"}),
+		pluginTestPod("gpu-2", "a100-node", map[string]string{"tier": "standard"}),
+		pluginTestPod("unbound", "", map[string]string{"tier": "premium"}),
+	}
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		constrainedSandbox("plain", nil),
+		constrainedSandbox("needs-a100", &sandboxv1alpha1.AllocationConstraints{NodeSelector: map[string]string{"gpu": "a100"}}),
+		constrainedSandbox("premium-zone-a", &sandboxv1alpha1.AllocationConstraints{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "premium"}},
+			Zones:       []string{"zone-a"},
+		}),
+		constrainedSandbox("invalid", &sandboxv1alpha1.AllocationConstraints{
+			PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}}},
+		}),
+	}
+	algo, err := newConstrainedSchedule(ctx, c, &algorithm.PackedSchedule{}, pods, sandboxes)
+	require.NoError(t, err)
+
+	action := algo.Schedule([]string{"gpu-1", "gpu-2", "cpu-1", "unbound"}, []*algorithm.SandboxRequest{
+		{SandboxName: "premium-zone-a", PodSupplement: 1},
+		{SandboxName: "needs-a100", PodSupplement: 2, ToRelease: []string{"old"}},
+		{SandboxName: "invalid", PodSupplement: 1},
+		{SandboxName: "plain", PodSupplement: 2},
+	})
+
+	assert.Equal(t, []string{"gpu-1"}, action.ToAllocate["premium-zone-a"])
+	assert.Equal(t, []string{"gpu-2"}, action.ToAllocate["needs-a100"], "pods of other nodes are skipped")
+	assert.Equal(t, []string{"old"}, action.ToRelease["needs-a100"])
+	assert.Empty(t, action.ToAllocate["invalid"], "an invalid selector matches no pod")
+	assert.Equal(t, []string{"cpu-1", "unbound"}, action.ToAllocate["plain"], "later sandboxes get the pods left")
+	assert.Equal(t, int32(2), action.PodSupplement)
+}
+
+func TestConstrainedSchedule_KeepsRequestOrder(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		pluginTestNode("a100-node", map[string]string{"gpu": "a100"}),
+	).Build()
+	pods := []*corev1.Pod{pluginTestPod("gpu-1", "a100-node", nil)}
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		constrainedSandbox("older-plain", nil),
+		constrainedSandbox("newer-a100", &sandboxv1alpha1.AllocationConstraints{NodeSelector: map[string]string{"gpu": "a100"}}),
+		constrainedSandbox("newest-plain", nil),
+	}
+	algo, err := newConstrainedSchedule(ctx, c, &algorithm.PackedSchedule{}, pods, sandboxes)
+	require.NoError(t, err)
+
+	action := algo.Schedule([]string{"gpu-1"}, []*algorithm.SandboxRequest{
+		{SandboxName: "older-plain", PodSupplement: 1},
+		{SandboxName: "newer-a100", PodSupplement: 1},
+		{SandboxName: "newest-plain", PodSupplement: 1},
+	})
+
+	assert.Equal(t, []string{"gpu-1"}, action.ToAllocate["older-plain"], "the older request wins the only idle pod")
+	assert.Empty(t, action.ToAllocate["newer-a100"])
+	assert.Empty(t, action.ToAllocate["newest-plain"])
+	assert.Equal(t, int32(2), action.PodSupplement)
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	gerrors "errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+const (
+	reasonPreempting         = "Preempting"
+	reasonPreempted          = "Preempted"
+	reasonPreemptionCanceled = "PreemptionCanceled"
+
+	defaultPreemptionGracePeriod = 30 * time.Second
+)
+
+// preemptsLowerPriority reports whether the sandbox may take pods from sandboxes of lower priority.
+func preemptsLowerPriority(sandbox *sandboxv1alpha1.BatchSandbox) bool {
+	policy := sandbox.Spec.AllocationPolicy
+	return policy != nil && policy.PreemptionPolicy == sandboxv1alpha1.AllocationPreemptLowerPriority
+}
+
+// preemptionGracePeriod returns how long the pods of the sandbox are kept once they are chosen to be preempted.
+func preemptionGracePeriod(sandbox *sandboxv1alpha1.BatchSandbox) time.Duration {
+	if policy := sandbox.Spec.AllocationPolicy; policy != nil && policy.PreemptionGracePeriodSeconds != nil {
+		return time.Duration(*policy.PreemptionGracePeriodSeconds) * time.Second
+	}
+	return defaultPreemptionGracePeriod
+}
+
+// schedulePreemption lets the sandboxes with the PreemptLowerPriority policy that the action leaves short of
+// pods take pods from sandboxes of lower priority, once the pool is at poolMax and has no pod of their flavor
+// left. A pod is first marked with its preemptor and a deadline, the grace period of its sandbox, and only
+// released at the deadline; marks whose preemptor no longer waits are dropped. A preempted pod is forgotten
+// once it has been recycled, so the victim keeps it if it is allocated the pod again. The released pods are
+// added to the ToRelease of the action, and the preemption states that change to its Preemptions.
+func schedulePreemption(ctx context.Context, spec *AllocSpec, podAllocation map[string]string, availablePods []string,
+	allRequest []*algorithm.SandboxRequest, action *algorithm.AllocAction, now time.Time) {
+	log := logf.FromContext(ctx)
+	sandboxes := make(map[string]*sandboxv1alpha1.BatchSandbox, len(spec.Sandboxes))
+	states := make(map[string]algorithm.Preemption)
+	preemptors := false
+	for _, sandbox := range spec.Sandboxes {
+		sandboxes[sandbox.Name] = sandbox
+		preemptors = preemptors || preemptsLowerPriority(sandbox)
+		// Invalid states are logged by getSandboxRequest and start over.
+		if state, err := parseSandboxPreemption(sandbox); err == nil && (len(state.Pods) > 0 || len(state.Preempted) > 0) {
+			states[sandbox.Name] = state
+		}
+	}
+	if !preemptors && len(states) == 0 {
+		return
+	}
+
+	// The pods a sandbox keeps are the ones it may be preempted of.
+	requests := make(map[string]*algorithm.SandboxRequest, len(allRequest))
+	held := make(map[string][]string, len(allRequest))
+	for _, req := range allRequest {
+		requests[req.SandboxName] = req
+		for _, name := range req.CurAllocation {
+			if !slices.Contains(req.CurReleased, name) && !slices.Contains(req.ToRelease, name) {
+				held[req.SandboxName] = append(held[req.SandboxName], name)
+			}
+		}
+	}
+
+	// A sandbox preempts only what the pool cannot supply: the pool is at poolMax and this round leaves no
+	// available pod of its flavor.
+	podFlavors := make(map[string]string, len(spec.Pods))
+	for _, pod := range spec.Pods {
+		podFlavors[pod.Name] = pod.Labels[LabelPoolFlavor]
+	}
+	allocated := make(map[string]bool)
+	for _, pods := range action.ToAllocate {
+		for _, name := range pods {
+			allocated[name] = true
+		}
+	}
+	available := make(map[string]bool, len(availablePods))
+	free := make(map[string]int)
+	for _, name := range availablePods {
+		available[name] = true
+		if !allocated[name] {
+			free[podFlavors[name]]++
+		}
+	}
+	atMax := int32(len(spec.Pods)) >= spec.Pool.Spec.CapacitySpec.PoolMax
+	need := make(map[string]int32)
+	for _, req := range allRequest {
+		sandbox, ok := sandboxes[req.SandboxName]
+		if !ok || !preemptsLowerPriority(sandbox) || !sandbox.DeletionTimestamp.IsZero() || !atMax || free[sandbox.Spec.Flavor] > 0 {
+			continue
+		}
+		if missing := req.PodSupplement - int32(len(action.ToAllocate[req.SandboxName])); missing > 0 {
+			need[req.SandboxName] = missing
+		}
+	}
+
+	// A preempted pod is done with once it is gone, no longer allocated to the victim, or recycled: available
+	// again or allocated to another sandbox. Otherwise the victim would release it again when it is given the
+	// pod back.
+	preempted := make(map[string]map[string]string, len(states))
+	for victim, state := range states {
+		preempted[victim] = maps.Clone(state.Preempted)
+		maps.DeleteFunc(preempted[victim], func(name, _ string) bool {
+			req := requests[victim]
+			if _, exists := podFlavors[name]; !exists || req == nil || !slices.Contains(req.CurAllocation, name) {
+				return true
+			}
+			owner, owned := podAllocation[name]
+			return available[name] || owned && owner != victim
+		})
+	}
+
+	// Pods already preempted that are still recycling are on their way to the preemptor.
+	for victim := range states {
+		for name, preemptor := range preempted[victim] {
+			if _, waiting := need[preemptor]; !waiting || podFlavors[name] != sandboxes[preemptor].Spec.Flavor {
+				continue
+			}
+			owner, owned := podAllocation[name]
+			releasing := owner == victim && !slices.Contains(requests[victim].CurReleased, name)
+			if _, exists := podFlavors[name]; exists && !available[name] && (!owned || releasing) {
+				need[preemptor]--
+			}
+		}
+	}
+
+	next := make(map[string]*algorithm.Preemption, len(states))
+	stateOf := func(name string) *algorithm.Preemption {
+		state, ok := next[name]
+		if !ok {
+			state = &algorithm.Preemption{}
+			next[name] = state
+		}
+		if state.Pods == nil {
+			state.Pods = make(map[string]algorithm.PreemptionMark)
+		}
+		if state.Preempted == nil {
+			state.Preempted = make(map[string]string)
+		}
+		return state
+	}
+	for name, state := range states {
+		next[name] = &algorithm.Preemption{Pods: maps.Clone(state.Pods), Preempted: preempted[name]}
+	}
+	deadline := func(mark algorithm.PreemptionMark) {
+		if action.PreemptionDeadline.IsZero() || mark.Deadline.Before(action.PreemptionDeadline) {
+			action.PreemptionDeadline = mark.Deadline
+		}
+	}
+
+	// Keep the marks whose preemptor still waits for pods, and release the pods whose deadline has passed.
+	for _, victim := range slices.Sorted(maps.Keys(states)) {
+		state := stateOf(victim)
+		for _, name := range slices.Sorted(maps.Keys(state.Pods)) {
+			mark := state.Pods[name]
+			preemptor, ok := sandboxes[mark.Preemptor]
+			if !ok || need[mark.Preemptor] <= 0 || !slices.Contains(held[victim], name) ||
+				allocationPriority(preemptor) <= allocationPriority(sandboxes[victim]) {
+				delete(state.Pods, name)
+				continue
+			}
+			need[mark.Preemptor]--
+			if now.Before(mark.Deadline) {
+				deadline(mark)
+				continue
+			}
+			delete(state.Pods, name)
+			state.Preempted[name] = mark.Preemptor
+			action.ToRelease[victim] = append(action.ToRelease[victim], name)
+			log.Info("Preempting pod", "pod", name, "sandbox", victim, "preemptor", mark.Preemptor)
+		}
+	}
+
+	// Mark pods for the preemptors that still wait, in queue order. They take the pods of the sandboxes of the
+	// lowest priority first, and of those the youngest.
+	type candidate struct {
+		sandbox *sandboxv1alpha1.BatchSandbox
+		pod     string
+	}
+	for _, req := range allRequest {
+		n := need[req.SandboxName]
+		if n <= 0 {
+			continue
+		}
+		preemptor := sandboxes[req.SandboxName]
+		var candidates []candidate
+		for _, victim := range spec.Sandboxes {
+			if allocationPriority(victim) >= allocationPriority(preemptor) || victim.Spec.Flavor != preemptor.Spec.Flavor {
+				continue
+			}
+			var marks map[string]algorithm.PreemptionMark
+			if state, ok := next[victim.Name]; ok {
+				marks = state.Pods
+			}
+			for _, name := range held[victim.Name] {
+				if _, marked := marks[name]; !marked {
+					candidates = append(candidates, candidate{sandbox: victim, pod: name})
+				}
+			}
+		}
+		// A gang is not helped by part of its pods.
+		if len(candidates) == 0 || req.Gang && int32(len(candidates)) < n {
+			continue
+		}
+		slices.SortFunc(candidates, func(a, b candidate) int {
+			if c := cmp.Compare(allocationPriority(a.sandbox), allocationPriority(b.sandbox)); c != 0 {
+				return c
+			}
+			if c := b.sandbox.CreationTimestamp.Compare(a.sandbox.CreationTimestamp.Time); c != 0 {
+				return c
+			}
+			if c := strings.Compare(a.sandbox.Name, b.sandbox.Name); c != 0 {
+				return c
+			}
+			return strings.Compare(a.pod, b.pod)
+		})
+		for _, c := range candidates[:min(int(n), len(candidates))] {
+			mark := algorithm.PreemptionMark{Preemptor: req.SandboxName, Deadline: now.Add(preemptionGracePeriod(c.sandbox))}
+			stateOf(c.sandbox.Name).Pods[c.pod] = mark
+			deadline(mark)
+		}
+	}
+
+	for name, state := range next {
+		previous := states[name]
+		if maps.Equal(previous.Pods, state.Pods) && maps.Equal(previous.Preempted, state.Preempted) {
+			continue
+		}
+		if action.Preemptions == nil {
+			action.Preemptions = make(map[string]*algorithm.Preemption)
+		}
+		action.Preemptions[name] = state
+	}
+}
+
+// syncPreemptions records the preemption states of the action on the sandboxes and reports their changes
+// through events. It runs before pods are released, so that a sandbox preempted of a pod is given a
+// replacement even if the release fails this round.
+func (r *PoolReconciler) syncPreemptions(ctx context.Context, batchSandboxes []*sandboxv1alpha1.BatchSandbox, preemptions map[string]*algorithm.Preemption) error {
+	sandboxByName := make(map[string]*sandboxv1alpha1.BatchSandbox, len(batchSandboxes))
+	for _, bs := range batchSandboxes {
+		sandboxByName[bs.Name] = bs
+	}
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(preemptions)) {
+		sandbox, ok := sandboxByName[name]
+		if !ok {
+			continue
+		}
+		state := preemptions[name]
+		previous, _ := parseSandboxPreemption(sandbox)
+		old := sandbox.DeepCopy()
+		setSandboxPreemption(sandbox, state)
+		if err := r.Patch(ctx, sandbox, client.MergeFrom(old)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record preemption of sandbox %s: %w", name, err))
+			continue
+		}
+
+		for _, pod := range slices.Sorted(maps.Keys(state.Pods)) {
+			mark := state.Pods[pod]
+			if _, ok := previous.Pods[pod]; ok {
+				continue
+			}
+			deadline := mark.Deadline.UTC().Format(time.RFC3339)
+			r.Recorder.Eventf(sandbox, corev1.EventTypeWarning, reasonPreempting,
+				"Pod %s will be released at %s for sandbox %s of higher priority", pod, deadline, mark.Preemptor)
+			if preemptor, ok := sandboxByName[mark.Preemptor]; ok {
+				r.Recorder.Eventf(preemptor, corev1.EventTypeNormal, reasonPreempting,
+					"Preempting pod %s of sandbox %s, released at %s", pod, name, deadline)
+			}
+		}
+		for _, pod := range slices.Sorted(maps.Keys(previous.Pods)) {
+			if _, ok := state.Pods[pod]; ok {
+				continue
+			}
+			if preemptor, ok := state.Preempted[pod]; ok {
+				r.Recorder.Eventf(sandbox, corev1.EventTypeWarning, reasonPreempted,
+					"Released pod %s for sandbox %s of higher priority", pod, preemptor)
+			} else {
+				r.Recorder.Eventf(sandbox, corev1.EventTypeNormal, reasonPreemptionCanceled,
+					"Pod %s is no longer preempted", pod)
+			}
+		}
+	}
+	return gerrors.Join(errs...)
+}
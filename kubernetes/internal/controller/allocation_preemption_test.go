@@ -0,0 +1,310 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+func preemptionSandbox(name string, priority int32, created int64, preempts bool) *sandboxv1alpha1.BatchSandbox {
+	policy := &sandboxv1alpha1.AllocationPolicy{Priority: ptr.To(priority)}
+	if preempts {
+		policy.PreemptionPolicy = sandboxv1alpha1.AllocationPreemptLowerPriority
+	}
+	return &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.Unix(created, 0)},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{AllocationPolicy: policy},
+	}
+}
+
+func preemptionPool(poolMax int32) *sandboxv1alpha1.Pool {
+	return &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{PoolMax: poolMax}}}
+}
+
+func emptyAllocAction() *algorithm.AllocAction {
+	return &algorithm.AllocAction{ToAllocate: make(map[string][]string), ToRelease: make(map[string][]string)}
+}
+
+func TestSchedulePreemption_MarksLowerPriority(t *testing.T) {
+	now := time.Unix(1000, 0)
+	old := preemptionSandbox("old", 0, 100, false)
+	young := preemptionSandbox("young", 0, 200, false)
+	young.Spec.AllocationPolicy.PreemptionGracePeriodSeconds = ptr.To(int32(5))
+	mid := preemptionSandbox("mid", 5, 50, false)
+	high := preemptionSandbox("high", 10, 300, true)
+	spec := &AllocSpec{
+		Pool:      preemptionPool(4),
+		Pods:      []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil), pluginTestPod("p3", "", nil), pluginTestPod("p4", "", nil)},
+		Sandboxes: []*sandboxv1alpha1.BatchSandbox{old, young, mid, high},
+	}
+	podAllocation := map[string]string{"p1": "old", "p2": "young", "p3": "young", "p4": "mid"}
+	allRequest := []*algorithm.SandboxRequest{
+		{SandboxName: "high", PodSupplement: 3},
+		{SandboxName: "mid", CurAllocation: []string{"p4"}},
+		{SandboxName: "old", CurAllocation: []string{"p1"}},
+		{SandboxName: "young", CurAllocation: []string{"p2", "p3"}},
+	}
+	action := emptyAllocAction()
+
+	schedulePreemption(context.Background(), spec, podAllocation, nil, allRequest, action, now)
+
+	assert.Empty(t, action.ToRelease, "pods are released at their deadline only")
+	require.Len(t, action.Preemptions, 2)
+	assert.Equal(t, map[string]algorithm.PreemptionMark{
+		"p2": {Preemptor: "high", Deadline: now.Add(5 * time.Second)},
+		"p3": {Preemptor: "high", Deadline: now.Add(5 * time.Second)},
+	}, action.Preemptions["young"].Pods, "the youngest sandbox of the lowest priority goes first")
+	assert.Equal(t, map[string]algorithm.PreemptionMark{
+		"p1": {Preemptor: "high", Deadline: now.Add(defaultPreemptionGracePeriod)},
+	}, action.Preemptions["old"].Pods)
+	assert.NotContains(t, action.Preemptions, "mid")
+	assert.Equal(t, now.Add(5*time.Second), action.PreemptionDeadline)
+}
+
+func TestSchedulePreemption_ReleasesAtDeadline(t *testing.T) {
+	now := time.Unix(1000, 0)
+	low := preemptionSandbox("low", 0, 100, false)
+	low.Annotations = map[string]string{AnnoPreemptionKey: utils.DumpJSON(algorithm.Preemption{Pods: map[string]algorithm.PreemptionMark{
+		"p1": {Preemptor: "high", Deadline: now.Add(-time.Second)},
+		"p2": {Preemptor: "high", Deadline: now.Add(time.Minute)},
+		"p3": {Preemptor: "gone", Deadline: now.Add(-time.Second)},
+	}})}
+	high := preemptionSandbox("high", 10, 300, true)
+	spec := &AllocSpec{
+		Pool:      preemptionPool(3),
+		Pods:      []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil), pluginTestPod("p3", "", nil)},
+		Sandboxes: []*sandboxv1alpha1.BatchSandbox{low, high},
+	}
+	podAllocation := map[string]string{"p1": "low", "p2": "low", "p3": "low"}
+	allRequest := []*algorithm.SandboxRequest{
+		{SandboxName: "high", PodSupplement: 2},
+		{SandboxName: "low", CurAllocation: []string{"p1", "p2", "p3"}},
+	}
+	action := emptyAllocAction()
+
+	schedulePreemption(context.Background(), spec, podAllocation, nil, allRequest, action, now)
+
+	assert.Equal(t, []string{"p1"}, action.ToRelease["low"])
+	require.Contains(t, action.Preemptions, "low")
+	assert.Equal(t, map[string]string{"p1": "high"}, action.Preemptions["low"].Preempted)
+	assert.Equal(t, []string{"p2"}, slices.Sorted(maps.Keys(action.Preemptions["low"].Pods)), "the mark of a sandbox that is gone is dropped")
+	assert.WithinDuration(t, now.Add(time.Minute), action.PreemptionDeadline, 0)
+
+	// Once p1 recycles it is on its way to high, which needs no other pod.
+	low.Annotations[AnnoPreemptionKey] = utils.DumpJSON(action.Preemptions["low"])
+	allRequest[1] = &algorithm.SandboxRequest{SandboxName: "low", CurAllocation: []string{"p1", "p2", "p3"}, CurReleased: []string{"p1"}}
+	action = emptyAllocAction()
+	schedulePreemption(context.Background(), spec, map[string]string{"p2": "low", "p3": "low"}, nil, allRequest, action, now)
+	assert.Empty(t, action.Preemptions)
+}
+
+func TestSchedulePreemption_Cancels(t *testing.T) {
+	now := time.Unix(1000, 0)
+	marked := func() *sandboxv1alpha1.BatchSandbox {
+		low := preemptionSandbox("low", 0, 100, false)
+		low.Annotations = map[string]string{AnnoPreemptionKey: utils.DumpJSON(algorithm.Preemption{Pods: map[string]algorithm.PreemptionMark{
+			"p1": {Preemptor: "high", Deadline: now.Add(time.Minute)},
+		}})}
+		return low
+	}
+	high := preemptionSandbox("high", 10, 300, true)
+	pods := []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil)}
+
+	// The pool has a free pod again, which high is allocated.
+	action := emptyAllocAction()
+	action.ToAllocate["high"] = []string{"p2"}
+	schedulePreemption(context.Background(), &AllocSpec{Pool: preemptionPool(2), Pods: pods, Sandboxes: []*sandboxv1alpha1.BatchSandbox{marked(), high}},
+		map[string]string{"p1": "low"}, []string{"p2"}, []*algorithm.SandboxRequest{
+			{SandboxName: "high", PodSupplement: 1},
+			{SandboxName: "low", CurAllocation: []string{"p1"}},
+		}, action, now)
+	require.Contains(t, action.Preemptions, "low")
+	assert.Empty(t, action.Preemptions["low"].Pods)
+	assert.Empty(t, action.ToRelease)
+	assert.True(t, action.PreemptionDeadline.IsZero())
+
+	// The pool may still grow.
+	action = emptyAllocAction()
+	schedulePreemption(context.Background(), &AllocSpec{Pool: preemptionPool(3), Pods: pods[:1], Sandboxes: []*sandboxv1alpha1.BatchSandbox{marked(), high}},
+		map[string]string{"p1": "low"}, nil, []*algorithm.SandboxRequest{
+			{SandboxName: "high", PodSupplement: 1},
+			{SandboxName: "low", CurAllocation: []string{"p1"}},
+		}, action, now)
+	require.Contains(t, action.Preemptions, "low")
+	assert.Empty(t, action.Preemptions["low"].Pods)
+}
+
+func TestSchedulePreemption_Skips(t *testing.T) {
+	now := time.Unix(1000, 0)
+	pods := []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil)}
+	podAllocation := map[string]string{"p1": "low", "p2": "equal"}
+	schedule := func(high *sandboxv1alpha1.BatchSandbox, req *algorithm.SandboxRequest) *algorithm.AllocAction {
+		action := emptyAllocAction()
+		schedulePreemption(context.Background(), &AllocSpec{Pool: preemptionPool(2), Pods: pods, Sandboxes: []*sandboxv1alpha1.BatchSandbox{
+			preemptionSandbox("low", 0, 100, false), preemptionSandbox("equal", 10, 100, false), high,
+		}}, podAllocation, nil, []*algorithm.SandboxRequest{
+			req,
+			{SandboxName: "low", CurAllocation: []string{"p1"}},
+			{SandboxName: "equal", CurAllocation: []string{"p2"}},
+		}, action, now)
+		return action
+	}
+
+	assert.Empty(t, schedule(preemptionSandbox("high", 10, 300, false), &algorithm.SandboxRequest{SandboxName: "high", PodSupplement: 1}).Preemptions,
+		"sandboxes preempt only with the PreemptLowerPriority policy")
+	assert.Empty(t, schedule(preemptionSandbox("high", 10, 300, true), &algorithm.SandboxRequest{SandboxName: "high", PodSupplement: 2, Gang: true}).Preemptions,
+		"a gang preempts all the pods it misses or none")
+	flavored := preemptionSandbox("high", 10, 300, true)
+	flavored.Spec.Flavor = "gpu"
+	assert.Empty(t, schedule(flavored, &algorithm.SandboxRequest{SandboxName: "high", PodSupplement: 1}).Preemptions,
+		"pods of other flavors are of no use")
+	action := schedule(preemptionSandbox("high", 10, 300, true), &algorithm.SandboxRequest{SandboxName: "high", PodSupplement: 2})
+	require.Len(t, action.Preemptions, 1)
+	assert.Equal(t, []string{"p1"}, slices.Sorted(maps.Keys(action.Preemptions["low"].Pods)), "sandboxes of equal priority are not preempted")
+}
+
+func TestSchedulePreemption_ForgetsRecycledPods(t *testing.T) {
+	now := time.Unix(1000, 0)
+	low := preemptionSandbox("low", 0, 100, false)
+	low.Spec.Replicas = ptr.To(int32(2))
+	low.Annotations = map[string]string{
+		AnnoAllocStatusKey:   utils.DumpJSON(SandboxAllocation{Pods: []string{"p1", "p2"}}),
+		AnnoAllocReleasedKey: utils.DumpJSON(AllocationReleased{Pods: []string{"p1"}}),
+		AnnoPreemptionKey:    utils.DumpJSON(algorithm.Preemption{Preempted: map[string]string{"p1": "high"}}),
+	}
+	other := preemptionSandbox("other", 0, 100, false)
+	high := preemptionSandbox("high", 10, 300, true)
+	spec := &AllocSpec{
+		Pool:      preemptionPool(3),
+		Pods:      []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil), pluginTestPod("p3", "", nil)},
+		Sandboxes: []*sandboxv1alpha1.BatchSandbox{low, other, high},
+	}
+	lowRequest := &algorithm.SandboxRequest{SandboxName: "low", CurAllocation: []string{"p1", "p2"}, CurReleased: []string{"p1"}}
+	schedule := func(podAllocation map[string]string, available []string, toAllocate map[string][]string) *algorithm.AllocAction {
+		action := emptyAllocAction()
+		maps.Copy(action.ToAllocate, toAllocate)
+		schedulePreemption(context.Background(), spec, podAllocation, available, []*algorithm.SandboxRequest{
+			lowRequest, {SandboxName: "other"}, {SandboxName: "high"},
+		}, action, now)
+		return action
+	}
+
+	// While p1 recycles it stays preempted.
+	assert.Empty(t, schedule(map[string]string{"p2": "low", "p3": "high"}, nil, nil).Preemptions)
+
+	// Once it has been recycled and is handed to another sandbox, it is forgotten.
+	action := schedule(map[string]string{"p2": "low", "p3": "high"}, []string{"p1"}, map[string][]string{"other": {"p1"}})
+	require.Contains(t, action.Preemptions, "low")
+	assert.Empty(t, action.Preemptions["low"].Preempted)
+	action = schedule(map[string]string{"p1": "other", "p2": "low", "p3": "high"}, nil, nil)
+	require.Contains(t, action.Preemptions, "low")
+	assert.Empty(t, action.Preemptions["low"].Preempted)
+
+	// So is a pod that is allocated to the victim again, which then keeps it.
+	action = schedule(map[string]string{"p2": "low", "p3": "high"}, []string{"p1"}, map[string][]string{"low": {"p1"}})
+	require.Contains(t, action.Preemptions, "low")
+	assert.Empty(t, action.Preemptions["low"].Preempted)
+
+	low.Annotations[AnnoPreemptionKey] = utils.DumpJSON(action.Preemptions["low"])
+	low.Annotations[AnnoAllocStatusKey] = utils.DumpJSON(SandboxAllocation{Pods: []string{"p2", "p1"}})
+	low.Annotations[AnnoAllocReleasedKey] = utils.DumpJSON(AllocationReleased{})
+	c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+	allocator := NewDefaultAllocator(c, record.NewFakeRecorder(10)).(*defaultAllocator)
+	req, err := allocator.getSandboxRequest(context.Background(), low)
+	require.NoError(t, err)
+	assert.Empty(t, req.ToRelease, "the pod given back is not released again")
+	assert.Equal(t, int32(0), req.PodSupplement)
+	kept, err := poolKeptPods(context.Background(), c, low)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), kept)
+}
+
+func TestGetSandboxRequest_ReplacesPreemptedPods(t *testing.T) {
+	sandbox := preemptionSandbox("low", 0, 100, false)
+	sandbox.Spec.Replicas = ptr.To(int32(3))
+	sandbox.Annotations = map[string]string{
+		AnnoAllocStatusKey:   utils.DumpJSON(SandboxAllocation{Pods: []string{"p1", "p2", "p3"}}),
+		AnnoAllocReleasedKey: utils.DumpJSON(AllocationReleased{Pods: []string{"p1"}}),
+		AnnoPreemptionKey:    utils.DumpJSON(algorithm.Preemption{Preempted: map[string]string{"p1": "high", "p2": "high"}}),
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+	allocator := NewDefaultAllocator(c, record.NewFakeRecorder(10)).(*defaultAllocator)
+
+	req, err := allocator.getSandboxRequest(context.Background(), sandbox)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), req.PodSupplement, "preempted pods are replaced")
+	assert.Equal(t, []string{"p2"}, req.ToRelease, "preempted pods not released yet are released")
+}
+
+func TestSyncPreemptions(t *testing.T) {
+	deadline := time.Date(2025, 1, 1, 0, 0, 30, 0, time.UTC)
+	low := preemptionSandbox("low", 0, 100, false)
+	low.Annotations = map[string]string{AnnoPreemptionKey: utils.DumpJSON(algorithm.Preemption{Pods: map[string]algorithm.PreemptionMark{
+		"p1": {Preemptor: "high", Deadline: deadline},
+		"p2": {Preemptor: "high", Deadline: deadline},
+	}})}
+	high := preemptionSandbox("high", 10, 300, true)
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(low, high).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Client: c, Recorder: recorder}
+
+	err := r.syncPreemptions(context.Background(), []*sandboxv1alpha1.BatchSandbox{low, high}, map[string]*algorithm.Preemption{
+		"low": {
+			Pods:      map[string]algorithm.PreemptionMark{"p3": {Preemptor: "high", Deadline: deadline}},
+			Preempted: map[string]string{"p1": "high"},
+		},
+	})
+	require.NoError(t, err)
+
+	stored := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(low), stored))
+	state, err := parseSandboxPreemption(stored)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"p1": "high"}, state.Preempted)
+	assert.Equal(t, []string{"p3"}, slices.Sorted(maps.Keys(state.Pods)))
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Equal(t, []string{
+		"Warning Preempting Pod p3 will be released at 2025-01-01T00:00:30Z for sandbox high of higher priority",
+		"Normal Preempting Preempting pod p3 of sandbox low, released at 2025-01-01T00:00:30Z",
+		"Warning Preempted Released pod p1 for sandbox high of higher priority",
+		"Normal PreemptionCanceled Pod p2 is no longer preempted",
+	}, events)
+
+	// An empty state removes the annotation.
+	require.NoError(t, r.syncPreemptions(context.Background(), []*sandboxv1alpha1.BatchSandbox{stored}, map[string]*algorithm.Preemption{"low": {}}))
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(low), stored))
+	assert.NotContains(t, stored.Annotations, AnnoPreemptionKey)
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+const reasonAllocationQueued = "AllocationQueued"
+
+// allocationQueueAging is how long a sandbox waits for its priority to be raised by one.
+var allocationQueueAging = time.Minute
+
+// allocationPriority returns allocationPolicy.priority of the sandbox, 0 when unset.
+func allocationPriority(sandbox *sandboxv1alpha1.BatchSandbox) int32 {
+	if policy := sandbox.Spec.AllocationPolicy; policy != nil && policy.Priority != nil {
+		return *policy.Priority
+	}
+	return 0
+}
+
+// queuePriority is the priority of the sandbox raised by the time it has waited since its creation.
+func queuePriority(sandbox *sandboxv1alpha1.BatchSandbox, now time.Time) int64 {
+	aged := int64(0)
+	if waited := now.Sub(sandbox.CreationTimestamp.Time); waited > 0 && allocationQueueAging > 0 {
+		aged = int64(waited / allocationQueueAging)
+	}
+	return int64(allocationPriority(sandbox)) + aged
+}
+
+// sortAllocationQueue orders the sandboxes in which they are served: by aged priority, then first come first
+// served, then by name. Without priorities this is creation order, since older sandboxes have aged more.
+func sortAllocationQueue(sandboxes []*sandboxv1alpha1.BatchSandbox, now time.Time) []*sandboxv1alpha1.BatchSandbox {
+	ordered := slices.Clone(sandboxes)
+	slices.SortStableFunc(ordered, func(a, b *sandboxv1alpha1.BatchSandbox) int {
+		if c := cmp.Compare(queuePriority(b, now), queuePriority(a, now)); c != 0 {
+			return c
+		}
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return ordered
+}
+
+// allocationQueue reports the position of the sandboxes still waiting for pods of each pool through
+// AllocationQueued events, one whenever the position of a sandbox changes.
+type allocationQueue struct {
+	mu        sync.Mutex
+	positions map[string]map[string]int // pool key -> sandbox -> 1-based position
+}
+
+func newAllocationQueue() *allocationQueue {
+	return &allocationQueue{positions: make(map[string]map[string]int)}
+}
+
+// report records the sandboxes whose wanted pods the action does not fully allocate, in the order of the
+// requests. wanted is the number of pods each sandbox asked for before quotas were applied.
+func (q *allocationQueue) report(recorder record.EventRecorder, pool *sandboxv1alpha1.Pool, sandboxes []*sandboxv1alpha1.BatchSandbox,
+	allRequest []*algorithm.SandboxRequest, wanted map[string]int32, action *algorithm.AllocAction) {
+	sandboxMap := make(map[string]*sandboxv1alpha1.BatchSandbox, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		sandboxMap[sandbox.Name] = sandbox
+	}
+	type waiter struct {
+		sandbox *sandboxv1alpha1.BatchSandbox
+		missing int32
+	}
+	var waiting []waiter
+	for _, req := range allRequest {
+		sandbox, ok := sandboxMap[req.SandboxName]
+		if !ok {
+			continue
+		}
+		if missing := wanted[req.SandboxName] - int32(len(action.ToAllocate[req.SandboxName])); missing > 0 {
+			waiting = append(waiting, waiter{sandbox: sandbox, missing: missing})
+		}
+	}
+
+	positions := make(map[string]int, len(waiting))
+	for i, w := range waiting {
+		positions[w.sandbox.Name] = i + 1
+	}
+	key := pool.Namespace + "/" + pool.Name
+	q.mu.Lock()
+	previous := q.positions[key]
+	if len(positions) == 0 {
+		delete(q.positions, key)
+	} else {
+		q.positions[key] = positions
+	}
+	q.mu.Unlock()
+
+	if recorder == nil {
+		return
+	}
+	for i, w := range waiting {
+		if previous[w.sandbox.Name] == i+1 {
+			continue
+		}
+		recorder.Eventf(w.sandbox, corev1.EventTypeNormal, reasonAllocationQueued,
+			"Waiting for %d pod(s) of pool %s at position %d of %d", w.missing, pool.Name, i+1, len(waiting))
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+func queuedSandbox(name string, created time.Time, priority *int32) *sandboxv1alpha1.BatchSandbox {
+	sandbox := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created)}}
+	if priority != nil {
+		sandbox.Spec.AllocationPolicy = &sandboxv1alpha1.AllocationPolicy{Priority: priority}
+	}
+	return sandbox
+}
+
+func TestSortAllocationQueue(t *testing.T) {
+	now := time.Now()
+	names := func(sandboxes []*sandboxv1alpha1.BatchSandbox) []string {
+		out := make([]string, 0, len(sandboxes))
+		for _, sandbox := range sandboxes {
+			out = append(out, sandbox.Name)
+		}
+		return out
+	}
+
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		queuedSandbox("newest", now, nil),
+		queuedSandbox("b-tie", now.Add(-time.Minute), nil),
+		queuedSandbox("a-tie", now.Add(-time.Minute), nil),
+		queuedSandbox("oldest", now.Add(-90*time.Second), nil),
+	}
+	assert.Equal(t, []string{"oldest", "a-tie", "b-tie", "newest"}, names(sortAllocationQueue(sandboxes, now)),
+		"without priorities sandboxes are served first come first served")
+
+	sandboxes = []*sandboxv1alpha1.BatchSandbox{
+		queuedSandbox("low-waiting", now.Add(-10*time.Minute), ptr.To[int32](0)),
+		queuedSandbox("high", now.Add(-time.Minute), ptr.To[int32](5)),
+		queuedSandbox("low", now.Add(-2*time.Minute), nil),
+	}
+	assert.Equal(t, []string{"low-waiting", "high", "low"}, names(sortAllocationQueue(sandboxes, now)),
+		"a sandbox waiting longer than the priority difference in minutes goes first")
+	assert.Equal(t, []string{"high", "low-waiting", "low"}, names(sortAllocationQueue(sandboxes, now.Add(-6*time.Minute+time.Second))))
+}
+
+func TestAllocationQueueReport(t *testing.T) {
+	now := time.Now()
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}}
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		queuedSandbox("first", now.Add(-time.Minute), nil),
+		queuedSandbox("second", now, nil),
+		queuedSandbox("served", now, nil),
+	}
+	requests := []*algorithm.SandboxRequest{
+		{SandboxName: "served", PodSupplement: 1},
+		{SandboxName: "first", PodSupplement: 1},
+		{SandboxName: "second", PodSupplement: 0},
+		{SandboxName: "orphan", ToRelease: []string{"pod-x"}},
+	}
+	wanted := map[string]int32{"served": 1, "first": 2, "second": 1}
+	action := &algorithm.AllocAction{ToAllocate: map[string][]string{"served": {"pod-1"}, "first": {"pod-2"}}}
+
+	recorder := record.NewFakeRecorder(10)
+	queue := newAllocationQueue()
+	queue.report(recorder, pool, sandboxes, requests, wanted, action)
+	require.Len(t, recorder.Events, 2)
+	assert.Equal(t, "Normal AllocationQueued Waiting for 1 pod(s) of pool pool at position 1 of 2", <-recorder.Events)
+	assert.Equal(t, "Normal AllocationQueued Waiting for 1 pod(s) of pool pool at position 2 of 2", <-recorder.Events,
+		"a sandbox held back by quota is still queued")
+
+	queue.report(recorder, pool, sandboxes, requests, wanted, action)
+	assert.Empty(t, recorder.Events, "unchanged positions are not reported again")
+
+	action.ToAllocate["first"] = []string{"pod-2", "pod-3"}
+	queue.report(recorder, pool, sandboxes, requests, wanted, action)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "at position 1 of 1")
+
+	wanted["second"] = 0
+	queue.report(recorder, pool, sandboxes, requests, wanted, action)
+	assert.Empty(t, queue.positions, "pools without waiting sandboxes are forgotten")
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+const reasonAllocationQuotaExceeded = "AllocationQuotaExceeded"
+
+// quotaThrottle is a request held back by the quota of its tenant.
+type quotaThrottle struct {
+	sandbox      string
+	tenant       string
+	maxAllocated int32
+	allocated    int32 // pods the tenant held before the request was granted
+	requested    int32
+	granted      int32
+}
+
+// tenantOf returns the tenant a BatchSandbox is accounted to.
+func tenantOf(quota *sandboxv1alpha1.AllocationQuota, sandbox *sandboxv1alpha1.BatchSandbox) string {
+	return sandbox.Labels[quota.TenantLabelKey]
+}
+
+// tenantChanged reports whether an update of the sandbox accounts it to another tenant of its pool's quota. If the
+// pool cannot be read, any change of the labels counts.
+func tenantChanged(ctx context.Context, c client.Reader, oldObj, newObj *sandboxv1alpha1.BatchSandbox) bool {
+	pool := &sandboxv1alpha1.Pool{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: newObj.Namespace, Name: newObj.Spec.PoolRef}, pool); err != nil {
+		return !errors.IsNotFound(err) && !maps.Equal(oldObj.Labels, newObj.Labels)
+	}
+	if pool.Spec.AllocationQuota == nil {
+		return false
+	}
+	return tenantOf(pool.Spec.AllocationQuota, oldObj) != tenantOf(pool.Spec.AllocationQuota, newObj)
+}
+
+// tenantLimit returns the quota of the tenant, or nil if the tenant is unlimited.
+func tenantLimit(quota *sandboxv1alpha1.AllocationQuota, tenant string) *int32 {
+	for i := range quota.Tenants {
+		if quota.Tenants[i].Name == tenant {
+			return &quota.Tenants[i].MaxAllocated
+		}
+	}
+	return quota.DefaultMaxAllocated
+}
+
+// countTenantAllocated counts allocated pods per tenant. Pods of sandboxes that no longer exist are not counted.
+func countTenantAllocated(quota *sandboxv1alpha1.AllocationQuota, sandboxes []*sandboxv1alpha1.BatchSandbox, podAllocation map[string]string) map[string]int32 {
+	sandboxTenant := make(map[string]string, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		sandboxTenant[sandbox.Name] = tenantOf(quota, sandbox)
+	}
+	allocated := make(map[string]int32)
+	for _, sandboxName := range podAllocation {
+		if tenant, ok := sandboxTenant[sandboxName]; ok {
+			allocated[tenant]++
+		}
+	}
+	return allocated
+}
+
+// applyAllocationQuota caps the pod supplement of each request so that no tenant exceeds its quota.
+// Requests are granted in sandbox creation order, so the excess of a tenant stays queued until
+// earlier sandboxes of the same tenant release pods. It returns the requests it held back.
+func applyAllocationQuota(ctx context.Context, pool *sandboxv1alpha1.Pool, sandboxes []*sandboxv1alpha1.BatchSandbox, podAllocation map[string]string, allRequest []*algorithm.SandboxRequest) []quotaThrottle {
+	quota := pool.Spec.AllocationQuota
+	if quota == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx)
+	sandboxByName := make(map[string]*sandboxv1alpha1.BatchSandbox, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		sandboxByName[sandbox.Name] = sandbox
+	}
+	used := countTenantAllocated(quota, sandboxes, podAllocation)
+
+	ordered := make([]*algorithm.SandboxRequest, 0, len(allRequest))
+	for _, req := range allRequest {
+		if req.PodSupplement > 0 && sandboxByName[req.SandboxName] != nil {
+			ordered = append(ordered, req)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ti := sandboxByName[ordered[i].SandboxName].CreationTimestamp
+		tj := sandboxByName[ordered[j].SandboxName].CreationTimestamp
+		return ti.Before(&tj)
+	})
+
+	var throttles []quotaThrottle
+	for _, req := range ordered {
+		tenant := tenantOf(quota, sandboxByName[req.SandboxName])
+		limit := tenantLimit(quota, tenant)
+		if limit == nil {
+			continue
+		}
+		grant := max(*limit-used[tenant], 0)
+		if req.Gang && grant < req.PodSupplement {
+			// A gang request is granted all of its pods or none.
+			grant = 0
+		}
+		if grant < req.PodSupplement {
+			log.Info("Allocation throttled by tenant quota", "pool", pool.Name, "sandbox", req.SandboxName,
+				"tenant", tenant, "maxAllocated", *limit, "allocated", used[tenant], "requested", req.PodSupplement, "granted", grant)
+			throttles = append(throttles, quotaThrottle{sandbox: req.SandboxName, tenant: tenant, maxAllocated: *limit,
+				allocated: used[tenant], requested: req.PodSupplement, granted: grant})
+			req.PodSupplement = grant
+		}
+		used[tenant] += req.PodSupplement
+	}
+	return throttles
+}
+
+// quotaEvents reports the sandboxes held back by the quotas of their tenants through AllocationQuotaExceeded
+// events, one when a sandbox is held back and one whenever the pods it is granted change.
+type quotaEvents struct {
+	mu        sync.Mutex
+	throttled map[string]map[string]int32 // pool key -> sandbox -> granted pods
+}
+
+func newQuotaEvents() *quotaEvents {
+	return &quotaEvents{throttled: make(map[string]map[string]int32)}
+}
+
+func (q *quotaEvents) report(recorder record.EventRecorder, pool *sandboxv1alpha1.Pool, sandboxes []*sandboxv1alpha1.BatchSandbox, throttles []quotaThrottle) {
+	throttled := make(map[string]int32, len(throttles))
+	for _, t := range throttles {
+		throttled[t.sandbox] = t.granted
+	}
+	key := pool.Namespace + "/" + pool.Name
+	q.mu.Lock()
+	previous := q.throttled[key]
+	if len(throttled) == 0 {
+		delete(q.throttled, key)
+	} else {
+		q.throttled[key] = throttled
+	}
+	q.mu.Unlock()
+
+	if recorder == nil || len(throttles) == 0 {
+		return
+	}
+	sandboxByName := make(map[string]*sandboxv1alpha1.BatchSandbox, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		sandboxByName[sandbox.Name] = sandbox
+	}
+	for _, t := range throttles {
+		if granted, ok := previous[t.sandbox]; ok && granted == t.granted {
+			continue
+		}
+		sandbox, ok := sandboxByName[t.sandbox]
+		if !ok {
+			continue
+		}
+		recorder.Eventf(sandbox, corev1.EventTypeWarning, reasonAllocationQuotaExceeded,
+			"Tenant %q holds %d of its %d pod(s) of pool %s: granted %d of %d requested pod(s), the rest waits for the tenant to release pods",
+			t.tenant, t.allocated, t.maxAllocated, pool.Name, t.granted, t.requested)
+	}
+}
+
+// calculateQuotaUsage reports per-tenant usage for PoolStatus. Pending counts the outstanding demand
+// that the tenant's quota does not leave room for.
+func calculateQuotaUsage(pool *sandboxv1alpha1.Pool, sandboxes []*sandboxv1alpha1.BatchSandbox, podAllocation map[string]string) []sandboxv1alpha1.TenantQuotaStatus {
+	quota := pool.Spec.AllocationQuota
+	if quota == nil {
+		return nil
+	}
+	allocated := countTenantAllocated(quota, sandboxes, podAllocation)
+	sandboxAllocated := make(map[string]int32)
+	for _, sandboxName := range podAllocation {
+		sandboxAllocated[sandboxName]++
+	}
+	demand := make(map[string]int32)
+	for _, sandbox := range sandboxes {
+		tenant := tenantOf(quota, sandbox)
+		if _, ok := allocated[tenant]; !ok {
+			allocated[tenant] = 0
+		}
+		if !sandbox.DeletionTimestamp.IsZero() || sandbox.Spec.Replicas == nil {
+			continue
+		}
+		demand[tenant] += max(*sandbox.Spec.Replicas-sandboxAllocated[sandbox.Name], 0)
+	}
+
+	usage := make([]sandboxv1alpha1.TenantQuotaStatus, 0, len(allocated))
+	for tenant, cnt := range allocated {
+		status := sandboxv1alpha1.TenantQuotaStatus{
+			Tenant:    tenant,
+			Allocated: cnt,
+		}
+		if limit := tenantLimit(quota, tenant); limit != nil {
+			maxAllocated := *limit
+			status.MaxAllocated = &maxAllocated
+			status.Pending = max(demand[tenant]-max(*limit-cnt, 0), 0)
+		}
+		usage = append(usage, status)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Tenant < usage[j].Tenant
+	})
+	return usage
+}
@@ -0,0 +1,238 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+const testTenantLabel = "tenant"
+
+func newQuotaPool(defaultMax *int32, tenants ...sandboxv1alpha1.TenantQuota) *sandboxv1alpha1.Pool {
+	return &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			AllocationQuota: &sandboxv1alpha1.AllocationQuota{
+				TenantLabelKey:      testTenantLabel,
+				DefaultMaxAllocated: defaultMax,
+				Tenants:             tenants,
+			},
+		},
+	}
+}
+
+func newQuotaSandbox(name, tenant string, replicas int32, created time.Time) *sandboxv1alpha1.BatchSandbox {
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To(replicas)},
+	}
+	if tenant != "" {
+		sbx.Labels = map[string]string{testTenantLabel: tenant}
+	}
+	return sbx
+}
+
+func TestApplyAllocationQuota(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name           string
+		pool           *sandboxv1alpha1.Pool
+		sandboxes      []*sandboxv1alpha1.BatchSandbox
+		podAllocation  map[string]string
+		requests       []*algorithm.SandboxRequest
+		wantSupplement map[string]int32
+	}{
+		{
+			name: "no quota leaves requests untouched",
+			pool: &sandboxv1alpha1.Pool{},
+			sandboxes: []*sandboxv1alpha1.BatchSandbox{
+				newQuotaSandbox("sbx1", "a", 5, now),
+			},
+			requests:       []*algorithm.SandboxRequest{{SandboxName: "sbx1", PodSupplement: 5}},
+			wantSupplement: map[string]int32{"sbx1": 5},
+		},
+		{
+			name: "default quota caps supplement with existing usage",
+			pool: newQuotaPool(ptr.To(int32(3))),
+			sandboxes: []*sandboxv1alpha1.BatchSandbox{
+				newQuotaSandbox("sbx1", "a", 4, now),
+			},
+			podAllocation:  map[string]string{"pod1": "sbx1", "pod2": "sbx1"},
+			requests:       []*algorithm.SandboxRequest{{SandboxName: "sbx1", PodSupplement: 2}},
+			wantSupplement: map[string]int32{"sbx1": 1},
+		},
+		{
+			name: "earlier sandboxes of a tenant are granted first",
+			pool: newQuotaPool(ptr.To(int32(2))),
+			sandboxes: []*sandboxv1alpha1.BatchSandbox{
+				newQuotaSandbox("late", "a", 2, now.Add(time.Minute)),
+				newQuotaSandbox("early", "a", 2, now),
+			},
+			requests: []*algorithm.SandboxRequest{
+				{SandboxName: "late", PodSupplement: 2},
+				{SandboxName: "early", PodSupplement: 2},
+			},
+			wantSupplement: map[string]int32{"late": 0, "early": 2},
+		},
+		{
+			name: "tenant override and unlimited tenants",
+			pool: newQuotaPool(nil, sandboxv1alpha1.TenantQuota{Name: "a", MaxAllocated: 1}),
+			sandboxes: []*sandboxv1alpha1.BatchSandbox{
+				newQuotaSandbox("sbx-a", "a", 3, now),
+				newQuotaSandbox("sbx-b", "b", 3, now),
+			},
+			requests: []*algorithm.SandboxRequest{
+				{SandboxName: "sbx-a", PodSupplement: 3},
+				{SandboxName: "sbx-b", PodSupplement: 3},
+			},
+			wantSupplement: map[string]int32{"sbx-a": 1, "sbx-b": 3},
+		},
+		{
+			name: "unlabelled sandboxes share the empty tenant",
+			pool: newQuotaPool(ptr.To(int32(1))),
+			sandboxes: []*sandboxv1alpha1.BatchSandbox{
+				newQuotaSandbox("sbx1", "", 1, now),
+				newQuotaSandbox("sbx2", "", 1, now.Add(time.Second)),
+			},
+			requests: []*algorithm.SandboxRequest{
+				{SandboxName: "sbx1", PodSupplement: 1},
+				{SandboxName: "sbx2", PodSupplement: 1},
+			},
+			wantSupplement: map[string]int32{"sbx1": 1, "sbx2": 0},
+		},
+		{
+			name: "gang requests are granted all or nothing",
+			pool: newQuotaPool(ptr.To(int32(3))),
+			sandboxes: []*sandboxv1alpha1.BatchSandbox{
+				newQuotaSandbox("gang", "a", 4, now),
+				newQuotaSandbox("plain", "a", 2, now.Add(time.Second)),
+			},
+			requests: []*algorithm.SandboxRequest{
+				{SandboxName: "gang", PodSupplement: 4, Gang: true},
+				{SandboxName: "plain", PodSupplement: 2},
+			},
+			wantSupplement: map[string]int32{"gang": 0, "plain": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyAllocationQuota(context.Background(), tt.pool, tt.sandboxes, tt.podAllocation, tt.requests)
+			got := make(map[string]int32, len(tt.requests))
+			for _, req := range tt.requests {
+				got[req.SandboxName] = req.PodSupplement
+			}
+			assert.Equal(t, tt.wantSupplement, got)
+		})
+	}
+}
+
+func TestQuotaEvents(t *testing.T) {
+	now := time.Now()
+	pool := newQuotaPool(ptr.To(int32(2)))
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		newQuotaSandbox("early", "a", 1, now),
+		newQuotaSandbox("late", "a", 3, now.Add(time.Second)),
+	}
+	schedule := func(podAllocation map[string]string, supplement int32) []quotaThrottle {
+		return applyAllocationQuota(context.Background(), pool, sandboxes, podAllocation, []*algorithm.SandboxRequest{
+			{SandboxName: "late", PodSupplement: supplement},
+		})
+	}
+	recorder := record.NewFakeRecorder(10)
+	events := newQuotaEvents()
+
+	throttles := schedule(map[string]string{"pod1": "early"}, 3)
+	assert.Equal(t, []quotaThrottle{{sandbox: "late", tenant: "a", maxAllocated: 2, allocated: 1, requested: 3, granted: 1}}, throttles)
+	events.report(recorder, pool, sandboxes, throttles)
+	assert.Equal(t, `Warning AllocationQuotaExceeded Tenant "a" holds 1 of its 2 pod(s) of pool pool: granted 1 of 3 requested pod(s), the rest waits for the tenant to release pods`,
+		<-recorder.Events)
+
+	events.report(recorder, pool, sandboxes, schedule(map[string]string{"pod1": "early"}, 3))
+	assert.Empty(t, recorder.Events, "an unchanged grant is not reported again")
+
+	events.report(recorder, pool, sandboxes, schedule(map[string]string{"pod1": "early", "pod2": "late"}, 2))
+	assert.Contains(t, <-recorder.Events, "granted 0 of 2")
+
+	events.report(recorder, pool, sandboxes, nil)
+	events.report(recorder, pool, sandboxes, schedule(map[string]string{"pod1": "early", "pod2": "late"}, 2))
+	assert.Contains(t, <-recorder.Events, "granted 0 of 2", "a sandbox held back again is reported again")
+}
+
+func TestCalculateQuotaUsage(t *testing.T) {
+	now := time.Now()
+	pool := newQuotaPool(ptr.To(int32(2)), sandboxv1alpha1.TenantQuota{Name: "b", MaxAllocated: 5})
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		newQuotaSandbox("sbx-a", "a", 4, now),
+		newQuotaSandbox("sbx-b", "b", 1, now),
+	}
+	podAllocation := map[string]string{"pod1": "sbx-a", "pod2": "sbx-a", "pod3": "orphan"}
+
+	got := calculateQuotaUsage(pool, sandboxes, podAllocation)
+	assert.Equal(t, []sandboxv1alpha1.TenantQuotaStatus{
+		{Tenant: "a", Allocated: 2, Pending: 2, MaxAllocated: ptr.To(int32(2))},
+		{Tenant: "b", Allocated: 0, Pending: 0, MaxAllocated: ptr.To(int32(5))},
+	}, got)
+
+	assert.Nil(t, calculateQuotaUsage(&sandboxv1alpha1.Pool{}, sandboxes, podAllocation))
+}
+
+func TestTenantChanged(t *testing.T) {
+	pool := newQuotaPool(nil)
+	unlimited := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "unlimited", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool, unlimited).Build()
+	sandbox := func(poolRef, tenant, other string) *sandboxv1alpha1.BatchSandbox {
+		sbx := newQuotaSandbox("sbx", tenant, 1, time.Now())
+		sbx.Spec.PoolRef = poolRef
+		if other != "" {
+			if sbx.Labels == nil {
+				sbx.Labels = map[string]string{}
+			}
+			sbx.Labels["other"] = other
+		}
+		return sbx
+	}
+
+	tests := []struct {
+		name     string
+		old, new *sandboxv1alpha1.BatchSandbox
+		want     bool
+	}{
+		{name: "tenant changed", old: sandbox("pool", "a", ""), new: sandbox("pool", "b", ""), want: true},
+		{name: "tenant set", old: sandbox("pool", "", ""), new: sandbox("pool", "a", ""), want: true},
+		{name: "other label changed", old: sandbox("pool", "a", "x"), new: sandbox("pool", "a", "y"), want: false},
+		{name: "pool without quota", old: sandbox("unlimited", "a", ""), new: sandbox("unlimited", "b", ""), want: false},
+		{name: "pool not found", old: sandbox("missing", "a", ""), new: sandbox("missing", "b", ""), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tenantChanged(context.Background(), c, tt.old, tt.new))
+		})
+	}
+}
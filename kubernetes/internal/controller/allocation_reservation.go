@@ -0,0 +1,306 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+// reservedAllocation is what the reservations of a pool contribute to a scheduling round.
+type reservedAllocation struct {
+	// toAllocate are the reserved pods allocated to the sandboxes referencing their reservation.
+	toAllocate map[string][]string
+	// from is the reservation each sandbox of toAllocate takes its pods from.
+	from map[string]string
+	// gang are the gang requests that take only part of their pods from a reservation. They keep their
+	// reserved pods only if the algorithm supplies the rest.
+	gang map[string]*algorithm.SandboxRequest
+	// states are the new states of the reservations, with the consumption of this round.
+	states map[string]*algorithm.Reservation
+	// expiry is the earliest expiry of the reservations holding pods, zero if none.
+	expiry time.Time
+}
+
+// reservationActive reports whether the reservation still holds pods at now.
+func reservationActive(reservation *sandboxv1alpha1.Reservation, now time.Time) bool {
+	if expire := reservation.Spec.ExpireTime; expire != nil && !now.Before(expire.Time) {
+		return false
+	}
+	return reservation.Status.Consumed < reservation.Spec.Count && reservation.DeletionTimestamp.IsZero()
+}
+
+// scheduleReservations holds idle pods of the pool for its reservations and allocates them to the sandboxes
+// referencing the reservations. It returns the available pods left for the other sandboxes and the requests
+// the algorithm still has to serve, with the reserved pods taken off their supplements. The requests passed
+// in are not changed.
+//
+// Reservations hold the pods they held before while those stay available, and top up from the available
+// pods of the pool template, preferring the latest revision. Sandboxes take reserved pods in request order,
+// if the pods are of their flavor and revision policy; sandboxes with allocation constraints take none.
+func scheduleReservations(spec *AllocSpec, availablePods []string, allRequest []*algorithm.SandboxRequest,
+	now time.Time) ([]string, []*algorithm.SandboxRequest, *reservedAllocation) {
+	reserved := &reservedAllocation{
+		toAllocate: make(map[string][]string),
+		from:       make(map[string]string),
+		gang:       make(map[string]*algorithm.SandboxRequest),
+		states:     make(map[string]*algorithm.Reservation),
+	}
+	if len(spec.Reservations) == 0 {
+		return availablePods, allRequest, reserved
+	}
+	pods := make(map[string]*corev1.Pod, len(spec.Pods))
+	for _, pod := range spec.Pods {
+		pods[pod.Name] = pod
+	}
+	// Only pods of the pool template are held, so that a reservation is not left with pods of a flavor
+	// no referencing sandbox asks for.
+	remaining := slices.Clone(availablePods)
+	holdable := func(name string) bool {
+		pod, ok := pods[name]
+		return ok && pod.Labels[LabelPoolFlavor] == ""
+	}
+	take := func(name string) bool {
+		i := slices.Index(remaining, name)
+		if i < 0 {
+			return false
+		}
+		remaining = slices.Delete(remaining, i, i+1)
+		return true
+	}
+
+	reservations := slices.Clone(spec.Reservations)
+	slices.SortStableFunc(reservations, func(a, b *sandboxv1alpha1.Reservation) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	active := make(map[string]*algorithm.Reservation)
+	need := make(map[string]int32)
+	for _, reservation := range reservations {
+		state := &algorithm.Reservation{Consumed: reservation.Status.Consumed}
+		reserved.states[reservation.Name] = state
+		if !reservationActive(reservation, now) {
+			state.Expired = reservation.Status.Consumed < reservation.Spec.Count
+			continue
+		}
+		active[reservation.Name] = state
+		need[reservation.Name] = reservation.Spec.Count - reservation.Status.Consumed
+		if expire := reservation.Spec.ExpireTime; expire != nil && (reserved.expiry.IsZero() || expire.Time.Before(reserved.expiry)) {
+			reserved.expiry = expire.Time
+		}
+		for _, name := range reservation.Status.Pods {
+			if int32(len(state.Pods)) < need[reservation.Name] && holdable(name) && take(name) {
+				state.Pods = append(state.Pods, name)
+			}
+		}
+	}
+	// Top up in a second pass, so that no reservation takes the pods another one already holds.
+	for _, reservation := range reservations {
+		state, ok := active[reservation.Name]
+		if !ok {
+			continue
+		}
+		missing := int(need[reservation.Name]) - len(state.Pods)
+		if missing <= 0 {
+			continue
+		}
+		var latest, others []string
+		for _, name := range remaining {
+			if !holdable(name) {
+				continue
+			}
+			if spec.Revision == "" || pods[name].Labels[LabelPoolRevision] == spec.Revision {
+				latest = append(latest, name)
+			} else {
+				others = append(others, name)
+			}
+		}
+		candidates := append(latest, others...)
+		for _, name := range candidates[:min(missing, len(candidates))] {
+			take(name)
+			state.Pods = append(state.Pods, name)
+		}
+	}
+
+	sandboxes := make(map[string]*sandboxv1alpha1.BatchSandbox, len(spec.Sandboxes))
+	for _, sandbox := range spec.Sandboxes {
+		sandboxes[sandbox.Name] = sandbox
+	}
+	eligible := func(sandbox *sandboxv1alpha1.BatchSandbox, name string) bool {
+		pod := pods[name]
+		if pod.Labels[LabelPoolFlavor] != sandbox.Spec.Flavor {
+			return false
+		}
+		return !requiresLatestRevision(sandbox) || spec.Revision == "" || pod.Labels[LabelPoolRevision] == spec.Revision
+	}
+	// waiting is what the sandboxes referencing a reservation still wait for after taking its pods. The
+	// reservation gets no pods for them: they are supplied to the sandboxes themselves.
+	waiting := make(map[string]int32)
+	taken := make(map[string]int32)
+	requests := make([]*algorithm.SandboxRequest, len(allRequest))
+	for i, req := range allRequest {
+		requests[i] = req
+		sandbox, ok := sandboxes[req.SandboxName]
+		if !ok || req.PodSupplement <= 0 || sandbox.Spec.AllocationConstraints != nil {
+			continue
+		}
+		state, ok := active[sandbox.Spec.ReservationRef]
+		if !ok {
+			continue
+		}
+		var picked []string
+		for _, name := range state.Pods {
+			if int32(len(picked)) < req.PodSupplement && eligible(sandbox, name) {
+				picked = append(picked, name)
+			}
+		}
+		waiting[sandbox.Spec.ReservationRef] += req.PodSupplement - int32(len(picked))
+		if len(picked) == 0 {
+			continue
+		}
+		state.Pods = slices.DeleteFunc(state.Pods, func(name string) bool { return slices.Contains(picked, name) })
+		state.Consumed += int32(len(picked))
+		taken[sandbox.Spec.ReservationRef] += int32(len(picked))
+		reserved.toAllocate[req.SandboxName] = picked
+		reserved.from[req.SandboxName] = sandbox.Spec.ReservationRef
+		rest := *req
+		rest.PodSupplement -= int32(len(picked))
+		requests[i] = &rest
+		if rest.Gang && rest.PodSupplement > 0 {
+			reserved.gang[req.SandboxName] = &rest
+		}
+	}
+
+	for name, state := range active {
+		// Pods handed back by gang sandboxes in apply move from Consumed to Pods, which leaves the
+		// shortfall as is.
+		state.Shortfall = max(need[name]-taken[name]-int32(len(state.Pods))-waiting[name], 0)
+	}
+	return remaining, requests, reserved
+}
+
+// apply adds the reserved pods to the allocations of the action, along with the pods the reservations
+// still need. Gang sandboxes that the action leaves short of pods hand their reserved pods back.
+func (reserved *reservedAllocation) apply(pool *sandboxv1alpha1.Pool, action *algorithm.AllocAction) {
+	for _, name := range slices.Sorted(maps.Keys(reserved.toAllocate)) {
+		pods := reserved.toAllocate[name]
+		if req, ok := reserved.gang[name]; ok && int32(len(action.ToAllocate[name])) < req.PodSupplement {
+			// The reservation keeps the pods for the next round.
+			state := reserved.states[reserved.from[name]]
+			state.Pods = append(state.Pods, pods...)
+			state.Consumed -= int32(len(pods))
+			continue
+		}
+		action.ToAllocate[name] = append(slices.Clone(pods), action.ToAllocate[name]...)
+	}
+	if !reserved.expiry.IsZero() {
+		action.ReservationExpiry = reserved.expiry
+	}
+	action.Reservations = reserved.states
+	var shortfall int32
+	for _, state := range reserved.states {
+		shortfall += state.Shortfall
+	}
+	if shortfall == 0 {
+		return
+	}
+	action.PodSupplement += shortfall
+	if len(pool.Spec.Flavors) > 0 {
+		if action.FlavorSupplement == nil {
+			action.FlavorSupplement = make(map[string]int32)
+		}
+		action.FlavorSupplement[""] += shortfall
+	}
+}
+
+// listReservations returns the reservations of the pool, leaving out those being deleted.
+func (r *PoolReconciler) listReservations(ctx context.Context, pool *sandboxv1alpha1.Pool) ([]*sandboxv1alpha1.Reservation, error) {
+	list := &sandboxv1alpha1.ReservationList{}
+	if err := r.List(ctx, list, client.InNamespace(pool.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+	var reservations []*sandboxv1alpha1.Reservation
+	for i := range list.Items {
+		reservation := &list.Items[i]
+		if reservation.Spec.PoolRef == pool.Name && reservation.DeletionTimestamp.IsZero() {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+// reservationStatus returns the status of the reservation in the state.
+func reservationStatus(reservation *sandboxv1alpha1.Reservation, state *algorithm.Reservation) sandboxv1alpha1.ReservationStatus {
+	status := sandboxv1alpha1.ReservationStatus{
+		ObservedGeneration: reservation.Generation,
+		Pods:               state.Pods,
+		Reserved:           int32(len(state.Pods)),
+		Consumed:           state.Consumed,
+	}
+	switch {
+	case state.Consumed >= reservation.Spec.Count:
+		status.Phase = sandboxv1alpha1.ReservationPhaseConsumed
+	case state.Expired:
+		status.Phase = sandboxv1alpha1.ReservationPhaseExpired
+	case status.Reserved+state.Consumed >= reservation.Spec.Count:
+		status.Phase = sandboxv1alpha1.ReservationPhaseReady
+	default:
+		status.Phase = sandboxv1alpha1.ReservationPhasePending
+	}
+	return status
+}
+
+// syncReservations records the new states of the reservations once their pods are allocated. A reservation
+// whose status cannot be recorded holds the pods it held before until the next round.
+func (r *PoolReconciler) syncReservations(ctx context.Context, reservations []*sandboxv1alpha1.Reservation,
+	states map[string]*algorithm.Reservation) error {
+	log := logf.FromContext(ctx)
+	var errs []error
+	for _, reservation := range reservations {
+		state, ok := states[reservation.Name]
+		if !ok {
+			continue
+		}
+		status := reservationStatus(reservation, state)
+		if equality.Semantic.DeepEqual(reservation.Status, status) {
+			continue
+		}
+		old := reservation.DeepCopy()
+		reservation.Status = status
+		if err := r.Status().Patch(ctx, reservation, client.MergeFrom(old)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update status of reservation %s: %w", reservation.Name, err))
+			continue
+		}
+		if old.Status.Phase != status.Phase {
+			log.Info("Reservation phase changed", "reservation", reservation.Name, "phase", status.Phase)
+		}
+	}
+	return gerrors.Join(errs...)
+}
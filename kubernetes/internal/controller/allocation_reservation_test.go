@@ -0,0 +1,170 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+func testReservation(name string, count int32, created int64, pods ...string) *sandboxv1alpha1.Reservation {
+	return &sandboxv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.Unix(created, 0)},
+		Spec:       sandboxv1alpha1.ReservationSpec{PoolRef: "pool", Count: count},
+		Status:     sandboxv1alpha1.ReservationStatus{Pods: pods, Reserved: int32(len(pods))},
+	}
+}
+
+func reservingSandbox(name, reservation string) *sandboxv1alpha1.BatchSandbox {
+	return &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool", ReservationRef: reservation},
+	}
+}
+
+// scheduleWithReservations runs a scheduling round the way the allocator does.
+func scheduleWithReservations(spec *AllocSpec, available []string, requests []*algorithm.SandboxRequest, now time.Time) *algorithm.AllocAction {
+	available, scheduled, reserved := scheduleReservations(spec, available, requests, now)
+	action := scheduleRevisions(context.Background(), &algorithm.PackedSchedule{}, spec, available, scheduled)
+	reserved.apply(spec.Pool, action)
+	return action
+}
+
+func TestScheduleReservations(t *testing.T) {
+	now := time.Unix(1000, 0)
+	pods := []*corev1.Pod{
+		pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil), pluginTestPod("p3", "", nil),
+		pluginTestPod("p4", "", nil), pluginTestPod("p5", "", nil),
+	}
+	first := testReservation("first", 2, 100, "p4")
+	second := testReservation("second", 2, 200)
+	expired := testReservation("expired", 3, 50, "p5")
+	expired.Spec.ExpireTime = &metav1.Time{Time: now.Add(-time.Second)}
+	spec := &AllocSpec{
+		Pool:         &sandboxv1alpha1.Pool{},
+		Pods:         pods,
+		Sandboxes:    []*sandboxv1alpha1.BatchSandbox{reservingSandbox("other", ""), reservingSandbox("late", "expired")},
+		Reservations: []*sandboxv1alpha1.Reservation{second, expired, first},
+	}
+
+	action := scheduleWithReservations(spec, []string{"p1", "p2", "p3", "p4", "p5"}, []*algorithm.SandboxRequest{
+		{SandboxName: "other", PodSupplement: 2},
+		{SandboxName: "late", PodSupplement: 1},
+	}, now)
+
+	assert.Equal(t, []string{"p4", "p1"}, action.Reservations["first"].Pods, "a reservation keeps its pods and tops up")
+	assert.Equal(t, []string{"p2", "p3"}, action.Reservations["second"].Pods)
+	assert.Empty(t, action.Reservations["expired"].Pods)
+	assert.True(t, action.Reservations["expired"].Expired)
+	assert.Equal(t, []string{"p5"}, action.ToAllocate["other"], "reserved pods are kept from other sandboxes")
+	assert.Empty(t, action.ToAllocate["late"], "the sandboxes of an expired reservation wait like any other")
+	assert.Equal(t, int32(2), action.PodSupplement)
+}
+
+func TestScheduleReservations_Consume(t *testing.T) {
+	now := time.Unix(1000, 0)
+	pods := []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil), pluginTestPod("p3", "", nil)}
+	reservation := testReservation("ci", 4, 100, "p1", "p2")
+	reservation.Spec.ExpireTime = &metav1.Time{Time: now.Add(time.Minute)}
+	spec := &AllocSpec{
+		Pool:         &sandboxv1alpha1.Pool{},
+		Pods:         pods,
+		Sandboxes:    []*sandboxv1alpha1.BatchSandbox{reservingSandbox("job", "ci")},
+		Reservations: []*sandboxv1alpha1.Reservation{reservation},
+	}
+	requests := []*algorithm.SandboxRequest{{SandboxName: "job", PodSupplement: 3}}
+
+	action := scheduleWithReservations(spec, []string{"p1", "p2", "p3"}, requests, now)
+
+	assert.Equal(t, []string{"p1", "p2", "p3"}, action.ToAllocate["job"], "reserved pods go first")
+	assert.Equal(t, int32(3), requests[0].PodSupplement, "the requests passed in are not changed")
+	state := action.Reservations["ci"]
+	assert.Empty(t, state.Pods)
+	assert.Equal(t, int32(3), state.Consumed)
+	assert.Equal(t, int32(1), state.Shortfall, "a new pod is requested for the rest of the reservation")
+	assert.Equal(t, int32(1), action.PodSupplement)
+	assert.Equal(t, now.Add(time.Minute), action.ReservationExpiry)
+
+	// The consumed reservation holds nothing.
+	reservation.Status.Consumed = 4
+	action = scheduleWithReservations(spec, []string{"p1"}, []*algorithm.SandboxRequest{{SandboxName: "job", PodSupplement: 1}}, now)
+	assert.Equal(t, []string{"p1"}, action.ToAllocate["job"])
+	assert.Empty(t, action.Reservations["ci"].Pods)
+	assert.Zero(t, action.PodSupplement)
+	assert.True(t, action.ReservationExpiry.IsZero())
+}
+
+func TestScheduleReservations_Gang(t *testing.T) {
+	now := time.Unix(1000, 0)
+	gang := reservingSandbox("gang", "ci")
+	spec := &AllocSpec{
+		Pool:         &sandboxv1alpha1.Pool{},
+		Pods:         []*corev1.Pod{pluginTestPod("p1", "", nil), pluginTestPod("p2", "", nil)},
+		Sandboxes:    []*sandboxv1alpha1.BatchSandbox{gang},
+		Reservations: []*sandboxv1alpha1.Reservation{testReservation("ci", 1, 100, "p1")},
+	}
+
+	action := scheduleWithReservations(spec, []string{"p1"}, []*algorithm.SandboxRequest{{SandboxName: "gang", PodSupplement: 2, Gang: true}}, now)
+	assert.Empty(t, action.ToAllocate["gang"], "a gang takes no reserved pods without the rest")
+	assert.Equal(t, []string{"p1"}, action.Reservations["ci"].Pods)
+	assert.Zero(t, action.Reservations["ci"].Consumed)
+	assert.Equal(t, int32(1), action.PodSupplement)
+
+	action = scheduleWithReservations(spec, []string{"p1", "p2"}, []*algorithm.SandboxRequest{{SandboxName: "gang", PodSupplement: 2, Gang: true}}, now)
+	assert.Equal(t, []string{"p1", "p2"}, action.ToAllocate["gang"])
+	assert.Equal(t, int32(1), action.Reservations["ci"].Consumed)
+}
+
+func TestSyncReservations(t *testing.T) {
+	ready := testReservation("ready", 2, 100)
+	consumed := testReservation("consumed", 1, 100)
+	unchanged := testReservation("unchanged", 1, 100, "p4")
+	unchanged.Status.Phase = sandboxv1alpha1.ReservationPhaseReady
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(ready, consumed, unchanged).
+		WithStatusSubresource(&sandboxv1alpha1.Reservation{}).Build()
+	r := &PoolReconciler{Client: c}
+
+	err := r.syncReservations(context.Background(), []*sandboxv1alpha1.Reservation{ready, consumed, unchanged}, map[string]*algorithm.Reservation{
+		"ready":     {Pods: []string{"p1", "p2"}},
+		"consumed":  {Consumed: 1},
+		"unchanged": {Pods: []string{"p4"}},
+	})
+	require.NoError(t, err)
+
+	stored := &sandboxv1alpha1.Reservation{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(ready), stored))
+	assert.Equal(t, sandboxv1alpha1.ReservationStatus{
+		Phase:    sandboxv1alpha1.ReservationPhaseReady,
+		Pods:     []string{"p1", "p2"},
+		Reserved: 2,
+	}, stored.Status)
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(consumed), stored))
+	assert.Equal(t, sandboxv1alpha1.ReservationPhaseConsumed, stored.Status.Phase)
+	assert.Equal(t, int32(1), stored.Status.Consumed)
+
+	assert.Equal(t, sandboxv1alpha1.ReservationPhasePending, reservationStatus(ready, &algorithm.Reservation{Pods: []string{"p1"}}).Phase)
+	assert.Equal(t, sandboxv1alpha1.ReservationPhaseExpired, reservationStatus(ready, &algorithm.Reservation{Expired: true}).Phase)
+}
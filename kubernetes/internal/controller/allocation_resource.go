@@ -0,0 +1,237 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// Values of the --allocation-store flag of the manager.
+const (
+	// AllocationStoreAnnotation keeps allocations in the alloc-status and alloc-released annotations
+	// (NewDefaultAllocator).
+	AllocationStoreAnnotation = "annotation"
+	// AllocationStoreResource keeps allocations in Allocation objects (NewResourceAllocator).
+	AllocationStoreResource = "resource"
+)
+
+// usesAllocationResource reports whether the allocation of the sandbox has moved to its Allocation.
+func usesAllocationResource(sbx metav1.Object) bool {
+	return sbx.GetAnnotations()[AnnoAllocStoreKey] == sandboxv1alpha1.AllocStoreResource
+}
+
+// getAllocationResource returns the Allocation of the sandbox, or an unsaved empty one if there is none.
+func getAllocationResource(ctx context.Context, c client.Reader, sbx *sandboxv1alpha1.BatchSandbox) (*sandboxv1alpha1.Allocation, error) {
+	alloc := &sandboxv1alpha1.Allocation{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(sbx), alloc); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get allocation of sandbox %s: %w", sbx.Name, err)
+		}
+		alloc = &sandboxv1alpha1.Allocation{ObjectMeta: metav1.ObjectMeta{Namespace: sbx.Namespace, Name: sbx.Name}}
+	}
+	return alloc, nil
+}
+
+// sandboxAllocation returns the pods allocated to a pooled sandbox: from its Allocation once the sandbox
+// has been migrated, from the alloc-status annotation otherwise.
+func sandboxAllocation(ctx context.Context, c client.Reader, sbx *sandboxv1alpha1.BatchSandbox) (SandboxAllocation, error) {
+	if !usesAllocationResource(sbx) {
+		return parseSandboxAllocation(sbx)
+	}
+	alloc, err := getAllocationResource(ctx, c, sbx)
+	if err != nil {
+		return SandboxAllocation{}, err
+	}
+	return SandboxAllocation{Pods: alloc.Spec.Pods}, nil
+}
+
+// sandboxAllocationReleased is sandboxAllocation for the allocated pods the pool has reclaimed
+// (alloc-released).
+func sandboxAllocationReleased(ctx context.Context, c client.Reader, sbx *sandboxv1alpha1.BatchSandbox) (AllocationReleased, error) {
+	if !usesAllocationResource(sbx) {
+		pods, err := allocationDecodes.decodePods(sbx, AnnoAllocReleasedKey)
+		return AllocationReleased{Pods: pods}, err
+	}
+	alloc, err := getAllocationResource(ctx, c, sbx)
+	if err != nil {
+		return AllocationReleased{}, err
+	}
+	return AllocationReleased{Pods: alloc.Spec.Released}, nil
+}
+
+// updateAllocationResource applies mutate to the Allocation of the sandbox and saves it, creating it owned
+// by the sandbox if needed. A sandbox that has not been migrated yet starts from its annotations. Only the
+// changed fields are patched, like the annotations, so concurrent reconciles don't conflict.
+func updateAllocationResource(ctx context.Context, c client.Client, sbx *sandboxv1alpha1.BatchSandbox,
+	mutate func(spec *sandboxv1alpha1.AllocationSpec)) (*sandboxv1alpha1.Allocation, error) {
+	alloc, err := getAllocationResource(ctx, c, sbx)
+	if err != nil {
+		return nil, err
+	}
+	old := alloc.DeepCopy()
+	if !usesAllocationResource(sbx) {
+		pods, err := parseSandboxAllocation(sbx)
+		if err != nil {
+			return nil, err
+		}
+		released, err := sandboxAllocationReleased(ctx, c, sbx)
+		if err != nil {
+			return nil, err
+		}
+		alloc.Spec.Pods, alloc.Spec.Released = pods.Pods, released.Pods
+	}
+	alloc.Spec.PoolRef = sbx.Spec.PoolRef
+	mutate(&alloc.Spec)
+	if alloc.ResourceVersion == "" {
+		if err := controllerutil.SetControllerReference(sbx, alloc, c.Scheme()); err != nil {
+			return nil, err
+		}
+		if err := c.Create(ctx, alloc); err != nil {
+			return nil, fmt.Errorf("failed to create allocation of sandbox %s: %w", sbx.Name, err)
+		}
+		return alloc, nil
+	}
+	if err := c.Patch(ctx, alloc, client.MergeFrom(old)); err != nil {
+		return nil, fmt.Errorf("failed to update allocation of sandbox %s: %w", sbx.Name, err)
+	}
+	return alloc, nil
+}
+
+// markAllocationResource switches the sandbox to its Allocation and drops the annotations it replaces.
+func markAllocationResource(sbx *sandboxv1alpha1.BatchSandbox) {
+	anno := sbx.GetAnnotations()
+	if anno == nil {
+		anno = make(map[string]string)
+	}
+	anno[AnnoAllocStoreKey] = sandboxv1alpha1.AllocStoreResource
+	delete(anno, AnnoAllocStatusKey)
+	delete(anno, AnnoAllocReleasedKey)
+	sbx.SetAnnotations(anno)
+}
+
+// allPodsReleased reports whether every allocated pod has been released.
+func allPodsReleased(allocated, released []string) bool {
+	for _, p := range allocated {
+		if !slices.Contains(released, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceAllocationSyncer is the AllocationSyncer of the resource store. Sandboxes that keep their
+// allocation in annotations are migrated to an Allocation on their next write, unless they are being
+// deleted; with migrate unset they stay on annotations and only migrated sandboxes use their Allocation.
+type resourceAllocationSyncer struct {
+	client  client.Client
+	anno    *annoAllocationSyncer
+	migrate bool
+}
+
+func newResourceAllocationSyncer(client client.Client, migrate bool) *resourceAllocationSyncer {
+	return &resourceAllocationSyncer{
+		client:  client,
+		anno:    &annoAllocationSyncer{client: client},
+		migrate: migrate,
+	}
+}
+
+// usesResource reports whether writes for the sandbox go to its Allocation.
+func (syncer *resourceAllocationSyncer) usesResource(sandbox *sandboxv1alpha1.BatchSandbox) bool {
+	return usesAllocationResource(sandbox) || (syncer.migrate && sandbox.DeletionTimestamp.IsZero())
+}
+
+func (syncer *resourceAllocationSyncer) SetAllocation(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox, allocation *SandboxAllocation) error {
+	if !syncer.usesResource(sandbox) {
+		return syncer.anno.SetAllocation(ctx, sandbox, allocation)
+	}
+	if _, err := updateAllocationResource(ctx, syncer.client, sandbox, func(spec *sandboxv1alpha1.AllocationSpec) {
+		spec.Pods = allocation.Pods
+	}); err != nil {
+		return err
+	}
+	if usesAllocationResource(sandbox) && controllerutil.ContainsFinalizer(sandbox, FinalizerPoolAllocation) {
+		return nil
+	}
+	old := sandbox.DeepCopy()
+	if !usesAllocationResource(sandbox) {
+		logf.FromContext(ctx).Info("Migrated sandbox allocation to an Allocation", "sandbox", sandbox.Name)
+	}
+	markAllocationResource(sandbox)
+	// Add finalizer to ensure the sandbox is not deleted before all pods are recycled.
+	controllerutil.AddFinalizer(sandbox, FinalizerPoolAllocation)
+	return syncer.client.Patch(ctx, sandbox, client.MergeFrom(old))
+}
+
+func (syncer *resourceAllocationSyncer) GetAllocation(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox) (*SandboxAllocation, error) {
+	alloc, err := sandboxAllocation(ctx, syncer.client, sandbox)
+	if err != nil {
+		return nil, err
+	}
+	if alloc.Pods == nil {
+		alloc.Pods = make([]string, 0)
+	}
+	return &alloc, nil
+}
+
+func (syncer *resourceAllocationSyncer) GetRelease(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox) (*AllocationRelease, error) {
+	// Release requests are written by the BatchSandbox side and stay in the alloc-release annotation.
+	return syncer.anno.GetRelease(ctx, sandbox)
+}
+
+func (syncer *resourceAllocationSyncer) GetReleased(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox) (*AllocationReleased, error) {
+	released, err := sandboxAllocationReleased(ctx, syncer.client, sandbox)
+	if err != nil {
+		return nil, err
+	}
+	if released.Pods == nil {
+		released.Pods = make([]string, 0)
+	}
+	return &released, nil
+}
+
+func (syncer *resourceAllocationSyncer) SetReleased(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox, released *AllocationReleased) error {
+	if !syncer.usesResource(sandbox) {
+		return syncer.anno.SetReleased(ctx, sandbox, released)
+	}
+	alloc, err := updateAllocationResource(ctx, syncer.client, sandbox, func(spec *sandboxv1alpha1.AllocationSpec) {
+		spec.Released = released.Pods
+	})
+	if err != nil {
+		return err
+	}
+	old := sandbox.DeepCopy()
+	markAllocationResource(sandbox)
+	// If the sandbox is being deleted and all allocated pods have been released,
+	// remove the finalizer so the sandbox can be garbage collected.
+	if !sandbox.DeletionTimestamp.IsZero() && allPodsReleased(alloc.Spec.Pods, released.Pods) {
+		controllerutil.RemoveFinalizer(sandbox, FinalizerPoolAllocation)
+	}
+	if usesAllocationResource(old) && controllerutil.ContainsFinalizer(old, FinalizerPoolAllocation) ==
+		controllerutil.ContainsFinalizer(sandbox, FinalizerPoolAllocation) {
+		return nil
+	}
+	return syncer.client.Patch(ctx, sandbox, client.MergeFrom(old))
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func newResourceSyncerTest(t *testing.T, migrate bool, objs ...client.Object) (*resourceAllocationSyncer, client.Client) {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+	return newResourceAllocationSyncer(c, migrate), c
+}
+
+func TestResourceAllocationSyncer_MigratesAnnotations(t *testing.T) {
+	ctx := context.Background()
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx1", Namespace: "default", UID: "uid-1", Annotations: map[string]string{
+			AnnoAllocStatusKey:   `{"pods":["pod1","pod2"]}`,
+			AnnoAllocReleasedKey: `{"pods":["pod1"]}`,
+			AnnoAllocReleaseKey:  `{"pods":["pod1"]}`,
+		}},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+	syncer, c := newResourceSyncerTest(t, true, sbx)
+
+	require.NoError(t, syncer.SetAllocation(ctx, sbx, &SandboxAllocation{Pods: []string{"pod1", "pod2", "pod3"}}))
+
+	alloc := &sandboxv1alpha1.Allocation{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(sbx), alloc))
+	assert.Equal(t, "pool1", alloc.Spec.PoolRef)
+	assert.Equal(t, []string{"pod1", "pod2", "pod3"}, alloc.Spec.Pods)
+	assert.Equal(t, []string{"pod1"}, alloc.Spec.Released, "released pods are carried over from the annotation")
+	require.Len(t, alloc.OwnerReferences, 1)
+	assert.Equal(t, "sbx1", alloc.OwnerReferences[0].Name)
+
+	latest := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(sbx), latest))
+	assert.True(t, usesAllocationResource(latest))
+	assert.NotContains(t, latest.Annotations, AnnoAllocStatusKey)
+	assert.NotContains(t, latest.Annotations, AnnoAllocReleasedKey)
+	assert.Contains(t, latest.Annotations, AnnoAllocReleaseKey, "release requests stay on the sandbox")
+	assert.True(t, controllerutil.ContainsFinalizer(latest, FinalizerPoolAllocation))
+
+	got, err := syncer.GetAllocation(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod1", "pod2", "pod3"}, got.Pods)
+	require.NoError(t, syncer.SetReleased(ctx, latest, &AllocationReleased{Pods: []string{"pod1", "pod2"}}))
+	released, err := syncer.GetReleased(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod1", "pod2"}, released.Pods)
+	release, err := syncer.GetRelease(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod1"}, release.Pods)
+}
+
+func TestResourceAllocationSyncer_AnnotationStore(t *testing.T) {
+	ctx := context.Background()
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx1", Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+	migrated := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx2", Namespace: "default", UID: "uid-2"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+	markAllocationResource(migrated)
+	controllerutil.AddFinalizer(migrated, FinalizerPoolAllocation)
+	syncer, c := newResourceSyncerTest(t, false, sbx, migrated)
+
+	require.NoError(t, syncer.SetAllocation(ctx, sbx, &SandboxAllocation{Pods: []string{"pod1"}}))
+	assert.Equal(t, `{"pods":["pod1"]}`, sbx.Annotations[AnnoAllocStatusKey])
+	err := c.Get(ctx, client.ObjectKeyFromObject(sbx), &sandboxv1alpha1.Allocation{})
+	assert.True(t, errors.IsNotFound(err), "sandboxes on annotations are not migrated")
+
+	// A migrated sandbox keeps its Allocation.
+	require.NoError(t, syncer.SetAllocation(ctx, migrated, &SandboxAllocation{Pods: []string{"pod2"}}))
+	got, err := syncer.GetAllocation(ctx, migrated)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod2"}, got.Pods)
+	assert.NotContains(t, migrated.Annotations, AnnoAllocStatusKey)
+}
+
+func TestResourceAllocationSyncer_SetReleasedRemovesFinalizer(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.NewTime(time.Now())
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx1", Namespace: "default", UID: "uid-1",
+			Finalizers: []string{FinalizerPoolAllocation}, DeletionTimestamp: &now},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+	markAllocationResource(sbx)
+	alloc := &sandboxv1alpha1.Allocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx1", Namespace: "default"},
+		Spec:       sandboxv1alpha1.AllocationSpec{PoolRef: "pool1", Pods: []string{"pod1", "pod2"}},
+	}
+	syncer, _ := newResourceSyncerTest(t, true, sbx, alloc)
+
+	require.NoError(t, syncer.SetReleased(ctx, sbx, &AllocationReleased{Pods: []string{"pod1"}}))
+	assert.True(t, controllerutil.ContainsFinalizer(sbx, FinalizerPoolAllocation))
+	require.NoError(t, syncer.SetReleased(ctx, sbx, &AllocationReleased{Pods: []string{"pod1", "pod2"}}))
+	assert.False(t, controllerutil.ContainsFinalizer(sbx, FinalizerPoolAllocation))
+}
+
+func TestInMemoryAllocationStore_RecoverFromAllocationResource(t *testing.T) {
+	ctx := context.Background()
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx1", Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+	markAllocationResource(sbx)
+	alloc := &sandboxv1alpha1.Allocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx1", Namespace: "default"},
+		Spec:       sandboxv1alpha1.AllocationSpec{PoolRef: "pool1", Pods: []string{"pod1", "pod2"}, Released: []string{"pod2"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(sbx, alloc).Build()
+	store := NewInMemoryAllocationStore()
+
+	require.NoError(t, store.Recover(ctx, c))
+	got, err := store.GetAllocation(ctx, &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "default"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pod1": "sbx1"}, got.PodAllocation)
+}
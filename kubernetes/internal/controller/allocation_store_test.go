@@ -17,12 +17,16 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
@@ -144,6 +148,7 @@ func TestInMemoryAllocationStore_GetAllocation_IsolatedByPool(t *testing.T) {
 
 func TestInMemoryAllocationStore_Recover(t *testing.T) {
 	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
 	_ = sandboxv1alpha1.AddToScheme(scheme)
 
 	allocation1 := &SandboxAllocation{Pods: []string{"pod1", "pod2"}}
@@ -212,8 +217,114 @@ func TestInMemoryAllocationStore_Recover(t *testing.T) {
 	assert.Equal(t, 0, len(store.pools["default/pool2"].data), "pool2 should have no allocations")
 }
 
+func TestInMemoryAllocationStore_Recover_CorruptedAllocationFromPodLabels(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = sandboxv1alpha1.AddToScheme(scheme)
+
+	sandbox := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sandbox1",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnoAllocStatusKey: `{"pods":[`},
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			PoolRef: "pool1",
+		},
+	}
+	allocatedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "pod1",
+		Namespace: "default",
+		Labels:    map[string]string{LabelPoolName: "pool1", LabelAllocatedTo: "sandbox1"},
+	}}
+	otherPoolPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "pod2",
+		Namespace: "default",
+		Labels:    map[string]string{LabelPoolName: "pool2", LabelAllocatedTo: "sandbox1"},
+	}}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(sandbox, allocatedPod, otherPoolPod).
+		Build()
+
+	store := NewInMemoryAllocationStore().(*InMemoryAllocationStore)
+	err := store.Recover(context.Background(), client)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"pod1": "sandbox1"}, store.pools["default/pool1"].data)
+}
+
+func TestInMemoryAllocationStore_Recover_FromPodLabels(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = sandboxv1alpha1.AddToScheme(scheme)
+
+	newLabeledPod := func(name, sandboxName string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{LabelPoolName: "pool1", LabelAllocatedTo: sandboxName},
+		}}
+	}
+	// sandbox1 lost pod2 from its annotation; pod3 was released; pod4 is claimed by sandbox2's label only.
+	sandbox1 := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sandbox1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnoAllocStatusKey:   `{"pods":["pod1","pod3","pod4"]}`,
+				AnnoAllocReleasedKey: `{"pods":["pod3"]}`,
+			},
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+	sandbox2 := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sandbox2", Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool1"},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(sandbox1, sandbox2,
+			newLabeledPod("pod1", "sandbox1"),
+			newLabeledPod("pod2", "sandbox1"),
+			newLabeledPod("pod3", "sandbox1"),
+			newLabeledPod("pod4", "sandbox2"),
+			newLabeledPod("pod5", "deleted-sandbox"),
+		).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	store := NewDefaultAllocator(client, recorder).(*defaultAllocator).store.(*InMemoryAllocationStore)
+	ctx := context.Background()
+
+	err := store.Recover(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"pod1": "sandbox1",
+		"pod2": "sandbox1",
+		"pod4": "sandbox1",
+		"pod5": "deleted-sandbox",
+	}, store.pools["default/pool1"].data)
+
+	got := &sandboxv1alpha1.BatchSandbox{}
+	assert.NoError(t, client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "sandbox1"}, got))
+	alloc, err := parseSandboxAllocation(got)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pod1", "pod2", "pod3", "pod4"}, alloc.Pods)
+
+	events := make([]string, 0, len(recorder.Events))
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Len(t, events, 2)
+	assert.Contains(t, strings.Join(events, "\n"), "AllocationMismatch")
+	assert.Contains(t, strings.Join(events, "\n"), "AllocationRestored")
+}
+
 func TestInMemoryAllocationStore_Recover_ReleaseOnlyOwnPods(t *testing.T) {
 	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
 	_ = sandboxv1alpha1.AddToScheme(scheme)
 
 	allocation1 := &SandboxAllocation{Pods: []string{"pod1"}}
@@ -268,6 +379,7 @@ func TestInMemoryAllocationStore_Recover_ReleaseOnlyOwnPods(t *testing.T) {
 
 func TestInMemoryAllocationStore_Recover_ReleasePodReassignedMultipleTimes(t *testing.T) {
 	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
 	_ = sandboxv1alpha1.AddToScheme(scheme)
 
 	allocation1 := &SandboxAllocation{Pods: []string{"pod1"}}
@@ -333,6 +445,7 @@ func TestInMemoryAllocationStore_Recover_ReleasePodReassignedMultipleTimes(t *te
 
 func TestInMemoryAllocationStore_Recover_ClearsExisting(t *testing.T) {
 	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
 	_ = sandboxv1alpha1.AddToScheme(scheme)
 
 	client := fake.NewClientBuilder().WithScheme(scheme).Build()
@@ -370,6 +483,45 @@ func TestInMemoryAllocationStore_Recover_ClearsExisting(t *testing.T) {
 	assert.Equal(t, "sandbox1", store.pools["default/pool1"].data["new-pod"])
 }
 
+func TestInMemoryAllocationStore_RecoverPool(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = sandboxv1alpha1.AddToScheme(scheme)
+
+	newSandbox := func(name, pool, alloc string) *sandboxv1alpha1.BatchSandbox {
+		return &sandboxv1alpha1.BatchSandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{AnnoAllocStatusKey: alloc},
+			},
+			Spec: sandboxv1alpha1.BatchSandboxSpec{PoolRef: pool},
+		}
+	}
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newSandbox("sandbox1", "pool1", `{"pods":["pod1"]}`),
+			newSandbox("sandbox2", "pool2", `{"pods":["pod9"]}`),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod2",
+				Namespace: "default",
+				Labels:    map[string]string{LabelPoolName: "pool1", LabelAllocatedTo: "deleted-sandbox"},
+			}},
+		).
+		Build()
+	store := NewInMemoryAllocationStore().(*InMemoryAllocationStore)
+	ctx := context.Background()
+
+	store.pools["default/pool1"] = &poolEntry{data: map[string]string{"stale-pod": "sandbox1"}}
+	store.pools["default/pool2"] = &poolEntry{data: map[string]string{"pod8": "sandbox2"}}
+
+	err := store.RecoverPool(ctx, client, "default", "pool1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"pod1": "sandbox1", "pod2": "deleted-sandbox"}, store.pools["default/pool1"].data)
+	assert.Equal(t, map[string]string{"pod8": "sandbox2"}, store.pools["default/pool2"].data, "other pools are untouched")
+}
+
 func TestInMemoryAllocationStore_ThreadSafety(t *testing.T) {
 	store := NewInMemoryAllocationStore()
 	ctx := context.Background()
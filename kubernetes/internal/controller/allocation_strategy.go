@@ -0,0 +1,203 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+// allocationStrategy returns allocationPolicy.strategy of the sandbox, else allocationStrategy of the pool.
+func allocationStrategy(pool *sandboxv1alpha1.Pool, sandbox *sandboxv1alpha1.BatchSandbox) sandboxv1alpha1.AllocationStrategy {
+	if policy := sandbox.Spec.AllocationPolicy; policy != nil && policy.Strategy != "" {
+		return policy.Strategy
+	}
+	return pool.Spec.AllocationStrategy
+}
+
+// strategySchedule serves the sandboxes with an allocation strategy one at a time, handing the inner
+// algorithm the available pods in the order of the strategy. The requests keep their order: the requests
+// between two sandboxes with a strategy are served together, in pool order.
+type strategySchedule struct {
+	inner      algorithm.Algorithm
+	strategies map[string]sandboxv1alpha1.AllocationStrategy // sandbox -> strategy
+	nodes      map[string]string                             // pod -> node
+	zones      map[string]string                             // pod -> zone, only for SpreadZones
+}
+
+// newStrategySchedule wraps the algorithm so that it places pods by the allocation strategies of the
+// sandboxes, or returns it as is when no sandbox has one.
+func newStrategySchedule(ctx context.Context, c client.Client, inner algorithm.Algorithm, pool *sandboxv1alpha1.Pool,
+	pods []*corev1.Pod, sandboxes []*sandboxv1alpha1.BatchSandbox) (algorithm.Algorithm, error) {
+	strategies := make(map[string]sandboxv1alpha1.AllocationStrategy)
+	byZone := false
+	for _, sandbox := range sandboxes {
+		strategy := allocationStrategy(pool, sandbox)
+		if strategy == "" {
+			continue
+		}
+		strategies[sandbox.Name] = strategy
+		byZone = byZone || strategy == sandboxv1alpha1.AllocationStrategySpreadZones
+	}
+	if len(strategies) == 0 {
+		return inner, nil
+	}
+
+	s := &strategySchedule{
+		inner:      inner,
+		strategies: strategies,
+		nodes:      make(map[string]string, len(pods)),
+		zones:      make(map[string]string),
+	}
+	for _, pod := range pods {
+		s.nodes[pod.Name] = pod.Spec.NodeName
+	}
+	if byZone {
+		values, err := podNodeLabels(ctx, c, pods, []string{corev1.LabelTopologyZone})
+		if err != nil {
+			return nil, err
+		}
+		for name, podValues := range values {
+			s.zones[name] = podValues[0]
+		}
+	}
+	return s, nil
+}
+
+func (s *strategySchedule) Schedule(availablePods []string, allRequest []*algorithm.SandboxRequest) *algorithm.AllocAction {
+	action := &algorithm.AllocAction{
+		ToAllocate: make(map[string][]string),
+		ToRelease:  make(map[string][]string),
+	}
+	remaining := slices.Clone(availablePods)
+	serve := func(pods []string, requests []*algorithm.SandboxRequest) {
+		// The inner algorithm may return slices of pods, which must not change with remaining.
+		served := s.inner.Schedule(slices.Clone(pods), requests)
+		mergeAllocAction(action, served)
+		for _, picked := range served.ToAllocate {
+			remaining = slices.DeleteFunc(remaining, func(name string) bool { return slices.Contains(picked, name) })
+		}
+	}
+	var batch []*algorithm.SandboxRequest
+	for _, req := range allRequest {
+		strategy, ok := s.strategies[req.SandboxName]
+		if !ok || req.PodSupplement <= 0 {
+			batch = append(batch, req)
+			continue
+		}
+		if len(batch) > 0 {
+			serve(remaining, batch)
+			batch = nil
+		}
+		serve(s.order(strategy, req, remaining), []*algorithm.SandboxRequest{req})
+	}
+	if len(batch) > 0 {
+		serve(remaining, batch)
+	}
+	return action
+}
+
+// order returns the available pods in the order the strategy allocates them to the sandbox of the request.
+// Pods not bound to a node go last.
+func (s *strategySchedule) order(strategy sandboxv1alpha1.AllocationStrategy, req *algorithm.SandboxRequest, available []string) []string {
+	// The pods the sandbox keeps count towards the nodes and zones it is on.
+	var held []string
+	for _, name := range req.CurAllocation {
+		if !slices.Contains(req.CurReleased, name) && !slices.Contains(req.ToRelease, name) {
+			held = append(held, name)
+		}
+	}
+
+	zone := func(name string) string {
+		if strategy != sandboxv1alpha1.AllocationStrategySpreadZones {
+			return ""
+		}
+		return s.zones[name]
+	}
+
+	type bucket struct {
+		node, zone string
+		pods       []string
+	}
+	var buckets []*bucket
+	byNode := make(map[string]*bucket)
+	var unbound []string
+	for _, name := range available {
+		node := s.nodes[name]
+		if node == "" {
+			unbound = append(unbound, name)
+			continue
+		}
+		b, ok := byNode[node]
+		if !ok {
+			b = &bucket{node: node, zone: zone(name)}
+			byNode[node] = b
+			buckets = append(buckets, b)
+		}
+		b.pods = append(b.pods, name)
+	}
+	nodeCount := make(map[string]int)
+	zoneCount := make(map[string]int)
+	for _, name := range held {
+		if node := s.nodes[name]; node != "" {
+			nodeCount[node]++
+			zoneCount[zone(name)]++
+		}
+	}
+
+	ordered := make([]string, 0, len(available))
+	if strategy == sandboxv1alpha1.AllocationStrategyPack {
+		// Whole nodes at a time: the nodes the sandbox is on, then the nodes with the most available pods.
+		slices.SortStableFunc(buckets, func(a, b *bucket) int {
+			if c := nodeCount[b.node] - nodeCount[a.node]; c != 0 {
+				return c
+			}
+			return len(b.pods) - len(a.pods)
+		})
+		for _, b := range buckets {
+			ordered = append(ordered, b.pods...)
+		}
+		return append(ordered, unbound...)
+	}
+
+	// One pod at a time from the zone and then the node the sandbox has the fewest pods in. Zones are
+	// all "" unless spreading across zones, so only nodes count then.
+	for {
+		var best *bucket
+		for _, b := range buckets {
+			if len(b.pods) == 0 {
+				continue
+			}
+			if best == nil || zoneCount[b.zone] < zoneCount[best.zone] ||
+				zoneCount[b.zone] == zoneCount[best.zone] && nodeCount[b.node] < nodeCount[best.node] {
+				best = b
+			}
+		}
+		if best == nil {
+			break
+		}
+		ordered = append(ordered, best.pods[0])
+		best.pods = best.pods[1:]
+		nodeCount[best.node]++
+		zoneCount[best.zone]++
+	}
+	return append(ordered, unbound...)
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+func strategySandbox(name string, strategy sandboxv1alpha1.AllocationStrategy) *sandboxv1alpha1.BatchSandbox {
+	sandbox := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	if strategy != "" {
+		sandbox.Spec.AllocationPolicy = &sandboxv1alpha1.AllocationPolicy{Strategy: strategy}
+	}
+	return sandbox
+}
+
+func TestNewStrategySchedule_WithoutStrategy(t *testing.T) {
+	inner := &algorithm.PackedSchedule{}
+	pool := &sandboxv1alpha1.Pool{}
+	algo, err := newStrategySchedule(context.Background(), nil, inner, pool, nil, []*sandboxv1alpha1.BatchSandbox{strategySandbox("plain", "")})
+	require.NoError(t, err)
+	assert.Same(t, inner, algo)
+}
+
+func TestStrategySchedule(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		pluginTestNode("node-1", map[string]string{corev1.LabelTopologyZone: "zone-a"}),
+		pluginTestNode("node-2", map[string]string{corev1.LabelTopologyZone: "zone-a"}),
+		pluginTestNode("node-3", map[string]string{corev1.LabelTopologyZone: "zone-b"}),
+	).Build()
+	pods := []*corev1.Pod{
+		pluginTestPod("a-1", "node-1", nil),
+		pluginTestPod("b-1", "node-2", nil),
+		pluginTestPod("a-2", "node-1", nil),
+		pluginTestPod("c-1", "node-3", nil),
+		pluginTestPod("a-3", "node-1", nil),
+		pluginTestPod("c-2", "node-3", nil),
+		pluginTestPod("unbound", "", nil),
+		pluginTestPod("held", "node-1", nil),
+	}
+	available := []string{"a-1", "b-1", "a-2", "c-1", "a-3", "c-2", "unbound"}
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{AllocationStrategy: sandboxv1alpha1.AllocationStrategySpreadNodes}}
+
+	schedule := func(sandbox *sandboxv1alpha1.BatchSandbox, req *algorithm.SandboxRequest) []string {
+		algo, err := newStrategySchedule(ctx, c, &algorithm.PackedSchedule{}, pool, pods, []*sandboxv1alpha1.BatchSandbox{sandbox})
+		require.NoError(t, err)
+		return algo.Schedule(available, []*algorithm.SandboxRequest{req}).ToAllocate[sandbox.Name]
+	}
+
+	assert.Equal(t, []string{"a-1", "a-2", "a-3", "c-1"},
+		schedule(strategySandbox("pack", sandboxv1alpha1.AllocationStrategyPack), &algorithm.SandboxRequest{SandboxName: "pack", PodSupplement: 4}),
+		"the node with the most available pods is filled first")
+	assert.Equal(t, []string{"a-1", "a-2"},
+		schedule(strategySandbox("pack-held", sandboxv1alpha1.AllocationStrategyPack), &algorithm.SandboxRequest{SandboxName: "pack-held", PodSupplement: 2, CurAllocation: []string{"held"}}))
+	assert.Equal(t, []string{"a-1", "b-1", "c-1", "a-2"},
+		schedule(strategySandbox("spread", ""), &algorithm.SandboxRequest{SandboxName: "spread", PodSupplement: 4}),
+		"the strategy of the pool applies to sandboxes without one")
+	assert.Equal(t, []string{"b-1", "c-1"},
+		schedule(strategySandbox("spread-held", ""), &algorithm.SandboxRequest{SandboxName: "spread-held", PodSupplement: 2, CurAllocation: []string{"held"}}),
+		"the nodes the sandbox is on already go last")
+	assert.Equal(t, []string{"a-1", "b-1"},
+		schedule(strategySandbox("released", ""), &algorithm.SandboxRequest{SandboxName: "released", PodSupplement: 2, CurAllocation: []string{"held"}, CurReleased: []string{"held"}}))
+	assert.Equal(t, []string{"a-1", "c-1", "b-1", "c-2"},
+		schedule(strategySandbox("zones", sandboxv1alpha1.AllocationStrategySpreadZones), &algorithm.SandboxRequest{SandboxName: "zones", PodSupplement: 4}))
+}
+
+func TestStrategySchedule_KeepsRequestOrder(t *testing.T) {
+	pods := []*corev1.Pod{
+		pluginTestPod("a-1", "node-1", nil),
+		pluginTestPod("a-2", "node-1", nil),
+		pluginTestPod("b-1", "node-2", nil),
+		pluginTestPod("b-2", "node-2", nil),
+	}
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		strategySandbox("first", ""),
+		strategySandbox("spread", sandboxv1alpha1.AllocationStrategySpreadNodes),
+		strategySandbox("last", ""),
+	}
+	algo, err := newStrategySchedule(context.Background(), nil, &algorithm.PackedSchedule{}, &sandboxv1alpha1.Pool{}, pods, sandboxes)
+	require.NoError(t, err)
+
+	action := algo.Schedule([]string{"a-1", "a-2", "b-1", "b-2"}, []*algorithm.SandboxRequest{
+		{SandboxName: "first", PodSupplement: 1},
+		{SandboxName: "spread", PodSupplement: 2},
+		{SandboxName: "last", PodSupplement: 2, ToRelease: []string{"old"}},
+	})
+
+	assert.Equal(t, []string{"a-1"}, action.ToAllocate["first"])
+	assert.Equal(t, []string{"a-2", "b-1"}, action.ToAllocate["spread"])
+	assert.Equal(t, []string{"b-2"}, action.ToAllocate["last"])
+	assert.Equal(t, []string{"old"}, action.ToRelease["last"])
+	assert.Equal(t, int32(1), action.PodSupplement)
+}
@@ -19,16 +19,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
 	algorithm "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
-	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
 )
 
 type AllocationStore interface {
@@ -41,6 +44,8 @@ type AllocationStore interface {
 	// This is used when a BatchSandbox is deleted and its allocation needs to be cleaned up.
 	ReleaseSandboxAllocation(ctx context.Context, ns string, poolName string, sandboxName string)
 	Recover(ctx context.Context, c client.Client) error
+	// RecoverPool rebuilds the allocation of a single pool, replacing its in-memory state.
+	RecoverPool(ctx context.Context, c client.Client, ns string, poolName string) error
 }
 
 // poolEntry represents a single pool's allocation data with its own lock for fine-grained concurrency control
@@ -49,17 +54,18 @@ type poolEntry struct {
 	data map[string]string // podName -> sandboxName
 }
 
-// InMemoryAllocationStore depends on annoAllocationSyncer to get allocation info from BatchSandbox.
+// InMemoryAllocationStore recovers allocation info from the BatchSandboxes, reading their annotations or
+// their Allocation objects.
 type InMemoryAllocationStore struct {
 	poolsMu sync.RWMutex
 	pools   map[string]*poolEntry
-	syncer  *annoAllocationSyncer
+	// recorder reports inconsistencies found during Recover. Optional.
+	recorder record.EventRecorder
 }
 
 func NewInMemoryAllocationStore() AllocationStore {
 	return &InMemoryAllocationStore{
-		pools:  make(map[string]*poolEntry),
-		syncer: &annoAllocationSyncer{},
+		pools: make(map[string]*poolEntry),
 	}
 }
 
@@ -76,18 +82,15 @@ func (store *InMemoryAllocationStore) Recover(ctx context.Context, c client.Clie
 
 	// Build new pools map first without holding the lock
 	newPools := make(map[string]*poolEntry)
+	sandboxes := make(map[string]*sandboxv1alpha1.BatchSandbox)
+	releasedPods := make(map[string]map[string]struct{})
 
-	for _, sbx := range batchSandboxList.Items {
-		poolRef := sbx.Spec.PoolRef
-		if poolRef == "" {
+	for i := range batchSandboxList.Items {
+		sbx := &batchSandboxList.Items[i]
+		if sbx.Spec.PoolRef == "" {
 			continue
 		}
-		allocation, err := store.syncer.GetAllocation(ctx, &sbx)
-		if err != nil {
-			log.Error(err, "Failed to unmarshal sandbox allocation during recovery", "sandbox", sbx.Name)
-			return err
-		}
-		key := store.poolKey(sbx.Namespace, poolRef)
+		key := store.poolKey(sbx.Namespace, sbx.Spec.PoolRef)
 		entry, exists := newPools[key]
 		if !exists {
 			entry = &poolEntry{
@@ -95,25 +98,17 @@ func (store *InMemoryAllocationStore) Recover(ctx context.Context, c client.Clie
 			}
 			newPools[key] = entry
 		}
-
-		for _, podName := range allocation.Pods {
-			entry.data[podName] = sbx.Name
-		}
-		// Filter pods that have already been released (alloc-released records completed recycle).
-		// alloc-release (in-progress) pods must NOT be filtered: the recycle handler is still
-		// processing them and they are still "in use" from the pool's perspective.
-		allocReleased, err := store.syncer.GetReleased(ctx, &sbx)
+		sbxKey := store.poolKey(sbx.Namespace, sbx.Name)
+		sandboxes[sbxKey] = sbx
+		released, err := store.recoverSandbox(ctx, c, sbx, entry)
 		if err != nil {
-			log.Error(err, "Failed to unmarshal sandbox released during recovery", "sandbox", sbx.Name)
 			return err
 		}
-		for _, podName := range allocReleased.Pods {
-			if entry.data[podName] == sbx.Name {
-				delete(entry.data, podName)
-			}
-		}
+		releasedPods[sbxKey] = released
+	}
 
-		log.Info("Recovered sandbox allocation", "pool", poolRef, "sandbox", sbx.Name, "pods", len(allocation.Pods))
+	if err := store.recoverFromPodLabels(ctx, c, newPools, sandboxes, releasedPods); err != nil {
+		return err
 	}
 
 	store.poolsMu.Lock()
@@ -124,6 +119,188 @@ func (store *InMemoryAllocationStore) Recover(ctx context.Context, c client.Clie
 	return nil
 }
 
+// RecoverPool rebuilds the allocation of a single pool from its BatchSandboxes and pod labels, replacing
+// the in-memory state of that pool. It is used when a manager replica takes over a pool that another
+// replica has been allocating from.
+func (store *InMemoryAllocationStore) RecoverPool(ctx context.Context, c client.Client, ns string, poolName string) error {
+	log := logf.FromContext(ctx)
+	batchSandboxList := &sandboxv1alpha1.BatchSandboxList{}
+	if err := c.List(ctx, batchSandboxList, client.InNamespace(ns)); err != nil {
+		return fmt.Errorf("failed to list batch sandboxes for recovery of pool %s: %w", poolName, err)
+	}
+
+	key := store.poolKey(ns, poolName)
+	pools := map[string]*poolEntry{key: {data: make(map[string]string)}}
+	sandboxes := make(map[string]*sandboxv1alpha1.BatchSandbox)
+	releasedPods := make(map[string]map[string]struct{})
+	for i := range batchSandboxList.Items {
+		sbx := &batchSandboxList.Items[i]
+		if sbx.Spec.PoolRef != poolName {
+			continue
+		}
+		sbxKey := store.poolKey(sbx.Namespace, sbx.Name)
+		sandboxes[sbxKey] = sbx
+		released, err := store.recoverSandbox(ctx, c, sbx, pools[key])
+		if err != nil {
+			return err
+		}
+		releasedPods[sbxKey] = released
+	}
+	if err := store.recoverFromPodLabels(ctx, c, pools, sandboxes, releasedPods,
+		client.InNamespace(ns), client.MatchingLabels{LabelPoolName: poolName}); err != nil {
+		return err
+	}
+
+	store.poolsMu.Lock()
+	store.pools[key] = pools[key]
+	store.poolsMu.Unlock()
+
+	log.Info("Pool allocation recovery completed", "pool", poolName, "pods", len(pools[key].data))
+	return nil
+}
+
+// recoverSandbox adds the allocation recorded on the sandbox to the pool entry and returns the pods the
+// sandbox has already released.
+func (store *InMemoryAllocationStore) recoverSandbox(ctx context.Context, c client.Client, sbx *sandboxv1alpha1.BatchSandbox, entry *poolEntry) (map[string]struct{}, error) {
+	log := logf.FromContext(ctx)
+	allocation, err := sandboxAllocation(ctx, c, sbx)
+	if err != nil {
+		// Fall back to pod labels; the pool reconciler persists the reconstructed allocation later.
+		log.Error(err, "Corrupted sandbox allocation during recovery, reconstructing from pod labels", "sandbox", sbx.Name)
+		fromLabels, err := store.allocationFromPodLabels(ctx, c, sbx)
+		if err != nil {
+			return nil, err
+		}
+		allocation = *fromLabels
+	}
+	for _, podName := range allocation.Pods {
+		entry.data[podName] = sbx.Name
+	}
+	// Filter pods that have already been released (alloc-released records completed recycle).
+	// alloc-release (in-progress) pods must NOT be filtered: the recycle handler is still
+	// processing them and they are still "in use" from the pool's perspective.
+	allocReleased, err := sandboxAllocationReleased(ctx, c, sbx)
+	if err != nil {
+		log.Error(err, "Failed to unmarshal sandbox released during recovery", "sandbox", sbx.Name)
+		return nil, err
+	}
+	released := make(map[string]struct{}, len(allocReleased.Pods))
+	for _, podName := range allocReleased.Pods {
+		released[podName] = struct{}{}
+		if entry.data[podName] == sbx.Name {
+			delete(entry.data, podName)
+		}
+	}
+
+	log.Info("Recovered sandbox allocation", "pool", sbx.Spec.PoolRef, "sandbox", sbx.Name, "pods", len(allocation.Pods))
+	return released, nil
+}
+
+// recoverFromPodLabels cross-checks the allocation rebuilt from sandbox annotations against the
+// LabelAllocatedTo pod labels. Annotations win on conflicts. Labeled pods missing from the annotation
+// of a live sandbox are restored into its alloc-status, and pods labeled for a sandbox that no longer
+// exists are recorded as orphans so that the pool reconciler recycles them.
+func (store *InMemoryAllocationStore) recoverFromPodLabels(ctx context.Context, c client.Client, pools map[string]*poolEntry,
+	sandboxes map[string]*sandboxv1alpha1.BatchSandbox, releasedPods map[string]map[string]struct{}, opts ...client.ListOption) error {
+	log := logf.FromContext(ctx)
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, append([]client.ListOption{client.HasLabels{LabelAllocatedTo, LabelPoolName}}, opts...)...); err != nil {
+		return fmt.Errorf("failed to list allocated pods for recovery: %w", err)
+	}
+
+	restored := make(map[string][]string)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		sbxName := pod.Labels[LabelAllocatedTo]
+		poolKey := store.poolKey(pod.Namespace, pod.Labels[LabelPoolName])
+		sbxKey := store.poolKey(pod.Namespace, sbxName)
+		entry, exists := pools[poolKey]
+		if !exists {
+			entry = &poolEntry{data: make(map[string]string)}
+			pools[poolKey] = entry
+		}
+
+		if owner, ok := entry.data[pod.Name]; ok {
+			if owner != sbxName {
+				log.Info("Pod label conflicts with sandbox allocation, keeping annotation", "pod", pod.Name, "label", sbxName, "sandbox", owner)
+				if sbx := sandboxes[store.poolKey(pod.Namespace, owner)]; sbx != nil {
+					store.eventf(sbx, corev1.EventTypeWarning, "AllocationMismatch",
+						"Pod %s is labeled as allocated to %s but allocated to %s by annotation", pod.Name, sbxName, owner)
+				}
+			}
+			continue
+		}
+		sbx := sandboxes[sbxKey]
+		if sbx == nil {
+			log.Info("Recovered orphan allocation from pod label", "pod", pod.Name, "sandbox", sbxName)
+			entry.data[pod.Name] = sbxName
+			continue
+		}
+		if _, released := releasedPods[sbxKey][pod.Name]; released || sbx.Spec.PoolRef != pod.Labels[LabelPoolName] {
+			continue
+		}
+		entry.data[pod.Name] = sbxName
+		restored[sbxKey] = append(restored[sbxKey], pod.Name)
+	}
+
+	for sbxKey, pods := range restored {
+		if err := store.restoreSandboxAllocation(ctx, c, sandboxes[sbxKey], pods); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreSandboxAllocation adds pods recovered from labels back to the alloc-status of the sandbox.
+func (store *InMemoryAllocationStore) restoreSandboxAllocation(ctx context.Context, c client.Client, sbx *sandboxv1alpha1.BatchSandbox, pods []string) error {
+	log := logf.FromContext(ctx)
+	if usesAllocationResource(sbx) {
+		if _, err := updateAllocationResource(ctx, c, sbx, func(spec *sandboxv1alpha1.AllocationSpec) {
+			spec.Pods = append(spec.Pods, pods...)
+		}); err != nil {
+			return fmt.Errorf("failed to restore allocation of sandbox %s: %w", sbx.Name, err)
+		}
+	} else {
+		allocation, err := parseSandboxAllocation(sbx)
+		if err != nil {
+			// Corrupted annotations are quarantined by the pool reconciler.
+			return nil
+		}
+		old := sbx.DeepCopy()
+		setSandboxAllocation(sbx, SandboxAllocation{Pods: append(allocation.Pods, pods...)})
+		if err := c.Patch(ctx, sbx, client.MergeFrom(old)); err != nil {
+			return fmt.Errorf("failed to restore allocation of sandbox %s: %w", sbx.Name, err)
+		}
+	}
+	log.Info("Restored sandbox allocation from pod labels", "sandbox", sbx.Name, "pods", pods)
+	store.eventf(sbx, corev1.EventTypeWarning, "AllocationRestored",
+		"Restored %d pods missing from %s from pod labels: %v", len(pods), AnnoAllocStatusKey, pods)
+	return nil
+}
+
+func (store *InMemoryAllocationStore) eventf(obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if store.recorder != nil {
+		store.recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+	}
+}
+
+// allocationFromPodLabels returns the pool pods labeled as allocated to the sandbox.
+func (store *InMemoryAllocationStore) allocationFromPodLabels(ctx context.Context, c client.Client, sbx *sandboxv1alpha1.BatchSandbox) (*SandboxAllocation, error) {
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(sbx.Namespace),
+		client.MatchingLabels{LabelPoolName: sbx.Spec.PoolRef, LabelAllocatedTo: sbx.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list pods allocated to sandbox %s: %w", sbx.Name, err)
+	}
+	allocation := &SandboxAllocation{Pods: make([]string, 0, len(podList.Items))}
+	for i := range podList.Items {
+		allocation.Pods = append(allocation.Pods, podList.Items[i].Name)
+	}
+	return allocation, nil
+}
+
 func (store *InMemoryAllocationStore) ClearAllocation(ctx context.Context, ns string, poolName string) error {
 	log := logf.FromContext(ctx)
 	store.poolsMu.Lock()
@@ -289,54 +466,36 @@ func (syncer *annoAllocationSyncer) SetAllocation(ctx context.Context, sandbox *
 }
 
 func (syncer *annoAllocationSyncer) GetAllocation(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox) (*SandboxAllocation, error) {
-	allocation := &SandboxAllocation{
-		Pods: make([]string, 0),
-	}
-	anno := sandbox.GetAnnotations()
-	if anno == nil {
-		return allocation, nil
+	pods, err := allocationDecodes.decodePods(sandbox, AnnoAllocStatusKey)
+	if err != nil {
+		return nil, err
 	}
-	if raw := anno[AnnoAllocStatusKey]; raw != "" {
-		err := json.Unmarshal([]byte(raw), allocation)
-		if err != nil {
-			return nil, err
-		}
+	if pods == nil {
+		pods = make([]string, 0)
 	}
-	return allocation, nil
+	return &SandboxAllocation{Pods: pods}, nil
 }
 
 func (syncer *annoAllocationSyncer) GetRelease(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox) (*AllocationRelease, error) {
-	release := &AllocationRelease{
-		Pods: make([]string, 0),
-	}
-	anno := sandbox.GetAnnotations()
-	if anno == nil {
-		return release, nil
+	pods, err := allocationDecodes.decodePods(sandbox, AnnoAllocReleaseKey)
+	if err != nil {
+		return nil, err
 	}
-	if raw := anno[AnnoAllocReleaseKey]; raw != "" {
-		err := json.Unmarshal([]byte(raw), release)
-		if err != nil {
-			return nil, err
-		}
+	if pods == nil {
+		pods = make([]string, 0)
 	}
-	return release, nil
+	return &AllocationRelease{Pods: pods}, nil
 }
 
 func (syncer *annoAllocationSyncer) GetReleased(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox) (*AllocationReleased, error) {
-	released := &AllocationReleased{
-		Pods: make([]string, 0),
-	}
-	anno := sandbox.GetAnnotations()
-	if anno == nil {
-		return released, nil
+	pods, err := allocationDecodes.decodePods(sandbox, AnnoAllocReleasedKey)
+	if err != nil {
+		return nil, err
 	}
-	if raw := anno[AnnoAllocReleasedKey]; raw != "" {
-		err := json.Unmarshal([]byte(raw), released)
-		if err != nil {
-			return nil, err
-		}
+	if pods == nil {
+		pods = make([]string, 0)
 	}
-	return released, nil
+	return &AllocationReleased{Pods: pods}, nil
 }
 
 func (syncer *annoAllocationSyncer) SetReleased(ctx context.Context, sandbox *sandboxv1alpha1.BatchSandbox, released *AllocationReleased) error {
@@ -361,18 +520,7 @@ func (syncer *annoAllocationSyncer) SetReleased(ctx context.Context, sandbox *sa
 		if err != nil {
 			return err
 		}
-		releasedSet := make(map[string]struct{}, len(released.Pods))
-		for _, p := range released.Pods {
-			releasedSet[p] = struct{}{}
-		}
-		allReleased := true
-		for _, p := range allocation.Pods {
-			if _, ok := releasedSet[p]; !ok {
-				allReleased = false
-				break
-			}
-		}
-		if allReleased {
+		if allPodsReleased(allocation.Pods, released.Pods) {
 			controllerutil.RemoveFinalizer(sandbox, FinalizerPoolAllocation)
 		}
 	}
@@ -386,12 +534,20 @@ type AllocSpec struct {
 	Pool      *sandboxv1alpha1.Pool
 	// Pods contains all candidate pods owned by the pool.
 	Pods []*corev1.Pod
+	// Revision is the latest revision of the pool, the only one sandboxes with the Latest revision policy
+	// are allocated pods of. Empty when unknown.
+	Revision string
+	// Reservations are the reservations of the pool, which hold idle pods for the sandboxes referencing them.
+	Reservations []*sandboxv1alpha1.Reservation
 }
 
 type Allocator interface {
 	Schedule(ctx context.Context, spec *AllocSpec) (*algorithm.AllocAction, error)
 	GetPoolAllocation(ctx context.Context, pool *sandboxv1alpha1.Pool) (map[string]string, error)
 	ClearPoolAllocation(ctx context.Context, ns string, poolName string) error
+	// RecoverPoolAllocation discards the in-memory allocation of the pool and rebuilds it from the cluster.
+	// It must be called before scheduling a pool whose allocations may have been changed by another replica.
+	RecoverPoolAllocation(ctx context.Context, ns string, poolName string) error
 	// ReleasePodsAllocation releases the in-memory allocation for the given pods directly,
 	// without persisting to an annotation. Used for orphan pods whose sandbox no longer exists.
 	ReleasePodsAllocation(ctx context.Context, ns string, poolName string, pods []string)
@@ -402,19 +558,42 @@ type Allocator interface {
 }
 
 type defaultAllocator struct {
-	store       AllocationStore
-	syncer      AllocationSyncer
-	client      client.Client
-	algorithm   algorithm.Algorithm
+	store     AllocationStore
+	syncer    AllocationSyncer
+	client    client.Client
+	algorithm algorithm.Algorithm
+	// framework, when set, places pods with allocator plugins instead of algorithm.
+	framework   *AllocatorFramework
+	recorder    record.EventRecorder
+	queue       *allocationQueue
+	quota       *quotaEvents
 	recoverOnce sync.Once
 }
 
-func NewDefaultAllocator(client client.Client) Allocator {
+// NewDefaultAllocator returns an allocator that persists allocations in BatchSandbox annotations. Sandboxes
+// already migrated to an Allocation by NewResourceAllocator keep using it.
+func NewDefaultAllocator(client client.Client, recorder record.EventRecorder) Allocator {
+	return newDefaultAllocator(client, recorder, newResourceAllocationSyncer(client, false))
+}
+
+// NewResourceAllocator returns an allocator that persists allocations in Allocation objects. Sandboxes
+// that still keep their allocation in annotations are migrated on their next allocation change.
+func NewResourceAllocator(client client.Client, recorder record.EventRecorder) Allocator {
+	return newDefaultAllocator(client, recorder, newResourceAllocationSyncer(client, true))
+}
+
+func newDefaultAllocator(client client.Client, recorder record.EventRecorder, syncer AllocationSyncer) Allocator {
 	return &defaultAllocator{
-		store:     NewInMemoryAllocationStore(),
-		syncer:    NewAnnoAllocationSyncer(client),
+		store: &InMemoryAllocationStore{
+			pools:    make(map[string]*poolEntry),
+			recorder: recorder,
+		},
+		syncer:    syncer,
 		client:    client,
 		algorithm: &algorithm.PackedSchedule{},
+		recorder:  recorder,
+		queue:     newAllocationQueue(),
+		quota:     newQuotaEvents(),
 	}
 }
 
@@ -442,15 +621,46 @@ func (allocator *defaultAllocator) Schedule(ctx context.Context, spec *AllocSpec
 	if err != nil {
 		return nil, err
 	}
+	wanted := make(map[string]int32, len(allRequest))
+	for _, req := range allRequest {
+		wanted[req.SandboxName] = req.PodSupplement
+	}
+	// Hold back supplements that would exceed tenant quotas; they are retried on the next reconcile.
+	throttles := applyAllocationQuota(ctx, spec.Pool, spec.Sandboxes, podAllocation, allRequest)
+	if allocator.quota != nil {
+		allocator.quota.report(allocator.recorder, spec.Pool, spec.Sandboxes, throttles)
+	}
 
 	// Build available pod list using the already-fetched allocation to avoid an extra store read.
-	availablePods, err := allocator.getAvailablePodsFromAlloc(ctx, podAllocation, spec.Pods)
+	availablePods, err := allocator.getAvailablePodsFromAlloc(ctx, spec.Pool, podAllocation, spec.Pods)
 	if err != nil {
 		return nil, err
 	}
 
+	// Hold back reserved pods and allocate them to the sandboxes referencing their reservation first.
+	now := time.Now()
+	availablePods, scheduled, reserved := scheduleReservations(spec, availablePods, allRequest, now)
+
 	// Run the allocation algorithm.
-	action := allocator.algorithm.Schedule(availablePods, allRequest)
+	algo := allocator.algorithm
+	if allocator.framework != nil {
+		algo = allocator.framework.newSchedule(ctx, spec.Pool, spec.Pods, spec.Sandboxes)
+	}
+	algo, err = newStrategySchedule(ctx, allocator.client, algo, spec.Pool, spec.Pods, spec.Sandboxes)
+	if err != nil {
+		return nil, err
+	}
+	algo, err = newConstrainedSchedule(ctx, allocator.client, algo, spec.Pods, spec.Sandboxes)
+	if err != nil {
+		return nil, err
+	}
+	action := scheduleRevisions(ctx, algo, spec, availablePods, scheduled)
+	reserved.apply(spec.Pool, action)
+	// Sandboxes the exhausted pool leaves short of pods may take them from sandboxes of lower priority.
+	schedulePreemption(ctx, spec, podAllocation, availablePods, allRequest, action, now)
+	if allocator.queue != nil {
+		allocator.queue.report(allocator.recorder, spec.Pool, spec.Sandboxes, allRequest, wanted, action)
+	}
 
 	return action, nil
 }
@@ -459,11 +669,13 @@ func (allocator *defaultAllocator) Schedule(ctx context.Context, spec *AllocSpec
 // orphan entries for pods in podAllocation whose sandbox is no longer in the sandboxes list
 // (e.g. force-deleted). Orphan entries carry PodSupplement=0 and ToRelease set to the orphan
 // pods so the normal recycle path handles them without special-casing in the caller.
+// Requests are ordered by sortAllocationQueue, so that contending sandboxes are served by priority and first
+// come first served rather than in the order the cache lists them.
 func (allocator *defaultAllocator) getAllRequest(ctx context.Context, sandboxes []*sandboxv1alpha1.BatchSandbox, podAllocation map[string]string) ([]*algorithm.SandboxRequest, error) {
 	log := logf.FromContext(ctx)
 	existingSandboxes := make(map[string]struct{}, len(sandboxes))
 	allRequest := make([]*algorithm.SandboxRequest, 0, len(sandboxes))
-	for _, sandbox := range sandboxes {
+	for _, sandbox := range sortAllocationQueue(sandboxes, time.Now()) {
 		existingSandboxes[sandbox.Name] = struct{}{}
 		request, err := allocator.getSandboxRequest(ctx, sandbox)
 		if err != nil {
@@ -537,14 +749,44 @@ func (allocator *defaultAllocator) getSandboxRequest(ctx context.Context, sandbo
 		}
 	}
 
+	// Pods released by preemption are released like the pods of alloc-release, but replaced.
+	preemption, err := parseSandboxPreemption(sandbox)
+	if err != nil {
+		log.Error(err, "Ignoring invalid preemption state", "sandbox", sandbox.Name)
+	}
+	kept := int32(len(allocated))
+	for _, p := range allocated {
+		if _, ok := preemption.Preempted[p]; !ok {
+			continue
+		}
+		kept--
+		if _, exists := releasedSet[p]; !exists && !slices.Contains(toRelease, p) {
+			toRelease = append(toRelease, p)
+		}
+	}
+
+	// Pods the sandbox created on demand while the pool was saturated are not supplied again.
+	overflow, err := parseSandboxOverflow(sandbox)
+	if err != nil {
+		log.Error(err, "Ignoring invalid on-demand pods", "sandbox", sandbox.Name)
+	}
+	kept += int32(len(overflow.Pods))
+	// So are the replicas handed to a fallback pool.
+	fallback, err := parseSandboxFallback(sandbox)
+	if err != nil {
+		log.Error(err, "Ignoring invalid fallback", "sandbox", sandbox.Name)
+	}
+	kept += fallback.Replicas
+
 	replica := int32(0)
 	if sandbox.Spec.Replicas != nil {
 		replica = *sandbox.Spec.Replicas
 	}
 
+	// A fail-fast sandbox that gave up on the pool keeps what it has but asks for nothing more.
 	supplement := int32(0)
-	if replica-int32(len(allocated)) > 0 {
-		supplement = replica - int32(len(allocated))
+	if replica-kept > 0 && !isPoolExhausted(sandbox) {
+		supplement = replica - kept
 	}
 
 	return &algorithm.SandboxRequest{
@@ -553,6 +795,7 @@ func (allocator *defaultAllocator) getSandboxRequest(ctx context.Context, sandbo
 		CurReleased:   released,
 		PodSupplement: supplement,
 		ToRelease:     toRelease,
+		Gang:          isGangAllocation(sandbox),
 	}, nil
 }
 
@@ -595,6 +838,10 @@ func (allocator *defaultAllocator) ClearPoolAllocation(ctx context.Context, ns s
 	return allocator.store.ClearAllocation(ctx, ns, poolName)
 }
 
+func (allocator *defaultAllocator) RecoverPoolAllocation(ctx context.Context, ns string, poolName string) error {
+	return allocator.store.RecoverPool(ctx, allocator.client, ns, poolName)
+}
+
 func (allocator *defaultAllocator) ReleasePodsAllocation(ctx context.Context, ns string, poolName string, pods []string) {
 	allocator.store.ReleaseAllocation(ctx, ns, poolName, pods)
 }
@@ -661,13 +908,13 @@ func (allocator *defaultAllocator) checkRecovery(ctx context.Context) error {
 	return nil
 }
 
-func (allocator *defaultAllocator) getAvailablePodsFromAlloc(_ context.Context, podAllocation map[string]string, pods []*corev1.Pod) ([]string, error) {
+func (allocator *defaultAllocator) getAvailablePodsFromAlloc(_ context.Context, pool *sandboxv1alpha1.Pool, podAllocation map[string]string, pods []*corev1.Pod) ([]string, error) {
 	availablePods := make([]string, 0)
 	for _, pod := range pods {
 		if _, ok := podAllocation[pod.Name]; ok {
 			continue
 		}
-		if !utils.IsPodReady(pod) {
+		if !isPodAvailable(pool, pod) {
 			continue
 		}
 		availablePods = append(availablePods, pod.Name)
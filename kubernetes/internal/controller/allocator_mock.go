@@ -80,6 +80,20 @@ func (mr *MockAllocationStoreMockRecorder) Recover(ctx, c interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recover", reflect.TypeOf((*MockAllocationStore)(nil).Recover), ctx, c)
 }
 
+// RecoverPool mocks base method.
+func (m *MockAllocationStore) RecoverPool(ctx context.Context, c client.Client, ns, poolName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverPool", ctx, c, ns, poolName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverPool indicates an expected call of RecoverPool.
+func (mr *MockAllocationStoreMockRecorder) RecoverPool(ctx, c, ns, poolName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverPool", reflect.TypeOf((*MockAllocationStore)(nil).RecoverPool), ctx, c, ns, poolName)
+}
+
 // ReleaseAllocation mocks base method.
 func (m *MockAllocationStore) ReleaseAllocation(ctx context.Context, ns, poolName string, pods []string) {
 	m.ctrl.T.Helper()
@@ -308,6 +322,20 @@ func (mr *MockAllocatorMockRecorder) GetSandboxReleased(ctx, sandbox interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSandboxReleased", reflect.TypeOf((*MockAllocator)(nil).GetSandboxReleased), ctx, sandbox)
 }
 
+// RecoverPoolAllocation mocks base method.
+func (m *MockAllocator) RecoverPoolAllocation(ctx context.Context, ns, poolName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverPoolAllocation", ctx, ns, poolName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverPoolAllocation indicates an expected call of RecoverPoolAllocation.
+func (mr *MockAllocatorMockRecorder) RecoverPoolAllocation(ctx, ns, poolName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverPoolAllocation", reflect.TypeOf((*MockAllocator)(nil).RecoverPoolAllocation), ctx, ns, poolName)
+}
+
 // ReleasePodsAllocation mocks base method.
 func (m *MockAllocator) ReleasePodsAllocation(ctx context.Context, ns, poolName string, pods []string) {
 	m.ctrl.T.Helper()
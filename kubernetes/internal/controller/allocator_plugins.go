@@ -0,0 +1,420 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+const (
+	// AnnoRequiredNodeLabelsKey is set on a pooled BatchSandbox to a label selector the nodes of its pods
+	// must match, e.g. "nvidia.com/gpu.present=true". Used by the NodeLabels allocator plugin.
+	AnnoRequiredNodeLabelsKey = "sandbox.opensandbox.io/required-node-labels"
+	// AnnoPreferredNodeLabelsKey is AnnoRequiredNodeLabelsKey for nodes to prefer: pods on nodes matching
+	// more of the requirements of the selector are allocated first.
+	AnnoPreferredNodeLabelsKey = "sandbox.opensandbox.io/preferred-node-labels"
+	// AnnoColocateWithKey is set on a pooled BatchSandbox to a label selector of pods in its namespace, e.g.
+	// a data cache; pool pods on the nodes of those pods are allocated first. Used by the Colocation
+	// allocator plugin.
+	AnnoColocateWithKey = "sandbox.opensandbox.io/colocate-with"
+)
+
+// MaxPluginScore is the highest score a ScorePlugin gives a pod.
+const MaxPluginScore int64 = 100
+
+// AllocatorPlugin is a placement plugin of the allocator. A plugin implements FilterPlugin, ScorePlugin
+// or both. Filter plugins run first and rule out pods for a sandbox; the remaining pods are allocated in
+// decreasing order of the weighted sum of the scores, pods of equal score in the order of the pool.
+type AllocatorPlugin interface {
+	Name() string
+}
+
+// FilterPlugin rules out pool pods for a sandbox.
+type FilterPlugin interface {
+	AllocatorPlugin
+	// Filter reports whether the pod can be allocated to the sandbox. An error rules the pod out.
+	Filter(ctx context.Context, cycle *AllocationCycle, sandbox *sandboxv1alpha1.BatchSandbox, pod *corev1.Pod) (bool, error)
+}
+
+// ScorePlugin ranks the pool pods that passed the filters for a sandbox.
+type ScorePlugin interface {
+	AllocatorPlugin
+	// Score rates the pod for the sandbox between 0 and MaxPluginScore. An error scores 0.
+	Score(ctx context.Context, cycle *AllocationCycle, sandbox *sandboxv1alpha1.BatchSandbox, pod *corev1.Pod) (int64, error)
+}
+
+// AllocatorPluginFactory builds a plugin for the manager client.
+type AllocatorPluginFactory func(c client.Client) (AllocatorPlugin, error)
+
+var (
+	allocatorPluginsMu sync.RWMutex
+	allocatorPlugins   = map[string]AllocatorPluginFactory{
+		nodeLabelsPluginName: func(client.Client) (AllocatorPlugin, error) { return &nodeLabelsPlugin{}, nil },
+		colocationPluginName: func(client.Client) (AllocatorPlugin, error) { return &colocationPlugin{}, nil },
+	}
+)
+
+// RegisterAllocatorPlugin makes a plugin available to --allocator-plugins. Builds of the manager with
+// custom placement logic call it before the flags are applied.
+func RegisterAllocatorPlugin(name string, factory AllocatorPluginFactory) {
+	allocatorPluginsMu.Lock()
+	defer allocatorPluginsMu.Unlock()
+	allocatorPlugins[name] = factory
+}
+
+// AllocatorPluginConfig enables a plugin with the weight of its scores.
+type AllocatorPluginConfig struct {
+	Name   string
+	Weight int64
+}
+
+// ParseAllocatorPluginConfigs parses the value of --allocator-plugins: a comma-separated list of plugin
+// names, each optionally followed by =<weight> (default 1).
+func ParseAllocatorPluginConfigs(value string) ([]AllocatorPluginConfig, error) {
+	var configs []AllocatorPluginConfig
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		config := AllocatorPluginConfig{Name: entry, Weight: 1}
+		if name, weight, ok := strings.Cut(entry, "="); ok {
+			w, err := strconv.ParseInt(weight, 10, 64)
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight of allocator plugin %q: %q", name, weight)
+			}
+			config = AllocatorPluginConfig{Name: name, Weight: w}
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// AllocatorFramework is the plugin chain the allocator places pods with.
+type AllocatorFramework struct {
+	client  client.Client
+	filters []FilterPlugin
+	scores  []weightedScorePlugin
+}
+
+type weightedScorePlugin struct {
+	ScorePlugin
+	weight int64
+}
+
+// NewAllocatorFramework builds the plugins of configs, in order.
+func NewAllocatorFramework(c client.Client, configs []AllocatorPluginConfig) (*AllocatorFramework, error) {
+	framework := &AllocatorFramework{client: c}
+	allocatorPluginsMu.RLock()
+	defer allocatorPluginsMu.RUnlock()
+	for _, config := range configs {
+		factory, ok := allocatorPlugins[config.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown allocator plugin %q", config.Name)
+		}
+		plugin, err := factory(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build allocator plugin %q: %w", config.Name, err)
+		}
+		used := false
+		if filter, ok := plugin.(FilterPlugin); ok {
+			framework.filters = append(framework.filters, filter)
+			used = true
+		}
+		if score, ok := plugin.(ScorePlugin); ok {
+			framework.scores = append(framework.scores, weightedScorePlugin{ScorePlugin: score, weight: config.Weight})
+			used = true
+		}
+		if !used {
+			return nil, fmt.Errorf("allocator plugin %q implements neither Filter nor Score", config.Name)
+		}
+	}
+	return framework, nil
+}
+
+// WithAllocatorPlugins makes the allocator place pods with the plugins of framework.
+func WithAllocatorPlugins(allocator Allocator, framework *AllocatorFramework) (Allocator, error) {
+	a, ok := allocator.(*defaultAllocator)
+	if !ok {
+		return nil, fmt.Errorf("allocator %T does not support plugins", allocator)
+	}
+	a.framework = framework
+	return a, nil
+}
+
+// AllocationCycle is the state the plugins share within one Schedule of a pool.
+type AllocationCycle struct {
+	Client client.Client
+	Pool   *sandboxv1alpha1.Pool
+
+	nodes map[string]*corev1.Node
+	cache map[string]any
+}
+
+// Node returns the node of the name, or nil if it does not exist. Nodes are read once per cycle.
+func (cycle *AllocationCycle) Node(ctx context.Context, name string) (*corev1.Node, error) {
+	if node, ok := cycle.nodes[name]; ok {
+		return node, nil
+	}
+	node := &corev1.Node{}
+	if err := cycle.Client.Get(ctx, client.ObjectKey{Name: name}, node); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		node = nil
+	}
+	cycle.nodes[name] = node
+	return node, nil
+}
+
+// Cached returns the value of the key for this cycle, computing it with compute the first time.
+// Plugins use it for lookups that are the same for many pods.
+func (cycle *AllocationCycle) Cached(key string, compute func() (any, error)) (any, error) {
+	if value, ok := cycle.cache[key]; ok {
+		return value, nil
+	}
+	value, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	cycle.cache[key] = value
+	return value, nil
+}
+
+// newSchedule returns the algorithm placing pods with the plugins for one Schedule of the pool.
+func (framework *AllocatorFramework) newSchedule(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod,
+	sandboxes []*sandboxv1alpha1.BatchSandbox) algorithm.Algorithm {
+	s := &pluginSchedule{
+		ctx:       ctx,
+		framework: framework,
+		cycle: &AllocationCycle{
+			Client: framework.client,
+			Pool:   pool,
+			nodes:  make(map[string]*corev1.Node),
+			cache:  make(map[string]any),
+		},
+		pods:      make(map[string]*corev1.Pod, len(pods)),
+		sandboxes: make(map[string]*sandboxv1alpha1.BatchSandbox, len(sandboxes)),
+	}
+	for _, pod := range pods {
+		s.pods[pod.Name] = pod
+	}
+	for _, sandbox := range sandboxes {
+		s.sandboxes[sandbox.Name] = sandbox
+	}
+	return s
+}
+
+// pluginSchedule serves the requests in order like algorithm.PackedSchedule, giving each sandbox the best
+// scored of the available pods that pass the filters. Gang requests get nothing unless enough pods pass.
+type pluginSchedule struct {
+	ctx       context.Context
+	framework *AllocatorFramework
+	cycle     *AllocationCycle
+	pods      map[string]*corev1.Pod
+	sandboxes map[string]*sandboxv1alpha1.BatchSandbox
+}
+
+func (s *pluginSchedule) Schedule(availablePods []string, allRequest []*algorithm.SandboxRequest) *algorithm.AllocAction {
+	action := &algorithm.AllocAction{
+		ToAllocate:    make(map[string][]string),
+		ToRelease:     make(map[string][]string),
+		PodSupplement: int32(0),
+	}
+
+	available := slices.Clone(availablePods)
+	for _, req := range allRequest {
+		if len(req.ToRelease) > 0 {
+			action.ToRelease[req.SandboxName] = req.ToRelease
+		}
+
+		need := req.PodSupplement
+		if need <= 0 {
+			continue
+		}
+		sandbox, ok := s.sandboxes[req.SandboxName]
+		if !ok {
+			continue
+		}
+		picked := s.pick(sandbox, available, int(need))
+		if req.Gang && int32(len(picked)) < need {
+			picked = nil
+		}
+		if len(picked) > 0 {
+			action.ToAllocate[req.SandboxName] = picked
+			available = slices.DeleteFunc(available, func(name string) bool { return slices.Contains(picked, name) })
+		}
+		action.PodSupplement += need - int32(len(picked))
+	}
+
+	return action
+}
+
+// pick returns up to n of the available pods for the sandbox, best scored first.
+func (s *pluginSchedule) pick(sandbox *sandboxv1alpha1.BatchSandbox, available []string, n int) []string {
+	log := logf.FromContext(s.ctx)
+	type candidate struct {
+		name  string
+		score int64
+	}
+	candidates := make([]candidate, 0, len(available))
+	for _, name := range available {
+		pod, ok := s.pods[name]
+		if !ok {
+			continue
+		}
+		feasible := true
+		for _, filter := range s.framework.filters {
+			ok, err := filter.Filter(s.ctx, s.cycle, sandbox, pod)
+			if err != nil {
+				log.Error(err, "Allocator plugin filter failed", "plugin", filter.Name(), "sandbox", sandbox.Name, "pod", name)
+			}
+			if err != nil || !ok {
+				feasible = false
+				break
+			}
+		}
+		if !feasible {
+			continue
+		}
+		var total int64
+		for _, plugin := range s.framework.scores {
+			score, err := plugin.Score(s.ctx, s.cycle, sandbox, pod)
+			if err != nil {
+				log.Error(err, "Allocator plugin score failed", "plugin", plugin.Name(), "sandbox", sandbox.Name, "pod", name)
+				continue
+			}
+			total += plugin.weight * min(max(score, 0), MaxPluginScore)
+		}
+		candidates = append(candidates, candidate{name: name, score: total})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	picked := make([]string, 0, min(n, len(candidates)))
+	for _, c := range candidates[:min(n, len(candidates))] {
+		picked = append(picked, c.name)
+	}
+	return picked
+}
+
+const (
+	nodeLabelsPluginName = "NodeLabels"
+	colocationPluginName = "Colocation"
+)
+
+// nodeLabelsPlugin keeps sandboxes on nodes matching their AnnoRequiredNodeLabelsKey and prefers nodes
+// matching their AnnoPreferredNodeLabelsKey.
+type nodeLabelsPlugin struct{}
+
+func (p *nodeLabelsPlugin) Name() string { return nodeLabelsPluginName }
+
+func (p *nodeLabelsPlugin) Filter(ctx context.Context, cycle *AllocationCycle, sandbox *sandboxv1alpha1.BatchSandbox, pod *corev1.Pod) (bool, error) {
+	value := sandbox.Annotations[AnnoRequiredNodeLabelsKey]
+	if value == "" {
+		return true, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", AnnoRequiredNodeLabelsKey, err)
+	}
+	node, err := podNode(ctx, cycle, pod)
+	if err != nil || node == nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(node.Labels)), nil
+}
+
+func (p *nodeLabelsPlugin) Score(ctx context.Context, cycle *AllocationCycle, sandbox *sandboxv1alpha1.BatchSandbox, pod *corev1.Pod) (int64, error) {
+	value := sandbox.Annotations[AnnoPreferredNodeLabelsKey]
+	if value == "" {
+		return 0, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", AnnoPreferredNodeLabelsKey, err)
+	}
+	requirements, _ := selector.Requirements()
+	node, err := podNode(ctx, cycle, pod)
+	if err != nil || node == nil || len(requirements) == 0 {
+		return 0, err
+	}
+	matched := 0
+	for _, requirement := range requirements {
+		if requirement.Matches(labels.Set(node.Labels)) {
+			matched++
+		}
+	}
+	return MaxPluginScore * int64(matched) / int64(len(requirements)), nil
+}
+
+// colocationPlugin prefers the nodes running the pods selected by the AnnoColocateWithKey of a sandbox.
+type colocationPlugin struct{}
+
+func (p *colocationPlugin) Name() string { return colocationPluginName }
+
+func (p *colocationPlugin) Score(ctx context.Context, cycle *AllocationCycle, sandbox *sandboxv1alpha1.BatchSandbox, pod *corev1.Pod) (int64, error) {
+	value := sandbox.Annotations[AnnoColocateWithKey]
+	if value == "" || pod.Spec.NodeName == "" {
+		return 0, nil
+	}
+	nodes, err := cycle.Cached(colocationPluginName+"/"+sandbox.Namespace+"/"+value, func() (any, error) {
+		selector, err := labels.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AnnoColocateWithKey, err)
+		}
+		podList := &corev1.PodList{}
+		if err := cycle.Client.List(ctx, podList, client.InNamespace(sandbox.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		nodes := make(map[string]bool, len(podList.Items))
+		for i := range podList.Items {
+			if name := podList.Items[i].Spec.NodeName; name != "" {
+				nodes[name] = true
+			}
+		}
+		return nodes, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if nodes.(map[string]bool)[pod.Spec.NodeName] {
+		return MaxPluginScore, nil
+	}
+	return 0, nil
+}
+
+// podNode returns the node of the pod, or nil if the pod is not bound to an existing node.
+func podNode(ctx context.Context, cycle *AllocationCycle, pod *corev1.Pod) (*corev1.Node, error) {
+	if pod.Spec.NodeName == "" {
+		return nil, nil
+	}
+	return cycle.Node(ctx, pod.Spec.NodeName)
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+func pluginTestPod(name, node string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+func pluginTestNode(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func pluginTestSandbox(name string, annotations map[string]string) *sandboxv1alpha1.BatchSandbox {
+	return &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+}
+
+func TestParseAllocatorPluginConfigs(t *testing.T) {
+	configs, err := ParseAllocatorPluginConfigs("NodeLabels, Colocation=3,")
+	require.NoError(t, err)
+	assert.Equal(t, []AllocatorPluginConfig{{Name: "NodeLabels", Weight: 1}, {Name: "Colocation", Weight: 3}}, configs)
+
+	_, err = ParseAllocatorPluginConfigs("Colocation=x")
+	assert.Error(t, err)
+	_, err = NewAllocatorFramework(nil, []AllocatorPluginConfig{{Name: "Missing", Weight: 1}})
+	assert.Error(t, err)
+}
+
+func TestAllocatorPlugins_NodeLabels(t *testing.T) {
+	ctx := context.Background()
+	pods := []*corev1.Pod{
+		pluginTestPod("cpu-1", "cpu-node", nil),
+		pluginTestPod("gpu-1", "gpu-node", nil),
+		pluginTestPod("gpu-2", "gpu-node", nil),
+		pluginTestPod("unbound", "", nil),
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		pluginTestNode("cpu-node", map[string]string{"zone": "a"}),
+		pluginTestNode("gpu-node", map[string]string{"zone": "a", "gpu": "true"}),
+	).Build()
+	framework, err := NewAllocatorFramework(c, []AllocatorPluginConfig{{Name: "NodeLabels", Weight: 1}})
+	require.NoError(t, err)
+
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		pluginTestSandbox("prefers-gpu", map[string]string{AnnoPreferredNodeLabelsKey: "gpu=true"}),
+		pluginTestSandbox("needs-gpu", map[string]string{AnnoRequiredNodeLabelsKey: "gpu=true"}),
+	}
+	algo := framework.newSchedule(ctx, &sandboxv1alpha1.Pool{}, pods, sandboxes)
+	action := algo.Schedule([]string{"cpu-1", "unbound", "gpu-1", "gpu-2"}, []*algorithm.SandboxRequest{
+		{SandboxName: "prefers-gpu", PodSupplement: 1},
+		{SandboxName: "needs-gpu", PodSupplement: 2},
+	})
+
+	assert.Equal(t, []string{"gpu-1"}, action.ToAllocate["prefers-gpu"], "the preferred node comes first")
+	assert.Equal(t, []string{"gpu-2"}, action.ToAllocate["needs-gpu"], "pods on other nodes are filtered out")
+	assert.Equal(t, int32(1), action.PodSupplement)
+
+	action = algo.Schedule([]string{"cpu-1", "unbound", "gpu-1"}, []*algorithm.SandboxRequest{
+		{SandboxName: "needs-gpu", PodSupplement: 2, Gang: true},
+	})
+	assert.Empty(t, action.ToAllocate, "a gang gets no pods unless enough pass the filters")
+	assert.Equal(t, int32(2), action.PodSupplement)
+}
+
+func TestAllocatorPlugins_Colocation(t *testing.T) {
+	ctx := context.Background()
+	pods := []*corev1.Pod{
+		pluginTestPod("pool-1", "node-a", nil),
+		pluginTestPod("pool-2", "node-b", nil),
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		pluginTestPod("cache", "node-b", map[string]string{"app": "cache"}),
+	).Build()
+	framework, err := NewAllocatorFramework(c, []AllocatorPluginConfig{{Name: "Colocation", Weight: 1}})
+	require.NoError(t, err)
+
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		pluginTestSandbox("plain", nil),
+		pluginTestSandbox("with-cache", map[string]string{AnnoColocateWithKey: "app=cache"}),
+	}
+	algo := framework.newSchedule(ctx, &sandboxv1alpha1.Pool{}, pods, sandboxes)
+	action := algo.Schedule([]string{"pool-1", "pool-2"}, []*algorithm.SandboxRequest{
+		{SandboxName: "with-cache", PodSupplement: 1},
+		{SandboxName: "plain", PodSupplement: 1, ToRelease: []string{"old"}},
+	})
+
+	assert.Equal(t, []string{"pool-2"}, action.ToAllocate["with-cache"])
+	assert.Equal(t, []string{"pool-1"}, action.ToAllocate["plain"])
+	assert.Equal(t, []string{"old"}, action.ToRelease["plain"])
+	assert.Zero(t, action.PodSupplement)
+}
+
+type reversePlugin struct{}
+
+func (reversePlugin) Name() string { return "Reverse" }
+
+func (reversePlugin) Score(_ context.Context, _ *AllocationCycle, _ *sandboxv1alpha1.BatchSandbox, pod *corev1.Pod) (int64, error) {
+	return int64(pod.Name[len(pod.Name)-1] - '0'), nil
+}
+
+func TestAllocatorPlugins_Registered(t *testing.T) {
+	RegisterAllocatorPlugin("Reverse", func(client.Client) (AllocatorPlugin, error) { return reversePlugin{}, nil })
+	t.Cleanup(func() {
+		allocatorPluginsMu.Lock()
+		defer allocatorPluginsMu.Unlock()
+		delete(allocatorPlugins, "Reverse")
+	})
+
+	framework, err := NewAllocatorFramework(nil, []AllocatorPluginConfig{{Name: "Reverse", Weight: 1}})
+	require.NoError(t, err)
+	allocator, err := WithAllocatorPlugins(NewDefaultAllocator(nil, nil), framework)
+	require.NoError(t, err)
+	assert.Same(t, framework, allocator.(*defaultAllocator).framework)
+
+	pods := []*corev1.Pod{pluginTestPod("pod-1", "", nil), pluginTestPod("pod-2", "", nil), pluginTestPod("pod-3", "", nil)}
+	algo := framework.newSchedule(context.Background(), &sandboxv1alpha1.Pool{}, pods,
+		[]*sandboxv1alpha1.BatchSandbox{pluginTestSandbox("sbx", nil)})
+	action := algo.Schedule([]string{"pod-1", "pod-2", "pod-3"}, []*algorithm.SandboxRequest{{SandboxName: "sbx", PodSupplement: 2}})
+	assert.Equal(t, []string{"pod-3", "pod-2"}, action.ToAllocate["sbx"])
+}
@@ -100,6 +100,28 @@ func TestSchedule(t *testing.T) {
 				PodSupplement: 1,
 			},
 		},
+		{
+			name: "contending sandboxes - oldest first",
+			spec: &AllocSpec{
+				Pods: []*corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}},
+				},
+				Pool: &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool1"}},
+				Sandboxes: []*sandboxv1alpha1.BatchSandbox{
+					{ObjectMeta: metav1.ObjectMeta{Name: "sbx1", CreationTimestamp: metav1.Unix(200, 0)}, Spec: sandboxv1alpha1.BatchSandboxSpec{Replicas: &replica1}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "sbx2", CreationTimestamp: metav1.Unix(100, 0)}, Spec: sandboxv1alpha1.BatchSandboxSpec{Replicas: &replica1}},
+				},
+			},
+			poolAlloc:     &PoolAllocation{PodAllocation: map[string]string{}},
+			sandboxAllocs: map[string]*SandboxAllocation{"sbx1": {Pods: []string{}}, "sbx2": {Pods: []string{}}},
+			releases:      map[string]*AllocationRelease{"sbx1": {Pods: []string{}}, "sbx2": {Pods: []string{}}},
+			released:      map[string]*AllocationReleased{"sbx1": {Pods: []string{}}, "sbx2": {Pods: []string{}}},
+			wantAction: &algorithm.AllocAction{
+				ToAllocate:    map[string][]string{"sbx2": {"pod1"}},
+				ToRelease:     map[string][]string{},
+				PodSupplement: 1,
+			},
+		},
 		{
 			name: "partial allocated - allocate remaining",
 			spec: &AllocSpec{
@@ -122,6 +144,34 @@ func TestSchedule(t *testing.T) {
 				PodSupplement: 0,
 			},
 		},
+		{
+			name: "pool exhausted - no new allocation",
+			spec: &AllocSpec{
+				Pods: []*corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}},
+				},
+				Pool: &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool1"}},
+				Sandboxes: []*sandboxv1alpha1.BatchSandbox{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "sbx1"},
+						Spec:       sandboxv1alpha1.BatchSandboxSpec{Replicas: &replica2},
+						Status: sandboxv1alpha1.BatchSandboxStatus{Conditions: []sandboxv1alpha1.BatchSandboxCondition{
+							{Type: sandboxv1alpha1.BatchSandboxConditionPoolExhausted, Status: sandboxv1alpha1.ConditionTrue},
+						}},
+					},
+				},
+			},
+			poolAlloc:     &PoolAllocation{PodAllocation: map[string]string{"pod1": "sbx1"}},
+			sandboxAllocs: map[string]*SandboxAllocation{"sbx1": {Pods: []string{"pod1"}}},
+			releases:      map[string]*AllocationRelease{"sbx1": {Pods: []string{}}},
+			released:      map[string]*AllocationReleased{"sbx1": {Pods: []string{}}},
+			wantAction: &algorithm.AllocAction{
+				ToAllocate:    map[string][]string{},
+				ToRelease:     map[string][]string{},
+				PodSupplement: 0,
+			},
+		},
 		{
 			name: "with release - pods to release",
 			spec: &AllocSpec{
@@ -19,20 +19,92 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/recycle"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
 	pkgutils "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/utils"
 )
 
 const (
-	AnnoAllocStatusKey           = "sandbox.opensandbox.io/alloc-status"
-	AnnoAllocReleaseKey          = "sandbox.opensandbox.io/alloc-release"
-	AnnoAllocReleasedKey         = "sandbox.opensandbox.io/alloc-released"
+	AnnoAllocStatusKey           = sandboxv1alpha1.AnnotationAllocStatus
+	AnnoAllocReleaseKey          = sandboxv1alpha1.AnnotationAllocRelease
+	AnnoAllocReleasedKey         = sandboxv1alpha1.AnnotationAllocReleased
+	AnnoAllocStoreKey            = sandboxv1alpha1.AnnotationAllocStore
 	LabelBatchSandboxPodIndexKey = "batch-sandbox.sandbox.opensandbox.io/pod-index"
 	LabelBatchSandboxNameKey     = "batch-sandbox.sandbox.opensandbox.io/name"
 	LabelPrivilegedNodeAccess    = "sandbox.opensandbox.io/privileged-node-access"
 
+	// AnnoPropagateLabelsKey and AnnoPropagateAnnotationsKey are set on a pooled BatchSandbox with a
+	// comma-separated list of its own label/annotation keys to copy onto allocated pool pods.
+	AnnoPropagateLabelsKey      = "sandbox.opensandbox.io/propagate-labels"
+	AnnoPropagateAnnotationsKey = "sandbox.opensandbox.io/propagate-annotations"
+	// AnnoPropagatedMetadataKey records on the pod which keys were copied from the BatchSandbox,
+	// so that they can be removed when the pod is released back to the pool.
+	AnnoPropagatedMetadataKey = "sandbox.opensandbox.io/propagated-metadata"
+
+	// LabelAllocatedTo is set on pool pods to the name of the BatchSandbox they are allocated to.
+	LabelAllocatedTo = "sandbox.opensandbox.io/allocated-to"
+
+	// AnnoEndpointPortsKey is set in a pod template to the named ports the sandbox serves, as a
+	// comma-separated list of name=port, where port is a number or the name of a container port. The
+	// controller reports them as host:port in status.podEndpoints.
+	AnnoEndpointPortsKey = "sandbox.opensandbox.io/endpoint-ports"
+
+	// AnnoAllocStatusCorruptedKey keeps a malformed alloc-status payload after it has been replaced
+	// by an allocation reconstructed from LabelAllocatedTo.
+	AnnoAllocStatusCorruptedKey = "sandbox.opensandbox.io/alloc-status-corrupted"
+
+	// AnnoHeartbeatKey is patched by BatchSandbox consumers with an RFC3339 timestamp to keep an active
+	// sandbox alive; see BatchSandboxSpec.HeartbeatTimeoutSeconds.
+	AnnoHeartbeatKey = "sandbox.opensandbox.io/heartbeat"
+
+	// LabelEgressTier is set on a namespace to the name of the ClusterEgressPolicy its sandboxes default to.
+	LabelEgressTier = "sandbox.opensandbox.io/egress-tier"
+	// AnnoEgressDefaultPolicyKey records on a pod which default egress policy has been pushed to its egress
+	// sidecar, as <BatchSandbox UID>/<policy name>/<policy generation>.
+	AnnoEgressDefaultPolicyKey = "sandbox.opensandbox.io/egress-default-policy"
+
+	// AnnoPoolRollbackToKey is set on a Pool to a template revision hash, or a ControllerRevision number,
+	// to restore the template of that revision. The controller removes it once the rollback is applied.
+	AnnoPoolRollbackToKey = "pool.sandbox.opensandbox.io/rollback-to"
+
+	// AnnoPoolSurgeReplacedKey marks an idle pool pod whose surge replacement has been requested, with the
+	// revision of the replacement. The pod is deleted once the replacement is available.
+	AnnoPoolSurgeReplacedKey = "pool.sandbox.opensandbox.io/surge-replaced"
+
+	// AnnoPoolConfirmScaleInKey is set on a Pool to its generation to confirm the scale-ins beyond
+	// ScaleStrategy.MaxScaleIn of that generation.
+	AnnoPoolConfirmScaleInKey = "pool.sandbox.opensandbox.io/confirm-scale-in"
+
+	// AnnoPoolDeletionCostKey steers which idle pool pods are deleted first on scale-in, upgrades and
+	// preemption, like controller.kubernetes.io/pod-deletion-cost which it overrides: lower costs go first.
+	AnnoPoolDeletionCostKey = utils.AnnotationPoolDeletionCost
+
+	// AnnoPoolAllocationCountKey counts the allocations of a pool pod; see PoolSpec.MaxAllocations.
+	AnnoPoolAllocationCountKey = recycle.AnnotationAllocationCount
+
+	// AnnoPoolHibernatedKey marks a hibernated pool pod with the requests its containers had before; see
+	// PoolSpec.Hibernation.
+	AnnoPoolHibernatedKey = "pool.sandbox.opensandbox.io/hibernated"
+
+	// AnnoPreemptionKey keeps the preemption state of a pooled BatchSandbox: the pods being preempted and
+	// the pods already released by preemption; see AllocationPolicy.PreemptionPolicy.
+	AnnoPreemptionKey = "sandbox.opensandbox.io/preemption"
+
+	// AnnoFallbackKey records on a pooled BatchSandbox the sandbox that takes its replicas to the fallback
+	// pools; see BatchSandboxSpec.FallbackPoolRefs. LabelFallbackOfKey is set on that sandbox to the name
+	// of the sandbox it serves.
+	AnnoFallbackKey    = "sandbox.opensandbox.io/fallback"
+	LabelFallbackOfKey = "sandbox.opensandbox.io/fallback-of"
+
+	// AnnoOverflowKey keeps the on-demand pods of a pooled BatchSandbox; see AllocationPolicy.OverflowPolicy.
+	AnnoOverflowKey = "sandbox.opensandbox.io/overflow"
+
 	FinalizerTaskCleanup    = "batch-sandbox.sandbox.opensandbox.io/task-cleanup"
 	FinalizerPoolAllocation = "pool.sandbox.opensandbox.io/pool-allocation"
+	// FinalizerPoolProtection keeps a deleted Pool until no BatchSandbox holds its pods.
+	FinalizerPoolProtection = "pool.sandbox.opensandbox.io/allocation-protection"
 )
 
 // AnnotationSandboxEndpoints Use the exported constant from pkg/utils
@@ -54,14 +126,32 @@ type PoolAllocation struct {
 	PodAllocation map[string]string `json:"podAllocation"`
 }
 
+// PropagatedMetadata lists the label and annotation keys copied from a BatchSandbox onto a pool pod.
+type PropagatedMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// SandboxOverflow lists the pods a pooled BatchSandbox created on demand while its pool was saturated.
+// Pods are never removed from Pods once released, so that the pods keep their index; Next numbers the
+// next pod, so that a name is not taken again while a deleted pod terminates.
+type SandboxOverflow struct {
+	Pods     []string `json:"pods,omitempty"`
+	Released []string `json:"released,omitempty"`
+	Next     int      `json:"next,omitempty"`
+}
+
+// SandboxFallback is the sandbox that serves the replicas of a pooled BatchSandbox its pool could not
+// supply, from the next fallback pool.
+type SandboxFallback struct {
+	Sandbox  string `json:"sandbox"`
+	Pool     string `json:"pool"`
+	Replicas int32  `json:"replicas"`
+}
+
 func parseSandboxAllocation(obj metav1.Object) (SandboxAllocation, error) {
-	ret := SandboxAllocation{}
-	if raw := obj.GetAnnotations()[AnnoAllocStatusKey]; raw != "" {
-		if err := json.Unmarshal([]byte(raw), &ret); err != nil {
-			return ret, err
-		}
-	}
-	return ret, nil
+	pods, err := allocationDecodes.decodePods(obj, AnnoAllocStatusKey)
+	return SandboxAllocation{Pods: pods}, err
 }
 
 func setSandboxAllocation(obj metav1.Object, alloc SandboxAllocation) {
@@ -72,11 +162,51 @@ func setSandboxAllocation(obj metav1.Object, alloc SandboxAllocation) {
 }
 
 func parseSandboxReleased(obj metav1.Object) (AllocationRelease, error) {
-	ret := AllocationRelease{}
-	if raw := obj.GetAnnotations()[AnnoAllocReleaseKey]; raw != "" {
-		if err := json.Unmarshal([]byte(raw), &ret); err != nil {
-			return ret, err
-		}
+	pods, err := allocationDecodes.decodePods(obj, AnnoAllocReleaseKey)
+	return AllocationRelease{Pods: pods}, err
+}
+
+// parseSandboxPreemption returns the preemption state of the sandbox, empty when it has none.
+func parseSandboxPreemption(obj metav1.Object) (algorithm.Preemption, error) {
+	var preemption algorithm.Preemption
+	value := obj.GetAnnotations()[AnnoPreemptionKey]
+	if value == "" {
+		return preemption, nil
+	}
+	err := json.Unmarshal([]byte(value), &preemption)
+	return preemption, err
+}
+
+// setSandboxPreemption records the preemption state of the sandbox, removing the annotation when it is empty.
+func setSandboxPreemption(obj metav1.Object, preemption *algorithm.Preemption) {
+	if len(preemption.Pods) == 0 && len(preemption.Preempted) == 0 {
+		delete(obj.GetAnnotations(), AnnoPreemptionKey)
+		return
+	}
+	if obj.GetAnnotations() == nil {
+		obj.SetAnnotations(map[string]string{})
+	}
+	obj.GetAnnotations()[AnnoPreemptionKey] = utils.DumpJSON(preemption)
+}
+
+// parseSandboxOverflow returns the on-demand pods of the sandbox, empty when it has none.
+func parseSandboxOverflow(obj metav1.Object) (SandboxOverflow, error) {
+	var overflow SandboxOverflow
+	value := obj.GetAnnotations()[AnnoOverflowKey]
+	if value == "" {
+		return overflow, nil
+	}
+	err := json.Unmarshal([]byte(value), &overflow)
+	return overflow, err
+}
+
+// parseSandboxFallback returns the fallback of the sandbox, empty when it has none.
+func parseSandboxFallback(obj metav1.Object) (SandboxFallback, error) {
+	var fallback SandboxFallback
+	value := obj.GetAnnotations()[AnnoFallbackKey]
+	if value == "" {
+		return fallback, nil
 	}
-	return ret, nil
+	err := json.Unmarshal([]byte(value), &fallback)
+	return fallback, err
 }
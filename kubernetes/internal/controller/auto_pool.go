@@ -0,0 +1,239 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+const (
+	// LabelTemplateHash is set on template BatchSandboxes considered for an auto pool to the hash of
+	// their template.
+	LabelTemplateHash = "sandbox.opensandbox.io/template-hash"
+	// LabelAutoPool is set on the Pools created by the auto pool mode to the hash of their template.
+	LabelAutoPool = "sandbox.opensandbox.io/auto-pool"
+	// AnnoAutoPoolKey is set to "false" on a template BatchSandbox to keep it out of auto pools. The
+	// controller sets it to the name of the auto pool a BatchSandbox was moved to.
+	AnnoAutoPoolKey = "sandbox.opensandbox.io/auto-pool"
+	// AnnoAutoPoolLastUsedKey records on an auto pool the last time a BatchSandbox with its template
+	// was created, as an RFC3339 timestamp.
+	AnnoAutoPoolLastUsedKey = "sandbox.opensandbox.io/auto-pool-last-used"
+
+	autoPoolNamePrefix = "auto-"
+)
+
+// AutoPoolReconciler derives Pools from the templates that template BatchSandboxes use repeatedly.
+// Once Threshold BatchSandboxes with the same template are created within Window in a namespace, it
+// creates a Pool for the template with a buffer of Buffer pods, and the BatchSandbox controller moves
+// the following BatchSandboxes with that template to the pool while it has pods available for them.
+// Auto pools that serve no BatchSandbox for IdleTimeout are deleted.
+type AutoPoolReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	// Threshold is the number of BatchSandboxes with the same template that creates a pool.
+	Threshold int
+	// Window is how far back BatchSandboxes count towards Threshold.
+	Window time.Duration
+	// Buffer is the bufferMin and bufferMax of the pools.
+	Buffer int32
+	// PoolMax is the poolMax of the pools.
+	PoolMax int32
+	// IdleTimeout is how long a pool is kept after the last BatchSandbox with its template, zero to keep
+	// pools forever.
+	IdleTimeout time.Duration
+}
+
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=pools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile deletes an auto pool once it has been idle for IdleTimeout and holds no allocated pods.
+func (r *AutoPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pool := &sandboxv1alpha1.Pool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if pool.Labels[LabelAutoPool] == "" || !pool.DeletionTimestamp.IsZero() || r.IdleTimeout <= 0 {
+		return ctrl.Result{}, nil
+	}
+	lastUsed := pool.CreationTimestamp.Time
+	if t, err := time.Parse(time.RFC3339, pool.Annotations[AnnoAutoPoolLastUsedKey]); err == nil && t.After(lastUsed) {
+		lastUsed = t
+	}
+	if idleFor := time.Since(lastUsed); idleFor < r.IdleTimeout {
+		return ctrl.Result{RequeueAfter: r.IdleTimeout - idleFor}, nil
+	}
+	if pool.Status.Allocated > 0 {
+		// Checked again when the pods are released.
+		return ctrl.Result{}, nil
+	}
+	logf.FromContext(ctx).Info("Deleting idle auto pool", "pool", pool.Name, "lastUsed", lastUsed)
+	r.Recorder.Eventf(pool, corev1.EventTypeNormal, "AutoPoolIdle", "Deleting auto pool unused since %s", lastUsed.Format(time.RFC3339))
+	return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, pool))
+}
+
+// route considers a newly created template BatchSandbox for an auto pool. It counts the BatchSandbox
+// towards its template, creates the pool of the template once the template reaches Threshold, and
+// moves the BatchSandbox to the pool when the pool has pods available for all of its replicas. It
+// returns whether the BatchSandbox was moved.
+func (r *AutoPoolReconciler) route(ctx context.Context, bs *sandboxv1alpha1.BatchSandbox) (bool, error) {
+	if !isAutoPoolCandidate(bs) {
+		return false, nil
+	}
+	log := logf.FromContext(ctx)
+	hash, err := templateHash(bs.Spec.Template)
+	if err != nil {
+		return false, err
+	}
+	// The label also marks the BatchSandbox as considered, so it is routed at most once.
+	patch := client.MergeFrom(bs.DeepCopy())
+	if bs.Labels == nil {
+		bs.Labels = make(map[string]string)
+	}
+	bs.Labels[LabelTemplateHash] = hash
+	if err := r.Patch(ctx, bs, patch); err != nil {
+		return false, err
+	}
+
+	pool := &sandboxv1alpha1.Pool{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: bs.Namespace, Name: autoPoolNamePrefix + hash}, pool)
+	if errors.IsNotFound(err) {
+		seen, err := r.countTemplate(ctx, bs.Namespace, hash)
+		if err != nil || seen < r.Threshold {
+			return false, err
+		}
+		pool = r.newAutoPool(bs, hash)
+		if err := r.Create(ctx, pool); err != nil {
+			return false, client.IgnoreAlreadyExists(err)
+		}
+		log.Info("Created auto pool", "pool", pool.Name, "seen", seen)
+		r.Recorder.Eventf(bs, corev1.EventTypeNormal, "AutoPoolCreated", "Created pool %s for its template, seen %d times", pool.Name, seen)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// A Pool of the same name that the auto pool mode did not create is left alone.
+	if pool.Labels[LabelAutoPool] != hash || !pool.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+	poolPatch := client.MergeFrom(pool.DeepCopy())
+	if pool.Annotations == nil {
+		pool.Annotations = make(map[string]string)
+	}
+	pool.Annotations[AnnoAutoPoolLastUsedKey] = time.Now().UTC().Format(time.RFC3339)
+	if err := r.Patch(ctx, pool, poolPatch); err != nil {
+		return false, err
+	}
+	// Waiting for the pool would be slower than creating the pods, so only a pool with pods for
+	// every replica takes the BatchSandbox.
+	if pool.Status.Available < *bs.Spec.Replicas {
+		return false, nil
+	}
+
+	patch = client.MergeFrom(bs.DeepCopy())
+	bs.Spec.PoolRef = pool.Name
+	bs.Spec.Template = nil
+	if bs.Annotations == nil {
+		bs.Annotations = make(map[string]string)
+	}
+	bs.Annotations[AnnoAutoPoolKey] = pool.Name
+	if err := r.Patch(ctx, bs, patch); err != nil {
+		return false, err
+	}
+	log.Info("Moved BatchSandbox to auto pool", "batchSandbox", bs.Name, "pool", pool.Name)
+	r.Recorder.Eventf(bs, corev1.EventTypeNormal, "AutoPooled", "Allocating from auto pool %s", pool.Name)
+	return true, nil
+}
+
+// isAutoPoolCandidate reports whether bs is a template BatchSandbox that has not been considered for
+// an auto pool and has not created pods yet. BatchSandboxes with shard patches use several templates.
+func isAutoPoolCandidate(bs *sandboxv1alpha1.BatchSandbox) bool {
+	return bs.Spec.Template != nil && bs.Spec.PoolRef == "" && len(bs.Spec.ShardPatches) == 0 &&
+		bs.Spec.Replicas != nil && *bs.Spec.Replicas > 0 && bs.DeletionTimestamp.IsZero() &&
+		bs.Labels[LabelTemplateHash] == "" && bs.Annotations[AnnoAutoPoolKey] != "false" &&
+		bs.Status.Phase == "" && bs.Status.Replicas == 0
+}
+
+// countTemplate returns the number of BatchSandboxes with the template hash created within Window.
+func (r *AutoPoolReconciler) countTemplate(ctx context.Context, namespace, hash string) (int, error) {
+	list := &sandboxv1alpha1.BatchSandboxList{}
+	if err := r.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{LabelTemplateHash: hash}); err != nil {
+		return 0, err
+	}
+	since := time.Now().Add(-r.Window)
+	seen := 0
+	for i := range list.Items {
+		if list.Items[i].CreationTimestamp.Time.After(since) {
+			seen++
+		}
+	}
+	return seen, nil
+}
+
+func (r *AutoPoolReconciler) newAutoPool(bs *sandboxv1alpha1.BatchSandbox, hash string) *sandboxv1alpha1.Pool {
+	return &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   bs.Namespace,
+			Name:        autoPoolNamePrefix + hash,
+			Labels:      map[string]string{LabelAutoPool: hash},
+			Annotations: map[string]string{AnnoAutoPoolLastUsedKey: time.Now().UTC().Format(time.RFC3339)},
+		},
+		Spec: sandboxv1alpha1.PoolSpec{
+			Template: bs.Spec.Template.DeepCopy(),
+			CapacitySpec: sandboxv1alpha1.CapacitySpec{
+				BufferMin: r.Buffer,
+				BufferMax: r.Buffer,
+				PoolMax:   max(r.PoolMax, r.Buffer),
+			},
+		},
+	}
+}
+
+// templateHash hashes a BatchSandbox template the way pool revisions are hashed.
+func templateHash(template *corev1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AutoPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isAutoPool := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[LabelAutoPool] != ""
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&sandboxv1alpha1.Pool{}, builder.WithPredicates(isAutoPool)).
+		Named("autopool").
+		Complete(r)
+}
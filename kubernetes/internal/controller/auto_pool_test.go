@@ -0,0 +1,139 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func newAutoPoolSandbox(name string) *sandboxv1alpha1.BatchSandbox {
+	replicas := int32(1)
+	return &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.Now()},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			Replicas: &replicas,
+			Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}},
+		},
+	}
+}
+
+func TestAutoPoolRoute(t *testing.T) {
+	ctx := context.Background()
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{newAutoPoolSandbox("bs-0"), newAutoPoolSandbox("bs-1"), newAutoPoolSandbox("bs-2")}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(sandboxes[0], sandboxes[1], sandboxes[2]).
+		WithStatusSubresource(&sandboxv1alpha1.Pool{}).Build()
+	r := &AutoPoolReconciler{Client: c, Recorder: record.NewFakeRecorder(10), Threshold: 2, Window: time.Hour, Buffer: 2, PoolMax: 5}
+	hash, err := templateHash(sandboxes[0].Spec.Template)
+	require.NoError(t, err)
+	poolKey := types.NamespacedName{Namespace: "default", Name: "auto-" + hash}
+
+	// The first BatchSandbox is below the threshold.
+	moved, err := r.route(ctx, sandboxes[0])
+	require.NoError(t, err)
+	assert.False(t, moved)
+	assert.Equal(t, hash, sandboxes[0].Labels[LabelTemplateHash])
+	assert.True(t, errors.IsNotFound(c.Get(ctx, poolKey, &sandboxv1alpha1.Pool{})))
+	moved, err = r.route(ctx, sandboxes[0])
+	require.NoError(t, err)
+	assert.False(t, moved, "a BatchSandbox is considered once")
+
+	// The second one creates the pool but does not wait for it.
+	moved, err = r.route(ctx, sandboxes[1])
+	require.NoError(t, err)
+	assert.False(t, moved)
+	pool := &sandboxv1alpha1.Pool{}
+	require.NoError(t, c.Get(ctx, poolKey, pool))
+	assert.Equal(t, hash, pool.Labels[LabelAutoPool])
+	assert.Equal(t, sandboxes[1].Spec.Template, pool.Spec.Template)
+	assert.Equal(t, sandboxv1alpha1.CapacitySpec{BufferMin: 2, BufferMax: 2, PoolMax: 5}, pool.Spec.CapacitySpec)
+
+	// The third one is moved to the warm pool.
+	pool.Status.Available = 2
+	require.NoError(t, c.Status().Update(ctx, pool))
+	moved, err = r.route(ctx, sandboxes[2])
+	require.NoError(t, err)
+	assert.True(t, moved)
+	latest := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(sandboxes[2]), latest))
+	assert.Equal(t, pool.Name, latest.Spec.PoolRef)
+	assert.Nil(t, latest.Spec.Template)
+	assert.Equal(t, pool.Name, latest.Annotations[AnnoAutoPoolKey])
+}
+
+func TestAutoPoolRouteSkipsPoolWithoutPods(t *testing.T) {
+	ctx := context.Background()
+	bs := newAutoPoolSandbox("bs")
+	hash, err := templateHash(bs.Spec.Template)
+	require.NoError(t, err)
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "auto-" + hash, Labels: map[string]string{LabelAutoPool: hash}},
+		Status:     sandboxv1alpha1.PoolStatus{Available: 1},
+	}
+	*bs.Spec.Replicas = 2
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(bs, pool).Build()
+	r := &AutoPoolReconciler{Client: c, Recorder: record.NewFakeRecorder(10), Threshold: 1, Window: time.Hour}
+
+	moved, err := r.route(ctx, bs)
+	require.NoError(t, err)
+	assert.False(t, moved, "the pool has one pod for two replicas")
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pool), pool))
+	assert.NotEmpty(t, pool.Annotations[AnnoAutoPoolLastUsedKey], "the demand keeps the pool")
+
+	optOut := newAutoPoolSandbox("opt-out")
+	optOut.Annotations = map[string]string{AnnoAutoPoolKey: "false"}
+	assert.False(t, isAutoPoolCandidate(optOut))
+}
+
+func TestAutoPoolReconcileDeletesIdlePools(t *testing.T) {
+	ctx := context.Background()
+	newPool := func(name string, lastUsed time.Time, allocated int32) *sandboxv1alpha1.Pool {
+		return &sandboxv1alpha1.Pool{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: name, Labels: map[string]string{LabelAutoPool: name},
+				Annotations: map[string]string{AnnoAutoPoolLastUsedKey: lastUsed.UTC().Format(time.RFC3339)},
+			},
+			Status: sandboxv1alpha1.PoolStatus{Allocated: allocated},
+		}
+	}
+	idle, busy, recent := newPool("idle", time.Now().Add(-2*time.Hour), 0), newPool("busy", time.Now().Add(-2*time.Hour), 1), newPool("recent", time.Now(), 0)
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(idle, busy, recent).Build()
+	r := &AutoPoolReconciler{Client: c, Recorder: record.NewFakeRecorder(10), IdleTimeout: time.Hour}
+
+	for _, name := range []string{"idle", "busy", "recent"} {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+		require.NoError(t, err)
+		if name == "recent" {
+			assert.InDelta(t, time.Hour, result.RequeueAfter, float64(time.Minute), "the pool is checked again once idle")
+		}
+	}
+	assert.True(t, errors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(idle), &sandboxv1alpha1.Pool{})))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(busy), &sandboxv1alpha1.Pool{}), "pools with allocated pods are kept")
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(recent), &sandboxv1alpha1.Pool{}))
+}
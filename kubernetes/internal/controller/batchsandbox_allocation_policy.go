@@ -0,0 +1,228 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+const defaultPoolWaitTimeout = 60 * time.Second
+
+// poolWaitTimeout returns how long a pooled sandbox waits for its pods, or false if it waits indefinitely.
+func poolWaitTimeout(batchSbx *sandboxv1alpha1.BatchSandbox) (time.Duration, bool) {
+	policy := batchSbx.Spec.AllocationPolicy
+	if batchSbx.Spec.PoolRef == "" || policy == nil || policy.WaitForPool == nil || *policy.WaitForPool {
+		return 0, false
+	}
+	if policy.TimeoutSeconds == nil {
+		return defaultPoolWaitTimeout, true
+	}
+	return time.Duration(*policy.TimeoutSeconds) * time.Second, true
+}
+
+// isGangAllocation reports whether the sandbox is allocated its pods all or nothing.
+func isGangAllocation(batchSbx *sandboxv1alpha1.BatchSandbox) bool {
+	policy := batchSbx.Spec.AllocationPolicy
+	return policy != nil && policy.Gang != nil && *policy.Gang
+}
+
+// requiresLatestRevision reports whether the sandbox only takes pods of the latest pool revision.
+func requiresLatestRevision(batchSbx *sandboxv1alpha1.BatchSandbox) bool {
+	policy := batchSbx.Spec.AllocationPolicy
+	return policy != nil && policy.Revision == sandboxv1alpha1.AllocationRevisionLatest
+}
+
+// scheduleRevisions serves the sandboxes that require the latest pool revision from the available pods of
+// that revision, and then the other sandboxes from the pods left. The former go first since they cannot use
+// any other pod. Without a known revision every sandbox is served from all available pods.
+func scheduleRevisions(ctx context.Context, algo algorithm.Algorithm, spec *AllocSpec, availablePods []string,
+	allRequest []*algorithm.SandboxRequest) *algorithm.AllocAction {
+	latest := make(map[string]bool)
+	for _, sandbox := range spec.Sandboxes {
+		if requiresLatestRevision(sandbox) {
+			latest[sandbox.Name] = true
+		}
+	}
+	if len(latest) == 0 || spec.Revision == "" {
+		return scheduleFlavors(ctx, algo, spec.Pool, spec.Pods, spec.Sandboxes, availablePods, allRequest)
+	}
+
+	podRevisions := make(map[string]string, len(spec.Pods))
+	for _, pod := range spec.Pods {
+		podRevisions[pod.Name] = pod.Labels[LabelPoolRevision]
+	}
+	var latestRequests, otherRequests []*algorithm.SandboxRequest
+	for _, request := range allRequest {
+		if latest[request.SandboxName] {
+			latestRequests = append(latestRequests, request)
+		} else {
+			otherRequests = append(otherRequests, request)
+		}
+	}
+	latestPods := slices.DeleteFunc(slices.Clone(availablePods), func(name string) bool {
+		return podRevisions[name] != spec.Revision
+	})
+
+	action := scheduleFlavors(ctx, algo, spec.Pool, spec.Pods, spec.Sandboxes, latestPods, latestRequests)
+	allocated := make(map[string]bool)
+	for _, pods := range action.ToAllocate {
+		for _, name := range pods {
+			allocated[name] = true
+		}
+	}
+	remaining := slices.DeleteFunc(slices.Clone(availablePods), func(name string) bool { return allocated[name] })
+	mergeAllocAction(action, scheduleFlavors(ctx, algo, spec.Pool, spec.Pods, spec.Sandboxes, remaining, otherRequests))
+	return action
+}
+
+// hasAllocationCondition reports whether the condition of the sandbox is true.
+func hasAllocationCondition(batchSbx *sandboxv1alpha1.BatchSandbox, conditionType sandboxv1alpha1.BatchSandboxConditionType) bool {
+	for _, cond := range batchSbx.Status.Conditions {
+		if cond.Type == conditionType {
+			return cond.Status == sandboxv1alpha1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isPoolExhausted reports whether the sandbox gave up waiting for pods from its pool, either at the
+// fail-fast timeout or at its allocation deadline.
+func isPoolExhausted(batchSbx *sandboxv1alpha1.BatchSandbox) bool {
+	return hasAllocationCondition(batchSbx, sandboxv1alpha1.BatchSandboxConditionPoolExhausted) ||
+		hasAllocationCondition(batchSbx, sandboxv1alpha1.BatchSandboxConditionUnschedulable)
+}
+
+// allocationShortfall returns the replicas of a pooled sandbox, and whether the sandbox still waits for some
+// of them in a phase where giving up applies.
+func allocationShortfall(batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus) (int32, bool) {
+	if batchSbx.DeletionTimestamp != nil {
+		return 0, false
+	}
+	switch status.Phase {
+	case sandboxv1alpha1.BatchSandboxPhasePending, sandboxv1alpha1.BatchSandboxPhaseSucceed:
+	default:
+		return 0, false
+	}
+	replicas := int32(0)
+	if batchSbx.Spec.Replicas != nil {
+		replicas = *batchSbx.Spec.Replicas
+	}
+	return replicas, status.Replicas < replicas
+}
+
+// applyAllocationPolicy fails a fail-fast pooled sandbox that is still short of pods once its allocation
+// timeout has passed. It returns the time left until the timeout, or zero if there is nothing to wait for.
+func applyAllocationPolicy(batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus, now time.Time) time.Duration {
+	timeout, ok := poolWaitTimeout(batchSbx)
+	if !ok {
+		return 0
+	}
+	replicas, short := allocationShortfall(batchSbx, status)
+	if !short {
+		return 0
+	}
+	if remaining := batchSbx.CreationTimestamp.Add(timeout).Sub(now); remaining > 0 {
+		return remaining
+	}
+	setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionPoolExhausted, sandboxv1alpha1.ConditionTrue, "AllocationTimeout",
+		fmt.Sprintf("allocated %d/%d pods from pool %s within %s", status.Replicas, replicas, batchSbx.Spec.PoolRef, timeout))
+	status.Phase = sandboxv1alpha1.BatchSandboxPhaseFailed
+	applyBatchSandboxPhaseConditions(status)
+	return 0
+}
+
+// allocationDeadline returns how long a pooled sandbox may wait for all of its replicas, or false if it has
+// no deadline.
+func allocationDeadline(batchSbx *sandboxv1alpha1.BatchSandbox) (time.Duration, bool) {
+	if batchSbx.Spec.PoolRef == "" || batchSbx.Spec.AllocationDeadlineSeconds == nil {
+		return 0, false
+	}
+	return time.Duration(*batchSbx.Spec.AllocationDeadlineSeconds) * time.Second, true
+}
+
+// applyAllocationDeadline fails a pooled sandbox that is still short of pods at its allocation deadline. It
+// returns the time left until the deadline, or zero if there is nothing to wait for.
+func applyAllocationDeadline(batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus, now time.Time) time.Duration {
+	deadline, ok := allocationDeadline(batchSbx)
+	if !ok {
+		return 0
+	}
+	replicas, short := allocationShortfall(batchSbx, status)
+	if !short {
+		return 0
+	}
+	if remaining := batchSbx.CreationTimestamp.Add(deadline).Sub(now); remaining > 0 {
+		return remaining
+	}
+	setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionUnschedulable, sandboxv1alpha1.ConditionTrue, "AllocationDeadlineExceeded",
+		fmt.Sprintf("allocated %d/%d pods from pool %s within %s", status.Replicas, replicas, batchSbx.Spec.PoolRef, deadline))
+	status.Phase = sandboxv1alpha1.BatchSandboxPhaseFailed
+	applyBatchSandboxPhaseConditions(status)
+	return 0
+}
+
+// countAllocationFailure counts a sandbox that has just given up waiting for pods of its pool, given the
+// persisted status.
+func countAllocationFailure(batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus) {
+	if isPoolExhausted(batchSbx) {
+		return
+	}
+	for _, cond := range status.Conditions {
+		if (cond.Type == sandboxv1alpha1.BatchSandboxConditionPoolExhausted || cond.Type == sandboxv1alpha1.BatchSandboxConditionUnschedulable) &&
+			cond.Status == sandboxv1alpha1.ConditionTrue {
+			poolAllocationFailures.WithLabelValues(batchSbx.Namespace, batchSbx.Spec.PoolRef, cond.Reason).Inc()
+			return
+		}
+	}
+}
+
+// deletesWhenUnschedulable reports whether the sandbox is to be deleted once it missed its allocation deadline.
+func deletesWhenUnschedulable(batchSbx *sandboxv1alpha1.BatchSandbox) bool {
+	return batchSbx.Spec.AllocationDeadlineAction == sandboxv1alpha1.AllocationDeadlineDelete
+}
+
+// handleUnschedulable reports a sandbox that just missed its allocation deadline through an event, and deletes
+// it with the Delete action. It runs once the status with the Unschedulable condition is persisted.
+func (r *BatchSandboxReconciler) handleUnschedulable(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus) error {
+	updated := &sandboxv1alpha1.BatchSandbox{Status: *status}
+	if !hasAllocationCondition(updated, sandboxv1alpha1.BatchSandboxConditionUnschedulable) {
+		return nil
+	}
+	if !hasAllocationCondition(batchSbx, sandboxv1alpha1.BatchSandboxConditionUnschedulable) {
+		for _, cond := range status.Conditions {
+			if cond.Type == sandboxv1alpha1.BatchSandboxConditionUnschedulable {
+				r.Recorder.Event(batchSbx, corev1.EventTypeWarning, string(sandboxv1alpha1.BatchSandboxConditionUnschedulable), cond.Message)
+			}
+		}
+	}
+	if !deletesWhenUnschedulable(batchSbx) || batchSbx.DeletionTimestamp != nil {
+		return nil
+	}
+	logf.FromContext(ctx).Info("batch sandbox missed its allocation deadline, delete")
+	if err := r.Delete(ctx, batchSbx); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete unschedulable batch sandbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+func TestApplyAllocationPolicy(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	failFast := &sandboxv1alpha1.AllocationPolicy{WaitForPool: ptr.To(false), TimeoutSeconds: ptr.To[int32](30)}
+	tests := []struct {
+		name      string
+		policy    *sandboxv1alpha1.AllocationPolicy
+		phase     sandboxv1alpha1.BatchSandboxPhase
+		allocated int32
+		elapsed   time.Duration
+		wantWait  time.Duration
+		wantPhase sandboxv1alpha1.BatchSandboxPhase
+	}{
+		{name: "no policy waits forever", phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "waitForPool true waits forever", policy: &sandboxv1alpha1.AllocationPolicy{WaitForPool: ptr.To(true)}, phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "before timeout requeues", policy: failFast, phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: 10 * time.Second, wantWait: 20 * time.Second, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "default timeout", policy: &sandboxv1alpha1.AllocationPolicy{WaitForPool: ptr.To(false)}, phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: 10 * time.Second, wantWait: 50 * time.Second, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "fully allocated", policy: failFast, phase: sandboxv1alpha1.BatchSandboxPhaseSucceed, allocated: 2, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhaseSucceed},
+		{name: "partially allocated after timeout", policy: failFast, phase: sandboxv1alpha1.BatchSandboxPhaseSucceed, allocated: 1, elapsed: time.Minute, wantPhase: sandboxv1alpha1.BatchSandboxPhaseFailed},
+		{name: "nothing allocated after timeout", policy: failFast, phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: 30 * time.Second, wantPhase: sandboxv1alpha1.BatchSandboxPhaseFailed},
+		{name: "paused sandbox is left alone", policy: failFast, phase: sandboxv1alpha1.BatchSandboxPhasePaused, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhasePaused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := &sandboxv1alpha1.BatchSandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "sbx", CreationTimestamp: metav1.NewTime(created)},
+				Spec:       sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To[int32](2), PoolRef: "pool", AllocationPolicy: tt.policy},
+			}
+			status := &sandboxv1alpha1.BatchSandboxStatus{Phase: tt.phase, Replicas: tt.allocated}
+			wait := applyAllocationPolicy(bs, status, created.Add(tt.elapsed))
+			assert.Equal(t, tt.wantWait, wait)
+			assert.Equal(t, tt.wantPhase, status.Phase)
+			bs.Status = *status
+			assert.Equal(t, tt.wantPhase == sandboxv1alpha1.BatchSandboxPhaseFailed, isPoolExhausted(bs))
+		})
+	}
+}
+
+func TestScheduleRevisions(t *testing.T) {
+	revisionPod := func(name, revision string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{LabelPoolRevision: revision}}}
+	}
+	latest := &sandboxv1alpha1.AllocationPolicy{Revision: sandboxv1alpha1.AllocationRevisionLatest}
+	spec := &AllocSpec{
+		Pool: &sandboxv1alpha1.Pool{},
+		Pods: []*corev1.Pod{revisionPod("old-0", "v1"), revisionPod("old-1", "v1"), revisionPod("new-0", "v2")},
+		Sandboxes: []*sandboxv1alpha1.BatchSandbox{
+			{ObjectMeta: metav1.ObjectMeta{Name: "any"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "latest"}, Spec: sandboxv1alpha1.BatchSandboxSpec{AllocationPolicy: latest}},
+		},
+		Revision: "v2",
+	}
+	requests := func() []*algorithm.SandboxRequest {
+		return []*algorithm.SandboxRequest{
+			{SandboxName: "any", PodSupplement: 2},
+			{SandboxName: "latest", PodSupplement: 2, ToRelease: []string{"gone"}},
+		}
+	}
+	available := []string{"new-0", "old-0", "old-1"}
+
+	action := scheduleRevisions(context.Background(), &algorithm.PackedSchedule{}, spec, available, requests())
+	assert.Equal(t, []string{"new-0"}, action.ToAllocate["latest"], "old pods are skipped")
+	assert.Equal(t, []string{"gone"}, action.ToRelease["latest"])
+	assert.Equal(t, []string{"old-0", "old-1"}, action.ToAllocate["any"])
+	assert.Equal(t, int32(1), action.PodSupplement, "a new pod is requested for the latest sandbox")
+
+	// Without a known revision the policy has nothing to select by.
+	spec.Revision = ""
+	action = scheduleRevisions(context.Background(), &algorithm.PackedSchedule{}, spec, available, requests())
+	assert.Equal(t, []string{"new-0", "old-0"}, action.ToAllocate["any"])
+	assert.Equal(t, []string{"old-1"}, action.ToAllocate["latest"])
+}
+
+func TestApplyAllocationDeadline(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		deadline  *int32
+		poolRef   string
+		phase     sandboxv1alpha1.BatchSandboxPhase
+		allocated int32
+		elapsed   time.Duration
+		wantWait  time.Duration
+		wantPhase sandboxv1alpha1.BatchSandboxPhase
+	}{
+		{name: "no deadline waits forever", poolRef: "pool", phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "not pooled", deadline: ptr.To[int32](60), phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "before deadline requeues", deadline: ptr.To[int32](60), poolRef: "pool", phase: sandboxv1alpha1.BatchSandboxPhasePending, elapsed: 15 * time.Second, wantWait: 45 * time.Second, wantPhase: sandboxv1alpha1.BatchSandboxPhasePending},
+		{name: "fully allocated", deadline: ptr.To[int32](60), poolRef: "pool", phase: sandboxv1alpha1.BatchSandboxPhaseSucceed, allocated: 2, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhaseSucceed},
+		{name: "partially allocated at deadline", deadline: ptr.To[int32](60), poolRef: "pool", phase: sandboxv1alpha1.BatchSandboxPhaseSucceed, allocated: 1, elapsed: time.Minute, wantPhase: sandboxv1alpha1.BatchSandboxPhaseFailed},
+		{name: "failed sandbox is left alone", deadline: ptr.To[int32](60), poolRef: "pool", phase: sandboxv1alpha1.BatchSandboxPhaseFailed, elapsed: time.Hour, wantPhase: sandboxv1alpha1.BatchSandboxPhaseFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := &sandboxv1alpha1.BatchSandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "sbx", CreationTimestamp: metav1.NewTime(created)},
+				Spec:       sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To[int32](2), PoolRef: tt.poolRef, AllocationDeadlineSeconds: tt.deadline},
+			}
+			status := &sandboxv1alpha1.BatchSandboxStatus{Phase: tt.phase, Replicas: tt.allocated}
+			wait := applyAllocationDeadline(bs, status, created.Add(tt.elapsed))
+			assert.Equal(t, tt.wantWait, wait)
+			assert.Equal(t, tt.wantPhase, status.Phase)
+			bs.Status = *status
+			unschedulable := tt.phase != sandboxv1alpha1.BatchSandboxPhaseFailed && tt.wantPhase == sandboxv1alpha1.BatchSandboxPhaseFailed
+			assert.Equal(t, unschedulable, hasAllocationCondition(bs, sandboxv1alpha1.BatchSandboxConditionUnschedulable))
+			assert.Equal(t, unschedulable, isPoolExhausted(bs), "the pool stops allocating to an unschedulable sandbox")
+		})
+	}
+}
+
+func TestHandleUnschedulable(t *testing.T) {
+	ctx := context.Background()
+	unschedulable := sandboxv1alpha1.BatchSandboxStatus{
+		Phase: sandboxv1alpha1.BatchSandboxPhaseFailed,
+		Conditions: []sandboxv1alpha1.BatchSandboxCondition{{
+			Type:    sandboxv1alpha1.BatchSandboxConditionUnschedulable,
+			Status:  sandboxv1alpha1.ConditionTrue,
+			Reason:  "AllocationDeadlineExceeded",
+			Message: "allocated 0/2 pods from pool pool within 1m0s",
+		}},
+	}
+	for _, action := range []sandboxv1alpha1.AllocationDeadlineAction{"", sandboxv1alpha1.AllocationDeadlineDelete} {
+		t.Run(string(action), func(t *testing.T) {
+			bs := &sandboxv1alpha1.BatchSandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default"},
+				Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool", AllocationDeadlineSeconds: ptr.To[int32](60), AllocationDeadlineAction: action},
+			}
+			c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(bs).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &BatchSandboxReconciler{Client: c, Recorder: recorder}
+
+			require.NoError(t, r.handleUnschedulable(ctx, bs, &unschedulable))
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, "Warning Unschedulable allocated 0/2 pods")
+
+			err := c.Get(ctx, client.ObjectKeyFromObject(bs), &sandboxv1alpha1.BatchSandbox{})
+			if action == sandboxv1alpha1.AllocationDeadlineDelete {
+				assert.True(t, errors.IsNotFound(err), "the sandbox is deleted")
+			} else {
+				assert.NoError(t, err, "the sandbox is kept")
+			}
+
+			// Once recorded, the condition is not reported again.
+			bs.Status = unschedulable
+			require.NoError(t, r.handleUnschedulable(ctx, bs, &unschedulable))
+			assert.Empty(t, recorder.Events)
+		})
+	}
+}
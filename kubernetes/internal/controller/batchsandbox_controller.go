@@ -35,13 +35,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/lifecycle"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
 	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
@@ -58,6 +62,9 @@ var (
 
 const batchSandboxFirstPodIndex = 0
 
+// dispatchRequeueDelay is how soon a BatchSandbox is reconciled again when its tasks were not all dispatched.
+const dispatchRequeueDelay = time.Second
+
 type taskScheduleResult struct {
 	Running, Failed, Succeed, Unknown, Pending int32
 }
@@ -70,6 +77,12 @@ type BatchSandboxReconciler struct {
 	taskSchedulers sync.Map
 	// ResumePullSecret is the K8s Secret name for pulling snapshot images during resume.
 	ResumePullSecret string
+	// AutoPool moves template BatchSandboxes to auto pools, nil to disable the auto pool mode.
+	AutoPool *AutoPoolReconciler
+	// Lifecycle receives TaskSucceeded and TaskFailed events, nil to send none.
+	Lifecycle lifecycle.Notifier
+
+	newFreezer func(pod *corev1.Pod) podFreezer
 }
 
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
@@ -77,6 +90,7 @@ type BatchSandboxReconciler struct {
 // +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes/finalizers,verbs=update
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=allocations,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -103,11 +117,18 @@ func (r *BatchSandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		return ctrl.Result{}, err
 	}
+	if err := r.refreshExpireTime(ctx, batchSbx); err != nil {
+		return ctrl.Result{}, err
+	}
 	// handle expire
 	if expireAt := batchSbx.Spec.ExpireTime; expireAt != nil {
 		now := time.Now()
 		if expireAt.Time.Before(now) {
-			if batchSbx.DeletionTimestamp == nil {
+			if until, held := debugHeldUntil(batchSbx, now); held && batchSbx.DeletionTimestamp == nil {
+				// Failed replicas stay up for debugging; the sandbox is deleted once the hold ends.
+				log.Info("batch sandbox expired, held for debugging", "expireAt", expireAt, "holdUntil", until)
+				DurationStore.Push(req.String(), until.Sub(now))
+			} else if batchSbx.DeletionTimestamp == nil {
 				log.Info("batch sandbox expired, delete", "expireAt", expireAt)
 				if err := r.Delete(ctx, batchSbx); err != nil {
 					if errors.IsNotFound(err) {
@@ -148,17 +169,36 @@ func (r *BatchSandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return result, err
 	}
 
+	if r.AutoPool != nil {
+		moved, err := r.AutoPool.route(ctx, batchSbx)
+		if err != nil {
+			// The BatchSandbox creates its own pods instead.
+			log.Error(err, "failed to route batch sandbox to an auto pool")
+		} else if moved {
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// dispatchPauseResume may patch BatchSandbox spec/state (for example resume detaches a pooled
 	// sandbox from its pool). Recompute strategies from the latest object before listing pods so
 	// normal reconciliation does not keep using a stale pre-dispatch view.
 	taskStrategy = strategy.NewTaskSchedulingStrategy(batchSbx)
 	poolStrategy := strategy.NewPoolStrategy(batchSbx)
 
+	if poolStrategy.IsPooledMode() {
+		if err := r.reconcileFallback(ctx, batchSbx); err != nil {
+			aggErrors = append(aggErrors, err)
+		}
+		if err := r.reconcileOverflow(ctx, batchSbx); err != nil {
+			aggErrors = append(aggErrors, err)
+		}
+	}
+
 	pods, err := r.listPods(ctx, poolStrategy, batchSbx)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to list pods %w", err)
 	}
-	podIndex, err := calPodIndex(poolStrategy, batchSbx, pods)
+	podIndex, err := calPodIndex(ctx, r.Client, poolStrategy, batchSbx, pods)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to cal pod index %w", err)
 	}
@@ -169,20 +209,46 @@ func (r *BatchSandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	// Normal mode owns pod lifecycle except while a sandbox is fully paused. In Paused, the
 	// snapshot-backed runtime is quiesced and pods must stay absent until resume rewrites the
 	// template images and transitions back through Resuming.
+	podsSettled := true
 	if !poolStrategy.IsPooledMode() && batchSbx.Status.Phase != sandboxv1alpha1.BatchSandboxPhasePaused {
-		err := r.scaleBatchSandbox(ctx, batchSbx, batchSbx.Spec.Template, pods)
+		podsSettled, err = r.scaleBatchSandbox(ctx, batchSbx, batchSbx.Spec.Template, pods)
 		if err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to scale batch sandbox %w", err)
 		}
 	}
 
+	if batchSbx.Status.Phase != sandboxv1alpha1.BatchSandboxPhasePaused {
+		if err := r.applyDefaultEgressPolicy(ctx, batchSbx, pods); err != nil {
+			aggErrors = append(aggErrors, err)
+		}
+	}
+
 	runtimeView := buildRuntimeView(batchSbx, pods)
+	syncUsage(runtimeView.status, ownPods(batchSbx, pods), poolStrategy.IsPooledMode(), time.Now())
+	if !podsSettled {
+		// The counters come from a pod list the scale has not caught up with yet; keep the previous
+		// generation so clients waiting on observedGeneration do not read them as final.
+		runtimeView.status.ObservedGeneration = batchSbx.Status.ObservedGeneration
+	}
+	if poolStrategy.IsPooledMode() {
+		if wait := applyAllocationPolicy(batchSbx, runtimeView.status, time.Now()); wait > 0 {
+			DurationStore.Push(req.String(), wait)
+		}
+		if wait := applyAllocationDeadline(batchSbx, runtimeView.status, time.Now()); wait > 0 {
+			DurationStore.Push(req.String(), wait)
+		}
+	}
 
 	if batchSbx.Status.Phase == sandboxv1alpha1.BatchSandboxPhasePaused {
 		r.deleteTaskScheduler(ctx, batchSbx)
 	}
 
-	if taskStrategy.NeedTaskScheduling() && batchSbx.Status.Phase != sandboxv1alpha1.BatchSandboxPhasePaused {
+	if err := r.reconcileFreeze(ctx, batchSbx, pods, runtimeView.status); err != nil {
+		aggErrors = append(aggErrors, err)
+	}
+
+	// Tasks are not dispatched to frozen pods; they would start running while the sandbox is frozen.
+	if taskStrategy.NeedTaskScheduling() && batchSbx.Status.Phase != sandboxv1alpha1.BatchSandboxPhasePaused && runtimeView.status.Frozen == 0 {
 		ts, err := r.reconcileTasks(ctx, batchSbx, pods)
 		if err != nil {
 			aggErrors = append(aggErrors, err)
@@ -194,23 +260,41 @@ func (r *BatchSandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			runtimeView.status.TaskPending = ts.Pending
 		}
 	}
+	if wait := applyDebugHold(batchSbx, runtimeView.status, time.Now()); wait > 0 {
+		DurationStore.Push(req.String(), wait)
+	}
 
-	aggErrors = append(aggErrors, r.persistRuntimeView(ctx, batchSbx, runtimeView)...)
+	persistErrors := r.persistRuntimeView(ctx, batchSbx, runtimeView)
+	aggErrors = append(aggErrors, persistErrors...)
+	if len(persistErrors) == 0 {
+		countAllocationFailure(batchSbx, runtimeView.status)
+		if err := r.handleUnschedulable(ctx, batchSbx, runtimeView.status); err != nil {
+			aggErrors = append(aggErrors, err)
+		}
+	}
 
 	return reconcile.Result{RequeueAfter: DurationStore.Pop(req.String())}, gerrors.Join(aggErrors...)
 }
 
-func calPodIndex(poolStrategy strategy.PoolStrategy, batchSbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) (map[string]int, error) {
+func calPodIndex(ctx context.Context, c client.Reader, poolStrategy strategy.PoolStrategy, batchSbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) (map[string]int, error) {
 	podIndex := map[string]int{}
 	if poolStrategy.IsPooledMode() {
 		// cal index from pool alloc result while using pooling
-		alloc, err := parseSandboxAllocation(batchSbx)
+		alloc, err := sandboxAllocation(ctx, c, batchSbx)
 		if err != nil {
 			return nil, err
 		}
 		for i := range alloc.Pods {
 			podIndex[alloc.Pods[i]] = i
 		}
+		// On-demand pods come after the pool pods.
+		overflow, err := parseSandboxOverflow(batchSbx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range overflow.Pods {
+			podIndex[overflow.Pods[i]] = len(alloc.Pods) + i
+		}
 	} else {
 		for i := range pods {
 			po := pods[i]
@@ -236,6 +320,8 @@ func (r *BatchSandboxReconciler) reconcileTasks(
 		return nil, err
 	}
 
+	sch.SetHoldFailed(holdsFailedTasks(batchSbx, time.Now()))
+
 	// Because tasks are in-memory and there is no event mechanism, periodic reconciliation is required.
 	DurationStore.Push(types.NamespacedName{Namespace: batchSbx.Namespace, Name: batchSbx.Name}.String(), 3*time.Second)
 
@@ -283,13 +369,19 @@ func (r *BatchSandboxReconciler) reconcileTasks(
 }
 
 func (r *BatchSandboxReconciler) listPods(ctx context.Context, poolStrategy strategy.PoolStrategy, batchSbx *sandboxv1alpha1.BatchSandbox) ([]*corev1.Pod, error) {
+	return listBatchSandboxPods(ctx, r.Client, poolStrategy, batchSbx)
+}
+
+// listBatchSandboxPods returns the active pods of the BatchSandbox: the allocated and not yet released
+// pool pods, on-demand pods and pods of fallback pools in pooled mode, or the owned pods otherwise.
+func listBatchSandboxPods(ctx context.Context, c client.Client, poolStrategy strategy.PoolStrategy, batchSbx *sandboxv1alpha1.BatchSandbox) ([]*corev1.Pod, error) {
 	var ret []*corev1.Pod
 	if poolStrategy.IsPooledMode() {
 		var (
 			allocSet    = make(sets.Set[string])
 			releasedSet = make(sets.Set[string])
 		)
-		alloc, err := parseSandboxAllocation(batchSbx)
+		alloc, err := sandboxAllocation(ctx, c, batchSbx)
 		if err != nil {
 			return nil, err
 		}
@@ -302,10 +394,15 @@ func (r *BatchSandboxReconciler) listPods(ctx context.Context, poolStrategy stra
 		releasedSet.Insert(released.Pods...)
 
 		activePods := allocSet.Difference(releasedSet)
+		overflow, err := parseSandboxOverflow(batchSbx)
+		if err != nil {
+			return nil, err
+		}
+		activePods.Insert(activeOverflowPods(overflow)...)
 		for name := range activePods {
 			pod := &corev1.Pod{}
 			// TODO maybe performance is problem
-			if err := r.Client.Get(ctx, types.NamespacedName{Namespace: batchSbx.Namespace, Name: name}, pod); err != nil {
+			if err := c.Get(ctx, types.NamespacedName{Namespace: batchSbx.Namespace, Name: name}, pod); err != nil {
 				if errors.IsNotFound(err) {
 					continue
 				}
@@ -313,9 +410,14 @@ func (r *BatchSandboxReconciler) listPods(ctx context.Context, poolStrategy stra
 			}
 			ret = append(ret, pod)
 		}
+		fallbackPods, err := listFallbackPods(ctx, c, batchSbx)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, fallbackPods...)
 	} else {
 		podList := &corev1.PodList{}
-		if err := r.Client.List(ctx, podList, &client.ListOptions{
+		if err := c.List(ctx, podList, &client.ListOptions{
 			Namespace:     batchSbx.Namespace,
 			FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForOwnerRefUID: string(batchSbx.UID)}),
 		}); err != nil {
@@ -348,6 +450,11 @@ func (r *BatchSandboxReconciler) getTaskScheduler(ctx context.Context, batchSbx
 		if err != nil {
 			return nil, fmt.Errorf("new task scheduler err %w", err)
 		}
+		placement, err := taskStrategy.GeneratePlacementPolicy()
+		if err != nil {
+			return nil, err
+		}
+		sc.SetPlacementPolicy(placement)
 		log.Info("successfully created task scheduler")
 		tSch = sc
 		r.taskSchedulers.Store(key, sc)
@@ -368,6 +475,11 @@ func (r *BatchSandboxReconciler) getTaskScheduler(ctx context.Context, batchSbx
 		if err := tSch.AddTasks(taskSpecs); err != nil {
 			return nil, fmt.Errorf("failed to add tasks on scale-out: %w", err)
 		}
+		placement, err := taskStrategy.GeneratePlacementPolicy()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate task placement policy: %w", err)
+		}
+		tSch.SetPlacementPolicy(placement)
 	}
 	return tSch, nil
 }
@@ -380,10 +492,24 @@ func (r *BatchSandboxReconciler) deleteTaskScheduler(ctx context.Context, batchS
 	}
 }
 
+// notifyTask sends the event of a completed task. The scheduler lists completed tasks on every
+// reconcile, so the event is notified again with the same ID until the sandbox is gone.
+func (r *BatchSandboxReconciler) notifyTask(batchSbx *sandboxv1alpha1.BatchSandbox, task taskscheduler.Task, typ lifecycle.EventType) {
+	if r.Lifecycle == nil {
+		return
+	}
+	notifyLifecycle(r.Lifecycle, typ, batchSbx, []string{task.GetPodName()}, task.GetName(), "", task.GetName())
+}
+
 func (r *BatchSandboxReconciler) scheduleTasks(ctx context.Context, tSch taskscheduler.TaskScheduler, batchSbx *sandboxv1alpha1.BatchSandbox) (*taskScheduleResult, error) {
 	log := logf.FromContext(ctx)
-	if err := tSch.Schedule(); err != nil {
-		return nil, err
+	if err := tSch.Schedule(ctx); err != nil {
+		if !gerrors.Is(err, taskscheduler.ErrDispatchIncomplete) {
+			return nil, err
+		}
+		// The tasks left over are dispatched by the next reconcile rather than holding up the worker.
+		log.Info("task dispatch incomplete, requeue", "reason", err.Error())
+		DurationStore.Push(types.NamespacedName{Namespace: batchSbx.Namespace, Name: batchSbx.Name}.String(), dispatchRequeueDelay)
 	}
 	tasks := tSch.ListTask()
 	toReleasedPods := []string{}
@@ -405,8 +531,10 @@ func (r *BatchSandboxReconciler) scheduleTasks(ctx context.Context, tSch tasksch
 				running++
 			case taskscheduler.SucceedTaskState:
 				succeed++
+				r.notifyTask(batchSbx, task, lifecycle.TaskSucceeded)
 			case taskscheduler.FailedTaskState:
 				failed++
+				r.notifyTask(batchSbx, task, lifecycle.TaskFailed)
 			case taskscheduler.UnknownTaskState:
 				unknown++
 			}
@@ -438,28 +566,70 @@ func (r *BatchSandboxReconciler) getTasksCleanupUnfinished(batchSbx *sandboxv1al
 	return notReleased
 }
 
+// releasePods hands pool pods back to the pool through the alloc-release annotation. On-demand pods are
+// marked released in the overflow annotation instead and deleted by reconcileOverflow, and pods of
+// fallback pools are released by the fallback sandbox.
 func (r *BatchSandboxReconciler) releasePods(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, toReleasePods []string) error {
-	releasedSet := make(sets.Set[string])
-	released, err := parseSandboxReleased(batchSbx)
+	overflow, err := parseSandboxOverflow(batchSbx)
 	if err != nil {
 		return err
 	}
-	releasedSet.Insert(released.Pods...)
-	releasedSet.Insert(toReleasePods...)
-	newRelease := AllocationRelease{
-		Pods: sets.List(releasedSet),
-	}
-	raw, err := json.Marshal(newRelease)
+	fallback, err := getFallbackSandbox(ctx, r.Client, batchSbx)
 	if err != nil {
-		return fmt.Errorf("Failed to marshal released pod names: %v", err)
+		return err
+	}
+	var alloc SandboxAllocation
+	if fallback != nil {
+		if alloc, err = sandboxAllocation(ctx, r.Client, batchSbx); err != nil {
+			return err
+		}
+	}
+	annotations := map[string]string{}
+	var poolPods, overflowPods, fallbackPods []string
+	for _, name := range toReleasePods {
+		switch {
+		case slices.Contains(overflow.Pods, name):
+			overflowPods = append(overflowPods, name)
+		case fallback != nil && !slices.Contains(alloc.Pods, name):
+			fallbackPods = append(fallbackPods, name)
+		default:
+			poolPods = append(poolPods, name)
+		}
+	}
+	if len(fallbackPods) > 0 {
+		if err := r.releasePods(ctx, fallback, fallbackPods); err != nil {
+			return err
+		}
+		if len(poolPods) == 0 && len(overflowPods) == 0 {
+			return nil
+		}
+	}
+	if len(overflowPods) > 0 {
+		overflow.Released = sets.List(sets.New(overflow.Released...).Insert(overflowPods...))
+		annotations[AnnoOverflowKey] = utils.DumpJSON(overflow)
+	}
+	if len(poolPods) > 0 || len(overflowPods) == 0 {
+		releasedSet := make(sets.Set[string])
+		released, err := parseSandboxReleased(batchSbx)
+		if err != nil {
+			return err
+		}
+		releasedSet.Insert(released.Pods...)
+		releasedSet.Insert(poolPods...)
+		newRelease := AllocationRelease{
+			Pods: sets.List(releasedSet),
+		}
+		raw, err := json.Marshal(newRelease)
+		if err != nil {
+			return fmt.Errorf("Failed to marshal released pod names: %v", err)
+		}
+		annotations[AnnoAllocReleaseKey] = string(raw)
 	}
 	body := utils.DumpJSON(struct {
 		MetaData metav1.ObjectMeta `json:"metadata"`
 	}{
 		MetaData: metav1.ObjectMeta{
-			Annotations: map[string]string{
-				AnnoAllocReleaseKey: string(raw),
-			},
+			Annotations: annotations,
 		},
 	})
 	b := &sandboxv1alpha1.BatchSandbox{
@@ -472,7 +642,9 @@ func (r *BatchSandboxReconciler) releasePods(ctx context.Context, batchSbx *sand
 }
 
 // Normal Mode
-func (r *BatchSandboxReconciler) scaleBatchSandbox(ctx context.Context, batchSandbox *sandboxv1alpha1.BatchSandbox, podTemplateSpec *corev1.PodTemplateSpec, pods []*corev1.Pod) error {
+// scaleBatchSandbox creates the missing pods. It reports whether pods already was the complete pod set of the
+// current spec, i.e. no scale was pending or issued.
+func (r *BatchSandboxReconciler) scaleBatchSandbox(ctx context.Context, batchSandbox *sandboxv1alpha1.BatchSandbox, podTemplateSpec *corev1.PodTemplateSpec, pods []*corev1.Pod) (bool, error) {
 	log := logf.FromContext(ctx)
 	indexedPodMap := map[int]*corev1.Pod{}
 	for i := range pods {
@@ -480,14 +652,14 @@ func (r *BatchSandboxReconciler) scaleBatchSandbox(ctx context.Context, batchSan
 		BatchSandboxScaleExpectations.ObserveScale(controllerutils.GetControllerKey(batchSandbox), expectations.Create, pod.Name)
 		idx, err := parseIndex(pod)
 		if err != nil {
-			return fmt.Errorf("failed to parse idx Pod %s, err %w", pod.Name, err)
+			return false, fmt.Errorf("failed to parse idx Pod %s, err %w", pod.Name, err)
 		}
 		indexedPodMap[idx] = pod
 	}
 	if satisfied, unsatisfiedDuration, dirtyPods := BatchSandboxScaleExpectations.SatisfiedExpectations(controllerutils.GetControllerKey(batchSandbox)); !satisfied {
 		log.Info("scale expectation is not satisfied", "unsatisfiedDuration", unsatisfiedDuration, "dirtyPods", dirtyPods)
 		DurationStore.Push(types.NamespacedName{Namespace: batchSandbox.Namespace, Name: batchSandbox.Name}.String(), expectations.ExpectationTimeout-unsatisfiedDuration)
-		return nil
+		return false, nil
 	}
 	// TODO consider supply Pods if Pods is deleted unexpectedly
 	var needCreateIndex []int
@@ -505,25 +677,25 @@ func (r *BatchSandboxReconciler) scaleBatchSandbox(ctx context.Context, batchSan
 	for _, idx := range needCreateIndex {
 		pod, err := utils.GetPodFromTemplate(podTemplateSpec, batchSandbox, metav1.NewControllerRef(batchSandbox, sandboxv1alpha1.SchemeBuilder.GroupVersion.WithKind("BatchSandbox")))
 		if err != nil {
-			return err
+			return false, err
 		}
 		// Apply shard patch if available for this index
 		if len(batchSandbox.Spec.ShardPatches) > 0 && idx < len(batchSandbox.Spec.ShardPatches) {
 			podBytes, err := json.Marshal(pod)
 			if err != nil {
-				return fmt.Errorf("failed to marshal pod: %w", err)
+				return false, fmt.Errorf("failed to marshal pod: %w", err)
 			}
 			patch := batchSandbox.Spec.ShardPatches[idx]
 			modifiedPodBytes, err := strategicpatch.StrategicMergePatch(podBytes, patch.Raw, &corev1.Pod{})
 			if err != nil {
-				return fmt.Errorf("failed to apply shard patch for index %d: %w", idx, err)
+				return false, fmt.Errorf("failed to apply shard patch for index %d: %w", idx, err)
 			}
 			if err := json.Unmarshal(modifiedPodBytes, pod); err != nil {
-				return fmt.Errorf("failed to unmarshal patched pod for index %d: %w", idx, err)
+				return false, fmt.Errorf("failed to unmarshal patched pod for index %d: %w", idx, err)
 			}
 		}
 		if err := ctrl.SetControllerReference(pod, batchSandbox, r.Scheme); err != nil {
-			return err
+			return false, err
 		}
 		pod.Labels[LabelBatchSandboxPodIndexKey] = strconv.Itoa(idx)
 		pod.Labels[LabelBatchSandboxNameKey] = batchSandbox.Name
@@ -533,11 +705,11 @@ func (r *BatchSandboxReconciler) scaleBatchSandbox(ctx context.Context, batchSan
 		if err := r.Create(ctx, pod); err != nil {
 			BatchSandboxScaleExpectations.ObserveScale(controllerutils.GetControllerKey(batchSandbox), expectations.Create, pod.Name)
 			r.Recorder.Eventf(batchSandbox, corev1.EventTypeWarning, "FailedCreate", "failed to create pod: %v, pod: %v", err, utils.DumpJSON(pod))
-			return err
+			return false, err
 		}
 		r.Recorder.Eventf(batchSandbox, corev1.EventTypeNormal, "SuccessfulCreate", "succeed to create pod %s", pod.Name)
 	}
-	return nil
+	return len(needCreateIndex) == 0, nil
 }
 
 func parseIndex(pod *corev1.Pod) (int, error) {
@@ -553,11 +725,32 @@ func parseIndex(pod *corev1.Pod) (int, error) {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *BatchSandboxReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) error {
+	// Task schedulers follow pods recreated under the same name through the endpoint cache, which is
+	// updated on pod events rather than on the next reconcile of the BatchSandbox.
+	podInformer, err := mgr.GetCache().GetInformer(context.Background(), &corev1.Pod{})
+	if err != nil {
+		return err
+	}
+	if _, err := podInformer.AddEventHandler(taskscheduler.PodEndpoints.EventHandler()); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sandboxv1alpha1.BatchSandbox{}).
 		Named("batchsandbox").
 		Owns(&corev1.Pod{}).
 		Owns(&sandboxv1alpha1.SandboxSnapshot{}).
+		Owns(&sandboxv1alpha1.BatchSandbox{}).
+		Owns(&sandboxv1alpha1.Allocation{}).
+		Watches(
+			&sandboxv1alpha1.ClusterEgressPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.findBatchSandboxesForEgressPolicy),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findBatchSandboxesForNamespace),
+			builder.WithPredicates(egressTierChanged),
+		).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		Complete(r)
 }
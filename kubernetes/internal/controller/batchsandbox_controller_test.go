@@ -29,6 +29,7 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -51,12 +52,14 @@ import (
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
 	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
 	mock_scheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler/mock"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
 )
 
 func init() {
 	testscheme = k8sruntime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(testscheme))
+	utilruntime.Must(appsv1.AddToScheme(testscheme))
 	utilruntime.Must(sandboxv1alpha1.AddToScheme(testscheme))
 }
 
@@ -157,7 +160,7 @@ var _ = Describe("BatchSandbox Controller", func() {
 					json.Unmarshal([]byte(raw), &gotIPs)
 				}
 
-				podIndex, err := calPodIndex(strategy.NewPoolStrategy(bs), bs, pods)
+				podIndex, err := calPodIndex(context.Background(), k8sClient, strategy.NewPoolStrategy(bs), bs, pods)
 				g.Expect(err).NotTo(HaveOccurred())
 				expectedIPs := make([]string, len(pods))
 				for _, pod := range pods {
@@ -636,7 +639,7 @@ func TestBatchSandboxReconciler_scheduleTasks(t *testing.T) {
 			args: args{
 				tSch: func() taskscheduler.TaskScheduler {
 					mockSche := mock_scheduler.NewMockTaskScheduler(ctrl)
-					mockSche.EXPECT().Schedule().Return(gerrors.New("err")).Times(1)
+					mockSche.EXPECT().Schedule(gomock.Any()).Return(gerrors.New("err")).Times(1)
 					return mockSche
 				}(),
 			},
@@ -650,7 +653,7 @@ func TestBatchSandboxReconciler_scheduleTasks(t *testing.T) {
 			args: args{
 				tSch: func() taskscheduler.TaskScheduler {
 					mockSche := mock_scheduler.NewMockTaskScheduler(ctrl)
-					mockSche.EXPECT().Schedule().Return(nil).Times(1)
+					mockSche.EXPECT().Schedule(gomock.Any()).Return(nil).Times(1)
 					mockTask := mock_scheduler.NewMockTask(ctrl)
 					mockTask.EXPECT().GetState().Return(taskscheduler.SucceedTaskState).Times(1)
 					mockTask.EXPECT().IsResourceReleased().Return(true).Times(1)
@@ -847,6 +850,44 @@ func Test_parseIndex(t *testing.T) {
 	}
 }
 
+func Test_scaleBatchSandboxSettled(t *testing.T) {
+	bs := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "settled", Namespace: "default", UID: types.UID("uid-settled")},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			Replicas: ptr.To(int32(2)),
+			Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}},
+		},
+	}
+	key := controllerutils.GetControllerKey(bs)
+	t.Cleanup(func() { BatchSandboxScaleExpectations.DeleteExpectations(key) })
+	c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+	r := &BatchSandboxReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	settled, err := r.scaleBatchSandbox(context.Background(), bs, bs.Spec.Template, nil)
+	if err != nil {
+		t.Fatalf("scaleBatchSandbox() error = %v", err)
+	}
+	if settled {
+		t.Errorf("scaleBatchSandbox() settled = true after creating pods")
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(context.Background(), podList); err != nil {
+		t.Fatal(err)
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	settled, err = r.scaleBatchSandbox(context.Background(), bs, bs.Spec.Template, pods)
+	if err != nil {
+		t.Fatalf("scaleBatchSandbox() error = %v", err)
+	}
+	if !settled || len(pods) != 2 {
+		t.Errorf("scaleBatchSandbox() settled = %v with %d pods, want true with 2", settled, len(pods))
+	}
+}
+
 func Test_calPodIndex(t *testing.T) {
 	type args struct {
 		batchSbx *sandboxv1alpha1.BatchSandbox
@@ -1077,7 +1118,7 @@ func Test_calPodIndex(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			poolStrategy := strategy.NewPoolStrategy(tt.args.batchSbx)
-			got, err := calPodIndex(poolStrategy, tt.args.batchSbx, tt.args.pods)
+			got, err := calPodIndex(context.Background(), fake.NewClientBuilder().WithScheme(testscheme).Build(), poolStrategy, tt.args.batchSbx, tt.args.pods)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("calPodIndex() error = %v, wantErr %v", err, tt.wantErr)
 				return
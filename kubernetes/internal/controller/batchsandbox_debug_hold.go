@@ -0,0 +1,119 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+const defaultDebugHold = time.Hour
+
+// debugHoldDuration returns how long spec.debugPolicy holds failed replicas, false without keepFailed.
+func debugHoldDuration(batchSbx *sandboxv1alpha1.BatchSandbox) (time.Duration, bool) {
+	policy := batchSbx.Spec.DebugPolicy
+	if policy == nil || !policy.KeepFailed {
+		return 0, false
+	}
+	if policy.HoldSeconds != nil {
+		return time.Duration(*policy.HoldSeconds) * time.Second, true
+	}
+	return defaultDebugHold, true
+}
+
+func debugHoldCondition(status *sandboxv1alpha1.BatchSandboxStatus) *sandboxv1alpha1.BatchSandboxCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == sandboxv1alpha1.BatchSandboxConditionDebugHold {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// debugHoldUntil returns when the hold recorded by the DebugHold condition ends, false unless the condition
+// is True.
+func debugHoldUntil(batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus) (time.Time, bool) {
+	hold, ok := debugHoldDuration(batchSbx)
+	cond := debugHoldCondition(status)
+	if !ok || cond == nil || cond.Status != sandboxv1alpha1.ConditionTrue || cond.LastTransitionTime == nil {
+		return time.Time{}, false
+	}
+	return cond.LastTransitionTime.Add(hold), true
+}
+
+// debugHeldUntil returns when the hold of the failed replicas of the sandbox ends, false if they are not held.
+// The sandbox is not deleted at its expireTime before.
+func debugHeldUntil(batchSbx *sandboxv1alpha1.BatchSandbox, now time.Time) (time.Time, bool) {
+	until, ok := debugHoldUntil(batchSbx, &batchSbx.Status)
+	if !ok || !now.Before(until) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// holdsFailedTasks reports whether the pods of failed tasks are kept from being released: with keepFailed,
+// from before the first failure until the hold has ended. Each sandbox is held once.
+func holdsFailedTasks(batchSbx *sandboxv1alpha1.BatchSandbox, now time.Time) bool {
+	if _, ok := debugHoldDuration(batchSbx); !ok || batchSbx.DeletionTimestamp != nil {
+		return false
+	}
+	if cond := debugHoldCondition(&batchSbx.Status); cond != nil {
+		_, held := debugHeldUntil(batchSbx, now)
+		return held
+	}
+	return true
+}
+
+// applyDebugHold starts the hold of failed replicas with the DebugHold condition once a task has failed, and
+// ends it with the condition False after the hold duration. It returns the time left of the hold, or zero
+// if there is nothing to wait for.
+func applyDebugHold(batchSbx *sandboxv1alpha1.BatchSandbox, status *sandboxv1alpha1.BatchSandboxStatus, now time.Time) time.Duration {
+	hold, ok := debugHoldDuration(batchSbx)
+	if !ok {
+		setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionDebugHold, sandboxv1alpha1.ConditionFalse, "", "")
+		return 0
+	}
+	cond := debugHoldCondition(status)
+	if cond == nil {
+		if status.TaskFailed == 0 || batchSbx.DeletionTimestamp != nil {
+			return 0
+		}
+		status.Conditions = append(status.Conditions, sandboxv1alpha1.BatchSandboxCondition{
+			Type:               sandboxv1alpha1.BatchSandboxConditionDebugHold,
+			Status:             sandboxv1alpha1.ConditionTrue,
+			Reason:             "TaskFailed",
+			Message:            fmt.Sprintf("Holding failed replicas for debugging until %s", now.Add(hold).UTC().Format(time.RFC3339)),
+			LastTransitionTime: ptr.To(metav1.NewTime(now)),
+		})
+		return hold
+	}
+	until, held := debugHoldUntil(batchSbx, status)
+	if !held {
+		return 0
+	}
+	if remaining := until.Sub(now); remaining > 0 {
+		return remaining
+	}
+	cond.Status = sandboxv1alpha1.ConditionFalse
+	cond.Reason = "HoldExpired"
+	cond.Message = fmt.Sprintf("Released failed replicas held since %s", cond.LastTransitionTime.UTC().Format(time.RFC3339))
+	cond.LastTransitionTime = ptr.To(metav1.NewTime(now))
+	return 0
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestApplyDebugHold(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	bs := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx"},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			DebugPolicy: &sandboxv1alpha1.DebugPolicy{KeepFailed: true, HoldSeconds: ptr.To[int32](600)},
+		},
+	}
+	assert.True(t, holdsFailedTasks(bs, start), "failed tasks are held from before the first failure")
+
+	status := bs.Status.DeepCopy()
+	assert.Zero(t, applyDebugHold(bs, status, start))
+	assert.Nil(t, debugHoldCondition(status), "nothing failed yet")
+
+	status.TaskFailed = 1
+	assert.Equal(t, 10*time.Minute, applyDebugHold(bs, status, start))
+	cond := debugHoldCondition(status)
+	require.NotNil(t, cond)
+	assert.Equal(t, sandboxv1alpha1.ConditionTrue, cond.Status)
+	assert.Equal(t, "TaskFailed", cond.Reason)
+	bs.Status = *status
+
+	now := start.Add(4 * time.Minute)
+	status = bs.Status.DeepCopy()
+	status.TaskFailed = 2
+	assert.Equal(t, 6*time.Minute, applyDebugHold(bs, status, now), "later failures do not extend the hold")
+	until, held := debugHeldUntil(bs, now)
+	assert.True(t, held)
+	assert.Equal(t, start.Add(10*time.Minute), until)
+	assert.True(t, holdsFailedTasks(bs, now))
+
+	now = start.Add(10 * time.Minute)
+	assert.Zero(t, applyDebugHold(bs, status, now))
+	cond = debugHoldCondition(status)
+	require.NotNil(t, cond)
+	assert.Equal(t, sandboxv1alpha1.ConditionFalse, cond.Status)
+	assert.Equal(t, "HoldExpired", cond.Reason)
+	bs.Status = *status
+	_, held = debugHeldUntil(bs, now)
+	assert.False(t, held, "an expired sandbox is deleted once the hold ends")
+	assert.False(t, holdsFailedTasks(bs, now), "each sandbox is held once")
+	assert.Zero(t, applyDebugHold(bs, status, now.Add(time.Hour)))
+	assert.Equal(t, sandboxv1alpha1.ConditionFalse, debugHoldCondition(status).Status)
+
+	bs.Spec.DebugPolicy = nil
+	assert.Zero(t, applyDebugHold(bs, status, now))
+	assert.Nil(t, debugHoldCondition(status), "the condition goes with the policy")
+	assert.False(t, holdsFailedTasks(bs, now))
+}
+
+func TestHoldsFailedTasks(t *testing.T) {
+	now := time.Now()
+	bs := &sandboxv1alpha1.BatchSandbox{}
+	assert.False(t, holdsFailedTasks(bs, now), "without a debug policy")
+	bs.Spec.DebugPolicy = &sandboxv1alpha1.DebugPolicy{}
+	assert.False(t, holdsFailedTasks(bs, now), "without keepFailed")
+
+	bs.Spec.DebugPolicy.KeepFailed = true
+	hold, ok := debugHoldDuration(bs)
+	assert.True(t, ok)
+	assert.Equal(t, defaultDebugHold, hold)
+	assert.True(t, holdsFailedTasks(bs, now))
+
+	bs.DeletionTimestamp = ptr.To(metav1.NewTime(now))
+	assert.False(t, holdsFailedTasks(bs, now), "deleting the sandbox ends the hold")
+	assert.Zero(t, applyDebugHold(bs, &sandboxv1alpha1.BatchSandboxStatus{TaskFailed: 1}, now))
+}
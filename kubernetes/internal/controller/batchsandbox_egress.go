@@ -0,0 +1,243 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+const (
+	// egressContainerName is the name of the egress sidecar container in sandbox pods.
+	egressContainerName = "egress"
+	// defaultEgressTier is the ClusterEgressPolicy used by namespaces without LabelEgressTier.
+	defaultEgressTier = "default"
+
+	egressRulesEnv      = "OPENSANDBOX_EGRESS_RULES"
+	egressPolicyFileEnv = "OPENSANDBOX_EGRESS_POLICY_FILE"
+	egressHTTPAddrEnv   = "OPENSANDBOX_EGRESS_HTTP_ADDR"
+	egressTokenEnv      = "OPENSANDBOX_EGRESS_TOKEN"
+	egressAuthHeader    = "OPENSANDBOX-EGRESS-AUTH"
+	egressDefaultPort   = "18080"
+)
+
+var egressPolicyHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=clusteregresspolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// applyDefaultEgressPolicy pushes the default egress policy of the namespace tier to the egress sidecar of every
+// ready pod of the BatchSandbox that doesn't declare its own policy, so that no sandbox runs with an open
+// egress sidecar. Each pod is pushed once per allocation and policy generation.
+func (r *BatchSandboxReconciler) applyDefaultEgressPolicy(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) error {
+	if batchSbx.DeletionTimestamp != nil {
+		return nil
+	}
+	targets := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && pod.Status.PodIP != "" && utils.IsPodReady(pod) && needsDefaultEgressPolicy(pod) {
+			targets = append(targets, pod)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	policy, err := r.defaultEgressPolicy(ctx, batchSbx.Namespace)
+	if err != nil || policy == nil {
+		return err
+	}
+	body, err := json.Marshal(&policy.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode egress policy %s: %w", policy.Name, err)
+	}
+
+	log := logf.FromContext(ctx)
+	stamp := fmt.Sprintf("%s/%s/%d", batchSbx.UID, policy.Name, policy.Generation)
+	var errs []error
+	for _, pod := range targets {
+		if pod.Annotations[AnnoEgressDefaultPolicyKey] == stamp {
+			continue
+		}
+		if err := pushEgressPolicy(ctx, pod, body); err != nil {
+			r.Recorder.Eventf(batchSbx, corev1.EventTypeWarning, "EgressPolicyPushFailed",
+				"failed to push default egress policy %s to pod %s: %v", policy.Name, pod.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		old := pod.DeepCopy()
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[AnnoEgressDefaultPolicyKey] = stamp
+		if err := r.Patch(ctx, pod, client.MergeFrom(old)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record egress policy on pod %s: %w", pod.Name, err))
+			continue
+		}
+		log.Info("pushed default egress policy", "pod", pod.Name, "policy", policy.Name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to apply default egress policy: %w", errs[0])
+	}
+	return nil
+}
+
+// defaultEgressPolicy returns the ClusterEgressPolicy of the namespace tier, or nil if there is none.
+func (r *BatchSandboxReconciler) defaultEgressPolicy(ctx context.Context, namespace string) (*sandboxv1alpha1.ClusterEgressPolicy, error) {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	tier := namespaceEgressTier(ns)
+	policy := &sandboxv1alpha1.ClusterEgressPolicy{}
+	if err := r.Get(ctx, types.NamespacedName{Name: tier}, policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cluster egress policy %s: %w", tier, err)
+	}
+	return policy, nil
+}
+
+// namespaceEgressTier returns the egress tier of the namespace, defaultEgressTier if it has no LabelEgressTier.
+func namespaceEgressTier(ns client.Object) string {
+	if tier := ns.GetLabels()[LabelEgressTier]; tier != "" {
+		return tier
+	}
+	return defaultEgressTier
+}
+
+// egressTierChanged passes the namespace updates that move a namespace to another egress tier.
+var egressTierChanged = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return namespaceEgressTier(e.ObjectOld) != namespaceEgressTier(e.ObjectNew)
+	},
+}
+
+// findBatchSandboxesForEgressPolicy maps a ClusterEgressPolicy to the BatchSandboxes in the namespaces of its
+// tier, so that a changed policy reaches the pods that are already running.
+func (r *BatchSandboxReconciler) findBatchSandboxesForEgressPolicy(ctx context.Context, obj client.Object) []reconcile.Request {
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range namespaces.Items {
+		if namespaceEgressTier(&namespaces.Items[i]) == obj.GetName() {
+			requests = append(requests, r.findBatchSandboxesForNamespace(ctx, &namespaces.Items[i])...)
+		}
+	}
+	return requests
+}
+
+// findBatchSandboxesForNamespace maps a namespace to its BatchSandboxes, so that a namespace moved to another
+// egress tier gets the policy of that tier.
+func (r *BatchSandboxReconciler) findBatchSandboxesForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	sandboxes := &sandboxv1alpha1.BatchSandboxList{}
+	if err := r.List(ctx, sandboxes, client.InNamespace(obj.GetName())); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(sandboxes.Items))
+	for i := range sandboxes.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&sandboxes.Items[i])})
+	}
+	return requests
+}
+
+// needsDefaultEgressPolicy reports whether the pod runs an egress sidecar that isn't started with a policy.
+func needsDefaultEgressPolicy(pod *corev1.Pod) bool {
+	c := egressContainer(pod)
+	if c == nil {
+		return false
+	}
+	for _, env := range c.Env {
+		if env.Name == egressRulesEnv || env.Name == egressPolicyFileEnv {
+			return false
+		}
+	}
+	return true
+}
+
+func egressContainer(pod *corev1.Pod) *corev1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == egressContainerName {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// pushEgressPolicy replaces the policy of the egress sidecar of the pod through its POST /policy API.
+func pushEgressPolicy(ctx context.Context, pod *corev1.Pod, body []byte) error {
+	c := egressContainer(pod)
+	port := egressDefaultPort
+	var token string
+	for _, env := range c.Env {
+		switch env.Name {
+		case egressHTTPAddrEnv:
+			if _, p, err := net.SplitHostPort(env.Value); err == nil && p != "" {
+				port = p
+			}
+		case egressTokenEnv:
+			if env.ValueFrom != nil {
+				return fmt.Errorf("%s of the egress sidecar must be a literal value", egressTokenEnv)
+			}
+			token = env.Value
+		}
+	}
+
+	url := fmt.Sprintf("http://%s/policy", net.JoinHostPort(pod.Status.PodIP, port))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(egressAuthHeader, token)
+	} else if bearer := api.BearerToken(); bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := egressPolicyHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("egress sidecar returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
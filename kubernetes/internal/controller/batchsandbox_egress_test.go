@@ -0,0 +1,170 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestApplyDefaultEgressPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	var pushed []sandboxv1alpha1.ClusterEgressPolicySpec
+	var tokens []string
+	sidecar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/policy", r.URL.Path)
+		raw, _ := io.ReadAll(r.Body)
+		spec := sandboxv1alpha1.ClusterEgressPolicySpec{}
+		assert.NoError(t, json.Unmarshal(raw, &spec))
+		pushed = append(pushed, spec)
+		tokens = append(tokens, r.Header.Get(egressAuthHeader))
+	}))
+	defer sidecar.Close()
+	sidecarURL, _ := url.Parse(sidecar.URL)
+
+	newPod := func(name string, env ...corev1.EnvVar) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tenant"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "sandbox"},
+				{Name: egressContainerName, Env: append(env, corev1.EnvVar{Name: egressHTTPAddrEnv, Value: ":" + sidecarURL.Port()})},
+			}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      sidecarURL.Hostname(),
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	open := newPod("open", corev1.EnvVar{Name: egressTokenEnv, Value: "secret"})
+	declared := newPod("declared", corev1.EnvVar{Name: egressRulesEnv, Value: `{"defaultAction":"allow"}`})
+	noSidecar := newPod("no-sidecar")
+	noSidecar.Spec.Containers = noSidecar.Spec.Containers[:1]
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: map[string]string{LabelEgressTier: "restricted"}}}
+	restricted := &sandboxv1alpha1.ClusterEgressPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "restricted", Generation: 1},
+		Spec: sandboxv1alpha1.ClusterEgressPolicySpec{
+			DefaultAction: "deny",
+			Egress:        []sandboxv1alpha1.EgressRule{{Action: "allow", Target: "*.pypi.org"}},
+		},
+	}
+	fallback := &sandboxv1alpha1.ClusterEgressPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultEgressTier, Generation: 1},
+		Spec:       sandboxv1alpha1.ClusterEgressPolicySpec{DefaultAction: "allow"},
+	}
+	bs := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "tenant", UID: "uid-1"}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).
+		WithObjects(ns, restricted, fallback, bs, open, declared, noSidecar).Build()
+	r := &BatchSandboxReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	assert.NoError(t, r.applyDefaultEgressPolicy(ctx, bs, []*corev1.Pod{open, declared, noSidecar}))
+	assert.Equal(t, []sandboxv1alpha1.ClusterEgressPolicySpec{restricted.Spec}, pushed)
+	assert.Equal(t, []string{"secret"}, tokens)
+	got := &corev1.Pod{}
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(open), got))
+	assert.Equal(t, "uid-1/restricted/1", got.Annotations[AnnoEgressDefaultPolicyKey])
+
+	// already pushed for this allocation
+	assert.NoError(t, r.applyDefaultEgressPolicy(ctx, bs, []*corev1.Pod{got}))
+	assert.Len(t, pushed, 1)
+
+	// unlabeled namespaces fall back to the default tier
+	ns.Labels = nil
+	assert.NoError(t, c.Update(ctx, ns))
+	other := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "tenant", UID: "uid-2"}}
+	assert.NoError(t, r.applyDefaultEgressPolicy(ctx, other, []*corev1.Pod{got}))
+	assert.Len(t, pushed, 2)
+	assert.Equal(t, fallback.Spec, pushed[1])
+}
+
+func TestDefaultEgressPolicy_RepushedOnChange(t *testing.T) {
+	ctx := context.Background()
+
+	var pushed []sandboxv1alpha1.ClusterEgressPolicySpec
+	sidecar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		spec := sandboxv1alpha1.ClusterEgressPolicySpec{}
+		assert.NoError(t, json.Unmarshal(raw, &spec))
+		pushed = append(pushed, spec)
+	}))
+	defer sidecar.Close()
+	sidecarURL, _ := url.Parse(sidecar.URL)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "tenant"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: egressContainerName, Env: []corev1.EnvVar{{Name: egressHTTPAddrEnv, Value: ":" + sidecarURL.Port()}}},
+		}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      sidecarURL.Hostname(),
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: map[string]string{LabelEgressTier: "restricted"}}}
+	otherNs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	policy := &sandboxv1alpha1.ClusterEgressPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "restricted", Generation: 1},
+		Spec:       sandboxv1alpha1.ClusterEgressPolicySpec{DefaultAction: "deny"},
+	}
+	bs := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "tenant", UID: "uid-1"}}
+	elsewhere := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "other"}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(ns, otherNs, policy, bs, elsewhere, pod).Build()
+	r := &BatchSandboxReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	assert.NoError(t, r.applyDefaultEgressPolicy(ctx, bs, []*corev1.Pod{pod}))
+	assert.Len(t, pushed, 1)
+
+	// An edited policy maps to the sandboxes of its tier only, and is pushed to their running pods again.
+	policy.Generation = 2
+	policy.Spec.Egress = []sandboxv1alpha1.EgressRule{{Action: "allow", Target: "*.pypi.org"}}
+	assert.NoError(t, c.Update(ctx, policy))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(bs)}},
+		r.findBatchSandboxesForEgressPolicy(ctx, policy))
+	got := &corev1.Pod{}
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pod), got))
+	assert.NoError(t, r.applyDefaultEgressPolicy(ctx, bs, []*corev1.Pod{got}))
+	assert.Len(t, pushed, 2)
+	assert.Equal(t, policy.Spec, pushed[1])
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pod), got))
+	assert.Equal(t, "uid-1/restricted/2", got.Annotations[AnnoEgressDefaultPolicyKey])
+
+	// Moving a namespace to another tier maps to its sandboxes.
+	relabeled := ns.DeepCopy()
+	relabeled.Labels[LabelEgressTier] = defaultEgressTier
+	assert.True(t, egressTierChanged.Update(event.UpdateEvent{ObjectOld: ns, ObjectNew: relabeled}))
+	assert.False(t, egressTierChanged.Update(event.UpdateEvent{ObjectOld: ns, ObjectNew: ns.DeepCopy()}))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(bs)}},
+		r.findBatchSandboxesForNamespace(ctx, ns))
+}
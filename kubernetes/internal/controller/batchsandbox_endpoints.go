@@ -0,0 +1,77 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// endpointPorts returns the named ports the pod declares in AnnoEndpointPortsKey. Entries that are
+// malformed or name a container port the pod does not have are skipped.
+func endpointPorts(pod *corev1.Pod) map[string]int32 {
+	value := pod.Annotations[AnnoEndpointPortsKey]
+	if value == "" {
+		return nil
+	}
+	ports := make(map[string]int32)
+	for _, entry := range strings.Split(value, ",") {
+		name, port, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name, port = strings.TrimSpace(name), strings.TrimSpace(port)
+		if !ok || name == "" || port == "" {
+			continue
+		}
+		if number, err := strconv.ParseInt(port, 10, 32); err == nil {
+			if number > 0 && number <= 65535 {
+				ports[name] = int32(number)
+			}
+			continue
+		}
+		if number, found := containerPort(pod, port); found {
+			ports[name] = number
+		}
+	}
+	return ports
+}
+
+func containerPort(pod *corev1.Pod, name string) (int32, bool) {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == name {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// podEndpoints returns the named endpoints of the pod as host:port, nil while it has no IP.
+func podEndpoints(pod *corev1.Pod) map[string]string {
+	if pod.Status.PodIP == "" {
+		return nil
+	}
+	ports := endpointPorts(pod)
+	if len(ports) == 0 {
+		return nil
+	}
+	endpoints := make(map[string]string, len(ports))
+	for name, port := range ports {
+		endpoints[name] = net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))
+	}
+	return endpoints
+}
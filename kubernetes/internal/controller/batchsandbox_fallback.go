@@ -0,0 +1,172 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+// planFallback returns how many replicas the fallback sandbox should take, given the replicas served from
+// the pool of the sandbox and on demand. The fallback only grows while the pool is saturated: replicas it
+// took keep their pods when the pool has room again.
+func planFallback(batchSbx *sandboxv1alpha1.BatchSandbox, current, served int32) int32 {
+	if len(batchSbx.Spec.FallbackPoolRefs) == 0 || batchSbx.Spec.Replicas == nil || isPoolExhausted(batchSbx) ||
+		!hasAllocationCondition(batchSbx, sandboxv1alpha1.BatchSandboxConditionPoolSaturated) ||
+		batchSbx.Status.Phase == sandboxv1alpha1.BatchSandboxPhasePaused {
+		return current
+	}
+	return max(current, *batchSbx.Spec.Replicas-served)
+}
+
+// reconcileFallback hands the replicas a saturated pool cannot supply to the fallback sandbox. The
+// replicas are recorded before the fallback sandbox is created or grown, so that the pool stops supplying
+// them. The fallback sandbox is owned by the sandbox and deleted along with it.
+func (r *BatchSandboxReconciler) reconcileFallback(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox) error {
+	fallback, err := parseSandboxFallback(batchSbx)
+	if err != nil {
+		return fmt.Errorf("failed to parse fallback: %w", err)
+	}
+	if (fallback.Sandbox == "" && len(batchSbx.Spec.FallbackPoolRefs) == 0) || !batchSbx.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	poolKept, err := poolKeptPods(ctx, r.Client, batchSbx)
+	if err != nil {
+		return err
+	}
+	overflow, err := parseSandboxOverflow(batchSbx)
+	if err != nil {
+		return fmt.Errorf("failed to parse on-demand pods: %w", err)
+	}
+	replicas := planFallback(batchSbx, fallback.Replicas, poolKept+int32(len(overflow.Pods)))
+	if replicas != fallback.Replicas {
+		if fallback.Sandbox == "" {
+			fallback.Sandbox = batchSbx.Name + "-fallback"
+			fallback.Pool = batchSbx.Spec.FallbackPoolRefs[0]
+		}
+		fallback.Replicas = replicas
+		if err := r.patchAnnotation(ctx, batchSbx, AnnoFallbackKey, utils.DumpJSON(fallback)); err != nil {
+			return fmt.Errorf("failed to record fallback: %w", err)
+		}
+	}
+	if fallback.Replicas == 0 {
+		return nil
+	}
+
+	child := &sandboxv1alpha1.BatchSandbox{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: batchSbx.Namespace, Name: fallback.Sandbox}, child)
+	if errors.IsNotFound(err) {
+		child = newFallbackSandbox(batchSbx, fallback)
+		if err := ctrl.SetControllerReference(batchSbx, child, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, child); err != nil && !errors.IsAlreadyExists(err) {
+			r.Recorder.Eventf(batchSbx, corev1.EventTypeWarning, "FailedCreate", "failed to create fallback sandbox %s: %v", fallback.Sandbox, err)
+			return err
+		}
+		r.Recorder.Eventf(batchSbx, corev1.EventTypeNormal, "FallbackPool", "pool %s is saturated, %d replicas fall back to pool %s",
+			batchSbx.Spec.PoolRef, fallback.Replicas, fallback.Pool)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(child, batchSbx) {
+		return fmt.Errorf("fallback sandbox %s is not owned by the sandbox", fallback.Sandbox)
+	}
+	if child.Spec.Replicas != nil && *child.Spec.Replicas >= fallback.Replicas {
+		return nil
+	}
+	patch := client.MergeFrom(child.DeepCopy())
+	child.Spec.Replicas = ptr.To(fallback.Replicas)
+	return r.Patch(ctx, child, patch)
+}
+
+// newFallbackSandbox returns the sandbox that allocates the replicas of the fallback from its pool. It
+// falls back in turn to the pools listed after that one, and gets no tasks: they are dispatched by the
+// sandbox it serves.
+func newFallbackSandbox(batchSbx *sandboxv1alpha1.BatchSandbox, fallback SandboxFallback) *sandboxv1alpha1.BatchSandbox {
+	var next []string
+	if i := slices.Index(batchSbx.Spec.FallbackPoolRefs, fallback.Pool); i >= 0 {
+		next = slices.Clone(batchSbx.Spec.FallbackPoolRefs[i+1:])
+	}
+	return &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fallback.Sandbox,
+			Namespace: batchSbx.Namespace,
+			Labels:    map[string]string{LabelFallbackOfKey: batchSbx.Name},
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			Replicas:              ptr.To(fallback.Replicas),
+			PoolRef:               fallback.Pool,
+			FallbackPoolRefs:      next,
+			Flavor:                batchSbx.Spec.Flavor,
+			AllocationPolicy:      batchSbx.Spec.AllocationPolicy.DeepCopy(),
+			AllocationConstraints: batchSbx.Spec.AllocationConstraints.DeepCopy(),
+		},
+	}
+}
+
+// getFallbackSandbox returns the fallback sandbox of the sandbox, nil when it has none.
+func getFallbackSandbox(ctx context.Context, c client.Reader, batchSbx *sandboxv1alpha1.BatchSandbox) (*sandboxv1alpha1.BatchSandbox, error) {
+	fallback, err := parseSandboxFallback(batchSbx)
+	if err != nil || fallback.Sandbox == "" {
+		return nil, err
+	}
+	child := &sandboxv1alpha1.BatchSandbox{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: batchSbx.Namespace, Name: fallback.Sandbox}, child); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !metav1.IsControlledBy(child, batchSbx) {
+		return nil, nil
+	}
+	return child, nil
+}
+
+// listFallbackPods returns the active pods of the fallback sandbox and of its own fallbacks.
+func listFallbackPods(ctx context.Context, c client.Client, batchSbx *sandboxv1alpha1.BatchSandbox) ([]*corev1.Pod, error) {
+	child, err := getFallbackSandbox(ctx, c, batchSbx)
+	if err != nil || child == nil {
+		return nil, err
+	}
+	return listBatchSandboxPods(ctx, c, strategy.NewPoolStrategy(child), child)
+}
+
+// ownPods drops the pods of fallback sandboxes, which record their own usage.
+func ownPods(batchSbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) []*corev1.Pod {
+	own := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if to := pod.Labels[LabelAllocatedTo]; to == "" || to == batchSbx.Name {
+			own = append(own, pod)
+		}
+	}
+	return own
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+func fallbackSandbox(replicas int32, saturated bool) *sandboxv1alpha1.BatchSandbox {
+	bs := overflowSandbox(replicas, saturated)
+	bs.Spec.AllocationPolicy = nil
+	bs.Spec.FallbackPoolRefs = []string{"zone-b", "shared"}
+	return bs
+}
+
+func TestPlanFallback(t *testing.T) {
+	assert.Equal(t, int32(2), planFallback(fallbackSandbox(3, true), 0, 1))
+	assert.Equal(t, int32(2), planFallback(fallbackSandbox(3, true), 2, 3), "replicas taken by the fallback keep their pods")
+	assert.Equal(t, int32(0), planFallback(fallbackSandbox(3, false), 0, 1), "only a saturated pool falls back")
+	assert.Equal(t, int32(0), planFallback(overflowSandbox(3, true), 0, 1), "without fallback pools the sandbox waits")
+}
+
+func TestReconcileFallback(t *testing.T) {
+	ctx := context.Background()
+	bs := fallbackSandbox(3, true)
+	bs.Spec.Flavor = "gpu"
+	setSandboxAllocation(bs, SandboxAllocation{Pods: []string{"pool-pod"}})
+	poolPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pool-pod", Namespace: "default", Labels: map[string]string{LabelAllocatedTo: "sbx"}}}
+	fallbackPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "zone-b-pod", Namespace: "default", Labels: map[string]string{LabelAllocatedTo: "sbx-fallback"}}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(bs, poolPod, fallbackPod).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &BatchSandboxReconciler{Client: c, Scheme: testscheme, Recorder: recorder}
+
+	require.NoError(t, r.reconcileFallback(ctx, bs))
+	assert.Contains(t, <-recorder.Events, "2 replicas fall back to pool zone-b")
+	fallback, err := parseSandboxFallback(bs)
+	require.NoError(t, err)
+	assert.Equal(t, SandboxFallback{Sandbox: "sbx-fallback", Pool: "zone-b", Replicas: 2}, fallback)
+
+	child := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sbx-fallback"}, child))
+	assert.True(t, metav1.IsControlledBy(child, bs))
+	assert.Equal(t, "sbx", child.Labels[LabelFallbackOfKey])
+	assert.Equal(t, ptr.To[int32](2), child.Spec.Replicas)
+	assert.Equal(t, "zone-b", child.Spec.PoolRef)
+	assert.Equal(t, []string{"shared"}, child.Spec.FallbackPoolRefs, "the fallback sandbox falls back to the remaining pools")
+	assert.Equal(t, "gpu", child.Spec.Flavor)
+	assert.Nil(t, child.Spec.TaskTemplate)
+
+	// The pods of the fallback pool serve the sandbox.
+	setSandboxAllocation(child, SandboxAllocation{Pods: []string{"zone-b-pod"}})
+	require.NoError(t, c.Update(ctx, child))
+	pods, err := listBatchSandboxPods(ctx, c, strategy.NewPoolStrategy(bs), bs)
+	require.NoError(t, err)
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"pool-pod", "zone-b-pod"}, names)
+	assert.Equal(t, []*corev1.Pod{pods[0]}, ownPods(bs, pods[:1]))
+	assert.Empty(t, ownPods(bs, pods[1:]), "fallback pods are accounted by the fallback sandbox")
+
+	// And are released to their own pool.
+	require.NoError(t, r.releasePods(ctx, bs, []string{"zone-b-pod"}))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(child), child))
+	released, err := parseSandboxReleased(child)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone-b-pod"}, released.Pods)
+	latest := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(bs), latest))
+	assert.NotContains(t, latest.Annotations, AnnoAllocReleaseKey)
+}
+
+func TestGetSandboxRequest_CountsFallbackReplicas(t *testing.T) {
+	sandbox := fallbackSandbox(3, true)
+	sandbox.Annotations = map[string]string{
+		AnnoAllocStatusKey: utils.DumpJSON(SandboxAllocation{Pods: []string{"p1"}}),
+		AnnoFallbackKey:    utils.DumpJSON(SandboxFallback{Sandbox: "sbx-fallback", Pool: "zone-b", Replicas: 1}),
+	}
+	allocator := NewDefaultAllocator(fake.NewClientBuilder().WithScheme(testscheme).Build(), record.NewFakeRecorder(10)).(*defaultAllocator)
+
+	req, err := allocator.getSandboxRequest(context.Background(), sandbox)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), req.PodSupplement, "the pool does not supply the replicas handed to a fallback pool")
+}
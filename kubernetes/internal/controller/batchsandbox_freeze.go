@@ -0,0 +1,111 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+// podFreezer is the part of the task-executor client used to freeze and thaw the tasks of a pod.
+type podFreezer interface {
+	Freeze(ctx context.Context) (*api.FreezeResponse, error)
+	Thaw(ctx context.Context) (*api.FreezeResponse, error)
+}
+
+func (r *BatchSandboxReconciler) freezerFor(pod *corev1.Pod) podFreezer {
+	if r.newFreezer != nil {
+		return r.newFreezer(pod)
+	}
+	return api.NewClient(taskscheduler.TaskExecutorEndpoint(pod.Status.PodIP))
+}
+
+// freezeRequested reports whether the tasks of the sandbox should be frozen. A snapshot pause owns
+// the pods while it runs, so spec.freeze only applies outside of it.
+func freezeRequested(batchSbx *sandboxv1alpha1.BatchSandbox) bool {
+	if batchSbx.Spec.Freeze == nil || !*batchSbx.Spec.Freeze {
+		return false
+	}
+	switch batchSbx.Status.Phase {
+	case sandboxv1alpha1.BatchSandboxPhasePausing, sandboxv1alpha1.BatchSandboxPhasePaused, sandboxv1alpha1.BatchSandboxPhaseResuming:
+		return false
+	}
+	return true
+}
+
+// reconcileFreeze freezes or thaws the tasks of the pods as requested by spec.freeze and records the
+// result in status. Pods are only contacted until status matches the request: a pod joining a frozen
+// sandbox is frozen on the next pass, and a thaw is sent while any pod may still be frozen.
+func (r *BatchSandboxReconciler) reconcileFreeze(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, status *sandboxv1alpha1.BatchSandboxStatus) error {
+	log := logf.FromContext(ctx)
+	freeze := freezeRequested(batchSbx)
+
+	serving := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && pod.Status.PodIP != "" {
+			serving = append(serving, pod)
+		}
+	}
+
+	if !freeze {
+		if status.Frozen == 0 {
+			setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionFrozen, sandboxv1alpha1.ConditionFalse, "", "")
+			return nil
+		}
+		var errs []error
+		for _, pod := range serving {
+			if _, err := r.freezerFor(pod).Thaw(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to thaw pod %s: %w", pod.Name, err))
+			}
+		}
+		if len(errs) > 0 {
+			return gerrors.Join(errs...)
+		}
+		log.Info("Thawed BatchSandbox tasks", "pods", len(serving))
+		status.Frozen = 0
+		setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionFrozen, sandboxv1alpha1.ConditionFalse, "", "")
+		return nil
+	}
+
+	if int(status.Frozen) == len(serving) && len(serving) > 0 {
+		return nil
+	}
+	var frozen int32
+	var errs []error
+	for _, pod := range serving {
+		if _, err := r.freezerFor(pod).Freeze(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to freeze pod %s: %w", pod.Name, err))
+			continue
+		}
+		frozen++
+	}
+	status.Frozen = frozen
+	if len(errs) > 0 || frozen == 0 {
+		setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionFrozen, sandboxv1alpha1.ConditionFalse, "", "")
+		return gerrors.Join(errs...)
+	}
+	log.Info("Froze BatchSandbox tasks", "pods", frozen)
+	setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionFrozen, sandboxv1alpha1.ConditionTrue, "Frozen",
+		fmt.Sprintf("tasks of %d pods are frozen", frozen))
+	return nil
+}
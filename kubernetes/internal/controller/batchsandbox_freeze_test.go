@@ -0,0 +1,115 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+type fakePodFreezer struct {
+	frozen map[string]bool
+	calls  int
+	fail   bool
+	pod    string
+}
+
+func (f *fakePodFreezer) Freeze(context.Context) (*api.FreezeResponse, error) {
+	f.calls++
+	if f.fail {
+		return nil, errors.New("unreachable")
+	}
+	f.frozen[f.pod] = true
+	return &api.FreezeResponse{}, nil
+}
+
+func (f *fakePodFreezer) Thaw(context.Context) (*api.FreezeResponse, error) {
+	f.calls++
+	if f.fail {
+		return nil, errors.New("unreachable")
+	}
+	delete(f.frozen, f.pod)
+	return &api.FreezeResponse{}, nil
+}
+
+func hasCondition(status *sandboxv1alpha1.BatchSandboxStatus, conditionType sandboxv1alpha1.BatchSandboxConditionType) bool {
+	for _, cond := range status.Conditions {
+		if cond.Type == conditionType && cond.Status == sandboxv1alpha1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReconcileFreeze(t *testing.T) {
+	ctx := context.Background()
+	freezer := &fakePodFreezer{frozen: map[string]bool{}}
+	r := &BatchSandboxReconciler{newFreezer: func(pod *corev1.Pod) podFreezer {
+		freezer.pod = pod.Name
+		return freezer
+	}}
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "p0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unscheduled"}},
+	}
+	bs := &sandboxv1alpha1.BatchSandbox{
+		Spec:   sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To[int32](3)},
+		Status: sandboxv1alpha1.BatchSandboxStatus{Phase: sandboxv1alpha1.BatchSandboxPhaseSucceed},
+	}
+
+	// Nothing is sent while freeze is not requested.
+	assert.NoError(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	assert.Zero(t, freezer.calls)
+
+	bs.Spec.Freeze = ptr.To(true)
+	freezer.fail = true
+	assert.Error(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	assert.False(t, hasCondition(&bs.Status, sandboxv1alpha1.BatchSandboxConditionFrozen))
+
+	freezer.fail = false
+	assert.NoError(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	assert.Equal(t, map[string]bool{"p0": true, "p1": true}, freezer.frozen)
+	assert.Equal(t, int32(2), bs.Status.Frozen)
+	assert.True(t, hasCondition(&bs.Status, sandboxv1alpha1.BatchSandboxConditionFrozen))
+
+	// Frozen pods are not contacted again.
+	calls := freezer.calls
+	assert.NoError(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	assert.Equal(t, calls, freezer.calls)
+
+	// A snapshot pause takes over from the freeze.
+	bs.Status.Phase = sandboxv1alpha1.BatchSandboxPhasePausing
+	assert.NoError(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	assert.Empty(t, freezer.frozen)
+	assert.Zero(t, bs.Status.Frozen)
+	assert.False(t, hasCondition(&bs.Status, sandboxv1alpha1.BatchSandboxConditionFrozen))
+
+	bs.Status.Phase = sandboxv1alpha1.BatchSandboxPhaseSucceed
+	assert.NoError(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	bs.Spec.Freeze = ptr.To(false)
+	assert.NoError(t, r.reconcileFreeze(ctx, bs, pods, &bs.Status))
+	assert.Empty(t, freezer.frozen)
+	assert.Zero(t, bs.Status.Frozen)
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// refreshExpireTime moves spec.expireTime forward according to the heartbeat annotation of the BatchSandbox.
+// A malformed heartbeat is reported as an event and otherwise ignored, so it can never shorten or
+// indefinitely extend the sandbox lifetime.
+func (r *BatchSandboxReconciler) refreshExpireTime(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox) error {
+	if batchSbx.DeletionTimestamp != nil {
+		return nil
+	}
+	expireAt, err := heartbeatExpireTime(batchSbx, time.Now())
+	if err != nil {
+		r.Recorder.Eventf(batchSbx, corev1.EventTypeWarning, "InvalidHeartbeat", "ignoring heartbeat: %v", err)
+		return nil
+	}
+	if expireAt == nil {
+		return nil
+	}
+	old := batchSbx.DeepCopy()
+	batchSbx.Spec.ExpireTime = expireAt
+	if err := r.Patch(ctx, batchSbx, client.MergeFrom(old)); err != nil {
+		return fmt.Errorf("failed to refresh expireTime from heartbeat: %w", err)
+	}
+	logf.FromContext(ctx).Info("refreshed expireTime from heartbeat", "expireAt", expireAt)
+	return nil
+}
+
+// heartbeatExpireTime returns the expire time implied by the heartbeat annotation, or nil if heartbeats are
+// disabled, absent or would not extend the current expire time. Heartbeats from the future are clamped to now.
+func heartbeatExpireTime(batchSbx *sandboxv1alpha1.BatchSandbox, now time.Time) (*metav1.Time, error) {
+	timeout := batchSbx.Spec.HeartbeatTimeoutSeconds
+	raw, ok := batchSbx.Annotations[AnnoHeartbeatKey]
+	if timeout == nil || *timeout <= 0 || !ok {
+		return nil, nil
+	}
+	heartbeat, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s=%q is not an RFC3339 timestamp", AnnoHeartbeatKey, raw)
+	}
+	if heartbeat.After(now) {
+		heartbeat = now
+	}
+	// metav1.Time is serialized with second precision
+	expireAt := metav1.NewTime(heartbeat.Add(time.Duration(*timeout) * time.Second).Truncate(time.Second))
+	if cur := batchSbx.Spec.ExpireTime; cur != nil && !expireAt.After(cur.Time) {
+		return nil, nil
+	}
+	return &expireAt, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestHeartbeatExpireTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.Time {
+		v := metav1.NewTime(now.Add(d))
+		return &v
+	}
+	tests := []struct {
+		name       string
+		timeout    *int32
+		heartbeat  string
+		expireTime *metav1.Time
+		want       *metav1.Time
+		wantErr    bool
+	}{
+		{name: "heartbeat disabled", heartbeat: now.Format(time.RFC3339)},
+		{name: "no heartbeat", timeout: ptr.To[int32](60)},
+		{name: "malformed heartbeat", timeout: ptr.To[int32](60), heartbeat: "yesterday", wantErr: true},
+		{name: "extends expire time", timeout: ptr.To[int32](600), heartbeat: now.Format(time.RFC3339), expireTime: at(time.Minute), want: at(10 * time.Minute)},
+		{name: "sets missing expire time", timeout: ptr.To[int32](60), heartbeat: now.Add(-30 * time.Second).Format(time.RFC3339), want: at(30 * time.Second)},
+		{name: "never shortens expire time", timeout: ptr.To[int32](60), heartbeat: now.Format(time.RFC3339), expireTime: at(time.Hour)},
+		{name: "future heartbeat is clamped", timeout: ptr.To[int32](60), heartbeat: now.Add(24 * time.Hour).Format(time.RFC3339), want: at(time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := &sandboxv1alpha1.BatchSandbox{
+				Spec: sandboxv1alpha1.BatchSandboxSpec{HeartbeatTimeoutSeconds: tt.timeout, ExpireTime: tt.expireTime},
+			}
+			if tt.heartbeat != "" {
+				bs.Annotations = map[string]string{AnnoHeartbeatKey: tt.heartbeat}
+			}
+			got, err := heartbeatExpireTime(bs, now)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.want == nil {
+				assert.Nil(t, got)
+			} else {
+				assert.True(t, tt.want.Equal(got), "want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRefreshExpireTime(t *testing.T) {
+	ctx := context.Background()
+	heartbeat := time.Now().Truncate(time.Second)
+	bs := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sbx",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnoHeartbeatKey: heartbeat.Format(time.RFC3339)},
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			ExpireTime:              &metav1.Time{Time: heartbeat.Add(time.Second)},
+			HeartbeatTimeoutSeconds: ptr.To[int32](300),
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &BatchSandboxReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(testscheme).WithObjects(bs).Build(),
+		Scheme:   testscheme,
+		Recorder: recorder,
+	}
+
+	assert.NoError(t, r.refreshExpireTime(ctx, bs))
+	got := &sandboxv1alpha1.BatchSandbox{}
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(bs), got))
+	assert.True(t, got.Spec.ExpireTime.Time.Equal(heartbeat.Add(5*time.Minute)))
+
+	got.Annotations[AnnoHeartbeatKey] = "not-a-time"
+	assert.NoError(t, r.refreshExpireTime(ctx, got))
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "InvalidHeartbeat")
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+// overflowsOnDemand reports whether the sandbox creates the pods a saturated pool cannot supply.
+func overflowsOnDemand(batchSbx *sandboxv1alpha1.BatchSandbox) bool {
+	policy := batchSbx.Spec.AllocationPolicy
+	return policy != nil && policy.OverflowPolicy == sandboxv1alpha1.AllocationOverflowOnDemand
+}
+
+// overflowPodPrefix prefixes the names of the on-demand pods of the sandbox.
+func overflowPodPrefix(batchSbx *sandboxv1alpha1.BatchSandbox) string {
+	return batchSbx.Name + "-overflow-"
+}
+
+// activeOverflowPods returns the on-demand pods that are not released, in creation order.
+func activeOverflowPods(overflow SandboxOverflow) []string {
+	active := make([]string, 0, len(overflow.Pods))
+	for _, name := range overflow.Pods {
+		if !slices.Contains(overflow.Released, name) {
+			active = append(active, name)
+		}
+	}
+	return active
+}
+
+// planOverflow returns the on-demand pods the sandbox should have, given the pool pods it keeps. While the
+// pool is saturated, the replicas the pool cannot supply are added. Once the pool pods and the on-demand
+// pods exceed the replicas, e.g. because the pool allocated a pod while the on-demand pods were created,
+// the newest on-demand pods still in use are dropped. Released pods stay, so that they are not replaced.
+func planOverflow(batchSbx *sandboxv1alpha1.BatchSandbox, overflow SandboxOverflow, poolKept int32) SandboxOverflow {
+	replicas := int32(0)
+	if batchSbx.Spec.Replicas != nil {
+		replicas = *batchSbx.Spec.Replicas
+	}
+	plan := SandboxOverflow{
+		Pods:     slices.Clone(overflow.Pods),
+		Released: slices.Clone(overflow.Released),
+		Next:     overflow.Next,
+	}
+	for i := len(plan.Pods) - 1; i >= 0 && poolKept+int32(len(plan.Pods)) > replicas; i-- {
+		if !slices.Contains(plan.Released, plan.Pods[i]) {
+			plan.Pods = slices.Delete(plan.Pods, i, i+1)
+		}
+	}
+	saturated := hasAllocationCondition(batchSbx, sandboxv1alpha1.BatchSandboxConditionPoolSaturated)
+	// A sandbox with fallback pools leaves the overflow to the sandbox of the last one.
+	if overflowsOnDemand(batchSbx) && len(batchSbx.Spec.FallbackPoolRefs) == 0 && saturated && !isPoolExhausted(batchSbx) &&
+		batchSbx.Status.Phase != sandboxv1alpha1.BatchSandboxPhasePaused {
+		for poolKept+int32(len(plan.Pods)) < replicas {
+			plan.Pods = append(plan.Pods, fmt.Sprintf("%s%d", overflowPodPrefix(batchSbx), plan.Next))
+			plan.Next++
+		}
+	}
+	return plan
+}
+
+// poolKeptPods counts the pool pods the sandbox keeps: the allocated pods, released or not, except those
+// being preempted, which the pool replaces.
+func poolKeptPods(ctx context.Context, c client.Reader, batchSbx *sandboxv1alpha1.BatchSandbox) (int32, error) {
+	alloc, err := sandboxAllocation(ctx, c, batchSbx)
+	if err != nil {
+		return 0, err
+	}
+	preemption, err := parseSandboxPreemption(batchSbx)
+	if err != nil {
+		return 0, err
+	}
+	kept := int32(0)
+	for _, name := range alloc.Pods {
+		if _, ok := preemption.Preempted[name]; !ok {
+			kept++
+		}
+	}
+	return kept, nil
+}
+
+// reconcileOverflow keeps the on-demand pods of a pooled sandbox: it records the pods planOverflow asks
+// for before creating them, so that the pool stops supplying the same replicas, creates them from the pool
+// template, and deletes the on-demand pods that were released or dropped. The pods are owned by the
+// sandbox and deleted along with it.
+func (r *BatchSandboxReconciler) reconcileOverflow(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox) error {
+	overflow, err := parseSandboxOverflow(batchSbx)
+	if err != nil {
+		return fmt.Errorf("failed to parse on-demand pods: %w", err)
+	}
+	if (len(overflow.Pods) == 0 && !overflowsOnDemand(batchSbx)) || !batchSbx.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	poolKept, err := poolKeptPods(ctx, r.Client, batchSbx)
+	if err != nil {
+		return err
+	}
+	plan := planOverflow(batchSbx, overflow, poolKept)
+	if !slices.Equal(plan.Pods, overflow.Pods) {
+		if err := r.patchAnnotation(ctx, batchSbx, AnnoOverflowKey, utils.DumpJSON(plan)); err != nil {
+			return fmt.Errorf("failed to record on-demand pods: %w", err)
+		}
+	}
+	return r.syncOverflowPods(ctx, batchSbx, plan)
+}
+
+// patchAnnotation sets an annotation of the sandbox, on the server and on batchSbx.
+func (r *BatchSandboxReconciler) patchAnnotation(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, key, value string) error {
+	body := utils.DumpJSON(struct {
+		MetaData metav1.ObjectMeta `json:"metadata"`
+	}{
+		MetaData: metav1.ObjectMeta{
+			Annotations: map[string]string{key: value},
+		},
+	})
+	b := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: batchSbx.Namespace,
+			Name:      batchSbx.Name,
+		},
+	}
+	if err := r.Client.Patch(ctx, b, client.RawPatch(types.MergePatchType, []byte(body))); err != nil {
+		return err
+	}
+	if batchSbx.Annotations == nil {
+		batchSbx.Annotations = map[string]string{}
+	}
+	batchSbx.Annotations[key] = value
+	return nil
+}
+
+// syncOverflowPods creates the active on-demand pods that do not exist and deletes the other on-demand pods
+// of the sandbox. A pod that was deleted while in use is created again.
+func (r *BatchSandboxReconciler) syncOverflowPods(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, overflow SandboxOverflow) error {
+	log := logf.FromContext(ctx)
+	active := activeOverflowPods(overflow)
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, &client.ListOptions{
+		Namespace:     batchSbx.Namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForOwnerRefUID: string(batchSbx.UID)}),
+	}); err != nil {
+		return err
+	}
+	var errs []error
+	existing := make(sets.Set[string])
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !metav1.IsControlledBy(pod, batchSbx) || !strings.HasPrefix(pod.Name, overflowPodPrefix(batchSbx)) {
+			continue
+		}
+		existing.Insert(pod.Name)
+		if slices.Contains(active, pod.Name) || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		log.Info("deleted on-demand pod", "pod", pod.Name)
+	}
+
+	var missing []string
+	for _, name := range active {
+		if !existing.Has(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return gerrors.Join(errs...)
+	}
+	template, err := r.overflowTemplate(ctx, batchSbx)
+	if err != nil {
+		r.Recorder.Eventf(batchSbx, corev1.EventTypeWarning, "FailedCreate", "failed to resolve the template of on-demand pods: %v", err)
+		return gerrors.Join(append(errs, err)...)
+	}
+	for _, name := range missing {
+		pod, err := utils.GetPodFromTemplate(template, batchSbx, metav1.NewControllerRef(batchSbx, sandboxv1alpha1.SchemeBuilder.GroupVersion.WithKind("BatchSandbox")))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pod.Namespace = batchSbx.Namespace
+		pod.Name = name
+		pod.GenerateName = ""
+		pod.Labels[LabelBatchSandboxNameKey] = batchSbx.Name
+		if err := r.Create(ctx, pod); err != nil {
+			// The pod list may not have caught up with a pod created by an earlier reconcile.
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+			r.Recorder.Eventf(batchSbx, corev1.EventTypeWarning, "FailedCreate", "failed to create on-demand pod %s: %v", name, err)
+			errs = append(errs, err)
+			continue
+		}
+		r.Recorder.Eventf(batchSbx, corev1.EventTypeNormal, "SuccessfulCreate", "created on-demand pod %s, pool %s is saturated", name, batchSbx.Spec.PoolRef)
+	}
+	return gerrors.Join(errs...)
+}
+
+// overflowTemplate returns the template the pool creates pods of the sandbox's flavor from.
+func (r *BatchSandboxReconciler) overflowTemplate(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox) (*corev1.PodTemplateSpec, error) {
+	pool := &sandboxv1alpha1.Pool{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: batchSbx.Namespace, Name: batchSbx.Spec.PoolRef}, pool); err != nil {
+		return nil, err
+	}
+	resolved, err := resolvePoolTemplate(ctx, r.Client, pool)
+	if err != nil {
+		return nil, err
+	}
+	template := applyPoolTopologySpread(pool, applyPoolPriority(pool, resolved))
+	if batchSbx.Spec.Flavor == "" {
+		return template, nil
+	}
+	for i := range pool.Spec.Flavors {
+		if pool.Spec.Flavors[i].Name == batchSbx.Spec.Flavor {
+			return flavorTemplate(template, &pool.Spec.Flavors[i])
+		}
+	}
+	return nil, fmt.Errorf("pool %s has no flavor %s", pool.Name, batchSbx.Spec.Flavor)
+}
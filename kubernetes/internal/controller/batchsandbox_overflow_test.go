@@ -0,0 +1,132 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+func overflowSandbox(replicas int32, saturated bool) *sandboxv1alpha1.BatchSandbox {
+	bs := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default", UID: "sbx-uid"},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			Replicas:         ptr.To(replicas),
+			PoolRef:          "pool",
+			AllocationPolicy: &sandboxv1alpha1.AllocationPolicy{OverflowPolicy: sandboxv1alpha1.AllocationOverflowOnDemand},
+		},
+	}
+	if saturated {
+		bs.Status.Conditions = []sandboxv1alpha1.BatchSandboxCondition{{
+			Type:   sandboxv1alpha1.BatchSandboxConditionPoolSaturated,
+			Status: sandboxv1alpha1.ConditionTrue,
+		}}
+	}
+	return bs
+}
+
+func TestPlanOverflow(t *testing.T) {
+	saturated := overflowSandbox(3, true)
+	assert.Equal(t, []string{"sbx-overflow-0", "sbx-overflow-1"}, planOverflow(saturated, SandboxOverflow{}, 1).Pods)
+
+	plan := planOverflow(saturated, SandboxOverflow{Pods: []string{"sbx-overflow-0"}, Released: []string{"sbx-overflow-0"}, Next: 1}, 1)
+	assert.Equal(t, []string{"sbx-overflow-0", "sbx-overflow-1"}, plan.Pods, "released pods are not replaced")
+	assert.Equal(t, 2, plan.Next)
+
+	waiting := overflowSandbox(3, true)
+	waiting.Spec.AllocationPolicy = nil
+	assert.Empty(t, planOverflow(waiting, SandboxOverflow{}, 1).Pods, "sandboxes wait for the pool by default")
+	assert.Empty(t, planOverflow(overflowSandbox(3, false), SandboxOverflow{}, 1).Pods, "only a saturated pool overflows")
+
+	// The pool allocated pods meanwhile: the newest pods in use are dropped, released ones stay.
+	overflow := SandboxOverflow{Pods: []string{"sbx-overflow-0", "sbx-overflow-1", "sbx-overflow-2"}, Released: []string{"sbx-overflow-2"}, Next: 3}
+	plan = planOverflow(overflowSandbox(3, false), overflow, 1)
+	assert.Equal(t, []string{"sbx-overflow-0", "sbx-overflow-2"}, plan.Pods)
+	assert.Equal(t, 3, plan.Next)
+}
+
+func TestReconcileOverflow(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "sandbox"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "sandbox:latest"}}},
+			},
+		},
+	}
+	bs := overflowSandbox(2, true)
+	setSandboxAllocation(bs, SandboxAllocation{Pods: []string{"pool-pod"}})
+	c := fake.NewClientBuilder().WithScheme(testscheme).
+		WithIndex(&corev1.Pod{}, fieldindex.IndexNameForOwnerRefUID, fieldindex.OwnerIndexFunc).
+		WithObjects(pool, bs).Build()
+	r := &BatchSandboxReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	require.NoError(t, r.reconcileOverflow(ctx, bs))
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sbx-overflow-0"}, pod))
+	assert.Equal(t, "sandbox:latest", pod.Spec.Containers[0].Image)
+	assert.Equal(t, "sandbox", pod.Labels["app"])
+	assert.NotContains(t, pod.Labels, LabelPoolName, "on-demand pods are not pool pods")
+	assert.True(t, metav1.IsControlledBy(pod, bs))
+
+	latest := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(bs), latest))
+	overflow, err := parseSandboxOverflow(latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sbx-overflow-0"}, overflow.Pods)
+
+	pods, err := listBatchSandboxPods(ctx, c, strategy.NewPoolStrategy(latest), latest)
+	require.NoError(t, err)
+	assert.Len(t, pods, 1, "the on-demand pod is listed; the pool pod does not exist")
+
+	// Releasing the on-demand pod deletes it instead of handing it to the pool.
+	require.NoError(t, r.releasePods(ctx, latest, []string{"sbx-overflow-0"}))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(bs), latest))
+	assert.NotContains(t, latest.Annotations, AnnoAllocReleaseKey)
+	require.NoError(t, r.reconcileOverflow(ctx, latest))
+	err = c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "sbx-overflow-0"}, &corev1.Pod{})
+	assert.True(t, errors.IsNotFound(err), "the released pod is deleted")
+}
+
+func TestGetSandboxRequest_CountsOverflowPods(t *testing.T) {
+	sandbox := overflowSandbox(3, true)
+	sandbox.Annotations = map[string]string{
+		AnnoAllocStatusKey: utils.DumpJSON(SandboxAllocation{Pods: []string{"p1"}}),
+		AnnoOverflowKey:    utils.DumpJSON(SandboxOverflow{Pods: []string{"sbx-overflow-0"}, Next: 1}),
+	}
+	allocator := NewDefaultAllocator(fake.NewClientBuilder().WithScheme(testscheme).Build(), record.NewFakeRecorder(10)).(*defaultAllocator)
+
+	req, err := allocator.getSandboxRequest(context.Background(), sandbox)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), req.PodSupplement, "the pool does not supply the replicas served on demand")
+	assert.Empty(t, req.ToRelease)
+}
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	gerrors "errors"
 	"fmt"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
+	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
 	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
 )
@@ -93,6 +95,7 @@ func sourcePodTemplateForPause(pod *corev1.Pod) *corev1.PodTemplateSpec {
 	labels := copyPodTemplateMap(pod.Labels)
 	delete(labels, LabelPoolName)
 	delete(labels, LabelPoolRevision)
+	delete(labels, LabelPoolOrdinal)
 	delete(labels, LabelBatchSandboxNameKey)
 	delete(labels, LabelBatchSandboxPodIndexKey)
 
@@ -130,7 +133,7 @@ func (r *BatchSandboxReconciler) deleteInternalPauseSnapshot(ctx context.Context
 func (r *BatchSandboxReconciler) hasReadyResumePod(ctx context.Context, bs *sandboxv1alpha1.BatchSandbox) (bool, error) {
 	poolStrategy := strategy.NewPoolStrategy(bs)
 	if poolStrategy.IsPooledMode() {
-		alloc, err := parseSandboxAllocation(bs)
+		alloc, err := sandboxAllocation(ctx, r.Client, bs)
 		if err != nil {
 			return false, err
 		}
@@ -334,7 +337,7 @@ func (r *BatchSandboxReconciler) stopTasksBeforePause(ctx context.Context, bs *s
 		log.Info("Stopping tasks before pause", "count", len(stoppingTasks))
 	}
 
-	if err := sch.Schedule(); err != nil {
+	if err := sch.Schedule(ctx); err != nil && !gerrors.Is(err, taskscheduler.ErrDispatchIncomplete) {
 		return false, fmt.Errorf("failed to stop tasks before pause: %w", err)
 	}
 	unfinishedTasks := r.getTasksCleanupUnfinished(bs, sch)
@@ -62,7 +62,7 @@ type forbiddenTaskScheduler struct {
 	t *testing.T
 }
 
-func (f *forbiddenTaskScheduler) Schedule() error {
+func (f *forbiddenTaskScheduler) Schedule(context.Context) error {
 	f.t.Fatalf("task scheduler should not be invoked while sandbox is paused")
 	return nil
 }
@@ -71,6 +71,14 @@ func (f *forbiddenTaskScheduler) UpdatePods(_ []*corev1.Pod) {
 	f.t.Fatalf("task scheduler should not receive pod updates while sandbox is paused")
 }
 
+func (f *forbiddenTaskScheduler) SetPlacementPolicy(_ taskscheduler.PlacementPolicy) {
+	f.t.Fatalf("task scheduler should not receive placement updates while sandbox is paused")
+}
+
+func (f *forbiddenTaskScheduler) SetHoldFailed(_ bool) {
+	f.t.Fatalf("task scheduler should not receive hold updates while sandbox is paused")
+}
+
 func (f *forbiddenTaskScheduler) ListTask() []taskscheduler.Task {
 	f.t.Fatalf("task scheduler should not list tasks while sandbox is paused")
 	return nil
@@ -116,7 +124,7 @@ type recordingTaskScheduler struct {
 	tasks           []taskscheduler.Task
 }
 
-func (r *recordingTaskScheduler) Schedule() error {
+func (r *recordingTaskScheduler) Schedule(context.Context) error {
 	r.scheduleCalls++
 	return nil
 }
@@ -125,6 +133,10 @@ func (r *recordingTaskScheduler) UpdatePods(_ []*corev1.Pod) {
 	r.updatePodsCalls++
 }
 
+func (r *recordingTaskScheduler) SetPlacementPolicy(_ taskscheduler.PlacementPolicy) {}
+
+func (r *recordingTaskScheduler) SetHoldFailed(_ bool) {}
+
 func (r *recordingTaskScheduler) ListTask() []taskscheduler.Task {
 	return r.tasks
 }
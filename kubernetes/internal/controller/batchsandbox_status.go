@@ -18,6 +18,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -168,18 +170,35 @@ func buildRuntimeView(batchSbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod
 	newStatus.Replicas = 0
 	newStatus.Allocated = 0
 	newStatus.Ready = 0
+	newStatus.PodRevisions = nil
+	newStatus.PodEndpoints = nil
 
 	ipList := make([]string, len(pods))
 	for i, pod := range pods {
 		newStatus.Replicas++
+		if revision := pod.Labels[LabelPoolRevision]; revision != "" {
+			newStatus.PodRevisions = append(newStatus.PodRevisions, sandboxv1alpha1.PodRevision{Pod: pod.Name, Revision: revision, Pool: pod.Labels[LabelPoolName]})
+		}
 		if utils.IsAssigned(pod) {
 			newStatus.Allocated++
 			ipList[i] = pod.Status.PodIP
+			if endpoints := podEndpoints(pod); endpoints != nil {
+				newStatus.PodEndpoints = append(newStatus.PodEndpoints, sandboxv1alpha1.PodEndpoints{Pod: pod.Name, Endpoints: endpoints})
+			}
 		}
-		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && utils.IsPodReady(pod) {
+		// A pod still hibernated has not got its requests back yet.
+		_, hibernated := hibernatedRequests(pod)
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && utils.IsPodReady(pod) && !hibernated {
 			newStatus.Ready++
 		}
 	}
+	// Pods are listed in cache order; keep the status stable across reconciles.
+	slices.SortFunc(newStatus.PodRevisions, func(a, b sandboxv1alpha1.PodRevision) int {
+		return strings.Compare(a.Pod, b.Pod)
+	})
+	slices.SortFunc(newStatus.PodEndpoints, func(a, b sandboxv1alpha1.PodEndpoints) int {
+		return strings.Compare(a.Pod, b.Pod)
+	})
 
 	switch batchSbx.Status.Phase {
 	case sandboxv1alpha1.BatchSandboxPhasePausing, sandboxv1alpha1.BatchSandboxPhasePaused:
@@ -290,6 +309,7 @@ func (r *BatchSandboxReconciler) updateStatus(batchSandbox *sandboxv1alpha1.Batc
 		}
 		mergedStatus := newStatus.DeepCopy()
 		mergedStatus.Conditions = mergeLifecycleConditions(mergedStatus.Conditions, clone.Status.Conditions)
+		mergedStatus.Conditions = mergePoolOwnedConditions(mergedStatus.Conditions, clone.Status.Conditions)
 		clone.Status = *mergedStatus
 		return r.Status().Update(context.TODO(), clone)
 	})
@@ -316,6 +336,30 @@ func mergeLifecycleConditions(
 	return merged
 }
 
+// mergePoolOwnedConditions takes the conditions maintained by the pool controller from latest, so a
+// status computed from an older copy neither drops nor revives them.
+func mergePoolOwnedConditions(
+	desired []sandboxv1alpha1.BatchSandboxCondition,
+	latest []sandboxv1alpha1.BatchSandboxCondition,
+) []sandboxv1alpha1.BatchSandboxCondition {
+	merged := make([]sandboxv1alpha1.BatchSandboxCondition, 0, len(desired))
+	for _, cond := range desired {
+		if !isPoolOwnedCondition(cond.Type) {
+			merged = append(merged, cond)
+		}
+	}
+	for _, cond := range latest {
+		if isPoolOwnedCondition(cond.Type) {
+			merged = append(merged, cond)
+		}
+	}
+	return merged
+}
+
+func isPoolOwnedCondition(conditionType sandboxv1alpha1.BatchSandboxConditionType) bool {
+	return conditionType == sandboxv1alpha1.BatchSandboxConditionPoolSaturated
+}
+
 func isLifecycleOwnedCondition(conditionType sandboxv1alpha1.BatchSandboxConditionType) bool {
 	switch conditionType {
 	case sandboxv1alpha1.BatchSandboxConditionPauseFailed,
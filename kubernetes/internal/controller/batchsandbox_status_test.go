@@ -0,0 +1,71 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestBuildRuntimeView_PodRevisions(t *testing.T) {
+	bs := &sandboxv1alpha1.BatchSandbox{
+		Status: sandboxv1alpha1.BatchSandboxStatus{
+			PodRevisions: []sandboxv1alpha1.PodRevision{{Pod: "released", Revision: "v1"}},
+		},
+	}
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-b", Labels: map[string]string{LabelPoolRevision: "v2"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-a", Labels: map[string]string{LabelPoolRevision: "v1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "own-pod"}},
+	}
+
+	view := buildRuntimeView(bs, pods)
+	assert.Equal(t, []sandboxv1alpha1.PodRevision{{Pod: "pod-a", Revision: "v1"}, {Pod: "pod-b", Revision: "v2"}}, view.status.PodRevisions)
+
+	view = buildRuntimeView(bs, nil)
+	assert.Empty(t, view.status.PodRevisions)
+}
+
+func TestBuildRuntimeView_PodEndpoints(t *testing.T) {
+	pod := func(name, ip, ports string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{AnnoEndpointPortsKey: ports}},
+			Spec: corev1.PodSpec{
+				NodeName:   "node",
+				Containers: []corev1.Container{{Name: "main", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+			},
+			Status: corev1.PodStatus{PodIP: ip},
+		}
+	}
+	pods := []*corev1.Pod{
+		pod("pod-b", "10.0.0.2", "jupyter=8888, vnc=5900"),
+		pod("pod-a", "10.0.0.1", "web=http,bad,zero=0,missing=grpc"),
+		pod("pending", "", "jupyter=8888"),
+		pod("plain", "10.0.0.3", ""),
+		pod("ipv6", "fd00::1", "jupyter=8888"),
+	}
+
+	view := buildRuntimeView(&sandboxv1alpha1.BatchSandbox{}, pods)
+	assert.Equal(t, []sandboxv1alpha1.PodEndpoints{
+		{Pod: "ipv6", Endpoints: map[string]string{"jupyter": "[fd00::1]:8888"}},
+		{Pod: "pod-a", Endpoints: map[string]string{"web": "10.0.0.1:8080"}},
+		{Pod: "pod-b", Endpoints: map[string]string{"jupyter": "10.0.0.2:8888", "vnc": "10.0.0.2:5900"}},
+	}, view.status.PodEndpoints)
+}
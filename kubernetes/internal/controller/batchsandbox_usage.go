@@ -0,0 +1,202 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// podRequests returns the resource requests of the pod: the sum of its containers plus the pod overhead.
+// Init containers run before the pod serves a sandbox and are left out.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	add := func(list corev1.ResourceList) {
+		for name, quantity := range list {
+			sum := requests[name]
+			sum.Add(quantity)
+			requests[name] = sum
+		}
+	}
+	for i := range pod.Spec.Containers {
+		add(pod.Spec.Containers[i].Resources.Requests)
+	}
+	add(pod.Spec.Overhead)
+	return requests
+}
+
+// syncUsage records in status which pods serve the sandbox since when. Pods that are gone, released or
+// terminated are closed with now. Records only change when pods come and go, so the status is not
+// rewritten on every reconcile.
+func syncUsage(status *sandboxv1alpha1.BatchSandboxStatus, pods []*corev1.Pod, pooled bool, now time.Time) {
+	serving := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			serving[pod.Name] = pod
+		}
+	}
+	if status.Usage == nil {
+		if len(serving) == 0 {
+			return
+		}
+		status.Usage = &sandboxv1alpha1.BatchSandboxUsage{}
+	}
+	usage := status.Usage
+	open := make(map[string]bool, len(usage.Pods))
+	for i := range usage.Pods {
+		record := &usage.Pods[i]
+		if record.Until != nil {
+			continue
+		}
+		if _, ok := serving[record.Name]; ok {
+			open[record.Name] = true
+			continue
+		}
+		record.Until = &metav1.Time{Time: now}
+	}
+	for _, pod := range pods {
+		if _, ok := serving[pod.Name]; !ok || open[pod.Name] {
+			continue
+		}
+		since := metav1.Time{Time: now}
+		if !pooled && !pod.CreationTimestamp.IsZero() && pod.CreationTimestamp.Time.Before(now) {
+			since = pod.CreationTimestamp
+		}
+		usage.Pods = append(usage.Pods, sandboxv1alpha1.PodUsage{Name: pod.Name, Requests: podRequests(pod), Since: since})
+	}
+}
+
+// SandboxUsage is the usage of a BatchSandbox served by the manager API.
+type SandboxUsage struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Pool      string `json:"pool,omitempty"`
+	// PodSeconds is the time pods served the sandbox, summed over the pods.
+	PodSeconds float64 `json:"podSeconds"`
+	// ResourceSeconds integrates the requests of the pods over the time they served the sandbox, in the base
+	// unit of each resource: core-seconds for cpu, byte-seconds for memory.
+	ResourceSeconds map[corev1.ResourceName]float64 `json:"resourceSeconds"`
+	// Running is the number of pods still serving the sandbox.
+	Running int32 `json:"running"`
+	// Pods are the periods the usage is computed from.
+	Pods []sandboxv1alpha1.PodUsage `json:"pods"`
+}
+
+// summarizeUsage computes the usage of the sandbox up to now.
+func summarizeUsage(sbx *sandboxv1alpha1.BatchSandbox, now time.Time) *SandboxUsage {
+	summary := &SandboxUsage{
+		Namespace:       sbx.Namespace,
+		Name:            sbx.Name,
+		Pool:            sbx.Spec.PoolRef,
+		ResourceSeconds: map[corev1.ResourceName]float64{},
+		Pods:            []sandboxv1alpha1.PodUsage{},
+	}
+	if sbx.Status.Usage == nil {
+		return summary
+	}
+	summary.Pods = sbx.Status.Usage.Pods
+	for _, record := range sbx.Status.Usage.Pods {
+		until := now
+		if record.Until != nil {
+			until = record.Until.Time
+		} else {
+			summary.Running++
+		}
+		seconds := until.Sub(record.Since.Time).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		summary.PodSeconds += seconds
+		for name, quantity := range record.Requests {
+			summary.ResourceSeconds[name] += quantity.AsApproximateFloat64() * seconds
+		}
+	}
+	return summary
+}
+
+// handleUsage returns the usage of a BatchSandbox. Callers must be authorized to "get" the BatchSandbox.
+//
+//	GET /v1/namespaces/{namespace}/batchsandboxes/{name}/usage
+func (s *ManagerAPIServer) handleUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	status, err := s.reviewAccess(ctx, r, &authorizationv1.ResourceAttributes{
+		Namespace: key.Namespace,
+		Verb:      "get",
+		Group:     sandboxv1alpha1.GroupVersion.Group,
+		Resource:  "batchsandboxes",
+		Name:      key.Name,
+	})
+	if err != nil {
+		writeAPIError(w, status, err)
+		return
+	}
+
+	sbx := &sandboxv1alpha1.BatchSandbox{}
+	if err := s.Client.Get(ctx, key, sbx); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeAPIError(w, http.StatusNotFound, fmt.Errorf("batchsandbox %s not found", key))
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, summarizeUsage(sbx, time.Now()))
+}
+
+// handleNamespaceUsage returns the usage of every BatchSandbox in the namespace, optionally only those of
+// the pool given with ?pool=. Callers must be authorized to "list" BatchSandboxes in the namespace.
+//
+//	GET /v1/namespaces/{namespace}/usage[?pool=NAME]
+func (s *ManagerAPIServer) handleNamespaceUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	namespace := r.PathValue("namespace")
+	status, err := s.reviewAccess(ctx, r, &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "list",
+		Group:     sandboxv1alpha1.GroupVersion.Group,
+		Resource:  "batchsandboxes",
+	})
+	if err != nil {
+		writeAPIError(w, status, err)
+		return
+	}
+
+	list := &sandboxv1alpha1.BatchSandboxList{}
+	if err := s.Client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	pool := r.URL.Query().Get("pool")
+	now := time.Now()
+	usages := []*SandboxUsage{}
+	for i := range list.Items {
+		if pool != "" && list.Items[i].Spec.PoolRef != pool {
+			continue
+		}
+		usages = append(usages, summarizeUsage(&list.Items[i], now))
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{"items": usages})
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func usagePod(name, cpu string, created time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Time{Time: created}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "main",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	pod := usagePod("pod", "500m", time.Time{})
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "sidecar", Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+	}})
+	pod.Spec.InitContainers = []corev1.Container{{Name: "init", Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+	}}}
+	pod.Spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}
+
+	requests := podRequests(pod)
+	assert.Equal(t, int64(1000), requests.Cpu().MilliValue())
+	assert.Equal(t, int64(1<<30), requests.Memory().Value())
+}
+
+func TestSyncUsage(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := t0.Add(-time.Minute)
+
+	t.Run("no pods records nothing", func(t *testing.T) {
+		status := &sandboxv1alpha1.BatchSandboxStatus{}
+		syncUsage(status, nil, true, t0)
+		assert.Nil(t, status.Usage)
+	})
+
+	t.Run("pool pods are recorded from when they are seen", func(t *testing.T) {
+		status := &sandboxv1alpha1.BatchSandboxStatus{}
+		syncUsage(status, []*corev1.Pod{usagePod("a", "1", created), usagePod("b", "2", created)}, true, t0)
+		require.Len(t, status.Usage.Pods, 2)
+		assert.Equal(t, t0, status.Usage.Pods[0].Since.Time)
+		assert.Nil(t, status.Usage.Pods[0].Until)
+
+		// Unchanged pods leave the records untouched.
+		before := status.Usage.DeepCopy()
+		syncUsage(status, []*corev1.Pod{usagePod("a", "1", created), usagePod("b", "2", created)}, true, t0.Add(time.Hour))
+		assert.Equal(t, before, status.Usage)
+
+		// b is released and a terminates.
+		a := usagePod("a", "1", created)
+		a.Status.Phase = corev1.PodSucceeded
+		syncUsage(status, []*corev1.Pod{a}, true, t0.Add(2*time.Hour))
+		require.Len(t, status.Usage.Pods, 2)
+		for _, record := range status.Usage.Pods {
+			assert.Equal(t, t0.Add(2*time.Hour), record.Until.Time, record.Name)
+		}
+
+		// b serving the sandbox again opens a new record.
+		syncUsage(status, []*corev1.Pod{usagePod("b", "2", created)}, true, t0.Add(3*time.Hour))
+		require.Len(t, status.Usage.Pods, 3)
+		assert.Equal(t, "b", status.Usage.Pods[2].Name)
+		assert.Equal(t, t0.Add(3*time.Hour), status.Usage.Pods[2].Since.Time)
+	})
+
+	t.Run("own pods are recorded from their creation", func(t *testing.T) {
+		status := &sandboxv1alpha1.BatchSandboxStatus{}
+		syncUsage(status, []*corev1.Pod{usagePod("a", "1", created)}, false, t0)
+		require.Len(t, status.Usage.Pods, 1)
+		assert.Equal(t, created, status.Usage.Pods[0].Since.Time)
+	})
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := metav1.NewTime(t0.Add(time.Hour))
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool"},
+		Status: sandboxv1alpha1.BatchSandboxStatus{Usage: &sandboxv1alpha1.BatchSandboxUsage{Pods: []sandboxv1alpha1.PodUsage{
+			{Name: "a", Requests: podRequests(usagePod("a", "2", t0)), Since: metav1.NewTime(t0), Until: &until},
+			{Name: "b", Requests: podRequests(usagePod("b", "500m", t0)), Since: metav1.NewTime(t0.Add(time.Hour))},
+		}}},
+	}
+
+	got := summarizeUsage(sbx, t0.Add(3*time.Hour))
+	assert.Equal(t, "pool", got.Pool)
+	assert.Equal(t, int32(1), got.Running)
+	assert.InDelta(t, 3*3600, got.PodSeconds, 0.001)
+	assert.InDelta(t, 2*3600+0.5*2*3600, got.ResourceSeconds[corev1.ResourceCPU], 0.001)
+	assert.InDelta(t, float64(1<<30)*3*3600, got.ResourceSeconds[corev1.ResourceMemory], 1)
+
+	empty := summarizeUsage(&sandboxv1alpha1.BatchSandbox{}, t0)
+	assert.Zero(t, empty.PodSeconds)
+	assert.NotNil(t, empty.Pods)
+}
+
+func TestManagerAPIServerUsage(t *testing.T) {
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(sandboxv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(authenticationv1.AddToScheme(scheme))
+	utilruntime.Must(authorizationv1.AddToScheme(scheme))
+
+	since := metav1.NewTime(time.Now().Add(-time.Hour))
+	newSandbox := func(name, poolRef string) *sandboxv1alpha1.BatchSandbox {
+		return &sandboxv1alpha1.BatchSandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: poolRef},
+			Status: sandboxv1alpha1.BatchSandboxStatus{Usage: &sandboxv1alpha1.BatchSandboxUsage{Pods: []sandboxv1alpha1.PodUsage{
+				{Name: name + "-pod", Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}, Since: since},
+			}}},
+		}
+	}
+
+	var reviewed []authorizationv1.ResourceAttributes
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(newSandbox("a", "pool"), newSandbox("b", "other")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				switch review := obj.(type) {
+				case *authenticationv1.TokenReview:
+					review.Status.Authenticated = true
+					review.Status.User.Username = review.Spec.Token
+				case *authorizationv1.SubjectAccessReview:
+					reviewed = append(reviewed, *review.Spec.ResourceAttributes)
+					review.Status.Allowed = review.Spec.User == "alice"
+				default:
+					return c.Create(ctx, obj, opts...)
+				}
+				return nil
+			},
+		}).Build()
+	ts := httptest.NewServer((&ManagerAPIServer{Client: c}).Handler())
+	defer ts.Close()
+
+	get := func(path, token string) (int, []byte) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.StatusCode, raw
+	}
+
+	code, _ := get("/v1/namespaces/default/batchsandboxes/a/usage", "bob")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = get("/v1/namespaces/default/batchsandboxes/missing/usage", "alice")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, raw := get("/v1/namespaces/default/batchsandboxes/a/usage", "alice")
+	assert.Equal(t, http.StatusOK, code)
+	got := &SandboxUsage{}
+	assert.NoError(t, json.Unmarshal(raw, got))
+	assert.Equal(t, "a", got.Name)
+	assert.Equal(t, int32(1), got.Running)
+	assert.InDelta(t, 3600, got.ResourceSeconds[corev1.ResourceCPU], 60)
+	assert.Equal(t, authorizationv1.ResourceAttributes{
+		Namespace: "default",
+		Verb:      "get",
+		Group:     sandboxv1alpha1.GroupVersion.Group,
+		Resource:  "batchsandboxes",
+		Name:      "a",
+	}, reviewed[len(reviewed)-1])
+
+	code, raw = get("/v1/namespaces/default/usage?pool=other", "alice")
+	assert.Equal(t, http.StatusOK, code)
+	list := struct {
+		Items []SandboxUsage `json:"items"`
+	}{}
+	assert.NoError(t, json.Unmarshal(raw, &list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "b", list.Items[0].Name)
+	assert.Equal(t, "list", reviewed[len(reviewed)-1].Verb)
+}
@@ -0,0 +1,346 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	gerrors "errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/expectations"
+)
+
+const (
+	// LabelBatchSandboxSetNameKey is set on the BatchSandboxes of a set to the set name.
+	LabelBatchSandboxSetNameKey = "batch-sandbox-set.sandbox.opensandbox.io/name"
+	// LabelBatchSandboxSetParameterKey is set on the BatchSandboxes of a set to their parameter name.
+	LabelBatchSandboxSetParameterKey = "batch-sandbox-set.sandbox.opensandbox.io/parameter"
+)
+
+var BatchSandboxSetExpectations = expectations.NewScaleExpectations()
+
+// templateVarRef matches the $(key) references expanded by a BatchSandboxSet.
+var templateVarRef = regexp.MustCompile(`\$\(([A-Za-z0-9_.-]+)\)`)
+
+// BatchSandboxSetReconciler reconciles a BatchSandboxSet object
+type BatchSandboxSetReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
+
+func (r *BatchSandboxSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	set := &sandboxv1alpha1.BatchSandboxSet{}
+	if err := r.Get(ctx, req.NamespacedName, set); err != nil {
+		if errors.IsNotFound(err) {
+			BatchSandboxSetExpectations.DeleteExpectations(req.String())
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	// The BatchSandboxes are owned by the set and removed by the garbage collector.
+	if !set.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	list := &sandboxv1alpha1.BatchSandboxList{}
+	if err := r.List(ctx, list, client.InNamespace(set.Namespace), client.MatchingLabels{LabelBatchSandboxSetNameKey: set.Name}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list BatchSandboxes: %w", err)
+	}
+	key := controllerutils.GetControllerKey(set)
+	children := make(map[string]*sandboxv1alpha1.BatchSandbox, len(list.Items))
+	for i := range list.Items {
+		child := &list.Items[i]
+		if !metav1.IsControlledBy(child, set) {
+			continue
+		}
+		BatchSandboxSetExpectations.ObserveScale(key, expectations.Create, child.Name)
+		children[child.Labels[LabelBatchSandboxSetParameterKey]] = child
+	}
+	// Until the cache has caught up with the BatchSandboxes created before, a missing one may just not be
+	// seen yet: nothing is created and none is taken as deleted.
+	satisfied, unsatisfiedDuration, _ := BatchSandboxSetExpectations.SatisfiedExpectations(key)
+
+	var errs []error
+	params := make(map[string]struct{}, len(set.Spec.Parameters))
+	for _, param := range set.Spec.Parameters {
+		params[param.Name] = struct{}{}
+	}
+	for name, child := range children {
+		if _, ok := params[name]; ok || !child.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, child); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete BatchSandbox %s: %w", child.Name, err))
+		}
+	}
+
+	recorded := make(map[string]sandboxv1alpha1.BatchSandboxSetParameterPhase, len(set.Status.Parameters))
+	for _, st := range set.Status.Parameters {
+		recorded[st.Name] = st.Phase
+	}
+	newStatus := set.Status.DeepCopy()
+	newStatus.Parameters = make([]sandboxv1alpha1.BatchSandboxSetParameterStatus, 0, len(set.Spec.Parameters))
+	var pending []sandboxv1alpha1.BatchSandboxSetParameter
+	unfinished := int32(0)
+	for _, param := range set.Spec.Parameters {
+		phase, ok := recorded[param.Name]
+		child := children[param.Name]
+		switch {
+		case ok && batchSandboxSetParameterFinished(phase):
+		case child != nil:
+			phase, ok = batchSandboxSetChildPhase(child), true
+		case ok && satisfied:
+			phase = sandboxv1alpha1.BatchSandboxSetParameterFailed
+			r.Recorder.Eventf(set, corev1.EventTypeWarning, "BatchSandboxDeleted", "BatchSandbox of parameter %s was deleted before it finished", param.Name)
+		}
+		if !ok {
+			pending = append(pending, param)
+			continue
+		}
+		if !batchSandboxSetParameterFinished(phase) {
+			unfinished++
+		}
+		newStatus.Parameters = append(newStatus.Parameters, sandboxv1alpha1.BatchSandboxSetParameterStatus{Name: param.Name, Phase: phase})
+	}
+
+	if satisfied {
+		for _, param := range pending {
+			if set.Spec.Parallelism != nil && unfinished >= *set.Spec.Parallelism {
+				break
+			}
+			created, err := r.createBatchSandboxSetChild(ctx, set, param)
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+			if created {
+				unfinished++
+				newStatus.Parameters = append(newStatus.Parameters, sandboxv1alpha1.BatchSandboxSetParameterStatus{Name: param.Name, Phase: sandboxv1alpha1.BatchSandboxSetParameterCreated})
+			}
+		}
+	}
+
+	now := time.Now()
+	aggregateBatchSandboxSetStatus(set, newStatus, now)
+	if !equality.Semantic.DeepEqual(*newStatus, set.Status) {
+		set.Status = *newStatus
+		if err := r.Status().Update(ctx, set); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update BatchSandboxSet status: %w", err))
+			return ctrl.Result{}, gerrors.Join(errs...)
+		}
+	}
+
+	var result ctrl.Result
+	if !satisfied {
+		result.RequeueAfter = expectations.ExpectationTimeout - unsatisfiedDuration
+	}
+	if set.Spec.TTLSecondsAfterFinished != nil && set.Status.CompletionTime != nil {
+		expiry := set.Status.CompletionTime.Add(time.Duration(*set.Spec.TTLSecondsAfterFinished) * time.Second)
+		if wait := expiry.Sub(now); wait > 0 {
+			requeueSooner(&result, wait)
+		} else {
+			logf.FromContext(ctx).Info("Deleting finished BatchSandboxSet", "completionTime", set.Status.CompletionTime)
+			if err := r.Delete(ctx, set); err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete finished BatchSandboxSet: %w", err))
+			}
+		}
+	}
+	return result, gerrors.Join(errs...)
+}
+
+// createBatchSandboxSetChild creates the BatchSandbox of param. It reports false when a BatchSandbox of
+// that name already exists and is not part of the set.
+func (r *BatchSandboxSetReconciler) createBatchSandboxSetChild(ctx context.Context, set *sandboxv1alpha1.BatchSandboxSet, param sandboxv1alpha1.BatchSandboxSetParameter) (bool, error) {
+	child, err := expandBatchSandboxSetChild(set, param)
+	if err != nil {
+		r.Recorder.Eventf(set, corev1.EventTypeWarning, "InvalidTemplate", "Failed to expand template for parameter %s: %v", param.Name, err)
+		return false, err
+	}
+	if err := ctrl.SetControllerReference(set, child, r.Scheme); err != nil {
+		return false, err
+	}
+	key := controllerutils.GetControllerKey(set)
+	BatchSandboxSetExpectations.ExpectScale(key, expectations.Create, child.Name)
+	if err := r.Create(ctx, child); err != nil {
+		BatchSandboxSetExpectations.ObserveScale(key, expectations.Create, child.Name)
+		if errors.IsAlreadyExists(err) {
+			r.Recorder.Eventf(set, corev1.EventTypeWarning, "FailedCreate", "BatchSandbox %s already exists and is not part of the set", child.Name)
+			return false, nil
+		}
+		r.Recorder.Eventf(set, corev1.EventTypeWarning, "FailedCreate", "Failed to create BatchSandbox %s: %v", child.Name, err)
+		return false, fmt.Errorf("failed to create BatchSandbox %s: %w", child.Name, err)
+	}
+	r.Recorder.Eventf(set, corev1.EventTypeNormal, "SuccessfulCreate", "Created BatchSandbox %s", child.Name)
+	return true, nil
+}
+
+// expandBatchSandboxSetChild builds the BatchSandbox of param from the template of the set.
+func expandBatchSandboxSetChild(set *sandboxv1alpha1.BatchSandboxSet, param sandboxv1alpha1.BatchSandboxSetParameter) (*sandboxv1alpha1.BatchSandbox, error) {
+	vars := map[string]string{"name": param.Name}
+	maps.Copy(vars, param.Values)
+	template, err := expandTemplateVars(&set.Spec.Template, vars)
+	if err != nil {
+		return nil, err
+	}
+	child := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        set.Name + "-" + param.Name,
+			Namespace:   set.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	if child.Labels == nil {
+		child.Labels = map[string]string{}
+	}
+	child.Labels[LabelBatchSandboxSetNameKey] = set.Name
+	child.Labels[LabelBatchSandboxSetParameterKey] = param.Name
+	return child, nil
+}
+
+// expandTemplateVars replaces the $(key) references in every string of template. Going through the JSON
+// form covers raw fields such as shard patches as well.
+func expandTemplateVars(template *sandboxv1alpha1.BatchSandboxTemplateSpec, vars map[string]string) (*sandboxv1alpha1.BatchSandboxTemplateSpec, error) {
+	raw, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if raw, err = json.Marshal(expandJSONStrings(doc, vars)); err != nil {
+		return nil, err
+	}
+	out := &sandboxv1alpha1.BatchSandboxTemplateSpec{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func expandJSONStrings(v any, vars map[string]string) any {
+	switch v := v.(type) {
+	case string:
+		return templateVarRef.ReplaceAllStringFunc(v, func(ref string) string {
+			if value, ok := vars[ref[2:len(ref)-1]]; ok {
+				return value
+			}
+			return ref
+		})
+	case map[string]any:
+		for k, item := range v {
+			v[k] = expandJSONStrings(item, vars)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = expandJSONStrings(item, vars)
+		}
+	}
+	return v
+}
+
+func batchSandboxSetParameterFinished(phase sandboxv1alpha1.BatchSandboxSetParameterPhase) bool {
+	return phase == sandboxv1alpha1.BatchSandboxSetParameterSucceeded || phase == sandboxv1alpha1.BatchSandboxSetParameterFailed
+}
+
+// batchSandboxSetChildPhase derives the parameter phase from the BatchSandbox status. A BatchSandbox with a
+// task template finishes once the task of every replica has finished.
+func batchSandboxSetChildPhase(child *sandboxv1alpha1.BatchSandbox) sandboxv1alpha1.BatchSandboxSetParameterPhase {
+	if child.Status.Phase == sandboxv1alpha1.BatchSandboxPhaseFailed {
+		return sandboxv1alpha1.BatchSandboxSetParameterFailed
+	}
+	replicas := int32(1)
+	if child.Spec.Replicas != nil {
+		replicas = *child.Spec.Replicas
+	}
+	if child.Spec.TaskTemplate != nil && replicas > 0 && child.Status.TaskSucceed+child.Status.TaskFailed >= replicas {
+		if child.Status.TaskFailed > 0 {
+			return sandboxv1alpha1.BatchSandboxSetParameterFailed
+		}
+		return sandboxv1alpha1.BatchSandboxSetParameterSucceeded
+	}
+	if child.Status.Ready >= replicas {
+		return sandboxv1alpha1.BatchSandboxSetParameterReady
+	}
+	return sandboxv1alpha1.BatchSandboxSetParameterCreated
+}
+
+// aggregateBatchSandboxSetStatus fills the counters and the phase of status from its parameters.
+func aggregateBatchSandboxSetStatus(set *sandboxv1alpha1.BatchSandboxSet, status *sandboxv1alpha1.BatchSandboxSetStatus, now time.Time) {
+	status.ObservedGeneration = set.Generation
+	status.Total = int32(len(set.Spec.Parameters))
+	status.Created = int32(len(status.Parameters))
+	status.Ready, status.Succeeded, status.Failed = 0, 0, 0
+	for _, st := range status.Parameters {
+		switch st.Phase {
+		case sandboxv1alpha1.BatchSandboxSetParameterReady:
+			status.Ready++
+		case sandboxv1alpha1.BatchSandboxSetParameterSucceeded:
+			status.Succeeded++
+		case sandboxv1alpha1.BatchSandboxSetParameterFailed:
+			status.Failed++
+		}
+	}
+	switch {
+	case status.Created == 0:
+		status.Phase = sandboxv1alpha1.BatchSandboxSetPhasePending
+	case status.Succeeded+status.Failed < status.Total:
+		status.Phase = sandboxv1alpha1.BatchSandboxSetPhaseRunning
+	case status.Failed > 0:
+		status.Phase = sandboxv1alpha1.BatchSandboxSetPhaseFailed
+	default:
+		status.Phase = sandboxv1alpha1.BatchSandboxSetPhaseSucceeded
+	}
+	finished := status.Phase == sandboxv1alpha1.BatchSandboxSetPhaseSucceeded || status.Phase == sandboxv1alpha1.BatchSandboxSetPhaseFailed
+	if !finished {
+		status.CompletionTime = nil
+	} else if status.CompletionTime == nil {
+		status.CompletionTime = &metav1.Time{Time: now}
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BatchSandboxSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&sandboxv1alpha1.BatchSandboxSet{}).
+		Owns(&sandboxv1alpha1.BatchSandbox{}).
+		Named("batchsandboxset").
+		Complete(r)
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func newTestBatchSandboxSet(params ...string) *sandboxv1alpha1.BatchSandboxSet {
+	set := &sandboxv1alpha1.BatchSandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "eval", Namespace: "default", UID: types.UID("uid-eval"), Generation: 1},
+		Spec: sandboxv1alpha1.BatchSandboxSetSpec{
+			Template: sandboxv1alpha1.BatchSandboxTemplateSpec{
+				Labels: map[string]string{"case": "$(name)"},
+				Spec: sandboxv1alpha1.BatchSandboxSpec{
+					Replicas: ptr.To(int32(1)),
+					PoolRef:  "pool",
+					TaskTemplate: &sandboxv1alpha1.TaskTemplateSpec{Spec: sandboxv1alpha1.TaskSpec{
+						Process: &sandboxv1alpha1.ProcessTask{Command: []string{"run", "--shard=$(shard)", "$(unknown)"}},
+					}},
+				},
+			},
+		},
+	}
+	for _, name := range params {
+		set.Spec.Parameters = append(set.Spec.Parameters, sandboxv1alpha1.BatchSandboxSetParameter{Name: name, Values: map[string]string{"shard": name + "-shard"}})
+	}
+	return set
+}
+
+func TestExpandBatchSandboxSetChild(t *testing.T) {
+	set := newTestBatchSandboxSet("a")
+	child, err := expandBatchSandboxSetChild(set, set.Spec.Parameters[0])
+	require.NoError(t, err)
+	assert.Equal(t, "eval-a", child.Name)
+	assert.Equal(t, map[string]string{"case": "a", LabelBatchSandboxSetNameKey: "eval", LabelBatchSandboxSetParameterKey: "a"}, child.Labels)
+	assert.Equal(t, []string{"run", "--shard=a-shard", "$(unknown)"}, child.Spec.TaskTemplate.Spec.Process.Command)
+	assert.Equal(t, "$(name)", set.Spec.Template.Labels["case"], "the template is not modified")
+
+	// Values take precedence over the parameter name.
+	set.Spec.Parameters[0].Values["name"] = "override"
+	child, err = expandBatchSandboxSetChild(set, set.Spec.Parameters[0])
+	require.NoError(t, err)
+	assert.Equal(t, "override", child.Labels["case"])
+}
+
+func TestBatchSandboxSetChildPhase(t *testing.T) {
+	withTask := func(status sandboxv1alpha1.BatchSandboxStatus) *sandboxv1alpha1.BatchSandbox {
+		return &sandboxv1alpha1.BatchSandbox{
+			Spec:   sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To(int32(2)), TaskTemplate: &sandboxv1alpha1.TaskTemplateSpec{}},
+			Status: status,
+		}
+	}
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetParameterCreated, batchSandboxSetChildPhase(withTask(sandboxv1alpha1.BatchSandboxStatus{Ready: 1})))
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetParameterReady, batchSandboxSetChildPhase(withTask(sandboxv1alpha1.BatchSandboxStatus{Ready: 2, TaskSucceed: 1})))
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetParameterSucceeded, batchSandboxSetChildPhase(withTask(sandboxv1alpha1.BatchSandboxStatus{TaskSucceed: 2})))
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetParameterFailed, batchSandboxSetChildPhase(withTask(sandboxv1alpha1.BatchSandboxStatus{TaskSucceed: 1, TaskFailed: 1})))
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetParameterFailed, batchSandboxSetChildPhase(withTask(sandboxv1alpha1.BatchSandboxStatus{Phase: sandboxv1alpha1.BatchSandboxPhaseFailed})))
+
+	noTask := &sandboxv1alpha1.BatchSandbox{Spec: sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To(int32(1))}, Status: sandboxv1alpha1.BatchSandboxStatus{Ready: 1}}
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetParameterReady, batchSandboxSetChildPhase(noTask))
+}
+
+func TestBatchSandboxSetReconcile(t *testing.T) {
+	ctx := context.Background()
+	set := newTestBatchSandboxSet("a", "b", "c")
+	set.Spec.Parallelism = ptr.To(int32(2))
+	set.Spec.TTLSecondsAfterFinished = ptr.To(int32(60))
+	t.Cleanup(func() { BatchSandboxSetExpectations.DeleteExpectations("default/eval") })
+
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(set).
+		WithStatusSubresource(&sandboxv1alpha1.BatchSandboxSet{}, &sandboxv1alpha1.BatchSandbox{}).Build()
+	r := &BatchSandboxSetReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(100)}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "eval"}}
+	reconcile := func() *sandboxv1alpha1.BatchSandboxSet {
+		_, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		got := &sandboxv1alpha1.BatchSandboxSet{}
+		require.NoError(t, c.Get(ctx, req.NamespacedName, got))
+		return got
+	}
+	children := func() []string {
+		list := &sandboxv1alpha1.BatchSandboxList{}
+		require.NoError(t, c.List(ctx, list))
+		var names []string
+		for _, bs := range list.Items {
+			names = append(names, bs.Name)
+		}
+		return names
+	}
+	succeed := func(name string) {
+		bs := &sandboxv1alpha1.BatchSandbox{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, bs))
+		bs.Status.TaskSucceed = 1
+		require.NoError(t, c.Status().Update(ctx, bs))
+	}
+
+	got := reconcile()
+	assert.ElementsMatch(t, []string{"eval-a", "eval-b"}, children(), "parallelism holds back the third")
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetPhaseRunning, got.Status.Phase)
+	assert.Equal(t, int32(3), got.Status.Total)
+	assert.Equal(t, int32(2), got.Status.Created)
+	child := &sandboxv1alpha1.BatchSandbox{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "eval-a"}, child))
+	assert.True(t, metav1.IsControlledBy(child, got))
+
+	succeed("eval-a")
+	got = reconcile()
+	assert.ElementsMatch(t, []string{"eval-a", "eval-b", "eval-c"}, children())
+	assert.Equal(t, int32(1), got.Status.Succeeded)
+
+	// A BatchSandbox deleted before it finished counts as failed and is not created again.
+	require.NoError(t, c.Delete(ctx, &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eval-b"}}))
+	succeed("eval-c")
+	got = reconcile()
+	assert.ElementsMatch(t, []string{"eval-a", "eval-c"}, children())
+	assert.Equal(t, int32(2), got.Status.Succeeded)
+	assert.Equal(t, int32(1), got.Status.Failed)
+	assert.Equal(t, sandboxv1alpha1.BatchSandboxSetPhaseFailed, got.Status.Phase)
+	require.NotNil(t, got.Status.CompletionTime)
+
+	// Once the TTL has passed the set is deleted.
+	got.Status.CompletionTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	require.NoError(t, c.Status().Update(ctx, got))
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, errors.IsNotFound(c.Get(ctx, req.NamespacedName, &sandboxv1alpha1.BatchSandboxSet{})))
+}
+
+func TestBatchSandboxSetRemovedParameter(t *testing.T) {
+	ctx := context.Background()
+	set := newTestBatchSandboxSet("a", "b")
+	t.Cleanup(func() { BatchSandboxSetExpectations.DeleteExpectations("default/eval") })
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(set).
+		WithStatusSubresource(&sandboxv1alpha1.BatchSandboxSet{}).Build()
+	r := &BatchSandboxSetReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(100)}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "eval"}}
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	latest := &sandboxv1alpha1.BatchSandboxSet{}
+	require.NoError(t, c.Get(ctx, req.NamespacedName, latest))
+	latest.Spec.Parameters = latest.Spec.Parameters[:1]
+	require.NoError(t, c.Update(ctx, latest))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	list := &sandboxv1alpha1.BatchSandboxList{}
+	require.NoError(t, c.List(ctx, list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "eval-a", list.Items[0].Name)
+	require.NoError(t, c.Get(ctx, req.NamespacedName, latest))
+	assert.Equal(t, int32(1), latest.Status.Total)
+	assert.Equal(t, []sandboxv1alpha1.BatchSandboxSetParameterStatus{{Name: "a", Phase: sandboxv1alpha1.BatchSandboxSetParameterCreated}}, latest.Status.Parameters)
+}
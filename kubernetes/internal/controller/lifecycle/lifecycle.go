@@ -0,0 +1,68 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lifecycle delivers sandbox lifecycle events to external systems, such as billing or
+// evaluation tracking, that should not have to watch the CRDs themselves.
+package lifecycle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// EventType names a lifecycle event.
+type EventType string
+
+const (
+	// SandboxAllocated is sent when pool pods are allocated to a BatchSandbox.
+	SandboxAllocated EventType = "SandboxAllocated"
+	// SandboxReleased is sent when pods released by a BatchSandbox are back in the pool.
+	SandboxReleased EventType = "SandboxReleased"
+	// TaskSucceeded is sent when a task of a BatchSandbox exits successfully.
+	TaskSucceeded EventType = "TaskSucceeded"
+	// TaskFailed is sent when a task of a BatchSandbox fails.
+	TaskFailed EventType = "TaskFailed"
+	// PoolSaturated is sent when a BatchSandbox starts waiting for pods its pool cannot create
+	// because it is at poolMax.
+	PoolSaturated EventType = "PoolSaturated"
+)
+
+// Event is the JSON body of a lifecycle event.
+type Event struct {
+	// ID identifies the event. Events may be delivered more than once, with the same ID.
+	ID           string    `json:"id"`
+	Type         EventType `json:"type"`
+	Time         time.Time `json:"time"`
+	Namespace    string    `json:"namespace"`
+	BatchSandbox string    `json:"batchSandbox,omitempty"`
+	Pool         string    `json:"pool,omitempty"`
+	// Pods are the pods the event is about: the pods allocated or released, or the pod of a task.
+	Pods    []string `json:"pods,omitempty"`
+	Task    string   `json:"task,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Notifier delivers lifecycle events. Notify must not block the reconcile it is called from.
+type Notifier interface {
+	Notify(event Event)
+}
+
+// EventID derives a stable event ID from what identifies the event, so an event emitted again after
+// a controller restart keeps its ID.
+func EventID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
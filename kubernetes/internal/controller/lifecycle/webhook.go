@@ -0,0 +1,268 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// HeaderSignature carries "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+	HeaderSignature = "X-OpenSandbox-Signature"
+	// HeaderEventType and HeaderEventID repeat the type and ID of the event in the body.
+	HeaderEventType = "X-OpenSandbox-Event"
+	HeaderEventID   = "X-OpenSandbox-Event-Id"
+
+	DefaultQueueSize   = 1000
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxAttempts = 5
+
+	// recentEvents is how many event IDs are remembered to drop events emitted again.
+	recentEvents = 10000
+)
+
+// WebhookOptions configures a WebhookNotifier.
+type WebhookOptions struct {
+	// URLs receive every event with a POST.
+	URLs []string
+	// Secret is the HMAC key events are signed with.
+	Secret []byte
+	// QueueSize is how many events may wait for delivery to a URL before new ones are dropped.
+	QueueSize int
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+	// MaxAttempts is how often a delivery is tried before the event is dropped.
+	MaxAttempts int
+	// Backoff is the wait before the second attempt, doubled for each further attempt.
+	Backoff time.Duration
+}
+
+// WebhookNotifier posts signed events to webhook URLs. Each URL has its own queue and worker, so a
+// slow receiver does not hold back the others. Events that were notified recently are dropped, so
+// reconciles that see the same change again do not deliver it twice.
+type WebhookNotifier struct {
+	opts      WebhookOptions
+	client    *http.Client
+	endpoints []*webhookEndpoint
+
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	recent []string
+}
+
+type webhookEndpoint struct {
+	url   string
+	queue chan webhookDelivery
+}
+
+type webhookDelivery struct {
+	event Event
+	body  []byte
+}
+
+// NewWebhookNotifier returns a notifier for opts. Nothing is delivered until it is started.
+func NewWebhookNotifier(opts WebhookOptions) (*WebhookNotifier, error) {
+	if len(opts.URLs) == 0 {
+		return nil, fmt.Errorf("no lifecycle webhook URLs")
+	}
+	if len(opts.Secret) == 0 {
+		return nil, fmt.Errorf("lifecycle webhooks need a signing secret")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	n := &WebhookNotifier{
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		seen:   make(map[string]struct{}),
+	}
+	for _, url := range opts.URLs {
+		n.endpoints = append(n.endpoints, &webhookEndpoint{url: url, queue: make(chan webhookDelivery, opts.QueueSize)})
+	}
+	return n, nil
+}
+
+// Notify queues the event for every URL, dropping it where the queue is full.
+func (n *WebhookNotifier) Notify(event Event) {
+	if !n.remember(event.ID) {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		logf.Log.Error(err, "Failed to encode lifecycle event", "type", event.Type, "id", event.ID)
+		return
+	}
+	for _, endpoint := range n.endpoints {
+		select {
+		case endpoint.queue <- webhookDelivery{event: event, body: body}:
+		default:
+			logf.Log.Info("Dropping lifecycle event, webhook queue is full", "url", endpoint.url, "type", event.Type, "id", event.ID)
+		}
+	}
+}
+
+// remember records the ID and reports whether it is new. Events without an ID are always new.
+func (n *WebhookNotifier) remember(id string) bool {
+	if id == "" {
+		return true
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.seen[id]; ok {
+		return false
+	}
+	n.seen[id] = struct{}{}
+	n.recent = append(n.recent, id)
+	if len(n.recent) > recentEvents {
+		delete(n.seen, n.recent[0])
+		n.recent = n.recent[1:]
+	}
+	return true
+}
+
+// Start delivers queued events until ctx is done.
+func (n *WebhookNotifier) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, endpoint := range n.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-endpoint.queue:
+					n.deliver(ctx, endpoint.url, delivery)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// NeedLeaderElection is false: only the leader emits events, and its queue drains regardless.
+func (n *WebhookNotifier) NeedLeaderElection() bool {
+	return false
+}
+
+func (n *WebhookNotifier) deliver(ctx context.Context, url string, delivery webhookDelivery) {
+	log := logf.FromContext(ctx).WithValues("url", url, "type", delivery.event.Type, "id", delivery.event.ID)
+	backoff := n.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := n.post(ctx, url, delivery)
+		if err == nil {
+			return
+		}
+		if attempt >= n.opts.MaxAttempts {
+			log.Error(err, "Dropping lifecycle event after failed deliveries", "attempts", attempt)
+			return
+		}
+		log.V(1).Info("Retrying lifecycle event delivery", "attempt", attempt, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n *WebhookNotifier) post(ctx context.Context, url string, delivery webhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, string(delivery.event.Type))
+	req.Header.Set(HeaderEventID, delivery.event.ID)
+	req.Header.Set(HeaderSignature, Sign(n.opts.Secret, time.Now(), delivery.body))
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header of body sent at t.
+func Sign(secret []byte, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + signature(secret, timestamp, body)
+}
+
+// Verify checks a signature header against body, rejecting signatures older than tolerance so a
+// captured request cannot be replayed later. Receivers written in Go can use it as is.
+func Verify(secret []byte, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sig = value
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || sig == "" {
+		return fmt.Errorf("malformed signature header")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp is outside the tolerance")
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, timestamp, body))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func signature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
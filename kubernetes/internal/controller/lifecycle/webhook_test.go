@@ -0,0 +1,146 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedEvent struct {
+	event  Event
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver fails the first failures requests and records the rest.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	events   []receivedEvent
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var event Event
+	_ = json.Unmarshal(body, &event)
+	r.events = append(r.events, receivedEvent{event: event, header: req.Header.Clone(), body: body})
+}
+
+func (r *webhookReceiver) received() []receivedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedEvent(nil), r.events...)
+}
+
+func startNotifier(t *testing.T, opts WebhookOptions) *WebhookNotifier {
+	n, err := NewWebhookNotifier(opts)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = n.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return n
+}
+
+func TestWebhookNotifierDeliversSignedEvents(t *testing.T) {
+	receiver := &webhookReceiver{failures: 2}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	secret := []byte("s3cret")
+	n := startNotifier(t, WebhookOptions{URLs: []string{server.URL}, Secret: secret, Backoff: time.Millisecond})
+
+	n.Notify(Event{ID: EventID("a"), Type: SandboxAllocated, Namespace: "default", BatchSandbox: "sbx", Pods: []string{"pod1"}})
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	got := receiver.received()[0]
+	assert.Equal(t, SandboxAllocated, got.event.Type)
+	assert.Equal(t, []string{"pod1"}, got.event.Pods)
+	assert.False(t, got.event.Time.IsZero())
+	assert.Equal(t, string(SandboxAllocated), got.header.Get(HeaderEventType))
+	assert.Equal(t, EventID("a"), got.header.Get(HeaderEventID))
+	assert.NoError(t, Verify(secret, got.header.Get(HeaderSignature), got.body, time.Minute, time.Now()))
+	assert.Error(t, Verify([]byte("other"), got.header.Get(HeaderSignature), got.body, time.Minute, time.Now()))
+}
+
+func TestWebhookNotifierDropsRepeatedEvents(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	n := startNotifier(t, WebhookOptions{URLs: []string{server.URL}, Secret: []byte("s"), Backoff: time.Millisecond})
+
+	n.Notify(Event{ID: EventID("a"), Type: TaskSucceeded})
+	n.Notify(Event{ID: EventID("a"), Type: TaskSucceeded})
+	n.Notify(Event{ID: EventID("b"), Type: TaskFailed})
+	require.Eventually(t, func() bool { return len(receiver.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	events := receiver.received()
+	require.Len(t, events, 2)
+	assert.Equal(t, TaskSucceeded, events[0].event.Type)
+	assert.Equal(t, TaskFailed, events[1].event.Type)
+}
+
+func TestWebhookNotifierGivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := &webhookReceiver{failures: 3}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	n := startNotifier(t, WebhookOptions{URLs: []string{server.URL}, Secret: []byte("s"), MaxAttempts: 3, Backoff: time.Millisecond})
+
+	n.Notify(Event{ID: EventID("a"), Type: PoolSaturated})
+	n.Notify(Event{ID: EventID("b"), Type: PoolSaturated})
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, EventID("b"), receiver.received()[0].event.ID)
+}
+
+func TestNewWebhookNotifierValidates(t *testing.T) {
+	_, err := NewWebhookNotifier(WebhookOptions{Secret: []byte("s")})
+	assert.Error(t, err)
+	_, err = NewWebhookNotifier(WebhookOptions{URLs: []string{"http://example.com"}})
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	secret, body := []byte("s"), []byte(`{"id":"x"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign(secret, now, body)
+
+	assert.NoError(t, Verify(secret, header, body, time.Minute, now.Add(30*time.Second)))
+	assert.ErrorContains(t, Verify(secret, header, body, time.Minute, now.Add(2*time.Minute)), "tolerance")
+	assert.ErrorContains(t, Verify(secret, header, []byte(`{"id":"y"}`), time.Minute, now), "mismatch")
+	assert.ErrorContains(t, Verify(secret, "v1=abc", body, time.Minute, now), "malformed")
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/lifecycle"
+)
+
+// notifyLifecycle sends a lifecycle event about the sandbox. The event ID is derived from the event
+// type, the sandbox UID and key, so that the same event emitted by a later reconcile keeps its ID.
+func notifyLifecycle(notifier lifecycle.Notifier, typ lifecycle.EventType, sbx *sandboxv1alpha1.BatchSandbox, pods []string,
+	task, message string, key ...string) {
+	if notifier == nil {
+		return
+	}
+	pods = slices.Sorted(slices.Values(pods))
+	notifier.Notify(lifecycle.Event{
+		ID:           lifecycle.EventID(append([]string{string(typ), string(sbx.UID)}, key...)...),
+		Type:         typ,
+		Time:         time.Now(),
+		Namespace:    sbx.Namespace,
+		BatchSandbox: sbx.Name,
+		Pool:         sbx.Spec.PoolRef,
+		Pods:         pods,
+		Task:         task,
+		Message:      message,
+	})
+}
+
+// notifyingSync wraps a sync of the allocation or release annotation of a sandbox so that typ is sent
+// for the pods in changed once the sync succeeded.
+func notifyingSync(notifier lifecycle.Notifier, typ lifecycle.EventType, changed map[string][]string,
+	syncFn func(context.Context, *sandboxv1alpha1.BatchSandbox, []string) error) func(context.Context, *sandboxv1alpha1.BatchSandbox, []string) error {
+	if notifier == nil {
+		return syncFn
+	}
+	return func(ctx context.Context, sbx *sandboxv1alpha1.BatchSandbox, pods []string) error {
+		if err := syncFn(ctx, sbx, pods); err != nil {
+			return err
+		}
+		if podNames := changed[sbx.Name]; len(podNames) > 0 {
+			sorted := slices.Sorted(slices.Values(podNames))
+			notifyLifecycle(notifier, typ, sbx, sorted, "", "", sorted...)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/lifecycle"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []lifecycle.Event
+}
+
+func (n *recordingNotifier) Notify(event lifecycle.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+}
+
+func TestNotifyingSync(t *testing.T) {
+	sbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default", UID: "uid-1"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool"},
+	}
+	changed := map[string][]string{"sbx": {"pod2", "pod1"}}
+	var syncErr error
+	syncFn := func(context.Context, *sandboxv1alpha1.BatchSandbox, []string) error { return syncErr }
+
+	notifier := &recordingNotifier{}
+	wrapped := notifyingSync(notifier, lifecycle.SandboxAllocated, changed, syncFn)
+
+	syncErr = errors.New("conflict")
+	require.Error(t, wrapped(context.Background(), sbx, []string{"pod0", "pod1", "pod2"}))
+	assert.Empty(t, notifier.events, "no event before the allocation is persisted")
+
+	syncErr = nil
+	require.NoError(t, wrapped(context.Background(), sbx, []string{"pod0", "pod1", "pod2"}))
+	require.Len(t, notifier.events, 1)
+	event := notifier.events[0]
+	assert.Equal(t, lifecycle.SandboxAllocated, event.Type)
+	assert.Equal(t, "default", event.Namespace)
+	assert.Equal(t, "sbx", event.BatchSandbox)
+	assert.Equal(t, "pool", event.Pool)
+	assert.Equal(t, []string{"pod1", "pod2"}, event.Pods, "only the newly allocated pods")
+	assert.Equal(t, lifecycle.EventID(string(lifecycle.SandboxAllocated), "uid-1", "pod1", "pod2"), event.ID)
+
+	// Without a notifier the sync is used as is.
+	assert.NoError(t, notifyingSync(nil, lifecycle.SandboxAllocated, changed, syncFn)(context.Background(), sbx, nil))
+}
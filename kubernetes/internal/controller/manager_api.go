@@ -0,0 +1,164 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// ManagerAPIServer serves the manager-level HTTP API used by sandbox consumers that cannot or should not
+// talk to pods directly. It is served over TLS only. Callers authenticate with a bearer token that is checked
+// with a TokenReview, and every route is authorized with a SubjectAccessReview against the object it reads or
+// acts on.
+//
+//	POST /v1/namespaces/{namespace}/batchsandboxes/{name}/tasks      submit tasks (task_dispatch.go)
+//	GET  /v1/namespaces/{namespace}/batchsandboxes/{name}/tasks      sync tasks (task_dispatch.go)
+//	GET  /v1/namespaces/{namespace}/pools/{name}/estimate?replicas=N  estimate capacity (pool_estimate.go)
+//	GET  /v1/namespaces/{namespace}/batchsandboxes/{name}/usage      sandbox usage (batchsandbox_usage.go)
+//	GET  /v1/namespaces/{namespace}/usage[?pool=NAME]                usage of the namespace (batchsandbox_usage.go)
+type ManagerAPIServer struct {
+	Client client.Client
+	// BindAddress is the address the API listens on.
+	BindAddress string
+	// TLSOpts configure the TLS server. One of them must provide the serving certificate, e.g. through
+	// GetCertificate of a certwatcher.
+	TLSOpts []func(*tls.Config)
+	// TaskDispatchTimeout bounds each call to a task-executor; defaults to 10s.
+	TaskDispatchTimeout time.Duration
+
+	// newTaskClient is overridden in tests.
+	newTaskClient func(pod *corev1.Pod) taskExecutorClient
+}
+
+var _ manager.Runnable = &ManagerAPIServer{}
+var _ manager.LeaderElectionRunnable = &ManagerAPIServer{}
+
+// NeedLeaderElection returns false: the API only reads cluster state and talks to task-executors, so it can
+// be served by every replica.
+func (s *ManagerAPIServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the API until the context is cancelled.
+func (s *ManagerAPIServer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("manager-api")
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	for _, opt := range s.TLSOpts {
+		opt(tlsConfig)
+	}
+	// Callers send bearer tokens, so the API is never served in plain text.
+	if tlsConfig.GetCertificate == nil && len(tlsConfig.Certificates) == 0 {
+		return fmt.Errorf("manager API requires a serving certificate")
+	}
+	srv := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.Handler(),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("starting manager API server", "addr", s.BindAddress)
+		if err := srv.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *ManagerAPIServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/namespaces/{namespace}/batchsandboxes/{name}/tasks", s.handleDispatch)
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/batchsandboxes/{name}/tasks", s.handleSync)
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/pools/{name}/estimate", s.handleEstimate)
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/batchsandboxes/{name}/usage", s.handleUsage)
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/usage", s.handleNamespaceUsage)
+	return mux
+}
+
+// reviewAccess authenticates the bearer token of the request and checks that its user may perform attrs.
+func (s *ManagerAPIServer) reviewAccess(ctx context.Context, r *http.Request, attrs *authorizationv1.ResourceAttributes) (int, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return http.StatusUnauthorized, fmt.Errorf("missing bearer token")
+	}
+	tr := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := s.Client.Create(ctx, tr); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !tr.Status.Authenticated {
+		return http.StatusUnauthorized, fmt.Errorf("invalid bearer token")
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(tr.Status.User.Extra))
+	for k, v := range tr.Status.User.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: attrs,
+		User:               tr.Status.User.Username,
+		UID:                tr.Status.User.UID,
+		Groups:             tr.Status.User.Groups,
+		Extra:              extra,
+	}}
+	if err := s.Client.Create(ctx, sar); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to review access: %w", err)
+	}
+	if !sar.Status.Allowed {
+		resource := attrs.Resource
+		if attrs.Subresource != "" {
+			resource += "/" + attrs.Subresource
+		}
+		return http.StatusForbidden, fmt.Errorf("user %q cannot %s %s of %s/%s",
+			tr.Status.User.Username, attrs.Verb, resource, attrs.Namespace, attrs.Name)
+	}
+	return http.StatusOK, nil
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/expectations"
+)
+
+// adoptPods takes over the orphan pods selected by spec.adoption, up to poolMax together with the owned
+// pods: it makes the pool their controller and labels them with the current revision. Ready pods are
+// adopted first. The adoptions are expected like creations, so the pool does not scale up before it sees
+// the adopted pods.
+func (r *PoolReconciler) adoptPods(ctx context.Context, pool *sandboxv1alpha1.Pool, owned int) error {
+	if pool.Spec.Adoption == nil || !pool.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	log := logf.FromContext(ctx)
+	selector, err := metav1.LabelSelectorAsSelector(pool.Spec.Adoption.Selector)
+	if err != nil || selector.Empty() {
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "InvalidAdoption", "Not adopting pods, invalid selector: %v", err)
+		return nil
+	}
+	room := int(pool.Spec.CapacitySpec.PoolMax) - owned
+	if room <= 0 {
+		return nil
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(pool.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	candidates := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		if pod := &podList.Items[i]; adoptable(pod) {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	revision, err := r.adoptionRevision(ctx, pool)
+	if err != nil {
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "InvalidTemplate", "Not adopting pods without a template revision: %v", err)
+		return nil
+	}
+	slices.SortStableFunc(candidates, func(a, b *corev1.Pod) int {
+		if ra, rb := utils.IsPodReady(a), utils.IsPodReady(b); ra != rb {
+			if ra {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	controllerKey := controllerutils.GetControllerKey(pool)
+	for _, pod := range candidates[:min(room, len(candidates))] {
+		patch := client.MergeFromWithOptions(pod.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if err := ctrl.SetControllerReference(pool, pod, r.Scheme); err != nil {
+			return err
+		}
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[LabelPoolName] = pool.Name
+		pod.Labels[LabelPoolRevision] = revision
+		PoolScaleExpectations.ExpectScale(controllerKey, expectations.Create, pod.Name)
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			PoolScaleExpectations.ObserveScale(controllerKey, expectations.Create, pod.Name)
+			// Another controller may have claimed the pod in the meantime; try again on the next reconcile.
+			log.Error(err, "Failed to adopt pod", "pod", pod.Name)
+			return fmt.Errorf("failed to adopt pod %s: %w", pod.Name, err)
+		}
+		log.Info("Adopted pod", "pool", pool.Name, "pod", pod.Name, "revision", revision)
+		r.Recorder.Eventf(pool, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted pod: %v", pod.Name)
+	}
+	return nil
+}
+
+// adoptable reports whether a pod may be adopted: it has no controller and still runs.
+func adoptable(pod *corev1.Pod) bool {
+	return metav1.GetControllerOf(pod) == nil && pod.DeletionTimestamp.IsZero() &&
+		pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// adoptionRevision returns the revision adopted pods are labeled with: the revision of the current
+// template, the one pods created now would get.
+func (r *PoolReconciler) adoptionRevision(ctx context.Context, pool *sandboxv1alpha1.Pool) (string, error) {
+	resolved, err := resolvePoolTemplate(ctx, r.Client, pool)
+	if err != nil {
+		return "", err
+	}
+	return r.calculatePoolRevision(pool, applyPoolTopologySpread(pool, applyPoolPriority(pool, resolved)))
+}
+
+// findPoolsForOrphanPod enqueues the pools whose adoption selector selects a pod without a controller.
+func (r *PoolReconciler) findPoolsForOrphanPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || !adoptable(pod) {
+		return nil
+	}
+	pools := &sandboxv1alpha1.PoolList{}
+	if err := r.List(ctx, pools, client.InNamespace(pod.Namespace)); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range pools.Items {
+		adoption := pools.Items[i].Spec.Adoption
+		if adoption == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(adoption.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pools.Items[i])})
+	}
+	return requests
+}
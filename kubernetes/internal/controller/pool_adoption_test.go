@@ -0,0 +1,153 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/expectations"
+)
+
+func newAdoptionPool(poolMax int32) *sandboxv1alpha1.Pool {
+	return &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "adopting", Namespace: "default", UID: "pool-uid"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}},
+			CapacitySpec: sandboxv1alpha1.CapacitySpec{
+				PoolMax: poolMax,
+			},
+			Adoption: &sandboxv1alpha1.PoolAdoption{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "warm"}}},
+		},
+	}
+}
+
+func newFleetPod(name string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"fleet": "warm"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestAdoptPods(t *testing.T) {
+	ctx := context.Background()
+	pool := newAdoptionPool(3)
+	controllerKey := controllerutils.GetControllerKey(pool)
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerKey) })
+
+	controlled := newFleetPod("controlled", true)
+	controlled.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "rs-uid", Controller: ptr.To(true)}}
+	finished := newFleetPod("finished", true)
+	finished.Status.Phase = corev1.PodSucceeded
+	unselected := newFleetPod("unselected", true)
+	unselected.Labels = map[string]string{"fleet": "cold"}
+
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		newFleetPod("a-warming", false), newFleetPod("b-ready", true), newFleetPod("c-ready", true),
+		controlled, finished, unselected,
+	).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	revision, err := r.adoptionRevision(ctx, pool)
+	require.NoError(t, err)
+
+	// One pod is already owned, so two more fit into poolMax; ready pods go first.
+	require.NoError(t, r.adoptPods(ctx, pool, 1))
+
+	adopted := func(name string) bool {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil || owner.UID != pool.UID {
+			return false
+		}
+		assert.Equal(t, pool.Name, pod.Labels[LabelPoolName], name)
+		assert.Equal(t, revision, pod.Labels[LabelPoolRevision], name)
+		assert.Equal(t, "warm", pod.Labels["fleet"], name)
+		return true
+	}
+	assert.True(t, adopted("b-ready"))
+	assert.True(t, adopted("c-ready"))
+	assert.False(t, adopted("a-warming"), "poolMax leaves no room")
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(controlled), pod))
+	assert.Equal(t, "rs", metav1.GetControllerOf(pod).Name, "pods with a controller are left alone")
+	for _, name := range []string{"finished", "unselected"} {
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+		assert.Empty(t, pod.OwnerReferences, name)
+	}
+
+	satisfied, _, dirty := PoolScaleExpectations.SatisfiedExpectations(controllerKey)
+	assert.False(t, satisfied, "the pool waits to see the adopted pods")
+	assert.ElementsMatch(t, []string{"b-ready", "c-ready"}, dirty[expectations.Create])
+}
+
+func TestAdoptPodsSkipped(t *testing.T) {
+	ctx := context.Background()
+	pod := newFleetPod("pod", true)
+
+	for name, pool := range map[string]*sandboxv1alpha1.Pool{
+		"no adoption": func() *sandboxv1alpha1.Pool { p := newAdoptionPool(3); p.Spec.Adoption = nil; return p }(),
+		"empty selector": func() *sandboxv1alpha1.Pool {
+			p := newAdoptionPool(3)
+			p.Spec.Adoption.Selector = &metav1.LabelSelector{}
+			return p
+		}(),
+		"pool full": newAdoptionPool(1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pod.DeepCopy()).Build()
+			r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+			require.NoError(t, r.adoptPods(ctx, pool, 1))
+			got := &corev1.Pod{}
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pod), got))
+			assert.Empty(t, got.OwnerReferences)
+		})
+	}
+}
+
+func TestFindPoolsForOrphanPod(t *testing.T) {
+	ctx := context.Background()
+	other := newAdoptionPool(3)
+	other.Name = "other"
+	other.Spec.Adoption.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "cold"}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(newAdoptionPool(3), other).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme}
+
+	requests := r.findPoolsForOrphanPod(ctx, newFleetPod("pod", true))
+	require.Len(t, requests, 1)
+	assert.Equal(t, "adopting", requests[0].Name)
+
+	owned := newFleetPod("owned", true)
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "Pool", Name: "adopting", UID: "pool-uid", Controller: ptr.To(true)}}
+	assert.Empty(t, r.findPoolsForOrphanPod(ctx, owned))
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// repairCorruptedAllocations keeps a malformed alloc-status annotation from blocking the whole pool.
+// The bad payload is moved to AnnoAllocStatusCorruptedKey and replaced by the pods labeled as
+// allocated to the sandbox, which is then synced into the allocator.
+func (r *PoolReconciler) repairCorruptedAllocations(ctx context.Context, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) error {
+	var errs []error
+	for _, sbx := range batchSandboxes {
+		if _, err := parseSandboxAllocation(sbx); err == nil {
+			continue
+		}
+		if err := r.repairSandboxAllocation(ctx, sbx, pods); err != nil {
+			errs = append(errs, fmt.Errorf("failed to repair allocation of sandbox %s: %w", sbx.Name, err))
+		}
+	}
+	return gerrors.Join(errs...)
+}
+
+func (r *PoolReconciler) repairSandboxAllocation(ctx context.Context, sbx *sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) error {
+	log := logf.FromContext(ctx)
+	raw := sbx.Annotations[AnnoAllocStatusKey]
+	reconstructed := podsAllocatedTo(pods, sbx.Name)
+
+	old := sbx.DeepCopy()
+	sbx.Annotations[AnnoAllocStatusCorruptedKey] = raw
+	setSandboxAllocation(sbx, SandboxAllocation{Pods: reconstructed})
+	if err := r.Patch(ctx, sbx, client.MergeFrom(old)); err != nil {
+		return err
+	}
+	log.Info("Quarantined corrupted sandbox allocation", "sandbox", sbx.Name, "payload", raw, "reconstructedPods", reconstructed)
+	r.Recorder.Eventf(sbx, corev1.EventTypeWarning, "CorruptedAllocation",
+		"Malformed %s annotation moved to %s, reconstructed %d pods from pod labels", AnnoAllocStatusKey, AnnoAllocStatusCorruptedKey, len(reconstructed))
+	return r.Allocator.SyncSandboxAllocation(ctx, sbx, reconstructed)
+}
+
+// podsAllocatedTo returns the names of the pods labeled as allocated to the given sandbox.
+func podsAllocatedTo(pods []*corev1.Pod, sandboxName string) []string {
+	ret := make([]string, 0)
+	for _, pod := range pods {
+		if pod.Labels[LabelAllocatedTo] == sandboxName {
+			ret = append(ret, pod.Name)
+		}
+	}
+	return ret
+}
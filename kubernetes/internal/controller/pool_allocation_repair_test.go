@@ -0,0 +1,88 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestRepairCorruptedAllocations(t *testing.T) {
+	ctx := context.Background()
+	healthy := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{
+		Name:        "healthy",
+		Namespace:   "default",
+		Annotations: map[string]string{AnnoAllocStatusKey: `{"pods":["pod2"]}`},
+	}}
+	corrupted := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{
+		Name:        "corrupted",
+		Namespace:   "default",
+		Annotations: map[string]string{AnnoAllocStatusKey: `{"pods":`},
+	}}
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", Labels: map[string]string{LabelAllocatedTo: "corrupted"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default", Labels: map[string]string{LabelAllocatedTo: "healthy"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod3", Namespace: "default"}},
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	allocator := NewMockAllocator(mockCtrl)
+	allocator.EXPECT().SyncSandboxAllocation(gomock.Any(), gomock.Any(), []string{"pod1"}).Return(nil).Times(1)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(testscheme).WithObjects(healthy, corrupted).Build(),
+		Scheme:    testscheme,
+		Recorder:  recorder,
+		Allocator: allocator,
+	}
+
+	err := r.repairCorruptedAllocations(ctx, []*sandboxv1alpha1.BatchSandbox{healthy, corrupted}, pods)
+	assert.NoError(t, err)
+
+	got := &sandboxv1alpha1.BatchSandbox{}
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(corrupted), got))
+	assert.Equal(t, `{"pods":`, got.Annotations[AnnoAllocStatusCorruptedKey])
+	alloc, err := parseSandboxAllocation(got)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pod1"}, alloc.Pods)
+
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(healthy), got))
+	assert.NotContains(t, got.Annotations, AnnoAllocStatusCorruptedKey)
+
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "CorruptedAllocation")
+}
+
+func TestApplyAllocatedToLabel(t *testing.T) {
+	pod := &corev1.Pod{}
+	assert.False(t, applyAllocatedToLabel(pod, ""))
+	assert.True(t, applyAllocatedToLabel(pod, "sbx"))
+	assert.Equal(t, "sbx", pod.Labels[LabelAllocatedTo])
+	assert.False(t, applyAllocatedToLabel(pod, "sbx"))
+	assert.True(t, applyAllocatedToLabel(pod, ""))
+	assert.NotContains(t, pod.Labels, LabelAllocatedTo)
+}
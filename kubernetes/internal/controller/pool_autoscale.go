@@ -0,0 +1,145 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+const (
+	defaultAutoscalingWindow = 10 * time.Minute
+	// autoscalingStartupQuantile is the pod startup latency the buffer covers by default.
+	autoscalingStartupQuantile = 0.9
+)
+
+var poolAllocations = newAllocationTracker()
+
+// allocationEvent is a number of pods allocated in the same scheduling round.
+type allocationEvent struct {
+	at    time.Time
+	count int
+}
+
+// allocationTracker counts pod allocations per pool. It lives in memory only, so the rate starts
+// over when the controller restarts.
+type allocationTracker struct {
+	mu sync.Mutex
+	// allocation is poolKey -> pod -> sandbox at the last observation.
+	allocation map[string]map[string]string
+	// events is poolKey -> allocations, oldest first.
+	events map[string][]allocationEvent
+}
+
+func newAllocationTracker() *allocationTracker {
+	return &allocationTracker{
+		allocation: make(map[string]map[string]string),
+		events:     make(map[string][]allocationEvent),
+	}
+}
+
+// Observe records the pods allocated since the previous observation of the pool, including pods
+// handed over to another sandbox. The first observation of a pool only sets the baseline.
+func (t *allocationTracker) Observe(namespace, pool string, podAllocation map[string]string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := namespace + "/" + pool
+	prev, seen := t.allocation[key]
+	current := make(map[string]string, len(podAllocation))
+	allocated := 0
+	for pod, sandbox := range podAllocation {
+		current[pod] = sandbox
+		if seen && prev[pod] != sandbox {
+			allocated++
+		}
+	}
+	t.allocation[key] = current
+	if allocated > 0 {
+		t.events[key] = append(t.events[key], allocationEvent{at: now, count: allocated})
+	}
+}
+
+// Count returns the number of pods allocated within the window before now and when the oldest of
+// them leaves the window, dropping older allocations.
+func (t *allocationTracker) Count(namespace, pool string, window time.Duration, now time.Time) (int, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := namespace + "/" + pool
+	events := t.events[key]
+	start := now.Add(-window)
+	for len(events) > 0 && !events[0].at.After(start) {
+		events = events[1:]
+	}
+	if len(events) == 0 {
+		delete(t.events, key)
+		return 0, time.Time{}
+	}
+	t.events[key] = events
+	count := 0
+	for _, event := range events {
+		count += event.count
+	}
+	return count, events[0].at.Add(window)
+}
+
+// Forget drops the tracked allocations of a deleted pool.
+func (t *allocationTracker) Forget(namespace, pool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.allocation, namespace+"/"+pool)
+	delete(t.events, namespace+"/"+pool)
+}
+
+// AutoscaleResult is the buffer size chosen from the observed demand of a pool.
+type AutoscaleResult struct {
+	// TargetBuffer is the buffer size to converge to, nil when autoscaling is disabled.
+	TargetBuffer *int32
+	// RequeueAfter is when the oldest counted allocation leaves the window and the target may drop.
+	RequeueAfter time.Duration
+}
+
+// autoscaleBuffer sizes the buffer to the allocations expected over the lead time at the rate
+// observed within the window, within BufferMin and BufferMax.
+func autoscaleBuffer(pool *sandboxv1alpha1.Pool, now time.Time) *AutoscaleResult {
+	result := &AutoscaleResult{}
+	autoscaling := pool.Spec.CapacitySpec.Autoscaling
+	if autoscaling == nil {
+		return result
+	}
+	window := defaultAutoscalingWindow
+	if autoscaling.Window != nil && autoscaling.Window.Duration > 0 {
+		window = autoscaling.Window.Duration
+	}
+	leadTime := window
+	if autoscaling.LeadTime != nil && autoscaling.LeadTime.Duration > 0 {
+		leadTime = autoscaling.LeadTime.Duration
+	} else if startup, ok := podStartupQuantile(pool.Namespace, pool.Name, podStartupStageReady, autoscalingStartupQuantile); ok && startup > 0 {
+		leadTime = startup
+	}
+
+	count, expiresAt := poolAllocations.Count(pool.Namespace, pool.Name, window, now)
+	demand := int32(math.Ceil(float64(count) * leadTime.Seconds() / window.Seconds()))
+	target := min(max(demand, pool.Spec.CapacitySpec.BufferMin), pool.Spec.CapacitySpec.BufferMax)
+	result.TargetBuffer = &target
+	if !expiresAt.IsZero() {
+		result.RequeueAfter = expiresAt.Sub(now) + scheduleBoundarySlack
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestAllocationTracker(t *testing.T) {
+	tracker := newAllocationTracker()
+	now := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+
+	// The first observation is the baseline.
+	tracker.Observe("ns", "pool", map[string]string{"p1": "sbx-a"}, now)
+	count, _ := tracker.Count("ns", "pool", time.Minute, now)
+	assert.Zero(t, count)
+
+	tracker.Observe("ns", "pool", map[string]string{"p1": "sbx-b", "p2": "sbx-b", "p3": "sbx-c"}, now.Add(10*time.Second))
+	// Releasing pods is no demand.
+	tracker.Observe("ns", "pool", map[string]string{"p3": "sbx-c"}, now.Add(20*time.Second))
+	tracker.Observe("ns", "pool", map[string]string{"p3": "sbx-c", "p4": "sbx-d"}, now.Add(30*time.Second))
+
+	count, expiresAt := tracker.Count("ns", "pool", time.Minute, now.Add(40*time.Second))
+	assert.Equal(t, 4, count)
+	assert.Equal(t, now.Add(70*time.Second), expiresAt)
+
+	count, expiresAt = tracker.Count("ns", "pool", time.Minute, now.Add(80*time.Second))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, now.Add(90*time.Second), expiresAt)
+
+	tracker.Forget("ns", "pool")
+	tracker.Observe("ns", "pool", map[string]string{"p5": "sbx-e"}, now.Add(90*time.Second))
+	count, expiresAt = tracker.Count("ns", "pool", time.Minute, now.Add(90*time.Second))
+	assert.Zero(t, count)
+	assert.True(t, expiresAt.IsZero())
+}
+
+func TestAutoscaleBuffer(t *testing.T) {
+	now := time.Now()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "autoscale-pool", Namespace: "default"},
+		Spec: sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{
+			BufferMin: 2, BufferMax: 20, PoolMax: 100,
+		}},
+	}
+	defer poolAllocations.Forget(pool.Namespace, pool.Name)
+
+	assert.Nil(t, autoscaleBuffer(pool, now).TargetBuffer, "disabled without autoscaling")
+
+	pool.Spec.CapacitySpec.Autoscaling = &sandboxv1alpha1.BufferAutoscaling{
+		Window:   &metav1.Duration{Duration: 10 * time.Minute},
+		LeadTime: &metav1.Duration{Duration: time.Minute},
+	}
+	result := autoscaleBuffer(pool, now)
+	require.NotNil(t, result.TargetBuffer)
+	assert.Equal(t, int32(2), *result.TargetBuffer, "no demand keeps BufferMin")
+	assert.Zero(t, result.RequeueAfter)
+
+	allocation := map[string]string{}
+	poolAllocations.Observe(pool.Namespace, pool.Name, allocation, now.Add(-5*time.Minute))
+	for i := range 45 {
+		allocation[fmt.Sprintf("pod-%d", i)] = "sbx"
+	}
+	poolAllocations.Observe(pool.Namespace, pool.Name, allocation, now.Add(-5*time.Minute))
+
+	// 45 allocations in 10 minutes need 4.5 pods per minute of lead time.
+	result = autoscaleBuffer(pool, now)
+	require.NotNil(t, result.TargetBuffer)
+	assert.Equal(t, int32(5), *result.TargetBuffer)
+	assert.Equal(t, 5*time.Minute+scheduleBoundarySlack, result.RequeueAfter)
+
+	pool.Spec.CapacitySpec.Autoscaling.LeadTime = &metav1.Duration{Duration: 5 * time.Minute}
+	assert.Equal(t, int32(20), *autoscaleBuffer(pool, now).TargetBuffer, "capped at BufferMax")
+
+	result = autoscaleBuffer(pool, now.Add(6*time.Minute))
+	assert.Equal(t, int32(2), *result.TargetBuffer, "demand left the window")
+}
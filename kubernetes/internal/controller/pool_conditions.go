@@ -0,0 +1,67 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// setPoolConditions derives the pool conditions from the counters of pool.Status. bufferMin is the buffer
+// the pool is expected to keep: the autoscaled target when autoscaling is enabled, capacitySpec.bufferMin
+// otherwise.
+func setPoolConditions(pool *sandboxv1alpha1.Pool, bufferMin int32) {
+	status := &pool.Status
+	bufferSatisfied := status.Available >= bufferMin
+	buffer := metav1.Condition{
+		Type:    sandboxv1alpha1.PoolConditionBufferSatisfied,
+		Status:  metav1.ConditionTrue,
+		Reason:  "BufferAvailable",
+		Message: fmt.Sprintf("%d pods available, buffer minimum is %d", status.Available, bufferMin),
+	}
+	if !bufferSatisfied {
+		buffer.Status, buffer.Reason = metav1.ConditionFalse, "BufferBelowMinimum"
+	}
+
+	// A full pool without any available pod cannot serve the next allocation either, even without a buffer.
+	exhausted := metav1.Condition{
+		Type:    sandboxv1alpha1.PoolConditionCapacityExhausted,
+		Status:  metav1.ConditionFalse,
+		Reason:  "CapacityAvailable",
+		Message: fmt.Sprintf("%d of at most %d pods", status.Total, pool.Spec.CapacitySpec.PoolMax),
+	}
+	if status.Total >= pool.Spec.CapacitySpec.PoolMax && status.Available < max(bufferMin, 1) {
+		exhausted.Status, exhausted.Reason = metav1.ConditionTrue, "PoolMaxReached"
+	}
+
+	rollout := metav1.Condition{
+		Type:    sandboxv1alpha1.PoolConditionTemplateRollingOut,
+		Status:  metav1.ConditionFalse,
+		Reason:  "RolloutComplete",
+		Message: fmt.Sprintf("%d of %d pods at revision %s", status.Updated, status.Total, status.Revision),
+	}
+	if status.Updated < status.Total {
+		rollout.Status, rollout.Reason = metav1.ConditionTrue, "PodsOutdated"
+	}
+
+	for _, cond := range []metav1.Condition{buffer, exhausted, rollout} {
+		cond.ObservedGeneration = pool.Generation
+		meta.SetStatusCondition(&status.Conditions, cond)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestSetPoolConditions(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec:       sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{BufferMin: 2, PoolMax: 5}},
+		Status:     sandboxv1alpha1.PoolStatus{Total: 3, Available: 2, Updated: 3, Revision: "rev"},
+	}
+	status := func(condType string) metav1.ConditionStatus {
+		cond := meta.FindStatusCondition(pool.Status.Conditions, condType)
+		require.NotNil(t, cond, condType)
+		assert.Equal(t, int64(3), cond.ObservedGeneration)
+		return cond.Status
+	}
+
+	setPoolConditions(pool, 2)
+	assert.Equal(t, metav1.ConditionTrue, status(sandboxv1alpha1.PoolConditionBufferSatisfied))
+	assert.Equal(t, metav1.ConditionFalse, status(sandboxv1alpha1.PoolConditionCapacityExhausted))
+	assert.Equal(t, metav1.ConditionFalse, status(sandboxv1alpha1.PoolConditionTemplateRollingOut))
+
+	// The pool is full, one pod short of its buffer, and a new revision is rolling out.
+	pool.Status = sandboxv1alpha1.PoolStatus{Total: 5, Available: 1, Updated: 2, Revision: "rev2", Conditions: pool.Status.Conditions}
+	setPoolConditions(pool, 2)
+	assert.Equal(t, metav1.ConditionFalse, status(sandboxv1alpha1.PoolConditionBufferSatisfied))
+	assert.Equal(t, metav1.ConditionTrue, status(sandboxv1alpha1.PoolConditionCapacityExhausted))
+	assert.Equal(t, metav1.ConditionTrue, status(sandboxv1alpha1.PoolConditionTemplateRollingOut))
+
+	// Without a buffer a full pool is only exhausted once no pod is available.
+	setPoolConditions(pool, 0)
+	assert.Equal(t, metav1.ConditionTrue, status(sandboxv1alpha1.PoolConditionBufferSatisfied))
+	assert.Equal(t, metav1.ConditionFalse, status(sandboxv1alpha1.PoolConditionCapacityExhausted))
+	pool.Status.Available = 0
+	setPoolConditions(pool, 0)
+	assert.Equal(t, metav1.ConditionTrue, status(sandboxv1alpha1.PoolConditionCapacityExhausted))
+	assert.Len(t, pool.Status.Conditions, 3)
+}
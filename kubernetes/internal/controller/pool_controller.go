@@ -20,29 +20,38 @@ import (
 	"encoding/hex"
 	gerrors "errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"sync"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -51,6 +60,7 @@ import (
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/eviction"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/lifecycle"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/recycle"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
 	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
@@ -103,13 +113,26 @@ type PoolReconciler struct {
 	Recorder   record.EventRecorder
 	Allocator  Allocator
 	RestConfig *rest.Config
+	// Ownership, if set, lets several manager replicas reconcile disjoint sets of Pools instead of
+	// relying on leader election.
+	Ownership *PoolOwnership
+	// Lifecycle receives SandboxAllocated, SandboxReleased and PoolSaturated events, nil to send none.
+	Lifecycle lifecycle.Notifier
+	// PodDeletion bounds how fast the pods of a pool are deleted, nil to delete them
+	// DefaultPoolDeleteConcurrency at a time without a rate limit.
+	PodDeletion *PodDeletionLimiter
 }
 
 // +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=pools,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=pools/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=pools/finalizers,verbs=update
-// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=batchsandboxes,verbs=get;list;watch;patch;delete
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=allocations,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=reservations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=sandbox.opensandbox.io,resources=reservations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
@@ -124,6 +147,13 @@ func (r *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 			controllerKey := req.NamespacedName.String()
 			PoolScaleExpectations.DeleteExpectations(controllerKey)
 			r.Allocator.ClearPoolAllocation(ctx, req.Namespace, req.Name)
+			poolPodStartup.Forget(req.Namespace, req.Name)
+			poolAllocations.Forget(req.Namespace, req.Name)
+			poolSandboxAllocation.Forget(req.Namespace, req.Name)
+			forgetPoolMetrics(req.Namespace, req.Name)
+			r.PodDeletion.forget(controllerKey)
+			poolWarmReclaims.Delete(req.Namespace + "/" + req.Name)
+			poolIdleSince.Delete(req.Namespace + "/" + req.Name)
 			log.Info("Pool resource not found, cleaned up scale expectations", "pool", controllerKey)
 			return ctrl.Result{}, nil
 		}
@@ -131,14 +161,51 @@ func (r *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		log.Error(err, "Failed to get Pool")
 		return ctrl.Result{}, err
 	}
-	if !pool.DeletionTimestamp.IsZero() {
+	// A protected pool keeps being reconciled while it is deleted, so that its allocations can be released.
+	if !pool.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(pool, FinalizerPoolProtection) {
 		controllerKey := controllerutils.GetControllerKey(pool)
 		PoolScaleExpectations.DeleteExpectations(controllerKey)
 		r.Allocator.ClearPoolAllocation(ctx, req.Namespace, req.Name)
+		poolPodStartup.Forget(req.Namespace, req.Name)
+		poolAllocations.Forget(req.Namespace, req.Name)
+		poolSandboxAllocation.Forget(req.Namespace, req.Name)
+		forgetPoolMetrics(req.Namespace, req.Name)
+		poolWarmReclaims.Delete(req.Namespace + "/" + req.Name)
+		poolIdleSince.Delete(req.Namespace + "/" + req.Name)
 		log.Info("Pool resource is being deleted, cleaned up scale expectations", "pool", controllerKey)
 		return ctrl.Result{}, nil
 	}
 
+	if r.Ownership != nil {
+		owned, acquired, recheckAfter, err := r.Ownership.Acquire(ctx, pool)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !owned {
+			log.V(1).Info("Pool is owned by another replica", "recheckAfter", recheckAfter)
+			return ctrl.Result{RequeueAfter: recheckAfter}, nil
+		}
+		defer r.Ownership.Done(pool)
+		if acquired {
+			// Allocations may have been changed by the previous owner since this replica last saw the pool.
+			if err := r.Allocator.RecoverPoolAllocation(ctx, pool.Namespace, pool.Name); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if pool.DeletionTimestamp.IsZero() {
+		if rolledBack, err := r.rollbackPool(ctx, pool); err != nil || rolledBack {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncDeletionProtection(pool); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncDisruptionBudget(ctx, pool); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// List all pods of the pool
 	podList := &corev1.PodList{}
 	if err := r.List(ctx, podList, &client.ListOptions{
@@ -148,14 +215,20 @@ func (r *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		log.Error(err, "Failed to list pods")
 		return reconcile.Result{}, err
 	}
+	controllerKey := controllerutils.GetControllerKey(pool)
 	pods := make([]*corev1.Pod, 0, len(podList.Items))
 	for i := range podList.Items {
 		pod := podList.Items[i]
-		PoolScaleExpectations.ObserveScale(controllerutils.GetControllerKey(pool), expectations.Create, pod.Name)
+		PoolScaleExpectations.ObserveScale(controllerKey, expectations.Create, pod.Name)
 		if pod.DeletionTimestamp.IsZero() {
 			pods = append(pods, &pod)
 		}
 	}
+	observePoolPodDeletions(controllerKey, pods)
+	poolPodStartup.Observe(pool.Namespace, pool.Name, pods)
+	if err := r.adoptPods(ctx, pool, len(podList.Items)); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// List all batch sandboxes  ref to the pool
 	batchSandboxList := &sandboxv1alpha1.BatchSandboxList{}
@@ -189,70 +262,183 @@ func (r *PoolReconciler) reconcilePool(ctx context.Context, pool *sandboxv1alpha
 			return err
 		}
 
+		// The active schedule window replaces the capacity of this in-memory copy only; the pool is
+		// written back through the status subresource and finalizer updates of a fresh copy.
+		capacity, scheduleErr := effectiveCapacity(latestPool, time.Now())
+		if scheduleErr != nil {
+			r.Recorder.Eventf(latestPool, corev1.EventTypeWarning, "InvalidSchedule", "Ignoring capacity schedule windows: %v", scheduleErr)
+		}
+		schedule := latestPool.Spec.CapacitySpec.Schedule
+		latestPool.Spec.CapacitySpec = capacity.Capacity
+		latestPool.Spec.CapacitySpec.Schedule = schedule
+		requeueSooner(&result, capacity.RequeueAfter)
+
 		// 2. Handle pod eviction
 		schedulePods, evictionErr := r.handleEviction(ctx, latestPool, pods)
 		if schedulePods == nil {
 			return evictionErr
 		}
 
-		// 3. Schedule sandbox (compute + persist + sync)
-		schedResult, err := r.scheduleSandbox(ctx, latestPool, batchSandboxes, schedulePods)
+		// An unresolvable template only stops pod creation and rollout; existing pods keep being
+		// allocated and released.
+		resolved, templateErr := resolvePoolTemplate(ctx, r.Client, latestPool)
+		if templateErr != nil {
+			r.Recorder.Eventf(latestPool, corev1.EventTypeWarning, "InvalidTemplate", "Failed to resolve pod template: %v", templateErr)
+		}
+		template := applyPoolTopologySpread(latestPool, applyPoolPriority(latestPool, resolved))
+
+		// 3. Schedule sandbox (compute + persist + sync). A pool being deleted only serves the
+		// sandboxes that already hold its pods, so that they can release them.
+		deleting := !latestPool.DeletionTimestamp.IsZero()
+		scheduled := batchSandboxes
+		if deleting {
+			scheduled = make([]*sandboxv1alpha1.BatchSandbox, 0, len(batchSandboxes))
+			for _, sandbox := range batchSandboxes {
+				if holdsPoolPods(ctx, r.Client, sandbox) {
+					scheduled = append(scheduled, sandbox)
+				}
+			}
+		}
+		latestRevision, err := r.latestRevision(latestPool, template)
 		if err != nil {
 			return err
 		}
-		// Requeue if there are pending sandboxes waiting for scheduling
-		if schedResult.SupplyCnt > 0 {
-			result = ctrl.Result{RequeueAfter: defaultRetryTime}
+		schedResult, err := r.scheduleSandbox(ctx, latestPool, scheduled, schedulePods, latestRevision)
+		if err != nil {
+			return err
 		}
-
-		// 4. Handle pool upgrade
-		updateResult, err := r.updatePool(ctx, latestPool, schedulePods, schedResult.IdlePods)
+		poolPodSupplement.WithLabelValues(latestPool.Namespace, latestPool.Name).Set(float64(schedResult.SupplyCnt))
+		// Requeue if there are pending sandboxes waiting for scheduling or pods still recycling
+		if schedResult.SupplyCnt > 0 || schedResult.RecyclePending {
+			requeueSooner(&result, defaultRetryTime)
+		}
+		if !schedResult.PreemptionDeadline.IsZero() {
+			requeueSooner(&result, time.Until(schedResult.PreemptionDeadline))
+		}
+		if !schedResult.ReservationExpiry.IsZero() {
+			requeueSooner(&result, time.Until(schedResult.ReservationExpiry))
+		}
+		// Demand autoscaling counts the allocations of this round as well.
+		poolAllocations.Observe(latestPool.Namespace, latestPool.Name, schedResult.LatestAllocation, time.Now())
+		poolSandboxAllocation.Observe(latestPool.Namespace, latestPool.Name, batchSandboxes, schedResult.LatestAllocation, time.Now())
+		autoscale := autoscaleBuffer(latestPool, time.Now())
+		requeueSooner(&result, autoscale.RequeueAfter)
+		// Failing to report saturation must not hold back scaling.
+		saturationErr := r.syncPoolSaturation(ctx, latestPool, batchSandboxes, schedResult, int32(len(pods)))
+
+		// 4. Handle pool upgrade, once the nodes have pulled the images of the latest revision.
+		prePull, prePullAfter, err := r.syncImagePrePull(ctx, latestPool, template, latestRevision, schedulePods, time.Now())
 		if err != nil {
 			return err
 		}
+		requeueSooner(&result, prePullAfter)
+		updateResult := &UpdateResult{UpdateRevision: latestRevision, IdlePods: schedResult.IdlePods}
+		if prePull == nil || prePull.Phase != sandboxv1alpha1.ImagePrePullPhasePulling {
+			if updateResult, err = r.updatePool(ctx, latestPool, template, schedulePods, schedResult.IdlePods); err != nil {
+				return err
+			}
+		}
+		if template != nil && !deleting {
+			if err := r.syncPoolRevisions(ctx, latestPool, resolved, updateResult.UpdateRevision, pods); err != nil {
+				return err
+			}
+		}
+
+		// 5. Recreate idle pods past maxPodAge, unless no replacement can be created.
+		ageResult := &AgeResult{IdlePods: updateResult.IdlePods}
+		if template != nil && !deleting && !latestPool.Spec.Paused {
+			ageResult = expireAgedPods(ctx, latestPool, schedulePods, updateResult.IdlePods, len(updateResult.ToDeletePods), time.Now())
+		}
+		requeueSooner(&result, ageResult.RequeueAfter)
 
-		// 5. Handle pool scale
+		// 6. Replace idle pods that can no longer serve, unless no replacement can be created.
+		healthResult := &HealthResult{IdlePods: ageResult.IdlePods}
+		if template != nil && !deleting && !latestPool.Spec.Paused {
+			healthResult = r.replaceUnhealthyPods(ctx, latestPool, schedulePods, ageResult.IdlePods, time.Now())
+		}
+		requeueSooner(&result, healthResult.RequeueAfter)
+
+		// 7. Handle pool scale
 		toDeletePods := append(updateResult.ToDeletePods, schedResult.ToDelete...)
+		toDeletePods = append(toDeletePods, ageResult.ToDeletePods...)
+		toDeletePods = append(toDeletePods, healthResult.ToDeletePods...)
 		args := &scaleArgs{
+			template:       template,
 			updateRevision: updateResult.UpdateRevision,
 			pods:           schedulePods,
+			allPods:        pods,
 			totalPodCnt:    int32(len(pods)),
-			allocatedCnt:   int32(len(schedResult.LatestAllocation)),
-			idlePods:       updateResult.IdlePods,
+			allocatedCnt:   int32(len(schedResult.LatestAllocation) + len(schedResult.ReservedPods)),
+			idlePods:       healthResult.IdlePods,
 			toDeletePods:   toDeletePods,
-			supplyCnt:      schedResult.SupplyCnt + updateResult.SupplyUpdateRevision,
+			upgradePods:    updateResult.ToDeletePods,
+			targetBuffer:   autoscale.TargetBuffer,
+			heldScaleIn:    &heldScaleIn{},
+			deferred:       &deferredDeletes{},
+			supplyCnt:      schedResult.SupplyCnt + updateResult.SupplyUpdateRevision + int32(len(ageResult.ToDeletePods)+len(healthResult.ToDeletePods)),
+
+			allocation:      schedResult.LatestAllocation,
+			reservedPods:    schedResult.ReservedPods,
+			flavorSupplyCnt: schedResult.FlavorSupplyCnt,
+			replacedPods:    slices.Concat(updateResult.SuppliedPods, ageResult.ToDeletePods, healthResult.ToDeletePods),
 		}
 
 		if err := r.scalePool(ctx, latestPool, args); err != nil {
 			return err
 		}
+		requeueSooner(&result, args.deferred.retryAfter)
+
+		// 8. Hibernate the idle pods left and wake the hibernated pods reserved since, or allocated ones that
+		// failed to wake on allocation.
+		hibernateAfter, hibernationErr := r.syncHibernation(ctx, latestPool, schedulePods, hibernationCandidates(healthResult.IdlePods, toDeletePods), time.Now())
+		requeueSooner(&result, hibernateAfter)
+
+		// 9. Make room for pods the scheduler cannot place by reclaiming idle pods of lower priority pools.
+		reclaimAfter, reclaimErr := r.reclaimWarmPods(ctx, latestPool, pods, time.Now())
+		requeueSooner(&result, reclaimAfter)
 
-		// 6. Update pool status
-		if err := r.updatePoolStatus(ctx, updateResult.UpdateRevision, latestPool, pods, schedulePods, schedResult.LatestAllocation); err != nil {
+		// 10. Update pool status
+		if err := r.updatePoolStatus(ctx, updateResult.UpdateRevision, latestPool, batchSandboxes, pods, schedulePods, schedResult.LatestAllocation, capacity.ActiveWindow, autoscale.TargetBuffer, args.heldScaleIn, prePull); err != nil {
 			return err
 		}
 
+		if deleting {
+			if err := r.finishPoolDeletion(ctx, latestPool, batchSandboxes, poolHolders(schedResult.LatestAllocation)); err != nil {
+				return err
+			}
+		}
+
 		if evictionErr != nil {
 			return evictionErr
 		}
+		if saturationErr != nil {
+			return saturationErr
+		}
+		if reclaimErr != nil {
+			return reclaimErr
+		}
+		if hibernationErr != nil {
+			return hibernationErr
+		}
 
-		return nil
+		return templateErr
 	})
 
 	return result, err
 }
 
-func (r *PoolReconciler) calculateRevision(pool *sandboxv1alpha1.Pool) (string, error) {
-	template, err := json.Marshal(pool.Spec.Template)
+// calculateRevision hashes the resolved pod template, so the revision only changes with the
+// template content and not with where it is stored.
+func (r *PoolReconciler) calculateRevision(template *corev1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(template)
 	if err != nil {
 		return "", err
 	}
-	revision := sha256.Sum256(template)
+	revision := sha256.Sum256(data)
 	return hex.EncodeToString(revision[:8]), nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
-// Todo pod deletion expectations
 func (r *PoolReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) error {
 	filterBatchSandbox := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
@@ -271,14 +457,35 @@ func (r *PoolReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconci
 			if newObj.Spec.PoolRef == "" {
 				return false
 			}
+			// A BatchSandbox moved to the pool, e.g. an auto pool.
+			if oldObj.Spec.PoolRef != newObj.Spec.PoolRef {
+				return true
+			}
 			oldVal := oldObj.Annotations[AnnoAllocReleaseKey]
 			newVal := newObj.Annotations[AnnoAllocReleaseKey]
 			if oldVal != newVal {
 				return true
 			}
+			// On-demand pods and fallback pools change how many pods the sandbox still waits for.
+			if oldObj.Annotations[AnnoOverflowKey] != newObj.Annotations[AnnoOverflowKey] ||
+				oldObj.Annotations[AnnoFallbackKey] != newObj.Annotations[AnnoFallbackKey] {
+				return true
+			}
 			if oldObj.Spec.Replicas != newObj.Spec.Replicas {
 				return true
 			}
+			// The pods of the sandbox carry its propagated labels and annotations.
+			if propagatedMetadataChanged(oldObj, newObj) {
+				return true
+			}
+			// The quota of the pool accounts the sandbox to the tenant named by one of its labels.
+			if tenantChanged(context.Background(), mgr.GetClient(), oldObj, newObj) {
+				return true
+			}
+			// A sandbox that gave up waiting no longer counts towards the pool's demand.
+			if !isPoolExhausted(oldObj) && isPoolExhausted(newObj) {
+				return true
+			}
 			// Trigger reconcile when sandbox enters terminating state (DeletionTimestamp is set).
 			if oldObj.DeletionTimestamp.IsZero() && !newObj.DeletionTimestamp.IsZero() {
 				return true
@@ -344,9 +551,34 @@ func (r *PoolReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconci
 		},
 	}
 
+	findPoolForReservation := func(_ context.Context, obj client.Object) []reconcile.Request {
+		reservation, ok := obj.(*sandboxv1alpha1.Reservation)
+		if !ok {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: reservation.Namespace, Name: reservation.Spec.PoolRef}}}
+	}
+
+	// Annotations do not bump the generation, so a rollback request is watched for on its own.
+	rollbackRequested := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			target := e.ObjectNew.GetAnnotations()[AnnoPoolRollbackToKey]
+			return target != "" && target != e.ObjectOld.GetAnnotations()[AnnoPoolRollbackToKey]
+		},
+	}
+	// So is a confirmation of a held scale-in.
+	scaleInConfirmedPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			confirmed := e.ObjectNew.GetAnnotations()[AnnoPoolConfirmScaleInKey]
+			return confirmed != "" && confirmed != e.ObjectOld.GetAnnotations()[AnnoPoolConfirmScaleInKey]
+		},
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&sandboxv1alpha1.Pool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&sandboxv1alpha1.Pool{}, builder.WithPredicates(predicate.Or[client.Object](predicate.GenerationChangedPredicate{}, rollbackRequested, scaleInConfirmedPredicate))).
 		Owns(&corev1.Pod{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&appsv1.DaemonSet{}).
 		Watches(
 			&sandboxv1alpha1.BatchSandbox{},
 			handler.EnqueueRequestsFromMapFunc(findPoolForBatchSandbox),
@@ -357,8 +589,26 @@ func (r *PoolReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconci
 			enqueueOldPoolForDetachedBatchSandbox,
 			builder.WithPredicates(filterBatchSandboxDetached),
 		).
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.findPoolsForOrphanPod),
+		).
+		Watches(
+			&sandboxv1alpha1.Reservation{},
+			handler.EnqueueRequestsFromMapFunc(findPoolForReservation),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findPoolsForConfigMap),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Named("pool").
-		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			// With pool ownership every replica reconciles the Pools it owns.
+			NeedLeaderElection: ptr.To(r.Ownership == nil),
+		}).
 		Complete(r)
 }
 
@@ -366,8 +616,12 @@ func (r *PoolReconciler) doAllocate(ctx context.Context, pool *sandboxv1alpha1.P
 	// 1. Compute latest allocated pods per sandbox (merge current + newly allocated).
 	toSyncMap := r.getLatestAllocated(ctx, pool, batchSandboxes, toAllocate)
 
+	// 1.1 Give hibernated pods their requests back before they are handed out.
+	r.wakeAllocatedPods(ctx, pool, pods, toAllocate)
+
 	// 2. Concurrently sync each sandbox's Allocated annotation (AddFinalizer is called inside SyncSandboxAllocation).
-	return r.syncSandboxConcurrently(ctx, batchSandboxes, toSyncMap, r.Allocator.SyncSandboxAllocation, "allocated")
+	syncFn := notifyingSync(r.Lifecycle, lifecycle.SandboxAllocated, toAllocate, r.Allocator.SyncSandboxAllocation)
+	return r.syncSandboxConcurrently(ctx, batchSandboxes, toSyncMap, syncFn, "allocated")
 }
 
 // getLatestAllocated computes the latest allocated pods for each sandbox by merging current allocation with new pods to allocate.
@@ -437,18 +691,26 @@ func (r *PoolReconciler) syncSandboxConcurrently(ctx context.Context, batchSandb
 	return gerrors.Join(errs...)
 }
 
-func (r *PoolReconciler) doRecycle(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, toRecycle map[string][]string) (map[string][]string, []string, error) {
+// doRecycle recycles the pods returned to the pool. pending reports whether some pods are still
+// recycling without being deleted, which nothing but a later reconcile may drive forward.
+func (r *PoolReconciler) doRecycle(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, toRecycle map[string][]string) (succeedMap map[string][]string, toDeletePods []string, pending bool, err error) {
 	if len(toRecycle) == 0 {
-		return nil, nil, nil
+		return nil, nil, false, nil
 	}
 
-	handler, err := recycle.NewHandler(r.Client, r.RestConfig, pool)
+	handler, err := recycle.NewHandler(r.Client, r.RestConfig, r.Recorder, pool)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get recycle handler for pool %s: %w", pool.Name, err)
+		return nil, nil, false, fmt.Errorf("failed to get recycle handler for pool %s: %w", pool.Name, err)
 	}
 
 	results := r.runRecycleTasks(ctx, pool, pods, toRecycle, handler)
-	return collectRecycleResults(ctx, results)
+	for _, res := range results {
+		if res.err == nil && res.status.State == recycle.StateRecycling && !res.status.NeedDelete {
+			pending = true
+		}
+	}
+	succeedMap, toDeletePods, err = collectRecycleResults(ctx, results)
+	return succeedMap, toDeletePods, pending, err
 }
 
 type recycleResult struct {
@@ -522,11 +784,11 @@ func collectRecycleResults(ctx context.Context, results []recycleResult) (map[st
 
 // doRelease runs the recycle operation for pods to be returned to the pool,
 // then persists the released state to each sandbox's annotation.
-func (r *PoolReconciler) doRelease(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, toRelease map[string][]string) ([]string, error) {
+func (r *PoolReconciler) doRelease(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, toRelease map[string][]string) ([]string, bool, error) {
 	log := logf.FromContext(ctx)
 
 	// 1. Recycle pods.
-	succeedMap, toDeletePods, err := r.doRecycle(ctx, pool, batchSandboxes, pods, toRelease)
+	succeedMap, toDeletePods, pending, err := r.doRecycle(ctx, pool, batchSandboxes, pods, toRelease)
 	if err != nil {
 		log.Error(err, "Some errors occurred during recycle")
 	}
@@ -536,7 +798,8 @@ func (r *PoolReconciler) doRelease(ctx context.Context, pool *sandboxv1alpha1.Po
 	toSyncMap, orphanPods := r.getLatestReleased(ctx, batchSandboxes, succeedMap)
 
 	// 3. Concurrently sync each sandbox's Released annotation.
-	syncErr := r.syncSandboxConcurrently(ctx, batchSandboxes, toSyncMap, r.Allocator.SyncSandboxReleased, "released")
+	syncFn := notifyingSync(r.Lifecycle, lifecycle.SandboxReleased, succeedMap, r.Allocator.SyncSandboxReleased)
+	syncErr := r.syncSandboxConcurrently(ctx, batchSandboxes, toSyncMap, syncFn, "released")
 	if syncErr != nil {
 		log.Error(syncErr, "Failed to sync released")
 	}
@@ -546,7 +809,7 @@ func (r *PoolReconciler) doRelease(ctx context.Context, pool *sandboxv1alpha1.Po
 		r.Allocator.ReleasePodsAllocation(ctx, pool.Namespace, pool.Name, orphanPods)
 	}
 
-	return toDeletePods, gerrors.Join(err, syncErr)
+	return toDeletePods, pending, gerrors.Join(err, syncErr)
 }
 
 // getLatestReleased computes the latest released pods for each sandbox by merging current released with recycle-succeeded pods.
@@ -581,28 +844,52 @@ func (r *PoolReconciler) getLatestReleased(ctx context.Context, batchSandboxes [
 	return toSyncMap, orphanPods
 }
 
-func (r *PoolReconciler) scheduleSandbox(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod) (*ScheduleResult, error) {
+func (r *PoolReconciler) scheduleSandbox(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, revision string) (*ScheduleResult, error) {
 	log := logf.FromContext(ctx)
+	// 0. Replace corrupted allocation annotations so that they don't block scheduling of the pool.
+	if err := r.repairCorruptedAllocations(ctx, batchSandboxes, pods); err != nil {
+		return nil, err
+	}
+	// A pool being deleted holds no pods for reservations.
+	var reservations []*sandboxv1alpha1.Reservation
+	if pool.DeletionTimestamp.IsZero() {
+		var err error
+		if reservations, err = r.listReservations(ctx, pool); err != nil {
+			return nil, err
+		}
+	}
 	// 1. Compute scheduling actions.
 	spec := &AllocSpec{
-		Sandboxes: batchSandboxes,
-		Pool:      pool,
-		Pods:      pods,
+		Sandboxes:    batchSandboxes,
+		Pool:         pool,
+		Pods:         pods,
+		Revision:     revision,
+		Reservations: reservations,
 	}
 	allocAction, err := r.Allocator.Schedule(ctx, spec)
 	if err != nil {
+		poolAllocationFailures.WithLabelValues(pool.Namespace, pool.Name, allocationFailureSchedule).Inc()
 		return nil, err
 	}
 	log.Info("Allocate action", "pool", pool.Name, "toAllocate", allocAction.ToAllocate, "toRelease", allocAction.ToRelease)
+	// 1.1 Record preemptions first, so that preempted pods are released and replaced even if the release fails.
+	if err := r.syncPreemptions(ctx, batchSandboxes, allocAction.Preemptions); err != nil {
+		return nil, err
+	}
 
 	// 2. Execute scheduling actions.
 	// 2.1 Execute ToAllocate / update in-memory store.
 	err = r.doAllocate(ctx, pool, batchSandboxes, pods, allocAction.ToAllocate)
 	if err != nil {
+		poolAllocationFailures.WithLabelValues(pool.Namespace, pool.Name, allocationFailureSync).Inc()
+		return nil, err
+	}
+	// 2.1.1 Record what the reservations hold and what they have handed out, now that it is allocated.
+	if err := r.syncReservations(ctx, reservations, allocAction.Reservations); err != nil {
 		return nil, err
 	}
 	// 2.2 Execute ToRelease / release in-memory store.
-	toDeletePods, err := r.doRelease(ctx, pool, batchSandboxes, pods, allocAction.ToRelease)
+	toDeletePods, recyclePending, err := r.doRelease(ctx, pool, batchSandboxes, pods, allocAction.ToRelease)
 	if err != nil {
 		return nil, err
 	}
@@ -612,41 +899,83 @@ func (r *PoolReconciler) scheduleSandbox(ctx context.Context, pool *sandboxv1alp
 	if err != nil {
 		return nil, err
 	}
+	// 4. Propagate sandbox metadata to allocated pods and strip it from released ones.
+	if err := r.syncPodMetadata(ctx, batchSandboxes, pods, latestAllocation); err != nil {
+		return nil, err
+	}
+	// Reserved pods are kept from scaling, updates and replacement like allocated ones.
+	var reservedPods []string
+	for _, name := range slices.Sorted(maps.Keys(allocAction.Reservations)) {
+		reservedPods = append(reservedPods, allocAction.Reservations[name].Pods...)
+	}
 	idlePods := make([]string, 0)
 	for _, pod := range pods {
-		if _, ok := latestAllocation[pod.Name]; !ok {
+		if _, ok := latestAllocation[pod.Name]; !ok && !slices.Contains(reservedPods, pod.Name) {
 			idlePods = append(idlePods, pod.Name)
 		}
 	}
 	result := &ScheduleResult{
-		LatestAllocation: latestAllocation,
-		IdlePods:         idlePods,
-		ToDelete:         toDeletePods,
-		SupplyCnt:        allocAction.PodSupplement,
+		LatestAllocation:   latestAllocation,
+		IdlePods:           idlePods,
+		ToDelete:           toDeletePods,
+		SupplyCnt:          allocAction.PodSupplement,
+		FlavorSupplyCnt:    allocAction.FlavorSupplement,
+		RecyclePending:     recyclePending,
+		PreemptionDeadline: allocAction.PreemptionDeadline,
+		ReservedPods:       reservedPods,
+		ReservationExpiry:  allocAction.ReservationExpiry,
 	}
 	log.Info("Schedule result", "pool", pool.Name, "toDeletePods", toDeletePods, "supplyCnt", allocAction.PodSupplement)
 	return result, nil
 }
 
-func (r *PoolReconciler) updatePool(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, idlePods []string) (*UpdateResult, error) {
-	updateRevision, err := r.calculateRevision(pool)
+func (r *PoolReconciler) updatePool(ctx context.Context, pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec, pods []*corev1.Pod, idlePods []string) (*UpdateResult, error) {
+	updateRevision, err := r.latestRevision(pool, template)
 	if err != nil {
 		return nil, err
 	}
+	// A paused pool reports the new revision but leaves its pods alone.
+	if pool.Spec.Paused {
+		return &UpdateResult{UpdateRevision: updateRevision, IdlePods: idlePods}, nil
+	}
 	strategy := NewPoolUpdateStrategy(pool)
 	result := strategy.Compute(ctx, updateRevision, pods, idlePods)
 	result.UpdateRevision = updateRevision
+	if err := r.markSurgeReplaced(ctx, pods, result.ToSurgePods, updateRevision); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// latestRevision returns the revision of the resolved pod template. Without a template it keeps the current
+// revision, so pods are not rolled to an unknown one.
+func (r *PoolReconciler) latestRevision(pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec) (string, error) {
+	if template == nil {
+		return pool.Status.Revision, nil
+	}
+	return r.calculatePoolRevision(pool, template)
+}
+
 type scaleArgs struct {
+	template       *corev1.PodTemplateSpec // resolved pod template; nil disables scale-up
 	updateRevision string
 	pods           []*corev1.Pod
-	totalPodCnt    int32 // all pods including evicting ones, for PoolMax enforcement
+	allPods        []*corev1.Pod // all pods including evicting ones, for pod naming
+	totalPodCnt    int32         // all pods including evicting ones, for PoolMax enforcement
 	allocatedCnt   int32
 	supplyCnt      int32 // to create
 	idlePods       []string
 	toDeletePods   []string
+	upgradePods    []string         // the toDeletePods that are replaced by the latest revision
+	targetBuffer   *int32           // buffer size chosen by autoscaling, nil without it
+	heldScaleIn    *heldScaleIn     // collects the scale-ins held for confirmation, nil to not report them
+	deferred       *deferredDeletes // collects the deletions deferred by the rate limit, nil to not report them
+
+	// For pools with flavors, which scale each flavor on its own.
+	allocation      map[string]string // pod -> sandbox
+	reservedPods    []string          // idle pods held by reservations, counted as allocated
+	flavorSupplyCnt map[string]int32
+	replacedPods    []string // pods that supplyCnt replaces
 }
 
 type ScheduleResult struct {
@@ -659,24 +988,50 @@ type ScheduleResult struct {
 	ToDelete []string
 	// SupplyCnt is the number of additional pods the allocator needs but are not yet available.
 	SupplyCnt int32
+	// FlavorSupplyCnt breaks SupplyCnt down by flavor, "" for the pool template.
+	FlavorSupplyCnt map[string]int32
+	// RecyclePending is set when released pods are still recycling.
+	RecyclePending bool
+	// PreemptionDeadline is when the next pod being preempted is released, zero if none is.
+	PreemptionDeadline time.Time
+	// ReservedPods are the idle pods held by reservations, which are not in IdlePods.
+	ReservedPods []string
+	// ReservationExpiry is when the next reservation holding pods expires, zero if none does.
+	ReservationExpiry time.Time
 }
 
 type UpdateResult struct {
 	UpdateRevision string
 	IdlePods       []string
 	ToDeletePods   []string
+	// ToSurgePods are idle pods to mark for replacement by surge pods; they stay idle until then.
+	ToSurgePods []string
 	// Supply Pods with update revision
 	SupplyUpdateRevision int32
+	// SuppliedPods are the pods that SupplyUpdateRevision replaces.
+	SuppliedPods []string
 }
 
 func (r *PoolReconciler) scalePool(ctx context.Context, pool *sandboxv1alpha1.Pool, args *scaleArgs) error {
 	log := logf.FromContext(ctx)
-	errs := make([]error, 0)
-	pods := args.pods
 	if satisfied, unsatisfiedDuration, dirtyPods := PoolScaleExpectations.SatisfiedExpectations(controllerutils.GetControllerKey(pool)); !satisfied {
 		log.Info("Pool scale is not ready, requeue", "unsatisfiedDuration", unsatisfiedDuration, "dirtyPods", dirtyPods)
 		return fmt.Errorf("pool scale is not ready, %v", pool.Name)
 	}
+	if len(pool.Spec.Flavors) > 0 {
+		return r.scaleFlavors(ctx, pool, args)
+	}
+	_, err := r.scalePoolPods(ctx, pool, args)
+	return err
+}
+
+// scalePoolPods scales the pods of args to the capacity of the pool and returns the number of pods
+// it created.
+func (r *PoolReconciler) scalePoolPods(ctx context.Context, pool *sandboxv1alpha1.Pool, args *scaleArgs) (int32, error) {
+	log := logf.FromContext(ctx)
+	errs := make([]error, 0)
+	pods := args.pods
+	created := int32(0)
 	schedulableCnt := int32(len(args.pods))
 	totalPodCnt := args.totalPodCnt
 	allocatedCnt := args.allocatedCnt
@@ -686,12 +1041,29 @@ func (r *PoolReconciler) scalePool(ctx context.Context, pool *sandboxv1alpha1.Po
 
 	// Calculate desired buffer cnt.
 	desiredBufferCnt := bufferCnt
-	if bufferCnt < pool.Spec.CapacitySpec.BufferMin || bufferCnt > pool.Spec.CapacitySpec.BufferMax {
+	if args.targetBuffer != nil {
+		desiredBufferCnt = *args.targetBuffer
+	} else if bufferCnt < pool.Spec.CapacitySpec.BufferMin || bufferCnt > pool.Spec.CapacitySpec.BufferMax {
 		desiredBufferCnt = (pool.Spec.CapacitySpec.BufferMin + pool.Spec.CapacitySpec.BufferMax) / 2
 	}
 
-	// Calculate desired schedulable cnt.
+	// Calculate desired schedulable cnt. Replicas set through the scale subresource replace the buffer.
 	desiredSchedulableCnt := max(allocatedCnt+supplyCnt+desiredBufferCnt, pool.Spec.CapacitySpec.PoolMin)
+	if replicas := pool.Spec.CapacitySpec.Replicas; replicas != nil {
+		desiredSchedulableCnt = max(allocatedCnt+supplyCnt, *replicas, pool.Spec.CapacitySpec.PoolMin)
+	}
+	// Zone minimums add pinned pods on top of the buffer of the whole pool.
+	var zones map[string]string
+	var zoneDeficit []string
+	if len(pool.Spec.CapacitySpec.Zones) > 0 {
+		var err error
+		if zones, err = podZones(ctx, r.Client, pods); err != nil {
+			log.Error(err, "Failed to get pod zones, skipping zone minimums", "pool", pool.Name)
+			errs = append(errs, err)
+		} else if zoneDeficit = zoneDeficits(pool, pods, args.idlePods, zones); len(zoneDeficit) > 0 {
+			desiredSchedulableCnt = max(desiredSchedulableCnt, schedulableCnt+int32(len(zoneDeficit)))
+		}
+	}
 	// Enforce PoolMax: limit new pods based on total running pods (including evicting).
 	maxNewPods := max(pool.Spec.CapacitySpec.PoolMax-totalPodCnt, 0)
 
@@ -700,55 +1072,95 @@ func (r *PoolReconciler) scalePool(ctx context.Context, pool *sandboxv1alpha1.Po
 		"allocatedCnt", allocatedCnt, "bufferCnt", bufferCnt,
 		"desiredBufferCnt", desiredBufferCnt, "supplyCnt", supplyCnt,
 		"desiredSchedulableCnt", desiredSchedulableCnt, "maxNewPods", maxNewPods,
-		"toDeletePods", len(toDeletePods), "idlePods", len(args.idlePods))
+		"toDeletePods", len(toDeletePods), "idlePods", len(args.idlePods), "zoneDeficit", zoneDeficit)
 
 	// Scale-up: create new pods if needed and allowed by PoolMax
-	if desiredSchedulableCnt > schedulableCnt && maxNewPods > 0 {
+	scaleUp := desiredSchedulableCnt > schedulableCnt && maxNewPods > 0
+	if scaleUp && args.template == nil {
+		log.Info("Skipping pool scale-up without a resolved pod template", "pool", pool.Name)
+		scaleUp = false
+	}
+	if scaleUp && !pool.DeletionTimestamp.IsZero() {
+		log.Info("Skipping pool scale-up while the pool is being deleted", "pool", pool.Name)
+		scaleUp = false
+	}
+	if scaleUp && pool.Spec.Paused {
+		log.Info("Skipping pool scale-up while the pool is paused", "pool", pool.Name)
+		scaleUp = false
+	}
+	if scaleUp {
 		createCnt := min(desiredSchedulableCnt-schedulableCnt, maxNewPods)
 		scaleMaxUnavailable := r.getScaleMaxUnavailable(pool, desiredSchedulableCnt)
-		notReadyCnt := r.countNotReadyPods(pods)
+		notReadyCnt := r.countNotReadyPods(pool, pods)
 		limitedCreateCnt := scaleMaxUnavailable - notReadyCnt
 		createCnt = max(0, min(createCnt, limitedCreateCnt))
 		if createCnt > 0 {
 			log.Info("Scaling up pool with constraint", "pool", pool.Name,
 				"createCnt", createCnt, "scaleMaxUnavailable", scaleMaxUnavailable,
 				"notReadyCnt", notReadyCnt, "desiredSchedulableCnt", desiredSchedulableCnt, "limitedCreateCnt", limitedCreateCnt)
-			for range createCnt {
-				if err := r.createPoolPod(ctx, pool, args.updateRevision); err != nil {
+			namer := newPodNamer(pool, args.allPods)
+			succeeded, createErrs := slowStartBatch(int(createCnt), slowStartInitialBatchSize, func(i int) error {
+				template := args.template
+				if i < len(zoneDeficit) {
+					template = pinToZone(template, zoneDeficit[i])
+				}
+				err := r.createPoolPod(ctx, pool, template, args.updateRevision, namer)
+				if err != nil {
 					log.Error(err, "Failed to create pool pod")
-					errs = append(errs, err)
 				}
+				return err
+			})
+			created = int32(succeeded)
+			if skipped := int(createCnt) - succeeded - len(createErrs); skipped > 0 {
+				log.Info("Skipping pool pod creation after failures, retrying on the next reconcile", "pool", pool.Name, "skipped", skipped)
 			}
+			errs = append(errs, createErrs...)
 		}
 	}
 
 	// Scale-down: delete redundant or excess pods
+	// A paused pool still deletes the pods its recycle policy gave up on.
 	scaleIn := int32(0)
-	if desiredSchedulableCnt < schedulableCnt {
+	if desiredSchedulableCnt < schedulableCnt && !pool.Spec.Paused {
 		scaleIn = schedulableCnt - desiredSchedulableCnt
+		if allowed := guardScaleIn(pool, schedulableCnt, scaleIn, args.heldScaleIn); allowed < scaleIn {
+			log.Info("Holding pool scale-in for confirmation", "pool", pool.Name, "scaleIn", scaleIn, "schedulableCnt", schedulableCnt)
+			scaleIn = allowed
+		}
 	}
 	if scaleIn > 0 || len(toDeletePods) > 0 {
-		podsToDelete := r.pickPodsToDelete(pods, args.idlePods, args.toDeletePods, scaleIn)
+		var domains map[string][]string
+		if scaleIn > 0 && len(pool.Spec.TopologySpreadConstraints) > 0 {
+			var err error
+			if domains, err = podTopologyDomains(ctx, r.Client, pool, pods); err != nil {
+				log.Error(err, "Failed to get pod topology domains, scaling in by age", "pool", pool.Name)
+			}
+		}
+		idlePods := args.idlePods
+		if zones != nil {
+			idlePods = protectZoneBuffers(pool, pods, idlePods, zones)
+		}
+		podsToDelete := r.pickPodsToDelete(pods, idlePods, args.toDeletePods, scaleIn, domains)
 		log.Info("Scaling down pool", "pool", pool.Name, "scaleIn", scaleIn, "toDeletePods", len(toDeletePods), "podsToDelete", len(podsToDelete))
-		for _, pod := range podsToDelete {
-			log.Info("Deleting pool pod", "pool", pool.Name, "pod", pod.Name)
-			if err := r.Delete(ctx, pod); err != nil {
-				log.Error(err, "Failed to delete pool pod", "pod", pod.Name)
-				errs = append(errs, err)
+		deleted, deleteErrs := r.deletePoolPods(ctx, pool, podsToDelete, args.deferred)
+		errs = append(errs, deleteErrs...)
+		for _, pod := range deleted {
+			if slices.Contains(args.upgradePods, pod.Name) {
+				poolUpgradedPods.WithLabelValues(pool.Namespace, pool.Name).Inc()
 			}
 		}
 	}
-	return gerrors.Join(errs...)
+	return created, gerrors.Join(errs...)
 }
 
-func (r *PoolReconciler) updatePoolStatus(ctx context.Context, updateRevision string, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, schedulePods []*corev1.Pod, podAllocation map[string]string) error {
+func (r *PoolReconciler) updatePoolStatus(ctx context.Context, updateRevision string, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, schedulePods []*corev1.Pod, podAllocation map[string]string, activeWindow string, targetBuffer *int32, held *heldScaleIn, prePull *sandboxv1alpha1.ImagePrePullStatus) error {
 	oldStatus := pool.Status.DeepCopy()
 	availableCnt := int32(0)
 	for _, pod := range schedulePods {
 		if _, ok := podAllocation[pod.Name]; ok {
 			continue
 		}
-		if !utils.IsPodReady(pod) {
+		if !isPodAvailable(pool, pod) {
 			continue
 		}
 		availableCnt++
@@ -765,6 +1177,33 @@ func (r *PoolReconciler) updatePoolStatus(ctx context.Context, updateRevision st
 	pool.Status.Available = availableCnt
 	pool.Status.Revision = updateRevision
 	pool.Status.Updated = updatedCnt
+	pool.Status.Hibernated = countHibernated(pods)
+	pool.Status.QuotaUsage = calculateQuotaUsage(pool, batchSandboxes, podAllocation)
+	pool.Status.ActiveWindow = activeWindow
+	pool.Status.PrePull = prePull
+	pool.Status.TargetBuffer = targetBuffer
+	pool.Status.Selector = labels.SelectorFromSet(labels.Set{LabelPoolName: pool.Name}).String()
+	if zones, err := podZones(ctx, r.Client, pods); err == nil {
+		pool.Status.Zones = calculateZoneStatus(pool, pods, schedulePods, podAllocation, zones)
+	} else {
+		logf.FromContext(ctx).Error(err, "Failed to get pod zones, keeping the zone status", "pool", pool.Name)
+	}
+	pool.Status.Flavors = calculateFlavorStatus(pool, pods, schedulePods, podAllocation)
+	pool.Status.DeletionBlockedBy = nil
+	if !pool.DeletionTimestamp.IsZero() {
+		pool.Status.DeletionBlockedBy = poolHolders(podAllocation)
+	}
+	bufferMin := pool.Spec.CapacitySpec.BufferMin
+	if targetBuffer != nil {
+		bufferMin = *targetBuffer
+	}
+	observePoolStatus(pool)
+	setPoolConditions(pool, bufferMin)
+	setSchedulingFailedCondition(pool, pods, time.Now())
+	if setNeedsConfirmationCondition(pool, held) {
+		r.Recorder.Event(pool, corev1.EventTypeWarning, "ScaleInHeld",
+			meta.FindStatusCondition(pool.Status.Conditions, sandboxv1alpha1.PoolConditionNeedsConfirmation).Message)
+	}
 	if equality.Semantic.DeepEqual(*oldStatus, pool.Status) {
 		return nil
 	}
@@ -777,7 +1216,10 @@ func (r *PoolReconciler) updatePoolStatus(ctx context.Context, updateRevision st
 	return nil
 }
 
-func (r *PoolReconciler) pickPodsToDelete(pods []*corev1.Pod, idlePodNames []string, toDeletePodNames []string, scaleIn int32) []*corev1.Pod {
+// pickPodsToDelete returns the pods to delete and scaleIn idle pods, those with the lowest deletion cost
+// (see AnnoPoolDeletionCostKey) first and the oldest of equal cost. With the topology domains of the pods,
+// the idle pods are picked to keep the pool spread instead, in that order among equally spread pods.
+func (r *PoolReconciler) pickPodsToDelete(pods []*corev1.Pod, idlePodNames []string, toDeletePodNames []string, scaleIn int32, domains map[string][]string) []*corev1.Pod {
 	podMap := make(map[string]*corev1.Pod)
 	for _, pod := range pods {
 		podMap[pod.Name] = pod
@@ -801,8 +1243,14 @@ func (r *PoolReconciler) pickPodsToDelete(pods []*corev1.Pod, idlePodNames []str
 		idlePods = append(idlePods, pod)
 	}
 	sort.Slice(idlePods, func(i, j int) bool {
+		if ci, cj := utils.PodDeletionCost(idlePods[i]), utils.PodDeletionCost(idlePods[j]); ci != cj {
+			return ci < cj
+		}
 		return idlePods[i].CreationTimestamp.Before(&idlePods[j].CreationTimestamp)
 	})
+	if domains != nil {
+		return append(podsToDelete, pickSpreadPods(pods, idlePods, sets.New(toDeletePodNames...), domains, scaleIn)...)
+	}
 	for _, pod := range idlePods {
 		if scaleIn <= 0 {
 			break
@@ -835,41 +1283,130 @@ func (r *PoolReconciler) getScaleMaxUnavailable(pool *sandboxv1alpha1.Pool, desi
 
 // countNotReadyPods returns the count of pods that are not ready.
 // A pod is considered not ready if it doesn't have a Ready condition
-// with status True.
-func (r *PoolReconciler) countNotReadyPods(pods []*corev1.Pod) int32 {
+// with status True or misses a readiness condition of the pool.
+func (r *PoolReconciler) countNotReadyPods(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod) int32 {
 	var count int32
 	for _, pod := range pods {
-		if !utils.IsPodReady(pod) {
+		if !isPodAvailable(pool, pod) {
 			count++
 		}
 	}
 	return count
 }
 
-func (r *PoolReconciler) createPoolPod(ctx context.Context, pool *sandboxv1alpha1.Pool, updateRevision string) error {
+// maxPodNameAttempts bounds how often a pod is created again under another name after its name
+// turned out to be taken.
+const maxPodNameAttempts = 16
+
+// slowStartInitialBatchSize is the size of the first batch of pods a pool creates in a reconcile.
+const slowStartInitialBatchSize = 1
+
+// slowStartBatch calls fn for the indexes 0 to count-1 in batches run in parallel, like the ReplicaSet
+// controller. The batches double in size from initialBatchSize as long as all calls succeed; after a batch
+// with a failure no further batch is started, so a pool that can't create pods, e.g. over a quota, sends
+// a few failing calls rather than hundreds. It returns the number of successful calls and the errors of
+// the failed ones.
+func slowStartBatch(count, initialBatchSize int, fn func(index int) error) (int, []error) {
+	successes := 0
+	next := 0
+	for batchSize := min(count, initialBatchSize); batchSize > 0; batchSize = min(2*batchSize, count-next) {
+		errCh := make(chan error, batchSize)
+		var wg sync.WaitGroup
+		for index := next; index < next+batchSize; index++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := fn(index); err != nil {
+					errCh <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errCh)
+		next += batchSize
+		var errs []error
+		for err := range errCh {
+			errs = append(errs, err)
+		}
+		successes += batchSize - len(errs)
+		if len(errs) > 0 {
+			return successes, errs
+		}
+	}
+	return successes, nil
+}
+
+func (r *PoolReconciler) createPoolPod(ctx context.Context, pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec, updateRevision string, namer podNamer) error {
 	log := logf.FromContext(ctx)
-	pod, err := utils.GetPodFromTemplate(pool.Spec.Template, pool, metav1.NewControllerRef(pool, sandboxv1alpha1.SchemeBuilder.GroupVersion.WithKind("Pool")))
+	pod, err := utils.GetPodFromTemplate(template, pool, metav1.NewControllerRef(pool, sandboxv1alpha1.SchemeBuilder.GroupVersion.WithKind("Pool")))
 	if err != nil {
 		return err
 	}
 	pod.Namespace = pool.Namespace
-	pod.Name = ""
-	pod.GenerateName = pool.Name + "-"
 	pod.Labels[LabelPoolName] = pool.Name
 	pod.Labels[LabelPoolRevision] = updateRevision
 	if err := ctrl.SetControllerReference(pool, pod, r.Scheme); err != nil {
 		return err
 	}
-	if err := r.Create(ctx, pod); err != nil {
+	for attempt := 1; ; attempt++ {
+		namer.Name(pod)
+		nameForVolumeClaims(pool, pod)
+		addVolumeClaims(pool, pod)
+		err = r.Create(ctx, pod)
+		if err == nil || !errors.IsAlreadyExists(err) || !namer.Retry() || attempt == maxPodNameAttempts {
+			break
+		}
+		log.Info("Pool pod name is taken, trying the next one", "pool", pool.Name, "pod", pod.Name)
+	}
+	if err != nil {
 		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "FailedCreate", "Failed to create pool pod: %v", err)
 		return err
 	}
+	if err := createVolumeClaims(ctx, r.Client, pool, pod); err != nil {
+		// The pod would stay pending without its claims; it is recreated on the next scale-up once
+		// its deletion is observed.
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "FailedCreate", "Failed to create pool pod volume claims: %v", err)
+		if deleteErr := deletePoolPod(ctx, r.Client, pool, pod); deleteErr != nil && !errors.IsNotFound(deleteErr) {
+			log.Error(deleteErr, "Failed to delete pool pod without volume claims", "pod", pod.Name)
+		}
+		return err
+	}
 	PoolScaleExpectations.ExpectScale(controllerutils.GetControllerKey(pool), expectations.Create, pod.Name)
 	log.Info("Created pool pod", "pool", pool.Name, "pod", pod.Name, "revision", updateRevision)
 	r.Recorder.Eventf(pool, corev1.EventTypeNormal, "SuccessfulCreate", "Created pool pod: %v", pod.Name)
 	return nil
 }
 
+// deletePoolPod deletes a pod of the pool and expects the deletion, so that the pool does not scale on
+// a pod list that still holds the pod until the deletion is observed.
+func deletePoolPod(ctx context.Context, c client.Client, pool *sandboxv1alpha1.Pool, pod *corev1.Pod) error {
+	controllerKey := controllerutils.GetControllerKey(pool)
+	PoolScaleExpectations.ExpectScale(controllerKey, expectations.Delete, pod.Name)
+	if err := c.Delete(ctx, pod); err != nil {
+		PoolScaleExpectations.ObserveScale(controllerKey, expectations.Delete, pod.Name)
+		return err
+	}
+	return nil
+}
+
+// observePoolPodDeletions observes the expected deletions of pods that are terminating or gone, given
+// the pods of the pool that are not terminating.
+func observePoolPodDeletions(controllerKey string, pods []*corev1.Pod) {
+	expected := PoolScaleExpectations.GetExpectations(controllerKey)[expectations.Delete]
+	if expected.Len() == 0 {
+		return
+	}
+	live := sets.New[string]()
+	for _, pod := range pods {
+		live.Insert(pod.Name)
+	}
+	for _, name := range expected.List() {
+		if !live.Has(name) {
+			PoolScaleExpectations.ObserveScale(controllerKey, expectations.Delete, name)
+		}
+	}
+}
+
 // handleEviction fetches the current allocation, evicts idle pods marked for eviction,
 // and returns the schedulable pods (excluding evicting idle pods) along with any eviction error.
 // Eviction errors are non-fatal: they are returned to trigger a requeue but do not block the current reconcile.
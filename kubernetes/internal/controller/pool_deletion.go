@@ -0,0 +1,120 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+// maxReportedPoolHolders caps the sandboxes named in events about a blocked pool deletion.
+const maxReportedPoolHolders = 10
+
+func poolDeletionPolicy(pool *sandboxv1alpha1.Pool) sandboxv1alpha1.PoolDeletionPolicy {
+	if pool.Spec.DeletionPolicy == "" {
+		return sandboxv1alpha1.PoolDeletionPolicyBlock
+	}
+	return pool.Spec.DeletionPolicy
+}
+
+// syncDeletionProtection adds the protection finalizer to a live pool, or removes it when the pool
+// orphans its allocations.
+func (r *PoolReconciler) syncDeletionProtection(pool *sandboxv1alpha1.Pool) error {
+	protected := controllerutil.ContainsFinalizer(pool, FinalizerPoolProtection)
+	orphan := poolDeletionPolicy(pool) == sandboxv1alpha1.PoolDeletionPolicyOrphan
+	switch {
+	case !orphan && !protected:
+		return utils.UpdateFinalizer(r.Client, pool, utils.AddFinalizerOpType, FinalizerPoolProtection)
+	case orphan && protected:
+		return utils.UpdateFinalizer(r.Client, pool, utils.RemoveFinalizerOpType, FinalizerPoolProtection)
+	}
+	return nil
+}
+
+// holdsPoolPods reports whether the sandbox has been allocated pods. Once a pool is being deleted
+// only these sandboxes are scheduled, so pending sandboxes get no new pods but releases still run.
+func holdsPoolPods(ctx context.Context, c client.Reader, sandbox *sandboxv1alpha1.BatchSandbox) bool {
+	alloc, err := sandboxAllocation(ctx, c, sandbox)
+	return err != nil || len(alloc.Pods) > 0
+}
+
+// poolHolders returns the sorted names of the sandboxes in a pod-to-sandbox allocation.
+func poolHolders(podAllocation map[string]string) []string {
+	seen := make(map[string]bool, len(podAllocation))
+	holders := make([]string, 0, len(podAllocation))
+	for _, sandbox := range podAllocation {
+		if !seen[sandbox] {
+			seen[sandbox] = true
+			holders = append(holders, sandbox)
+		}
+	}
+	sort.Strings(holders)
+	return holders
+}
+
+// finishPoolDeletion runs after a scheduling round of a pool being deleted. It deletes the holders
+// under the Cascade policy and removes the protection finalizer once no sandbox holds pods, or right
+// away if the policy was switched to Orphan after the deletion started.
+func (r *PoolReconciler) finishPoolDeletion(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox, holders []string) error {
+	log := logf.FromContext(ctx)
+	if len(holders) == 0 || poolDeletionPolicy(pool) == sandboxv1alpha1.PoolDeletionPolicyOrphan {
+		log.Info("Releasing pool for deletion", "pool", pool.Name, "holders", len(holders))
+		return utils.UpdateFinalizer(r.Client, pool, utils.RemoveFinalizerOpType, FinalizerPoolProtection)
+	}
+
+	reported := holders
+	if len(reported) > maxReportedPoolHolders {
+		reported = reported[:maxReportedPoolHolders]
+	}
+	msg := fmt.Sprintf("%d BatchSandboxes still hold pods: %s", len(holders), strings.Join(reported, ", "))
+	if len(holders) > len(reported) {
+		msg += ", ..."
+	}
+
+	if poolDeletionPolicy(pool) != sandboxv1alpha1.PoolDeletionPolicyCascade {
+		r.Recorder.Event(pool, corev1.EventTypeWarning, "DeletionBlocked", msg)
+		return nil
+	}
+
+	isHolder := make(map[string]bool, len(holders))
+	for _, name := range holders {
+		isHolder[name] = true
+	}
+	var errs []error
+	for _, sandbox := range batchSandboxes {
+		if !isHolder[sandbox.Name] || !sandbox.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, sandbox, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete BatchSandbox %s: %w", sandbox.Name, err))
+			continue
+		}
+		log.Info("Deleted BatchSandbox holding pods of deleted pool", "pool", pool.Name, "sandbox", sandbox.Name)
+	}
+	r.Recorder.Event(pool, corev1.EventTypeNormal, "DeletionCascading", msg)
+	return gerrors.Join(errs...)
+}
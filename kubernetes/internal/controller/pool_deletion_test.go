@@ -0,0 +1,132 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestSyncDeletionProtection(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool).Build()
+	r := &PoolReconciler{Client: c}
+	get := func() *sandboxv1alpha1.Pool {
+		latest := &sandboxv1alpha1.Pool{}
+		require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(pool), latest))
+		return latest
+	}
+
+	require.NoError(t, r.syncDeletionProtection(get()))
+	assert.True(t, controllerutil.ContainsFinalizer(get(), FinalizerPoolProtection))
+
+	orphan := get()
+	orphan.Spec.DeletionPolicy = sandboxv1alpha1.PoolDeletionPolicyOrphan
+	require.NoError(t, r.syncDeletionProtection(orphan))
+	assert.False(t, controllerutil.ContainsFinalizer(get(), FinalizerPoolProtection))
+}
+
+func TestPoolHolders(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, poolHolders(map[string]string{"p1": "b", "p2": "a", "p3": "b"}))
+	assert.Empty(t, poolHolders(nil))
+
+	ctx := context.Background()
+	migrated := &sandboxv1alpha1.Allocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrated", Namespace: "default"},
+		Spec:       sandboxv1alpha1.AllocationSpec{Pods: []string{"p2"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(migrated).Build()
+	pending := &sandboxv1alpha1.BatchSandbox{}
+	assert.False(t, holdsPoolPods(ctx, c, pending))
+	setSandboxAllocation(pending, SandboxAllocation{Pods: []string{"p1"}})
+	assert.True(t, holdsPoolPods(ctx, c, pending))
+
+	sbx := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "migrated", Namespace: "default"}}
+	markAllocationResource(sbx)
+	assert.True(t, holdsPoolPods(ctx, c, sbx))
+}
+
+func TestFinishPoolDeletion(t *testing.T) {
+	ctx := context.Background()
+	newPool := func(policy sandboxv1alpha1.PoolDeletionPolicy) *sandboxv1alpha1.Pool {
+		return &sandboxv1alpha1.Pool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pool", Namespace: "default",
+				Finalizers:        []string{FinalizerPoolProtection},
+				DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time},
+			},
+			Spec: sandboxv1alpha1.PoolSpec{DeletionPolicy: policy},
+		}
+	}
+	newSandbox := func(name string) *sandboxv1alpha1.BatchSandbox {
+		return &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", Finalizers: []string{FinalizerPoolAllocation},
+		}}
+	}
+
+	t.Run("block keeps the pool while sandboxes hold pods", func(t *testing.T) {
+		pool, holder := newPool(""), newSandbox("holder")
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool, holder).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &PoolReconciler{Client: c, Recorder: recorder}
+
+		require.NoError(t, r.finishPoolDeletion(ctx, pool, []*sandboxv1alpha1.BatchSandbox{holder}, []string{"holder"}))
+		events := drainEvents(recorder)
+		require.Len(t, events, 1)
+		assert.Contains(t, events[0], "DeletionBlocked")
+		assert.Contains(t, events[0], "holder")
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(holder), holder))
+		assert.True(t, holder.DeletionTimestamp.IsZero())
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pool), pool))
+
+		// Once the last allocation is released the pool goes away.
+		require.NoError(t, r.finishPoolDeletion(ctx, pool, []*sandboxv1alpha1.BatchSandbox{holder}, nil))
+		assert.True(t, errors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(pool), pool)))
+	})
+
+	t.Run("cascade deletes the holders", func(t *testing.T) {
+		pool, holder, pending := newPool(sandboxv1alpha1.PoolDeletionPolicyCascade), newSandbox("holder"), newSandbox("pending")
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool, holder, pending).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &PoolReconciler{Client: c, Recorder: recorder}
+
+		require.NoError(t, r.finishPoolDeletion(ctx, pool, []*sandboxv1alpha1.BatchSandbox{holder, pending}, []string{"holder"}))
+		assert.Contains(t, drainEvents(recorder)[0], "DeletionCascading")
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(holder), holder))
+		assert.False(t, holder.DeletionTimestamp.IsZero(), "the holder is deleted and waits for its pods to be released")
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pending), pending))
+		assert.True(t, pending.DeletionTimestamp.IsZero())
+	})
+
+	t.Run("orphan releases the pool right away", func(t *testing.T) {
+		pool := newPool(sandboxv1alpha1.PoolDeletionPolicyOrphan)
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool).Build()
+		r := &PoolReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+
+		require.NoError(t, r.finishPoolDeletion(ctx, pool, nil, []string{"holder"}))
+		assert.True(t, errors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(pool), pool)))
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+
+// disruptionBudgetSpec returns the spec of the PodDisruptionBudget of the pool. It selects the pods of the
+// pool that carry LabelAllocatedTo, which syncPodMetadata sets while a pod is allocated.
+func disruptionBudgetSpec(pool *sandboxv1alpha1.Pool) policyv1.PodDisruptionBudgetSpec {
+	maxUnavailable := intstr.FromInt32(0)
+	if budget := pool.Spec.DisruptionBudget; budget != nil && budget.MaxUnavailable != nil {
+		maxUnavailable = *budget.MaxUnavailable
+	}
+	return policyv1.PodDisruptionBudgetSpec{
+		MaxUnavailable: &maxUnavailable,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{LabelPoolName: pool.Name},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: LabelAllocatedTo, Operator: metav1.LabelSelectorOpExists},
+			},
+		},
+	}
+}
+
+// syncDisruptionBudget creates or updates the PodDisruptionBudget of the allocated pods of the pool, or
+// deletes it once spec.disruptionBudget is unset. A budget of the same name that the pool does not control
+// is left alone.
+func (r *PoolReconciler) syncDisruptionBudget(ctx context.Context, pool *sandboxv1alpha1.Pool) error {
+	log := logf.FromContext(ctx)
+	pdb := &policyv1.PodDisruptionBudget{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(pool), pdb)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(pdb, pool) {
+		if pool.Spec.DisruptionBudget != nil {
+			r.Recorder.Eventf(pool, corev1.EventTypeWarning, "DisruptionBudgetConflict",
+				"PodDisruptionBudget %s exists and is not controlled by the pool", pool.Name)
+		}
+		return nil
+	}
+
+	if pool.Spec.DisruptionBudget == nil {
+		if !exists {
+			return nil
+		}
+		if err := r.Delete(ctx, pdb); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Deleted pool disruption budget", "pool", pool.Name)
+		return nil
+	}
+
+	spec := disruptionBudgetSpec(pool)
+	if !exists {
+		pdb = &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pool.Name,
+				Namespace: pool.Namespace,
+				Labels:    map[string]string{LabelPoolName: pool.Name},
+			},
+			Spec: spec,
+		}
+		if err := ctrl.SetControllerReference(pool, pdb, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, pdb); err != nil {
+			return err
+		}
+		log.Info("Created pool disruption budget", "pool", pool.Name, "maxUnavailable", spec.MaxUnavailable.String())
+		return nil
+	}
+	if equality.Semantic.DeepEqual(pdb.Spec.MaxUnavailable, spec.MaxUnavailable) &&
+		equality.Semantic.DeepEqual(pdb.Spec.Selector, spec.Selector) && pdb.Spec.MinAvailable == nil {
+		return nil
+	}
+	pdb.Spec.MinAvailable = nil
+	pdb.Spec.MaxUnavailable = spec.MaxUnavailable
+	pdb.Spec.Selector = spec.Selector
+	if err := r.Update(ctx, pdb); err != nil {
+		return err
+	}
+	log.Info("Updated pool disruption budget", "pool", pool.Name, "maxUnavailable", spec.MaxUnavailable.String())
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestDisruptionBudgetSelectsAllocatedPods(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	spec := disruptionBudgetSpec(pool)
+	assert.Equal(t, intstr.FromInt32(0), *spec.MaxUnavailable)
+
+	selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+	require.NoError(t, err)
+	assert.True(t, selector.Matches(labels.Set{LabelPoolName: "pool", LabelAllocatedTo: "sbx"}))
+	assert.False(t, selector.Matches(labels.Set{LabelPoolName: "pool"}), "idle pods stay evictable")
+	assert.False(t, selector.Matches(labels.Set{LabelPoolName: "other", LabelAllocatedTo: "sbx"}))
+}
+
+func TestSyncDisruptionBudget(t *testing.T) {
+	ctx := context.Background()
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(policyv1.AddToScheme(scheme))
+	utilruntime.Must(sandboxv1alpha1.AddToScheme(scheme))
+
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"},
+		Spec:       sandboxv1alpha1.PoolSpec{DisruptionBudget: &sandboxv1alpha1.PoolDisruptionBudget{}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+	key := client.ObjectKeyFromObject(pool)
+
+	require.NoError(t, r.syncDisruptionBudget(ctx, pool))
+	pdb := &policyv1.PodDisruptionBudget{}
+	require.NoError(t, c.Get(ctx, key, pdb))
+	assert.True(t, metav1.IsControlledBy(pdb, pool))
+	assert.Equal(t, intstr.FromInt32(0), *pdb.Spec.MaxUnavailable)
+
+	maxUnavailable := intstr.FromString("10%")
+	pool.Spec.DisruptionBudget.MaxUnavailable = &maxUnavailable
+	require.NoError(t, r.syncDisruptionBudget(ctx, pool))
+	require.NoError(t, c.Get(ctx, key, pdb))
+	assert.Equal(t, maxUnavailable, *pdb.Spec.MaxUnavailable)
+
+	pool.Spec.DisruptionBudget = nil
+	require.NoError(t, r.syncDisruptionBudget(ctx, pool))
+	assert.True(t, errors.IsNotFound(c.Get(ctx, key, pdb)))
+	require.NoError(t, r.syncDisruptionBudget(ctx, pool), "nothing to delete")
+
+	// A budget of the same name created by someone else is neither updated nor deleted.
+	foreign := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}}
+	require.NoError(t, c.Create(ctx, foreign))
+	require.NoError(t, r.syncDisruptionBudget(ctx, pool))
+	pool.Spec.DisruptionBudget = &sandboxv1alpha1.PoolDisruptionBudget{}
+	require.NoError(t, r.syncDisruptionBudget(ctx, pool))
+	require.NoError(t, c.Get(ctx, key, pdb))
+	assert.Nil(t, pdb.Spec.MaxUnavailable)
+	assert.Contains(t, <-recorder.Events, "DisruptionBudgetConflict")
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+// estimateStartupQuantile is the quantile of the observed pod startup latency used as cold-start ETA.
+const estimateStartupQuantile = 0.9
+
+// PoolEstimate tells whether a new BatchSandbox with the given number of replicas can be satisfied by a Pool.
+type PoolEstimate struct {
+	Replicas int32 `json:"replicas"`
+	// Satisfiable is false if the pool can't grow enough within capacitySpec.poolMax.
+	Satisfiable bool `json:"satisfiable"`
+	// Immediate is true if enough ready idle pods are available right now.
+	Immediate bool `json:"immediate"`
+	// Available is the number of ready idle pods of the pool.
+	Available int32 `json:"available"`
+	// Warming is the number of idle pods of the pool that are not ready yet.
+	Warming int32 `json:"warming"`
+	// Queued is the number of pods requested by existing BatchSandboxes of the pool but not allocated yet.
+	// Queued requests are served first.
+	Queued int32 `json:"queued"`
+	// ColdStart is the number of pods that have to be created for the new BatchSandbox.
+	ColdStart int32 `json:"coldStart"`
+	// ETASeconds estimates when all replicas can be allocated, based on the observed pod startup latency of
+	// the pool. It is omitted if nothing has been observed yet.
+	ETASeconds *int64 `json:"etaSeconds,omitempty"`
+	// Reason explains why the request is not satisfiable.
+	Reason string `json:"reason,omitempty"`
+}
+
+// handleEstimate estimates whether a new BatchSandbox of N replicas can be satisfied from the current pool
+// capacity, so that batch submitters can defer submission. Callers must be authorized to "get" the pool.
+//
+//	GET /v1/namespaces/{namespace}/pools/{name}/estimate?replicas=N
+func (s *ManagerAPIServer) handleEstimate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	replicas, err := strconv.ParseInt(r.URL.Query().Get("replicas"), 10, 32)
+	if err != nil || replicas < 1 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("replicas must be a positive integer"))
+		return
+	}
+
+	status, err := s.reviewAccess(ctx, r, &authorizationv1.ResourceAttributes{
+		Namespace: key.Namespace,
+		Verb:      "get",
+		Group:     sandboxv1alpha1.GroupVersion.Group,
+		Resource:  "pools",
+		Name:      key.Name,
+	})
+	if err != nil {
+		writeAPIError(w, status, err)
+		return
+	}
+
+	pool := &sandboxv1alpha1.Pool{}
+	if err := s.Client.Get(ctx, key, pool); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeAPIError(w, http.StatusNotFound, fmt.Errorf("pool %s not found", key))
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	queued, err := queuedPoolDemand(ctx, s.Client, pool)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, estimatePool(pool, queued, int32(replicas)))
+}
+
+// queuedPoolDemand sums the replicas of the live BatchSandboxes of a pool that are not allocated yet.
+func queuedPoolDemand(ctx context.Context, c client.Client, pool *sandboxv1alpha1.Pool) (int32, error) {
+	list := &sandboxv1alpha1.BatchSandboxList{}
+	if err := c.List(ctx, list, &client.ListOptions{
+		Namespace:     pool.Namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForPoolRef: pool.Name}),
+	}); err != nil {
+		return 0, fmt.Errorf("failed to list batch sandboxes: %w", err)
+	}
+	queued := int32(0)
+	for i := range list.Items {
+		batchSbx := &list.Items[i]
+		if batchSbx.DeletionTimestamp != nil || batchSbx.Spec.Template != nil || batchSbx.Spec.Replicas == nil {
+			continue
+		}
+		alloc, err := sandboxAllocation(ctx, c, batchSbx)
+		if err != nil {
+			continue
+		}
+		queued += max(*batchSbx.Spec.Replicas-int32(len(alloc.Pods)), 0)
+	}
+	return queued, nil
+}
+
+// estimatePool serves queued demand from idle pods first, ready ones before warming ones, and the new
+// request from whatever is left; the rest has to be cold-started within capacitySpec.poolMax.
+func estimatePool(pool *sandboxv1alpha1.Pool, queued, replicas int32) *PoolEstimate {
+	available := pool.Status.Available
+	warming := max(pool.Status.Total-pool.Status.Allocated-available, 0)
+	est := &PoolEstimate{
+		Replicas:  replicas,
+		Available: available,
+		Warming:   warming,
+		Queued:    queued,
+	}
+
+	freeAvailable := max(available-queued, 0)
+	queuedLeft := max(queued-available, 0)
+	freeWarming := max(warming-queuedLeft, 0)
+	queuedLeft = max(queuedLeft-warming, 0)
+	if replicas <= freeAvailable {
+		est.Satisfiable, est.Immediate = true, true
+		est.ETASeconds = new(int64)
+		return est
+	}
+	fromWarming := min(replicas-freeAvailable, freeWarming)
+	est.ColdStart = replicas - freeAvailable - fromWarming
+	// An invalid schedule window is reported by the pool controller; the others still apply.
+	capacity, _ := effectiveCapacity(pool, time.Now())
+	poolMax := capacity.Capacity.PoolMax
+	headroom := max(poolMax-pool.Status.Total-queuedLeft, 0)
+	if est.ColdStart > headroom {
+		est.Reason = fmt.Sprintf("%d pods have to be created but the pool can only grow by %d within poolMax %d",
+			est.ColdStart, headroom, poolMax)
+		return est
+	}
+	est.Satisfiable = true
+	if eta, ok := podStartupQuantile(pool.Namespace, pool.Name, podStartupStageReady, estimateStartupQuantile); ok {
+		seconds := int64(eta.Round(time.Second) / time.Second)
+		est.ETASeconds = &seconds
+	}
+	return est
+}
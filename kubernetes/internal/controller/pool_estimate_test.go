@@ -0,0 +1,184 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+func TestEstimatePool(t *testing.T) {
+	newPool := func(name string, total, allocated, available, poolMax int32) *sandboxv1alpha1.Pool {
+		return &sandboxv1alpha1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "estimate"},
+			Spec:       sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{PoolMax: poolMax}},
+			Status:     sandboxv1alpha1.PoolStatus{Total: total, Allocated: allocated, Available: available},
+		}
+	}
+	tests := []struct {
+		name     string
+		pool     *sandboxv1alpha1.Pool
+		queued   int32
+		replicas int32
+		want     PoolEstimate
+	}{
+		{
+			name:     "served by idle pods",
+			pool:     newPool("idle", 10, 2, 6, 20),
+			replicas: 5,
+			want:     PoolEstimate{Replicas: 5, Satisfiable: true, Immediate: true, Available: 6, Warming: 2, ETASeconds: ptr.To[int64](0)},
+		},
+		{
+			name:     "queued requests go first",
+			pool:     newPool("queued", 10, 2, 6, 20),
+			queued:   3,
+			replicas: 5,
+			want:     PoolEstimate{Replicas: 5, Satisfiable: true, Available: 6, Warming: 2, Queued: 3},
+		},
+		{
+			name:     "cold start within poolMax",
+			pool:     newPool("cold", 10, 2, 6, 20),
+			queued:   4,
+			replicas: 10,
+			want:     PoolEstimate{Replicas: 10, Satisfiable: true, Available: 6, Warming: 2, Queued: 4, ColdStart: 6},
+		},
+		{
+			name:     "exceeds poolMax",
+			pool:     newPool("full", 10, 2, 6, 12),
+			queued:   10,
+			replicas: 3,
+			want: PoolEstimate{Replicas: 3, Available: 6, Warming: 2, Queued: 10, ColdStart: 3,
+				Reason: "3 pods have to be created but the pool can only grow by 0 within poolMax 12"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, &tt.want, estimatePool(tt.pool, tt.queued, tt.replicas))
+		})
+	}
+
+	t.Run("eta from observed startup latency", func(t *testing.T) {
+		pool := newPool("observed", 4, 4, 0, 10)
+		defer poolPodStartup.Forget(pool.Namespace, pool.Name)
+		for _, seconds := range []float64{3, 4, 8, 9, 12, 14, 18, 22, 25, 28} {
+			poolPodStartupSeconds.WithLabelValues(pool.Namespace, pool.Name, podStartupStageReady).Observe(seconds)
+		}
+		eta, ok := podStartupQuantile(pool.Namespace, pool.Name, podStartupStageReady, estimateStartupQuantile)
+		assert.True(t, ok)
+		// 7 samples fall below 20s and 3 into the 20-30s bucket, the 9th sample is two thirds into it
+		assert.Equal(t, 26*time.Second, eta.Truncate(time.Second))
+		got := estimatePool(pool, 0, 2)
+		assert.Equal(t, int32(2), got.ColdStart)
+		assert.Equal(t, int64(27), *got.ETASeconds)
+	})
+}
+
+func TestManagerAPIServerEstimate(t *testing.T) {
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(sandboxv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(authenticationv1.AddToScheme(scheme))
+	utilruntime.Must(authorizationv1.AddToScheme(scheme))
+
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{PoolMax: 8}},
+		Status:     sandboxv1alpha1.PoolStatus{Total: 4, Allocated: 1, Available: 3},
+	}
+	newSandbox := func(name, poolRef string, replicas int32, allocated string) *sandboxv1alpha1.BatchSandbox {
+		return &sandboxv1alpha1.BatchSandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{AnnoAllocStatusKey: allocated},
+			},
+			Spec: sandboxv1alpha1.BatchSandboxSpec{PoolRef: poolRef, Replicas: ptr.To(replicas)},
+		}
+	}
+
+	var reviewed []authorizationv1.ResourceAttributes
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(pool,
+			newSandbox("pending", "pool", 3, `{"pods":["pod-a"]}`),
+			newSandbox("other", "other-pool", 5, "")).
+		WithIndex(&sandboxv1alpha1.BatchSandbox{}, fieldindex.IndexNameForPoolRef, fieldindex.PoolRefIndexFunc).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				switch review := obj.(type) {
+				case *authenticationv1.TokenReview:
+					review.Status.Authenticated = true
+					review.Status.User.Username = review.Spec.Token
+				case *authorizationv1.SubjectAccessReview:
+					reviewed = append(reviewed, *review.Spec.ResourceAttributes)
+					review.Status.Allowed = review.Spec.User == "alice"
+				default:
+					return c.Create(ctx, obj, opts...)
+				}
+				return nil
+			},
+		}).Build()
+	ts := httptest.NewServer((&ManagerAPIServer{Client: c}).Handler())
+	defer ts.Close()
+
+	get := func(path, token string) (int, []byte) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.StatusCode, raw
+	}
+
+	code, _ := get("/v1/namespaces/default/pools/pool/estimate?replicas=0", "alice")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("/v1/namespaces/default/pools/pool/estimate?replicas=2", "bob")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = get("/v1/namespaces/default/pools/missing/estimate?replicas=2", "alice")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, raw := get("/v1/namespaces/default/pools/pool/estimate?replicas=2", "alice")
+	assert.Equal(t, http.StatusOK, code)
+	got := &PoolEstimate{}
+	assert.NoError(t, json.Unmarshal(raw, got))
+	assert.Equal(t, &PoolEstimate{Replicas: 2, Satisfiable: true, Available: 3, Queued: 2, ColdStart: 1}, got)
+	assert.Equal(t, authorizationv1.ResourceAttributes{
+		Namespace: "default",
+		Verb:      "get",
+		Group:     sandboxv1alpha1.GroupVersion.Group,
+		Resource:  "pools",
+		Name:      "pool",
+	}, reviewed[len(reviewed)-1])
+}
@@ -44,6 +44,9 @@ func (a *stubAllocator) GetPoolAllocation(_ context.Context, _ *sandboxv1alpha1.
 func (a *stubAllocator) ClearPoolAllocation(_ context.Context, _ string, _ string) error {
 	return nil
 }
+func (a *stubAllocator) RecoverPoolAllocation(_ context.Context, _ string, _ string) error {
+	return nil
+}
 func (a *stubAllocator) SyncSandboxAllocation(_ context.Context, _ *sandboxv1alpha1.BatchSandbox, _ []string) error {
 	return nil
 }
@@ -0,0 +1,273 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	gerrors "errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+)
+
+// LabelPoolFlavor is set on the pods of a pool flavor to the flavor name.
+const LabelPoolFlavor = "pool.sandbox.opensandbox.io/flavor"
+
+// calculatePoolRevision hashes the resolved pod template together with the flavors of the pool, so
+// that changing a flavor rolls the pool. Pools without flavors keep the revision of the template.
+func (r *PoolReconciler) calculatePoolRevision(pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec) (string, error) {
+	if len(pool.Spec.Flavors) == 0 {
+		return r.calculateRevision(template)
+	}
+	data, err := json.Marshal(struct {
+		Template *corev1.PodTemplateSpec      `json:"template"`
+		Flavors  []sandboxv1alpha1.PoolFlavor `json:"flavors"`
+	}{template, pool.Spec.Flavors})
+	if err != nil {
+		return "", err
+	}
+	revision := sha256.Sum256(data)
+	return hex.EncodeToString(revision[:8]), nil
+}
+
+// flavorTemplate returns the template with the patch of the flavor applied, labeled with the flavor.
+func flavorTemplate(template *corev1.PodTemplateSpec, flavor *sandboxv1alpha1.PoolFlavor) (*corev1.PodTemplateSpec, error) {
+	out := template.DeepCopy()
+	if len(flavor.Patch.Raw) > 0 {
+		original, err := json.Marshal(template)
+		if err != nil {
+			return nil, err
+		}
+		patched, err := strategicpatch.StrategicMergePatch(original, flavor.Patch.Raw, &corev1.PodTemplateSpec{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply the patch of flavor %s: %w", flavor.Name, err)
+		}
+		out = &corev1.PodTemplateSpec{}
+		if err := json.Unmarshal(patched, out); err != nil {
+			return nil, fmt.Errorf("failed to decode the template of flavor %s: %w", flavor.Name, err)
+		}
+	}
+	if out.Labels == nil {
+		out.Labels = make(map[string]string)
+	}
+	out.Labels[LabelPoolFlavor] = flavor.Name
+	return out, nil
+}
+
+// scheduleFlavors runs the allocation algorithm for each flavor on its own, so that sandboxes are
+// only given pods of the flavor they ask for. Requests of orphaned allocations only release pods and
+// go with the pool template. The supplement of each flavor is reported in FlavorSupplement; requests
+// for a flavor the pool does not have are left waiting.
+func scheduleFlavors(ctx context.Context, algo algorithm.Algorithm, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod,
+	sandboxes []*sandboxv1alpha1.BatchSandbox, availablePods []string, allRequest []*algorithm.SandboxRequest) *algorithm.AllocAction {
+	podFlavors := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		podFlavors[pod.Name] = pod.Labels[LabelPoolFlavor]
+	}
+	sandboxFlavors := make(map[string]string, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		sandboxFlavors[sandbox.Name] = sandbox.Spec.Flavor
+	}
+	flavorPods := make(map[string][]string)
+	for _, name := range availablePods {
+		flavor := podFlavors[name]
+		flavorPods[flavor] = append(flavorPods[flavor], name)
+	}
+	flavorRequests := make(map[string][]*algorithm.SandboxRequest)
+	for _, request := range allRequest {
+		flavor := sandboxFlavors[request.SandboxName]
+		flavorRequests[flavor] = append(flavorRequests[flavor], request)
+	}
+	known := map[string]bool{"": true}
+	for _, flavor := range pool.Spec.Flavors {
+		known[flavor.Name] = true
+	}
+
+	flavors := make([]string, 0, len(flavorRequests))
+	for flavor := range flavorRequests {
+		flavors = append(flavors, flavor)
+	}
+	sort.Strings(flavors)
+	action := &algorithm.AllocAction{
+		ToAllocate:       make(map[string][]string),
+		ToRelease:        make(map[string][]string),
+		FlavorSupplement: make(map[string]int32),
+	}
+	for _, flavor := range flavors {
+		if !known[flavor] {
+			logf.FromContext(ctx).Info("Sandboxes request a flavor the pool does not have", "pool", pool.Name, "flavor", flavor)
+		}
+		flavorAction := algo.Schedule(flavorPods[flavor], flavorRequests[flavor])
+		for name, pods := range flavorAction.ToAllocate {
+			action.ToAllocate[name] = pods
+		}
+		for name, pods := range flavorAction.ToRelease {
+			action.ToRelease[name] = pods
+		}
+		// No pods are created for a flavor the pool does not have.
+		if known[flavor] && flavorAction.PodSupplement > 0 {
+			action.PodSupplement += flavorAction.PodSupplement
+			action.FlavorSupplement[flavor] = flavorAction.PodSupplement
+		}
+	}
+	return action
+}
+
+// scaleFlavors scales the pods of each flavor of the pool to the buffer of the flavor, and the pods
+// of the pool template to the capacity of the pool. Idle pods of flavors that were removed from the
+// pool are deleted.
+func (r *PoolReconciler) scaleFlavors(ctx context.Context, pool *sandboxv1alpha1.Pool, args *scaleArgs) error {
+	known := make(map[string]bool, len(pool.Spec.Flavors))
+	for _, flavor := range pool.Spec.Flavors {
+		known[flavor.Name] = true
+	}
+	podFlavors := make(map[string]string, len(args.pods))
+	for _, pod := range args.pods {
+		podFlavors[pod.Name] = pod.Labels[LabelPoolFlavor]
+	}
+	partition := func(names []string, flavor string) []string {
+		var out []string
+		for _, name := range names {
+			if podFlavors[name] == flavor {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+	flavorArgs := func(flavor string) *scaleArgs {
+		out := &scaleArgs{
+			template:       args.template,
+			updateRevision: args.updateRevision,
+			allPods:        args.allPods,
+			totalPodCnt:    args.totalPodCnt,
+			idlePods:       partition(args.idlePods, flavor),
+			toDeletePods:   partition(args.toDeletePods, flavor),
+			upgradePods:    partition(args.upgradePods, flavor),
+			supplyCnt:      args.flavorSupplyCnt[flavor] + int32(len(partition(args.replacedPods, flavor))),
+			heldScaleIn:    args.heldScaleIn,
+			deferred:       args.deferred,
+		}
+		for _, pod := range args.pods {
+			if podFlavors[pod.Name] != flavor {
+				continue
+			}
+			out.pods = append(out.pods, pod)
+			if _, ok := args.allocation[pod.Name]; ok || slices.Contains(args.reservedPods, pod.Name) {
+				out.allocatedCnt++
+			}
+		}
+		return out
+	}
+
+	// Pods of removed flavors are not scaled with any flavor: the idle ones are deleted, unless the
+	// pool is paused, and allocated ones once they are released.
+	var errs []error
+	removed := sets.New[string]()
+	for _, name := range args.toDeletePods {
+		if flavor := podFlavors[name]; flavor != "" && !known[flavor] {
+			removed.Insert(name)
+		}
+	}
+	if !pool.Spec.Paused {
+		for _, name := range args.idlePods {
+			if flavor := podFlavors[name]; flavor != "" && !known[flavor] {
+				removed.Insert(name)
+			}
+		}
+	}
+	var toDelete []*corev1.Pod
+	for _, pod := range r.pickPodsToDelete(args.pods, nil, sets.List(removed), 0, nil) {
+		if pod.DeletionTimestamp == nil {
+			toDelete = append(toDelete, pod)
+		}
+	}
+	_, deleteErrs := r.deletePoolPods(ctx, pool, toDelete, args.deferred)
+	errs = append(errs, deleteErrs...)
+
+	// The pool template first, so that it has the capacity of the pool before flavors take a share.
+	partArgs := flavorArgs("")
+	partArgs.targetBuffer = args.targetBuffer
+	created, err := r.scalePoolPods(ctx, pool, partArgs)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	totalPodCnt := args.totalPodCnt + created
+	for i := range pool.Spec.Flavors {
+		flavor := &pool.Spec.Flavors[i]
+		partArgs := flavorArgs(flavor.Name)
+		partArgs.totalPodCnt = totalPodCnt
+		if args.template != nil {
+			if partArgs.template, err = flavorTemplate(args.template, flavor); err != nil {
+				// Without a template the flavor still scales in, like a pool without one.
+				r.Recorder.Eventf(pool, corev1.EventTypeWarning, "InvalidFlavor", "%v", err)
+				errs = append(errs, err)
+			}
+		}
+		flavorPool := *pool
+		flavorPool.Spec.CapacitySpec = sandboxv1alpha1.CapacitySpec{
+			BufferMin: flavor.BufferMin,
+			BufferMax: flavor.BufferMax,
+			PoolMax:   pool.Spec.CapacitySpec.PoolMax,
+		}
+		created, err := r.scalePoolPods(ctx, &flavorPool, partArgs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		totalPodCnt += created
+	}
+	return gerrors.Join(errs...)
+}
+
+// calculateFlavorStatus breaks the pods of the pool down by flavor, nil for pools without flavors.
+func calculateFlavorStatus(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, schedulePods []*corev1.Pod, podAllocation map[string]string) []sandboxv1alpha1.PoolFlavorStatus {
+	if len(pool.Spec.Flavors) == 0 {
+		return nil
+	}
+	result := make([]sandboxv1alpha1.PoolFlavorStatus, len(pool.Spec.Flavors))
+	index := make(map[string]int, len(pool.Spec.Flavors))
+	for i, flavor := range pool.Spec.Flavors {
+		result[i].Name = flavor.Name
+		index[flavor.Name] = i
+	}
+	for _, pod := range pods {
+		if i, ok := index[pod.Labels[LabelPoolFlavor]]; ok {
+			result[i].Total++
+			if _, ok := podAllocation[pod.Name]; ok {
+				result[i].Allocated++
+			}
+		}
+	}
+	for _, pod := range schedulePods {
+		i, ok := index[pod.Labels[LabelPoolFlavor]]
+		if !ok {
+			continue
+		}
+		if _, ok := podAllocation[pod.Name]; !ok && isPodAvailable(pool, pod) {
+			result[i].Available++
+		}
+	}
+	return result
+}
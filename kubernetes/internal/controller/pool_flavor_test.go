@@ -0,0 +1,165 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/algorithm"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func newFlavorPod(name, flavor string) *corev1.Pod {
+	pod := newZonePod(name, "", 0, true)
+	if flavor != "" {
+		pod.Labels = map[string]string{LabelPoolFlavor: flavor}
+	}
+	return pod
+}
+
+func gpuFlavor(bufferMin, bufferMax int32) sandboxv1alpha1.PoolFlavor {
+	return sandboxv1alpha1.PoolFlavor{
+		Name:      "gpu",
+		Patch:     runtime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"main","image":"cuda"}]}}`)},
+		BufferMin: bufferMin,
+		BufferMax: bufferMax,
+	}
+}
+
+func TestFlavorTemplate(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "main", Image: "busybox"}, {Name: "sidecar", Image: "proxy"},
+	}}}
+	flavor := gpuFlavor(0, 0)
+	out, err := flavorTemplate(template, &flavor)
+	require.NoError(t, err)
+	assert.Equal(t, "gpu", out.Labels[LabelPoolFlavor])
+	require.Len(t, out.Spec.Containers, 2, "containers are merged by name")
+	assert.Equal(t, "cuda", out.Spec.Containers[0].Image)
+	assert.Equal(t, "proxy", out.Spec.Containers[1].Image)
+	assert.Equal(t, "busybox", template.Spec.Containers[0].Image, "the template is not modified")
+
+	out, err = flavorTemplate(template, &sandboxv1alpha1.PoolFlavor{Name: "plain"})
+	require.NoError(t, err)
+	assert.Equal(t, "busybox", out.Spec.Containers[0].Image)
+	assert.Equal(t, "plain", out.Labels[LabelPoolFlavor])
+
+	_, err = flavorTemplate(template, &sandboxv1alpha1.PoolFlavor{Name: "bad", Patch: runtime.RawExtension{Raw: []byte(`{"spec":[]}`)}})
+	assert.Error(t, err)
+}
+
+func TestScheduleFlavors(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{Flavors: []sandboxv1alpha1.PoolFlavor{gpuFlavor(1, 1)}}}
+	pods := []*corev1.Pod{newFlavorPod("cpu-0", ""), newFlavorPod("gpu-0", "gpu")}
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}, Spec: sandboxv1alpha1.BatchSandboxSpec{Flavor: "gpu"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tpu"}, Spec: sandboxv1alpha1.BatchSandboxSpec{Flavor: "tpu"}},
+	}
+	requests := []*algorithm.SandboxRequest{
+		{SandboxName: "cpu", PodSupplement: 1},
+		{SandboxName: "gpu", PodSupplement: 2},
+		{SandboxName: "tpu", PodSupplement: 1},
+	}
+	action := scheduleFlavors(context.Background(), &algorithm.PackedSchedule{}, pool, pods, sandboxes, []string{"cpu-0", "gpu-0"}, requests)
+	assert.Equal(t, []string{"cpu-0"}, action.ToAllocate["cpu"])
+	assert.Equal(t, []string{"gpu-0"}, action.ToAllocate["gpu"])
+	assert.Empty(t, action.ToAllocate["tpu"], "the pool has no tpu flavor")
+	assert.Equal(t, map[string]int32{"gpu": 1}, action.FlavorSupplement)
+	assert.Equal(t, int32(1), action.PodSupplement, "no pods are requested for unknown flavors")
+}
+
+func TestScalePoolFlavors(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	maxUnavailable := intstr.FromString("100%")
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "flavors", Namespace: "default", UID: types.UID("uid-flavors")},
+		Spec: sandboxv1alpha1.PoolSpec{
+			CapacitySpec:  sandboxv1alpha1.CapacitySpec{BufferMin: 1, BufferMax: 1, PoolMax: 10},
+			ScaleStrategy: &sandboxv1alpha1.ScaleStrategy{MaxUnavailable: &maxUnavailable},
+			Flavors:       []sandboxv1alpha1.PoolFlavor{gpuFlavor(2, 2)},
+		},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	cpu, gpu, removed := newFlavorPod("flavors-cpu", ""), newFlavorPod("flavors-gpu", "gpu"), newFlavorPod("flavors-old", "tpu")
+	pods := []*corev1.Pod{cpu, gpu, removed}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(cpu, gpu, removed).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	// The gpu pod is allocated and one more is requested for it.
+	args := &scaleArgs{
+		template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 3, allocatedCnt: 1,
+		idlePods: []string{"flavors-cpu", "flavors-old"}, supplyCnt: 1,
+		allocation:      map[string]string{"flavors-gpu": "bs"},
+		flavorSupplyCnt: map[string]int32{"gpu": 1},
+	}
+	require.NoError(t, r.scalePool(context.Background(), pool, args))
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), list))
+	byFlavor := map[string][]corev1.Pod{}
+	for _, pod := range list.Items {
+		byFlavor[pod.Labels[LabelPoolFlavor]] = append(byFlavor[pod.Labels[LabelPoolFlavor]], pod)
+	}
+	assert.Len(t, byFlavor[""], 1, "the pool template keeps its buffer of one")
+	require.Len(t, byFlavor["gpu"], 4, "the allocated pod, the requested one and a buffer of two")
+	for _, pod := range byFlavor["gpu"] {
+		if pod.Name != gpu.Name {
+			assert.Equal(t, "cuda", pod.Spec.Containers[0].Image)
+		}
+	}
+	assert.Empty(t, byFlavor["tpu"], "idle pods of removed flavors are deleted")
+}
+
+func TestCalculateFlavorStatus(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{Flavors: []sandboxv1alpha1.PoolFlavor{gpuFlavor(0, 0)}}}
+	pods := []*corev1.Pod{newFlavorPod("cpu", ""), newFlavorPod("gpu-0", "gpu"), newFlavorPod("gpu-1", "gpu"), newFlavorPod("gpu-2", "gpu")}
+	pods[3].Status.Phase = corev1.PodPending
+	status := calculateFlavorStatus(pool, pods, pods, map[string]string{"gpu-0": "bs"})
+	assert.Equal(t, []sandboxv1alpha1.PoolFlavorStatus{{Name: "gpu", Total: 3, Allocated: 1, Available: 1}}, status)
+	assert.Nil(t, calculateFlavorStatus(&sandboxv1alpha1.Pool{}, pods, pods, nil))
+}
+
+func TestCalculatePoolRevisionFlavors(t *testing.T) {
+	r := &PoolReconciler{}
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	pool := &sandboxv1alpha1.Pool{}
+	plain, err := r.calculateRevision(template)
+	require.NoError(t, err)
+	revision, err := r.calculatePoolRevision(pool, template)
+	require.NoError(t, err)
+	assert.Equal(t, plain, revision, "pools without flavors keep their revision")
+
+	pool.Spec.Flavors = []sandboxv1alpha1.PoolFlavor{gpuFlavor(1, 1)}
+	withFlavor, err := r.calculatePoolRevision(pool, template)
+	require.NoError(t, err)
+	assert.NotEqual(t, plain, withFlavor)
+	pool.Spec.Flavors[0].Patch.Raw = []byte(`{"spec":{"containers":[{"name":"main","image":"cuda:12"}]}}`)
+	changed, err := r.calculatePoolRevision(pool, template)
+	require.NoError(t, err)
+	assert.NotEqual(t, withFlavor, changed, "changing a flavor rolls the pool")
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	gerrors "errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+// +kubebuilder:rbac:groups=core,resources=pods/resize,verbs=patch
+
+const defaultHibernationIdle = 5 * time.Minute
+
+// poolIdleSince is poolKey -> pod name -> when the pool first saw the pod idle.
+var poolIdleSince sync.Map
+
+func hibernationIdle(hibernation *sandboxv1alpha1.PoolHibernation) time.Duration {
+	if hibernation.IdleSeconds == nil {
+		return defaultHibernationIdle
+	}
+	return time.Duration(*hibernation.IdleSeconds) * time.Second
+}
+
+// observeIdlePods records when the idle pods of the pool became idle, forgetting the pods that no
+// longer are, and returns the record.
+func observeIdlePods(key string, idlePods []string, now time.Time) map[string]time.Time {
+	var prev map[string]time.Time
+	if v, ok := poolIdleSince.Load(key); ok {
+		prev = v.(map[string]time.Time)
+	}
+	since := make(map[string]time.Time, len(idlePods))
+	for _, name := range idlePods {
+		if at, ok := prev[name]; ok {
+			since[name] = at
+		} else {
+			since[name] = now
+		}
+	}
+	poolIdleSince.Store(key, since)
+	return since
+}
+
+// hibernatedRequests returns the container requests recorded on a hibernated pod, and whether the
+// pod is hibernated.
+func hibernatedRequests(pod *corev1.Pod) (map[string]corev1.ResourceList, bool) {
+	raw, ok := pod.Annotations[AnnoPoolHibernatedKey]
+	if !ok {
+		return nil, false
+	}
+	requests := map[string]corev1.ResourceList{}
+	// A corrupted record leaves nothing to restore, but the pod still wakes.
+	_ = json.Unmarshal([]byte(raw), &requests)
+	return requests, true
+}
+
+// lowerRequests lowers the container requests of the pod to requests and returns the requests it
+// replaced by container, empty if none was larger.
+func lowerRequests(pod *corev1.Pod, requests corev1.ResourceList) map[string]corev1.ResourceList {
+	replaced := map[string]corev1.ResourceList{}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		for name, quantity := range requests {
+			current, ok := container.Resources.Requests[name]
+			if !ok || current.Cmp(quantity) <= 0 {
+				continue
+			}
+			if replaced[container.Name] == nil {
+				replaced[container.Name] = corev1.ResourceList{}
+			}
+			replaced[container.Name][name] = current
+			container.Resources.Requests[name] = quantity
+		}
+	}
+	return replaced
+}
+
+// restoreRequests sets the container requests of the pod back to the recorded ones and returns
+// whether any changed.
+func restoreRequests(pod *corev1.Pod, recorded map[string]corev1.ResourceList) bool {
+	changed := false
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		for name, quantity := range recorded[container.Name] {
+			if current, ok := container.Resources.Requests[name]; ok && current.Equal(quantity) {
+				continue
+			}
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[name] = quantity
+			changed = true
+		}
+	}
+	return changed
+}
+
+// syncHibernation hibernates the available idle pods of the pool once they have been idle for
+// hibernation.idleSeconds and wakes every other hibernated pod, such as the pods allocated or reserved
+// since. Guaranteed pods are left alone, as lowering their requests would change their QoS class.
+// A paused pool hibernates no pod but still wakes them. It returns when the next idle pod is due to
+// hibernate.
+func (r *PoolReconciler) syncHibernation(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, idlePods []string, now time.Time) (time.Duration, error) {
+	key := pool.Namespace + "/" + pool.Name
+	hibernation := pool.Spec.Hibernation
+	var idleSince map[string]time.Time
+	if hibernation != nil && pool.DeletionTimestamp.IsZero() {
+		idleSince = observeIdlePods(key, idlePods, now)
+	} else {
+		poolIdleSince.Delete(key)
+	}
+
+	var requeueAfter time.Duration
+	var errs []error
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		_, hibernated := hibernatedRequests(pod)
+		since, idle := idleSince[pod.Name]
+		if !idle {
+			if hibernated {
+				errs = append(errs, r.wakePod(ctx, pool, pod))
+			}
+			continue
+		}
+		if hibernated || pool.Spec.Paused || pod.Status.QOSClass == corev1.PodQOSGuaranteed || !isPodAvailable(pool, pod) {
+			continue
+		}
+		if wait := since.Add(hibernationIdle(hibernation)).Sub(now); wait > 0 {
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+			continue
+		}
+		errs = append(errs, r.hibernatePod(ctx, pool, pod, hibernation.Requests))
+	}
+	return requeueAfter, gerrors.Join(errs...)
+}
+
+// hibernatePod records the requests of the pod before resizing it down, so that the pod wakes with
+// them even if the pool template has changed since.
+func (r *PoolReconciler) hibernatePod(ctx context.Context, pool *sandboxv1alpha1.Pool, pod *corev1.Pod, requests corev1.ResourceList) error {
+	resized := pod.DeepCopy()
+	replaced := lowerRequests(resized, requests)
+	if len(replaced) == 0 {
+		return nil
+	}
+	annotated := pod.DeepCopy()
+	if annotated.Annotations == nil {
+		annotated.Annotations = map[string]string{}
+	}
+	annotated.Annotations[AnnoPoolHibernatedKey] = utils.DumpJSON(replaced)
+	if err := r.Patch(ctx, annotated, client.MergeFrom(pod)); err != nil {
+		return fmt.Errorf("failed to mark pod %s hibernated: %w", pod.Name, err)
+	}
+	annotated.ObjectMeta.DeepCopyInto(&resized.ObjectMeta)
+	if err := r.SubResource("resize").Patch(ctx, resized, client.StrategicMergeFrom(annotated)); err != nil {
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "HibernationFailed", "Failed to resize idle pod %s: %v", pod.Name, err)
+		// Unmarked, the pod is tried again instead of being woken to the requests it still has.
+		awake := annotated.DeepCopy()
+		delete(awake.Annotations, AnnoPoolHibernatedKey)
+		if unmarkErr := r.Patch(ctx, awake, client.MergeFrom(annotated)); unmarkErr != nil {
+			return gerrors.Join(err, unmarkErr)
+		}
+		return fmt.Errorf("failed to hibernate pod %s: %w", pod.Name, err)
+	}
+	logf.FromContext(ctx).Info("Hibernated idle pod", "pool", pool.Name, "pod", pod.Name, "requests", requests)
+	return nil
+}
+
+// wakeAllocatedPods wakes the hibernated pods about to be allocated before the sandboxes are told about them,
+// so that no sandbox counts a pod ready while it still has its hibernation requests. A pod that fails to wake
+// is allocated all the same: it stays marked, which keeps it from counting as ready, and syncHibernation wakes
+// it later.
+func (r *PoolReconciler) wakeAllocatedPods(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, toAllocate map[string][]string) {
+	allocated := sets.New[string]()
+	for _, names := range toAllocate {
+		allocated.Insert(names...)
+	}
+	for _, pod := range pods {
+		if _, hibernated := hibernatedRequests(pod); !hibernated || !allocated.Has(pod.Name) {
+			continue
+		}
+		if err := r.wakePod(ctx, pool, pod); err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to wake allocated pod", "pool", pool.Name, "pod", pod.Name)
+		}
+	}
+}
+
+// wakePod resizes a hibernated pod back to its recorded requests and then unmarks it, updating pod to the
+// woken pod. The kubelet may defer the resize while the node lacks room; the pod serves with its smaller
+// requests meanwhile.
+func (r *PoolReconciler) wakePod(ctx context.Context, pool *sandboxv1alpha1.Pool, pod *corev1.Pod) error {
+	recorded, _ := hibernatedRequests(pod)
+	awake := pod.DeepCopy()
+	if restoreRequests(awake, recorded) {
+		if err := r.SubResource("resize").Patch(ctx, awake, client.StrategicMergeFrom(pod)); err != nil {
+			r.Recorder.Eventf(pool, corev1.EventTypeWarning, "WakeFailed", "Failed to resize pod %s back: %v", pod.Name, err)
+			return fmt.Errorf("failed to wake pod %s: %w", pod.Name, err)
+		}
+	}
+	unmarked := awake.DeepCopy()
+	delete(unmarked.Annotations, AnnoPoolHibernatedKey)
+	if err := r.Patch(ctx, unmarked, client.MergeFrom(awake)); err != nil {
+		return fmt.Errorf("failed to unmark woken pod %s: %w", pod.Name, err)
+	}
+	unmarked.DeepCopyInto(pod)
+	logf.FromContext(ctx).Info("Woke hibernated pod", "pool", pool.Name, "pod", pod.Name)
+	return nil
+}
+
+// hibernationCandidates returns the idle pods that are not about to be deleted.
+func hibernationCandidates(idlePods, toDeletePods []string) []string {
+	candidates := make([]string, 0, len(idlePods))
+	for _, name := range idlePods {
+		if !slices.Contains(toDeletePods, name) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+func countHibernated(pods []*corev1.Pod) int32 {
+	count := int32(0)
+	for _, pod := range pods {
+		if _, ok := hibernatedRequests(pod); ok {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestSyncHibernation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hibernating", Namespace: "default"},
+		Spec: sandboxv1alpha1.PoolSpec{Hibernation: &sandboxv1alpha1.PoolHibernation{
+			IdleSeconds: ptr.To[int32](60),
+			Requests:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+		}},
+	}
+	t.Cleanup(func() { poolIdleSince.Delete("default/hibernating") })
+	newPod := func(name string, qos corev1.PodQOSClass) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "main", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+				}},
+				{Name: "sidecar", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5m")},
+				}},
+			}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				QOSClass:   qos,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	idle := newPod("idle", corev1.PodQOSBurstable)
+	allocated := newPod("allocated", corev1.PodQOSBurstable)
+	guaranteed := newPod("guaranteed", corev1.PodQOSGuaranteed)
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(idle, allocated, guaranteed).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	get := func(name string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+		return pod
+	}
+	sync := func(idlePods []string, now time.Time) time.Duration {
+		pods := []*corev1.Pod{get("idle"), get("allocated"), get("guaranteed")}
+		requeue, err := r.syncHibernation(ctx, pool, pods, idlePods, now)
+		require.NoError(t, err)
+		return requeue
+	}
+
+	assert.Equal(t, time.Minute, sync([]string{"idle", "guaranteed"}, now), "pods hibernate once idle for idleSeconds")
+	assert.NotContains(t, get("idle").Annotations, AnnoPoolHibernatedKey)
+	assert.Equal(t, 30*time.Second, sync([]string{"idle", "guaranteed"}, now.Add(30*time.Second)))
+
+	assert.Zero(t, sync([]string{"idle", "guaranteed"}, now.Add(time.Minute)))
+	pod := get("idle")
+	recorded, hibernated := hibernatedRequests(pod)
+	require.True(t, hibernated)
+	assert.Equal(t, map[string]corev1.ResourceList{"main": {
+		corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}}, recorded, "only the requests that were lowered are recorded")
+	main := pod.Spec.Containers[0].Resources
+	assert.True(t, main.Requests.Cpu().Equal(resource.MustParse("10m")))
+	assert.True(t, main.Requests.Memory().Equal(resource.MustParse("64Mi")))
+	assert.True(t, main.Limits.Cpu().Equal(resource.MustParse("2")), "limits are kept")
+	assert.True(t, pod.Spec.Containers[1].Resources.Requests.Cpu().Equal(resource.MustParse("5m")), "smaller requests are kept")
+	assert.NotContains(t, get("guaranteed").Annotations, AnnoPoolHibernatedKey, "guaranteed pods would change their QoS class")
+	assert.NotContains(t, get("allocated").Annotations, AnnoPoolHibernatedKey)
+	assert.Equal(t, int32(1), countHibernated([]*corev1.Pod{get("idle"), get("allocated"), get("guaranteed")}))
+
+	assert.Zero(t, sync([]string{"guaranteed"}, now.Add(2*time.Minute)), "an allocated pod wakes")
+	pod = get("idle")
+	assert.NotContains(t, pod.Annotations, AnnoPoolHibernatedKey)
+	assert.True(t, pod.Spec.Containers[0].Resources.Requests.Cpu().Equal(resource.MustParse("2")))
+	assert.True(t, pod.Spec.Containers[0].Resources.Requests.Memory().Equal(resource.MustParse("4Gi")))
+
+	assert.Equal(t, time.Minute, sync([]string{"idle", "guaranteed"}, now.Add(3*time.Minute)), "the idle time starts over after an allocation")
+}
+
+func TestSyncHibernation_Disabled(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "hibernated", Namespace: "default", Annotations: map[string]string{
+			AnnoPoolHibernatedKey: `{"main":{"cpu":"1"}}`,
+		}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+		}}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pod).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"}}
+
+	requeue, err := r.syncHibernation(ctx, pool, []*corev1.Pod{pod}, []string{"hibernated"}, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, requeue)
+	got := &corev1.Pod{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pod), got))
+	assert.NotContains(t, got.Annotations, AnnoPoolHibernatedKey, "removing hibernation wakes idle pods")
+	assert.True(t, got.Spec.Containers[0].Resources.Requests.Cpu().Equal(resource.MustParse("1")))
+}
+
+func TestWakeAllocatedPods(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "hibernating", Namespace: "default"}}
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: map[string]string{
+				AnnoPoolHibernatedKey: `{"main":{"cpu":"2"}}`,
+			}},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+			}}}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	sandbox := &sandboxv1alpha1.BatchSandbox{ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default"}}
+	readyPods := func(pods ...*corev1.Pod) int32 {
+		return buildRuntimeView(sandbox, pods).status.Ready
+	}
+
+	t.Run("woken before it is handed out", func(t *testing.T) {
+		allocated, idle := newPod("allocated"), newPod("idle")
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(allocated, idle).Build()
+		r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+		r.wakeAllocatedPods(ctx, pool, []*corev1.Pod{allocated, idle}, map[string][]string{"sbx": {"allocated"}})
+
+		got := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(allocated), got))
+		assert.NotContains(t, got.Annotations, AnnoPoolHibernatedKey)
+		assert.True(t, got.Spec.Containers[0].Resources.Requests.Cpu().Equal(resource.MustParse("2")))
+		assert.NotContains(t, allocated.Annotations, AnnoPoolHibernatedKey, "the listed pod is updated as well")
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(idle), got))
+		assert.Contains(t, got.Annotations, AnnoPoolHibernatedKey, "idle pods stay hibernated")
+		assert.Equal(t, int32(1), readyPods(allocated))
+	})
+
+	t.Run("not ready while the wake fails", func(t *testing.T) {
+		allocated := newPod("allocated")
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(allocated).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				return fmt.Errorf("node has no room")
+			},
+		}).Build()
+		r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+		r.wakeAllocatedPods(ctx, pool, []*corev1.Pod{allocated}, map[string][]string{"sbx": {"allocated"}})
+
+		got := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(allocated), got))
+		assert.Contains(t, got.Annotations, AnnoPoolHibernatedKey)
+		got.Status = allocated.Status
+		assert.Zero(t, readyPods(got), "a pod still hibernated is not reported ready")
+	})
+}
+
+func TestHibernationCandidates(t *testing.T) {
+	assert.Equal(t, []string{"a", "c"}, hibernationCandidates([]string{"a", "b", "c"}, []string{"b", "d"}))
+	assert.Empty(t, hibernationCandidates(nil, []string{"b"}))
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+
+const (
+	// LabelPoolPrePull marks the pre-pull DaemonSet of a pool and its pods with the name of the pool.
+	LabelPoolPrePull = "sandbox.opensandbox.io/pool-prepull"
+
+	defaultPrePullTimeout = 10 * time.Minute
+	// prePullPollInterval is how often the progress of a pre-pull is checked.
+	prePullPollInterval = 5 * time.Second
+)
+
+// prePullDaemonSetName returns the name of the pre-pull DaemonSet of the pool.
+func prePullDaemonSetName(pool *sandboxv1alpha1.Pool) string {
+	return pool.Name + "-prepull"
+}
+
+// prePullTimeout returns updateStrategy.prePull.timeoutSeconds of the pool, else the default.
+func prePullTimeout(pool *sandboxv1alpha1.Pool) time.Duration {
+	if seconds := pool.Spec.UpdateStrategy.PrePull.TimeoutSeconds; seconds != nil {
+		return time.Duration(*seconds) * time.Second
+	}
+	return defaultPrePullTimeout
+}
+
+// podSpecImages returns the images of the containers and init containers of the pod spec, in order.
+func podSpecImages(spec *corev1.PodSpec) []string {
+	var images []string
+	for _, container := range slices.Concat(spec.InitContainers, spec.Containers) {
+		if container.Image != "" && !slices.Contains(images, container.Image) {
+			images = append(images, container.Image)
+		}
+	}
+	return images
+}
+
+// imagesToPrePull returns the images of the template that the pods of other revisions than the latest do
+// not use. It returns none without such pods, since there is nothing to replace then.
+func imagesToPrePull(template *corev1.PodTemplateSpec, revision string, pods []*corev1.Pod) []string {
+	var replaced []string
+	for _, pod := range pods {
+		if pod.Labels[LabelPoolRevision] == revision || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, image := range podSpecImages(&pod.Spec) {
+			if !slices.Contains(replaced, image) {
+				replaced = append(replaced, image)
+			}
+		}
+	}
+	if len(replaced) == 0 {
+		return nil
+	}
+	return slices.DeleteFunc(podSpecImages(&template.Spec), func(image string) bool { return slices.Contains(replaced, image) })
+}
+
+// prePullPodTemplate returns the pod template of the pre-pull DaemonSet: a container for each image on
+// the nodes the pods of the pool can run on. The containers only exit, since an image is pulled once its
+// container is created, whether or not it has a shell to run.
+func prePullPodTemplate(pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec, revision string, images []string) corev1.PodTemplateSpec {
+	podLabels := map[string]string{LabelPoolPrePull: pool.Name, LabelPoolRevision: revision}
+	spec := corev1.PodSpec{
+		NodeSelector:                  template.Spec.NodeSelector,
+		Tolerations:                   template.Spec.Tolerations,
+		ImagePullSecrets:              template.Spec.ImagePullSecrets,
+		AutomountServiceAccountToken:  ptr.To(false),
+		TerminationGracePeriodSeconds: ptr.To(int64(0)),
+	}
+	if affinity := template.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		spec.Affinity = &corev1.Affinity{NodeAffinity: affinity.NodeAffinity}
+	}
+	for i, image := range images {
+		spec.Containers = append(spec.Containers, corev1.Container{
+			Name:            fmt.Sprintf("prepull-%d", i),
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"sh", "-c", "exit 0"},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1m"),
+					corev1.ResourceMemory: resource.MustParse("4Mi"),
+				},
+			},
+		})
+	}
+	return corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}, Spec: spec}
+}
+
+// podPulledImages reports whether every container of the pre-pull pod has pulled its image, which the
+// kubelet reports with the image ID once the container is created.
+func podPulledImages(pod *corev1.Pod) bool {
+	if len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.ImageID == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// syncImagePrePull pulls the images that the latest revision adds onto the nodes of the pool with a
+// DaemonSet, and returns the status of the pre-pull and when to check on it again. Idle pods are not
+// replaced while the status is Pulling. The DaemonSet is deleted once every node it runs on has pulled the
+// images, or at the timeout.
+func (r *PoolReconciler) syncImagePrePull(ctx context.Context, pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec,
+	revision string, pods []*corev1.Pod, now time.Time) (*sandboxv1alpha1.ImagePrePullStatus, time.Duration, error) {
+	log := logf.FromContext(ctx)
+	enabled := pool.Spec.UpdateStrategy != nil && pool.Spec.UpdateStrategy.PrePull != nil
+	if !enabled || template == nil || pool.Spec.Paused || !pool.DeletionTimestamp.IsZero() {
+		var status *sandboxv1alpha1.ImagePrePullStatus
+		if enabled {
+			status = pool.Status.PrePull
+		}
+		return status, 0, r.deletePrePullDaemonSet(ctx, pool)
+	}
+
+	status := pool.Status.PrePull
+	if status != nil && status.Revision == revision && status.Phase != sandboxv1alpha1.ImagePrePullPhasePulling {
+		return status, 0, r.deletePrePullDaemonSet(ctx, pool)
+	}
+	images := imagesToPrePull(template, revision, pods)
+	if len(images) == 0 {
+		// Nothing is replaced, or the replacements use the images the pods already run.
+		return nil, 0, r.deletePrePullDaemonSet(ctx, pool)
+	}
+	if status == nil || status.Revision != revision {
+		status = &sandboxv1alpha1.ImagePrePullStatus{
+			Revision:  revision,
+			Images:    images,
+			Phase:     sandboxv1alpha1.ImagePrePullPhasePulling,
+			StartTime: metav1.NewTime(now),
+		}
+		r.Recorder.Eventf(pool, corev1.EventTypeNormal, "PrePullingImages", "Pulling images %v of revision %s onto the nodes of the pool", images, revision)
+	} else {
+		status = status.DeepCopy()
+	}
+
+	ds, err := r.ensurePrePullDaemonSet(ctx, pool, prePullPodTemplate(pool, template, revision, status.Images))
+	if err != nil || ds == nil {
+		return nil, 0, err
+	}
+	if ds.Status.ObservedGeneration > 0 && ds.Status.ObservedGeneration >= ds.Generation {
+		list := &corev1.PodList{}
+		if err := r.List(ctx, list, client.InNamespace(pool.Namespace), client.MatchingLabels{LabelPoolPrePull: pool.Name, LabelPoolRevision: revision}); err != nil {
+			return nil, 0, err
+		}
+		pulled := int32(0)
+		for i := range list.Items {
+			if podPulledImages(&list.Items[i]) {
+				pulled++
+			}
+		}
+		status.Nodes = ds.Status.DesiredNumberScheduled
+		status.Pulled = min(pulled, status.Nodes)
+		if status.Pulled >= status.Nodes {
+			status.Phase = sandboxv1alpha1.ImagePrePullPhaseComplete
+			log.Info("Pre-pulled images", "pool", pool.Name, "revision", revision, "nodes", status.Nodes)
+			r.Recorder.Eventf(pool, corev1.EventTypeNormal, "PrePulledImages", "Pulled images of revision %s onto %d nodes", revision, status.Nodes)
+			return status, 0, r.deletePrePullDaemonSet(ctx, pool)
+		}
+	}
+	remaining := status.StartTime.Add(prePullTimeout(pool)).Sub(now)
+	if remaining <= 0 {
+		status.Phase = sandboxv1alpha1.ImagePrePullPhaseTimedOut
+		log.Info("Image pre-pull timed out", "pool", pool.Name, "revision", revision, "pulled", status.Pulled, "nodes", status.Nodes)
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "ImagePrePullTimedOut",
+			"Only %d of %d nodes pulled the images of revision %s in time, replacing pods anyway", status.Pulled, status.Nodes, revision)
+		return status, 0, r.deletePrePullDaemonSet(ctx, pool)
+	}
+	return status, min(prePullPollInterval, remaining), nil
+}
+
+// ensurePrePullDaemonSet creates the pre-pull DaemonSet of the pool with the pod template, or updates it to
+// the template. It returns nil when a DaemonSet of the same name belongs to someone else.
+func (r *PoolReconciler) ensurePrePullDaemonSet(ctx context.Context, pool *sandboxv1alpha1.Pool, template corev1.PodTemplateSpec) (*appsv1.DaemonSet, error) {
+	ds := &appsv1.DaemonSet{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: pool.Namespace, Name: prePullDaemonSetName(pool)}, ds)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if errors.IsNotFound(err) {
+		ds = &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      prePullDaemonSetName(pool),
+				Namespace: pool.Namespace,
+				Labels:    map[string]string{LabelPoolPrePull: pool.Name},
+			},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{LabelPoolPrePull: pool.Name}},
+				Template: template,
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+					Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: ptr.To(intstr.FromString("100%"))},
+				},
+			},
+		}
+		if err := ctrl.SetControllerReference(pool, ds, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, ds); err != nil {
+			return nil, err
+		}
+		logf.FromContext(ctx).Info("Created image pre-pull DaemonSet", "pool", pool.Name, "daemonSet", ds.Name)
+		return ds, nil
+	}
+	if !metav1.IsControlledBy(ds, pool) {
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "ImagePrePullConflict",
+			"DaemonSet %s exists and is not controlled by the pool, replacing pods without pre-pulling images", ds.Name)
+		return nil, nil
+	}
+	if equality.Semantic.DeepDerivative(template, ds.Spec.Template) {
+		return ds, nil
+	}
+	ds.Spec.Template = template
+	if err := r.Update(ctx, ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// deletePrePullDaemonSet deletes the pre-pull DaemonSet of the pool, if there is one.
+func (r *PoolReconciler) deletePrePullDaemonSet(ctx context.Context, pool *sandboxv1alpha1.Pool) error {
+	ds := &appsv1.DaemonSet{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: pool.Namespace, Name: prePullDaemonSetName(pool)}, ds); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(ds, pool) {
+		return nil
+	}
+	if err := r.Delete(ctx, ds, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logf.FromContext(ctx).Info("Deleted image pre-pull DaemonSet", "pool", pool.Name, "daemonSet", ds.Name)
+	return nil
+}
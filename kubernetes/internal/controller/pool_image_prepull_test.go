@@ -0,0 +1,198 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func prePullTestPod(name, revision string, images ...string) *corev1.Pod {
+	pod := pluginTestPod(name, "node-1", map[string]string{LabelPoolRevision: revision})
+	for _, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: image, Image: image})
+	}
+	return pod
+}
+
+func TestImagesToPrePull(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "init:v2"}},
+		Containers:     []corev1.Container{{Name: "main", Image: "main:v2"}, {Name: "sidecar", Image: "sidecar:v1"}},
+	}}
+
+	assert.Nil(t, imagesToPrePull(template, "new", nil))
+	assert.Nil(t, imagesToPrePull(template, "new", []*corev1.Pod{prePullTestPod("a", "new", "main:v2")}),
+		"pods of the latest revision are not replaced")
+	assert.Equal(t, []string{"init:v2", "main:v2"},
+		imagesToPrePull(template, "new", []*corev1.Pod{prePullTestPod("a", "old", "main:v1", "sidecar:v1")}),
+		"images the replaced pods run are on their nodes already")
+	assert.Empty(t, imagesToPrePull(template, "new", []*corev1.Pod{prePullTestPod("a", "old", "init:v2", "main:v2", "sidecar:v1")}))
+}
+
+func TestPrePullPodTemplate(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	nodeAffinity := &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{}}
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		NodeSelector:     map[string]string{"pool": "sandbox"},
+		Tolerations:      []corev1.Toleration{{Key: "sandbox", Operator: corev1.TolerationOpExists}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		Affinity: &corev1.Affinity{
+			NodeAffinity:    nodeAffinity,
+			PodAntiAffinity: &corev1.PodAntiAffinity{},
+		},
+	}}
+
+	got := prePullPodTemplate(pool, template, "rev", []string{"main:v2", "init:v2"})
+	assert.Equal(t, map[string]string{LabelPoolPrePull: "pool", LabelPoolRevision: "rev"}, got.Labels)
+	assert.NotContains(t, got.Labels, LabelPoolName, "pre-pull pods are not pool pods")
+	assert.Equal(t, template.Spec.NodeSelector, got.Spec.NodeSelector)
+	assert.Equal(t, template.Spec.Tolerations, got.Spec.Tolerations)
+	assert.Equal(t, template.Spec.ImagePullSecrets, got.Spec.ImagePullSecrets)
+	assert.Equal(t, &corev1.Affinity{NodeAffinity: nodeAffinity}, got.Spec.Affinity, "only node affinity applies")
+	require.Len(t, got.Spec.Containers, 2)
+	assert.Equal(t, "prepull-0", got.Spec.Containers[0].Name)
+	assert.Equal(t, "main:v2", got.Spec.Containers[0].Image)
+	assert.Equal(t, corev1.PullIfNotPresent, got.Spec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, "init:v2", got.Spec.Containers[1].Image)
+}
+
+func TestSyncImagePrePull(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"},
+		Spec: sandboxv1alpha1.PoolSpec{UpdateStrategy: &sandboxv1alpha1.UpdateStrategy{
+			PrePull: &sandboxv1alpha1.ImagePrePull{TimeoutSeconds: ptr.To(int32(60))},
+		}},
+	}
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "main:v2"}}}}
+	pods := []*corev1.Pod{prePullTestPod("old", "v1", "main:v1")}
+	c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: recorder}
+	key := client.ObjectKey{Namespace: "default", Name: "pool-prepull"}
+
+	status, after, err := r.syncImagePrePull(ctx, pool, template, "v2", pods, start)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, sandboxv1alpha1.ImagePrePullPhasePulling, status.Phase)
+	assert.Equal(t, []string{"main:v2"}, status.Images)
+	assert.Equal(t, prePullPollInterval, after)
+	ds := &appsv1.DaemonSet{}
+	require.NoError(t, c.Get(ctx, key, ds))
+	assert.True(t, metav1.IsControlledBy(ds, pool))
+	assert.Equal(t, "main:v2", ds.Spec.Template.Spec.Containers[0].Image)
+
+	// Two nodes run the DaemonSet, one of which has pulled the image.
+	ds.Status = appsv1.DaemonSetStatus{ObservedGeneration: max(ds.Generation, 1), DesiredNumberScheduled: 2}
+	require.NoError(t, c.Status().Update(ctx, ds))
+	pulled := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-prepull-a", Namespace: "default", Labels: ds.Spec.Template.Labels},
+		Spec:       ds.Spec.Template.Spec,
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "prepull-0", ImageID: "sha256:abc"}}},
+	}
+	pulling := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-prepull-b", Namespace: "default", Labels: ds.Spec.Template.Labels},
+		Spec:       ds.Spec.Template.Spec,
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "prepull-0"}}},
+	}
+	for _, pod := range []*corev1.Pod{pulled, pulling} {
+		podStatus := pod.Status
+		require.NoError(t, c.Create(ctx, pod))
+		pod.Status = podStatus
+		require.NoError(t, c.Status().Update(ctx, pod))
+	}
+	pool.Status.PrePull = status
+
+	status, after, err = r.syncImagePrePull(ctx, pool, template, "v2", pods, start.Add(58*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, sandboxv1alpha1.ImagePrePullPhasePulling, status.Phase)
+	assert.Equal(t, int32(2), status.Nodes)
+	assert.Equal(t, int32(1), status.Pulled)
+	assert.Equal(t, 2*time.Second, after, "the next check is at the timeout")
+
+	pulling.Status.ContainerStatuses[0].ImageID = "sha256:abc"
+	require.NoError(t, c.Status().Update(ctx, pulling))
+	pool.Status.PrePull = status
+	status, _, err = r.syncImagePrePull(ctx, pool, template, "v2", pods, start.Add(59*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, sandboxv1alpha1.ImagePrePullPhaseComplete, status.Phase)
+	assert.Equal(t, int32(2), status.Pulled)
+	assert.True(t, errors.IsNotFound(c.Get(ctx, key, ds)), "the DaemonSet is deleted once the images are pulled")
+
+	// A completed pre-pull is kept, without pulling again.
+	pool.Status.PrePull = status
+	status, after, err = r.syncImagePrePull(ctx, pool, template, "v2", pods, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, sandboxv1alpha1.ImagePrePullPhaseComplete, status.Phase)
+	assert.Zero(t, after)
+	assert.True(t, errors.IsNotFound(c.Get(ctx, key, ds)))
+}
+
+func TestSyncImagePrePull_TimesOut(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"},
+		Spec: sandboxv1alpha1.PoolSpec{UpdateStrategy: &sandboxv1alpha1.UpdateStrategy{
+			PrePull: &sandboxv1alpha1.ImagePrePull{},
+		}},
+	}
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "main:v2"}}}}
+	pods := []*corev1.Pod{prePullTestPod("old", "v1", "main:v1")}
+	c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: recorder}
+
+	status, _, err := r.syncImagePrePull(ctx, pool, template, "v2", pods, start)
+	require.NoError(t, err)
+	pool.Status.PrePull = status
+	status, _, err = r.syncImagePrePull(ctx, pool, template, "v2", pods, start.Add(defaultPrePullTimeout))
+	require.NoError(t, err)
+	assert.Equal(t, sandboxv1alpha1.ImagePrePullPhaseTimedOut, status.Phase)
+	assert.True(t, errors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pool-prepull"}, &appsv1.DaemonSet{})))
+	assert.Contains(t, <-recorder.Events, "PrePullingImages")
+	assert.Contains(t, <-recorder.Events, "ImagePrePullTimedOut")
+
+	// A new revision pulls again.
+	pool.Status.PrePull = status
+	template.Spec.Containers[0].Image = "main:v3"
+	status, _, err = r.syncImagePrePull(ctx, pool, template, "v3", pods, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, sandboxv1alpha1.ImagePrePullPhasePulling, status.Phase)
+	assert.Equal(t, "v3", status.Revision)
+
+	// Turning pre-pull off deletes the DaemonSet and drops the status.
+	pool.Spec.UpdateStrategy.PrePull = nil
+	status, _, err = r.syncImagePrePull(ctx, pool, template, "v3", pods, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, status)
+	assert.True(t, errors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pool-prepull"}, &appsv1.DaemonSet{})))
+}
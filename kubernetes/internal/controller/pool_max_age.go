@@ -0,0 +1,100 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// AgeResult is the outcome of checking the idle pods of a pool against its maxPodAge.
+type AgeResult struct {
+	// IdlePods are the idle pods that stay.
+	IdlePods []string
+	// ToDeletePods are the idle pods past maxPodAge to recreate.
+	ToDeletePods []string
+	// RequeueAfter is when the next idle pod reaches maxPodAge, zero if none will.
+	RequeueAfter time.Duration
+}
+
+// expireAgedPods picks the idle pods older than maxPodAge for recreation, oldest first. Unavailable
+// pods are always picked; available ones only within the update maxUnavailable budget that is left
+// after the unavailable pods and the pods already being replaced, so the buffer never drains.
+func expireAgedPods(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, idlePods []string, replacing int, now time.Time) *AgeResult {
+	result := &AgeResult{IdlePods: idlePods}
+	if pool.Spec.MaxPodAge == nil || pool.Spec.MaxPodAge.Duration <= 0 {
+		return result
+	}
+	maxAge := pool.Spec.MaxPodAge.Duration
+
+	podMap := make(map[string]*corev1.Pod, len(pods))
+	unavailable := int32(replacing)
+	for _, pod := range pods {
+		podMap[pod.Name] = pod
+		if !isPodAvailable(pool, pod) {
+			unavailable++
+		}
+	}
+	budget := max(getUpdateMaxUnavailable(pool, int32(len(pods)))-unavailable, 0)
+
+	result.IdlePods = make([]string, 0, len(idlePods))
+	idle := make([]*corev1.Pod, 0, len(idlePods))
+	for _, name := range idlePods {
+		if pod, ok := podMap[name]; ok {
+			idle = append(idle, pod)
+		} else {
+			result.IdlePods = append(result.IdlePods, name)
+		}
+	}
+	sort.SliceStable(idle, func(i, j int) bool {
+		return idle[i].CreationTimestamp.Before(&idle[j].CreationTimestamp)
+	})
+
+	for _, pod := range idle {
+		expiresIn := pod.CreationTimestamp.Add(maxAge).Sub(now)
+		switch {
+		case expiresIn > 0:
+			if result.RequeueAfter == 0 || expiresIn < result.RequeueAfter {
+				result.RequeueAfter = expiresIn
+			}
+		case pod.DeletionTimestamp != nil:
+		case !isPodAvailable(pool, pod):
+			result.ToDeletePods = append(result.ToDeletePods, pod.Name)
+			continue
+		case budget > 0:
+			budget--
+			result.ToDeletePods = append(result.ToDeletePods, pod.Name)
+			continue
+		default:
+			// Retried once the replacements of the pods recreated now are ready.
+			if result.RequeueAfter == 0 || defaultRetryTime < result.RequeueAfter {
+				result.RequeueAfter = defaultRetryTime
+			}
+		}
+		result.IdlePods = append(result.IdlePods, pod.Name)
+	}
+
+	if len(result.ToDeletePods) > 0 {
+		logf.FromContext(ctx).Info("Recreating idle pods past max age", "pool", pool.Name,
+			"maxPodAge", maxAge, "toDeletePods", result.ToDeletePods, "idlePods", len(result.IdlePods))
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestExpireAgedPods(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	newPod := func(name string, age time.Duration, ready bool) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))}}
+		if ready {
+			pod.Status = corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+		}
+		return pod
+	}
+	pods := []*corev1.Pod{
+		newPod("fresh", time.Hour, true),
+		newPod("old", 3*time.Hour, true),
+		newPod("older", 4*time.Hour, true),
+		newPod("old-broken", 3*time.Hour, false),
+		newPod("allocated", 5*time.Hour, true),
+	}
+	idle := []string{"fresh", "old", "older", "old-broken"}
+	pool := &sandboxv1alpha1.Pool{}
+
+	result := expireAgedPods(ctx, pool, pods, idle, 0, now)
+	assert.Equal(t, idle, result.IdlePods, "nothing expires without maxPodAge")
+	assert.Empty(t, result.ToDeletePods)
+
+	pool.Spec.MaxPodAge = &metav1.Duration{Duration: 2 * time.Hour}
+	maxUnavailable := intstr.FromInt32(2)
+	pool.Spec.UpdateStrategy = &sandboxv1alpha1.UpdateStrategy{MaxUnavailable: &maxUnavailable}
+	result = expireAgedPods(ctx, pool, pods, idle, 0, now)
+	assert.Equal(t, []string{"older", "old-broken"}, result.ToDeletePods, "the broken pod uses up the budget, the oldest ready pod goes first")
+	assert.Equal(t, []string{"old", "fresh"}, result.IdlePods)
+	assert.Equal(t, defaultRetryTime, result.RequeueAfter)
+
+	// With budget to spare the requeue follows the next pod to expire.
+	maxUnavailable = intstr.FromInt32(4)
+	result = expireAgedPods(ctx, pool, pods, idle, 0, now)
+	assert.ElementsMatch(t, []string{"older", "old", "old-broken"}, result.ToDeletePods)
+	assert.Equal(t, []string{"fresh"}, result.IdlePods)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+
+	// Pods already replaced by an update take from the same budget.
+	result = expireAgedPods(ctx, pool, pods, idle, 3, now)
+	assert.Equal(t, []string{"old-broken"}, result.ToDeletePods)
+}
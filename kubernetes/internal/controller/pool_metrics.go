@@ -0,0 +1,280 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+const (
+	// podStartupStageRunning is reached when all containers of the pod have started.
+	podStartupStageRunning = "running"
+	// podStartupStageReady is reached when the pod becomes Ready.
+	podStartupStageReady = "ready"
+
+	// allocationFailureSchedule is counted when the allocator fails to compute the allocation of a pool.
+	allocationFailureSchedule = "ScheduleFailed"
+	// allocationFailureSync is counted when allocated or released pods can't be recorded on a sandbox.
+	allocationFailureSync = "SyncFailed"
+)
+
+var (
+	poolPodStartupSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "opensandbox_pool_pod_startup_seconds",
+		Help:    "Time from pool pod creation until the pod reaches the given startup stage.",
+		Buckets: []float64{1, 2, 5, 10, 15, 20, 30, 45, 60, 90, 120, 180, 300, 600},
+	}, []string{"namespace", "pool", "stage"})
+
+	poolPods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opensandbox_pool_pods",
+		Help: "Number of pods of the pool, as in status.total.",
+	}, []string{"namespace", "pool"})
+	poolAvailablePods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opensandbox_pool_available_pods",
+		Help: "Number of idle pods of the pool ready to be allocated, as in status.available.",
+	}, []string{"namespace", "pool"})
+	poolAllocatedPods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opensandbox_pool_allocated_pods",
+		Help: "Number of pods of the pool allocated to sandboxes, as in status.allocated.",
+	}, []string{"namespace", "pool"})
+	poolPodSupplement = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opensandbox_pool_pod_supplement",
+		Help: "Number of pods sandboxes wait for that the pool has yet to provide.",
+	}, []string{"namespace", "pool"})
+	poolUpgradedPods = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opensandbox_pool_upgraded_pods_total",
+		Help: "Number of idle pool pods deleted to be recreated from the latest pod template.",
+	}, []string{"namespace", "pool"})
+	poolAllocationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opensandbox_pool_allocation_failures_total",
+		Help: "Number of failed allocation attempts of the pool and of sandboxes that gave up waiting for its pods, by reason.",
+	}, []string{"namespace", "pool", "reason"})
+	sandboxAllocationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "opensandbox_sandbox_allocation_seconds",
+		Help:    "Time from BatchSandbox creation until all of its replicas are allocated from the pool.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 15, 30, 60, 120, 300, 600},
+	}, []string{"namespace", "pool"})
+
+	poolPodStartup        = newPodStartupTracker(time.Now())
+	poolSandboxAllocation = newSandboxAllocationTracker(time.Now())
+)
+
+func init() {
+	metrics.Registry.MustRegister(poolPodStartupSeconds, poolPods, poolAvailablePods, poolAllocatedPods, poolPodSupplement,
+		poolUpgradedPods, poolAllocationFailures, sandboxAllocationSeconds)
+}
+
+// observePoolStatus exports the pod counts of the pool status.
+func observePoolStatus(pool *sandboxv1alpha1.Pool) {
+	poolPods.WithLabelValues(pool.Namespace, pool.Name).Set(float64(pool.Status.Total))
+	poolAvailablePods.WithLabelValues(pool.Namespace, pool.Name).Set(float64(pool.Status.Available))
+	poolAllocatedPods.WithLabelValues(pool.Namespace, pool.Name).Set(float64(pool.Status.Allocated))
+}
+
+// forgetPoolMetrics drops the exported series of a deleted pool.
+func forgetPoolMetrics(namespace, pool string) {
+	labels := prometheus.Labels{"namespace": namespace, "pool": pool}
+	for _, vec := range []interface{ DeletePartialMatch(prometheus.Labels) int }{
+		poolPods, poolAvailablePods, poolAllocatedPods, poolPodSupplement, poolUpgradedPods, poolAllocationFailures,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+}
+
+// sandboxAllocationTracker observes the allocation latency of each sandbox of a pool exactly once.
+type sandboxAllocationTracker struct {
+	mu sync.Mutex
+	// since skips sandboxes created before the controller started, which may have been allocated long ago.
+	since time.Time
+	// observed is poolKey -> UIDs of the sandboxes waiting for or done with their allocation.
+	observed map[string]map[types.UID]bool
+}
+
+func newSandboxAllocationTracker(since time.Time) *sandboxAllocationTracker {
+	return &sandboxAllocationTracker{
+		since:    since,
+		observed: make(map[string]map[types.UID]bool),
+	}
+}
+
+// Observe records the allocation latency of the sandboxes that now hold all of their replicas, given the
+// pod -> sandbox allocation of the pool, and forgets sandboxes that are no longer part of it.
+func (t *sandboxAllocationTracker) Observe(namespace, pool string, sandboxes []*sandboxv1alpha1.BatchSandbox, allocation map[string]string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	allocated := make(map[string]int32, len(sandboxes))
+	for _, sandbox := range allocation {
+		allocated[sandbox]++
+	}
+	key := namespace + "/" + pool
+	prev := t.observed[key]
+	current := make(map[types.UID]bool, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		if sandbox.CreationTimestamp.Time.Before(t.since) || sandbox.Spec.Replicas == nil {
+			continue
+		}
+		done := prev[sandbox.UID]
+		if !done && allocated[sandbox.Name] >= *sandbox.Spec.Replicas {
+			done = true
+			sandboxAllocationSeconds.WithLabelValues(namespace, pool).Observe(now.Sub(sandbox.CreationTimestamp.Time).Seconds())
+		}
+		current[sandbox.UID] = done
+	}
+	t.observed[key] = current
+}
+
+// Forget drops the tracked sandboxes and exported series of a deleted pool.
+func (t *sandboxAllocationTracker) Forget(namespace, pool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.observed, namespace+"/"+pool)
+	sandboxAllocationSeconds.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "pool": pool})
+}
+
+// podStartupTracker observes each startup stage of a pool pod exactly once.
+type podStartupTracker struct {
+	mu sync.Mutex
+	// since skips pods created before the controller started, whose startup has been observed already
+	// or can't be attributed to the current buffer settings.
+	since time.Time
+	// observed is poolKey -> pod UID -> observed stages.
+	observed map[string]map[types.UID]map[string]struct{}
+}
+
+func newPodStartupTracker(since time.Time) *podStartupTracker {
+	return &podStartupTracker{
+		since:    since,
+		observed: make(map[string]map[types.UID]map[string]struct{}),
+	}
+}
+
+// Observe records the startup stages newly reached by the given pods of a pool and forgets pods
+// that are no longer part of it.
+func (t *podStartupTracker) Observe(namespace, pool string, pods []*corev1.Pod) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := namespace + "/" + pool
+	prev := t.observed[key]
+	current := make(map[types.UID]map[string]struct{}, len(pods))
+	for _, pod := range pods {
+		if pod.CreationTimestamp.Time.Before(t.since) {
+			continue
+		}
+		stages := prev[pod.UID]
+		if stages == nil {
+			stages = make(map[string]struct{}, 2)
+		}
+		current[pod.UID] = stages
+		for stage, reachedAt := range podStartupStages(pod) {
+			if _, ok := stages[stage]; ok {
+				continue
+			}
+			stages[stage] = struct{}{}
+			poolPodStartupSeconds.WithLabelValues(namespace, pool, stage).
+				Observe(reachedAt.Sub(pod.CreationTimestamp.Time).Seconds())
+		}
+	}
+	t.observed[key] = current
+}
+
+// Forget drops the tracked pods and exported series of a deleted pool.
+func (t *podStartupTracker) Forget(namespace, pool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.observed, namespace+"/"+pool)
+	poolPodStartupSeconds.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "pool": pool})
+}
+
+// podStartupQuantile estimates the q-quantile of the startup latency of a pool up to the given stage from
+// the exported histogram, interpolating linearly within buckets like histogram_quantile does. It reports
+// false if no pod of the pool has reached the stage since the controller started.
+func podStartupQuantile(namespace, pool, stage string, q float64) (time.Duration, bool) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		poolPodStartupSeconds.Collect(ch)
+		close(ch)
+	}()
+	var hist *dto.Histogram
+	for metric := range ch {
+		m := &dto.Metric{}
+		if hist != nil || metric.Write(m) != nil {
+			continue
+		}
+		labels := make(map[string]string, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["namespace"] == namespace && labels["pool"] == pool && labels["stage"] == stage {
+			hist = m.GetHistogram()
+		}
+	}
+	if hist.GetSampleCount() == 0 {
+		return 0, false
+	}
+
+	rank := q * float64(hist.GetSampleCount())
+	lower, below := 0.0, 0.0
+	for _, b := range hist.GetBucket() {
+		upper, count := b.GetUpperBound(), float64(b.GetCumulativeCount())
+		if count >= rank {
+			seconds := upper
+			if count > below {
+				seconds = lower + (upper-lower)*(rank-below)/(count-below)
+			}
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		lower, below = upper, count
+	}
+	// the quantile falls into the +Inf bucket, the highest finite bound is the best estimate
+	return time.Duration(lower * float64(time.Second)), true
+}
+
+// podStartupStages returns the time at which each startup stage was reached by the pod.
+func podStartupStages(pod *corev1.Pod) map[string]time.Time {
+	stages := make(map[string]time.Time, 2)
+	if pod.Status.Phase == corev1.PodRunning && len(pod.Status.ContainerStatuses) > 0 {
+		var startedAt time.Time
+		allStarted := true
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Running == nil {
+				allStarted = false
+				break
+			}
+			if cs.State.Running.StartedAt.After(startedAt) {
+				startedAt = cs.State.Running.StartedAt.Time
+			}
+		}
+		if allStarted {
+			stages[podStartupStageRunning] = startedAt
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			stages[podStartupStageReady] = cond.LastTransitionTime.Time
+		}
+	}
+	return stages
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func startupHistogram(t *testing.T, namespace, pool, stage string) *dto.Histogram {
+	m := &dto.Metric{}
+	observer := poolPodStartupSeconds.WithLabelValues(namespace, pool, stage)
+	assert.NoError(t, observer.(prometheus.Histogram).Write(m))
+	return m.GetHistogram()
+}
+
+func TestPodStartupTracker(t *testing.T) {
+	created := time.Now().Truncate(time.Second)
+	newPod := func(uid string, createdAt time.Time) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              uid,
+			UID:               types.UID(uid),
+			CreationTimestamp: metav1.NewTime(createdAt),
+		}}
+	}
+	markRunning := func(pod *corev1.Pod, after time.Duration) {
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{
+				StartedAt: metav1.NewTime(pod.CreationTimestamp.Add(after)),
+			}},
+		}}
+	}
+	markReady := func(pod *corev1.Pod, after time.Duration) {
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(pod.CreationTimestamp.Add(after)),
+		}}
+	}
+
+	tracker := newPodStartupTracker(created.Add(-time.Minute))
+	pending := newPod("pod-1", created)
+	stale := newPod("pod-2", created.Add(-time.Hour))
+	markRunning(stale, time.Second)
+	markReady(stale, time.Second)
+
+	tracker.Observe("ns", "metrics-pool", []*corev1.Pod{pending, stale})
+	assert.Equal(t, uint64(0), startupHistogram(t, "ns", "metrics-pool", podStartupStageRunning).GetSampleCount())
+
+	markRunning(pending, 4*time.Second)
+	tracker.Observe("ns", "metrics-pool", []*corev1.Pod{pending, stale})
+	markReady(pending, 6*time.Second)
+	tracker.Observe("ns", "metrics-pool", []*corev1.Pod{pending, stale})
+	tracker.Observe("ns", "metrics-pool", []*corev1.Pod{pending, stale})
+
+	running := startupHistogram(t, "ns", "metrics-pool", podStartupStageRunning)
+	assert.Equal(t, uint64(1), running.GetSampleCount())
+	assert.Equal(t, 4.0, running.GetSampleSum())
+	ready := startupHistogram(t, "ns", "metrics-pool", podStartupStageReady)
+	assert.Equal(t, uint64(1), ready.GetSampleCount())
+	assert.Equal(t, 6.0, ready.GetSampleSum())
+
+	tracker.Observe("ns", "metrics-pool", nil)
+	assert.Empty(t, tracker.observed["ns/metrics-pool"])
+
+	tracker.Forget("ns", "metrics-pool")
+	assert.NotContains(t, tracker.observed, "ns/metrics-pool")
+	assert.Equal(t, uint64(0), startupHistogram(t, "ns", "metrics-pool", podStartupStageReady).GetSampleCount())
+}
+
+func allocationHistogram(t *testing.T, namespace, pool string) *dto.Histogram {
+	m := &dto.Metric{}
+	observer := sandboxAllocationSeconds.WithLabelValues(namespace, pool)
+	assert.NoError(t, observer.(prometheus.Histogram).Write(m))
+	return m.GetHistogram()
+}
+
+func TestSandboxAllocationTracker(t *testing.T) {
+	created := time.Now().Truncate(time.Second)
+	newSandbox := func(name string, createdAt time.Time, replicas int32) *sandboxv1alpha1.BatchSandbox {
+		return &sandboxv1alpha1.BatchSandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name), CreationTimestamp: metav1.NewTime(createdAt)},
+			Spec:       sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To(replicas)},
+		}
+	}
+
+	tracker := newSandboxAllocationTracker(created.Add(-time.Minute))
+	waiting := newSandbox("sbx-1", created, 2)
+	stale := newSandbox("sbx-2", created.Add(-time.Hour), 1)
+	sandboxes := []*sandboxv1alpha1.BatchSandbox{waiting, stale}
+
+	tracker.Observe("ns", "alloc-pool", sandboxes, map[string]string{"pod-1": "sbx-1", "pod-2": "sbx-2"}, created.Add(time.Second))
+	assert.Equal(t, uint64(0), allocationHistogram(t, "ns", "alloc-pool").GetSampleCount())
+
+	allocation := map[string]string{"pod-1": "sbx-1", "pod-2": "sbx-2", "pod-3": "sbx-1"}
+	tracker.Observe("ns", "alloc-pool", sandboxes, allocation, created.Add(3*time.Second))
+	tracker.Observe("ns", "alloc-pool", sandboxes, allocation, created.Add(5*time.Second))
+	hist := allocationHistogram(t, "ns", "alloc-pool")
+	assert.Equal(t, uint64(1), hist.GetSampleCount(), "each sandbox is observed once")
+	assert.Equal(t, 3.0, hist.GetSampleSum())
+
+	tracker.Observe("ns", "alloc-pool", nil, nil, created.Add(6*time.Second))
+	assert.Empty(t, tracker.observed["ns/alloc-pool"])
+
+	tracker.Forget("ns", "alloc-pool")
+	assert.NotContains(t, tracker.observed, "ns/alloc-pool")
+	assert.Equal(t, uint64(0), allocationHistogram(t, "ns", "alloc-pool").GetSampleCount())
+}
+
+func TestPoolStatusMetrics(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "status-pool", Namespace: "ns"},
+		Status:     sandboxv1alpha1.PoolStatus{Total: 5, Allocated: 3, Available: 1},
+	}
+	observePoolStatus(pool)
+	assert.Equal(t, 5.0, testutil.ToFloat64(poolPods.WithLabelValues("ns", "status-pool")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(poolAllocatedPods.WithLabelValues("ns", "status-pool")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(poolAvailablePods.WithLabelValues("ns", "status-pool")))
+
+	forgetPoolMetrics("ns", "status-pool")
+	assert.Zero(t, poolPods.DeletePartialMatch(prometheus.Labels{"namespace": "ns", "pool": "status-pool"}), "the series are dropped")
+}
+
+func TestCountAllocationFailure(t *testing.T) {
+	failure := poolAllocationFailures.WithLabelValues("ns", "failing-pool", "AllocationTimeout")
+	sandbox := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "ns"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{PoolRef: "failing-pool"},
+	}
+	status := sandbox.Status.DeepCopy()
+	countAllocationFailure(sandbox, status)
+	assert.Equal(t, 0.0, testutil.ToFloat64(failure))
+
+	setConditionInStatus(status, sandboxv1alpha1.BatchSandboxConditionPoolExhausted, sandboxv1alpha1.ConditionTrue, "AllocationTimeout", "")
+	countAllocationFailure(sandbox, status)
+	assert.Equal(t, 1.0, testutil.ToFloat64(failure))
+
+	sandbox.Status = *status
+	countAllocationFailure(sandbox, status)
+	assert.Equal(t, 1.0, testutil.ToFloat64(failure), "a sandbox that gave up already is not counted again")
+	forgetPoolMetrics("ns", "failing-pool")
+}
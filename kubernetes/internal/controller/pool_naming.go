@@ -0,0 +1,85 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// LabelPoolOrdinal carries the ordinal of a pod created with the Ordinal naming strategy.
+const LabelPoolOrdinal = "sandbox.opensandbox.io/pool-ordinal"
+
+// podNamer names the pods a pool creates. It is safe for concurrent use.
+type podNamer interface {
+	// Name sets the name of a new pod.
+	Name(pod *corev1.Pod)
+	// Retry reports whether a pod whose name already exists can be named again. The name set by
+	// the failed Name call is not handed out again.
+	Retry() bool
+}
+
+// newPodNamer returns the namer of the pool strategy. pods are the pods the pool has now.
+func newPodNamer(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod) podNamer {
+	if pool.Spec.PodNamingStrategy != sandboxv1alpha1.PodNamingStrategyOrdinal {
+		return generateNamer{prefix: pool.Name + "-"}
+	}
+	used := make(map[int]bool, len(pods))
+	for _, pod := range pods {
+		if ordinal, err := strconv.Atoi(pod.Labels[LabelPoolOrdinal]); err == nil && ordinal >= 0 {
+			used[ordinal] = true
+		}
+	}
+	return &ordinalNamer{pool: pool.Name, used: used}
+}
+
+// generateNamer leaves the name to the API server.
+type generateNamer struct {
+	prefix string
+}
+
+func (n generateNamer) Name(pod *corev1.Pod) {
+	pod.Name = ""
+	pod.GenerateName = n.prefix
+}
+
+func (generateNamer) Retry() bool { return false }
+
+// ordinalNamer hands out the lowest ordinals no pod uses. A terminating pod that is no longer listed
+// still holds its name; creating over it fails with AlreadyExists and the next ordinal is tried.
+type ordinalNamer struct {
+	pool string
+	mu   sync.Mutex
+	used map[int]bool
+}
+
+func (n *ordinalNamer) Name(pod *corev1.Pod) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ordinal := 0
+	for n.used[ordinal] {
+		ordinal++
+	}
+	n.used[ordinal] = true
+	pod.GenerateName = ""
+	pod.Name = n.pool + "-" + strconv.Itoa(ordinal)
+	pod.Labels[LabelPoolOrdinal] = strconv.Itoa(ordinal)
+}
+
+func (*ordinalNamer) Retry() bool { return true }
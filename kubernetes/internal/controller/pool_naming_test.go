@@ -0,0 +1,82 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func TestNewPodNamer(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}}}
+	namer := newPodNamer(pool, nil)
+	namer.Name(pod)
+	assert.Equal(t, "pool-", pod.GenerateName)
+	assert.Empty(t, pod.Name)
+	assert.False(t, namer.Retry())
+
+	pool.Spec.PodNamingStrategy = sandboxv1alpha1.PodNamingStrategyOrdinal
+	existing := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pool-0", Labels: map[string]string{LabelPoolOrdinal: "0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pool-2", Labels: map[string]string{LabelPoolOrdinal: "2"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pool-x7k2p"}},
+	}
+	namer = newPodNamer(pool, existing)
+	var names []string
+	for range 3 {
+		namer.Name(pod)
+		names = append(names, pod.Name)
+		assert.Empty(t, pod.GenerateName)
+		assert.Equal(t, pod.Name, "pool-"+pod.Labels[LabelPoolOrdinal])
+	}
+	assert.Equal(t, []string{"pool-1", "pool-3", "pool-4"}, names, "freed ordinals are reused first")
+}
+
+func TestCreatePoolPodOrdinal(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "ordinal-pool", Namespace: "default", UID: "pool-uid"},
+		Spec:       sandboxv1alpha1.PoolSpec{PodNamingStrategy: sandboxv1alpha1.PodNamingStrategyOrdinal},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+	// A terminating pod that is no longer listed still holds ordinal 0.
+	terminating := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "ordinal-pool-0", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(terminating).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+
+	namer := newPodNamer(pool, nil)
+	require.NoError(t, r.createPoolPod(ctx, pool, template, "rev", namer))
+	require.NoError(t, r.createPoolPod(ctx, pool, template, "rev", namer))
+
+	for _, name := range []string{"ordinal-pool-1", "ordinal-pool-2"} {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+		assert.Equal(t, name[len("ordinal-pool-"):], pod.Labels[LabelPoolOrdinal])
+		assert.Equal(t, pool.Name, pod.Labels[LabelPoolName])
+	}
+}
@@ -0,0 +1,507 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+const (
+	// LabelManagerMember marks the member Lease of a manager replica taking part in pool ownership.
+	LabelManagerMember = "sandbox.opensandbox.io/manager-member"
+
+	poolLeasePrefix   = "opensandbox-pool-"
+	memberLeasePrefix = "opensandbox-manager-"
+
+	DefaultPoolLeaseDuration = 15 * time.Second
+
+	releasePollInterval = 100 * time.Millisecond
+)
+
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+
+// PoolOwnership splits Pools between manager replicas with one coordination Lease per Pool, so that several
+// replicas can reconcile Pools concurrently instead of a single leader. Every replica heartbeats a member
+// Lease; each Pool is preferred by one live member picked by rendezvous hashing, which acquires the Pool
+// Lease once it is free or expired. Pools of a failed replica are therefore taken over one LeaseDuration
+// after its last renewal, and a replica hands over Pools preferred by a newly joined member.
+type PoolOwnership struct {
+	// Client writes Leases.
+	Client client.Client
+	// Reader reads Leases; use an uncached reader so that the manager doesn't watch every Lease in the cluster.
+	Reader client.Reader
+	// Identity uniquely identifies this replica.
+	Identity string
+	// Namespace holds the member Leases.
+	Namespace string
+	// LeaseDuration is how long a Lease stays valid without renewal. Leases are renewed every third of it.
+	LeaseDuration time.Duration
+
+	now func() time.Time
+
+	mu sync.Mutex
+	// members are the identities of the live replicas, including this one.
+	members        []string
+	membersFetched bool
+	// owned maps the Pool key to the last renewal of its Lease by this replica.
+	owned map[string]time.Time
+	// waiting maps the Pool key to when this replica first saw its Lease takeable while preferred by another
+	// member; after a LeaseDuration the Pool is taken anyway so it can't be stranded by a stuck member.
+	waiting map[string]time.Time
+	// reconciling counts the reconciles of each Pool between Acquire and Done.
+	reconciling map[string]int
+	// handingOver holds the owned Pools preferred by another member. They are not reconciled any more, and their
+	// Lease is released once their in-flight reconciles are done.
+	handingOver map[string]bool
+}
+
+var _ manager.Runnable = &PoolOwnership{}
+var _ manager.LeaderElectionRunnable = &PoolOwnership{}
+
+// NeedLeaderElection returns false: ownership replaces leader election for the Pool controller.
+func (o *PoolOwnership) NeedLeaderElection() bool {
+	return false
+}
+
+// Start heartbeats the member Lease and renews the owned Pool Leases until the context is cancelled, then
+// releases all of them so that the other replicas take over without waiting for expiry.
+func (o *PoolOwnership) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("pool-ownership")
+	ticker := time.NewTicker(o.renewInterval())
+	defer ticker.Stop()
+	for {
+		if err := o.heartbeat(ctx); err != nil {
+			log.Error(err, "Failed to renew pool ownership leases")
+		}
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), o.renewInterval())
+			defer cancel()
+			o.releaseAll(releaseCtx)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Acquire reports whether this replica owns the Pool and may reconcile it. acquired is true when the Pool
+// has just been taken over, in which case allocations made by the previous owner must be reloaded. If the
+// Pool is not owned, recheckAfter is when ownership should be checked again. A reconcile of an owned Pool must
+// call Done when it ends, the Pool is not handed over before.
+func (o *PoolOwnership) Acquire(ctx context.Context, pool *sandboxv1alpha1.Pool) (owned, acquired bool, recheckAfter time.Duration, err error) {
+	if err := o.ensureMembers(ctx); err != nil {
+		return false, false, 0, err
+	}
+	key := client.ObjectKeyFromObject(pool).String()
+	if !o.begin(key) {
+		return false, false, o.renewInterval(), nil
+	}
+	defer func() {
+		if !owned {
+			o.end(key)
+		}
+	}()
+	now := o.clock()
+
+	o.mu.Lock()
+	renewedAt, wasOwned := o.owned[key]
+	o.mu.Unlock()
+	// Leases owned by this replica are renewed by Start, a local check is enough while the last renewal is fresh.
+	if wasOwned && now.Sub(renewedAt) < o.renewInterval() {
+		return true, false, 0, nil
+	}
+
+	lease := &coordinationv1.Lease{}
+	err = o.Reader.Get(ctx, types.NamespacedName{Namespace: pool.Namespace, Name: poolLeasePrefix + pool.Name}, lease)
+	if errors.IsNotFound(err) {
+		if !o.mayTake(key, now) {
+			return false, false, o.renewInterval(), nil
+		}
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{
+			Namespace: pool.Namespace,
+			Name:      poolLeasePrefix + pool.Name,
+			Labels:    map[string]string{LabelPoolName: pool.Name},
+		}}
+		if err := controllerutil.SetOwnerReference(pool, lease, o.Client.Scheme()); err != nil {
+			return false, false, 0, err
+		}
+		o.hold(lease, now, true)
+		if err := o.Client.Create(ctx, lease); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return false, false, o.renewInterval(), nil
+			}
+			return false, false, 0, fmt.Errorf("failed to create pool lease: %w", err)
+		}
+		o.markOwned(key, now)
+		return true, true, 0, nil
+	}
+	if err != nil {
+		return false, false, 0, fmt.Errorf("failed to get pool lease: %w", err)
+	}
+
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+	expiresAt := leaseExpiry(lease, o.LeaseDuration)
+	switch {
+	case holder == o.Identity:
+		if err := o.renew(ctx, lease, now); err != nil {
+			o.forget(key)
+			return false, false, o.renewInterval(), err
+		}
+		o.markOwned(key, now)
+		return true, !wasOwned, 0, nil
+	case holder != "" && now.Before(expiresAt):
+		o.forget(key)
+		return false, false, expiresAt.Sub(now) + time.Second, nil
+	case !o.mayTake(key, now):
+		return false, false, o.renewInterval(), nil
+	}
+
+	o.hold(lease, now, true)
+	if err := o.Client.Update(ctx, lease); err != nil {
+		if errors.IsConflict(err) {
+			return false, false, o.renewInterval(), nil
+		}
+		return false, false, 0, fmt.Errorf("failed to take over pool lease: %w", err)
+	}
+	logf.FromContext(ctx).Info("Took over pool ownership", "pool", key, "previousHolder", holder)
+	o.markOwned(key, now)
+	return true, true, 0, nil
+}
+
+// Done ends a reconcile of a Pool that Acquire reported as owned.
+func (o *PoolOwnership) Done(pool *sandboxv1alpha1.Pool) {
+	o.end(client.ObjectKeyFromObject(pool).String())
+}
+
+// begin registers a reconcile of the Pool, unless the Pool is being handed over.
+func (o *PoolOwnership) begin(key string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.handingOver[key] {
+		return false
+	}
+	if o.reconciling == nil {
+		o.reconciling = make(map[string]int)
+	}
+	o.reconciling[key]++
+	return true
+}
+
+func (o *PoolOwnership) end(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.reconciling[key]--; o.reconciling[key] <= 0 {
+		delete(o.reconciling, key)
+	}
+}
+
+// drained marks the Pool as being handed over and reports whether none of its reconciles is in flight.
+func (o *PoolOwnership) drained(key string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.handingOver == nil {
+		o.handingOver = make(map[string]bool)
+	}
+	o.handingOver[key] = true
+	return o.reconciling[key] == 0
+}
+
+// heartbeat renews the member Lease, refreshes the member list, renews owned Pool Leases and hands over
+// owned Pools that are preferred by another live member. A Pool is handed over once its in-flight reconciles
+// are done; its Lease is renewed meanwhile, so that the next owner can't reconcile it concurrently.
+func (o *PoolOwnership) heartbeat(ctx context.Context) error {
+	now := o.clock()
+	member := &coordinationv1.Lease{}
+	err := o.Reader.Get(ctx, types.NamespacedName{Namespace: o.Namespace, Name: memberLeasePrefix + o.Identity}, member)
+	switch {
+	case errors.IsNotFound(err):
+		member = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.Namespace,
+			Name:      memberLeasePrefix + o.Identity,
+			Labels:    map[string]string{LabelManagerMember: "true"},
+		}}
+		o.hold(member, now, false)
+		if err := o.Client.Create(ctx, member); err != nil {
+			return fmt.Errorf("failed to create member lease: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get member lease: %w", err)
+	default:
+		o.hold(member, now, false)
+		if err := o.Client.Update(ctx, member); err != nil {
+			return fmt.Errorf("failed to renew member lease: %w", err)
+		}
+	}
+	if err := o.refreshMembers(ctx); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	keys := make([]string, 0, len(o.owned))
+	for key := range o.owned {
+		keys = append(keys, key)
+	}
+	// Pools that are no longer checked, e.g. deleted or since held by another member, don't need to wait.
+	for key, since := range o.waiting {
+		if now.Sub(since) >= 2*o.LeaseDuration {
+			delete(o.waiting, key)
+		}
+	}
+	o.mu.Unlock()
+
+	log := logf.FromContext(ctx)
+	for _, key := range keys {
+		nn, ok := splitPoolKey(key)
+		if !ok {
+			continue
+		}
+		lease := &coordinationv1.Lease{}
+		if err := o.Reader.Get(ctx, types.NamespacedName{Namespace: nn.Namespace, Name: poolLeasePrefix + nn.Name}, lease); err != nil {
+			o.forget(key)
+			log.Error(err, "Lost pool ownership", "pool", key)
+			continue
+		}
+		if ptr.Deref(lease.Spec.HolderIdentity, "") != o.Identity {
+			o.forget(key)
+			log.Info("Lost pool ownership", "pool", key, "holder", ptr.Deref(lease.Spec.HolderIdentity, ""))
+			continue
+		}
+		if preferred := o.preferred(key); preferred != o.Identity && o.drained(key) {
+			if err := o.release(ctx, lease); err != nil {
+				log.Error(err, "Failed to hand over pool ownership", "pool", key, "to", preferred)
+			} else {
+				log.Info("Handed over pool ownership", "pool", key, "to", preferred)
+			}
+			o.forget(key)
+			continue
+		}
+		if err := o.renew(ctx, lease, now); err != nil {
+			o.forget(key)
+			log.Error(err, "Lost pool ownership", "pool", key)
+			continue
+		}
+		o.markOwned(key, now)
+		if o.preferred(key) == o.Identity {
+			o.mu.Lock()
+			delete(o.handingOver, key)
+			o.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+func (o *PoolOwnership) ensureMembers(ctx context.Context) error {
+	o.mu.Lock()
+	fetched := o.membersFetched
+	o.mu.Unlock()
+	if fetched {
+		return nil
+	}
+	return o.refreshMembers(ctx)
+}
+
+func (o *PoolOwnership) refreshMembers(ctx context.Context) error {
+	leases := &coordinationv1.LeaseList{}
+	if err := o.Reader.List(ctx, leases, client.InNamespace(o.Namespace), client.MatchingLabels{LabelManagerMember: "true"}); err != nil {
+		return fmt.Errorf("failed to list member leases: %w", err)
+	}
+	now := o.clock()
+	members := []string{o.Identity}
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+		if holder == "" || holder == o.Identity || !now.Before(leaseExpiry(lease, o.LeaseDuration)) {
+			continue
+		}
+		members = append(members, holder)
+	}
+	sort.Strings(members)
+
+	o.mu.Lock()
+	o.members = members
+	o.membersFetched = true
+	o.mu.Unlock()
+	return nil
+}
+
+// preferred returns the live member with the highest rendezvous hash for the Pool.
+func (o *PoolOwnership) preferred(key string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var (
+		best      string
+		bestScore uint64
+	)
+	for _, member := range o.members {
+		sum := sha256.Sum256([]byte(member + "/" + key))
+		if score := binary.BigEndian.Uint64(sum[:8]); best == "" || score > bestScore {
+			best, bestScore = member, score
+		}
+	}
+	if best == "" {
+		return o.Identity
+	}
+	return best
+}
+
+// mayTake reports whether this replica may take a free or expired Pool Lease.
+func (o *PoolOwnership) mayTake(key string, now time.Time) bool {
+	if o.preferred(key) == o.Identity {
+		return true
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.waiting == nil {
+		o.waiting = make(map[string]time.Time)
+	}
+	since, ok := o.waiting[key]
+	if !ok {
+		o.waiting[key] = now
+		return false
+	}
+	return now.Sub(since) >= o.LeaseDuration
+}
+
+func (o *PoolOwnership) hold(lease *coordinationv1.Lease, now time.Time, transition bool) {
+	renewTime := metav1.NewMicroTime(now)
+	if transition && ptr.Deref(lease.Spec.HolderIdentity, "") != o.Identity {
+		lease.Spec.AcquireTime = &renewTime
+		if lease.Spec.HolderIdentity != nil {
+			lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+		}
+	}
+	lease.Spec.HolderIdentity = ptr.To(o.Identity)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(o.LeaseDuration / time.Second))
+	lease.Spec.RenewTime = &renewTime
+}
+
+func (o *PoolOwnership) renew(ctx context.Context, lease *coordinationv1.Lease, now time.Time) error {
+	o.hold(lease, now, false)
+	if err := o.Client.Update(ctx, lease); err != nil {
+		return fmt.Errorf("failed to renew pool lease: %w", err)
+	}
+	return nil
+}
+
+func (o *PoolOwnership) release(ctx context.Context, lease *coordinationv1.Lease) error {
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.RenewTime = nil
+	return o.Client.Update(ctx, lease)
+}
+
+// releaseAll releases the owned Pool Leases and deletes the member Lease. The Lease of a Pool that is still
+// being reconciled when the context ends is left to expire.
+func (o *PoolOwnership) releaseAll(ctx context.Context) {
+	log := logf.FromContext(ctx)
+	o.mu.Lock()
+	keys := make([]string, 0, len(o.owned))
+	for key := range o.owned {
+		keys = append(keys, key)
+	}
+	o.owned = nil
+	o.mu.Unlock()
+
+	for _, key := range keys {
+		nn, ok := splitPoolKey(key)
+		if !ok {
+			continue
+		}
+		if err := wait.PollUntilContextCancel(ctx, releasePollInterval, true, func(context.Context) (bool, error) {
+			return o.drained(key), nil
+		}); err != nil {
+			log.Info("Pool is still being reconciled, leaving its lease to expire", "pool", key)
+			continue
+		}
+		lease := &coordinationv1.Lease{}
+		if err := o.Reader.Get(ctx, types.NamespacedName{Namespace: nn.Namespace, Name: poolLeasePrefix + nn.Name}, lease); err != nil {
+			continue
+		}
+		if ptr.Deref(lease.Spec.HolderIdentity, "") != o.Identity {
+			continue
+		}
+		if err := o.release(ctx, lease); err != nil {
+			log.Error(err, "Failed to release pool ownership", "pool", key)
+		}
+	}
+	member := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: o.Namespace, Name: memberLeasePrefix + o.Identity}}
+	if err := o.Client.Delete(ctx, member); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete member lease")
+	}
+}
+
+func (o *PoolOwnership) markOwned(key string, now time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.owned == nil {
+		o.owned = make(map[string]time.Time)
+	}
+	o.owned[key] = now
+	delete(o.waiting, key)
+}
+
+func (o *PoolOwnership) forget(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.owned, key)
+	delete(o.waiting, key)
+	delete(o.handingOver, key)
+}
+
+func (o *PoolOwnership) renewInterval() time.Duration {
+	return o.LeaseDuration / 3
+}
+
+func (o *PoolOwnership) clock() time.Time {
+	if o.now != nil {
+		return o.now()
+	}
+	return time.Now()
+}
+
+func leaseExpiry(lease *coordinationv1.Lease, defaultDuration time.Duration) time.Time {
+	if lease.Spec.RenewTime == nil {
+		return time.Time{}
+	}
+	duration := defaultDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return lease.Spec.RenewTime.Add(duration)
+}
+
+func splitPoolKey(key string) (types.NamespacedName, bool) {
+	namespace, name, ok := strings.Cut(key, "/")
+	return types.NamespacedName{Namespace: namespace, Name: name}, ok
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestPoolOwnership(t *testing.T) {
+	ctx := context.Background()
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(sandboxv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(coordinationv1.AddToScheme(scheme))
+
+	pools := make([]*sandboxv1alpha1.Pool, 0, 8)
+	objs := make([]client.Object, 0, 8)
+	for i := 0; i < 8; i++ {
+		pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("pool-%d", i),
+			Namespace: "default",
+			UID:       types.UID(fmt.Sprintf("uid-%d", i)),
+		}}
+		pools = append(pools, pool)
+		objs = append(objs, pool)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	newOwnership := func(identity string) *PoolOwnership {
+		return &PoolOwnership{
+			Client:        c,
+			Reader:        c,
+			Identity:      identity,
+			Namespace:     "opensandbox-system",
+			LeaseDuration: 15 * time.Second,
+			now:           clock,
+		}
+	}
+	owners := func(replicas ...*PoolOwnership) map[string]string {
+		ret := make(map[string]string)
+		for _, pool := range pools {
+			for _, o := range replicas {
+				owned, _, _, err := o.Acquire(ctx, pool)
+				assert.NoError(t, err)
+				if owned {
+					assert.NotContains(t, ret, pool.Name, "pool owned by two replicas")
+					ret[pool.Name] = o.Identity
+					o.Done(pool)
+				}
+			}
+		}
+		return ret
+	}
+
+	a := newOwnership("replica-a")
+	assert.NoError(t, a.heartbeat(ctx))
+	got := owners(a)
+	assert.Len(t, got, len(pools))
+
+	lease := &coordinationv1.Lease{}
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: poolLeasePrefix + "pool-0"}, lease))
+	assert.Equal(t, "replica-a", *lease.Spec.HolderIdentity)
+	assert.Equal(t, "pool-0", lease.OwnerReferences[0].Name)
+
+	// a new replica joins and the pools it is preferred for are handed over once their reconciles are done
+	b := newOwnership("replica-b")
+	assert.NoError(t, b.heartbeat(ctx))
+	assert.Empty(t, owners(b))
+	var busy *sandboxv1alpha1.Pool
+	for _, pool := range pools {
+		if b.preferred("default/"+pool.Name) == "replica-b" {
+			busy = pool
+			break
+		}
+	}
+	assert.NotNil(t, busy)
+	now = now.Add(5 * time.Second)
+	owned, _, _, err := a.Acquire(ctx, busy)
+	assert.NoError(t, err)
+	assert.True(t, owned)
+	assert.NoError(t, a.heartbeat(ctx))
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: poolLeasePrefix + busy.Name}, lease))
+	assert.Equal(t, "replica-a", *lease.Spec.HolderIdentity, "a pool being reconciled is not handed over")
+	owned, _, _, err = a.Acquire(ctx, busy)
+	assert.NoError(t, err)
+	assert.False(t, owned, "a pool being handed over is not reconciled")
+	a.Done(busy)
+	assert.NoError(t, a.heartbeat(ctx))
+	got = owners(a, b)
+	assert.Len(t, got, len(pools))
+	for name, owner := range got {
+		assert.Equal(t, b.preferred("default/"+name), owner, name)
+	}
+	assert.Contains(t, got, "pool-0")
+	counts := map[string]int{}
+	for _, owner := range got {
+		counts[owner]++
+	}
+	assert.Positive(t, counts["replica-a"])
+	assert.Positive(t, counts["replica-b"])
+
+	// replica-b stops renewing and replica-a takes over its pools once the leases expire
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, a.heartbeat(ctx))
+	for _, pool := range pools {
+		if got[pool.Name] != "replica-b" {
+			continue
+		}
+		owned, _, recheckAfter, err := a.Acquire(ctx, pool)
+		assert.NoError(t, err)
+		assert.False(t, owned)
+		assert.Positive(t, recheckAfter)
+	}
+	now = now.Add(16 * time.Second)
+	assert.NoError(t, a.heartbeat(ctx))
+	for _, pool := range pools {
+		owned, acquired, _, err := a.Acquire(ctx, pool)
+		assert.NoError(t, err)
+		assert.True(t, owned, pool.Name)
+		assert.Equal(t, got[pool.Name] == "replica-b", acquired, pool.Name)
+		a.Done(pool)
+	}
+
+	// waiting for a pool that is no longer checked is forgotten
+	a.mu.Lock()
+	a.waiting = map[string]time.Time{"default/gone": now}
+	a.mu.Unlock()
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, a.heartbeat(ctx))
+	assert.Empty(t, a.waiting)
+
+	// shutting down releases every lease
+	a.releaseAll(ctx)
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: poolLeasePrefix + "pool-0"}, lease))
+	assert.Nil(t, lease.Spec.HolderIdentity)
+	members := &coordinationv1.LeaseList{}
+	assert.NoError(t, c.List(ctx, members, client.InNamespace("opensandbox-system")))
+	assert.Len(t, members.Items, 1)
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func TestPausedPool(t *testing.T) {
+	ctx := context.Background()
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "paused", Namespace: "default", UID: types.UID("uid-paused")},
+		Spec: sandboxv1alpha1.PoolSpec{
+			CapacitySpec: sandboxv1alpha1.CapacitySpec{BufferMin: 1, BufferMax: 1, PoolMax: 10},
+			Paused:       true,
+		},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	var objs []client.Object
+	var pods []*corev1.Pod
+	var names []string
+	for i := range 4 {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("paused-%d", i), Namespace: "default", Labels: map[string]string{LabelPoolRevision: "old"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		}
+		objs = append(objs, pod)
+		pods = append(pods, pod)
+		names = append(names, pod.Name)
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	// The new revision is reported, but no pod is rolled to it.
+	update, err := r.updatePool(ctx, pool, template, pods, names)
+	require.NoError(t, err)
+	assert.NotEqual(t, "old", update.UpdateRevision)
+	assert.Empty(t, update.ToDeletePods)
+	assert.Zero(t, update.SupplyUpdateRevision)
+	assert.Equal(t, names, update.IdlePods)
+
+	// Four idle pods exceed bufferMax, yet only the pod given up by recycling is deleted, and nothing is
+	// created for the pending supply.
+	require.NoError(t, r.scalePool(ctx, pool, &scaleArgs{
+		template: template, updateRevision: update.UpdateRevision, pods: pods, allPods: pods, totalPodCnt: 4,
+		idlePods: names[1:], toDeletePods: names[:1], supplyCnt: 3,
+	}))
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, list))
+	require.Len(t, list.Items, 3)
+	for _, pod := range list.Items {
+		assert.NotEqual(t, "paused-0", pod.Name)
+	}
+}
+
+func TestPausedPool_Hibernation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "paused-hibernation", Namespace: "default"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			Paused: true,
+			Hibernation: &sandboxv1alpha1.PoolHibernation{
+				IdleSeconds: ptr.To[int32](60),
+				Requests:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+			},
+		},
+	}
+	t.Cleanup(func() { poolIdleSince.Delete("default/paused-hibernation") })
+	newPod := func(name string, annotations map[string]string, cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			}}}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				QOSClass:   corev1.PodQOSBurstable,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	idle := newPod("idle", nil, "2")
+	allocated := newPod("allocated", map[string]string{AnnoPoolHibernatedKey: `{"main":{"cpu":"2"}}`}, "10m")
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(idle, allocated).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	get := func(name string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+		return pod
+	}
+
+	for _, at := range []time.Time{now, now.Add(2 * time.Minute)} {
+		requeue, err := r.syncHibernation(ctx, pool, []*corev1.Pod{get("idle"), get("allocated")}, []string{"idle"}, at)
+		require.NoError(t, err)
+		assert.Zero(t, requeue)
+	}
+	pod := get("idle")
+	assert.NotContains(t, pod.Annotations, AnnoPoolHibernatedKey, "a paused pool hibernates no pod")
+	assert.True(t, pod.Spec.Containers[0].Resources.Requests.Cpu().Equal(resource.MustParse("2")))
+	pod = get("allocated")
+	assert.NotContains(t, pod.Annotations, AnnoPoolHibernatedKey, "hibernated pods still wake")
+	assert.True(t, pod.Spec.Containers[0].Resources.Requests.Cpu().Equal(resource.MustParse("2")))
+}
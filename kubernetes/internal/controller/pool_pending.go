@@ -0,0 +1,90 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// pendingPodTimeout returns capacitySpec.pendingTimeout, or zero when pending pods are left alone.
+func pendingPodTimeout(pool *sandboxv1alpha1.Pool) time.Duration {
+	if pool.Spec.CapacitySpec.PendingTimeout == nil {
+		return 0
+	}
+	return pool.Spec.CapacitySpec.PendingTimeout.Duration
+}
+
+// isPodUnscheduled reports whether the pod still waits for a node.
+func isPodUnscheduled(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp == nil && pod.Spec.NodeName == "" && pod.Status.Phase == corev1.PodPending
+}
+
+// podPendingReason returns why an unscheduled pod is given up on once it has waited for a node for timeout,
+// or an empty reason and how long until then.
+func podPendingReason(pod *corev1.Pod, timeout time.Duration, now time.Time) (string, time.Duration) {
+	if timeout <= 0 || !isPodUnscheduled(pod) {
+		return "", 0
+	}
+	if wait := pod.CreationTimestamp.Add(timeout).Sub(now); wait > 0 {
+		return "", wait
+	}
+	if isPodUnschedulable(pod) {
+		return corev1.PodReasonUnschedulable, 0
+	}
+	return "PendingTimeout", 0
+}
+
+// setSchedulingFailedCondition reports whether pods of the pool fail to schedule. The condition turns True
+// once a pod waited for a node past the pending timeout, and stays so while any pod is unscheduled, since
+// the replacements of such pods usually do not fare better. Without a timeout the condition is removed.
+func setSchedulingFailedCondition(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, now time.Time) {
+	status := &pool.Status
+	timeout := pendingPodTimeout(pool)
+	if timeout <= 0 {
+		meta.RemoveStatusCondition(&status.Conditions, sandboxv1alpha1.PoolConditionSchedulingFailed)
+		return
+	}
+
+	unscheduled, expired := 0, 0
+	for _, pod := range pods {
+		if !isPodUnscheduled(pod) {
+			continue
+		}
+		unscheduled++
+		if reason, _ := podPendingReason(pod, timeout, now); reason != "" {
+			expired++
+		}
+	}
+	cond := metav1.Condition{
+		Type:               sandboxv1alpha1.PoolConditionSchedulingFailed,
+		Status:             metav1.ConditionFalse,
+		Reason:             "PodsScheduled",
+		Message:            fmt.Sprintf("%d pods waiting for a node", unscheduled),
+		ObservedGeneration: pool.Generation,
+	}
+	failing := expired > 0 || (unscheduled > 0 && meta.IsStatusConditionTrue(status.Conditions, cond.Type))
+	if failing {
+		cond.Status, cond.Reason = metav1.ConditionTrue, "PendingTimeoutExceeded"
+		cond.Message = fmt.Sprintf("%d pods waiting for a node, %d of them for more than %s", unscheduled, expired, timeout)
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func pendingPod(name string, age time.Duration, now time.Time, unschedulable bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	if unschedulable {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable}}
+	}
+	return pod
+}
+
+func TestReplaceUnhealthyPods_PendingTimeout(t *testing.T) {
+	now := time.Now()
+	scheduled := pendingPod("pulling", time.Hour, now, false)
+	scheduled.Spec.NodeName = "node-1"
+	pods := []*corev1.Pod{
+		pendingPod("unschedulable", 15*time.Minute, now, true),
+		pendingPod("unbound", 11*time.Minute, now, false),
+		pendingPod("young", 8*time.Minute, now, true),
+		scheduled,
+	}
+	idle := []string{"unschedulable", "unbound", "young", "pulling"}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Recorder: recorder}
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	result := r.replaceUnhealthyPods(context.Background(), pool, pods, idle, now)
+	assert.Empty(t, result.ToDeletePods, "pending pods are left alone without a timeout")
+
+	pool.Spec.CapacitySpec.PendingTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	result = r.replaceUnhealthyPods(context.Background(), pool, pods, idle, now)
+	assert.Equal(t, []string{"unschedulable", "unbound"}, result.ToDeletePods)
+	assert.Equal(t, []string{"young", "pulling"}, result.IdlePods, "pods bound to a node are not pending on scheduling")
+	assert.Equal(t, 2*time.Minute, result.RequeueAfter)
+	assert.Contains(t, <-recorder.Events, corev1.PodReasonUnschedulable)
+	assert.Contains(t, <-recorder.Events, "PendingTimeout")
+}
+
+func TestSetSchedulingFailedCondition(t *testing.T) {
+	now := time.Now()
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	status := func() metav1.ConditionStatus {
+		cond := meta.FindStatusCondition(pool.Status.Conditions, sandboxv1alpha1.PoolConditionSchedulingFailed)
+		require.NotNil(t, cond)
+		assert.Equal(t, int64(2), cond.ObservedGeneration)
+		return cond.Status
+	}
+
+	setSchedulingFailedCondition(pool, []*corev1.Pod{pendingPod("stuck", time.Hour, now, true)}, now)
+	assert.Empty(t, pool.Status.Conditions, "the condition needs a pending timeout")
+
+	pool.Spec.CapacitySpec.PendingTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	setSchedulingFailedCondition(pool, []*corev1.Pod{pendingPod("young", time.Minute, now, true)}, now)
+	assert.Equal(t, metav1.ConditionFalse, status())
+
+	setSchedulingFailedCondition(pool, []*corev1.Pod{pendingPod("stuck", time.Hour, now, true)}, now)
+	assert.Equal(t, metav1.ConditionTrue, status())
+
+	// The replacement of a stuck pod keeps the condition until pods schedule again.
+	setSchedulingFailedCondition(pool, []*corev1.Pod{pendingPod("replacement", time.Second, now, true)}, now)
+	assert.Equal(t, metav1.ConditionTrue, status())
+	setSchedulingFailedCondition(pool, nil, now)
+	assert.Equal(t, metav1.ConditionFalse, status())
+
+	pool.Spec.CapacitySpec.PendingTimeout = nil
+	setSchedulingFailedCondition(pool, nil, now)
+	assert.Empty(t, pool.Status.Conditions)
+}
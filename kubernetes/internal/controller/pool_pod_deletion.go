@@ -0,0 +1,153 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+// DefaultPoolDeleteConcurrency is the number of pods of a pool deleted in parallel without a limiter.
+const DefaultPoolDeleteConcurrency = 16
+
+// PodDeletionLimiter bounds how fast the pool controller deletes the pods of each pool. Deletions run
+// concurrency at a time, and at qps per pool with bursts of burst. Deletions beyond the rate are deferred
+// to a later reconcile instead of waited for, so shrinking a large pool does not hold the reconcile worker.
+type PodDeletionLimiter struct {
+	concurrency int
+	qps         float64
+	burst       int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter // pool key -> limiter
+}
+
+// NewPodDeletionLimiter returns a limiter deleting concurrency pods of a pool at a time, at qps with bursts
+// of burst. A qps of zero does not limit the rate.
+func NewPodDeletionLimiter(concurrency int, qps float64, burst int) *PodDeletionLimiter {
+	return &PodDeletionLimiter{
+		concurrency: max(concurrency, 1),
+		qps:         qps,
+		burst:       max(burst, 1),
+		limiters:    make(map[string]*rate.Limiter),
+	}
+}
+
+// parallelism returns how many pods of a pool are deleted at a time.
+func (l *PodDeletionLimiter) parallelism() int {
+	if l == nil {
+		return DefaultPoolDeleteConcurrency
+	}
+	return l.concurrency
+}
+
+// allow returns how many of n deletions of the pool may go ahead at now and, if not all may, how long
+// until the next one may.
+func (l *PodDeletionLimiter) allow(pool string, n int, now time.Time) (int, time.Duration) {
+	if l == nil || l.qps <= 0 || n == 0 {
+		return n, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.limiters[pool]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(l.qps), l.burst)
+		l.limiters[pool] = limiter
+	}
+	allowed := min(n, max(int(limiter.TokensAt(now)), 0))
+	if allowed > 0 {
+		limiter.AllowN(now, allowed)
+	}
+	if allowed == n {
+		return n, 0
+	}
+	next := limiter.ReserveN(now, 1)
+	defer next.CancelAt(now)
+	return allowed, next.DelayFrom(now)
+}
+
+// forget drops the limiter of a pool that is gone.
+func (l *PodDeletionLimiter) forget(pool string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.limiters, pool)
+}
+
+// deferredDeletes sums up the pod deletions the rate limit defers in a reconcile, over the pool template
+// and its flavors.
+type deferredDeletes struct {
+	pods       int
+	retryAfter time.Duration // when the next deletion is allowed
+}
+
+// deletePoolPods deletes the pods of the pool in parallel, in the order given, as far as the deletion rate
+// of the pool allows. The pods beyond the rate are left for a later reconcile and added to deferred, if
+// not nil. It returns the deleted pods and the errors of the deletions that failed.
+func (r *PoolReconciler) deletePoolPods(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod,
+	deferred *deferredDeletes) ([]*corev1.Pod, []error) {
+	log := logf.FromContext(ctx)
+	allowed, retryAfter := r.PodDeletion.allow(controllerutils.GetControllerKey(pool), len(pods), time.Now())
+	if allowed < len(pods) {
+		log.Info("Deferring pool pod deletions to the deletion rate limit", "pool", pool.Name,
+			"deferred", len(pods)-allowed, "retryAfter", retryAfter)
+		if deferred != nil {
+			deferred.pods += len(pods) - allowed
+			if deferred.retryAfter == 0 || retryAfter < deferred.retryAfter {
+				deferred.retryAfter = retryAfter
+			}
+		}
+		pods = pods[:allowed]
+	}
+
+	errs := make([]error, len(pods))
+	sem := make(chan struct{}, r.PodDeletion.parallelism())
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			log.Info("Deleting pool pod", "pool", pool.Name, "pod", pod.Name)
+			if err := deletePoolPod(ctx, r.Client, pool, pod); err != nil {
+				log.Error(err, "Failed to delete pool pod", "pod", pod.Name)
+				errs[i] = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	var deleted []*corev1.Pod
+	var failed []error
+	for i, pod := range pods {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+		} else {
+			deleted = append(deleted, pod)
+		}
+	}
+	return deleted, failed
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func TestPodDeletionLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var unlimited *PodDeletionLimiter
+	allowed, retryAfter := unlimited.allow("default/pool", 500, now)
+	assert.Equal(t, 500, allowed)
+	assert.Zero(t, retryAfter)
+	assert.Equal(t, DefaultPoolDeleteConcurrency, unlimited.parallelism())
+
+	allowed, _ = NewPodDeletionLimiter(4, 0, 1).allow("default/pool", 500, now)
+	assert.Equal(t, 500, allowed, "a qps of zero does not limit the rate")
+
+	limiter := NewPodDeletionLimiter(4, 10, 3)
+	allowed, retryAfter = limiter.allow("default/pool", 5, now)
+	assert.Equal(t, 3, allowed, "a burst goes ahead at once")
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+	allowed, _ = limiter.allow("default/other", 5, now)
+	assert.Equal(t, 3, allowed, "pools are limited on their own")
+
+	allowed, retryAfter = limiter.allow("default/pool", 5, now.Add(250*time.Millisecond))
+	assert.Equal(t, 2, allowed)
+	assert.Equal(t, 50*time.Millisecond, retryAfter)
+	allowed, retryAfter = limiter.allow("default/pool", 0, now.Add(250*time.Millisecond))
+	assert.Zero(t, allowed)
+	assert.Zero(t, retryAfter)
+
+	limiter.forget("default/pool")
+	allowed, _ = limiter.allow("default/pool", 5, now.Add(250*time.Millisecond))
+	assert.Equal(t, 3, allowed, "a forgotten pool starts with a full burst")
+}
+
+func TestScalePoolDeletesInParallel(t *testing.T) {
+	ctx := context.Background()
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "parallel-delete", Namespace: "default", UID: "uid-parallel-delete"},
+		Spec:       sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{BufferMin: 2, BufferMax: 2, PoolMax: 20}},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	var objs []client.Object
+	var pods []*corev1.Pod
+	var names []string
+	for i := range 12 {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("parallel-delete-%d", i), Namespace: "default"},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		}
+		objs = append(objs, pod)
+		pods = append(pods, pod)
+		names = append(names, pod.Name)
+	}
+	var inFlight, maxInFlight, deletes atomic.Int32
+	failed := errors.New("delete failed")
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if n <= seen || maxInFlight.CompareAndSwap(seen, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			if deletes.Add(1) == 1 {
+				return failed
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build()
+	r := &PoolReconciler{
+		Client:      c,
+		Scheme:      testscheme,
+		Recorder:    record.NewFakeRecorder(10),
+		PodDeletion: NewPodDeletionLimiter(3, 1, 8),
+	}
+	args := &scaleArgs{
+		template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 12, idlePods: names,
+		deferred: &deferredDeletes{},
+	}
+
+	err := r.scalePool(ctx, pool, args)
+	require.ErrorIs(t, err, failed)
+	assert.Equal(t, int32(8), deletes.Load(), "deletions beyond the burst are deferred")
+	assert.Equal(t, int32(3), maxInFlight.Load(), "deletions run concurrency at a time")
+	assert.Equal(t, 2, args.deferred.pods)
+	assert.Equal(t, time.Second, args.deferred.retryAfter.Round(100*time.Millisecond))
+
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, list))
+	assert.Len(t, list.Items, 5, "a failed deletion is retried on a later reconcile")
+}
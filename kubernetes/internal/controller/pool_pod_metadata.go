@@ -0,0 +1,199 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	gerrors "errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/recycle"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+// reservedMetadataDomain guards controller-owned keys from being overwritten by propagation.
+const reservedMetadataDomain = "opensandbox.io/"
+
+// syncPodMetadata labels pool pods with the BatchSandbox they are allocated to and copies the propagated
+// labels/annotations of that BatchSandbox onto them. Both are stripped once a pod is no longer allocated.
+// Each new allocation also increments the allocation count of the pod.
+func (r *PoolReconciler) syncPodMetadata(ctx context.Context, batchSandboxes []*sandboxv1alpha1.BatchSandbox, pods []*corev1.Pod, podAllocation map[string]string) error {
+	log := logf.FromContext(ctx)
+	sandboxes := make(map[string]*sandboxv1alpha1.BatchSandbox, len(batchSandboxes))
+	for _, sbx := range batchSandboxes {
+		sandboxes[sbx.Name] = sbx
+	}
+	var errs []error
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		var sbx *sandboxv1alpha1.BatchSandbox
+		sbxName, ok := podAllocation[pod.Name]
+		if ok {
+			sbx = sandboxes[sbxName]
+		}
+		updated := pod.DeepCopy()
+		changed := applyPropagatedMetadata(updated, sbx)
+		if sbxName != "" && pod.Labels[LabelAllocatedTo] != sbxName {
+			countAllocation(updated)
+		}
+		changed = applyAllocatedToLabel(updated, sbxName) || changed
+		if !changed {
+			continue
+		}
+		if err := r.Patch(ctx, updated, client.MergeFrom(pod)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync metadata of pod %s: %w", pod.Name, err))
+			continue
+		}
+		log.Info("Synced pool pod metadata", "pod", pod.Name, "allocatedTo", sbxName, "propagated", utils.GetAnnotation(updated, AnnoPropagatedMetadataKey))
+	}
+	return gerrors.Join(errs...)
+}
+
+// countAllocation increments the allocation count of the pod, which maxAllocations retires pods by.
+func countAllocation(pod *corev1.Pod) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[AnnoPoolAllocationCountKey] = strconv.Itoa(int(recycle.AllocationCount(pod)) + 1)
+}
+
+// applyAllocatedToLabel sets LabelAllocatedTo to sandboxName, or removes it if sandboxName is empty.
+// It returns whether the pod was changed.
+func applyAllocatedToLabel(pod *corev1.Pod, sandboxName string) bool {
+	if pod.Labels[LabelAllocatedTo] == sandboxName {
+		return false
+	}
+	if sandboxName == "" {
+		if _, ok := pod.Labels[LabelAllocatedTo]; !ok {
+			return false
+		}
+		delete(pod.Labels, LabelAllocatedTo)
+		return true
+	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[LabelAllocatedTo] = sandboxName
+	return true
+}
+
+// applyPropagatedMetadata mutates pod so that it carries exactly the metadata propagated from sbx.
+// A nil sbx removes everything propagated before. Keys the pod already owns are never overwritten.
+// It returns whether the pod was changed.
+func applyPropagatedMetadata(pod *corev1.Pod, sbx *sandboxv1alpha1.BatchSandbox) bool {
+	prev := PropagatedMetadata{}
+	if raw := utils.GetAnnotation(pod, AnnoPropagatedMetadataKey); raw != "" {
+		// A corrupted record only means we can't clean up old keys; start over.
+		_ = json.Unmarshal([]byte(raw), &prev)
+	}
+	oldLabels := maps.Clone(pod.Labels)
+	oldAnnotations := maps.Clone(pod.Annotations)
+
+	var wantLabels, wantAnnotations map[string]string
+	if sbx != nil {
+		wantLabels = selectPropagated(sbx.Labels, utils.GetAnnotation(sbx, AnnoPropagateLabelsKey), pod.Labels, prev.Labels)
+		wantAnnotations = selectPropagated(sbx.Annotations, utils.GetAnnotation(sbx, AnnoPropagateAnnotationsKey), pod.Annotations, prev.Annotations)
+	}
+	pod.Labels = reconcilePropagated(pod.Labels, prev.Labels, wantLabels)
+	pod.Annotations = reconcilePropagated(pod.Annotations, prev.Annotations, wantAnnotations)
+
+	record := PropagatedMetadata{Labels: sortedKeys(wantLabels), Annotations: sortedKeys(wantAnnotations)}
+	if len(record.Labels) == 0 && len(record.Annotations) == 0 {
+		delete(pod.Annotations, AnnoPropagatedMetadataKey)
+	} else {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[AnnoPropagatedMetadataKey] = utils.DumpJSON(record)
+	}
+	return !maps.Equal(oldLabels, pod.Labels) || !maps.Equal(oldAnnotations, pod.Annotations)
+}
+
+// selectPropagated picks the source values for the comma-separated keys, skipping reserved keys and
+// keys already present on the pod that were not put there by propagation.
+// propagatedMetadataChanged reports whether an update of the sandbox changes the metadata propagated to its
+// pods: the lists of propagated keys, or the value of a listed key.
+func propagatedMetadataChanged(oldObj, newObj *sandboxv1alpha1.BatchSandbox) bool {
+	changed := func(oldSource, newSource map[string]string, listKey string) bool {
+		keyList := newObj.Annotations[listKey]
+		if oldObj.Annotations[listKey] != keyList {
+			return true
+		}
+		for _, key := range strings.Split(keyList, ",") {
+			if key = strings.TrimSpace(key); key != "" && oldSource[key] != newSource[key] {
+				return true
+			}
+		}
+		return false
+	}
+	return changed(oldObj.Labels, newObj.Labels, AnnoPropagateLabelsKey) ||
+		changed(oldObj.Annotations, newObj.Annotations, AnnoPropagateAnnotationsKey)
+}
+
+func selectPropagated(source map[string]string, keyList string, current map[string]string, owned []string) map[string]string {
+	ret := map[string]string{}
+	for _, key := range strings.Split(keyList, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || strings.Contains(key, reservedMetadataDomain) {
+			continue
+		}
+		value, ok := source[key]
+		if !ok {
+			continue
+		}
+		if _, exists := current[key]; exists && !slices.Contains(owned, key) {
+			continue
+		}
+		ret[key] = value
+	}
+	return ret
+}
+
+func reconcilePropagated(current map[string]string, owned []string, want map[string]string) map[string]string {
+	for _, key := range owned {
+		if _, ok := want[key]; !ok {
+			delete(current, key)
+		}
+	}
+	if len(want) > 0 && current == nil {
+		current = map[string]string{}
+	}
+	for key, value := range want {
+		current[key] = value
+	}
+	return current
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
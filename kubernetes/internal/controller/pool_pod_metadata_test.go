@@ -0,0 +1,225 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func newPropagationSandbox(labels, annotations map[string]string) *sandboxv1alpha1.BatchSandbox {
+	return &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sbx",
+			Namespace:   "default",
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestApplyPropagatedMetadata(t *testing.T) {
+	sbx := newPropagationSandbox(
+		map[string]string{"job-id": "j1", "team": "infra", "other": "x"},
+		map[string]string{
+			AnnoPropagateLabelsKey:      "job-id, team, missing, " + LabelBatchSandboxNameKey,
+			AnnoPropagateAnnotationsKey: "cost-center",
+			"cost-center":               "cc-42",
+		},
+	)
+
+	tests := []struct {
+		name            string
+		pod             *corev1.Pod
+		sbx             *sandboxv1alpha1.BatchSandbox
+		wantChanged     bool
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name: "allocated pod receives propagated metadata",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "pod-1",
+				Labels: map[string]string{"app": "pool"},
+			}},
+			sbx:         sbx,
+			wantChanged: true,
+			wantLabels:  map[string]string{"app": "pool", "job-id": "j1", "team": "infra"},
+			wantAnnotations: map[string]string{
+				"cost-center":             "cc-42",
+				AnnoPropagatedMetadataKey: `{"labels":["job-id","team"],"annotations":["cost-center"]}`,
+			},
+		},
+		{
+			name: "existing pod keys are not overwritten",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "pod-1",
+				Labels: map[string]string{"team": "pool-team"},
+			}},
+			sbx:         sbx,
+			wantChanged: true,
+			wantLabels:  map[string]string{"job-id": "j1", "team": "pool-team"},
+			wantAnnotations: map[string]string{
+				"cost-center":             "cc-42",
+				AnnoPropagatedMetadataKey: `{"labels":["job-id"],"annotations":["cost-center"]}`,
+			},
+		},
+		{
+			name: "already propagated pod is unchanged",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "pod-1",
+				Labels: map[string]string{"job-id": "j1", "team": "infra"},
+				Annotations: map[string]string{
+					"cost-center":             "cc-42",
+					AnnoPropagatedMetadataKey: `{"labels":["job-id","team"],"annotations":["cost-center"]}`,
+				},
+			}},
+			sbx:         sbx,
+			wantChanged: false,
+			wantLabels:  map[string]string{"job-id": "j1", "team": "infra"},
+			wantAnnotations: map[string]string{
+				"cost-center":             "cc-42",
+				AnnoPropagatedMetadataKey: `{"labels":["job-id","team"],"annotations":["cost-center"]}`,
+			},
+		},
+		{
+			name: "released pod is stripped of propagated metadata",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "pod-1",
+				Labels: map[string]string{"app": "pool", "job-id": "j1", "team": "infra"},
+				Annotations: map[string]string{
+					"cost-center":             "cc-42",
+					AnnoPropagatedMetadataKey: `{"labels":["job-id","team"],"annotations":["cost-center"]}`,
+				},
+			}},
+			sbx:             nil,
+			wantChanged:     true,
+			wantLabels:      map[string]string{"app": "pool"},
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name: "idle pod without propagation is unchanged",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   "pod-1",
+				Labels: map[string]string{"app": "pool"},
+			}},
+			sbx:         nil,
+			wantChanged: false,
+			wantLabels:  map[string]string{"app": "pool"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := applyPropagatedMetadata(tt.pod, tt.sbx)
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.wantLabels, tt.pod.Labels)
+			if tt.wantAnnotations == nil {
+				assert.Empty(t, tt.pod.Annotations)
+			} else {
+				assert.Equal(t, tt.wantAnnotations, tt.pod.Annotations)
+			}
+		})
+	}
+}
+
+func TestSyncPodMetadata(t *testing.T) {
+	ctx := context.Background()
+	sbx := newPropagationSandbox(
+		map[string]string{"job-id": "j1"},
+		map[string]string{AnnoPropagateLabelsKey: "job-id"},
+	)
+	allocated := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}}
+	released := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pod-2",
+		Namespace:   "default",
+		Labels:      map[string]string{"job-id": "old"},
+		Annotations: map[string]string{AnnoPropagatedMetadataKey: `{"labels":["job-id"]}`},
+	}}
+	alloc := map[string]string{"pod-1": "sbx"}
+	r := newEvictionTestReconciler(alloc, allocated, released)
+
+	err := r.syncPodMetadata(ctx, []*sandboxv1alpha1.BatchSandbox{sbx}, []*corev1.Pod{allocated, released}, alloc)
+	assert.NoError(t, err)
+
+	got := &corev1.Pod{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-1"}, got))
+	assert.Equal(t, "j1", got.Labels["job-id"])
+	assert.Equal(t, `{"labels":["job-id"]}`, got.Annotations[AnnoPropagatedMetadataKey])
+
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-2"}, got))
+	assert.NotContains(t, got.Labels, "job-id")
+	assert.NotContains(t, got.Annotations, AnnoPropagatedMetadataKey)
+}
+
+func TestSyncPodMetadataCountsAllocations(t *testing.T) {
+	ctx := context.Background()
+	sbx := newPropagationSandbox(nil, nil)
+	reused := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pod-1",
+		Namespace:   "default",
+		Annotations: map[string]string{AnnoPoolAllocationCountKey: "2"},
+	}}
+	alloc := map[string]string{"pod-1": "sbx"}
+	r := newEvictionTestReconciler(alloc, reused)
+
+	assert.NoError(t, r.syncPodMetadata(ctx, []*sandboxv1alpha1.BatchSandbox{sbx}, []*corev1.Pod{reused}, alloc))
+	got := &corev1.Pod{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-1"}, got))
+	assert.Equal(t, "3", got.Annotations[AnnoPoolAllocationCountKey])
+
+	// The same allocation is counted once.
+	assert.NoError(t, r.syncPodMetadata(ctx, []*sandboxv1alpha1.BatchSandbox{sbx}, []*corev1.Pod{got.DeepCopy()}, alloc))
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-1"}, got))
+	assert.Equal(t, "3", got.Annotations[AnnoPoolAllocationCountKey])
+
+	// Releasing the pod keeps the count.
+	assert.NoError(t, r.syncPodMetadata(ctx, []*sandboxv1alpha1.BatchSandbox{sbx}, []*corev1.Pod{got.DeepCopy()}, nil))
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-1"}, got))
+	assert.NotContains(t, got.Labels, LabelAllocatedTo)
+	assert.Equal(t, "3", got.Annotations[AnnoPoolAllocationCountKey])
+}
+
+func TestPropagatedMetadataChanged(t *testing.T) {
+	base := newPropagationSandbox(
+		map[string]string{"team": "a", "other": "x"},
+		map[string]string{AnnoPropagateLabelsKey: "team", AnnoPropagateAnnotationsKey: "owner", "owner": "alice", "note": "n"},
+	)
+	tests := []struct {
+		name   string
+		mutate func(sbx *sandboxv1alpha1.BatchSandbox)
+		want   bool
+	}{
+		{name: "propagated label", mutate: func(sbx *sandboxv1alpha1.BatchSandbox) { sbx.Labels["team"] = "b" }, want: true},
+		{name: "propagated annotation", mutate: func(sbx *sandboxv1alpha1.BatchSandbox) { sbx.Annotations["owner"] = "bob" }, want: true},
+		{name: "label list", mutate: func(sbx *sandboxv1alpha1.BatchSandbox) { sbx.Annotations[AnnoPropagateLabelsKey] = "team,other" }, want: true},
+		{name: "annotation list", mutate: func(sbx *sandboxv1alpha1.BatchSandbox) { delete(sbx.Annotations, AnnoPropagateAnnotationsKey) }, want: true},
+		{name: "other label", mutate: func(sbx *sandboxv1alpha1.BatchSandbox) { sbx.Labels["other"] = "y" }},
+		{name: "other annotation", mutate: func(sbx *sandboxv1alpha1.BatchSandbox) { sbx.Annotations["note"] = "m" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base.DeepCopy()
+			tt.mutate(updated)
+			assert.Equal(t, tt.want, propagatedMetadataChanged(base, updated))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+const (
+	reasonWarmPodReclaimed = "WarmPodReclaimed"
+	// warmReclaimInterval gives reclaimed pods time to terminate and pending pods time to be scheduled
+	// before more pods are reclaimed for the same pool.
+	warmReclaimInterval = 30 * time.Second
+)
+
+// poolWarmReclaims is poolKey -> when the pool last reclaimed pods of lower priority pools.
+var poolWarmReclaims sync.Map
+
+// applyPoolPriority returns the template with the PriorityClass of the pool, leaving the template
+// untouched when the pool has none.
+func applyPoolPriority(pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	if template == nil || pool.Spec.Priority == nil || pool.Spec.Priority.ClassName == "" {
+		return template
+	}
+	if template.Spec.PriorityClassName == pool.Spec.Priority.ClassName {
+		return template
+	}
+	out := template.DeepCopy()
+	out.Spec.PriorityClassName = pool.Spec.Priority.ClassName
+	// The admission controller resolves the priority from the class name.
+	out.Spec.Priority = nil
+	return out
+}
+
+func poolPriorityValue(pool *sandboxv1alpha1.Pool) int32 {
+	if pool.Spec.Priority == nil {
+		return 0
+	}
+	return pool.Spec.Priority.Value
+}
+
+// isPodUnschedulable reports whether the scheduler found no node for the pod.
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	if pod.Spec.NodeName != "" {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled {
+			return cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
+
+// reclaimWarmPods deletes idle pods of lower priority pools, one for every pod of the pool the scheduler
+// cannot place. Victims are taken from the lowest priority pool first and, within a pool, by lowest deletion
+// cost and then newest first, since those have served the least. It returns when to check the pool again while pods stay unschedulable.
+func (r *PoolReconciler) reclaimWarmPods(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, now time.Time) (time.Duration, error) {
+	if pool.Spec.Priority == nil || !pool.DeletionTimestamp.IsZero() || pool.Spec.Paused {
+		return 0, nil
+	}
+	unschedulable := 0
+	for _, pod := range pods {
+		if isPodUnschedulable(pod) {
+			unschedulable++
+		}
+	}
+	key := pool.Namespace + "/" + pool.Name
+	if unschedulable == 0 {
+		poolWarmReclaims.Delete(key)
+		return 0, nil
+	}
+	if last, ok := poolWarmReclaims.Load(key); ok {
+		if wait := last.(time.Time).Add(warmReclaimInterval).Sub(now); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	victims, err := r.listWarmVictims(ctx, pool, unschedulable)
+	if err != nil {
+		return 0, err
+	}
+	poolWarmReclaims.Store(key, now)
+	log := logf.FromContext(ctx)
+	var errs []error
+	for _, victim := range victims {
+		if err := deletePoolPod(ctx, r.Client, victim.pool, victim.pod); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to reclaim pod %s/%s: %w", victim.pod.Namespace, victim.pod.Name, err))
+			continue
+		}
+		log.Info("Reclaimed idle pod of lower priority pool", "pool", pool.Name, "victimPool", victim.pool.Name, "pod", victim.pod.Name)
+		r.Recorder.Eventf(victim.pool, corev1.EventTypeNormal, reasonWarmPodReclaimed,
+			"Deleted idle pod %s to make room for pool %s/%s of higher priority", victim.pod.Name, pool.Namespace, pool.Name)
+	}
+	if len(victims) > 0 {
+		r.Recorder.Eventf(pool, corev1.EventTypeNormal, reasonWarmPodReclaimed,
+			"Deleted %d idle pod(s) of lower priority pools for %d unschedulable pod(s)", len(victims), unschedulable)
+	}
+	return warmReclaimInterval, gerrors.Join(errs...)
+}
+
+type warmVictim struct {
+	pool *sandboxv1alpha1.Pool
+	pod  *corev1.Pod
+}
+
+// listWarmVictims returns up to limit idle, scheduled pods of unpaused pools with a lower priority than pool.
+func (r *PoolReconciler) listWarmVictims(ctx context.Context, pool *sandboxv1alpha1.Pool, limit int) ([]warmVictim, error) {
+	poolList := &sandboxv1alpha1.PoolList{}
+	if err := r.List(ctx, poolList); err != nil {
+		return nil, fmt.Errorf("failed to list pools: %w", err)
+	}
+	value := poolPriorityValue(pool)
+	lower := make([]*sandboxv1alpha1.Pool, 0, len(poolList.Items))
+	for i := range poolList.Items {
+		other := &poolList.Items[i]
+		// Paused pools keep their pods.
+		if poolPriorityValue(other) < value && !other.Spec.Paused {
+			lower = append(lower, other)
+		}
+	}
+	sort.SliceStable(lower, func(i, j int) bool {
+		if vi, vj := poolPriorityValue(lower[i]), poolPriorityValue(lower[j]); vi != vj {
+			return vi < vj
+		}
+		if lower[i].Namespace != lower[j].Namespace {
+			return lower[i].Namespace < lower[j].Namespace
+		}
+		return lower[i].Name < lower[j].Name
+	})
+
+	var victims []warmVictim
+	for _, other := range lower {
+		if len(victims) >= limit {
+			break
+		}
+		allocation, err := r.Allocator.GetPoolAllocation(ctx, other)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get allocation of pool %s/%s: %w", other.Namespace, other.Name, err)
+		}
+		podList := &corev1.PodList{}
+		if err := r.List(ctx, podList, &client.ListOptions{
+			Namespace:     other.Namespace,
+			FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForOwnerRefUID: string(other.UID)}),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list pods of pool %s/%s: %w", other.Namespace, other.Name, err)
+		}
+		idle := make([]*corev1.Pod, 0, len(podList.Items))
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if !pod.DeletionTimestamp.IsZero() || pod.Spec.NodeName == "" {
+				continue
+			}
+			if _, allocated := allocation[pod.Name]; allocated {
+				continue
+			}
+			idle = append(idle, pod)
+		}
+		sort.SliceStable(idle, func(i, j int) bool {
+			if ci, cj := utils.PodDeletionCost(idle[i]), utils.PodDeletionCost(idle[j]); ci != cj {
+				return ci < cj
+			}
+			return idle[j].CreationTimestamp.Before(&idle[i].CreationTimestamp)
+		})
+		for _, pod := range idle {
+			if len(victims) >= limit {
+				break
+			}
+			victims = append(victims, warmVictim{pool: other, pod: pod})
+		}
+	}
+	return victims, nil
+}
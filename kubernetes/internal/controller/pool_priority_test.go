@@ -0,0 +1,117 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+func TestApplyPoolPriority(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{PriorityClassName: "default", Containers: []corev1.Container{{Name: "main"}}}}
+	pool := &sandboxv1alpha1.Pool{}
+	assert.Same(t, template, applyPoolPriority(pool, template), "no priority keeps the template")
+
+	pool.Spec.Priority = &sandboxv1alpha1.PoolPriority{ClassName: "sandbox-high", Value: 100}
+	out := applyPoolPriority(pool, template)
+	assert.Equal(t, "sandbox-high", out.Spec.PriorityClassName)
+	assert.Equal(t, "default", template.Spec.PriorityClassName, "the resolved template is not modified")
+	assert.Nil(t, applyPoolPriority(pool, nil))
+}
+
+func TestReclaimWarmPods(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	newPool := func(name string, priority *sandboxv1alpha1.PoolPriority) *sandboxv1alpha1.Pool {
+		return &sandboxv1alpha1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+			Spec:       sandboxv1alpha1.PoolSpec{Priority: priority},
+		}
+	}
+	newPod := func(pool *sandboxv1alpha1.Pool, name, node string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: pool.Namespace,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				OwnerReferences:   []metav1.OwnerReference{{APIVersion: "sandbox.opensandbox.io/v1alpha1", Kind: "Pool", Name: pool.Name, UID: pool.UID, Controller: ptr.To(true)}},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+	high := newPool("high", &sandboxv1alpha1.PoolPriority{Value: 100})
+	low := newPool("low", nil)
+	lowest := newPool("lowest", &sandboxv1alpha1.PoolPriority{Value: -10})
+	peer := newPool("peer", &sandboxv1alpha1.PoolPriority{Value: 100})
+	t.Cleanup(func() { poolWarmReclaims.Delete("default/high") })
+
+	objs := []client.Object{
+		high, low, lowest, peer,
+		newPod(low, "low-allocated", "node-a", time.Hour),
+		newPod(low, "low-old", "node-a", time.Hour),
+		newPod(low, "low-new", "node-a", time.Minute),
+		newPod(lowest, "lowest-pending", "", time.Minute),
+		newPod(lowest, "lowest-idle", "node-b", time.Hour),
+		newPod(peer, "peer-idle", "node-a", time.Hour),
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).
+		WithIndex(&corev1.Pod{}, fieldindex.IndexNameForOwnerRefUID, fieldindex.OwnerIndexFunc).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: recorder,
+		Allocator: &stubAllocator{podAllocation: map[string]string{"low-allocated": "sbx"}}}
+
+	unschedulable := func(name string) *corev1.Pod {
+		pod := newPod(high, name, "", 0)
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable}}
+		return pod
+	}
+	pods := []*corev1.Pod{unschedulable("high-0"), unschedulable("high-1"), newPod(high, "high-2", "node-a", time.Hour)}
+
+	requeue, err := r.reclaimWarmPods(ctx, high, pods, now)
+	require.NoError(t, err)
+	assert.Equal(t, warmReclaimInterval, requeue)
+	remaining := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, remaining))
+	var names []string
+	for _, pod := range remaining.Items {
+		names = append(names, pod.Name)
+	}
+	// The lowest pool goes first, then the newest idle pod of the next one; pending, allocated and equal
+	// priority pods are kept.
+	assert.ElementsMatch(t, []string{"low-allocated", "low-old", "lowest-pending", "peer-idle"}, names)
+
+	requeue, err = r.reclaimWarmPods(ctx, high, pods, now.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, requeue, "no reclaim within the interval")
+	require.NoError(t, c.List(ctx, remaining))
+	assert.Len(t, remaining.Items, 4)
+
+	requeue, err = r.reclaimWarmPods(ctx, high, pods[2:], now.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.Zero(t, requeue, "nothing to do once every pod is scheduled")
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+// isPodAvailable reports whether a pool pod meets the readiness criteria of the pool and may be
+// handed to a BatchSandbox.
+func isPodAvailable(pool *sandboxv1alpha1.Pool, pod *corev1.Pod) bool {
+	if !utils.IsPodReady(pod) {
+		return false
+	}
+	if pool == nil || pool.Spec.Readiness == nil {
+		return true
+	}
+	for _, conditionType := range pool.Spec.Readiness.Conditions {
+		_, cond := utils.GetPodCondition(&pod.Status, conditionType)
+		if cond == nil || cond.Status != corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestIsPodAvailable(t *testing.T) {
+	const warmed corev1.PodConditionType = "sandbox.opensandbox.io/warmed"
+	newPod := func(name string, phase corev1.PodPhase, conditions ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.PodStatus{Phase: phase, Conditions: conditions},
+		}
+	}
+	ready := corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionTrue}
+	pods := []*corev1.Pod{
+		newPod("running", corev1.PodRunning),
+		newPod("ready", corev1.PodRunning, ready),
+		newPod("warmed", corev1.PodRunning, ready, corev1.PodCondition{Type: warmed, Status: corev1.ConditionTrue}),
+		newPod("cold", corev1.PodRunning, ready, corev1.PodCondition{Type: warmed, Status: corev1.ConditionFalse}),
+		newPod("pending", corev1.PodPending, corev1.PodCondition{Type: warmed, Status: corev1.ConditionTrue}),
+	}
+	allocator := &defaultAllocator{}
+	pool := &sandboxv1alpha1.Pool{}
+
+	available, err := allocator.getAvailablePodsFromAlloc(context.Background(), pool, map[string]string{"ready": "bs"}, pods)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warmed", "cold"}, available, "Running alone is not enough and allocated pods are skipped")
+
+	pool.Spec.Readiness = &sandboxv1alpha1.PoolReadiness{Conditions: []corev1.PodConditionType{warmed}}
+	available, err = allocator.getAvailablePodsFromAlloc(context.Background(), pool, nil, pods)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warmed"}, available)
+	assert.Equal(t, int32(4), (&PoolReconciler{}).countNotReadyPods(pool, pods))
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+const defaultPoolRevisionHistoryLimit = 10
+
+func poolRevisionHistoryLimit(pool *sandboxv1alpha1.Pool) int {
+	if pool.Spec.RevisionHistoryLimit == nil {
+		return defaultPoolRevisionHistoryLimit
+	}
+	return int(*pool.Spec.RevisionHistoryLimit)
+}
+
+// listPoolRevisions returns the ControllerRevisions of the pool, oldest first.
+func (r *PoolReconciler) listPoolRevisions(ctx context.Context, pool *sandboxv1alpha1.Pool) ([]*appsv1.ControllerRevision, error) {
+	list := &appsv1.ControllerRevisionList{}
+	if err := r.List(ctx, list, client.InNamespace(pool.Namespace), client.MatchingLabels{LabelPoolName: pool.Name}); err != nil {
+		return nil, err
+	}
+	revisions := make([]*appsv1.ControllerRevision, 0, len(list.Items))
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], pool) {
+			revisions = append(revisions, &list.Items[i])
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// syncPoolRevisions records template, the resolved template of the pool before its priority is
+// applied, as the ControllerRevision of revision. A revision that becomes current again is moved to
+// the end of the history. Past revisions beyond the history limit are deleted, oldest first, unless
+// pods still run them.
+func (r *PoolReconciler) syncPoolRevisions(ctx context.Context, pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec, revision string, pods []*corev1.Pod) error {
+	log := logf.FromContext(ctx)
+	revisions, err := r.listPoolRevisions(ctx, pool)
+	if err != nil {
+		return err
+	}
+	next := int64(1)
+	if len(revisions) > 0 {
+		next = revisions[len(revisions)-1].Revision + 1
+	}
+	var current *appsv1.ControllerRevision
+	for _, cr := range revisions {
+		if cr.Labels[LabelPoolRevision] == revision {
+			current = cr
+		}
+	}
+	switch {
+	case current == nil:
+		data, err := json.Marshal(template)
+		if err != nil {
+			return err
+		}
+		current = &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s", pool.Name, revision),
+				Namespace: pool.Namespace,
+				Labels:    map[string]string{LabelPoolName: pool.Name, LabelPoolRevision: revision},
+			},
+			Data:     runtime.RawExtension{Raw: data},
+			Revision: next,
+		}
+		if err := controllerutil.SetControllerReference(pool, current, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, current); err != nil {
+			return err
+		}
+		log.Info("Recorded pool revision", "revision", revision, "number", next)
+		revisions = append(revisions, current)
+	case current != revisions[len(revisions)-1]:
+		current.Revision = next
+		if err := r.Update(ctx, current); err != nil {
+			return err
+		}
+		sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	}
+
+	inUse := map[string]bool{revision: true}
+	for _, pod := range pods {
+		inUse[pod.Labels[LabelPoolRevision]] = true
+	}
+	history := make([]*appsv1.ControllerRevision, 0, len(revisions))
+	for _, cr := range revisions {
+		if !inUse[cr.Labels[LabelPoolRevision]] {
+			history = append(history, cr)
+		}
+	}
+	for i := 0; i < len(history)-poolRevisionHistoryLimit(pool); i++ {
+		if err := r.Delete(ctx, history[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Pruned pool revision", "revision", history[i].Labels[LabelPoolRevision], "number", history[i].Revision)
+	}
+	return nil
+}
+
+// rollbackPool restores the template of the revision named by the rollback annotation and removes the
+// annotation. The revision is matched by its template hash, its number or its ControllerRevision name.
+// A pool rolled back from templateFrom keeps the restored template inline. It reports whether the pool
+// was updated, in which case the update triggers the next reconcile.
+func (r *PoolReconciler) rollbackPool(ctx context.Context, pool *sandboxv1alpha1.Pool) (bool, error) {
+	target, ok := pool.Annotations[AnnoPoolRollbackToKey]
+	if !ok {
+		return false, nil
+	}
+	revisions, err := r.listPoolRevisions(ctx, pool)
+	if err != nil {
+		return false, err
+	}
+	var found *appsv1.ControllerRevision
+	for _, cr := range revisions {
+		if cr.Labels[LabelPoolRevision] == target || strconv.FormatInt(cr.Revision, 10) == target || cr.Name == target {
+			found = cr
+		}
+	}
+
+	latest := pool.DeepCopy()
+	delete(latest.Annotations, AnnoPoolRollbackToKey)
+	template := &corev1.PodTemplateSpec{}
+	switch {
+	case found == nil:
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "RollbackFailed", "Revision %q not found", target)
+	case json.Unmarshal(found.Data.Raw, template) != nil:
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "RollbackFailed", "Revision %q holds an invalid pod template", target)
+		found = nil
+	default:
+		latest.Spec.Template = template
+		latest.Spec.TemplateFrom = nil
+	}
+	if err := r.Update(ctx, latest); err != nil {
+		return false, err
+	}
+	if found != nil {
+		r.Recorder.Eventf(pool, corev1.EventTypeNormal, "RolledBack", "Rolled back to revision %s (%d)", found.Labels[LabelPoolRevision], found.Revision)
+	}
+	return true, nil
+}
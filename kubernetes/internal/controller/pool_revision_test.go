@@ -0,0 +1,108 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestSyncPoolRevisions(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: types.UID("uid-pool")},
+		Spec:       sandboxv1alpha1.PoolSpec{RevisionHistoryLimit: ptr.To(int32(1))},
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	template := func(image string) *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: image}}}}
+	}
+	podAt := func(revision string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelPoolRevision: revision}}}
+	}
+	history := func() map[string]int64 {
+		revisions, err := r.listPoolRevisions(ctx, pool)
+		require.NoError(t, err)
+		numbers := map[string]int64{}
+		for _, cr := range revisions {
+			numbers[cr.Labels[LabelPoolRevision]] = cr.Revision
+		}
+		return numbers
+	}
+
+	require.NoError(t, r.syncPoolRevisions(ctx, pool, template("v1"), "a", nil))
+	require.NoError(t, r.syncPoolRevisions(ctx, pool, template("v2"), "b", []*corev1.Pod{podAt("a")}))
+	require.NoError(t, r.syncPoolRevisions(ctx, pool, template("v3"), "c", []*corev1.Pod{podAt("a")}))
+	assert.Equal(t, map[string]int64{"a": 1, "b": 2, "c": 3}, history(), "a is in use and b is within the limit")
+
+	// Returning to a past revision moves it to the end of the history.
+	require.NoError(t, r.syncPoolRevisions(ctx, pool, template("v2"), "b", nil))
+	assert.Equal(t, map[string]int64{"b": 4, "c": 3}, history())
+}
+
+func TestRollbackPool(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: types.UID("uid-pool")},
+		Spec: sandboxv1alpha1.PoolSpec{
+			TemplateFrom: &sandboxv1alpha1.PoolTemplateSource{ConfigMapKeyRef: sandboxv1alpha1.ConfigMapKeyReference{Name: "cm", Key: "template"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(pool).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: recorder}
+	good := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "good"}}}}
+	require.NoError(t, r.syncPoolRevisions(ctx, pool, good, "good", nil))
+	require.NoError(t, r.syncPoolRevisions(ctx, pool, &corev1.PodTemplateSpec{}, "bad", nil))
+
+	requestRollback := func(target string) *sandboxv1alpha1.Pool {
+		latest := &sandboxv1alpha1.Pool{}
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pool), latest))
+		latest.Annotations = map[string]string{AnnoPoolRollbackToKey: target}
+		require.NoError(t, c.Update(ctx, latest))
+		rolledBack, err := r.rollbackPool(ctx, latest)
+		require.NoError(t, err)
+		assert.True(t, rolledBack)
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pool), latest))
+		assert.NotContains(t, latest.Annotations, AnnoPoolRollbackToKey)
+		return latest
+	}
+
+	latest := requestRollback("missing")
+	assert.NotNil(t, latest.Spec.TemplateFrom, "an unknown revision leaves the pool alone")
+	assert.Contains(t, <-recorder.Events, "RollbackFailed")
+
+	latest = requestRollback("1")
+	assert.Nil(t, latest.Spec.TemplateFrom)
+	assert.Equal(t, good, latest.Spec.Template)
+	assert.Contains(t, <-recorder.Events, "RolledBack")
+
+	rolledBack, err := r.rollbackPool(ctx, latest)
+	require.NoError(t, err)
+	assert.False(t, rolledBack)
+}
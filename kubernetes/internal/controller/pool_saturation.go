@@ -0,0 +1,126 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	gerrors "errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/lifecycle"
+)
+
+const reasonPoolSaturated = "PoolSaturated"
+
+// poolShortfall returns how many of the pods that sandboxes still wait for cannot be created because the
+// pool is at capacitySpec.poolMax. While sandboxes wait, every idle pod is still warming up and will be
+// allocated once ready, so idle pods reduce the number of pods that have to be created.
+func poolShortfall(pool *sandboxv1alpha1.Pool, supplyCnt, idleCnt, totalCnt int32) int32 {
+	if supplyCnt <= 0 {
+		return 0
+	}
+	toCreate := max(supplyCnt-idleCnt, 0)
+	headroom := max(pool.Spec.CapacitySpec.PoolMax-totalCnt, 0)
+	return max(toCreate-headroom, 0)
+}
+
+// syncPoolSaturation reports a pool that cannot create the pods its sandboxes wait for. While there is a
+// shortfall, the Pool gets a PoolSaturated event on every reconcile and each waiting BatchSandbox gets the
+// PoolSaturated condition and an event when the condition is set. The condition is removed as soon as the
+// sandbox stops waiting or the pool has room again.
+func (r *PoolReconciler) syncPoolSaturation(ctx context.Context, pool *sandboxv1alpha1.Pool, batchSandboxes []*sandboxv1alpha1.BatchSandbox,
+	schedResult *ScheduleResult, totalCnt int32) error {
+	shortfall := poolShortfall(pool, schedResult.SupplyCnt, int32(len(schedResult.IdlePods)), totalCnt)
+	message := ""
+	if shortfall > 0 {
+		message = fmt.Sprintf("Pool %s is at poolMax %d and cannot create %d of the pods its sandboxes are waiting for",
+			pool.Name, pool.Spec.CapacitySpec.PoolMax, shortfall)
+		r.Recorder.Event(pool, corev1.EventTypeWarning, reasonPoolSaturated, message)
+	}
+
+	allocated := make(map[string]int32, len(batchSandboxes))
+	for _, sandboxName := range schedResult.LatestAllocation {
+		allocated[sandboxName]++
+	}
+	var errs []error
+	for _, sbx := range batchSandboxes {
+		// On-demand pods and fallback pools serve the replicas the pool could not.
+		overflow, _ := parseSandboxOverflow(sbx)
+		fallback, _ := parseSandboxFallback(sbx)
+		served := allocated[sbx.Name] + int32(len(overflow.Pods)) + fallback.Replicas
+		waiting := sbx.DeletionTimestamp == nil && !isPoolExhausted(sbx) &&
+			sbx.Spec.Replicas != nil && served < *sbx.Spec.Replicas
+		saturated := shortfall > 0 && waiting
+		want := ""
+		if saturated {
+			want = message
+		}
+		if hasPoolSaturatedCondition(sbx, want) {
+			continue
+		}
+		if err := r.setPoolSaturatedCondition(ctx, sbx, saturated, want); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if saturated {
+			r.Recorder.Event(sbx, corev1.EventTypeWarning, reasonPoolSaturated, message)
+			// The condition is only set when it changes, so the resource version tells apart the
+			// sandbox saturating again from a repeated notification.
+			notifyLifecycle(r.Lifecycle, lifecycle.PoolSaturated, sbx, nil, "", message, sbx.ResourceVersion)
+		}
+	}
+	return gerrors.Join(errs...)
+}
+
+// hasPoolSaturatedCondition reports whether the sandbox carries the PoolSaturated condition with the given
+// message, or carries none when message is empty.
+func hasPoolSaturatedCondition(batchSbx *sandboxv1alpha1.BatchSandbox, message string) bool {
+	for _, cond := range batchSbx.Status.Conditions {
+		if cond.Type == sandboxv1alpha1.BatchSandboxConditionPoolSaturated && cond.Status == sandboxv1alpha1.ConditionTrue {
+			return cond.Message == message
+		}
+	}
+	return message == ""
+}
+
+func (r *PoolReconciler) setPoolSaturatedCondition(ctx context.Context, batchSbx *sandboxv1alpha1.BatchSandbox, saturated bool, message string) error {
+	status := sandboxv1alpha1.ConditionFalse
+	reason := ""
+	if saturated {
+		status, reason = sandboxv1alpha1.ConditionTrue, reasonPoolSaturated
+	}
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &sandboxv1alpha1.BatchSandbox{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(batchSbx), latest); err != nil {
+			return err
+		}
+		setConditionInStatus(&latest.Status, sandboxv1alpha1.BatchSandboxConditionPoolSaturated, status, reason, message)
+		return r.Status().Update(ctx, latest)
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update PoolSaturated condition", "sandbox", batchSbx.Name)
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestPoolShortfall(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{PoolMax: 10}}}
+	tests := []struct {
+		name                      string
+		supply, idle, total, want int32
+	}{
+		{name: "no demand", supply: 0, idle: 0, total: 10, want: 0},
+		{name: "room to create", supply: 3, idle: 0, total: 7, want: 0},
+		{name: "clamped by poolMax", supply: 5, idle: 0, total: 8, want: 3},
+		{name: "warming pods cover part of the demand", supply: 5, idle: 2, total: 8, want: 1},
+		{name: "warming pods cover all of the demand", supply: 2, idle: 2, total: 10, want: 0},
+		{name: "over poolMax", supply: 2, idle: 0, total: 12, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, poolShortfall(pool, tt.supply, tt.idle, tt.total))
+		})
+	}
+}
+
+func TestSyncPoolSaturation(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{PoolMax: 2}},
+	}
+	waiting := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "waiting", Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To[int32](3), PoolRef: "pool"},
+	}
+	served := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "served", Namespace: "default"},
+		Spec:       sandboxv1alpha1.BatchSandboxSpec{Replicas: ptr.To[int32](1), PoolRef: "pool"},
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(waiting, served).WithStatusSubresource(waiting, served).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Client: c, Recorder: recorder}
+
+	get := func(name string) *sandboxv1alpha1.BatchSandbox {
+		sbx := &sandboxv1alpha1.BatchSandbox{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, sbx))
+		return sbx
+	}
+
+	// Both pods of the pool are allocated and "waiting" still needs two more.
+	saturated := &ScheduleResult{
+		LatestAllocation: map[string]string{"pod-a": "waiting", "pod-b": "served"},
+		SupplyCnt:        2,
+	}
+	require.NoError(t, r.syncPoolSaturation(ctx, pool, []*sandboxv1alpha1.BatchSandbox{waiting, served}, saturated, 2))
+	assert.True(t, isConditionTrue(get("waiting"), sandboxv1alpha1.BatchSandboxConditionPoolSaturated))
+	assert.False(t, isConditionTrue(get("served"), sandboxv1alpha1.BatchSandboxConditionPoolSaturated))
+	events := drainEvents(recorder)
+	require.Len(t, events, 2)
+	for _, e := range events {
+		assert.Contains(t, e, "PoolSaturated")
+		assert.Contains(t, e, "cannot create 2 of the pods")
+	}
+
+	// Unchanged saturation only repeats the Pool event.
+	require.NoError(t, r.syncPoolSaturation(ctx, pool, []*sandboxv1alpha1.BatchSandbox{get("waiting"), get("served")}, saturated, 2))
+	assert.Len(t, drainEvents(recorder), 1)
+
+	// The pool makes room and the condition is removed.
+	cleared := &ScheduleResult{LatestAllocation: saturated.LatestAllocation, SupplyCnt: 2}
+	pool.Spec.CapacitySpec.PoolMax = 4
+	require.NoError(t, r.syncPoolSaturation(ctx, pool, []*sandboxv1alpha1.BatchSandbox{get("waiting"), get("served")}, cleared, 2))
+	assert.False(t, isConditionTrue(get("waiting"), sandboxv1alpha1.BatchSandboxConditionPoolSaturated))
+	assert.Empty(t, drainEvents(recorder))
+}
+
+func TestMergePoolOwnedConditions(t *testing.T) {
+	podFailed := sandboxv1alpha1.BatchSandboxCondition{Type: sandboxv1alpha1.BatchSandboxConditionPodFailed, Status: sandboxv1alpha1.ConditionTrue}
+	staleSaturated := sandboxv1alpha1.BatchSandboxCondition{Type: sandboxv1alpha1.BatchSandboxConditionPoolSaturated, Status: sandboxv1alpha1.ConditionTrue, Message: "old"}
+	latestSaturated := sandboxv1alpha1.BatchSandboxCondition{Type: sandboxv1alpha1.BatchSandboxConditionPoolSaturated, Status: sandboxv1alpha1.ConditionTrue, Message: "new"}
+
+	merged := mergePoolOwnedConditions([]sandboxv1alpha1.BatchSandboxCondition{podFailed, staleSaturated}, []sandboxv1alpha1.BatchSandboxCondition{latestSaturated})
+	assert.Equal(t, []sandboxv1alpha1.BatchSandboxCondition{podFailed, latestSaturated}, merged)
+
+	merged = mergePoolOwnedConditions([]sandboxv1alpha1.BatchSandboxCondition{podFailed, staleSaturated}, nil)
+	assert.Equal(t, []sandboxv1alpha1.BatchSandboxCondition{podFailed}, merged)
+}
+
+func isConditionTrue(sbx *sandboxv1alpha1.BatchSandbox, conditionType sandboxv1alpha1.BatchSandboxConditionType) bool {
+	for _, cond := range sbx.Status.Conditions {
+		if cond.Type == conditionType {
+			return cond.Status == sandboxv1alpha1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// heldScaleIn sums up the scale-ins held for confirmation in a reconcile, over the pool template and its
+// flavors.
+type heldScaleIn struct {
+	pods  int32 // pods the held scale-ins would delete
+	of    int32 // pods they would delete them from
+	limit int32 // pods that could be deleted without confirmation
+}
+
+// maxScaleIn returns how many of the pods a scale-in may delete without confirmation, or false if the pool
+// does not limit scale-ins. A malformed limit holds every scale-in.
+func maxScaleIn(pool *sandboxv1alpha1.Pool, podCnt int32) (int32, bool) {
+	if pool.Spec.ScaleStrategy == nil || pool.Spec.ScaleStrategy.MaxScaleIn == nil {
+		return 0, false
+	}
+	limit, err := intstr.GetScaledValueFromIntOrPercent(pool.Spec.ScaleStrategy.MaxScaleIn, int(podCnt), true)
+	if err != nil || limit < 0 {
+		return 0, true
+	}
+	return int32(limit), true
+}
+
+// scaleInConfirmed reports whether the scale-ins of the current pool generation are confirmed.
+func scaleInConfirmed(pool *sandboxv1alpha1.Pool) bool {
+	return pool.Annotations[AnnoPoolConfirmScaleInKey] == strconv.FormatInt(pool.Generation, 10)
+}
+
+// guardScaleIn returns the part of a scale-in of podCnt pods that may go ahead: all of it within
+// maxScaleIn or once confirmed, none of it otherwise. Held scale-ins are added to held, if not nil.
+func guardScaleIn(pool *sandboxv1alpha1.Pool, podCnt, scaleIn int32, held *heldScaleIn) int32 {
+	limit, ok := maxScaleIn(pool, podCnt)
+	if !ok || scaleIn <= limit || scaleInConfirmed(pool) {
+		return scaleIn
+	}
+	if held != nil {
+		held.pods += scaleIn
+		held.of += podCnt
+		held.limit += limit
+	}
+	return 0
+}
+
+// setNeedsConfirmationCondition reports scale-ins held for confirmation. It returns true when the condition
+// turns True.
+func setNeedsConfirmationCondition(pool *sandboxv1alpha1.Pool, held *heldScaleIn) bool {
+	status := &pool.Status
+	if pool.Spec.ScaleStrategy == nil || pool.Spec.ScaleStrategy.MaxScaleIn == nil {
+		meta.RemoveStatusCondition(&status.Conditions, sandboxv1alpha1.PoolConditionNeedsConfirmation)
+		return false
+	}
+	cond := metav1.Condition{
+		Type:               sandboxv1alpha1.PoolConditionNeedsConfirmation,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ScaleInAllowed",
+		Message:            "No scale-in is waiting for confirmation",
+		ObservedGeneration: pool.Generation,
+	}
+	if held != nil && held.pods > 0 {
+		cond.Status, cond.Reason = metav1.ConditionTrue, "ScaleInHeld"
+		cond.Message = fmt.Sprintf("Scaling in would delete %d of %d pods, more than maxScaleIn allows (%d); "+
+			"annotate the pool with %s=%d to proceed", held.pods, held.of, held.limit, AnnoPoolConfirmScaleInKey, pool.Generation)
+	}
+	wasTrue := meta.IsStatusConditionTrue(status.Conditions, sandboxv1alpha1.PoolConditionNeedsConfirmation)
+	meta.SetStatusCondition(&status.Conditions, cond)
+	return cond.Status == metav1.ConditionTrue && !wasTrue
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func guardedPool(limit *intstr.IntOrString) *sandboxv1alpha1.Pool {
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "guarded", Namespace: "default", Generation: 3}}
+	if limit != nil {
+		pool.Spec.ScaleStrategy = &sandboxv1alpha1.ScaleStrategy{MaxScaleIn: limit}
+	}
+	return pool
+}
+
+func TestGuardScaleIn(t *testing.T) {
+	count, percent, invalid := intstr.FromInt32(5), intstr.FromString("25%"), intstr.FromString("many")
+	tests := []struct {
+		name    string
+		limit   *intstr.IntOrString
+		confirm string
+		scaleIn int32
+		want    int32
+	}{
+		{name: "no limit", scaleIn: 90, want: 90},
+		{name: "within count", limit: &count, scaleIn: 5, want: 5},
+		{name: "beyond count", limit: &count, scaleIn: 6, want: 0},
+		{name: "within percentage, rounded up", limit: &percent, scaleIn: 3, want: 3},
+		{name: "beyond percentage", limit: &percent, scaleIn: 4, want: 0},
+		{name: "confirmed", limit: &count, confirm: "3", scaleIn: 90, want: 90},
+		{name: "confirmed for an older generation", limit: &count, confirm: "2", scaleIn: 90, want: 0},
+		{name: "malformed limit holds", limit: &invalid, scaleIn: 1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := guardedPool(tt.limit)
+			if tt.confirm != "" {
+				pool.Annotations = map[string]string{AnnoPoolConfirmScaleInKey: tt.confirm}
+			}
+			held := &heldScaleIn{}
+			assert.Equal(t, tt.want, guardScaleIn(pool, 10, tt.scaleIn, held))
+			if tt.want < tt.scaleIn {
+				assert.Equal(t, tt.scaleIn, held.pods)
+				assert.Equal(t, int32(10), held.of)
+			} else {
+				assert.Zero(t, held.pods)
+			}
+		})
+	}
+}
+
+func TestSetNeedsConfirmationCondition(t *testing.T) {
+	pool := guardedPool(nil)
+	assert.False(t, setNeedsConfirmationCondition(pool, &heldScaleIn{pods: 5}))
+	assert.Nil(t, meta.FindStatusCondition(pool.Status.Conditions, sandboxv1alpha1.PoolConditionNeedsConfirmation), "only reported with maxScaleIn")
+
+	limit := intstr.FromInt32(5)
+	pool = guardedPool(&limit)
+	assert.False(t, setNeedsConfirmationCondition(pool, &heldScaleIn{}))
+	cond := meta.FindStatusCondition(pool.Status.Conditions, sandboxv1alpha1.PoolConditionNeedsConfirmation)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+
+	assert.True(t, setNeedsConfirmationCondition(pool, &heldScaleIn{pods: 495, of: 500, limit: 5}))
+	cond = meta.FindStatusCondition(pool.Status.Conditions, sandboxv1alpha1.PoolConditionNeedsConfirmation)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Contains(t, cond.Message, "delete 495 of 500 pods")
+	assert.Contains(t, cond.Message, AnnoPoolConfirmScaleInKey+"=3")
+	assert.False(t, setNeedsConfirmationCondition(pool, &heldScaleIn{pods: 495, of: 500, limit: 5}), "reported once")
+}
+
+func TestScalePoolHoldsLargeScaleIn(t *testing.T) {
+	ctx := context.Background()
+	limit := intstr.FromInt32(2)
+	maxUnavailable := intstr.FromString("100%")
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "typo", Namespace: "default", UID: types.UID("uid-typo"), Generation: 7},
+		Spec: sandboxv1alpha1.PoolSpec{
+			CapacitySpec:  sandboxv1alpha1.CapacitySpec{BufferMin: 1, BufferMax: 1, PoolMax: 10},
+			ScaleStrategy: &sandboxv1alpha1.ScaleStrategy{MaxScaleIn: &limit, MaxUnavailable: &maxUnavailable},
+		},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	var objs []client.Object
+	var pods []*corev1.Pod
+	var names []string
+	for i := range 6 {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("typo-%d", i), Namespace: "default"},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		}
+		objs = append(objs, pod)
+		pods = append(pods, pod)
+		names = append(names, pod.Name)
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	countPods := func() int {
+		list := &corev1.PodList{}
+		require.NoError(t, c.List(ctx, list))
+		return len(list.Items)
+	}
+
+	held := &heldScaleIn{}
+	require.NoError(t, r.scalePool(ctx, pool, &scaleArgs{
+		updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 6, idlePods: names, toDeletePods: names[:1], heldScaleIn: held,
+	}))
+	assert.Equal(t, 5, countPods(), "pods to delete go, the scale-in is held")
+	assert.Equal(t, int32(5), held.pods)
+
+	observePoolPodDeletions(controllerutils.GetControllerKey(pool), pods[1:])
+	pool.Annotations = map[string]string{AnnoPoolConfirmScaleInKey: "7"}
+	held = &heldScaleIn{}
+	require.NoError(t, r.scalePool(ctx, pool, &scaleArgs{
+		updateRevision: "rev", pods: pods[1:], allPods: pods[1:], totalPodCnt: 5, idlePods: names[1:], heldScaleIn: held,
+	}))
+	assert.Equal(t, 1, countPods(), "a confirmed scale-in goes ahead")
+	assert.Zero(t, held.pods)
+}
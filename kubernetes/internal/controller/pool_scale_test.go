@@ -0,0 +1,244 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func TestScalePoolReplicas(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	maxUnavailable := intstr.FromString("100%")
+	newPool := func(name string, replicas int32) *sandboxv1alpha1.Pool {
+		pool := &sandboxv1alpha1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+			Spec: sandboxv1alpha1.PoolSpec{
+				CapacitySpec:  sandboxv1alpha1.CapacitySpec{BufferMin: 2, BufferMax: 4, PoolMax: 10, Replicas: ptr.To(replicas)},
+				ScaleStrategy: &sandboxv1alpha1.ScaleStrategy{MaxUnavailable: &maxUnavailable},
+			},
+		}
+		t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+		return pool
+	}
+	newPods := func(pool *sandboxv1alpha1.Pool, n int) ([]client.Object, []*corev1.Pod, []string) {
+		var objs []client.Object
+		var pods []*corev1.Pod
+		var names []string
+		for i := range n {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", pool.Name, i), Namespace: "default"},
+				Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+			}
+			objs = append(objs, pod)
+			pods = append(pods, pod)
+			names = append(names, pod.Name)
+		}
+		return objs, pods, names
+	}
+	countPods := func(t *testing.T, c client.Client) int {
+		list := &corev1.PodList{}
+		require.NoError(t, c.List(context.Background(), list))
+		return len(list.Items)
+	}
+
+	t.Run("scale up to replicas ignoring buffer", func(t *testing.T) {
+		pool := newPool("replicas-up", 7)
+		c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+		r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+		require.NoError(t, r.scalePool(context.Background(), pool, &scaleArgs{template: template, updateRevision: "rev"}))
+		assert.Equal(t, 7, countPods(t, c))
+	})
+
+	t.Run("scale down to replicas", func(t *testing.T) {
+		pool := newPool("replicas-down", 2)
+		objs, pods, names := newPods(pool, 5)
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+		r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+		require.NoError(t, r.scalePool(context.Background(), pool, &scaleArgs{
+			template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 5, idlePods: names,
+		}))
+		assert.Equal(t, 2, countPods(t, c))
+	})
+
+	t.Run("allocated pods are kept below replicas", func(t *testing.T) {
+		pool := newPool("replicas-allocated", 1)
+		objs, pods, names := newPods(pool, 3)
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+		r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+		require.NoError(t, r.scalePool(context.Background(), pool, &scaleArgs{
+			template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 3, allocatedCnt: 2, idlePods: names[2:],
+		}))
+		assert.Equal(t, 2, countPods(t, c))
+	})
+}
+
+func TestSlowStartBatch(t *testing.T) {
+	var mu sync.Mutex
+	var called []int
+	record := func(fail map[int]bool) func(int) error {
+		called = nil
+		return func(index int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			called = append(called, index)
+			if fail[index] {
+				return fmt.Errorf("create %d", index)
+			}
+			return nil
+		}
+	}
+
+	succeeded, errs := slowStartBatch(10, 1, record(nil))
+	assert.Equal(t, 10, succeeded)
+	assert.Empty(t, errs)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, called)
+
+	// Batches of 1, 2 and 4: the failures in the third batch stop the fourth.
+	succeeded, errs = slowStartBatch(20, 1, record(map[int]bool{4: true, 5: true}))
+	assert.Equal(t, 5, succeeded)
+	assert.Len(t, errs, 2)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6}, called)
+
+	succeeded, errs = slowStartBatch(0, 1, record(nil))
+	assert.Zero(t, succeeded)
+	assert.Empty(t, errs)
+	assert.Empty(t, called)
+}
+
+func TestScalePoolSlowStart(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	maxUnavailable := intstr.FromString("100%")
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "slow-start", Namespace: "default", UID: "uid-slow-start"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			PodNamingStrategy: sandboxv1alpha1.PodNamingStrategyOrdinal,
+			CapacitySpec:      sandboxv1alpha1.CapacitySpec{PoolMin: 50, PoolMax: 100},
+			ScaleStrategy:     &sandboxv1alpha1.ScaleStrategy{MaxUnavailable: &maxUnavailable},
+		},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	var attempts atomic.Int32
+	forbidden := errors.New("exceeded quota")
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if attempts.Add(1) > 3 {
+				return forbidden
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(100)}
+
+	err := r.scalePool(context.Background(), pool, &scaleArgs{template: template, updateRevision: "rev"})
+	require.ErrorIs(t, err, forbidden)
+	assert.Equal(t, int32(7), attempts.Load(), "no batch starts after the batch of 4 failed")
+
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), list))
+	var names []string
+	for _, pod := range list.Items {
+		names = append(names, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"slow-start-0", "slow-start-1", "slow-start-2"}, names, "concurrent creations get distinct ordinals")
+}
+
+func TestScalePoolDeleteExpectations(t *testing.T) {
+	ctx := context.Background()
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "expect-delete", Namespace: "default", UID: types.UID("uid-expect-delete")},
+		Spec:       sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{BufferMin: 2, BufferMax: 2, PoolMax: 10}},
+	}
+	controllerKey := controllerutils.GetControllerKey(pool)
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerKey) })
+
+	var objs []client.Object
+	var pods []*corev1.Pod
+	var names []string
+	for i := range 4 {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("expect-delete-%d", i), Namespace: "default"},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		}
+		objs = append(objs, pod)
+		pods = append(pods, pod)
+		names = append(names, pod.Name)
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+	args := &scaleArgs{template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 4, idlePods: names}
+	require.NoError(t, r.scalePool(ctx, pool, args))
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, list))
+	require.Len(t, list.Items, 2)
+
+	// A reconcile on a stale pod list does not delete again until the deletions are observed.
+	observePoolPodDeletions(controllerKey, pods)
+	assert.Error(t, r.scalePool(ctx, pool, args))
+	require.NoError(t, c.List(ctx, list))
+	assert.Len(t, list.Items, 2)
+
+	var remaining []*corev1.Pod
+	for i := range list.Items {
+		remaining = append(remaining, &list.Items[i])
+	}
+	observePoolPodDeletions(controllerKey, remaining)
+	satisfied, _, _ := PoolScaleExpectations.SatisfiedExpectations(controllerKey)
+	assert.True(t, satisfied)
+}
+
+func TestPickPodsToDeleteDeletionCost(t *testing.T) {
+	now := metav1.Now()
+	newPod := func(name string, age time.Duration, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age)), Annotations: annotations,
+		}}
+	}
+	oldest := newPod("oldest", 3*time.Hour, nil)
+	cheap := newPod("cheap", time.Hour, map[string]string{corev1.PodDeletionCost: "-10"})
+	costly := newPod("costly", 4*time.Hour, map[string]string{corev1.PodDeletionCost: "100"})
+	overridden := newPod("overridden", 2*time.Hour, map[string]string{corev1.PodDeletionCost: "-100", AnnoPoolDeletionCostKey: "50"})
+	pods := []*corev1.Pod{oldest, cheap, costly, overridden}
+
+	r := &PoolReconciler{}
+	picked := r.pickPodsToDelete(pods, []string{"oldest", "cheap", "costly", "overridden"}, nil, 3, nil)
+	names := make([]string, 0, len(picked))
+	for _, pod := range picked {
+		names = append(names, pod.Name)
+	}
+	assert.Equal(t, []string{"cheap", "oldest", "overridden"}, names)
+}
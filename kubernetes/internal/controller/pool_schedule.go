@@ -0,0 +1,114 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	gerrors "errors"
+	"fmt"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/cron"
+)
+
+// scheduleBoundarySlack delays the requeue at a window boundary so the boundary has passed when
+// the pool is reconciled.
+const scheduleBoundarySlack = time.Second
+
+// CapacityResult is the capacity of a pool at some point in time.
+type CapacityResult struct {
+	Capacity sandboxv1alpha1.CapacitySpec
+	// ActiveWindow is the name of the schedule window in effect, empty if none is.
+	ActiveWindow string
+	// RequeueAfter is when the next window opens or the active one closes, zero without a schedule.
+	RequeueAfter time.Duration
+}
+
+// effectiveCapacity resolves the capacity schedule of the pool at now. Windows that cannot be parsed
+// are skipped and reported in the returned error, so a typo in one window does not stop scaling.
+func effectiveCapacity(pool *sandboxv1alpha1.Pool, now time.Time) (*CapacityResult, error) {
+	result := &CapacityResult{Capacity: pool.Spec.CapacitySpec}
+	result.Capacity.Schedule = nil
+	schedule := pool.Spec.CapacitySpec.Schedule
+	if schedule == nil || len(schedule.Windows) == 0 {
+		return result, nil
+	}
+
+	loc := time.UTC
+	if schedule.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(schedule.TimeZone); err != nil {
+			return result, fmt.Errorf("invalid time zone %q: %w", schedule.TimeZone, err)
+		}
+	}
+	now = now.In(loc)
+
+	var errs []error
+	var nextBoundary time.Time
+	for i := range schedule.Windows {
+		window := &schedule.Windows[i]
+		start, err := cron.Parse(window.Start)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("window %s: %w", window.Name, err))
+			continue
+		}
+		if window.Duration.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("window %s: duration must be positive", window.Name))
+			continue
+		}
+		// The earliest start within the last duration opened the window if it is not in the future.
+		boundary := start.Next(now.Add(-window.Duration.Duration))
+		if !boundary.IsZero() && !boundary.After(now) {
+			boundary = boundary.Add(window.Duration.Duration)
+			if result.ActiveWindow == "" {
+				result.ActiveWindow = window.Name
+				applyCapacityWindow(&result.Capacity, window)
+			}
+		} else {
+			boundary = start.Next(now)
+		}
+		if !boundary.IsZero() && (nextBoundary.IsZero() || boundary.Before(nextBoundary)) {
+			nextBoundary = boundary
+		}
+	}
+	if !nextBoundary.IsZero() {
+		result.RequeueAfter = nextBoundary.Sub(now) + scheduleBoundarySlack
+	}
+	return result, gerrors.Join(errs...)
+}
+
+func applyCapacityWindow(capacity *sandboxv1alpha1.CapacitySpec, window *sandboxv1alpha1.CapacityWindow) {
+	if window.BufferMax != nil {
+		capacity.BufferMax = *window.BufferMax
+	}
+	if window.BufferMin != nil {
+		capacity.BufferMin = *window.BufferMin
+	}
+	if window.PoolMax != nil {
+		capacity.PoolMax = *window.PoolMax
+	}
+	if window.PoolMin != nil {
+		capacity.PoolMin = *window.PoolMin
+	}
+}
+
+// requeueSooner makes the result requeue after the given duration unless it already requeues sooner.
+func requeueSooner(result *ctrl.Result, after time.Duration) {
+	if after > 0 && (result.RequeueAfter == 0 || after < result.RequeueAfter) {
+		result.RequeueAfter = after
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestEffectiveCapacity(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{
+		BufferMin: 1, BufferMax: 2, PoolMin: 0, PoolMax: 10,
+		Schedule: &sandboxv1alpha1.CapacitySchedule{Windows: []sandboxv1alpha1.CapacityWindow{
+			{Name: "business-hours", Start: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour},
+				BufferMin: ptr.To[int32](10), BufferMax: ptr.To[int32](20), PoolMax: ptr.To[int32](50)},
+			{Name: "batch", Start: "0 17 * * *", Duration: metav1.Duration{Duration: 4 * time.Hour}, PoolMin: ptr.To[int32](5)},
+		}},
+	}}}
+	// Wednesday 2025-03-12
+	at := func(hour, minute int) time.Time { return time.Date(2025, 3, 12, hour, minute, 0, 0, time.UTC) }
+
+	result, err := effectiveCapacity(pool, at(6, 0))
+	require.NoError(t, err)
+	assert.Empty(t, result.ActiveWindow)
+	assert.Equal(t, int32(10), result.Capacity.PoolMax)
+	assert.Nil(t, result.Capacity.Schedule)
+	assert.Equal(t, 2*time.Hour+scheduleBoundarySlack, result.RequeueAfter, "requeued when business hours open")
+
+	result, err = effectiveCapacity(pool, at(9, 30))
+	require.NoError(t, err)
+	assert.Equal(t, "business-hours", result.ActiveWindow)
+	assert.Equal(t, sandboxv1alpha1.CapacitySpec{BufferMin: 10, BufferMax: 20, PoolMin: 0, PoolMax: 50}, result.Capacity)
+	assert.Equal(t, 7*time.Hour+30*time.Minute+scheduleBoundarySlack, result.RequeueAfter, "requeued when the batch window opens")
+
+	// Both windows are open; the first listed applies until business hours close.
+	result, err = effectiveCapacity(pool, at(17, 30))
+	require.NoError(t, err)
+	assert.Equal(t, "business-hours", result.ActiveWindow)
+	assert.Equal(t, 30*time.Minute+scheduleBoundarySlack, result.RequeueAfter)
+
+	result, err = effectiveCapacity(pool, at(19, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "batch", result.ActiveWindow)
+	assert.Equal(t, sandboxv1alpha1.CapacitySpec{BufferMin: 1, BufferMax: 2, PoolMin: 5, PoolMax: 10}, result.Capacity)
+	assert.Equal(t, 2*time.Hour+scheduleBoundarySlack, result.RequeueAfter)
+
+	// A broken window is reported and skipped.
+	pool.Spec.CapacitySpec.Schedule.Windows[0].Start = "0 8 * *"
+	result, err = effectiveCapacity(pool, at(19, 0))
+	assert.ErrorContains(t, err, "business-hours")
+	assert.Equal(t, "batch", result.ActiveWindow)
+
+	pool.Spec.CapacitySpec.Schedule.TimeZone = "Mars/Olympus"
+	result, err = effectiveCapacity(pool, at(19, 0))
+	assert.Error(t, err)
+	assert.Empty(t, result.ActiveWindow)
+	assert.Equal(t, int32(10), result.Capacity.PoolMax)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+// resolvePoolTemplate returns the pod template of the pool: spec.template, or
+// the template read from the ConfigMap key named by spec.templateFrom.
+func resolvePoolTemplate(ctx context.Context, c client.Reader, pool *sandboxv1alpha1.Pool) (*corev1.PodTemplateSpec, error) {
+	switch {
+	case pool.Spec.Template != nil && pool.Spec.TemplateFrom != nil:
+		return nil, fmt.Errorf("spec.template and spec.templateFrom are mutually exclusive")
+	case pool.Spec.Template != nil:
+		return pool.Spec.Template, nil
+	case pool.Spec.TemplateFrom == nil:
+		return nil, fmt.Errorf("one of spec.template and spec.templateFrom is required")
+	}
+
+	ref := pool.Spec.TemplateFrom.ConfigMapKeyRef
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: pool.Namespace, Name: ref.Name}, cm); err != nil {
+		return nil, fmt.Errorf("failed to get template ConfigMap %s: %w", ref.Name, err)
+	}
+	raw, ok := cm.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("template ConfigMap %s has no key %q", ref.Name, ref.Key)
+	}
+	return parsePodTemplate([]byte(raw))
+}
+
+// parsePodTemplate decodes a PodTemplateSpec from YAML or JSON. Unknown fields
+// are rejected so a typo does not silently drop part of the template.
+func parsePodTemplate(data []byte) (*corev1.PodTemplateSpec, error) {
+	template := &corev1.PodTemplateSpec{}
+	if err := yaml.UnmarshalStrict(data, template); err != nil {
+		return nil, fmt.Errorf("invalid pod template: %w", err)
+	}
+	if len(template.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("invalid pod template: no containers")
+	}
+	return template, nil
+}
+
+// findPoolsForConfigMap maps a ConfigMap to the Pools sourcing their template from it.
+func (r *PoolReconciler) findPoolsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	pools := &sandboxv1alpha1.PoolList{}
+	if err := r.List(ctx, pools, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{fieldindex.IndexNameForTemplateConfigMap: obj.GetName()}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(pools.Items))
+	for i := range pools.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pools.Items[i])})
+	}
+	return requests
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/fieldindex"
+)
+
+const templateYAML = `
+metadata:
+  labels:
+    app: sandbox
+spec:
+  containers:
+  - name: main
+    image: python:3.12
+`
+
+func templateFromPool(name, cm, key string) *sandboxv1alpha1.Pool {
+	return &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			TemplateFrom: &sandboxv1alpha1.PoolTemplateSource{
+				ConfigMapKeyRef: sandboxv1alpha1.ConfigMapKeyReference{Name: cm, Key: key},
+			},
+		},
+	}
+}
+
+func TestResolvePoolTemplate(t *testing.T) {
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "templates", Namespace: "default"},
+		Data: map[string]string{
+			"python":  templateYAML,
+			"typo":    "spec:\n  containerz: []\n",
+			"invalid": "spec: [",
+		},
+	}
+	r := &PoolReconciler{Client: fake.NewClientBuilder().WithScheme(testscheme).WithObjects(cm).Build()}
+
+	template, err := resolvePoolTemplate(ctx, r.Client, templateFromPool("p", "templates", "python"))
+	require.NoError(t, err)
+	assert.Equal(t, "python:3.12", template.Spec.Containers[0].Image)
+	assert.Equal(t, "sandbox", template.Labels["app"])
+
+	// The revision only depends on the content, not on where the template is stored.
+	inline := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{Template: template.DeepCopy()}}
+	inlineTemplate, err := resolvePoolTemplate(ctx, r.Client, inline)
+	require.NoError(t, err)
+	fromRevision, err := r.calculateRevision(template)
+	require.NoError(t, err)
+	inlineRevision, err := r.calculateRevision(inlineTemplate)
+	require.NoError(t, err)
+	assert.Equal(t, inlineRevision, fromRevision)
+
+	for name, pool := range map[string]*sandboxv1alpha1.Pool{
+		"missing configmap": templateFromPool("p", "absent", "python"),
+		"missing key":       templateFromPool("p", "templates", "go"),
+		"unknown field":     templateFromPool("p", "templates", "typo"),
+		"invalid yaml":      templateFromPool("p", "templates", "invalid"),
+		"no template":       {Spec: sandboxv1alpha1.PoolSpec{}},
+		"both set": {Spec: sandboxv1alpha1.PoolSpec{
+			Template:     template,
+			TemplateFrom: templateFromPool("p", "templates", "python").Spec.TemplateFrom,
+		}},
+	} {
+		_, err := resolvePoolTemplate(ctx, r.Client, pool)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFindPoolsForConfigMap(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testscheme).
+		WithIndex(&sandboxv1alpha1.Pool{}, fieldindex.IndexNameForTemplateConfigMap, fieldindex.TemplateConfigMapIndexFunc).
+		WithObjects(
+			templateFromPool("a", "templates", "python"),
+			templateFromPool("b", "templates", "go"),
+			templateFromPool("c", "other", "python"),
+			&sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "inline", Namespace: "default"}},
+		).Build()
+	r := &PoolReconciler{Client: c}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "templates", Namespace: "default"}}
+	requests := r.findPoolsForConfigMap(context.Background(), cm)
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}},
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "b"}},
+	}, requests)
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+// applyPoolTopologySpread returns the template with the topology spread constraints of the pool,
+// leaving the template untouched when the pool has none. A constraint of the template for the same
+// topology key and unsatisfiable policy wins over the one of the pool.
+func applyPoolTopologySpread(pool *sandboxv1alpha1.Pool, template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	if template == nil || len(pool.Spec.TopologySpreadConstraints) == 0 {
+		return template
+	}
+	out := template.DeepCopy()
+	for _, constraint := range pool.Spec.TopologySpreadConstraints {
+		exists := false
+		for _, c := range out.Spec.TopologySpreadConstraints {
+			if c.TopologyKey == constraint.TopologyKey && c.WhenUnsatisfiable == constraint.WhenUnsatisfiable {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+		constraint = *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{LabelPoolName: pool.Name}}
+		}
+		out.Spec.TopologySpreadConstraints = append(out.Spec.TopologySpreadConstraints, constraint)
+	}
+	return out
+}
+
+// podTopologyDomains returns pod name -> the domain of the pod for each topology spread constraint
+// of the pool. Pods not bound to a node have no entry.
+func podTopologyDomains(ctx context.Context, c client.Client, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod) (map[string][]string, error) {
+	keys := make([]string, 0, len(pool.Spec.TopologySpreadConstraints))
+	for _, constraint := range pool.Spec.TopologySpreadConstraints {
+		keys = append(keys, constraint.TopologyKey)
+	}
+	return podNodeLabels(ctx, c, pods, keys)
+}
+
+// podNodeLabels returns pod name -> the value of each of the keys in the labels of the node of the
+// pod. Pods not bound to a node have no entry.
+func podNodeLabels(ctx context.Context, c client.Client, pods []*corev1.Pod, keys []string) (map[string][]string, error) {
+	nodes := make(map[string]*corev1.Node)
+	values := make(map[string][]string, len(pods))
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			continue
+		}
+		node, ok := nodes[nodeName]
+		if !ok {
+			node = &corev1.Node{}
+			if err := c.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, err
+				}
+				node = nil
+			}
+			nodes[nodeName] = node
+		}
+		podValues := make([]string, len(keys))
+		for i, key := range keys {
+			switch {
+			case node != nil:
+				podValues[i] = node.Labels[key]
+			case key == corev1.LabelHostname:
+				podValues[i] = nodeName
+			}
+		}
+		values[pod.Name] = podValues
+	}
+	return values, nil
+}
+
+// pickSpreadPods picks scaleIn of the idle pods to delete so that the pods that stay are spread
+// evenly: pods not bound to a node go first, then pods of the most populated domain of the first
+// constraint, with the later constraints breaking ties. Pods in skip are deleted already and not
+// counted. Idle pods keep their order otherwise, so the oldest of equal pods goes first.
+func pickSpreadPods(pods []*corev1.Pod, idlePods []*corev1.Pod, skip sets.Set[string], domains map[string][]string, scaleIn int32) []*corev1.Pod {
+	var counts []map[string]int
+	for _, pod := range pods {
+		podDomains, ok := domains[pod.Name]
+		if !ok || skip.Has(pod.Name) {
+			continue
+		}
+		for i, domain := range podDomains {
+			if i == len(counts) {
+				counts = append(counts, make(map[string]int))
+			}
+			counts[i][domain]++
+		}
+	}
+	// more reports whether a is a better pick than b.
+	more := func(a, b *corev1.Pod) bool {
+		aDomains, aBound := domains[a.Name]
+		bDomains, bBound := domains[b.Name]
+		if aBound != bBound {
+			return !aBound
+		}
+		for i := range aDomains {
+			if i >= len(bDomains) {
+				break
+			}
+			if ca, cb := counts[i][aDomains[i]], counts[i][bDomains[i]]; ca != cb {
+				return ca > cb
+			}
+		}
+		return false
+	}
+
+	candidates := make([]*corev1.Pod, 0, len(idlePods))
+	for _, pod := range idlePods {
+		if pod.DeletionTimestamp != nil {
+			// The pod is on its way out already.
+			scaleIn--
+			continue
+		}
+		candidates = append(candidates, pod)
+	}
+	var picked []*corev1.Pod
+	for ; scaleIn > 0 && len(candidates) > 0; scaleIn-- {
+		best := 0
+		for i := 1; i < len(candidates); i++ {
+			if more(candidates[i], candidates[best]) {
+				best = i
+			}
+		}
+		pod := candidates[best]
+		candidates = append(candidates[:best], candidates[best+1:]...)
+		picked = append(picked, pod)
+		for i, domain := range domains[pod.Name] {
+			counts[i][domain]--
+		}
+	}
+	return picked
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func TestApplyPoolTopologySpread(t *testing.T) {
+	zone := corev1.TopologySpreadConstraint{MaxSkew: 1, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.ScheduleAnyway}
+	host := corev1.TopologySpreadConstraint{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.DoNotSchedule}
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool"},
+		Spec:       sandboxv1alpha1.PoolSpec{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{zone, host}},
+	}
+	ownHost := host
+	ownHost.MaxSkew = 3
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{ownHost}}}
+
+	out := applyPoolTopologySpread(pool, template)
+	require.Len(t, out.Spec.TopologySpreadConstraints, 2)
+	assert.Equal(t, int32(3), out.Spec.TopologySpreadConstraints[0].MaxSkew, "the constraint of the template wins")
+	assert.Equal(t, corev1.LabelTopologyZone, out.Spec.TopologySpreadConstraints[1].TopologyKey)
+	assert.Equal(t, map[string]string{LabelPoolName: "pool"}, out.Spec.TopologySpreadConstraints[1].LabelSelector.MatchLabels)
+	assert.Len(t, template.Spec.TopologySpreadConstraints, 1, "the template is not modified")
+	assert.Nil(t, pool.Spec.TopologySpreadConstraints[0].LabelSelector, "the pool is not modified")
+
+	assert.Same(t, template, applyPoolTopologySpread(&sandboxv1alpha1.Pool{}, template))
+	assert.Nil(t, applyPoolTopologySpread(pool, nil))
+}
+
+func TestScalePoolSpreadsScaleIn(t *testing.T) {
+	maxUnavailable := intstr.FromString("100%")
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "spread", Namespace: "default", UID: types.UID("uid-spread")},
+		Spec: sandboxv1alpha1.PoolSpec{
+			CapacitySpec:              sandboxv1alpha1.CapacitySpec{BufferMin: 3, BufferMax: 3, PoolMax: 10},
+			ScaleStrategy:             &sandboxv1alpha1.ScaleStrategy{MaxUnavailable: &maxUnavailable},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.ScheduleAnyway}},
+		},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	objs := []client.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{corev1.LabelTopologyZone: "a"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{corev1.LabelTopologyZone: "b"}}},
+	}
+	var pods []*corev1.Pod
+	var idle []string
+	// Zone a holds pods 0-3, zone b pods 4-5; pod 5 is the oldest.
+	for i := range 6 {
+		node := "node-a"
+		if i >= 4 {
+			node = "node-b"
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("spread-%d", i), Namespace: "default",
+				CreationTimestamp: metav1.NewTime(time.Unix(int64(1000-i), 0)),
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		}
+		objs = append(objs, pod)
+		pods = append(pods, pod)
+		idle = append(idle, pod.Name)
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	// Without spreading the three oldest pods would go, emptying zone b.
+	require.NoError(t, r.scalePool(context.Background(), pool, &scaleArgs{updateRevision: "rev", pods: pods, totalPodCnt: 6, idlePods: idle}))
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), list))
+	zones := map[string]int{}
+	for _, pod := range list.Items {
+		zones[pod.Spec.NodeName]++
+	}
+	assert.Equal(t, map[string]int{"node-a": 2, "node-b": 1}, zones)
+}
+
+func TestPickSpreadPods(t *testing.T) {
+	newPod := func(name string) *corev1.Pod { return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}} }
+	a1, a2, a3, b1, pending, deleting := newPod("a1"), newPod("a2"), newPod("a3"), newPod("b1"), newPod("pending"), newPod("deleting")
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	pods := []*corev1.Pod{a1, a2, a3, b1, pending, deleting}
+	domains := map[string][]string{
+		"a1": {"zone-a", "node-1"}, "a2": {"zone-a", "node-2"}, "a3": {"zone-a", "node-2"},
+		"b1": {"zone-b", "node-3"}, "deleting": {"zone-b", "node-3"},
+	}
+
+	picked := pickSpreadPods(pods, []*corev1.Pod{deleting, a1, b1, a2, a3, pending}, nil, domains, 3)
+	var names []string
+	for _, pod := range picked {
+		names = append(names, pod.Name)
+	}
+	// The deleting pod counts towards the scale-in, the unbound pod goes first, then the node with
+	// most pods in the zone with most pods.
+	assert.Equal(t, []string{"pending", "a2"}, names)
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
+)
+
+const defaultUnhealthyPodTimeout = 5 * time.Minute
+
+// HealthResult is the outcome of checking the health of the idle pods of a pool.
+type HealthResult struct {
+	// IdlePods are the idle pods that stay.
+	IdlePods []string
+	// ToDeletePods are the unhealthy idle pods to replace.
+	ToDeletePods []string
+	// RequeueAfter is when the next NotReady idle pod reaches the unhealthy timeout, zero if none will.
+	RequeueAfter time.Duration
+}
+
+func unhealthyPodTimeout(pool *sandboxv1alpha1.Pool) time.Duration {
+	if pool.Spec.UnhealthyPodTimeout == nil {
+		return defaultUnhealthyPodTimeout
+	}
+	return pool.Spec.UnhealthyPodTimeout.Duration
+}
+
+// podUnhealthyReason returns why the pod can no longer serve, or an empty reason if it may still
+// become ready. A running pod that is NotReady is unhealthy once it has been so for timeout; the
+// returned duration is how long until then.
+func podUnhealthyReason(pod *corev1.Pod, timeout time.Duration, now time.Time) (string, time.Duration) {
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		if pod.Status.Reason != "" {
+			return pod.Status.Reason, 0
+		}
+		return string(corev1.PodFailed), 0
+	case corev1.PodSucceeded:
+		return string(corev1.PodSucceeded), 0
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				return cs.State.Waiting.Reason, 0
+			}
+		}
+	}
+	if timeout <= 0 || pod.Status.Phase != corev1.PodRunning || utils.IsPodReady(pod) {
+		return "", 0
+	}
+	_, cond := utils.GetPodCondition(&pod.Status, corev1.PodReady)
+	if cond == nil {
+		return "", 0
+	}
+	if wait := cond.LastTransitionTime.Add(timeout).Sub(now); wait > 0 {
+		return "", wait
+	}
+	return "NotReady", 0
+}
+
+// replaceUnhealthyPods picks the idle pods that failed, were evicted, crash loop, stayed NotReady
+// past the unhealthy timeout or were not scheduled within the pending timeout for replacement. They do not count towards the available buffer, so
+// all of them are replaced at once.
+func (r *PoolReconciler) replaceUnhealthyPods(ctx context.Context, pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, idlePods []string, now time.Time) *HealthResult {
+	result := &HealthResult{IdlePods: make([]string, 0, len(idlePods))}
+	podMap := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podMap[pod.Name] = pod
+	}
+	timeout := unhealthyPodTimeout(pool)
+	pendingTimeout := pendingPodTimeout(pool)
+	for _, name := range idlePods {
+		pod, ok := podMap[name]
+		if !ok || pod.DeletionTimestamp != nil {
+			result.IdlePods = append(result.IdlePods, name)
+			continue
+		}
+		reason, wait := podUnhealthyReason(pod, timeout, now)
+		if reason == "" && wait == 0 {
+			reason, wait = podPendingReason(pod, pendingTimeout, now)
+		}
+		if reason == "" {
+			if wait > 0 && (result.RequeueAfter == 0 || wait < result.RequeueAfter) {
+				result.RequeueAfter = wait
+			}
+			result.IdlePods = append(result.IdlePods, name)
+			continue
+		}
+		result.ToDeletePods = append(result.ToDeletePods, name)
+		r.Recorder.Eventf(pool, corev1.EventTypeWarning, "UnhealthyPod", "Replacing unhealthy idle pod %s: %s", name, reason)
+	}
+
+	if len(result.ToDeletePods) > 0 {
+		logf.FromContext(ctx).Info("Replacing unhealthy idle pods", "pool", pool.Name,
+			"toDeletePods", result.ToDeletePods, "idlePods", len(result.IdlePods))
+	}
+	return result
+}
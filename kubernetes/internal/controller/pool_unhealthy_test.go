@@ -0,0 +1,66 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func TestReplaceUnhealthyPods(t *testing.T) {
+	now := time.Now()
+	notReadySince := func(name string, since time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(now.Add(-since))}},
+			},
+		}
+	}
+	healthy := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+	evicted := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "evicted"}, Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}
+	crashing := notReadySince("crashing", time.Second)
+	crashing.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "main", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}
+	starting := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "starting"}, Status: corev1.PodStatus{Phase: corev1.PodPending}}
+	pods := []*corev1.Pod{healthy, evicted, crashing, starting, notReadySince("stuck", 10*time.Minute), notReadySince("flapping", 4*time.Minute)}
+	idle := []string{"healthy", "evicted", "crashing", "starting", "stuck", "flapping"}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &PoolReconciler{Recorder: recorder}
+	pool := &sandboxv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	result := r.replaceUnhealthyPods(context.Background(), pool, pods, idle, now)
+	assert.Equal(t, []string{"evicted", "crashing", "stuck"}, result.ToDeletePods)
+	assert.Equal(t, []string{"healthy", "starting", "flapping"}, result.IdlePods)
+	assert.Equal(t, time.Minute, result.RequeueAfter, "the flapping pod reaches the default timeout in a minute")
+	assert.Contains(t, <-recorder.Events, "Evicted")
+
+	// A zero timeout still replaces failed and crash looping pods.
+	pool.Spec.UnhealthyPodTimeout = &metav1.Duration{}
+	result = r.replaceUnhealthyPods(context.Background(), pool, pods, idle, now)
+	assert.Equal(t, []string{"evicted", "crashing"}, result.ToDeletePods)
+	assert.Zero(t, result.RequeueAfter)
+}
@@ -16,10 +16,13 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
@@ -41,19 +44,47 @@ func getUpdateMaxUnavailable(pool *sandboxv1alpha1.Pool, desiredTotal int32) int
 		maxUnavailable = pool.Spec.UpdateStrategy.MaxUnavailable
 	}
 	result, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, int(desiredTotal), true)
+	// Without surge pods a zero budget would never update an available pod.
+	if err == nil && result == 0 && getUpdateMaxSurge(pool, desiredTotal) > 0 {
+		return 0
+	}
 	if err != nil || result < 1 {
 		result = 1
 	}
 	return int32(result)
 }
 
+func getUpdateMaxSurge(pool *sandboxv1alpha1.Pool, desiredTotal int32) int32 {
+	if pool.Spec.UpdateStrategy == nil || pool.Spec.UpdateStrategy.MaxSurge == nil {
+		return 0
+	}
+	result, err := intstr.GetScaledValueFromIntOrPercent(pool.Spec.UpdateStrategy.MaxSurge, int(desiredTotal), true)
+	if err != nil || result < 0 {
+		return 0
+	}
+	return int32(result)
+}
+
+func getUpdatePartition(pool *sandboxv1alpha1.Pool) int32 {
+	if pool.Spec.UpdateStrategy == nil || pool.Spec.UpdateStrategy.Partition == nil {
+		return 0
+	}
+	return *pool.Spec.UpdateStrategy.Partition
+}
+
 type recreateUpdateStrategy struct {
 	pool *sandboxv1alpha1.Pool
 }
 
+// Compute replaces idle pods of previous revisions. Unavailable ones are deleted right away and
+// available ones within the maxUnavailable budget, each with a new pod supplied in its place. Beyond
+// that budget up to maxSurge idle pods are marked and a new pod is supplied for each; a marked pod
+// stays idle until the new pods are available, and is then deleted without another supply. Partition
+// old pods, allocated ones included, are never replaced.
 func (s *recreateUpdateStrategy) Compute(ctx context.Context, updateRevision string, pods []*corev1.Pod, idlePods []string) *UpdateResult {
 	log := logf.FromContext(ctx)
 	maxUnavailable := getUpdateMaxUnavailable(s.pool, int32(len(pods)))
+	maxSurge := getUpdateMaxSurge(s.pool, int32(len(pods)))
 
 	podMap := make(map[string]*corev1.Pod, len(pods))
 	for _, pod := range pods {
@@ -61,17 +92,29 @@ func (s *recreateUpdateStrategy) Compute(ctx context.Context, updateRevision str
 	}
 
 	curUnavailable := int32(0)
+	oldCnt := int32(0)
+	newUnavailable := int32(0)
 	for _, pod := range pods {
-		if !utils.IsPodReady(pod) {
+		available := isPodAvailable(s.pool, pod)
+		if !available {
 			curUnavailable++
 		}
+		if pod.Labels[LabelPoolRevision] != updateRevision {
+			oldCnt++
+		} else if !available {
+			newUnavailable++
+		}
 	}
 	unavailableBudget := max(maxUnavailable-curUnavailable, 0)
 
 	idlePodList := make([]*corev1.Pod, 0, len(idlePods))
+	surgeReplaced := int32(0)
 	for _, name := range idlePods {
 		if pod, ok := podMap[name]; ok {
 			idlePodList = append(idlePodList, pod)
+			if isSurgeReplaced(pod, updateRevision) {
+				surgeReplaced++
+			}
 		}
 	}
 
@@ -79,7 +122,15 @@ func (s *recreateUpdateStrategy) Compute(ctx context.Context, updateRevision str
 		return utils.ComparePodsForDeletion(idlePodList[i], idlePodList[j])
 	})
 
+	// Marked pods already have their replacement requested.
+	replaceBudget := max(oldCnt-getUpdatePartition(s.pool)-surgeReplaced, 0)
+	// New pods that are not available yet may still be the replacements of marked pods.
+	retireBudget := max(surgeReplaced-newUnavailable, 0)
+	surgeBudget := max(maxSurge-surgeReplaced, 0)
+
 	toDeleteCurRevPods := make([]string, 0)
+	toSurgePods := make([]string, 0)
+	suppliedPods := make([]string, 0)
 	supplyNew := int32(0)
 	remainingIdlePods := make([]string, 0)
 
@@ -88,26 +139,86 @@ func (s *recreateUpdateStrategy) Compute(ctx context.Context, updateRevision str
 			remainingIdlePods = append(remainingIdlePods, pod.Name)
 			continue
 		}
-		if !utils.IsPodReady(pod) {
+		if isSurgeReplaced(pod, updateRevision) {
+			if retireBudget > 0 {
+				toDeleteCurRevPods = append(toDeleteCurRevPods, pod.Name)
+				retireBudget--
+			} else {
+				remainingIdlePods = append(remainingIdlePods, pod.Name)
+			}
+			continue
+		}
+		switch {
+		case replaceBudget <= 0:
+			remainingIdlePods = append(remainingIdlePods, pod.Name)
+		case !isPodAvailable(s.pool, pod):
 			toDeleteCurRevPods = append(toDeleteCurRevPods, pod.Name)
+			suppliedPods = append(suppliedPods, pod.Name)
 			supplyNew++
-		} else if unavailableBudget > 0 {
+			replaceBudget--
+		case unavailableBudget > 0:
 			toDeleteCurRevPods = append(toDeleteCurRevPods, pod.Name)
+			suppliedPods = append(suppliedPods, pod.Name)
 			supplyNew++
 			unavailableBudget--
-		} else {
+			replaceBudget--
+		case surgeBudget > 0:
+			toSurgePods = append(toSurgePods, pod.Name)
+			remainingIdlePods = append(remainingIdlePods, pod.Name)
+			suppliedPods = append(suppliedPods, pod.Name)
+			supplyNew++
+			surgeBudget--
+			replaceBudget--
+		default:
 			remainingIdlePods = append(remainingIdlePods, pod.Name)
 		}
 	}
 
-	if len(toDeleteCurRevPods) > 0 {
+	if len(toDeleteCurRevPods) > 0 || len(toSurgePods) > 0 {
 		log.Info("Recreate update: to recreate current revision pods", "updateRevision", updateRevision,
-			"maxUnavailable", maxUnavailable, "curUnavailable", curUnavailable,
-			"toDeleteCurrentRevisionPods", toDeleteCurRevPods, "supplyNew", supplyNew, "idlePods", len(remainingIdlePods))
+			"maxUnavailable", maxUnavailable, "curUnavailable", curUnavailable, "maxSurge", maxSurge,
+			"toDeleteCurrentRevisionPods", toDeleteCurRevPods, "toSurgePods", toSurgePods,
+			"supplyNew", supplyNew, "idlePods", len(remainingIdlePods))
 	}
 	return &UpdateResult{
 		IdlePods:             remainingIdlePods,
 		ToDeletePods:         toDeleteCurRevPods,
+		ToSurgePods:          toSurgePods,
 		SupplyUpdateRevision: supplyNew,
+		SuppliedPods:         suppliedPods,
+	}
+}
+
+// isSurgeReplaced reports whether a replacement of updateRevision has been requested for the pod.
+// Marks left by an earlier update are ignored.
+func isSurgeReplaced(pod *corev1.Pod, updateRevision string) bool {
+	return pod.Annotations[AnnoPoolSurgeReplacedKey] == updateRevision
+}
+
+// markSurgeReplaced records on the named pods that their replacement of updateRevision has been
+// requested, so later reconciles delete them instead of supplying again.
+func (r *PoolReconciler) markSurgeReplaced(ctx context.Context, pods []*corev1.Pod, names []string, updateRevision string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	podMap := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podMap[pod.Name] = pod
+	}
+	var errs []error
+	for _, name := range names {
+		pod, ok := podMap[name]
+		if !ok {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[AnnoPoolSurgeReplacedKey] = updateRevision
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			errs = append(errs, fmt.Errorf("failed to mark pod %s for surge replacement: %w", name, err))
+		}
 	}
+	return errors.Join(errs...)
 }
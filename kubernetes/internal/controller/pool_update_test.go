@@ -22,6 +22,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
 )
@@ -85,6 +86,19 @@ func TestResolveMaxUnavailable(t *testing.T) {
 			desiredTotal: 10,
 			want:         1,
 		},
+		{
+			name: "absolute value 0 is kept with maxSurge",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{
+					UpdateStrategy: &sandboxv1alpha1.UpdateStrategy{
+						MaxUnavailable: intStrIntPtr(0),
+						MaxSurge:       intStrIntPtr(1),
+					},
+				},
+			},
+			desiredTotal: 10,
+			want:         0,
+		},
 		{
 			name: "percentage rounds up - 10% of 9 = 1",
 			pool: &sandboxv1alpha1.Pool{
@@ -256,6 +270,26 @@ func TestRecreateUpdateStrategy_Compute(t *testing.T) {
 			wantDeletePods: []string{},
 			wantSupplyNew:  0,
 		},
+		{
+			name: "partition keeps old pods, allocated ones included",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{
+					UpdateStrategy: &sandboxv1alpha1.UpdateStrategy{
+						MaxUnavailable: intStrPtr("100%"),
+						Partition:      ptr.To(int32(2)),
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				makePod("pod-1", "v1", true, false),
+				makePod("pod-2", "v1", true, true),
+				makePod("pod-3", "v1", true, true),
+			},
+			idlePods:       []string{"pod-2", "pod-3"},
+			wantIdlePods:   []string{"pod-2"},
+			wantDeletePods: []string{"pod-3"},
+			wantSupplyNew:  1,
+		},
 		{
 			name: "empty idle pods",
 			pool: &sandboxv1alpha1.Pool{
@@ -355,6 +389,56 @@ func TestRecreateUpdateStrategy_Compute_Sorting(t *testing.T) {
 	}
 }
 
+func TestRecreateUpdateStrategy_Compute_Surge(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{
+		Spec: sandboxv1alpha1.PoolSpec{
+			UpdateStrategy: &sandboxv1alpha1.UpdateStrategy{
+				MaxUnavailable: intStrIntPtr(0),
+				MaxSurge:       intStrIntPtr(1),
+			},
+		},
+	}
+	strategy := &recreateUpdateStrategy{pool: pool}
+	resetPodCounter()
+	// Newer pods are replaced first.
+	pods := []*v1.Pod{
+		makePod("pod-2", "v1", true, true),
+		makePod("pod-1", "v1", true, true),
+	}
+
+	// The first pod is marked and a surge pod supplied; nothing is deleted.
+	result := strategy.Compute(ctx, "v2", pods, []string{"pod-1", "pod-2"})
+	if !stringSlicesEqual(result.ToSurgePods, []string{"pod-1"}) || len(result.ToDeletePods) != 0 || result.SupplyUpdateRevision != 1 {
+		t.Fatalf("first round = %+v, want pod-1 marked and one pod supplied", result)
+	}
+	if !stringSlicesEqualUnordered(result.IdlePods, []string{"pod-1", "pod-2"}) {
+		t.Errorf("IdlePods = %v, a marked pod stays allocatable", result.IdlePods)
+	}
+	pods[1].Annotations = map[string]string{AnnoPoolSurgeReplacedKey: "v2"}
+
+	// While the surge pod starts, the marked pod is kept and no other pod is marked.
+	starting := makePod("pod-3", "v2", false, true)
+	result = strategy.Compute(ctx, "v2", append(pods, starting), []string{"pod-1", "pod-2", "pod-3"})
+	if len(result.ToSurgePods) != 0 || len(result.ToDeletePods) != 0 || result.SupplyUpdateRevision != 0 {
+		t.Fatalf("second round = %+v, want no change", result)
+	}
+
+	// Once it is available the marked pod is deleted without another supply.
+	ready := makePod("pod-3", "v2", true, true)
+	result = strategy.Compute(ctx, "v2", append(pods, ready), []string{"pod-1", "pod-2", "pod-3"})
+	if !stringSlicesEqual(result.ToDeletePods, []string{"pod-1"}) || len(result.ToSurgePods) != 0 || result.SupplyUpdateRevision != 0 {
+		t.Fatalf("third round = %+v, want pod-1 deleted", result)
+	}
+
+	// A mark left by an earlier update does not count.
+	pods[1].Annotations[AnnoPoolSurgeReplacedKey] = "v1.5"
+	result = strategy.Compute(ctx, "v2", pods, []string{"pod-1", "pod-2"})
+	if !stringSlicesEqual(result.ToSurgePods, []string{"pod-1"}) {
+		t.Errorf("ToSurgePods = %v, want the stale mark replaced", result.ToSurgePods)
+	}
+}
+
 var podCreationCounter int
 
 func makePod(name, revision string, ready, idle bool) *v1.Pod {
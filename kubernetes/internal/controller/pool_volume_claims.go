@@ -0,0 +1,95 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// volumeClaimName is the name of the claim a pod gets from a volume claim template.
+func volumeClaimName(template, pod string) string {
+	return template + "-" + pod
+}
+
+// nameForVolumeClaims names a pod that the API server would have named, since its volumes reference
+// claims named after the pod.
+func nameForVolumeClaims(pool *sandboxv1alpha1.Pool, pod *corev1.Pod) {
+	if len(pool.Spec.VolumeClaimTemplates) == 0 || pod.Name != "" {
+		return
+	}
+	pod.Name = pod.GenerateName + utilrand.String(5)
+	pod.GenerateName = ""
+}
+
+// addVolumeClaims mounts the claims of the pod through volumes named after the volume claim templates
+// of the pool, replacing template volumes of the same name.
+func addVolumeClaims(pool *sandboxv1alpha1.Pool, pod *corev1.Pod) {
+	for i := range pool.Spec.VolumeClaimTemplates {
+		name := pool.Spec.VolumeClaimTemplates[i].Name
+		volume := corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: volumeClaimName(name, pod.Name)},
+		}}
+		replaced := false
+		for j := range pod.Spec.Volumes {
+			if pod.Spec.Volumes[j].Name == name {
+				pod.Spec.Volumes[j] = volume
+				replaced = true
+			}
+		}
+		if !replaced {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
+		}
+	}
+}
+
+// createVolumeClaims creates the claims of a created pod. Under the Delete retention policy the claims
+// are owned by the pod, so they are garbage collected with it; under Retain they are owned by the pool.
+// Claims that already exist, such as claims retained from a previous pod of the same name, are kept.
+func createVolumeClaims(ctx context.Context, c client.Client, pool *sandboxv1alpha1.Pool, pod *corev1.Pod) error {
+	owner := *metav1.NewControllerRef(pod, corev1.SchemeGroupVersion.WithKind("Pod"))
+	if pool.Spec.VolumeClaimRetentionPolicy == sandboxv1alpha1.PoolVolumeClaimRetentionPolicyRetain {
+		owner = *metav1.NewControllerRef(pool, sandboxv1alpha1.SchemeBuilder.GroupVersion.WithKind("Pool"))
+	}
+	for i := range pool.Spec.VolumeClaimTemplates {
+		template := &pool.Spec.VolumeClaimTemplates[i]
+		claim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            volumeClaimName(template.Name, pod.Name),
+				Namespace:       pool.Namespace,
+				Labels:          make(map[string]string, len(template.Labels)+1),
+				Annotations:     template.Annotations,
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Spec: *template.Spec.DeepCopy(),
+		}
+		for k, v := range template.Labels {
+			claim.Labels[k] = v
+		}
+		claim.Labels[LabelPoolName] = pool.Name
+		if err := c.Create(ctx, claim); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create claim %s of pod %s: %w", claim.Name, pod.Name, err)
+		}
+	}
+	return nil
+}
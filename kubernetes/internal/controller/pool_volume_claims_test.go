@@ -0,0 +1,131 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func newClaimPool(name string, policy sandboxv1alpha1.PoolVolumeClaimRetentionPolicy) *sandboxv1alpha1.Pool {
+	return &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: "pool-uid"},
+		Spec: sandboxv1alpha1.PoolSpec{
+			VolumeClaimRetentionPolicy: policy,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "scratch", Labels: map[string]string{"disk": "scratch"}},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")}},
+				},
+			}},
+		},
+	}
+}
+
+func claimTemplate() *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "main", Image: "busybox", VolumeMounts: []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}}},
+		Volumes:    []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+	}}
+}
+
+func TestCreatePoolPodVolumeClaims(t *testing.T) {
+	ctx := context.Background()
+	pool := newClaimPool("claims", sandboxv1alpha1.PoolVolumeClaimRetentionPolicyDelete)
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+	c := fake.NewClientBuilder().WithScheme(testscheme).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	require.NoError(t, r.createPoolPod(ctx, pool, claimTemplate(), "rev", newPodNamer(pool, nil)))
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, pods))
+	require.Len(t, pods.Items, 1)
+	pod := pods.Items[0]
+	assert.Regexp(t, "^claims-[a-z0-9]{5}$", pod.Name, "the pod is named before it is created")
+	require.Len(t, pod.Spec.Volumes, 1, "the template volume is replaced")
+	assert.Equal(t, "scratch-"+pod.Name, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+
+	claim := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "scratch-" + pod.Name}, claim))
+	assert.Equal(t, "scratch", claim.Labels["disk"])
+	assert.Equal(t, pool.Name, claim.Labels[LabelPoolName])
+	assert.Equal(t, resource.MustParse("50Gi"), claim.Spec.Resources.Requests[corev1.ResourceStorage])
+	require.Len(t, claim.OwnerReferences, 1)
+	assert.Equal(t, "Pod", claim.OwnerReferences[0].Kind, "the claim is deleted with the pod")
+	assert.Equal(t, pod.Name, claim.OwnerReferences[0].Name)
+}
+
+func TestCreatePoolPodRetainedVolumeClaims(t *testing.T) {
+	ctx := context.Background()
+	pool := newClaimPool("retain", sandboxv1alpha1.PoolVolumeClaimRetentionPolicyRetain)
+	pool.Spec.PodNamingStrategy = sandboxv1alpha1.PodNamingStrategyOrdinal
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+	// The claim of a deleted pod of the same name.
+	retained := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "scratch-retain-0", Namespace: "default", Labels: map[string]string{"kept": "true"}}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(retained).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	namer := newPodNamer(pool, nil)
+	require.NoError(t, r.createPoolPod(ctx, pool, claimTemplate(), "rev", namer))
+	require.NoError(t, r.createPoolPod(ctx, pool, claimTemplate(), "rev", namer))
+
+	claim := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(retained), claim))
+	assert.Equal(t, "true", claim.Labels["kept"], "the retained claim is reused")
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "scratch-retain-1"}, claim))
+	require.Len(t, claim.OwnerReferences, 1)
+	assert.Equal(t, "Pool", claim.OwnerReferences[0].Kind, "the claim outlives the pod")
+}
+
+func TestCreatePoolPodVolumeClaimFailure(t *testing.T) {
+	ctx := context.Background()
+	pool := newClaimPool("failing", "")
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if _, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+				return fmt.Errorf("quota exceeded")
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	assert.Error(t, r.createPoolPod(ctx, pool, claimTemplate(), "rev", newPodNamer(pool, nil)))
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, pods))
+	assert.Empty(t, pods.Items, "a pod without its claims is deleted")
+	controllerKey := controllerutils.GetControllerKey(pool)
+	satisfied, _, _ := PoolScaleExpectations.SatisfiedExpectations(controllerKey)
+	assert.False(t, satisfied, "the pool waits for the deletion to be observed before scaling again")
+	observePoolPodDeletions(controllerKey, nil)
+	satisfied, _, _ = PoolScaleExpectations.SatisfiedExpectations(controllerKey)
+	assert.True(t, satisfied)
+}
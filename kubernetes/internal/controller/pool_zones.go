@@ -0,0 +1,210 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// LabelPoolZone marks a pool pod created for the minimum of a zone and pinned to that zone.
+const LabelPoolZone = "sandbox.opensandbox.io/pool-zone"
+
+// podZones returns pod name -> zone of the node of the pod, for the pods bound to a node with a zone.
+func podZones(ctx context.Context, c client.Client, pods []*corev1.Pod) (map[string]string, error) {
+	labels, err := podNodeLabels(ctx, c, pods, []string{corev1.LabelTopologyZone})
+	if err != nil {
+		return nil, err
+	}
+	zones := make(map[string]string, len(labels))
+	for name, values := range labels {
+		if values[0] != "" {
+			zones[name] = values[0]
+		}
+	}
+	return zones, nil
+}
+
+// zoneDeficits returns the zone of every pod to create so that each zone of the pool keeps its
+// minimum of available idle pods, in the order of the zones of the pool. Pods pinned to a zone
+// that are not available yet count towards it, so they are not created twice.
+func zoneDeficits(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, idlePods []string, zones map[string]string) []string {
+	if len(pool.Spec.CapacitySpec.Zones) == 0 {
+		return nil
+	}
+	idle := make(map[string]bool, len(idlePods))
+	for _, name := range idlePods {
+		idle[name] = true
+	}
+	have := make(map[string]int32)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if isPodAvailable(pool, pod) {
+			if zone, ok := zones[pod.Name]; ok && idle[pod.Name] {
+				have[zone]++
+			}
+		} else if zone := pod.Labels[LabelPoolZone]; zone != "" {
+			have[zone]++
+		}
+	}
+	var deficits []string
+	for _, zone := range pool.Spec.CapacitySpec.Zones {
+		for range zone.BufferMin - have[zone.Zone] {
+			deficits = append(deficits, zone.Zone)
+		}
+	}
+	return deficits
+}
+
+// protectZoneBuffers returns the idle pods that scale-in may delete without taking a zone below its
+// minimum. The pods that zoneDeficits counts for a zone are kept, available ones and then the newest
+// first, so scale-in still removes the oldest pods.
+func protectZoneBuffers(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, idlePods []string, zones map[string]string) []string {
+	if len(pool.Spec.CapacitySpec.Zones) == 0 {
+		return idlePods
+	}
+	keep := make(map[string]int32, len(pool.Spec.CapacitySpec.Zones))
+	for _, zone := range pool.Spec.CapacitySpec.Zones {
+		keep[zone.Zone] = zone.BufferMin
+	}
+	idle := make(map[string]bool, len(idlePods))
+	for _, name := range idlePods {
+		idle[name] = true
+	}
+	type candidate struct {
+		pod       *corev1.Pod
+		zone      string
+		available bool
+	}
+	var candidates []candidate
+	for _, pod := range pods {
+		if !idle[pod.Name] || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if isPodAvailable(pool, pod) {
+			if zone, ok := zones[pod.Name]; ok {
+				candidates = append(candidates, candidate{pod: pod, zone: zone, available: true})
+			}
+		} else if zone := pod.Labels[LabelPoolZone]; zone != "" {
+			candidates = append(candidates, candidate{pod: pod, zone: zone})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].available != candidates[j].available {
+			return candidates[i].available
+		}
+		return candidates[j].pod.CreationTimestamp.Before(&candidates[i].pod.CreationTimestamp)
+	})
+	protected := make(map[string]bool)
+	for _, c := range candidates {
+		if keep[c.zone] > 0 {
+			keep[c.zone]--
+			protected[c.pod.Name] = true
+		}
+	}
+	out := make([]string, 0, len(idlePods))
+	for _, name := range idlePods {
+		if !protected[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// pinToZone returns the template with a required node affinity for the zone, labeled with the zone.
+func pinToZone(template *corev1.PodTemplateSpec, zone string) *corev1.PodTemplateSpec {
+	out := template.DeepCopy()
+	if out.Labels == nil {
+		out.Labels = make(map[string]string)
+	}
+	out.Labels[LabelPoolZone] = zone
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelTopologyZone,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{zone},
+	}
+	if out.Spec.Affinity == nil {
+		out.Spec.Affinity = &corev1.Affinity{}
+	}
+	if out.Spec.Affinity.NodeAffinity == nil {
+		out.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := out.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		out.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}}},
+		}
+		return out
+	}
+	// Terms are ORed, so every term needs the zone.
+	for i := range required.NodeSelectorTerms {
+		term := &required.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+	return out
+}
+
+// calculateZoneStatus breaks the pods of the pool down by zone, listing the zones of the pool even
+// without pods.
+func calculateZoneStatus(pool *sandboxv1alpha1.Pool, pods []*corev1.Pod, schedulePods []*corev1.Pod, podAllocation map[string]string, zones map[string]string) []sandboxv1alpha1.PoolZoneStatus {
+	byZone := make(map[string]*sandboxv1alpha1.PoolZoneStatus)
+	get := func(zone string) *sandboxv1alpha1.PoolZoneStatus {
+		status, ok := byZone[zone]
+		if !ok {
+			status = &sandboxv1alpha1.PoolZoneStatus{Zone: zone}
+			byZone[zone] = status
+		}
+		return status
+	}
+	for _, zone := range pool.Spec.CapacitySpec.Zones {
+		get(zone.Zone)
+	}
+	for _, pod := range pods {
+		zone, ok := zones[pod.Name]
+		if !ok {
+			continue
+		}
+		status := get(zone)
+		status.Total++
+		if _, ok := podAllocation[pod.Name]; ok {
+			status.Allocated++
+		}
+	}
+	for _, pod := range schedulePods {
+		zone, ok := zones[pod.Name]
+		if !ok {
+			continue
+		}
+		if _, ok := podAllocation[pod.Name]; !ok && isPodAvailable(pool, pod) {
+			get(zone).Available++
+		}
+	}
+	if len(byZone) == 0 {
+		return nil
+	}
+	result := make([]sandboxv1alpha1.PoolZoneStatus, 0, len(byZone))
+	for _, status := range byZone {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Zone < result[j].Zone })
+	return result
+}
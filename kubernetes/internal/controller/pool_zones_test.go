@@ -0,0 +1,159 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	controllerutils "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils/controller"
+)
+
+func newZonePod(name, node string, age int64, ready bool) *corev1.Pod {
+	phase, status := corev1.PodPending, corev1.ConditionFalse
+	if ready {
+		phase, status = corev1.PodRunning, corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Unix(1000-age, 0))},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: phase, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestZoneDeficits(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{
+		Zones: []sandboxv1alpha1.ZoneCapacity{{Zone: "a", BufferMin: 2}, {Zone: "b", BufferMin: 3}},
+	}}}
+	a1, a2, allocated := newZonePod("a1", "node-a", 2, true), newZonePod("a2", "node-a", 1, true), newZonePod("a3", "node-a", 0, true)
+	pinned := newZonePod("b1", "", 0, false)
+	pinned.Labels = map[string]string{LabelPoolZone: "b"}
+	pods := []*corev1.Pod{a1, a2, allocated, pinned}
+	zones := map[string]string{"a1": "a", "a2": "a", "a3": "a"}
+	idle := []string{"a1", "a2", "b1"}
+
+	assert.Equal(t, []string{"b", "b"}, zoneDeficits(pool, pods, idle, zones), "the pending pinned pod counts for zone b")
+	assert.Empty(t, protectZoneBuffers(pool, pods, idle, zones), "no zone has a pod to spare")
+
+	pool.Spec.CapacitySpec.Zones[0].BufferMin = 1
+	assert.Equal(t, []string{"a1"}, protectZoneBuffers(pool, pods, idle, zones), "the newest pod of zone a is kept")
+	assert.Nil(t, zoneDeficits(&sandboxv1alpha1.Pool{}, pods, idle, zones))
+}
+
+func TestPinToZone(t *testing.T) {
+	template := &corev1.PodTemplateSpec{}
+	out := pinToZone(template, "a")
+	assert.Equal(t, "a", out.Labels[LabelPoolZone])
+	terms := out.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, []string{"a"}, terms[0].MatchExpressions[0].Values)
+	assert.Nil(t, template.Spec.Affinity, "the template is not modified")
+
+	arch := corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}}
+	template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{arch}}, {}},
+	}}}
+	terms = pinToZone(template, "b").Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 2)
+	assert.Len(t, terms[0].MatchExpressions, 2, "the zone is added to every term")
+	assert.Len(t, terms[1].MatchExpressions, 1)
+}
+
+func TestScalePoolZoneMinimums(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "busybox"}}}}
+	maxUnavailable := intstr.FromString("100%")
+	pool := &sandboxv1alpha1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "zones", Namespace: "default", UID: types.UID("uid-zones")},
+		Spec: sandboxv1alpha1.PoolSpec{
+			CapacitySpec: sandboxv1alpha1.CapacitySpec{BufferMin: 2, BufferMax: 2, PoolMax: 10,
+				Zones: []sandboxv1alpha1.ZoneCapacity{{Zone: "a", BufferMin: 2}, {Zone: "b", BufferMin: 1}}},
+			ScaleStrategy: &sandboxv1alpha1.ScaleStrategy{MaxUnavailable: &maxUnavailable},
+		},
+	}
+	t.Cleanup(func() { PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool)) })
+
+	objs := []client.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{corev1.LabelTopologyZone: "a"}}},
+	}
+	var pods []*corev1.Pod
+	var idle []string
+	for i := range 4 {
+		pod := newZonePod(fmt.Sprintf("zones-%d", i), "node-a", int64(i), true)
+		objs = append(objs, pod)
+		pods = append(pods, pod)
+		idle = append(idle, pod.Name)
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+	r := &PoolReconciler{Client: c, Scheme: testscheme, Recorder: record.NewFakeRecorder(10)}
+
+	// The buffer of the pool is two pods over its maximum, but zone b has none of its minimum.
+	require.NoError(t, r.scalePool(context.Background(), pool, &scaleArgs{template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 4, idlePods: idle}))
+	list := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), list))
+	require.Len(t, list.Items, 5, "a pod is created for zone b and none is scaled in")
+	var created *corev1.Pod
+	for i := range list.Items {
+		if list.Items[i].Spec.NodeName == "" {
+			created = &list.Items[i]
+		}
+	}
+	require.NotNil(t, created)
+	assert.Equal(t, "b", created.Labels[LabelPoolZone])
+	assert.NotNil(t, created.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+
+	// Scale-in while the pod of zone b starts keeps it and the minimum of zone a.
+	PoolScaleExpectations.DeleteExpectations(controllerutils.GetControllerKey(pool))
+	pods = append(pods, created)
+	idle = append(idle, created.Name)
+	require.NoError(t, r.scalePool(context.Background(), pool, &scaleArgs{template: template, updateRevision: "rev", pods: pods, allPods: pods, totalPodCnt: 5, idlePods: idle}))
+	list = &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), list))
+	var names []string
+	for _, pod := range list.Items {
+		names = append(names, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"zones-0", "zones-1", created.Name}, names)
+}
+
+func TestCalculateZoneStatus(t *testing.T) {
+	pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{CapacitySpec: sandboxv1alpha1.CapacitySpec{
+		Zones: []sandboxv1alpha1.ZoneCapacity{{Zone: "c", BufferMin: 1}},
+	}}}
+	pods := []*corev1.Pod{
+		newZonePod("a1", "node-a", 0, true), newZonePod("a2", "node-a", 0, true), newZonePod("a3", "node-a", 0, false),
+		newZonePod("b1", "node-b", 0, true), newZonePod("pending", "", 0, false),
+	}
+	zones := map[string]string{"a1": "a", "a2": "a", "a3": "a", "b1": "b"}
+	status := calculateZoneStatus(pool, pods, pods, map[string]string{"a1": "sbx"}, zones)
+	assert.Equal(t, []sandboxv1alpha1.PoolZoneStatus{
+		{Zone: "a", Total: 3, Allocated: 1, Available: 1},
+		{Zone: "b", Total: 1, Available: 1},
+		{Zone: "c"},
+	}, status)
+	assert.Nil(t, calculateZoneStatus(&sandboxv1alpha1.Pool{}, pods, pods, nil, nil))
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+const (
+	// DefaultRecordingUploadTimeoutSeconds is how long a failing upload is retried when the pool sets no
+	// timeout.
+	DefaultRecordingUploadTimeoutSeconds int64 = 600
+
+	// AnnotationSealFailure is set on a pool pod to the sandbox and time of the first failed seal of its
+	// record, so the upload timeout survives controller restarts.
+	AnnotationSealFailure = "pool.sandbox.opensandbox.io/recording-seal-failure"
+)
+
+// SealFunc packages and uploads the session record of the pod for the sandbox id.
+type SealFunc func(ctx context.Context, pod *corev1.Pod, id string) error
+
+// sealFailure is the value of AnnotationSealFailure.
+type sealFailure struct {
+	ID    string      `json:"id"`
+	Since metav1.Time `json:"since"`
+}
+
+// RecordingRecycler is a RecycleHandler that seals the session record of a pod before
+// handing it to the wrapped handler, so the next allocation starts with an empty record.
+type RecordingRecycler struct {
+	inner    Handler
+	seal     SealFunc
+	client   client.Client
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// NewRecordingRecycler wraps inner so that session records are sealed first. c records when sealing
+// started failing on the pod and recorder reports pods given up on; recorder may be nil.
+func NewRecordingRecycler(inner Handler, seal SealFunc, c client.Client, recorder record.EventRecorder) *RecordingRecycler {
+	return &RecordingRecycler{inner: inner, seal: seal, client: c, recorder: recorder, now: time.Now}
+}
+
+// TryRecycle seals the record and then delegates to the wrapped handler. Sealing is
+// re-entrant: once the record is uploaded, later calls find nothing new to upload.
+// The pod stays in Recycling while the record cannot be uploaded, until the upload timeout
+// of the pool passes; then the pod is deleted, or with the Keep policy recycled with the
+// archive left in it. A nil pod has nothing left to seal.
+func (r *RecordingRecycler) TryRecycle(ctx context.Context, pool *sandboxv1alpha1.Pool, pod *corev1.Pod, spec *Spec) (*Status, error) {
+	if pod == nil {
+		return r.inner.TryRecycle(ctx, pool, pod, spec)
+	}
+	sealErr := r.seal(ctx, pod, spec.ID)
+	if sealErr == nil {
+		if err := r.clearFailure(ctx, pod); err != nil {
+			return nil, err
+		}
+		return r.inner.TryRecycle(ctx, pool, pod, spec)
+	}
+	logf.FromContext(ctx).Error(sealErr, "Failed to seal session record", "pod", pod.Name, "sandbox", spec.ID)
+
+	since, err := r.failingSince(ctx, pod, spec.ID)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(DefaultRecordingUploadTimeoutSeconds) * time.Second
+	policy := sandboxv1alpha1.RecordingUploadFailurePolicyDelete
+	if recording := pool.Spec.SessionRecording; recording != nil {
+		if recording.UploadTimeoutSeconds != nil {
+			timeout = time.Duration(*recording.UploadTimeoutSeconds) * time.Second
+		}
+		if recording.UploadFailurePolicy != "" {
+			policy = recording.UploadFailurePolicy
+		}
+	}
+	if r.now().Sub(since) < timeout {
+		return &Status{
+			State:   StateRecycling,
+			Message: fmt.Sprintf("recording recycler: failed to seal session record: %v", sealErr),
+		}, nil
+	}
+
+	if policy == sandboxv1alpha1.RecordingUploadFailurePolicyKeep {
+		// The annotation stays until a seal succeeds, so later calls keep recycling without waiting again.
+		r.event(pool, "RecordingUploadAbandoned", "Recycling pod %s of sandbox %s with its session record not uploaded after %s: %v", pod.Name, spec.ID, timeout, sealErr)
+		return r.inner.TryRecycle(ctx, pool, pod, spec)
+	}
+	r.event(pool, "RecordingUploadAbandoned", "Deleting pod %s of sandbox %s, its session record was not uploaded after %s: %v", pod.Name, spec.ID, timeout, sealErr)
+	return &Status{
+		State:      StateFailed,
+		Message:    fmt.Sprintf("recording recycler: session record not uploaded after %s: %v", timeout, sealErr),
+		NeedDelete: true,
+	}, nil
+}
+
+// failingSince returns when sealing the record of sandbox id on the pod started failing, recording
+// now on the pod for the first failure.
+func (r *RecordingRecycler) failingSince(ctx context.Context, pod *corev1.Pod, id string) (time.Time, error) {
+	var failure sealFailure
+	if raw, ok := pod.Annotations[AnnotationSealFailure]; ok {
+		if err := json.Unmarshal([]byte(raw), &failure); err == nil && failure.ID == id {
+			return failure.Since.Time, nil
+		}
+	}
+	failure = sealFailure{ID: id, Since: metav1.NewTime(r.now())}
+	raw, err := json.Marshal(failure)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to marshal seal failure: %w", err)
+	}
+	if err := r.patchFailure(ctx, pod, string(raw)); err != nil {
+		return time.Time{}, fmt.Errorf("failed to record seal failure: %w", err)
+	}
+	return failure.Since.Time, nil
+}
+
+// clearFailure removes the seal failure annotation from the pod, if set.
+func (r *RecordingRecycler) clearFailure(ctx context.Context, pod *corev1.Pod) error {
+	if _, ok := pod.Annotations[AnnotationSealFailure]; !ok {
+		return nil
+	}
+	if err := r.patchFailure(ctx, pod, nil); err != nil {
+		return fmt.Errorf("failed to clear seal failure: %w", err)
+	}
+	return nil
+}
+
+// patchFailure sets the seal failure annotation of the pod to value, or removes it when value is nil.
+func (r *RecordingRecycler) patchFailure(ctx context.Context, pod *corev1.Pod, value any) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{AnnotationSealFailure: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	obj := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name}}
+	return r.client.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch))
+}
+
+func (r *RecordingRecycler) event(pool *sandboxv1alpha1.Pool, reason, format string, args ...any) {
+	if r.recorder != nil {
+		r.recorder.Eventf(pool, corev1.EventTypeWarning, reason, format, args...)
+	}
+}
+
+// sealWithTaskExecutor asks the task-executor of the pod to seal its session record.
+func sealWithTaskExecutor(ctx context.Context, pod *corev1.Pod, id string) error {
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+	_, err := api.NewClient(taskscheduler.TaskExecutorEndpoint(pod.Status.PodIP)).SealRecording(ctx, id)
+	return err
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func newRecordingTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestRecordingRecycler(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}
+	c := newRecordingTestClient(t, pod.DeepCopy())
+	var sealed []string
+	sealErr := errors.New("upload failed")
+	seal := func(_ context.Context, p *corev1.Pod, id string) error {
+		sealed = append(sealed, p.Name+"/"+id)
+		return sealErr
+	}
+	r := NewRecordingRecycler(NewDeleteRecycler(), seal, c, nil)
+	latest := func() *corev1.Pod {
+		got := &corev1.Pod{}
+		require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(pod), got))
+		return got
+	}
+
+	// The pod is not recycled while its record cannot be uploaded.
+	status, err := r.TryRecycle(context.Background(), &sandboxv1alpha1.Pool{}, latest(), &Spec{ID: "sbx1"})
+	assert.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State)
+	assert.False(t, status.NeedDelete)
+	assert.Contains(t, status.Message, "upload failed")
+	assert.Contains(t, latest().Annotations, AnnotationSealFailure)
+
+	sealErr = nil
+	status, err = r.TryRecycle(context.Background(), &sandboxv1alpha1.Pool{}, latest(), &Spec{ID: "sbx1"})
+	assert.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State)
+	assert.True(t, status.NeedDelete, "the wrapped handler takes over once the record is sealed")
+	assert.Equal(t, []string{"pod1/sbx1", "pod1/sbx1"}, sealed)
+	assert.NotContains(t, latest().Annotations, AnnotationSealFailure, "a successful seal clears the failure")
+
+	// A deleted pod has nothing left to seal.
+	status, err = r.TryRecycle(context.Background(), &sandboxv1alpha1.Pool{}, nil, &Spec{ID: "sbx1"})
+	assert.NoError(t, err)
+	assert.Equal(t, StateSucceeded, status.State)
+	assert.Len(t, sealed, 2)
+}
+
+func TestRecordingRecycler_UploadTimeout(t *testing.T) {
+	seal := func(context.Context, *corev1.Pod, string) error { return errors.New("upload failed") }
+	for _, tt := range []struct {
+		name       string
+		policy     sandboxv1alpha1.RecordingUploadFailurePolicy
+		wantState  string
+		wantDelete bool
+	}{
+		{name: "delete by default", wantState: StateFailed, wantDelete: true},
+		{name: "keep", policy: sandboxv1alpha1.RecordingUploadFailurePolicyKeep, wantState: StateSucceeded},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}
+			c := newRecordingTestClient(t, pod.DeepCopy())
+			recorder := record.NewFakeRecorder(10)
+			r := NewRecordingRecycler(NewNoopRecycler(), seal, c, recorder)
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			r.now = func() time.Time { return now }
+			pool := &sandboxv1alpha1.Pool{Spec: sandboxv1alpha1.PoolSpec{SessionRecording: &sandboxv1alpha1.SessionRecording{
+				Enabled:              true,
+				UploadTimeoutSeconds: ptr.To[int64](60),
+				UploadFailurePolicy:  tt.policy,
+			}}}
+			latest := func() *corev1.Pod {
+				got := &corev1.Pod{}
+				require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(pod), got))
+				return got
+			}
+
+			status, err := r.TryRecycle(context.Background(), pool, latest(), &Spec{ID: "sbx1"})
+			require.NoError(t, err)
+			assert.Equal(t, StateRecycling, status.State)
+
+			// Still within the timeout.
+			now = now.Add(30 * time.Second)
+			status, err = r.TryRecycle(context.Background(), pool, latest(), &Spec{ID: "sbx1"})
+			require.NoError(t, err)
+			assert.Equal(t, StateRecycling, status.State)
+			assert.Empty(t, recorder.Events)
+
+			now = now.Add(30 * time.Second)
+			status, err = r.TryRecycle(context.Background(), pool, latest(), &Spec{ID: "sbx1"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantState, status.State)
+			assert.Equal(t, tt.wantDelete, status.NeedDelete)
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, "RecordingUploadAbandoned")
+
+			// The next sandbox starts its own timeout.
+			status, err = r.TryRecycle(context.Background(), pool, latest(), &Spec{ID: "sbx2"})
+			require.NoError(t, err)
+			assert.Equal(t, StateRecycling, status.State)
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
@@ -26,7 +27,31 @@ import (
 
 // NewHandler creates the appropriate Handler based on the Pool's recycle strategy.
 // If no strategy is configured, DeleteRecycler is used as the default.
-func NewHandler(c client.Client, restConfig *rest.Config, pool *sandboxv1alpha1.Pool) (Handler, error) {
+// Pods that are kept are sanitized first when the strategy sets a sanitize command, pods that reached
+// the maxAllocations of the pool are deleted instead, and with session recording enabled, the handler
+// seals the record of each pod before anything else, reporting pods it gives up on to recorder.
+func NewHandler(c client.Client, restConfig *rest.Config, recorder record.EventRecorder, pool *sandboxv1alpha1.Pool) (Handler, error) {
+	h, err := newStrategyHandler(c, restConfig, pool)
+	if err != nil {
+		return nil, err
+	}
+	if strategy := pool.Spec.RecycleStrategy; strategy != nil && strategy.Sanitize != nil {
+		if _, deletes := h.(*DeleteRecycler); !deletes {
+			h = NewSanitizingRecycler(h, strategy.Sanitize, taskExecutorClient)
+		}
+	}
+	if pool.Spec.MaxAllocations != nil {
+		if _, deletes := h.(*DeleteRecycler); !deletes {
+			h = NewRetiringRecycler(h, *pool.Spec.MaxAllocations)
+		}
+	}
+	if pool.Spec.SessionRecording != nil && pool.Spec.SessionRecording.Enabled {
+		return NewRecordingRecycler(h, sealWithTaskExecutor, c, recorder), nil
+	}
+	return h, nil
+}
+
+func newStrategyHandler(c client.Client, restConfig *rest.Config, pool *sandboxv1alpha1.Pool) (Handler, error) {
 	if pool.Spec.RecycleStrategy == nil {
 		return NewDeleteRecycler(), nil
 	}
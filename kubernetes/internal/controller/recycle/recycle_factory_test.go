@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
 )
@@ -78,10 +79,63 @@ func TestNewHandler(t *testing.T) {
 			},
 			wantHandler: &DeleteRecycler{},
 		},
+		{
+			name: "Sanitize_WrapsStrategy",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{
+					RecycleStrategy: &sandboxv1alpha1.RecycleStrategy{
+						Type:     sandboxv1alpha1.RecycleTypeNoop,
+						Sanitize: &sandboxv1alpha1.SanitizeAction{Command: []string{"/bin/cleanup"}},
+					},
+				},
+			},
+			wantHandler: &SanitizingRecycler{},
+		},
+		{
+			name: "Sanitize_SkippedForDelete",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{
+					RecycleStrategy: &sandboxv1alpha1.RecycleStrategy{
+						Type:     sandboxv1alpha1.RecycleTypeDelete,
+						Sanitize: &sandboxv1alpha1.SanitizeAction{Command: []string{"/bin/cleanup"}},
+					},
+				},
+			},
+			wantHandler: &DeleteRecycler{},
+		},
+		{
+			name: "MaxAllocations_WrapsStrategy",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{
+					RecycleStrategy: &sandboxv1alpha1.RecycleStrategy{
+						Type:     sandboxv1alpha1.RecycleTypeNoop,
+						Sanitize: &sandboxv1alpha1.SanitizeAction{Command: []string{"/bin/cleanup"}},
+					},
+					MaxAllocations: ptr.To[int32](10),
+				},
+			},
+			wantHandler: &RetiringRecycler{},
+		},
+		{
+			name: "MaxAllocations_SkippedForDelete",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{MaxAllocations: ptr.To[int32](10)},
+			},
+			wantHandler: &DeleteRecycler{},
+		},
+		{
+			name: "SessionRecording_WrapsStrategy",
+			pool: &sandboxv1alpha1.Pool{
+				Spec: sandboxv1alpha1.PoolSpec{
+					SessionRecording: &sandboxv1alpha1.SessionRecording{Enabled: true},
+				},
+			},
+			wantHandler: &RecordingRecycler{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h, err := NewHandler(nil, nil, tt.pool)
+			h, err := NewHandler(nil, nil, nil, tt.pool)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, h)
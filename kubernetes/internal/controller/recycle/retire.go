@@ -0,0 +1,74 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recycle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// AnnotationAllocationCount is set by the pool controller on a pool pod to the number of times the pod
+// has been allocated.
+const AnnotationAllocationCount = "pool.sandbox.opensandbox.io/allocation-count"
+
+// AllocationCount returns how many times the pod has been allocated, 0 if it never was or the count
+// cannot be read.
+func AllocationCount(pod *corev1.Pod) int32 {
+	count, err := strconv.ParseInt(pod.Annotations[AnnotationAllocationCount], 10, 32)
+	if err != nil || count < 0 {
+		return 0
+	}
+	return int32(count)
+}
+
+// RetiringRecycler is a RecycleHandler that deletes the pods that reached the maximum number of
+// allocations of the pool and hands the others to the wrapped handler.
+type RetiringRecycler struct {
+	inner          Handler
+	retire         *DeleteRecycler
+	maxAllocations int32
+}
+
+// NewRetiringRecycler wraps inner so that pods allocated maxAllocations times are deleted.
+func NewRetiringRecycler(inner Handler, maxAllocations int32) *RetiringRecycler {
+	return &RetiringRecycler{inner: inner, retire: NewDeleteRecycler(), maxAllocations: maxAllocations}
+}
+
+// TryRecycle deletes the pod once it has been allocated maxAllocations times, without running the
+// wrapped handler on it. A nil pod has nothing left to retire.
+func (r *RetiringRecycler) TryRecycle(ctx context.Context, pool *sandboxv1alpha1.Pool, pod *corev1.Pod, spec *Spec) (*Status, error) {
+	if pod == nil {
+		return r.inner.TryRecycle(ctx, pool, pod, spec)
+	}
+	count := AllocationCount(pod)
+	if count < r.maxAllocations {
+		return r.inner.TryRecycle(ctx, pool, pod, spec)
+	}
+	status, err := r.retire.TryRecycle(ctx, pool, pod, spec)
+	if err != nil {
+		return nil, err
+	}
+	if status.NeedDelete {
+		logf.FromContext(ctx).Info("Retiring pool pod", "pod", pod.Name, "allocations", count, "maxAllocations", r.maxAllocations)
+	}
+	status.Message = fmt.Sprintf("retiring recycler: pod was allocated %d times: %s", count, status.Message)
+	return status, nil
+}
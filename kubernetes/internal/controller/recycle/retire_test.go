@@ -0,0 +1,64 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recycle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func podWithAllocations(count string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Annotations: map[string]string{AnnotationAllocationCount: count}}}
+}
+
+func TestAllocationCount(t *testing.T) {
+	assert.Equal(t, int32(0), AllocationCount(&corev1.Pod{}))
+	assert.Equal(t, int32(4), AllocationCount(podWithAllocations("4")))
+	assert.Equal(t, int32(0), AllocationCount(podWithAllocations("many")))
+}
+
+func TestRetiringRecycler(t *testing.T) {
+	ctx := context.Background()
+	pool := &sandboxv1alpha1.Pool{}
+	r := NewRetiringRecycler(NewNoopRecycler(), 3)
+
+	status, err := r.TryRecycle(ctx, pool, podWithAllocations("2"), &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateSucceeded, status.State, "pods below the limit go back to the buffer")
+	assert.False(t, status.NeedDelete)
+
+	status, err = r.TryRecycle(ctx, pool, podWithAllocations("3"), &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State)
+	assert.True(t, status.NeedDelete, "pods at the limit are retired")
+	assert.Contains(t, status.Message, "allocated 3 times")
+
+	deleting := podWithAllocations("3")
+	deleting.DeletionTimestamp = &metav1.Time{}
+	status, err = r.TryRecycle(ctx, pool, deleting, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateSucceeded, status.State)
+
+	status, err = r.TryRecycle(ctx, pool, nil, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateSucceeded, status.State)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recycle
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+const (
+	// DefaultSanitizeTimeoutSeconds caps the sanitize command when the pool sets no timeout.
+	DefaultSanitizeTimeoutSeconds int64 = 60
+
+	sanitizeTaskPrefix = "sanitize-"
+)
+
+// TaskClient is the part of the task-executor API that runs the sanitize command.
+type TaskClient interface {
+	Set(ctx context.Context, task *api.Task) (*api.Task, error)
+	Get(ctx context.Context) (*api.Task, error)
+}
+
+// TaskClientFunc returns the task-executor client of a pod.
+type TaskClientFunc func(pod *corev1.Pod) (TaskClient, error)
+
+// SanitizingRecycler is a RecycleHandler that runs the sanitize command of the pool in a pod through
+// its task-executor before handing the pod to the wrapped handler.
+type SanitizingRecycler struct {
+	inner  Handler
+	action *sandboxv1alpha1.SanitizeAction
+	tasks  TaskClientFunc
+}
+
+// NewSanitizingRecycler wraps inner so that pods are sanitized first.
+func NewSanitizingRecycler(inner Handler, action *sandboxv1alpha1.SanitizeAction, tasks TaskClientFunc) *SanitizingRecycler {
+	return &SanitizingRecycler{inner: inner, action: action, tasks: tasks}
+}
+
+// TryRecycle starts the sanitize command of the sandbox as the only task of the pod, which also stops
+// the tasks the sandbox left running, and delegates to the wrapped handler once the command succeeded.
+// The terminated task stays on the task-executor until the next sandbox sets its tasks, so re-entrant
+// calls find the command done. A command that fails falls back to deleting the pod. A nil pod has
+// nothing left to sanitize.
+func (r *SanitizingRecycler) TryRecycle(ctx context.Context, pool *sandboxv1alpha1.Pool, pod *corev1.Pod, spec *Spec) (*Status, error) {
+	if pod == nil {
+		return r.inner.TryRecycle(ctx, pool, pod, spec)
+	}
+	recycling := func(format string, args ...any) (*Status, error) {
+		return &Status{State: StateRecycling, Message: "sanitizing recycler: " + fmt.Sprintf(format, args...)}, nil
+	}
+	tasks, err := r.tasks(pod)
+	if err != nil {
+		return recycling("%v", err)
+	}
+	name := sanitizeTaskPrefix + spec.ID
+	task, err := tasks.Get(ctx)
+	if err != nil {
+		return recycling("failed to get the tasks of the pod: %v", err)
+	}
+	if task == nil || task.Name != name {
+		timeout := DefaultSanitizeTimeoutSeconds
+		if r.action.TimeoutSeconds != nil {
+			timeout = *r.action.TimeoutSeconds
+		}
+		process := &api.Process{Command: r.action.Command, TimeoutSeconds: &timeout}
+		if _, err := tasks.Set(ctx, &api.Task{Name: name, Process: process}); err != nil {
+			return recycling("failed to start the sanitize command: %v", err)
+		}
+		logf.FromContext(ctx).Info("Started sanitize command", "pod", pod.Name, "sandbox", spec.ID)
+		return recycling("sanitize command started")
+	}
+	if task.ProcessStatus == nil || task.ProcessStatus.Terminated == nil {
+		return recycling("sanitize command running")
+	}
+	if terminated := task.ProcessStatus.Terminated; terminated.ExitCode != 0 {
+		return &Status{
+			State: StateFailed,
+			Message: fmt.Sprintf("sanitizing recycler: sanitize command exited with code %d: %s %s",
+				terminated.ExitCode, terminated.Reason, terminated.Message),
+			NeedDelete: true,
+		}, nil
+	}
+	return r.inner.TryRecycle(ctx, pool, pod, spec)
+}
+
+// taskExecutorClient returns the client of the task-executor of the pod.
+func taskExecutorClient(pod *corev1.Pod) (TaskClient, error) {
+	if pod.Status.PodIP == "" {
+		return nil, fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+	return api.NewClient(taskscheduler.TaskExecutorEndpoint(pod.Status.PodIP)), nil
+}
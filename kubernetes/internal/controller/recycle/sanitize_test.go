@@ -0,0 +1,103 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+type fakeTaskClient struct {
+	task   *api.Task
+	setErr error
+}
+
+func (f *fakeTaskClient) Set(_ context.Context, task *api.Task) (*api.Task, error) {
+	if f.setErr != nil {
+		return nil, f.setErr
+	}
+	f.task = task
+	return task, nil
+}
+
+func (f *fakeTaskClient) Get(context.Context) (*api.Task, error) {
+	return f.task, nil
+}
+
+func TestSanitizingRecycler(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}
+	tasks := &fakeTaskClient{task: &api.Task{Name: "sbx1-task", Process: &api.Process{Command: []string{"agent"}}}}
+	action := &sandboxv1alpha1.SanitizeAction{Command: []string{"sh", "-c", "rm -rf /tmp/*"}}
+	r := NewSanitizingRecycler(NewNoopRecycler(), action, func(*corev1.Pod) (TaskClient, error) { return tasks, nil })
+
+	// The sandbox task is replaced by the sanitize command.
+	status, err := r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, pod, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State)
+	require.NotNil(t, tasks.task)
+	assert.Equal(t, "sanitize-sbx1", tasks.task.Name)
+	assert.Equal(t, action.Command, tasks.task.Process.Command)
+	assert.Equal(t, DefaultSanitizeTimeoutSeconds, *tasks.task.Process.TimeoutSeconds)
+
+	status, err = r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, pod, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State, "the command is still running")
+
+	tasks.task.ProcessStatus = &api.ProcessStatus{Terminated: &api.Terminated{ExitCode: 0}}
+	status, err = r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, pod, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateSucceeded, status.State, "the wrapped handler takes over")
+
+	// The next release of the pod runs the command again.
+	status, err = r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, pod, &Spec{ID: "sbx2"})
+	require.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State)
+	assert.Equal(t, "sanitize-sbx2", tasks.task.Name)
+}
+
+func TestSanitizingRecyclerFailure(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}
+	tasks := &fakeTaskClient{setErr: errors.New("connection refused")}
+	r := NewSanitizingRecycler(NewNoopRecycler(), &sandboxv1alpha1.SanitizeAction{Command: []string{"false"}},
+		func(*corev1.Pod) (TaskClient, error) { return tasks, nil })
+
+	status, err := r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, pod, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateRecycling, status.State)
+	assert.Contains(t, status.Message, "connection refused")
+
+	tasks.setErr = nil
+	tasks.task = &api.Task{Name: "sanitize-sbx1", ProcessStatus: &api.ProcessStatus{Terminated: &api.Terminated{ExitCode: 1, Reason: "Error"}}}
+	status, err = r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, pod, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateFailed, status.State)
+	assert.True(t, status.NeedDelete, "an unsanitized pod is not reused")
+
+	// A deleted pod has nothing left to sanitize.
+	status, err = r.TryRecycle(ctx, &sandboxv1alpha1.Pool{}, nil, &Spec{ID: "sbx1"})
+	require.NoError(t, err)
+	assert.Equal(t, StateSucceeded, status.State)
+}
@@ -205,7 +205,7 @@ func (r *SandboxSnapshotReconciler) handleDeletion(ctx context.Context, snapshot
 
 // findPodForSandbox finds the running pod belonging to a BatchSandbox.
 func (r *SandboxSnapshotReconciler) findPodForSandbox(ctx context.Context, bs *sandboxv1alpha1.BatchSandbox, namespace string) (*corev1.Pod, error) {
-	alloc, err := parseSandboxAllocation(bs)
+	alloc, err := sandboxAllocation(ctx, r.Client, bs)
 	if err == nil && len(alloc.Pods) > 0 {
 		for _, podName := range alloc.Pods {
 			pod := &corev1.Pod{}
@@ -15,6 +15,7 @@
 package strategy
 
 import (
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
 	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 )
 
@@ -27,4 +28,7 @@ type TaskSchedulingStrategy interface {
 
 	// GenerateTaskSpecs generates the complete list of task specifications for the BatchSandbox.
 	GenerateTaskSpecs() ([]*api.Task, error)
+
+	// GeneratePlacementPolicy returns the order in which pending tasks are assigned pods.
+	GeneratePlacementPolicy() (scheduler.PlacementPolicy, error)
 }
@@ -17,10 +17,13 @@ package strategy
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/utils/ptr"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
 	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 )
 
@@ -54,39 +57,101 @@ func (s *DefaultTaskSchedulingStrategy) GenerateTaskSpecs() ([]*api.Task, error)
 	return ret, nil
 }
 
+// GeneratePlacementPolicy builds the placement policy for the tasks from TaskPlacementPolicy and
+// the priority and estimate each task declares in its patched TaskTemplate.
+func (s *DefaultTaskSchedulingStrategy) GeneratePlacementPolicy() (scheduler.PlacementPolicy, error) {
+	policy := sandboxv1alpha1.TaskPlacementPolicyIndexOrder
+	if s.Spec.TaskPlacementPolicy != nil {
+		policy = *s.Spec.TaskPlacementPolicy
+	}
+	if policy == sandboxv1alpha1.TaskPlacementPolicyIndexOrder || s.Spec.TaskTemplate == nil {
+		return scheduler.NewPlacementPolicy(policy, nil), nil
+	}
+	hints := make(map[string]scheduler.PlacementHint, *s.Spec.Replicas)
+	for idx := range int(*s.Spec.Replicas) {
+		taskTemplate, err := s.getTaskTemplate(idx)
+		if err != nil {
+			return nil, err
+		}
+		hint := scheduler.PlacementHint{}
+		if taskTemplate.Spec.Priority != nil {
+			hint.Priority = *taskTemplate.Spec.Priority
+		}
+		if taskTemplate.Spec.EstimatedDurationSeconds != nil {
+			hint.EstimatedDuration = ptr.To(time.Duration(*taskTemplate.Spec.EstimatedDurationSeconds) * time.Second)
+		}
+		hints[s.taskName(idx)] = hint
+	}
+	return scheduler.NewPlacementPolicy(policy, hints), nil
+}
+
+func (s *DefaultTaskSchedulingStrategy) taskName(idx int) string {
+	return fmt.Sprintf("%s-%d", s.Name, idx)
+}
+
+// getTaskTemplate returns the TaskTemplate for the given index with its ShardTaskPatches entry applied.
+func (s *DefaultTaskSchedulingStrategy) getTaskTemplate(idx int) (*sandboxv1alpha1.TaskTemplateSpec, error) {
+	if len(s.Spec.ShardTaskPatches) == 0 || idx >= len(s.Spec.ShardTaskPatches) {
+		return s.Spec.TaskTemplate, nil
+	}
+	taskTemplate := s.Spec.TaskTemplate.DeepCopy()
+	cloneBytes, _ := json.Marshal(taskTemplate)
+	patch := s.Spec.ShardTaskPatches[idx]
+	modified, err := strategicpatch.StrategicMergePatch(cloneBytes, patch.Raw, &sandboxv1alpha1.TaskTemplateSpec{})
+	if err != nil {
+		return nil, fmt.Errorf("batchsandbox: failed to merge patch raw %s, idx %d, err %w", patch.Raw, idx, err)
+	}
+	newTaskTemplate := &sandboxv1alpha1.TaskTemplateSpec{}
+	if err = json.Unmarshal(modified, newTaskTemplate); err != nil {
+		return nil, fmt.Errorf("batchsandbox: failed to unmarshal %s to TaskTemplateSpec, idx %d, err %w", modified, idx, err)
+	}
+	return newTaskTemplate, nil
+}
+
 // getTaskSpec generates a single task specification for the given index.
 // It applies ShardTaskPatches if available, otherwise uses the base TaskTemplate.
 func (s *DefaultTaskSchedulingStrategy) getTaskSpec(idx int) (*api.Task, error) {
 	task := &api.Task{
-		Name: fmt.Sprintf("%s-%d", s.Name, idx),
+		Name: s.taskName(idx),
 	}
 	if len(s.Spec.ShardTaskPatches) > 0 && idx < len(s.Spec.ShardTaskPatches) {
-		taskTemplate := s.Spec.TaskTemplate.DeepCopy()
-		cloneBytes, _ := json.Marshal(taskTemplate)
-		patch := s.Spec.ShardTaskPatches[idx]
-		modified, err := strategicpatch.StrategicMergePatch(cloneBytes, patch.Raw, &sandboxv1alpha1.TaskTemplateSpec{})
+		newTaskTemplate, err := s.getTaskTemplate(idx)
 		if err != nil {
-			return nil, fmt.Errorf("batchsandbox: failed to merge patch raw %s, idx %d, err %w", patch.Raw, idx, err)
-		}
-		newTaskTemplate := &sandboxv1alpha1.TaskTemplateSpec{}
-		if err = json.Unmarshal(modified, newTaskTemplate); err != nil {
-			return nil, fmt.Errorf("batchsandbox: failed to unmarshal %s to TaskTemplateSpec, idx %d, err %w", modified, idx, err)
+			return nil, err
 		}
 		task.Process = &api.Process{
 			Command:        newTaskTemplate.Spec.Process.Command,
 			Args:           newTaskTemplate.Spec.Process.Args,
 			Env:            newTaskTemplate.Spec.Process.Env,
 			WorkingDir:     newTaskTemplate.Spec.Process.WorkingDir,
+			Image:          newTaskTemplate.Spec.Process.Image,
 			TimeoutSeconds: s.Spec.TaskTemplate.Spec.TimeoutSeconds,
 		}
+		if n := newTaskTemplate.Spec.Process.CaptureOutputBytes; n != nil {
+			task.Process.CaptureOutputBytes = *n
+		}
+		setProcessTuning(task.Process, newTaskTemplate.Spec.Process)
 	} else if s.Spec.TaskTemplate != nil && s.Spec.TaskTemplate.Spec.Process != nil {
 		task.Process = &api.Process{
 			Command:        s.Spec.TaskTemplate.Spec.Process.Command,
 			Args:           s.Spec.TaskTemplate.Spec.Process.Args,
 			Env:            s.Spec.TaskTemplate.Spec.Process.Env,
 			WorkingDir:     s.Spec.TaskTemplate.Spec.Process.WorkingDir,
+			Image:          s.Spec.TaskTemplate.Spec.Process.Image,
 			TimeoutSeconds: s.Spec.TaskTemplate.Spec.TimeoutSeconds,
 		}
+		if n := s.Spec.TaskTemplate.Spec.Process.CaptureOutputBytes; n != nil {
+			task.Process.CaptureOutputBytes = *n
+		}
+		setProcessTuning(task.Process, s.Spec.TaskTemplate.Spec.Process)
 	}
 	return task, nil
 }
+
+// setProcessTuning copies the settings the task-executor applies to the process before executing it.
+func setProcessTuning(process *api.Process, task *sandboxv1alpha1.ProcessTask) {
+	process.Umask = task.Umask
+	process.MaxOpenFiles = task.MaxOpenFiles
+	process.Nice = task.Nice
+	process.IOClass = task.IOClass
+}
@@ -16,12 +16,15 @@ package strategy
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
 	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 )
 
@@ -213,3 +216,74 @@ func TestDefaultTaskSchedulingStrategy_getTaskSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultTaskSchedulingStrategy_GeneratePlacementPolicy(t *testing.T) {
+	batchSbx := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-bs",
+			Namespace: "default",
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			Replicas: ptr.To[int32](3),
+			TaskTemplate: &sandboxv1alpha1.TaskTemplateSpec{
+				Spec: sandboxv1alpha1.TaskSpec{
+					Process: &sandboxv1alpha1.ProcessTask{
+						Command: []string{"echo", "hello"},
+					},
+					EstimatedDurationSeconds: ptr.To[int64](60),
+				},
+			},
+			ShardTaskPatches: []runtime.RawExtension{
+				{Raw: []byte(`{"spec":{"priority":1}}`)},
+				{Raw: []byte(`{"spec":{"priority":5,"estimatedDurationSeconds":10}}`)},
+			},
+		},
+	}
+	order := func(policy scheduler.PlacementPolicy) []string {
+		names := []string{"test-bs-0", "test-bs-1", "test-bs-2"}
+		sort.SliceStable(names, func(i, j int) bool {
+			return policy.Less(fakeTask(names[i]), fakeTask(names[j]))
+		})
+		return names
+	}
+	tests := []struct {
+		name   string
+		policy *sandboxv1alpha1.TaskPlacementPolicy
+		want   []string
+	}{
+		{
+			name: "default is index order",
+			want: []string{"test-bs-0", "test-bs-1", "test-bs-2"},
+		},
+		{
+			name:   "shortest first uses patched estimates",
+			policy: ptr.To(sandboxv1alpha1.TaskPlacementPolicyShortestFirst),
+			want:   []string{"test-bs-1", "test-bs-0", "test-bs-2"},
+		},
+		{
+			name:   "priority uses patched priorities",
+			policy: ptr.To(sandboxv1alpha1.TaskPlacementPolicyPriority),
+			want:   []string{"test-bs-1", "test-bs-0", "test-bs-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := batchSbx.DeepCopy()
+			bs.Spec.TaskPlacementPolicy = tt.policy
+			policy, err := NewDefaultTaskSchedulingStrategy(bs).GeneratePlacementPolicy()
+			if err != nil {
+				t.Fatalf("GeneratePlacementPolicy() error = %v", err)
+			}
+			if got := order(policy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GeneratePlacementPolicy() order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeTask string
+
+func (f fakeTask) GetName() string               { return string(f) }
+func (f fakeTask) GetState() scheduler.TaskState { return scheduler.UnknownTaskState }
+func (f fakeTask) GetPodName() string            { return "" }
+func (f fakeTask) IsResourceReleased() bool      { return false }
@@ -0,0 +1,232 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/controller/strategy"
+	taskscheduler "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/scheduler"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+const (
+	// taskDispatchSubresource is the virtual BatchSandbox subresource that callers of the dispatch API
+	// must be authorized for: "create" to submit tasks and "get" to sync them.
+	taskDispatchSubresource = "tasks"
+
+	defaultTaskDispatchTimeout     = 10 * time.Second
+	defaultTaskDispatchConcurrency = 16
+	maxTaskDispatchBodyBytes       = 4 << 20
+)
+
+var errPodNotServing = errors.New("pod is not serving")
+
+// TaskDispatchRequest is the body of a task submission. Tasks[i] is sent to the pod with index i of the
+// BatchSandbox; a null entry leaves that pod untouched. A task with an empty name clears the pod's task.
+type TaskDispatchRequest struct {
+	Tasks []*api.Task `json:"tasks"`
+}
+
+// PodTaskResult is the outcome of dispatching to or syncing from the task-executor of a single pod.
+type PodTaskResult struct {
+	Index int       `json:"index"`
+	Pod   string    `json:"pod"`
+	Task  *api.Task `json:"task,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// TaskDispatchResponse lists the per-pod results ordered by pod index.
+type TaskDispatchResponse struct {
+	Pods []PodTaskResult `json:"pods"`
+}
+
+type taskExecutorClient interface {
+	Set(ctx context.Context, task *api.Task) (*api.Task, error)
+	Get(ctx context.Context) (*api.Task, error)
+}
+
+// handleDispatch submits tasks of a BatchSandbox to the task-executors of its pods.
+//
+//	POST /v1/namespaces/{namespace}/batchsandboxes/{name}/tasks  body: TaskDispatchRequest
+func (s *ManagerAPIServer) handleDispatch(w http.ResponseWriter, r *http.Request) {
+	// The body is only read once the caller is known to be allowed to submit tasks.
+	key, ok := s.authorizeTasks(w, r, "create")
+	if !ok {
+		return
+	}
+	req := &TaskDispatchRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaskDispatchBodyBytes)).Decode(req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	s.serve(w, r, key, "create", func(ctx context.Context, index int, c taskExecutorClient) (*api.Task, bool, error) {
+		if index >= len(req.Tasks) || req.Tasks[index] == nil {
+			return nil, false, nil
+		}
+		task := req.Tasks[index]
+		if task.Name == "" {
+			task = nil
+		}
+		got, err := c.Set(ctx, task)
+		return got, true, err
+	})
+}
+
+// handleSync reads the current task of every pod of a BatchSandbox.
+//
+//	GET /v1/namespaces/{namespace}/batchsandboxes/{name}/tasks
+func (s *ManagerAPIServer) handleSync(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.authorizeTasks(w, r, "get")
+	if !ok {
+		return
+	}
+	s.serve(w, r, key, "get", func(ctx context.Context, _ int, c taskExecutorClient) (*api.Task, bool, error) {
+		got, err := c.Get(ctx)
+		return got, true, err
+	})
+}
+
+// authorizeTasks checks that the caller may perform verb on the tasks of the BatchSandbox named by the
+// request path. It writes the error response and reports false otherwise.
+func (s *ManagerAPIServer) authorizeTasks(w http.ResponseWriter, r *http.Request, verb string) (types.NamespacedName, bool) {
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	status, err := s.reviewAccess(r.Context(), r, &authorizationv1.ResourceAttributes{
+		Namespace:   key.Namespace,
+		Verb:        verb,
+		Group:       sandboxv1alpha1.GroupVersion.Group,
+		Resource:    "batchsandboxes",
+		Subresource: taskDispatchSubresource,
+		Name:        key.Name,
+	})
+	if err != nil {
+		writeAPIError(w, status, err)
+		return key, false
+	}
+	return key, true
+}
+
+// serve resolves the pods of an authorized BatchSandbox and runs fn against the task-executor of each of them
+// concurrently. fn reports false if it skipped the pod.
+func (s *ManagerAPIServer) serve(w http.ResponseWriter, r *http.Request, key types.NamespacedName, verb string,
+	fn func(ctx context.Context, index int, c taskExecutorClient) (*api.Task, bool, error)) {
+	ctx := r.Context()
+	log := logf.FromContext(ctx).WithValues("batchsandbox", key, "verb", verb)
+
+	batchSbx := &sandboxv1alpha1.BatchSandbox{}
+	if err := s.Client.Get(ctx, key, batchSbx); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeAPIError(w, http.StatusNotFound, fmt.Errorf("batchsandbox %s not found", key))
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if batchSbx.Spec.TaskTemplate != nil {
+		writeAPIError(w, http.StatusConflict, fmt.Errorf("batchsandbox %s tasks are managed by the controller through spec.taskTemplate", key))
+		return
+	}
+	poolStrategy := strategy.NewPoolStrategy(batchSbx)
+	pods, err := listBatchSandboxPods(ctx, s.Client, poolStrategy, batchSbx)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	podIndex, err := calPodIndex(ctx, s.Client, poolStrategy, batchSbx, pods)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make([]PodTaskResult, 0, len(pods))
+		sem     = make(chan struct{}, defaultTaskDispatchConcurrency)
+	)
+	for _, pod := range pods {
+		idx, ok := podIndex[pod.Name]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			callCtx, cancel := context.WithTimeout(ctx, s.taskDispatchTimeout())
+			defer cancel()
+			task, dispatched, err := fn(callCtx, idx, s.taskClient(pod))
+			if !dispatched {
+				return
+			}
+			res := PodTaskResult{Index: idx, Pod: pod.Name, Task: task}
+			if err != nil {
+				log.Error(err, "task-executor call failed", "pod", pod.Name)
+				res.Error = err.Error()
+			}
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	writeAPIJSON(w, http.StatusOK, &TaskDispatchResponse{Pods: results})
+}
+
+func (s *ManagerAPIServer) taskDispatchTimeout() time.Duration {
+	if s.TaskDispatchTimeout > 0 {
+		return s.TaskDispatchTimeout
+	}
+	return defaultTaskDispatchTimeout
+}
+
+func (s *ManagerAPIServer) taskClient(pod *corev1.Pod) taskExecutorClient {
+	if pod.DeletionTimestamp != nil || pod.Status.PodIP == "" {
+		return unservingTaskExecutor{}
+	}
+	if s.newTaskClient != nil {
+		return s.newTaskClient(pod)
+	}
+	return api.NewClient(taskscheduler.TaskExecutorEndpoint(pod.Status.PodIP))
+}
+
+// unservingTaskExecutor stands in for the task-executor of a pod that is terminating or has no IP yet.
+type unservingTaskExecutor struct{}
+
+func (unservingTaskExecutor) Set(context.Context, *api.Task) (*api.Task, error) {
+	return nil, errPodNotServing
+}
+
+func (unservingTaskExecutor) Get(context.Context) (*api.Task, error) {
+	return nil, errPodNotServing
+}
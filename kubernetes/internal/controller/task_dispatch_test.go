@@ -0,0 +1,189 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+type fakeTaskExecutor struct {
+	mu   sync.Mutex
+	task *api.Task
+	err  error
+}
+
+func (f *fakeTaskExecutor) Set(_ context.Context, task *api.Task) (*api.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.task = task
+	return task, nil
+}
+
+func (f *fakeTaskExecutor) Get(_ context.Context) (*api.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.task, f.err
+}
+
+func TestTaskDispatchServer(t *testing.T) {
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(sandboxv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(authenticationv1.AddToScheme(scheme))
+	utilruntime.Must(authorizationv1.AddToScheme(scheme))
+
+	newPod := func(name, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+	pooled := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sbx",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnoAllocStatusKey: `{"pods":["pod-a","pod-b","pod-c"]}`},
+		},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{PoolRef: "pool"},
+	}
+	templated := &sandboxv1alpha1.BatchSandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "templated", Namespace: "default"},
+		Spec: sandboxv1alpha1.BatchSandboxSpec{
+			PoolRef:      "pool",
+			TaskTemplate: &sandboxv1alpha1.TaskTemplateSpec{},
+		},
+	}
+
+	var reviewed []authorizationv1.ResourceAttributes
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(pooled, templated, newPod("pod-a", "10.0.0.1"), newPod("pod-b", "10.0.0.2"), newPod("pod-c", "")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				switch review := obj.(type) {
+				case *authenticationv1.TokenReview:
+					review.Status.Authenticated = review.Spec.Token != "invalid"
+					review.Status.User.Username = review.Spec.Token
+				case *authorizationv1.SubjectAccessReview:
+					reviewed = append(reviewed, *review.Spec.ResourceAttributes)
+					review.Status.Allowed = review.Spec.User == "alice"
+				default:
+					return c.Create(ctx, obj, opts...)
+				}
+				return nil
+			},
+		}).Build()
+
+	executors := map[string]*fakeTaskExecutor{
+		"pod-a": {},
+		"pod-b": {err: fmt.Errorf("connection refused")},
+	}
+	srv := &ManagerAPIServer{
+		Client:        c,
+		newTaskClient: func(pod *corev1.Pod) taskExecutorClient { return executors[pod.Name] },
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	do := func(method, path, token, body string) (int, string) {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.StatusCode, string(raw)
+	}
+	const tasksPath = "/v1/namespaces/default/batchsandboxes/sbx/tasks"
+
+	code, _ := do(http.MethodGet, tasksPath, "", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = do(http.MethodGet, tasksPath, "invalid", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = do(http.MethodGet, tasksPath, "bob", "")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = do(http.MethodGet, "/v1/namespaces/default/batchsandboxes/missing/tasks", "alice", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = do(http.MethodGet, "/v1/namespaces/default/batchsandboxes/templated/tasks", "alice", "")
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = do(http.MethodPost, tasksPath, "alice", "{")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPost, tasksPath, "", "{")
+	assert.Equal(t, http.StatusUnauthorized, code, "the body is not read before the caller is authorized")
+	code, _ = do(http.MethodPost, tasksPath, "bob", "{")
+	assert.Equal(t, http.StatusForbidden, code, "the body is not read before the caller is authorized")
+
+	code, body := do(http.MethodPost, tasksPath, "alice", `{"tasks":[{"name":"t0"},{"name":"t1"},{"name":"t2"}]}`)
+	assert.Equal(t, http.StatusOK, code)
+	resp := &TaskDispatchResponse{}
+	assert.NoError(t, json.Unmarshal([]byte(body), resp))
+	assert.Len(t, resp.Pods, 3)
+	assert.Equal(t, PodTaskResult{Index: 0, Pod: "pod-a", Task: &api.Task{Name: "t0"}}, resp.Pods[0])
+	assert.Equal(t, PodTaskResult{Index: 1, Pod: "pod-b", Error: "connection refused"}, resp.Pods[1])
+	assert.Equal(t, PodTaskResult{Index: 2, Pod: "pod-c", Error: "pod is not serving"}, resp.Pods[2])
+	assert.Equal(t, "create", reviewed[len(reviewed)-1].Verb)
+	assert.Equal(t, "tasks", reviewed[len(reviewed)-1].Subresource)
+	assert.Equal(t, "sbx", reviewed[len(reviewed)-1].Name)
+
+	// a null entry leaves the pod untouched, an unnamed task clears it
+	code, body = do(http.MethodPost, tasksPath, "alice", `{"tasks":[{}]}`)
+	assert.Equal(t, http.StatusOK, code)
+	resp = &TaskDispatchResponse{}
+	assert.NoError(t, json.Unmarshal([]byte(body), resp))
+	assert.Equal(t, []PodTaskResult{{Index: 0, Pod: "pod-a"}}, resp.Pods)
+	assert.Nil(t, executors["pod-a"].task)
+
+	executors["pod-a"].task = &api.Task{Name: "t0"}
+	code, body = do(http.MethodGet, tasksPath, "alice", "")
+	assert.Equal(t, http.StatusOK, code)
+	resp = &TaskDispatchResponse{}
+	assert.NoError(t, json.Unmarshal([]byte(body), resp))
+	assert.Len(t, resp.Pods, 3)
+	assert.Equal(t, "t0", resp.Pods[0].Task.Name)
+	assert.Equal(t, "get", reviewed[len(reviewed)-1].Verb)
+}
+
+func TestManagerAPIServerRequiresCertificate(t *testing.T) {
+	srv := &ManagerAPIServer{BindAddress: "127.0.0.1:0"}
+	assert.ErrorContains(t, srv.Start(context.Background()), "requires a serving certificate")
+}
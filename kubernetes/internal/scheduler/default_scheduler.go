@@ -16,7 +16,9 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -52,6 +54,8 @@ type taskNode struct {
 	IP      string
 	PodName string
 
+	podNamespace string
+
 	// collect from endpoints
 	tState              TaskState
 	tStateLastTransTime *time.Time
@@ -141,11 +145,18 @@ type taskClient interface {
 }
 
 const (
-	defaultTimeout        time.Duration = 3 * time.Second
+	defaultTimeout time.Duration = 3 * time.Second
+	// defaultDispatchBudget caps the time a Schedule spends dispatching tasks, so task-executors that do not
+	// answer hold up the reconcile for at most that long.
+	defaultDispatchBudget time.Duration = 10 * time.Second
 	defaultTaskPort                     = "5758"
 	defaultSchConcurrency int           = 10
 )
 
+// ErrDispatchIncomplete is returned by Schedule when the dispatch budget or the context ran out before every
+// task node was dispatched. The next Schedule starts with the nodes left over.
+var ErrDispatchIncomplete = errors.New("task dispatch incomplete")
+
 func newTaskClient(ip string) taskClient {
 	return api.NewClient(fmtEndpoint(ip))
 }
@@ -162,22 +173,34 @@ type defaultTaskScheduler struct {
 	taskNodeByNameIndex map[string]*taskNode
 
 	maxConcurrency int
+	// dispatchBudget caps the time scheduleTaskNodes dispatches for, 0 for no cap. nextDispatch is the
+	// index of the task node it starts with, the first one left over when the last dispatch ran out.
+	dispatchBudget time.Duration
+	nextDispatch   int
 	once           sync.Once
 
 	taskStatusCollector       taskStatusCollector
 	taskClientCreator         taskClientCreator
 	resPolicyWhenTaskComplete sandboxv1alpha1.TaskResourcePolicy
+	placement                 PlacementPolicy
+	endpoints                 *EndpointCache
 	name                      string
 	logger                    logr.Logger
+
+	// holdFailed keeps failed tasks assigned under the Release policy, see SetHoldFailed.
+	holdFailed bool
 }
 
 func newTaskScheduler(name string, tasks []*api.Task, pods []*corev1.Pod, resPolicyWhenTaskComplete sandboxv1alpha1.TaskResourcePolicy, logger logr.Logger) (*defaultTaskScheduler, error) {
 	sch := &defaultTaskScheduler{
 		allPods:                   pods,
 		maxConcurrency:            defaultSchConcurrency,
+		dispatchBudget:            defaultDispatchBudget,
 		taskClientCreator:         newTaskClient,
 		taskStatusCollector:       newTaskStatusCollector(newTaskClient, logger),
 		resPolicyWhenTaskComplete: resPolicyWhenTaskComplete,
+		placement:                 indexOrderPlacement{},
+		endpoints:                 PodEndpoints,
 		name:                      name,
 		logger:                    logger,
 	}
@@ -205,16 +228,27 @@ func indexByName(taskNodes []*taskNode) map[string]*taskNode {
 	return ret
 }
 
-func (sch *defaultTaskScheduler) Schedule() error {
+func (sch *defaultTaskScheduler) Schedule(ctx context.Context) error {
+	sch.refreshEndpoints()
 	sch.refreshFreePods()
-	sch.collectTaskStatus(sch.taskNodes)
-	return sch.scheduleTaskNodes()
+	if err := sch.collectTaskStatus(ctx, sch.taskNodes); err != nil {
+		return err
+	}
+	return sch.scheduleTaskNodes(ctx)
 }
 
 func (sch *defaultTaskScheduler) UpdatePods(pods []*corev1.Pod) {
 	sch.allPods = pods
 }
 
+func (sch *defaultTaskScheduler) SetPlacementPolicy(placement PlacementPolicy) {
+	sch.placement = placement
+}
+
+func (sch *defaultTaskScheduler) SetHoldFailed(hold bool) {
+	sch.holdFailed = hold
+}
+
 // AddTasks registers task specs that are not yet tracked by the scheduler.
 // Tasks whose names are already tracked are silently skipped, making this
 // safe to call with the full task list during a scale-out reconciliation.
@@ -271,8 +305,9 @@ func initTaskNodes(tasks []*api.Task) ([]*taskNode, error) {
 	return taskNodes, nil
 }
 
-// collectTaskStatus from Pod via endpoint
-func (sch *defaultTaskScheduler) collectTaskStatus(taskNodes []*taskNode) {
+// collectTaskStatus from Pod via endpoint. The statuses are kept as they are when ctx is done, since a
+// task-executor not asked says nothing about its task.
+func (sch *defaultTaskScheduler) collectTaskStatus(ctx context.Context, taskNodes []*taskNode) error {
 	ips := []string{}
 	for _, tNode := range taskNodes {
 		// unassigned no need to collect task status
@@ -282,9 +317,12 @@ func (sch *defaultTaskScheduler) collectTaskStatus(taskNodes []*taskNode) {
 		ips = append(ips, tNode.IP)
 	}
 	if len(ips) == 0 {
-		return
+		return nil
+	}
+	tasks := sch.taskStatusCollector.Collect(ctx, ips)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to collect task status: %w", err)
 	}
-	tasks := sch.taskStatusCollector.Collect(context.Background(), ips)
 	for _, tNode := range taskNodes {
 		task, ok := tasks[tNode.IP]
 		tNode.Status = task
@@ -292,6 +330,7 @@ func (sch *defaultTaskScheduler) collectTaskStatus(taskNodes []*taskNode) {
 			tNode.transTaskState(parseTaskState(task), sch.logger)
 		}
 	}
+	return nil
 }
 
 func parseTaskState(task *api.Task) TaskState {
@@ -331,26 +370,84 @@ func parsePodTaskState(status *corev1.PodStatus) TaskState {
 	return UnknownTaskState
 }
 
-func (sch *defaultTaskScheduler) scheduleTaskNodes() error {
-	sch.freePods = assignTaskNodes(sch.taskNodes, sch.freePods, sch.logger)
+// scheduleTaskNodes dispatches the task nodes until the dispatch budget or ctx runs out, starting with the
+// nodes left over by the last dispatch so that task-executors that do not answer cannot starve the others.
+func (sch *defaultTaskScheduler) scheduleTaskNodes(ctx context.Context) error {
+	sch.freePods = assignTaskNodes(sch.placementOrder(), sch.freePods, sch.logger)
+	if sch.dispatchBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sch.dispatchBudget)
+		defer cancel()
+	}
 	semaphore := make(chan struct{}, sch.maxConcurrency)
 	var wg sync.WaitGroup
-	for idx := range sch.taskNodes {
-		tNode := sch.taskNodes[idx]
-		semaphore <- struct{}{}
+	size := len(sch.taskNodes)
+	start := 0
+	if size > 0 {
+		start = sch.nextDispatch % size
+	}
+	sch.nextDispatch = 0
+	for i := range size {
+		idx := (start + i) % size
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			wg.Wait()
+			sch.nextDispatch = idx
+			sch.logger.Info("task dispatch ran out of time", "scheduler", sch.name, "dispatched", i, "remaining", size-i)
+			return fmt.Errorf("%w: %d of %d task nodes left", ErrDispatchIncomplete, size-i, size)
+		}
 		wg.Add(1)
 		go func(node *taskNode) {
 			defer func() {
 				<-semaphore
 				wg.Done()
 			}()
-			scheduleSingleTaskNode(node, sch.taskClientCreator, sch.resPolicyWhenTaskComplete, sch.logger)
-		}(tNode)
+			scheduleSingleTaskNode(ctx, node, sch.taskClientCreator, sch.releasePolicy(node), sch.logger)
+		}(sch.taskNodes[idx])
 	}
 	wg.Wait()
 	return nil
 }
 
+// placementOrder returns the task nodes in the order they should be assigned free pods.
+// Assigned nodes are skipped by assignTaskNodes, so only the relative order of pending nodes matters.
+func (sch *defaultTaskScheduler) placementOrder() []*taskNode {
+	if sch.placement == nil {
+		return sch.taskNodes
+	}
+	if _, ok := sch.placement.(indexOrderPlacement); ok {
+		return sch.taskNodes
+	}
+	ordered := make([]*taskNode, len(sch.taskNodes))
+	copy(ordered, sch.taskNodes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return sch.placement.Less(ordered[i], ordered[j])
+	})
+	return ordered
+}
+
+// refreshEndpoints points assigned task nodes at the current IP of their pod, which changes when the
+// pod is recreated under the same name. The IP is kept until the recreated pod is assigned a new one.
+func (sch *defaultTaskScheduler) refreshEndpoints() {
+	if sch.endpoints == nil {
+		return
+	}
+	for _, tNode := range sch.taskNodes {
+		if tNode.IP == "" || tNode.PodName == "" {
+			continue
+		}
+		ip, ok := sch.endpoints.Lookup(tNode.podNamespace, tNode.PodName)
+		if !ok || ip == "" || ip == tNode.IP {
+			continue
+		}
+		sch.logger.Info("task node endpoint changed", "taskName", tNode.Name, "podName", tNode.PodName, "from", tNode.IP, "to", ip)
+		tNode.IP = ip
+	}
+}
+
 // refreshFreePods updates the freePods slice based on allPods and currently assigned pods
 // This ensures that each pod is only assigned to one taskNode
 // Only pods with IP addresses are considered free for assignment
@@ -385,11 +482,21 @@ func assignTaskNodes(taskNodes []*taskNode, freePods []*corev1.Pod, log logr.Log
 		log.Info("assign Pod to task node", "podName", pod.Name, "podNamespace", pod.Namespace, "podIP", pod.Status.PodIP, "taskName", tNode.Name)
 		tNode.IP = pod.Status.PodIP
 		tNode.PodName = pod.Name
+		tNode.podNamespace = pod.Namespace
 		freePods = freePods[1:]
 	}
 	return freePods
 }
 
+// releasePolicy returns the resource policy of the task node once its task completes: Retain for a failed
+// task while failed tasks are held, else the policy of the scheduler.
+func (sch *defaultTaskScheduler) releasePolicy(tNode *taskNode) sandboxv1alpha1.TaskResourcePolicy {
+	if sch.holdFailed && tNode.tState == FailedTaskState {
+		return sandboxv1alpha1.TaskResourcePolicyRetain
+	}
+	return sch.resPolicyWhenTaskComplete
+}
+
 func needRelease(tNode *taskNode, policy sandboxv1alpha1.TaskResourcePolicy) bool {
 	if tNode.DeletionTimestamp != nil {
 		return true
@@ -401,7 +508,7 @@ func needRelease(tNode *taskNode, policy sandboxv1alpha1.TaskResourcePolicy) boo
 }
 
 // scheduleSingleTaskNode handles scheduling for a single task node based on its state
-func scheduleSingleTaskNode(tNode *taskNode, taskClientCreator func(endpoint string) taskClient, resPolicyWhenTaskComplete sandboxv1alpha1.TaskResourcePolicy, log logr.Logger) {
+func scheduleSingleTaskNode(ctx context.Context, tNode *taskNode, taskClientCreator func(endpoint string) taskClient, resPolicyWhenTaskComplete sandboxv1alpha1.TaskResourcePolicy, log logr.Logger) {
 	// pending
 	if tNode.IP == "" {
 		if tNode.DeletionTimestamp != nil {
@@ -419,7 +526,7 @@ func scheduleSingleTaskNode(tNode *taskNode, taskClientCreator func(endpoint str
 					Process:         tNode.Spec.Process,
 					PodTemplateSpec: tNode.Spec.PodTemplateSpec,
 				}
-				_, err := setTask(taskClientCreator(tNode.IP), task, log)
+				_, err := setTask(ctx, taskClientCreator(tNode.IP), task, log)
 				if err != nil {
 					log.Error(err, "Failed to set task", "taskName", tNode.Name, "endpoint", tNode.IP)
 				}
@@ -430,7 +537,7 @@ func scheduleSingleTaskNode(tNode *taskNode, taskClientCreator func(endpoint str
 		if tNode.isTaskDeleted() {
 			tNode.transSchState(stateReleased, log)
 		} else {
-			_, err := setTask(taskClientCreator(tNode.IP), nil, log)
+			_, err := setTask(ctx, taskClientCreator(tNode.IP), nil, log)
 			if err != nil {
 				log.Error(err, "Failed to notify executor about releasing task", "taskName", tNode.Name, "endpoint", tNode.IP)
 			} else {
@@ -440,8 +547,8 @@ func scheduleSingleTaskNode(tNode *taskNode, taskClientCreator func(endpoint str
 	}
 }
 
-func setTask(client taskClient, task *api.Task, log logr.Logger) (*api.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+func setTask(ctx context.Context, client taskClient, task *api.Task, log logr.Logger) (*api.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
 	verboseLog := log.V(3)
 	if verboseLog.Enabled() {
@@ -15,6 +15,8 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -212,7 +214,7 @@ func Test_scheduleSingleTaskNode(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			scheduleSingleTaskNode(tt.args.tNode, tt.args.taskClientCreator, "", testLogger)
+			scheduleSingleTaskNode(context.Background(), tt.args.tNode, tt.args.taskClientCreator, "", testLogger)
 			if !reflect.DeepEqual(tt.expectTaskNode, tt.args.tNode) {
 				t.Errorf("scheduleSingleTaskNode, want %+v, got %+v", tt.expectTaskNode, tt.args.tNode)
 			}
@@ -642,7 +644,7 @@ func Test_collectTaskStatus(t *testing.T) {
 			}
 
 			// Call collectTaskStatus
-			sch.collectTaskStatus(tt.taskNodes)
+			sch.collectTaskStatus(context.Background(), tt.taskNodes)
 
 			// Verify results
 			for i, expectedNode := range tt.expectedTaskNodes {
@@ -1101,7 +1103,7 @@ func Test_scheduleTaskNodes(t *testing.T) {
 			}
 
 			// Call scheduleTaskNodes
-			err := sch.scheduleTaskNodes()
+			err := sch.scheduleTaskNodes(context.Background())
 
 			// Verify no error
 			if err != nil {
@@ -1133,6 +1135,79 @@ func Test_scheduleTaskNodes(t *testing.T) {
 	}
 }
 
+// hangingTaskClient answers Set only when its context is done, like a task-executor behind a black hole.
+type hangingTaskClient struct {
+	ip    string
+	calls chan<- string
+}
+
+func (c *hangingTaskClient) Set(ctx context.Context, _ *api.Task) (*api.Task, error) {
+	c.calls <- c.ip
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *hangingTaskClient) Get(ctx context.Context) (*api.Task, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func Test_scheduleTaskNodes_DispatchBudget(t *testing.T) {
+	calls := make(chan string, 10)
+	var taskNodes []*taskNode
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		taskNodes = append(taskNodes, &taskNode{
+			ObjectMeta: metav1.ObjectMeta{Name: "task-" + ip},
+			Spec:       taskSpec{Process: &api.Process{Command: []string{"sleep", "1"}}},
+			IP:         ip,
+			PodName:    "pod-" + ip,
+		})
+	}
+	sch := &defaultTaskScheduler{
+		taskNodes:      taskNodes,
+		maxConcurrency: 1,
+		dispatchBudget: 50 * time.Millisecond,
+		taskClientCreator: func(ip string) taskClient {
+			return &hangingTaskClient{ip: ip, calls: calls}
+		},
+		logger: testLogger,
+	}
+
+	start := time.Now()
+	err := sch.scheduleTaskNodes(context.Background())
+	if !errors.Is(err, ErrDispatchIncomplete) {
+		t.Fatalf("scheduleTaskNodes() error = %v, want ErrDispatchIncomplete", err)
+	}
+	if elapsed := time.Since(start); elapsed > defaultTimeout {
+		t.Errorf("scheduleTaskNodes() took %v, want it bounded by the dispatch budget", elapsed)
+	}
+	if got := <-calls; got != "10.0.0.1" {
+		t.Errorf("first dispatch to %s, want 10.0.0.1", got)
+	}
+	if sch.nextDispatch != 1 {
+		t.Errorf("nextDispatch = %d, want 1", sch.nextDispatch)
+	}
+
+	// The next dispatch starts with the nodes left over.
+	_ = sch.scheduleTaskNodes(context.Background())
+	if got := <-calls; got != "10.0.0.2" {
+		t.Errorf("next dispatch to %s, want 10.0.0.2", got)
+	}
+
+	// A canceled context dispatches nothing.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sch.nextDispatch = 0
+	if err := sch.scheduleTaskNodes(ctx); !errors.Is(err, ErrDispatchIncomplete) {
+		t.Errorf("scheduleTaskNodes() error = %v, want ErrDispatchIncomplete", err)
+	}
+	select {
+	case ip := <-calls:
+		t.Errorf("unexpected dispatch to %s", ip)
+	default:
+	}
+}
+
 func Test_parseTaskState(t *testing.T) {
 	mockTimeNow := time.Now()
 
@@ -1195,6 +1270,55 @@ func Test_parseTaskState(t *testing.T) {
 	}
 }
 
+func Test_releasePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     sandboxv1alpha1.TaskResourcePolicy
+		holdFailed bool
+		state      TaskState
+		expected   sandboxv1alpha1.TaskResourcePolicy
+	}{
+		{
+			name:     "failed task released without hold",
+			policy:   sandboxv1alpha1.TaskResourcePolicyRelease,
+			state:    FailedTaskState,
+			expected: sandboxv1alpha1.TaskResourcePolicyRelease,
+		},
+		{
+			name:       "failed task retained while held",
+			policy:     sandboxv1alpha1.TaskResourcePolicyRelease,
+			holdFailed: true,
+			state:      FailedTaskState,
+			expected:   sandboxv1alpha1.TaskResourcePolicyRetain,
+		},
+		{
+			name:       "succeeded task released while held",
+			policy:     sandboxv1alpha1.TaskResourcePolicyRelease,
+			holdFailed: true,
+			state:      SucceedTaskState,
+			expected:   sandboxv1alpha1.TaskResourcePolicyRelease,
+		},
+		{
+			name:       "retain policy kept",
+			policy:     sandboxv1alpha1.TaskResourcePolicyRetain,
+			holdFailed: true,
+			state:      SucceedTaskState,
+			expected:   sandboxv1alpha1.TaskResourcePolicyRetain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := &defaultTaskScheduler{resPolicyWhenTaskComplete: tt.policy}
+			sch.SetHoldFailed(tt.holdFailed)
+			result := sch.releasePolicy(&taskNode{tState: tt.state})
+			if result != tt.expected {
+				t.Errorf("releasePolicy() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func Test_initTaskNodes(t *testing.T) {
 	type args struct {
 		tasks []*api.Task
@@ -0,0 +1,105 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// PodEndpoints is the endpoint cache shared by the task schedulers. It is fed by pod informer events.
+var PodEndpoints = NewEndpointCache()
+
+type podEndpoint struct {
+	key types.NamespacedName
+	ip  string
+}
+
+// EndpointCache tracks the IPs of pods. Entries are keyed by pod UID, so that a late event of a deleted
+// pod does not overwrite the endpoint of the pod recreated under the same name.
+type EndpointCache struct {
+	mu     sync.RWMutex
+	byUID  map[types.UID]podEndpoint
+	byName map[types.NamespacedName]types.UID
+}
+
+func NewEndpointCache() *EndpointCache {
+	return &EndpointCache{
+		byUID:  map[types.UID]podEndpoint{},
+		byName: map[types.NamespacedName]types.UID{},
+	}
+}
+
+// Set records the IP of the pod.
+func (c *EndpointCache) Set(pod *corev1.Pod) {
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byUID[pod.UID] = podEndpoint{key: key, ip: pod.Status.PodIP}
+	c.byName[key] = pod.UID
+}
+
+// Delete forgets the pod.
+func (c *EndpointCache) Delete(pod *corev1.Pod) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ep, ok := c.byUID[pod.UID]
+	if !ok {
+		return
+	}
+	delete(c.byUID, pod.UID)
+	if c.byName[ep.key] == pod.UID {
+		delete(c.byName, ep.key)
+	}
+}
+
+// Lookup returns the IP of the current pod with the given name, which is empty until the pod is
+// assigned one.
+func (c *EndpointCache) Lookup(namespace, name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	uid, ok := c.byName[types.NamespacedName{Namespace: namespace, Name: name}]
+	if !ok {
+		return "", false
+	}
+	return c.byUID[uid].ip, true
+}
+
+// EventHandler returns the informer event handler that keeps the cache up to date.
+func (c *EndpointCache) EventHandler() toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				c.Set(pod)
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			if pod, ok := newObj.(*corev1.Pod); ok {
+				c.Set(pod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*corev1.Pod); ok {
+				c.Delete(pod)
+			}
+		},
+	}
+}
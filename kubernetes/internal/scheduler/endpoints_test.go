@@ -0,0 +1,81 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newEndpointPod(name, uid, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(uid)},
+		Status:     corev1.PodStatus{PodIP: ip},
+	}
+}
+
+func TestEndpointCache(t *testing.T) {
+	cache := NewEndpointCache()
+	handler := cache.EventHandler()
+	old := newEndpointPod("pod-1", "uid-1", "1.1.1.1")
+	handler.OnAdd(old, false)
+	if ip, ok := cache.Lookup("default", "pod-1"); !ok || ip != "1.1.1.1" {
+		t.Fatalf("Lookup() = %q, %v, want 1.1.1.1", ip, ok)
+	}
+
+	// The pod is recreated under the same name before the deletion of the old one is observed.
+	recreated := newEndpointPod("pod-1", "uid-2", "")
+	handler.OnAdd(recreated, false)
+	handler.OnDelete(old)
+	if ip, ok := cache.Lookup("default", "pod-1"); !ok || ip != "" {
+		t.Fatalf("Lookup() = %q, %v, want the recreated pod without IP", ip, ok)
+	}
+	handler.OnUpdate(recreated, newEndpointPod("pod-1", "uid-2", "1.1.1.2"))
+	if ip, ok := cache.Lookup("default", "pod-1"); !ok || ip != "1.1.1.2" {
+		t.Fatalf("Lookup() = %q, %v, want 1.1.1.2", ip, ok)
+	}
+
+	handler.OnDelete(recreated)
+	if _, ok := cache.Lookup("default", "pod-1"); ok {
+		t.Fatalf("Lookup() found a deleted pod")
+	}
+}
+
+func Test_refreshEndpoints(t *testing.T) {
+	cache := NewEndpointCache()
+	cache.Set(newEndpointPod("pod-1", "uid-1", "1.1.1.9"))
+	cache.Set(newEndpointPod("pod-2", "uid-2", ""))
+	sch := &defaultTaskScheduler{
+		taskNodes: []*taskNode{
+			{ObjectMeta: metav1.ObjectMeta{Name: "task-1"}, IP: "1.1.1.1", PodName: "pod-1", podNamespace: "default"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "task-2"}, IP: "1.1.1.2", PodName: "pod-2", podNamespace: "default"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "task-3"}, IP: "1.1.1.3", PodName: "pod-3", podNamespace: "default"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "task-4"}},
+		},
+		endpoints: cache,
+		logger:    testLogger,
+	}
+	sch.refreshEndpoints()
+
+	want := []string{"1.1.1.9", "1.1.1.2", "1.1.1.3", ""}
+	for i, tNode := range sch.taskNodes {
+		if tNode.IP != want[i] {
+			t.Errorf("taskNode[%d].IP = %q, want %q", i, tNode.IP, want[i])
+		}
+	}
+}
@@ -15,6 +15,8 @@
 package scheduler
 
 import (
+	"context"
+
 	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
 	apis "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 
@@ -23,8 +25,16 @@ import (
 )
 
 type TaskScheduler interface {
-	Schedule() error
+	// Schedule collects the status of the tasks and dispatches them to the task-executors. Each call to a
+	// task-executor is bounded by ctx, and dispatching by a budget per Schedule; ErrDispatchIncomplete
+	// reports that the budget ran out with task nodes left over for the next Schedule.
+	Schedule(ctx context.Context) error
 	UpdatePods(pod []*corev1.Pod)
+	// SetPlacementPolicy sets the order in which pending tasks are assigned free pods.
+	SetPlacementPolicy(placement PlacementPolicy)
+	// SetHoldFailed keeps the pods of failed tasks from being released on completion while hold is true.
+	// Stopped tasks are released regardless.
+	SetHoldFailed(hold bool)
 	ListTask() []Task
 	StopTask() []Task
 	// AddTasks registers task specs that are not yet tracked by the scheduler.
@@ -36,3 +46,8 @@ type TaskScheduler interface {
 func NewTaskScheduler(name string, tasks []*apis.Task, pods []*corev1.Pod, resPolicyWhenTaskCompleted sandboxv1alpha1.TaskResourcePolicy, logger logr.Logger) (TaskScheduler, error) {
 	return newTaskScheduler(name, tasks, pods, resPolicyWhenTaskCompleted, logger)
 }
+
+// TaskExecutorEndpoint returns the base URL of the task-executor serving in the pod with the given IP.
+func TaskExecutorEndpoint(podIP string) string {
+	return fmtEndpoint(podIP)
+}
@@ -5,6 +5,7 @@
 package mock_scheduler
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -66,17 +67,41 @@ func (mr *MockTaskSchedulerMockRecorder) ListTask() *gomock.Call {
 }
 
 // Schedule mocks base method.
-func (m *MockTaskScheduler) Schedule() error {
+func (m *MockTaskScheduler) Schedule(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Schedule")
+	ret := m.ctrl.Call(m, "Schedule", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Schedule indicates an expected call of Schedule.
-func (mr *MockTaskSchedulerMockRecorder) Schedule() *gomock.Call {
+func (mr *MockTaskSchedulerMockRecorder) Schedule(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockTaskScheduler)(nil).Schedule))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockTaskScheduler)(nil).Schedule), ctx)
+}
+
+// SetHoldFailed mocks base method.
+func (m *MockTaskScheduler) SetHoldFailed(hold bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetHoldFailed", hold)
+}
+
+// SetHoldFailed indicates an expected call of SetHoldFailed.
+func (mr *MockTaskSchedulerMockRecorder) SetHoldFailed(hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHoldFailed", reflect.TypeOf((*MockTaskScheduler)(nil).SetHoldFailed), hold)
+}
+
+// SetPlacementPolicy mocks base method.
+func (m *MockTaskScheduler) SetPlacementPolicy(placement scheduler.PlacementPolicy) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPlacementPolicy", placement)
+}
+
+// SetPlacementPolicy indicates an expected call of SetPlacementPolicy.
+func (mr *MockTaskSchedulerMockRecorder) SetPlacementPolicy(placement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPlacementPolicy", reflect.TypeOf((*MockTaskScheduler)(nil).SetPlacementPolicy), placement)
 }
 
 // StopTask mocks base method.
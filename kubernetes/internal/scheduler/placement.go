@@ -0,0 +1,78 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"time"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+// PlacementHint is what a placement policy knows about a task besides its index.
+type PlacementHint struct {
+	// Priority places higher values first.
+	Priority int32
+	// EstimatedDuration is the declared run time of the task, nil if not declared.
+	EstimatedDuration *time.Duration
+}
+
+// PlacementPolicy orders pending tasks when they compete for free pods.
+type PlacementPolicy interface {
+	// Less reports whether pending task a should get a pod before b.
+	// Tasks that neither precedes keep their index order.
+	Less(a, b Task) bool
+}
+
+// NewPlacementPolicy returns the placement policy of the given type. Hints are keyed by task name.
+func NewPlacementPolicy(policy sandboxv1alpha1.TaskPlacementPolicy, hints map[string]PlacementHint) PlacementPolicy {
+	switch policy {
+	case sandboxv1alpha1.TaskPlacementPolicyShortestFirst:
+		return &shortestFirstPlacement{hints: hints}
+	case sandboxv1alpha1.TaskPlacementPolicyPriority:
+		return &priorityPlacement{hints: hints}
+	default:
+		return indexOrderPlacement{}
+	}
+}
+
+// indexOrderPlacement places tasks in index order.
+type indexOrderPlacement struct{}
+
+func (indexOrderPlacement) Less(_, _ Task) bool {
+	return false
+}
+
+// shortestFirstPlacement places tasks with a smaller declared duration first and tasks without one last.
+type shortestFirstPlacement struct {
+	hints map[string]PlacementHint
+}
+
+func (p *shortestFirstPlacement) Less(a, b Task) bool {
+	da := p.hints[a.GetName()].EstimatedDuration
+	db := p.hints[b.GetName()].EstimatedDuration
+	if da == nil || db == nil {
+		return da != nil && db == nil
+	}
+	return *da < *db
+}
+
+// priorityPlacement places tasks with a higher priority first.
+type priorityPlacement struct {
+	hints map[string]PlacementHint
+}
+
+func (p *priorityPlacement) Less(a, b Task) bool {
+	return p.hints[a.GetName()].Priority > p.hints[b.GetName()].Priority
+}
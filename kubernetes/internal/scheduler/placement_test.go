@@ -0,0 +1,120 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sandboxv1alpha1 "github.com/alibaba/OpenSandbox/sandbox-k8s/apis/sandbox/v1alpha1"
+)
+
+func Test_placementOrder(t *testing.T) {
+	hints := map[string]PlacementHint{
+		"test-0": {Priority: 1, EstimatedDuration: ptr.To(30 * time.Second)},
+		"test-1": {Priority: 5},
+		"test-2": {Priority: 1, EstimatedDuration: ptr.To(10 * time.Second)},
+		"test-3": {EstimatedDuration: ptr.To(10 * time.Second)},
+	}
+	tests := []struct {
+		name      string
+		placement PlacementPolicy
+		want      []string
+	}{
+		{
+			name:      "nil placement keeps index order",
+			placement: nil,
+			want:      []string{"test-0", "test-1", "test-2", "test-3"},
+		},
+		{
+			name:      "index order",
+			placement: NewPlacementPolicy(sandboxv1alpha1.TaskPlacementPolicyIndexOrder, hints),
+			want:      []string{"test-0", "test-1", "test-2", "test-3"},
+		},
+		{
+			name:      "shortest first, ties keep index order, undeclared last",
+			placement: NewPlacementPolicy(sandboxv1alpha1.TaskPlacementPolicyShortestFirst, hints),
+			want:      []string{"test-2", "test-3", "test-0", "test-1"},
+		},
+		{
+			name:      "priority, ties keep index order",
+			placement: NewPlacementPolicy(sandboxv1alpha1.TaskPlacementPolicyPriority, hints),
+			want:      []string{"test-1", "test-0", "test-2", "test-3"},
+		},
+		{
+			name:      "unknown policy falls back to index order",
+			placement: NewPlacementPolicy("Random", hints),
+			want:      []string{"test-0", "test-1", "test-2", "test-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := &defaultTaskScheduler{
+				taskNodes: []*taskNode{
+					{ObjectMeta: v1.ObjectMeta{Name: "test-0"}},
+					{ObjectMeta: v1.ObjectMeta{Name: "test-1"}},
+					{ObjectMeta: v1.ObjectMeta{Name: "test-2"}},
+					{ObjectMeta: v1.ObjectMeta{Name: "test-3"}},
+				},
+				placement: tt.placement,
+			}
+			var got []string
+			for _, tNode := range sch.placementOrder() {
+				got = append(got, tNode.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("placementOrder() = %v, want %v", got, tt.want)
+			}
+			if sch.taskNodes[0].Name != "test-0" || sch.taskNodes[3].Name != "test-3" {
+				t.Errorf("placementOrder() reordered the task nodes in place")
+			}
+		})
+	}
+}
+
+func Test_placementOrder_partialAllocation(t *testing.T) {
+	sch := &defaultTaskScheduler{
+		taskNodes: []*taskNode{
+			{ObjectMeta: v1.ObjectMeta{Name: "test-0"}, IP: "1.1.1.1", PodName: "pod-a"},
+			{ObjectMeta: v1.ObjectMeta{Name: "test-1"}},
+			{ObjectMeta: v1.ObjectMeta{Name: "test-2"}},
+		},
+		placement: NewPlacementPolicy(sandboxv1alpha1.TaskPlacementPolicyPriority, map[string]PlacementHint{
+			"test-0": {Priority: 100},
+			"test-2": {Priority: 10},
+		}),
+	}
+	freePods := []*corev1.Pod{
+		{ObjectMeta: v1.ObjectMeta{Name: "pod-b"}, Status: corev1.PodStatus{PodIP: "2.2.2.2"}},
+	}
+	left := assignTaskNodes(sch.placementOrder(), freePods, testLogger)
+	if len(left) != 0 {
+		t.Fatalf("expected all free pods assigned, left %v", left)
+	}
+	if sch.taskNodes[0].PodName != "pod-a" {
+		t.Errorf("assigned task moved from pod-a to %q", sch.taskNodes[0].PodName)
+	}
+	if sch.taskNodes[2].PodName != "pod-b" {
+		t.Errorf("expected the higher priority pending task on pod-b, got %q", sch.taskNodes[2].PodName)
+	}
+	if sch.taskNodes[1].IP != "" {
+		t.Errorf("expected test-1 to stay pending, got IP %q", sch.taskNodes[1].IP)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth authenticates task-executor API callers with Kubernetes
+// projected service-account tokens.
+//
+// Callers send a token projected for the sidecar audience as a bearer token.
+// It is verified with a TokenReview, or locally against the cluster JWKS. The
+// modes, environment variables and subject allowlist match the execd and
+// egress sidecars (components/internal/k8sauth), so one projected token works
+// for every sidecar of a sandbox.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/jwks"
+)
+
+const (
+	ModeTokenReview = "tokenreview"
+	ModeJWKS        = "jwks"
+
+	DefaultAudience = "opensandbox-sidecar"
+
+	EnvAuthMode            = "OPENSANDBOX_AUTH_MODE"
+	EnvAuthAudience        = "OPENSANDBOX_AUTH_AUDIENCE"
+	EnvAuthIssuer          = "OPENSANDBOX_AUTH_ISSUER"
+	EnvAuthJWKSURL         = "OPENSANDBOX_AUTH_JWKS_URL"
+	EnvAuthAllowedSubjects = "OPENSANDBOX_AUTH_ALLOWED_SUBJECTS"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrForbidden    = errors.New("subject is not allowed")
+)
+
+// Identity is the authenticated caller.
+type Identity = jwks.Identity
+
+// Verifier checks a bearer token and returns its identity.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Identity, error)
+}
+
+// Authenticator verifies bearer tokens and checks the subject allowlist.
+// Allowlist entries are usernames, or groups written as "group:<name>"; an
+// empty allowlist admits nobody.
+type Authenticator struct {
+	verifier Verifier
+	allowed  []string
+}
+
+func NewAuthenticator(v Verifier, allowedSubjects []string) *Authenticator {
+	return &Authenticator{verifier: v, allowed: allowedSubjects}
+}
+
+// NewInClusterAuthenticator builds an Authenticator with the pod service
+// account. It returns nil when mode is empty, and an error when no subject is
+// allowed.
+func NewInClusterAuthenticator(mode, audience, issuer, jwksURL string, allowedSubjects []string) (*Authenticator, error) {
+	if mode == "" {
+		return nil, nil
+	}
+	if len(allowedSubjects) == 0 {
+		return nil, fmt.Errorf("auth mode %q requires at least one allowed subject", mode)
+	}
+	if audience == "" {
+		audience = DefaultAudience
+	}
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+	var v Verifier
+	switch mode {
+	case ModeTokenReview:
+		client, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+		v = NewTokenReviewVerifier(client, audience)
+	case ModeJWKS:
+		httpClient, err := rest.HTTPClientFor(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes http client: %w", err)
+		}
+		if jwksURL == "" {
+			jwksURL = strings.TrimSuffix(cfg.Host, "/") + "/openid/v1/jwks"
+		} else if !jwks.SameOrigin(jwksURL, cfg.Host) {
+			// Do not send the pod's credentials to a third-party issuer.
+			httpClient = http.DefaultClient
+		}
+		v = jwks.NewVerifier(httpClient, jwksURL, issuer, audience, nil)
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q, want %q or %q", mode, ModeTokenReview, ModeJWKS)
+	}
+	return NewAuthenticator(v, allowedSubjects), nil
+}
+
+// Authenticate verifies the bearer token of r.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token := BearerToken(r)
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	id, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allows(id) {
+		return id, fmt.Errorf("%w: %s", ErrForbidden, id.Username)
+	}
+	return id, nil
+}
+
+func (a *Authenticator) allows(id *Identity) bool {
+	for _, s := range a.allowed {
+		if group, ok := strings.CutPrefix(s, "group:"); ok {
+			if slices.Contains(id.Groups, group) {
+				return true
+			}
+		} else if s == id.Username {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rejects requests without a valid token. /health stays open for
+// probes.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, err := a.Authenticate(r); err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or "".
+func BearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// SplitSubjects parses a comma-separated allowlist.
+func SplitSubjects(s string) []string {
+	var ret []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTokenReviewVerifier(t *testing.T) {
+	client := fake.NewClientset()
+	reviews := 0
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		tr := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		assert.Equal(t, []string{DefaultAudience}, tr.Spec.Audiences)
+		if tr.Spec.Token == "good" {
+			tr.Status.Authenticated = true
+			tr.Status.Audiences = tr.Spec.Audiences
+			tr.Status.User.Username = "system:serviceaccount:opensandbox-system:controller"
+		} else {
+			tr.Status.Error = "invalid bearer token"
+		}
+		return true, tr, nil
+	})
+	v := NewTokenReviewVerifier(client, DefaultAudience)
+	ctx := context.Background()
+
+	id, err := v.Verify(ctx, "good")
+	assert.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:opensandbox-system:controller", id.Username)
+	_, err = v.Verify(ctx, "good")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reviews, "second verification should be cached")
+
+	_, err = v.Verify(ctx, "bad")
+	assert.ErrorContains(t, err, "invalid bearer token")
+}
+
+type staticVerifier map[string]*Identity
+
+func (s staticVerifier) Verify(_ context.Context, token string) (*Identity, error) {
+	if id, ok := s[token]; ok {
+		return id, nil
+	}
+	return nil, assert.AnError
+}
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	a := NewAuthenticator(staticVerifier{
+		"controller": {Username: "system:serviceaccount:opensandbox-system:controller"},
+		"admin":      {Username: "alice", Groups: []string{"sandbox-admins"}},
+		"other":      {Username: "bob"},
+	}, SplitSubjects("system:serviceaccount:opensandbox-system:controller, group:sandbox-admins"))
+	h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path  string
+		token string
+		want  int
+	}{
+		{path: "/health", want: http.StatusOK},
+		{path: "/getTasks", want: http.StatusUnauthorized},
+		{path: "/getTasks", token: "forged", want: http.StatusUnauthorized},
+		{path: "/getTasks", token: "other", want: http.StatusForbidden},
+		{path: "/getTasks", token: "controller", want: http.StatusOK},
+		{path: "/setTasks", token: "admin", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, tt.want, w.Code, "%s with token %q", tt.path, tt.token)
+	}
+}
+
+func TestAuthenticator_EmptyAllowlist(t *testing.T) {
+	a := NewAuthenticator(staticVerifier{"controller": {Username: "controller"}}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/getTasks", nil)
+	req.Header.Set("Authorization", "Bearer controller")
+	_, err := a.Authenticate(req)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = NewInClusterAuthenticator(ModeJWKS, "", "", "", nil)
+	assert.ErrorContains(t, err, "allowed subject")
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	tokenReviewCacheTTL  = 10 * time.Second
+	tokenReviewCacheSize = 1024
+)
+
+// TokenReviewVerifier verifies tokens with the TokenReview API, so tokens of
+// deleted pods and service accounts are rejected. The executor's service
+// account needs create on tokenreviews (system:auth-delegator). Successful
+// reviews are cached briefly so a burst of requests costs one API call.
+type TokenReviewVerifier struct {
+	client   kubernetes.Interface
+	audience string
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedReview
+	now   func() time.Time
+}
+
+type cachedReview struct {
+	id      *Identity
+	expires time.Time
+}
+
+func NewTokenReviewVerifier(client kubernetes.Interface, audience string) *TokenReviewVerifier {
+	return &TokenReviewVerifier{
+		client:   client,
+		audience: audience,
+		cache:    make(map[[sha256.Size]byte]cachedReview),
+		now:      time.Now,
+	}
+}
+
+func (v *TokenReviewVerifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	key := sha256.Sum256([]byte(token))
+	now := v.now()
+	v.mu.Lock()
+	if c, ok := v.cache[key]; ok && now.Before(c.expires) {
+		v.mu.Unlock()
+		return c.id, nil
+	}
+	v.mu.Unlock()
+
+	tr, err := v.client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: []string{v.audience}},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !tr.Status.Authenticated {
+		if tr.Status.Error != "" {
+			return nil, fmt.Errorf("token rejected: %s", tr.Status.Error)
+		}
+		return nil, errors.New("token rejected")
+	}
+	if !slices.Contains(tr.Status.Audiences, v.audience) {
+		return nil, fmt.Errorf("token is not issued for audience %q", v.audience)
+	}
+	id := &Identity{
+		Username: tr.Status.User.Username,
+		UID:      tr.Status.User.UID,
+		Groups:   tr.Status.User.Groups,
+	}
+
+	v.mu.Lock()
+	if len(v.cache) >= tokenReviewCacheSize {
+		clear(v.cache)
+	}
+	v.cache[key] = cachedReview{id: id, expires: now.Add(tokenReviewCacheTTL)}
+	v.mu.Unlock()
+	return id, nil
+}
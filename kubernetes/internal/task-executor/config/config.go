@@ -18,41 +18,107 @@ import (
 	"flag"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
-	"k8s.io/klog/v2"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/logging"
 )
 
 type Config struct {
-	DataDir           string
-	ListenAddr        string
-	CRISocket         string
-	ReadTimeout       time.Duration
-	WriteTimeout      time.Duration
-	ReconcileInterval time.Duration
-	EnableSidecarMode bool
-	MainContainerName string
-	LogMaxSize        int
-	LogMaxBackups     int
-	LogMaxAge         int
-	LogDir            string
+	// mu guards the Tunables fields, which change while the executor runs.
+	mu sync.RWMutex
+
+	DataDir           string        `json:"dataDir"`
+	ListenAddr        string        `json:"listenAddr"`
+	CRISocket         string        `json:"criSocket"`
+	ReadTimeout       time.Duration `json:"readTimeout"`
+	WriteTimeout      time.Duration `json:"writeTimeout"`
+	ReconcileInterval time.Duration `json:"reconcileInterval"`
+	EnableSidecarMode bool          `json:"enableSidecarMode"`
+	MainContainerName string        `json:"mainContainerName"`
+	LogMaxSize        int           `json:"logMaxSize"`
+	LogMaxBackups     int           `json:"logMaxBackups"`
+	LogMaxAge         int           `json:"logMaxAge"`
+	LogDir            string        `json:"logDir"`
+	// LogFormat is "json" (default) or "text" for the plain klog format.
+	// LogLevel is "debug", "info", "warn", "error" or a klog verbosity and
+	// can be changed at runtime through the /loglevel endpoint or Tunables.
+	LogFormat string `json:"logFormat"`
+	LogLevel  string `json:"logLevel"`
+	// SandboxID is added to every log line when set.
+	SandboxID string `json:"sandboxID"`
+	// EnableSelfUpdate allows replacing the executor binary in place through
+	// the API. Updates must be signed by SelfUpdatePublicKey and requested
+	// with the token read from SelfUpdateTokenFile.
+	EnableSelfUpdate    bool   `json:"enableSelfUpdate"`
+	SelfUpdatePublicKey string `json:"selfUpdatePublicKey"`
+	SelfUpdateTokenFile string `json:"selfUpdateTokenFile"`
+	// ReportExecutorReady sets the executor-ready condition on the pod named
+	// by PodNamespace/PodName once the API is serving.
+	ReportExecutorReady bool   `json:"reportExecutorReady"`
+	PodNamespace        string `json:"podNamespace"`
+	PodName             string `json:"podName"`
+	// AuthMode enables projected service-account token auth on the API:
+	// "tokenreview" or "jwks". AuthAllowedSubjects is a comma-separated list
+	// of usernames and "group:<name>" entries.
+	AuthMode            string `json:"authMode"`
+	AuthAudience        string `json:"authAudience"`
+	AuthIssuer          string `json:"authIssuer"`
+	AuthJWKSURL         string `json:"authJWKSURL"`
+	AuthAllowedSubjects string `json:"authAllowedSubjects"`
+	// RecordingDir enables session recording: task state changes are recorded
+	// there next to the records of execd and egress, and POST /recording/seal
+	// uploads everything to RecordingUploadURL, an https URL, with the bearer
+	// token read from RecordingUploadTokenFile. RecordingKeyFile holds the key
+	// the record chain is signed with; it must not be readable by the workload.
+	RecordingDir             string `json:"recordingDir"`
+	RecordingKeyFile         string `json:"recordingKeyFile"`
+	RecordingUploadURL       string `json:"recordingUploadURL"`
+	RecordingUploadTokenFile string `json:"recordingUploadTokenFile"`
+	// RetainedTaskTTL is how long a task deleted without purge is kept, with
+	// its logs, after the deletion. Zero keeps retained tasks until purged.
+	RetainedTaskTTL time.Duration `json:"retainedTaskTTL"`
+	// MaxConcurrentTasks is how many tasks may be active at once.
+	MaxConcurrentTasks int `json:"maxConcurrentTasks"`
+	// ImageDir is where the root filesystems of image tasks are unpacked, as
+	// seen from the main container in sidecar mode.
+	ImageDir string `json:"imageDir"`
+	// MaxCachedImages is how many unpacked images are kept under ImageDir
+	// when no task runs in them; 0 keeps all of them.
+	MaxCachedImages int `json:"maxCachedImages"`
+	// ConfigFile holds Tunables that are applied at startup and again on
+	// SIGHUP.
+	ConfigFile string `json:"configFile"`
+	// StoreKey, a base64 AES key, encrypts the task files in DataDir. It is
+	// read from StoreKeyFile, such as a mounted Secret, when not set inline.
+	StoreKey     string `json:"-"`
+	StoreKeyFile string `json:"storeKeyFile"`
 }
 
 func NewConfig() *Config {
 	return &Config{
-		DataDir:           "/var/lib/sandbox/tasks",
-		ListenAddr:        "0.0.0.0:5758",
-		CRISocket:         "/var/run/containerd/containerd.sock",
-		ReadTimeout:       30 * time.Second,
-		WriteTimeout:      30 * time.Second,
-		ReconcileInterval: 500 * time.Millisecond,
-		EnableSidecarMode: false,
-		MainContainerName: "main",
-		LogMaxSize:        100,
-		LogMaxBackups:     10,
-		LogMaxAge:         7,
-		LogDir:            "logs",
+		DataDir:            "/var/lib/sandbox/tasks",
+		ListenAddr:         "0.0.0.0:5758",
+		CRISocket:          "/var/run/containerd/containerd.sock",
+		ReadTimeout:        30 * time.Second,
+		WriteTimeout:       30 * time.Second,
+		ReconcileInterval:  500 * time.Millisecond,
+		EnableSidecarMode:  false,
+		MainContainerName:  "main",
+		LogMaxSize:         100,
+		LogMaxBackups:      10,
+		LogMaxAge:          7,
+		LogDir:             "logs",
+		LogFormat:          logging.FormatJSON,
+		LogLevel:           "info",
+		RetainedTaskTTL:    24 * time.Hour,
+		MaxConcurrentTasks: 1,
+		ImageDir:           "/var/lib/sandbox/images",
+		MaxCachedImages:    3,
 	}
 }
 
@@ -72,6 +138,87 @@ func (c *Config) LoadFromEnv() {
 	if v := os.Getenv("MAIN_CONTAINER_NAME"); v != "" {
 		c.MainContainerName = v
 	}
+	if v := os.Getenv("ENABLE_SELF_UPDATE"); v == "true" {
+		c.EnableSelfUpdate = true
+	}
+	if v := os.Getenv("SELF_UPDATE_PUBLIC_KEY"); v != "" {
+		c.SelfUpdatePublicKey = v
+	}
+	if v := os.Getenv("SELF_UPDATE_TOKEN_FILE"); v != "" {
+		c.SelfUpdateTokenFile = v
+	}
+	if v := os.Getenv("REPORT_EXECUTOR_READY"); v == "true" {
+		c.ReportExecutorReady = true
+	}
+	if v := os.Getenv("POD_NAMESPACE"); v != "" {
+		c.PodNamespace = v
+	}
+	if v := os.Getenv("POD_NAME"); v != "" {
+		c.PodName = v
+	}
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		c.LogFormat = v
+	}
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv("OPENSANDBOX_ID"); v != "" {
+		c.SandboxID = v
+	}
+	if v := os.Getenv("OPENSANDBOX_AUTH_MODE"); v != "" {
+		c.AuthMode = strings.ToLower(strings.TrimSpace(v))
+	}
+	if v := os.Getenv("OPENSANDBOX_AUTH_AUDIENCE"); v != "" {
+		c.AuthAudience = v
+	}
+	if v := os.Getenv("OPENSANDBOX_AUTH_ISSUER"); v != "" {
+		c.AuthIssuer = v
+	}
+	if v := os.Getenv("OPENSANDBOX_AUTH_JWKS_URL"); v != "" {
+		c.AuthJWKSURL = v
+	}
+	if v := os.Getenv("OPENSANDBOX_AUTH_ALLOWED_SUBJECTS"); v != "" {
+		c.AuthAllowedSubjects = v
+	}
+	if v := os.Getenv("OPENSANDBOX_RECORDING_DIR"); v != "" {
+		c.RecordingDir = v
+	}
+	if v := os.Getenv("OPENSANDBOX_RECORDING_KEY_FILE"); v != "" {
+		c.RecordingKeyFile = v
+	}
+	if v := os.Getenv("RECORDING_UPLOAD_URL"); v != "" {
+		c.RecordingUploadURL = v
+	}
+	if v := os.Getenv("RECORDING_UPLOAD_TOKEN_FILE"); v != "" {
+		c.RecordingUploadTokenFile = v
+	}
+	if v := os.Getenv("RETAINED_TASK_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.RetainedTaskTTL = d
+		}
+	}
+	if v := os.Getenv("MAX_CONCURRENT_TASKS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.MaxConcurrentTasks = n
+		}
+	}
+	if v := os.Getenv("IMAGE_DIR"); v != "" {
+		c.ImageDir = v
+	}
+	if v := os.Getenv("MAX_CACHED_IMAGES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			c.MaxCachedImages = n
+		}
+	}
+	if v := os.Getenv("CONFIG_FILE"); v != "" {
+		c.ConfigFile = v
+	}
+	if v := os.Getenv("STORE_ENCRYPTION_KEY"); v != "" {
+		c.StoreKey = v
+	}
+	if v := os.Getenv("STORE_ENCRYPTION_KEY_FILE"); v != "" {
+		c.StoreKeyFile = v
+	}
 }
 
 func (c *Config) LoadFromFlags() {
@@ -80,28 +227,41 @@ func (c *Config) LoadFromFlags() {
 	flag.StringVar(&c.CRISocket, "cri-socket", c.CRISocket, "CRI socket path for container runner mode")
 	flag.BoolVar(&c.EnableSidecarMode, "enable-sidecar-mode", c.EnableSidecarMode, "enable sidecar runner mode")
 	flag.StringVar(&c.MainContainerName, "main-container-name", c.MainContainerName, "main container name")
+	flag.BoolVar(&c.EnableSelfUpdate, "enable-self-update", c.EnableSelfUpdate, "allow replacing the executor binary in place via the API")
+	flag.StringVar(&c.SelfUpdatePublicKey, "self-update-public-key", c.SelfUpdatePublicKey, "base64 ed25519 public key that self-update manifests must be signed with")
+	flag.StringVar(&c.SelfUpdateTokenFile, "self-update-token-file", c.SelfUpdateTokenFile, "file holding the token self-update requests must carry")
+	flag.BoolVar(&c.ReportExecutorReady, "report-executor-ready", c.ReportExecutorReady, "set the executor-ready pod condition once serving")
+	flag.StringVar(&c.AuthMode, "auth-mode", c.AuthMode, "verify projected service-account tokens with \"tokenreview\" or \"jwks\"; empty disables auth")
+	flag.StringVar(&c.AuthAudience, "auth-audience", c.AuthAudience, "audience tokens must be issued for (default opensandbox-sidecar)")
+	flag.StringVar(&c.AuthAllowedSubjects, "auth-allowed-subjects", c.AuthAllowedSubjects, "comma-separated usernames and group:<name> entries allowed to call the API")
+	flag.StringVar(&c.RecordingDir, "recording-dir", c.RecordingDir, "shared session recording directory; empty disables recording")
+	flag.StringVar(&c.RecordingKeyFile, "recording-key-file", c.RecordingKeyFile, "file holding the key session records are signed with; required with --recording-dir")
+	flag.StringVar(&c.RecordingUploadURL, "recording-upload-url", c.RecordingUploadURL, "https base URL sealed session records are uploaded to with PUT")
+	flag.StringVar(&c.RecordingUploadTokenFile, "recording-upload-token-file", c.RecordingUploadTokenFile, "file holding the bearer token session record uploads carry")
+	flag.DurationVar(&c.RetainedTaskTTL, "retained-task-ttl", c.RetainedTaskTTL, "how long deleted tasks are kept with their logs until purged; 0 keeps them until an explicit purge")
+	flag.IntVar(&c.MaxConcurrentTasks, "max-concurrent-tasks", c.MaxConcurrentTasks, "maximum number of active tasks")
+	flag.StringVar(&c.ImageDir, "image-dir", c.ImageDir, "directory the root filesystems of image tasks are unpacked to, as seen from the main container")
+	flag.IntVar(&c.MaxCachedImages, "max-cached-images", c.MaxCachedImages, "unpacked images kept under --image-dir when no task runs in them; 0 keeps all")
+	flag.StringVar(&c.ConfigFile, "config-file", c.ConfigFile, "YAML or JSON file of runtime tunables, reloaded on SIGHUP")
+	flag.StringVar(&c.StoreKeyFile, "store-key-file", c.StoreKeyFile, "file holding a base64 AES key that task files are encrypted with; empty stores them in plain JSON")
 	// set log flags
 	flag.IntVar(&c.LogMaxSize, "log-max-size", c.LogMaxSize, "maximum log file size in MB")
 	flag.IntVar(&c.LogMaxBackups, "log-max-backups", c.LogMaxBackups, "maximum number of log backup files")
 	flag.IntVar(&c.LogMaxAge, "log-max-age", c.LogMaxAge, "maximum number of days to keep log files")
 	flag.StringVar(&c.LogDir, "log-dir", c.LogDir, "log file directory")
+	flag.StringVar(&c.LogFormat, "log-format", c.LogFormat, "log format: json or text")
+	flag.StringVar(&c.LogLevel, "log-level", c.LogLevel, "log level: debug, info, warn, error or a klog verbosity")
 	flag.Parse()
 }
 
 func (c *Config) InitKlog() error {
 	logFile := path.Join(c.LogDir, "task-executor.log")
-	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
-	klog.InitFlags(fs)
-	fs.Set("logtostderr", "false")
-	fs.Set("alsologtostderr", "false")
-	fs.Set("stderrthreshold", "FATAL")
-	fs.Set("one_output", "true")
-	klog.SetOutput(&lumberjack.Logger{
+	out := &lumberjack.Logger{
 		Filename:   logFile,
 		MaxSize:    c.LogMaxSize,
 		MaxBackups: c.LogMaxBackups,
 		MaxAge:     c.LogMaxAge,
 		Compress:   true,
-	})
-	return nil
+	}
+	return logging.Setup(out, c.LogFormat, c.LogLevel, logging.PodFields(c.PodName, c.PodNamespace, c.SandboxID)...)
 }
@@ -0,0 +1,128 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/logging"
+)
+
+// Tunables are the fields of Config that can be changed while the executor
+// runs, through ConfigFile or PATCH /config. Unset fields keep their value.
+type Tunables struct {
+	ReconcileInterval  *metav1.Duration `json:"reconcileInterval,omitempty"`
+	MaxConcurrentTasks *int             `json:"maxConcurrentTasks,omitempty"`
+	LogLevel           *string          `json:"logLevel,omitempty"`
+	RetainedTaskTTL    *metav1.Duration `json:"retainedTaskTTL,omitempty"`
+}
+
+// Apply validates t and applies all of it, or nothing if a value is invalid.
+func (c *Config) Apply(t Tunables) error {
+	if t.ReconcileInterval != nil && t.ReconcileInterval.Duration <= 0 {
+		return fmt.Errorf("reconcileInterval must be positive")
+	}
+	if t.MaxConcurrentTasks != nil && *t.MaxConcurrentTasks < 1 {
+		return fmt.Errorf("maxConcurrentTasks must be at least 1")
+	}
+	if t.RetainedTaskTTL != nil && t.RetainedTaskTTL.Duration < 0 {
+		return fmt.Errorf("retainedTaskTTL must not be negative")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.LogLevel != nil {
+		if err := logging.SetLevel(*t.LogLevel); err != nil {
+			return err
+		}
+		c.LogLevel = logging.Level()
+	}
+	if t.ReconcileInterval != nil {
+		c.ReconcileInterval = t.ReconcileInterval.Duration
+	}
+	if t.MaxConcurrentTasks != nil {
+		c.MaxConcurrentTasks = *t.MaxConcurrentTasks
+	}
+	if t.RetainedTaskTTL != nil {
+		c.RetainedTaskTTL = t.RetainedTaskTTL.Duration
+	}
+	return nil
+}
+
+// Reload applies the Tunables in ConfigFile, if set. Unknown fields are
+// rejected so that a typo does not go unnoticed.
+func (c *Config) Reload() error {
+	if c.ConfigFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.ConfigFile)
+	if err != nil {
+		return err
+	}
+	var t Tunables
+	if err := yaml.UnmarshalStrict(data, &t); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", c.ConfigFile, err)
+	}
+	return c.Apply(t)
+}
+
+// GetReconcileInterval returns the current ReconcileInterval.
+func (c *Config) GetReconcileInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ReconcileInterval
+}
+
+// GetMaxConcurrentTasks returns the current MaxConcurrentTasks, at least 1.
+func (c *Config) GetMaxConcurrentTasks() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return max(c.MaxConcurrentTasks, 1)
+}
+
+// GetRetainedTaskTTL returns the current RetainedTaskTTL.
+func (c *Config) GetRetainedTaskTTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.RetainedTaskTTL
+}
+
+// MarshalJSON reports the effective configuration, with durations as strings
+// and the log level as last set through any endpoint.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return json.Marshal(struct {
+		*plain
+		ReadTimeout       string `json:"readTimeout"`
+		WriteTimeout      string `json:"writeTimeout"`
+		ReconcileInterval string `json:"reconcileInterval"`
+		RetainedTaskTTL   string `json:"retainedTaskTTL"`
+		LogLevel          string `json:"logLevel"`
+	}{
+		plain:             (*plain)(c),
+		ReadTimeout:       c.ReadTimeout.String(),
+		WriteTimeout:      c.WriteTimeout.String(),
+		ReconcileInterval: c.ReconcileInterval.String(),
+		RetainedTaskTTL:   c.RetainedTaskTTL.String(),
+		LogLevel:          logging.Level(),
+	})
+}
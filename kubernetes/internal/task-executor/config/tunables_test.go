@@ -0,0 +1,47 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/logging"
+)
+
+func TestConfig_Reload(t *testing.T) {
+	t.Cleanup(func() { logging.SetLevel("info") })
+	cfg := NewConfig()
+	cfg.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+
+	require.NoError(t, os.WriteFile(cfg.ConfigFile, []byte("reconcileInterval: 1s\nlogLevel: debug\n"), 0o644))
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, time.Second, cfg.GetReconcileInterval())
+	assert.Equal(t, "debug", logging.Level())
+	assert.Equal(t, 24*time.Hour, cfg.GetRetainedTaskTTL(), "unset fields keep their value")
+
+	// A file with an unknown field or an invalid value is not applied.
+	require.NoError(t, os.WriteFile(cfg.ConfigFile, []byte("reconcileInterval: 2s\nlistenAddr: 0.0.0.0:1\n"), 0o644))
+	assert.Error(t, cfg.Reload())
+	require.NoError(t, os.WriteFile(cfg.ConfigFile, []byte("reconcileInterval: 2s\nlogLevel: loud\n"), 0o644))
+	assert.Error(t, cfg.Reload())
+	assert.Equal(t, time.Second, cfg.GetReconcileInterval())
+	assert.Equal(t, "debug", logging.Level())
+}
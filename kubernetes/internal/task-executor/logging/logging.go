@@ -0,0 +1,234 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging configures the task-executor log output.
+//
+// klog stays the logging API inside the executor. In JSON format its output
+// is routed through a slog JSON handler that writes the same keys as the
+// execd and egress sidecars (ts, level, msg, pod, namespace, sandbox_id,
+// task, trace_id), so the logs of one pod can be joined. The level can be
+// changed at runtime through LevelHandler.
+package logging
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Field keys shared with the other components.
+const (
+	FieldPod       = "pod"
+	FieldNamespace = "namespace"
+	FieldSandbox   = "sandbox_id"
+	FieldTask      = "task"
+	FieldTraceID   = "trace_id"
+)
+
+// debugVerbosity is the klog verbosity the "debug" level maps to.
+const debugVerbosity = 4
+
+var (
+	mu        sync.Mutex
+	current   = "info"
+	slogLevel = new(slog.LevelVar)
+
+	klogFlags     *flag.FlagSet
+	initKlogFlags sync.Once
+)
+
+// Setup directs klog to w in the given format and sets the initial level.
+// fields are attached to every line in JSON format.
+func Setup(w io.Writer, format, level string, fields ...any) error {
+	initKlogFlags.Do(func() {
+		klogFlags = flag.NewFlagSet("klog", flag.ContinueOnError)
+		klog.InitFlags(klogFlags)
+	})
+	klogFlags.Set("logtostderr", "false")
+	klogFlags.Set("alsologtostderr", "false")
+	klogFlags.Set("stderrthreshold", "FATAL")
+	klogFlags.Set("one_output", "true")
+	klog.SetOutput(w)
+
+	switch format {
+	case FormatText:
+	case FormatJSON, "":
+		handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slogLevel, ReplaceAttr: replaceAttr})
+		klog.SetSlogLogger(slog.New(handler).With(fields...))
+	default:
+		return fmt.Errorf("unknown log format %q, want %q or %q", format, FormatJSON, FormatText)
+	}
+	return SetLevel(level)
+}
+
+// PodFields returns the fields identifying the pod, skipping empty values.
+func PodFields(pod, namespace, sandboxID string) []any {
+	var fields []any
+	for _, kv := range [][2]string{{FieldPod, pod}, {FieldNamespace, namespace}, {FieldSandbox, sandboxID}} {
+		if kv[1] != "" {
+			fields = append(fields, kv[0], kv[1])
+		}
+	}
+	return fields
+}
+
+// SetLevel changes the level: "debug", "info", "warn", "error", or a klog
+// verbosity number. "debug" is klog verbosity 4; the other names keep klog
+// at verbosity 0 and drop lines below the level in JSON format.
+func SetLevel(level string) error {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if level == "" {
+		level = "info"
+	}
+	verbosity := 0
+	var min slog.Level
+	switch level {
+	case "debug":
+		verbosity = debugVerbosity
+		min = slog.Level(-debugVerbosity)
+	case "info":
+		min = slog.LevelInfo
+	case "warn", "warning":
+		level = "warn"
+		min = slog.LevelWarn
+	case "error":
+		min = slog.LevelError
+	default:
+		v, err := strconv.Atoi(level)
+		if err != nil || v < 0 {
+			return fmt.Errorf("unknown log level %q", level)
+		}
+		verbosity = v
+		min = slog.Level(-v)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if klogFlags != nil {
+		if err := klogFlags.Set("v", strconv.Itoa(verbosity)); err != nil {
+			return err
+		}
+	}
+	slogLevel.Set(min)
+	current = level
+	return nil
+}
+
+// Level returns the current level as given to SetLevel.
+func Level() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler reports the level on GET as {"level":"info"} and changes it on
+// PUT with the same body, like the level endpoints of execd and egress.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+				return
+			}
+			if err := SetLevel(req.Level); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			klog.InfoS("log level changed", "level", Level())
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, levelPayload{Level: Level()})
+	})
+}
+
+// TraceMiddleware puts a logger carrying the trace ID of the W3C traceparent
+// header into the request context, for handlers logging through
+// klog.FromContext.
+func TraceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := traceID(r.Header.Get("traceparent")); id != "" {
+			logger := klog.FromContext(r.Context()).WithValues(FieldTraceID, id)
+			r = r.WithContext(klog.NewContext(r.Context(), logger))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// traceID extracts the trace ID from a traceparent header of the form
+// version-traceid-parentid-flags.
+func traceID(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return ""
+	}
+	id := strings.ToLower(parts[1])
+	if strings.Trim(id, "0") == "" || strings.Trim(id, "0123456789abcdef") != "" {
+		return ""
+	}
+	return id
+}
+
+// replaceAttr renames the slog time and level keys to the ones written by
+// the other components.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String("ts", a.Value.Time().Format("2006-01-02T15:04:05.000Z0700"))
+	case slog.LevelKey:
+		level, _ := a.Value.Any().(slog.Level)
+		switch {
+		case level < slog.LevelInfo:
+			return slog.String(slog.LevelKey, "debug")
+		case level < slog.LevelWarn:
+			return slog.String(slog.LevelKey, "info")
+		case level < slog.LevelError:
+			return slog.String(slog.LevelKey, "warn")
+		default:
+			return slog.String(slog.LevelKey, "error")
+		}
+	}
+	return a
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
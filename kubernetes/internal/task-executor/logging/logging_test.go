@@ -0,0 +1,135 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/klog/v2"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m), line)
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestSetupJSON(t *testing.T) {
+	t.Cleanup(func() {
+		klog.ClearLogger()
+		_ = SetLevel("info")
+	})
+	out := &syncBuffer{}
+	require.NoError(t, Setup(out, FormatJSON, "info", PodFields("sbx-0", "default", "")...))
+
+	klog.V(1).InfoS("hidden", FieldTask, "t0")
+	klog.InfoS("shown", FieldTask, "t0")
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+	klog.V(2).InfoS("verbose", FieldTask, "t0")
+
+	lines := out.lines(t)
+	require.Len(t, lines, 3)
+	assert.Equal(t, "shown", lines[0]["msg"])
+	assert.Equal(t, "info", lines[0]["level"])
+	assert.Equal(t, "sbx-0", lines[0][FieldPod])
+	assert.Equal(t, "default", lines[0][FieldNamespace])
+	assert.Equal(t, "t0", lines[0][FieldTask])
+	assert.Contains(t, lines[0], "ts")
+	assert.NotContains(t, lines[0], FieldSandbox)
+	assert.Equal(t, "log level changed", lines[1]["msg"])
+	assert.Equal(t, "verbose", lines[2]["msg"])
+	assert.Equal(t, "debug", lines[2]["level"])
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Cleanup(func() { _ = SetLevel("info") })
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"loud"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"Warning"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "warn", Level())
+
+	rec = httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/loglevel", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestTraceID(t *testing.T) {
+	cases := map[string]string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": "",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01": "",
+		"": "",
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, traceID(header), header)
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	t.Cleanup(klog.ClearLogger)
+	out := &syncBuffer{}
+	require.NoError(t, Setup(out, FormatJSON, "info"))
+
+	h := TraceMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		klog.FromContext(r.Context()).Info("handled")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/getTasks", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/getTasks", nil))
+
+	lines := out.lines(t)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", lines[0][FieldTraceID])
+	assert.NotContains(t, lines[1], FieldTraceID)
+}
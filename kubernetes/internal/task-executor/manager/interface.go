@@ -16,23 +16,44 @@ package manager
 
 import (
 	"context"
+	"io"
 
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 )
 
 // TaskManager defines the contract for managing tasks in memory.
 type TaskManager interface {
 	Create(ctx context.Context, task *types.Task) (*types.Task, error)
 	// Sync synchronizes the current task list with the desired state.
-	// It deletes tasks not in the desired list and creates new ones.
-	// Returns the current task list after synchronization.
-	Sync(ctx context.Context, desired []*types.Task) ([]*types.Task, error)
+	// It purges tasks not in the desired list, creates new ones and restarts the ones whose spec changed,
+	// leaving unchanged tasks running. Returns the current task list after synchronization and what the
+	// sync did to each task, by name.
+	Sync(ctx context.Context, desired []*types.Task) ([]*types.Task, map[string]api.SyncAction, error)
 
 	Get(ctx context.Context, id string) (*types.Task, error)
 
 	List(ctx context.Context) ([]*types.Task, error)
 
+	// Delete stops a task and retains it, with its logs, until it is purged or its retention expires.
+	// Retained tasks do not count towards the concurrency limit.
 	Delete(ctx context.Context, id string) error
+	// Purge stops a task and removes it, with its logs, once it has stopped. It also removes
+	// retained tasks.
+	Purge(ctx context.Context, id string) error
+
+	// Freeze suspends every running task in place and returns the names of the tasks it froze.
+	Freeze(ctx context.Context) ([]string, error)
+	// Thaw continues every unfinished task and returns their names.
+	Thaw(ctx context.Context) ([]string, error)
+	// Pause suspends a single running task in place, like Freeze, and returns it.
+	Pause(ctx context.Context, id string) (*types.Task, error)
+	// Resume continues a single paused task, like Thaw, and returns it.
+	Resume(ctx context.Context, id string) (*types.Task, error)
+
+	// Logs returns the output of a task. A followed log ends once the task finishes or is removed.
+	Logs(ctx context.Context, id string, opts runtime.LogOptions) (io.ReadCloser, error)
 
 	Start(ctx context.Context)
 
@@ -0,0 +1,97 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+)
+
+var (
+	taskTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opensandbox_task_executor_task_transitions_total",
+		Help: "Task state transitions applied by the task-executor.",
+	}, []string{"from", "to"})
+
+	taskTransitionsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opensandbox_task_executor_task_transitions_rejected_total",
+		Help: "Task state transitions rejected by the task-executor as invalid.",
+	}, []string{"from", "to"})
+
+	taskStartupSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "opensandbox_task_executor_task_startup_seconds",
+		Help: "Time tasks spent in each step of their startup: persist (API receipt to store), spawn (store to process start, including image pulls), first_output (process start to first output) and total (API receipt to first output).",
+		// 1ms to about 33s
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
+	}, []string{"step"})
+)
+
+const (
+	startupStepPersist     = "persist"
+	startupStepSpawn       = "spawn"
+	startupStepFirstOutput = "first_output"
+	startupStepTotal       = "total"
+)
+
+func init() {
+	prometheus.MustRegister(taskTransitions, taskTransitionsRejected, taskStartupSeconds)
+}
+
+// stateLabel names the state of a task that has none yet.
+func stateLabel(state types.TaskState) string {
+	if state == "" {
+		return "New"
+	}
+	return string(state)
+}
+
+// countTransition is a TransitionHook that counts state changes.
+func countTransition(_ context.Context, task *types.Task, from types.TaskState) {
+	if from != task.Status.State {
+		taskTransitions.WithLabelValues(stateLabel(from), stateLabel(task.Status.State)).Inc()
+	}
+}
+
+// observeStartup records the time from start to end in the histogram of the step, when both are known.
+func observeStartup(step string, start, end *time.Time) {
+	if start == nil || end == nil {
+		return
+	}
+	taskStartupSeconds.WithLabelValues(step).Observe(max(end.Sub(*start), 0).Seconds())
+}
+
+// recordStartup is a TransitionHook that completes the timings of the task from its sub-status and
+// observes each step once, when its end is first seen. It runs before the status is persisted.
+func recordStartup(_ context.Context, task *types.Task, _ types.TaskState) {
+	if task.Timings == nil || len(task.Status.SubStatuses) == 0 {
+		return
+	}
+	timings, sub := task.Timings, task.Status.SubStatuses[0]
+	if timings.SpawnedAt == nil && sub.StartedAt != nil {
+		spawnedAt := *sub.StartedAt
+		timings.SpawnedAt = &spawnedAt
+		observeStartup(startupStepSpawn, timings.PersistedAt, timings.SpawnedAt)
+	}
+	if timings.FirstOutputAt == nil && sub.FirstOutputAt != nil {
+		firstOutputAt := *sub.FirstOutputAt
+		timings.FirstOutputAt = &firstOutputAt
+		observeStartup(startupStepFirstOutput, timings.SpawnedAt, timings.FirstOutputAt)
+		observeStartup(startupStepTotal, timings.ReceivedAt, timings.FirstOutputAt)
+	}
+}
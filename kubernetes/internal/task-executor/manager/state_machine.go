@@ -0,0 +1,105 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+)
+
+// ErrInvalidTransition is returned when a status would move a task to a state it cannot reach from
+// its current one.
+var ErrInvalidTransition = errors.New("invalid task state transition")
+
+// allowedTransitions lists the states each state may move to. Staying in the same state is always
+// allowed so that sub-statuses can be refreshed. A new task may start in any state, the terminal
+// states have no way out, and a task in Unknown may move anywhere once the executor can tell again.
+var allowedTransitions = map[types.TaskState][]types.TaskState{
+	"": {
+		types.TaskStatePending, types.TaskStateRunning, types.TaskStateSucceeded, types.TaskStateFailed,
+		types.TaskStateUnknown, types.TaskStateNotFound, types.TaskStateTimeout,
+	},
+	types.TaskStatePending: {
+		types.TaskStateRunning, types.TaskStateSucceeded, types.TaskStateFailed,
+		types.TaskStateUnknown, types.TaskStateNotFound, types.TaskStateTimeout,
+	},
+	types.TaskStateRunning: {
+		types.TaskStateSucceeded, types.TaskStateFailed, types.TaskStateUnknown,
+		types.TaskStateNotFound, types.TaskStateTimeout,
+	},
+	types.TaskStateTimeout: {
+		types.TaskStateSucceeded, types.TaskStateFailed, types.TaskStateUnknown, types.TaskStateNotFound,
+	},
+	types.TaskStateUnknown: {
+		types.TaskStatePending, types.TaskStateRunning, types.TaskStateSucceeded, types.TaskStateFailed,
+		types.TaskStateNotFound, types.TaskStateTimeout,
+	},
+	types.TaskStateSucceeded: {},
+	types.TaskStateFailed:    {},
+	types.TaskStateNotFound:  {},
+}
+
+// CanTransition reports whether a task in state from may move to state to.
+func CanTransition(from, to types.TaskState) bool {
+	next, known := allowedTransitions[from]
+	if !known {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	for _, state := range next {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionHook is called after the status of a task changed. from is the state before the change;
+// it equals task.Status.State when only the sub-statuses changed.
+type TransitionHook func(ctx context.Context, task *types.Task, from types.TaskState)
+
+// stateMachine is the only place that writes task status, so every change is validated and seen by
+// the same hooks.
+type stateMachine struct {
+	hooks []TransitionHook
+}
+
+func newStateMachine(hooks ...TransitionHook) *stateMachine {
+	return &stateMachine{hooks: hooks}
+}
+
+// Transition sets the status of the task and runs the hooks. It returns false if the status did not
+// change, and ErrInvalidTransition, leaving the task untouched, if the new state is not reachable.
+func (sm *stateMachine) Transition(ctx context.Context, task *types.Task, status types.Status) (bool, error) {
+	from := task.Status.State
+	if reflect.DeepEqual(task.Status, status) {
+		return false, nil
+	}
+	if !CanTransition(from, status.State) {
+		taskTransitionsRejected.WithLabelValues(stateLabel(from), stateLabel(status.State)).Inc()
+		return false, fmt.Errorf("%w: task %s from %q to %q", ErrInvalidTransition, task.Name, from, status.State)
+	}
+	task.Status = status.DeepCopy()
+	for _, hook := range sm.hooks {
+		hook(ctx, task, from)
+	}
+	return true, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	store "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/storage"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+)
+
+func TestCanTransition(t *testing.T) {
+	const (
+		pending   = types.TaskStatePending
+		running   = types.TaskStateRunning
+		succeeded = types.TaskStateSucceeded
+		failed    = types.TaskStateFailed
+		unknown   = types.TaskStateUnknown
+		notFound  = types.TaskStateNotFound
+		timeout   = types.TaskStateTimeout
+	)
+	states := []types.TaskState{"", pending, running, succeeded, failed, unknown, notFound, timeout}
+	// allowed[from] lists every state reachable from from, including from itself.
+	allowed := map[types.TaskState][]types.TaskState{
+		"":        {"", pending, running, succeeded, failed, unknown, notFound, timeout},
+		pending:   {pending, running, succeeded, failed, unknown, notFound, timeout},
+		running:   {running, succeeded, failed, unknown, notFound, timeout},
+		timeout:   {timeout, succeeded, failed, unknown, notFound},
+		unknown:   {pending, running, succeeded, failed, unknown, notFound, timeout},
+		succeeded: {succeeded},
+		failed:    {failed},
+		notFound:  {notFound},
+	}
+	for _, from := range states {
+		for _, to := range states {
+			want := false
+			for _, s := range allowed[from] {
+				want = want || s == to
+			}
+			assert.Equal(t, want, CanTransition(from, to), "%q -> %q", from, to)
+		}
+	}
+	assert.False(t, CanTransition("Bogus", running))
+}
+
+func TestStateMachineTransition(t *testing.T) {
+	ctx := context.Background()
+	var calls []types.TaskState
+	sm := newStateMachine(func(_ context.Context, task *types.Task, from types.TaskState) {
+		calls = append(calls, from, task.Status.State)
+	})
+	task := &types.Task{Name: "task"}
+
+	changed, err := sm.Transition(ctx, task, types.Status{State: types.TaskStateRunning})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []types.TaskState{"", types.TaskStateRunning}, calls)
+
+	// Unchanged status does not run the hooks.
+	changed, err = sm.Transition(ctx, task, types.Status{State: types.TaskStateRunning})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Len(t, calls, 2)
+
+	// Sub-status updates within a state run the hooks.
+	changed, err = sm.Transition(ctx, task, types.Status{State: types.TaskStateRunning, SubStatuses: []types.SubStatus{{Reason: "Running"}}})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, calls, 4)
+
+	changed, err = sm.Transition(ctx, task, types.Status{State: types.TaskStateFailed})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = sm.Transition(ctx, task, types.Status{State: types.TaskStateRunning})
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.False(t, changed)
+	assert.Equal(t, types.TaskStateFailed, task.Status.State)
+	assert.Len(t, calls, 6)
+}
+
+func TestTaskManager_ReconcileRejectsRevivingFinishedTask(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{DataDir: t.TempDir(), ReconcileInterval: time.Second}
+	taskStore, err := store.NewFileStore(cfg.DataDir)
+	require.NoError(t, err)
+	exec := newFakeExecutor()
+	mgr, err := NewTaskManager(cfg, taskStore, exec)
+	require.NoError(t, err)
+	m := mgr.(*taskManager)
+
+	_, err = mgr.Create(ctx, &types.Task{Name: "task"})
+	require.NoError(t, err)
+
+	exec.inspect["task"] = &types.Status{State: types.TaskStateFailed}
+	m.reconcileTasks(ctx)
+	got, err := mgr.Get(ctx, "task")
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateFailed, got.Status.State)
+
+	exec.inspect["task"] = &types.Status{State: types.TaskStateRunning}
+	m.reconcileTasks(ctx)
+	got, err = mgr.Get(ctx, "task")
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateFailed, got.Status.State)
+
+	persisted, err := m.store.Get(ctx, "task")
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateFailed, persisted.Status.State)
+}
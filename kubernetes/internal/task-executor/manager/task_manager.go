@@ -15,25 +15,33 @@
 package manager
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"reflect"
+	"io"
+	"sort"
 	"sync"
 	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/recording"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
 	store "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/storage"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 )
 
-const (
-	maxConcurrentTasks = 1
-)
+// ErrTaskNotRunning is returned when a task cannot be paused because it is not running, or resumed
+// because it has finished.
+var ErrTaskNotRunning = errors.New("task is not running")
 
+// taskManager owns the tasks in its map: they are only read or written under mu, and every task that
+// enters or leaves the manager is deep-copied, so callers can serialize or modify what they get back
+// while the reconcile loop keeps updating status.
 type taskManager struct {
 	mu    sync.RWMutex
 	tasks map[string]*types.Task // name -> task
@@ -42,7 +50,10 @@ type taskManager struct {
 	executor runtime.Executor
 	config   *config.Config
 
+	states   *stateMachine
 	stopping map[string]bool
+	// replacements are the new specs of updated tasks, created once the old tasks have stopped.
+	replacements map[string]*types.Task
 
 	stopCh chan struct{}
 	doneCh chan struct{}
@@ -60,7 +71,7 @@ func NewTaskManager(cfg *config.Config, taskStore store.TaskStore, exec runtime.
 		return nil, fmt.Errorf("executor cannot be nil")
 	}
 
-	return &taskManager{
+	m := &taskManager{
 		tasks:    make(map[string]*types.Task),
 		store:    taskStore,
 		executor: exec,
@@ -68,7 +79,83 @@ func NewTaskManager(cfg *config.Config, taskStore store.TaskStore, exec runtime.
 		stopping: make(map[string]bool),
 		stopCh:   make(chan struct{}),
 		doneCh:   make(chan struct{}),
-	}, nil
+
+		replacements: make(map[string]*types.Task),
+	}
+	hooks := []TransitionHook{recordStartup, m.persistTransition, logTransition, countTransition}
+	if cfg.RecordingDir != "" {
+		if cfg.RecordingKeyFile == "" {
+			return nil, fmt.Errorf("session recording requires a key file")
+		}
+		key, err := recording.LoadKey(cfg.RecordingKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session recording key: %w", err)
+		}
+		recorder, err := recording.NewRecorder(cfg.RecordingDir, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session recorder: %w", err)
+		}
+		hooks = append(hooks, recordTransition(recorder))
+	}
+	m.states = newStateMachine(hooks...)
+	return m, nil
+}
+
+// persistTransition is a TransitionHook that writes the new status to the store.
+func (m *taskManager) persistTransition(ctx context.Context, task *types.Task, _ types.TaskState) {
+	if err := m.store.Update(ctx, task); err != nil {
+		klog.ErrorS(err, "failed to update task status in store", "task", task.Name)
+	}
+}
+
+// logTransition is a TransitionHook that logs state changes of existing tasks.
+func logTransition(_ context.Context, task *types.Task, from types.TaskState) {
+	if from != "" && from != task.Status.State {
+		klog.InfoS("task state changed", "task", task.Name, "oldState", from, "newState", task.Status.State)
+	}
+}
+
+// recordTransition returns a TransitionHook that adds state changes to the session record. The first
+// entry of a task carries what it runs.
+func recordTransition(recorder *recording.Recorder) TransitionHook {
+	return func(_ context.Context, task *types.Task, from types.TaskState) {
+		if from == task.Status.State {
+			return
+		}
+		fields := map[string]any{"task": task.Name, "from": stateLabel(from), "to": stateLabel(task.Status.State)}
+		if from == "" && task.Process != nil {
+			fields["command"] = task.Process.Command
+			fields["args"] = task.Process.Args
+		}
+		if err := recorder.Record(recording.TypeTask, fields); err != nil {
+			klog.ErrorS(err, "failed to record task state change", "task", task.Name)
+		}
+	}
+}
+
+// applyStatus moves the task to status through the state machine and logs rejected transitions.
+func (m *taskManager) applyStatus(ctx context.Context, task *types.Task, status types.Status) {
+	if _, err := m.states.Transition(ctx, task, status); err != nil {
+		klog.ErrorS(err, "ignoring task status", "task", task.Name)
+	}
+}
+
+// startTimings starts the timings of a new task, received now unless the API recorded when.
+func startTimings(task *types.Task) {
+	if task.Timings == nil {
+		task.Timings = &types.Timings{}
+	}
+	if task.Timings.ReceivedAt == nil {
+		now := time.Now()
+		task.Timings.ReceivedAt = &now
+	}
+}
+
+// persisted records that the task has been written to the store. The time is persisted with the next status.
+func persisted(task *types.Task) {
+	now := time.Now()
+	task.Timings.PersistedAt = &now
+	observeStartup(startupStepPersist, task.Timings.ReceivedAt, task.Timings.PersistedAt)
 }
 
 // isTaskActive checks if the task is counting towards the concurrency limit
@@ -108,46 +195,49 @@ func (m *taskManager) Create(ctx context.Context, task *types.Task) (*types.Task
 	if _, exists := m.tasks[task.Name]; exists {
 		return nil, fmt.Errorf("task %s already exists", task.Name)
 	}
+	task = task.DeepCopy()
 
-	if m.countActiveTasks() >= maxConcurrentTasks {
-		return nil, fmt.Errorf("maximum concurrent tasks (%d) reached, cannot create new task", maxConcurrentTasks)
+	if limit := m.config.GetMaxConcurrentTasks(); m.countActiveTasks() >= limit {
+		return nil, fmt.Errorf("maximum concurrent tasks (%d) reached, cannot create new task", limit)
 	}
 
+	startTimings(task)
 	if err := m.store.Create(ctx, task); err != nil {
 		return nil, fmt.Errorf("failed to persist task: %w", err)
 	}
+	persisted(task)
 
 	if err := m.executor.Start(ctx, task); err != nil {
 		if delErr := m.store.Delete(ctx, task.Name); delErr != nil {
-			klog.ErrorS(delErr, "failed to rollback task creation", "name", task.Name)
+			klog.ErrorS(delErr, "failed to rollback task creation", "task", task.Name)
 		}
 		return nil, fmt.Errorf("failed to start task: %w", err)
 	}
 
+	// Persist the PID and initial status
 	if status, err := m.executor.Inspect(ctx, task); err == nil {
-		task.Status = *status
-		// Persist the PID and initial status
-		if err := m.store.Update(ctx, task); err != nil {
-			klog.ErrorS(err, "failed to persist initial task status", "name", task.Name)
-		}
+		m.applyStatus(ctx, task, *status)
 	} else {
-		klog.ErrorS(err, "failed to inspect task after start", "name", task.Name)
+		klog.ErrorS(err, "failed to inspect task after start", "task", task.Name)
 	}
 
 	if task.Status.State == "" {
-		task.Status.State = types.TaskStatePending
+		m.applyStatus(ctx, task, types.Status{State: types.TaskStatePending})
 	}
 
 	m.tasks[task.Name] = task
+	// A task created by name supersedes an update still waiting for its old task to stop.
+	delete(m.replacements, task.Name)
 
-	klog.InfoS("task created successfully", "name", task.Name)
-	return task, nil
+	klog.InfoS("task created successfully", "task", task.Name)
+	return task.DeepCopy(), nil
 }
 
-// Sync synchronizes the current task list with the desired state
-func (m *taskManager) Sync(ctx context.Context, desired []*types.Task) ([]*types.Task, error) {
+// Sync synchronizes the current task list with the desired state. Tasks whose spec changed are deleted
+// and created again with the new spec once they have stopped.
+func (m *taskManager) Sync(ctx context.Context, desired []*types.Task) ([]*types.Task, map[string]api.SyncAction, error) {
 	if desired == nil {
-		return nil, fmt.Errorf("desired task list cannot be nil")
+		return nil, nil, fmt.Errorf("desired task list cannot be nil")
 	}
 
 	m.mu.Lock()
@@ -161,10 +251,13 @@ func (m *taskManager) Sync(ctx context.Context, desired []*types.Task) ([]*types
 	}
 
 	var syncErrors []error
+	actions := make(map[string]api.SyncAction, len(desiredMap))
 
 	for name, task := range m.tasks {
 		if _, ok := desiredMap[name]; !ok {
-			if err := m.softDeleteLocked(ctx, task); err != nil {
+			delete(m.replacements, name)
+			actions[name] = api.SyncActionDeleted
+			if err := m.softDeleteLocked(ctx, task, false); err != nil {
 				klog.ErrorS(err, "failed to delete task during sync", "name", name)
 				syncErrors = append(syncErrors, fmt.Errorf("failed to delete task %s: %w", name, err))
 			}
@@ -172,18 +265,79 @@ func (m *taskManager) Sync(ctx context.Context, desired []*types.Task) ([]*types
 	}
 
 	for name, task := range desiredMap {
-		if _, exists := m.tasks[name]; !exists {
+		current, exists := m.tasks[name]
+		switch {
+		case !exists:
+			delete(m.replacements, name)
+			actions[name] = api.SyncActionCreated
 			if err := m.createTaskLocked(ctx, task); err != nil {
 				klog.ErrorS(err, "failed to create task during sync", "name", name)
 				syncErrors = append(syncErrors, fmt.Errorf("failed to create task %s: %w", name, err))
 			}
+		case m.replacements[name] != nil:
+			// Still stopping for an earlier update; the latest spec wins.
+			m.replacements[name] = task.DeepCopy()
+			actions[name] = api.SyncActionUpdated
+		case current.DeletionTimestamp != nil || !specChanged(current, task):
+			actions[name] = api.SyncActionKept
+		default:
+			actions[name] = api.SyncActionUpdated
+			if err := m.replaceTaskLocked(ctx, current, task); err != nil {
+				klog.ErrorS(err, "failed to update task during sync", "name", name)
+				syncErrors = append(syncErrors, fmt.Errorf("failed to update task %s: %w", name, err))
+			}
 		}
 	}
 
 	if len(syncErrors) > 0 {
-		return m.listTasksLocked(), errors.Join(syncErrors...)
+		return m.listTasksLocked(), actions, errors.Join(syncErrors...)
+	}
+	return m.listTasksLocked(), actions, nil
+}
+
+// specChanged reports whether the desired task runs something else than the current one. Specs are compared
+// in their persisted form, so tasks recovered from the store compare equal to the ones they were created from.
+func specChanged(current, desired *types.Task) bool {
+	currentProcess, _ := json.Marshal(current.Process)
+	desiredProcess, _ := json.Marshal(desired.Process)
+	currentTemplate, _ := json.Marshal(current.PodTemplateSpec)
+	desiredTemplate, _ := json.Marshal(desired.PodTemplateSpec)
+	return !bytes.Equal(currentProcess, desiredProcess) || !bytes.Equal(currentTemplate, desiredTemplate)
+}
+
+// replaceTaskLocked restarts the task with the spec of desired. A finished task is replaced at once; a
+// running one is deleted, and the reconcile loop creates the replacement once it has stopped.
+func (m *taskManager) replaceTaskLocked(ctx context.Context, current, desired *types.Task) error {
+	if isTerminalState(current.Status.State) && !m.stopping[current.Name] {
+		if err := m.store.Delete(ctx, current.Name); err != nil {
+			return fmt.Errorf("failed to delete replaced task: %w", err)
+		}
+		delete(m.tasks, current.Name)
+		klog.InfoS("replacing finished task", "task", current.Name)
+		return m.createTaskLocked(ctx, desired)
+	}
+	if err := m.softDeleteLocked(ctx, current, false); err != nil {
+		return err
+	}
+	m.replacements[current.Name] = desired.DeepCopy()
+	klog.InfoS("task marked for replacement", "task", current.Name)
+	return nil
+}
+
+// startReplacementsLocked creates the replacements of updated tasks that have stopped and been removed.
+// Replacements that cannot be created yet are retried on the next reconcile.
+func (m *taskManager) startReplacementsLocked(ctx context.Context) {
+	for name, task := range m.replacements {
+		if _, exists := m.tasks[name]; exists {
+			continue
+		}
+		if err := m.createTaskLocked(ctx, task); err != nil {
+			klog.ErrorS(err, "failed to create replacement task", "name", name)
+			continue
+		}
+		delete(m.replacements, name)
+		klog.InfoS("replacement task created", "name", name)
 	}
-	return m.listTasksLocked(), nil
 }
 
 func (m *taskManager) Get(ctx context.Context, name string) (*types.Task, error) {
@@ -199,7 +353,7 @@ func (m *taskManager) Get(ctx context.Context, name string) (*types.Task, error)
 		return nil, fmt.Errorf("task %s not found", name)
 	}
 
-	return task, nil
+	return task.DeepCopy(), nil
 }
 
 func (m *taskManager) List(ctx context.Context) ([]*types.Task, error) {
@@ -209,8 +363,17 @@ func (m *taskManager) List(ctx context.Context) ([]*types.Task, error) {
 	return m.listTasksLocked(), nil
 }
 
-// Delete removes a task by marking it for deletion
+// Delete marks a task for deletion and keeps it once it has stopped
 func (m *taskManager) Delete(ctx context.Context, name string) error {
+	return m.delete(ctx, name, true)
+}
+
+// Purge marks a task for deletion and removes it once it has stopped
+func (m *taskManager) Purge(ctx context.Context, name string) error {
+	return m.delete(ctx, name, false)
+}
+
+func (m *taskManager) delete(ctx context.Context, name string, retain bool) error {
 	if name == "" {
 		return fmt.Errorf("task name cannot be empty")
 	}
@@ -218,28 +381,181 @@ func (m *taskManager) Delete(ctx context.Context, name string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	delete(m.replacements, name)
 	task, exists := m.tasks[name]
 	if !exists {
 		return nil
 	}
 
-	return m.softDeleteLocked(ctx, task)
+	return m.softDeleteLocked(ctx, task, retain)
 }
 
-// softDeleteLocked marks a task for deletion
-func (m *taskManager) softDeleteLocked(ctx context.Context, task *types.Task) error {
-	if task.DeletionTimestamp != nil {
+func (m *taskManager) Freeze(ctx context.Context) ([]string, error) {
+	return m.freezeTasks(ctx, true)
+}
+
+func (m *taskManager) Thaw(ctx context.Context) ([]string, error) {
+	return m.freezeTasks(ctx, false)
+}
+
+// freezeTasks freezes the running tasks or thaws the unfinished ones. Both are safe to repeat, so
+// the executor keeps no frozen flag: a restarted executor thaws tasks frozen before the restart.
+func (m *taskManager) freezeTasks(ctx context.Context, freeze bool) ([]string, error) {
+	freezer, ok := m.executor.(runtime.Freezer)
+	if !ok {
+		return nil, fmt.Errorf("executor does not support freezing")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var names []string
+	for name, task := range m.tasks {
+		if !canFreeze(task, freeze) {
+			continue
+		}
+		var err error
+		if freeze {
+			err = freezer.Freeze(ctx, task)
+		} else {
+			err = freezer.Thaw(ctx, task)
+		}
+		if err != nil {
+			return names, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	klog.InfoS("tasks signaled", "freeze", freeze, "tasks", names)
+	return names, nil
+}
+
+func (m *taskManager) Pause(ctx context.Context, name string) (*types.Task, error) {
+	return m.freezeTask(ctx, name, true)
+}
+
+func (m *taskManager) Resume(ctx context.Context, name string) (*types.Task, error) {
+	return m.freezeTask(ctx, name, false)
+}
+
+// freezeTask freezes or thaws a single task, e.g. to throttle it under node pressure. Like
+// freezeTasks it is safe to repeat and keeps no paused flag.
+func (m *taskManager) freezeTask(ctx context.Context, name string, freeze bool) (*types.Task, error) {
+	if name == "" {
+		return nil, fmt.Errorf("task name cannot be empty")
+	}
+	freezer, ok := m.executor.(runtime.Freezer)
+	if !ok {
+		return nil, fmt.Errorf("executor does not support freezing")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	task, exists := m.tasks[name]
+	if !exists {
+		return nil, fmt.Errorf("task %s not found", name)
+	}
+	if !canFreeze(task, freeze) {
+		return nil, fmt.Errorf("%w: task %s is %s", ErrTaskNotRunning, name, task.Status.State)
+	}
+	var err error
+	if freeze {
+		err = freezer.Freeze(ctx, task)
+	} else {
+		err = freezer.Thaw(ctx, task)
+	}
+	if err != nil {
+		return nil, err
+	}
+	klog.InfoS("task signaled", "task", name, "freeze", freeze)
+	return task.DeepCopy(), nil
+}
+
+// canFreeze reports whether a task can be frozen, which only running tasks that are not being
+// deleted can, or thawed, which any unfinished task can.
+func canFreeze(task *types.Task, freeze bool) bool {
+	if freeze {
+		return task.Status.State == types.TaskStateRunning && task.DeletionTimestamp == nil
+	}
+	return !isTerminalState(task.Status.State)
+}
+
+func (m *taskManager) Logs(ctx context.Context, name string, opts runtime.LogOptions) (io.ReadCloser, error) {
+	reader, ok := m.executor.(runtime.LogReader)
+	if !ok {
+		return nil, fmt.Errorf("executor does not support reading logs")
+	}
+	task, err := m.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Follow || isTerminalState(task.Status.State) {
+		opts.Follow = false
+		return reader.Logs(ctx, task, opts)
+	}
+
+	followCtx, cancel := context.WithCancel(ctx)
+	logs, err := reader.Logs(followCtx, task, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go m.cancelWhenFinished(followCtx, cancel, name)
+	return &followedLogs{ReadCloser: logs, cancel: cancel}, nil
+}
+
+// cancelWhenFinished cancels a followed log once its task is finished or gone. The executors keep
+// no task state, so the manager ends the follow for every mode alike.
+func (m *taskManager) cancelWhenFinished(ctx context.Context, cancel context.CancelFunc, name string) {
+	ticker := time.NewTicker(m.config.GetReconcileInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.RLock()
+			task, exists := m.tasks[name]
+			finished := !exists || isTerminalState(task.Status.State)
+			m.mu.RUnlock()
+			if finished {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// followedLogs stops watching the task when the log is closed.
+type followedLogs struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (l *followedLogs) Close() error {
+	l.cancel()
+	return l.ReadCloser.Close()
+}
+
+// softDeleteLocked marks a task for deletion. A task already marked is only changed to be purged,
+// never to be retained again.
+func (m *taskManager) softDeleteLocked(ctx context.Context, task *types.Task, retain bool) error {
+	if task.DeletionTimestamp != nil && (retain || !task.Retain) {
 		return nil
 	}
 
-	now := time.Now()
-	task.DeletionTimestamp = &now
+	if task.DeletionTimestamp == nil {
+		now := time.Now()
+		task.DeletionTimestamp = &now
+	}
+	task.Retain = retain
 
 	if err := m.store.Update(ctx, task); err != nil {
 		return fmt.Errorf("failed to mark task for deletion: %w", err)
 	}
 
-	klog.InfoS("task marked for deletion", "name", task.Name)
+	klog.InfoS("task marked for deletion", "task", task.Name, "retain", retain)
 	return nil
 }
 
@@ -273,39 +589,39 @@ func (m *taskManager) createTaskLocked(ctx context.Context, task *types.Task) er
 		return fmt.Errorf("task %s already exists", task.Name)
 	}
 
-	if m.countActiveTasks() >= maxConcurrentTasks {
-		return fmt.Errorf("maximum concurrent tasks (%d) reached, cannot create new task", maxConcurrentTasks)
+	if limit := m.config.GetMaxConcurrentTasks(); m.countActiveTasks() >= limit {
+		return fmt.Errorf("maximum concurrent tasks (%d) reached, cannot create new task", limit)
 	}
+	task = task.DeepCopy()
 
+	startTimings(task)
 	if err := m.store.Create(ctx, task); err != nil {
 		return fmt.Errorf("failed to persist task: %w", err)
 	}
+	persisted(task)
 
 	if err := m.executor.Start(ctx, task); err != nil {
 		m.store.Delete(ctx, task.Name)
 		return fmt.Errorf("failed to start task: %w", err)
 	}
 
+	// Persist the PID and initial status
 	if status, err := m.executor.Inspect(ctx, task); err == nil {
-		task.Status = *status
-		// Persist the PID and initial status
-		if err := m.store.Update(ctx, task); err != nil {
-			klog.ErrorS(err, "failed to persist initial task status", "name", task.Name)
-		}
+		m.applyStatus(ctx, task, *status)
 	} else {
-		klog.ErrorS(err, "failed to inspect task after start", "name", task.Name)
+		klog.ErrorS(err, "failed to inspect task after start", "task", task.Name)
 	}
 
 	m.tasks[task.Name] = task
 	return nil
 }
 
-// listTasksLocked returns all tasks without acquiring the lock
+// listTasksLocked returns copies of all tasks without acquiring the lock
 func (m *taskManager) listTasksLocked() []*types.Task {
 	tasks := make([]*types.Task, 0, len(m.tasks))
 	for _, task := range m.tasks {
 		if task != nil {
-			tasks = append(tasks, task)
+			tasks = append(tasks, task.DeepCopy())
 		}
 	}
 	return tasks
@@ -330,24 +646,26 @@ func (m *taskManager) recoverTasks(ctx context.Context) error {
 		persistedState := task.Status.State
 		status, err := m.executor.Inspect(ctx, task)
 		if err != nil {
-			klog.ErrorS(err, "failed to inspect task during recovery", "name", task.Name)
+			klog.ErrorS(err, "failed to inspect task during recovery", "task", task.Name)
 			continue
 		}
 
 		if shouldDropRecoveredTask(task, persistedState, status.State) {
 			klog.InfoS("dropping recovered task with lost active runtime state",
-				"name", task.Name, "persistedState", persistedState, "recoveredState", status.State)
+				"task", task.Name, "persistedState", persistedState, "recoveredState", status.State)
 			if err := m.store.Delete(ctx, task.Name); err != nil {
-				klog.ErrorS(err, "failed to delete stale recovered task from store", "name", task.Name)
+				klog.ErrorS(err, "failed to delete stale recovered task from store", "task", task.Name)
 			}
 			continue
 		}
 
-		task.Status = *status
+		// A glitch in the runtime state must not revive a finished task, so the persisted status wins
+		// over one it cannot transition to.
+		m.applyStatus(ctx, task, *status)
 
 		m.tasks[task.Name] = task
 
-		klog.InfoS("recovered task", "name", task.Name, "state", task.Status.State, "deleting", task.DeletionTimestamp != nil)
+		klog.InfoS("recovered task", "task", task.Name, "state", task.Status.State, "deleting", task.DeletionTimestamp != nil)
 	}
 
 	klog.InfoS("task recovery completed", "count", len(m.tasks))
@@ -370,7 +688,8 @@ func shouldDropRecoveredTask(task *types.Task, persistedState, recoveredState ty
 }
 
 func (m *taskManager) reconcileLoop(ctx context.Context) {
-	ticker := time.NewTicker(m.config.ReconcileInterval)
+	interval := m.config.GetReconcileInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	defer close(m.doneCh)
 
@@ -378,6 +697,11 @@ func (m *taskManager) reconcileLoop(ctx context.Context) {
 		select {
 		case <-ticker.C:
 			m.reconcileTasks(ctx)
+			// Pick up a reloaded interval.
+			if latest := m.config.GetReconcileInterval(); latest != interval {
+				interval = latest
+				ticker.Reset(interval)
+			}
 		case <-m.stopCh:
 			klog.InfoS("reconcile loop stopped")
 			return
@@ -434,26 +758,22 @@ func (m *taskManager) reconcileTasks(ctx context.Context) {
 					klog.ErrorS(err, "failed to stop task", "name", taskName)
 				}
 				klog.InfoS("task stopped", "name", taskName)
-			}(task, name)
+			}(task.DeepCopy(), name)
 		}
 
 		if task.DeletionTimestamp != nil && isTerminalState(state) {
-			klog.InfoS("task terminated, finalizing deletion", "name", name)
-			tasksToDelete = append(tasksToDelete, name)
+			switch {
+			case !task.Retain:
+				klog.InfoS("task terminated, finalizing deletion", "name", name)
+				tasksToDelete = append(tasksToDelete, name)
+			case m.retentionExpired(task):
+				klog.InfoS("task retention expired, finalizing deletion", "name", name)
+				tasksToDelete = append(tasksToDelete, name)
+			}
 		}
 
 		if !m.stopping[name] {
-			if !reflect.DeepEqual(task.Status, *status) {
-				oldState := task.Status.State
-				task.Status = *status
-				// Log state changes only
-				if oldState != status.State {
-					klog.InfoS("task state changed", "name", name, "oldState", oldState, "newState", status.State)
-				}
-				if err := m.store.Update(ctx, task); err != nil {
-					klog.ErrorS(err, "failed to update task status in store", "name", name)
-				}
-			}
+			m.applyStatus(ctx, task, *status)
 		}
 	}
 
@@ -471,6 +791,14 @@ func (m *taskManager) reconcileTasks(ctx context.Context) {
 		delete(m.stopping, name)
 		klog.InfoS("task deleted successfully", "name", name)
 	}
+
+	m.startReplacementsLocked(ctx)
+}
+
+// retentionExpired reports whether a retained task has been kept for RetainedTaskTTL since it was deleted.
+func (m *taskManager) retentionExpired(task *types.Task) bool {
+	ttl := m.config.GetRetainedTaskTTL()
+	return ttl > 0 && time.Since(*task.DeletionTimestamp) >= ttl
 }
 
 // isTerminalState returns true if the task will not transition to another state
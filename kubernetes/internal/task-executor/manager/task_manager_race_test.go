@@ -0,0 +1,181 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	store "github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/storage"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+// These tests are meant to be run with -race: they exercise the reconcile loop
+// against API reads and fail under the race detector if the manager hands out
+// memory that it keeps mutating.
+
+// churningExecutor reports a different running status on every Inspect, so
+// every reconcile rewrites the status of every task.
+type churningExecutor struct {
+	mu       sync.Mutex
+	inspects int
+}
+
+func (e *churningExecutor) Start(_ context.Context, _ *types.Task) error {
+	return nil
+}
+
+func (e *churningExecutor) Inspect(_ context.Context, _ *types.Task) (*types.Status, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.inspects++
+	now := time.Now()
+	return &types.Status{
+		State: types.TaskStateRunning,
+		SubStatuses: []types.SubStatus{{
+			Reason:    "Running",
+			Message:   fmt.Sprintf("inspect %d", e.inspects),
+			StartedAt: &now,
+		}},
+	}, nil
+}
+
+func (e *churningExecutor) Stop(_ context.Context, _ *types.Task) error {
+	return nil
+}
+
+func newChurningManager(t *testing.T) *taskManager {
+	cfg := &config.Config{
+		DataDir:           t.TempDir(),
+		ReconcileInterval: time.Millisecond,
+	}
+	taskStore, err := store.NewFileStore(cfg.DataDir)
+	require.NoError(t, err)
+	mgr, err := NewTaskManager(cfg, taskStore, &churningExecutor{})
+	require.NoError(t, err)
+	return mgr.(*taskManager)
+}
+
+func TestTaskManager_ConcurrentReconcileAndReads(t *testing.T) {
+	mgr := newChurningManager(t)
+	ctx := context.Background()
+	mgr.Start(ctx)
+	defer mgr.Stop()
+
+	desired := []*types.Task{{
+		Name:    "race-task",
+		Process: &api.Process{Command: []string{"sleep", "30"}},
+	}}
+	_, _, err := mgr.Sync(ctx, desired)
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(300 * time.Millisecond)
+	var wg sync.WaitGroup
+	readers := []func() error{
+		func() error {
+			task, err := mgr.Get(ctx, "race-task")
+			if err != nil {
+				return err
+			}
+			_, err = json.Marshal(task)
+			return err
+		},
+		func() error {
+			tasks, err := mgr.List(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = json.Marshal(tasks)
+			return err
+		},
+		func() error {
+			tasks, _, err := mgr.Sync(ctx, desired)
+			if err != nil {
+				return err
+			}
+			_, err = json.Marshal(tasks)
+			return err
+		},
+		func() error {
+			mgr.reconcileTasks(ctx)
+			return nil
+		},
+	}
+	errs := make(chan error, len(readers))
+	for _, read := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				if err := read(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestTaskManager_ReadsReturnCopies(t *testing.T) {
+	mgr := newChurningManager(t)
+	ctx := context.Background()
+
+	timeout := int64(10)
+	input := &types.Task{
+		Name:    "copy-task",
+		Process: &api.Process{Command: []string{"sleep", "30"}, TimeoutSeconds: &timeout},
+	}
+	created, err := mgr.Create(ctx, input)
+	require.NoError(t, err)
+
+	// Neither the input nor the returned task is the one the manager keeps.
+	input.Process.Command[0] = "changed"
+	created.Status.SubStatuses[0].Message = "changed"
+	*created.Process.TimeoutSeconds = 1
+
+	got, err := mgr.Get(ctx, "copy-task")
+	require.NoError(t, err)
+	assert.Equal(t, "sleep", got.Process.Command[0])
+	assert.NotEqual(t, "changed", got.Status.SubStatuses[0].Message)
+	assert.Equal(t, int64(10), *got.Process.TimeoutSeconds)
+
+	listed, err := mgr.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	listed[0].Status.State = types.TaskStateFailed
+
+	got, err = mgr.Get(ctx, "copy-task")
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateRunning, got.Status.State)
+
+	// A snapshot does not change when the manager reconciles afterwards.
+	before := got.Status.SubStatuses[0].Message
+	mgr.reconcileTasks(ctx)
+	assert.Equal(t, before, got.Status.SubStatuses[0].Message)
+}
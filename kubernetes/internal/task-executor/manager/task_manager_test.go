@@ -16,12 +16,14 @@ package manager
 
 import (
 	"context"
+	"io"
 	"os/exec"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
@@ -94,7 +96,7 @@ func setupTestManager(t *testing.T) (TaskManager, *config.Config) {
 
 func cleanupTask(t *testing.T, mgr TaskManager, name string) {
 	ctx := context.Background()
-	mgr.Delete(ctx, name)
+	mgr.Purge(ctx, name)
 	deadline := time.Now().Add(5 * time.Second)
 	for time.Now().Before(deadline) {
 		_, err := mgr.Get(ctx, name)
@@ -220,13 +222,65 @@ func TestTaskManager_Create(t *testing.T) {
 				time.Sleep(200 * time.Millisecond)
 				// Then clean up
 				if tt.task != nil {
-					mgr.Delete(ctx, tt.task.Name)
+					mgr.Purge(ctx, tt.task.Name)
 				}
 			}
 		})
 	}
 }
 
+func TestTaskManager_CreateRecordsTimings(t *testing.T) {
+	mgr, _ := setupTestManager(t)
+	ctx := context.Background()
+	mgr.Start(ctx)
+	defer mgr.Stop()
+
+	receivedAt := time.Now()
+	task := &types.Task{
+		Name:    "timed-task",
+		Process: &api.Process{Command: []string{"sh", "-c", "echo hello; sleep 10"}},
+		Timings: &types.Timings{ReceivedAt: &receivedAt},
+	}
+	created, err := mgr.Create(ctx, task)
+	require.NoError(t, err)
+	defer cleanupTask(t, mgr, task.Name)
+	require.NotNil(t, created.Timings)
+	assert.True(t, receivedAt.Equal(*created.Timings.ReceivedAt), "the time the API received the task is kept")
+	require.NotNil(t, created.Timings.PersistedAt)
+	assert.False(t, created.Timings.PersistedAt.Before(receivedAt))
+
+	require.Eventually(t, func() bool {
+		got, err := mgr.Get(ctx, task.Name)
+		return err == nil && got.Timings.FirstOutputAt != nil
+	}, 5*time.Second, 50*time.Millisecond)
+	got, err := mgr.Get(ctx, task.Name)
+	require.NoError(t, err)
+	require.NotNil(t, got.Timings.SpawnedAt)
+	assert.False(t, got.Timings.FirstOutputAt.Before(got.Timings.SpawnedAt.Truncate(time.Second)))
+}
+
+func TestRecordStartup(t *testing.T) {
+	persistedAt := time.Now()
+	spawnedAt := persistedAt.Add(time.Second)
+	task := &types.Task{
+		Timings: &types.Timings{PersistedAt: &persistedAt},
+		Status:  types.Status{SubStatuses: []types.SubStatus{{StartedAt: &spawnedAt}}},
+	}
+	recordStartup(context.Background(), task, "")
+	require.NotNil(t, task.Timings.SpawnedAt)
+	assert.True(t, spawnedAt.Equal(*task.Timings.SpawnedAt))
+	assert.Nil(t, task.Timings.FirstOutputAt)
+
+	firstOutputAt := spawnedAt.Add(time.Second)
+	later := spawnedAt.Add(time.Minute)
+	task.Status.SubStatuses[0].StartedAt = &later
+	task.Status.SubStatuses[0].FirstOutputAt = &firstOutputAt
+	recordStartup(context.Background(), task, types.TaskStateRunning)
+	assert.True(t, spawnedAt.Equal(*task.Timings.SpawnedAt), "timings are set once")
+	require.NotNil(t, task.Timings.FirstOutputAt)
+	assert.True(t, firstOutputAt.Equal(*task.Timings.FirstOutputAt))
+}
+
 func TestTaskManager_CreateDuplicate(t *testing.T) {
 	mgr, _ := setupTestManager(t)
 	mgr.Start(context.Background())
@@ -368,7 +422,7 @@ func TestTaskManager_List(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
-	defer mgr.Delete(ctx, task.Name)
+	defer mgr.Purge(ctx, task.Name)
 
 	// List should return 1 task
 	tasks, err = mgr.List(ctx)
@@ -419,6 +473,28 @@ func TestTaskManager_Delete(t *testing.T) {
 		t.Error("DeletionTimestamp should be set after Delete()")
 	}
 
+	if !got.Retain {
+		t.Error("Retain should be set after Delete()")
+	}
+
+	// The stopped task is retained
+	time.Sleep(500 * time.Millisecond)
+	got, err = mgr.Get(ctx, task.Name)
+	if err != nil {
+		t.Fatalf("Get() should succeed for a retained task: %v", err)
+	}
+	if !isTerminalState(got.Status.State) {
+		t.Errorf("retained task state = %s, want a terminal state", got.Status.State)
+	}
+
+	// A repeated Delete() does not undo a purge
+	if err := mgr.Purge(ctx, task.Name); err != nil {
+		t.Errorf("Purge() failed: %v", err)
+	}
+	if err := mgr.Delete(ctx, task.Name); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+
 	// Wait for task to be finalized
 	timeout := 5 * time.Second
 	deadline := time.Now().Add(timeout)
@@ -433,6 +509,49 @@ func TestTaskManager_Delete(t *testing.T) {
 	t.Error("Task was not finalized (deleted) within timeout")
 }
 
+func TestTaskManager_RetentionExpires(t *testing.T) {
+	mgr, cfg := setupTestManager(t)
+	cfg.RetainedTaskTTL = 300 * time.Millisecond
+	mgr.Start(context.Background())
+	defer mgr.Stop()
+
+	ctx := context.Background()
+
+	task := &types.Task{
+		Name: "retained-task",
+		Process: &api.Process{
+			Command: []string{"echo", "retained"},
+		},
+	}
+	if _, err := mgr.Create(ctx, task); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := mgr.Delete(ctx, task.Name); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	// A retained task no longer counts towards the concurrency limit
+	next := &types.Task{
+		Name: "next-task",
+		Process: &api.Process{
+			Command: []string{"echo", "next"},
+		},
+	}
+	if _, err := mgr.Create(ctx, next); err != nil {
+		t.Fatalf("Create() after Delete() failed: %v", err)
+	}
+	defer cleanupTask(t, mgr, next.Name)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := mgr.Get(ctx, task.Name); err != nil {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Error("Retained task was not collected after its retention expired")
+}
+
 func TestTaskManager_DeleteNonExistent(t *testing.T) {
 	mgr, _ := setupTestManager(t)
 	ctx := context.Background()
@@ -472,7 +591,7 @@ func TestTaskManager_SyncRestartsRecoveredActiveTaskWhenRuntimeStateIsLost(t *te
 	mgr := mgrIface.(*taskManager)
 	require.NoError(t, mgr.recoverTasks(ctx))
 
-	tasks, err := mgr.Sync(ctx, []*types.Task{{
+	tasks, _, err := mgr.Sync(ctx, []*types.Task{{
 		Name: "resume-task",
 		Process: &api.Process{
 			Command: []string{"sleep", "3600"},
@@ -518,7 +637,7 @@ func TestTaskManager_SyncKeepsRecoveredSucceededTask(t *testing.T) {
 	mgr := mgrIface.(*taskManager)
 	require.NoError(t, mgr.recoverTasks(ctx))
 
-	tasks, err := mgr.Sync(ctx, []*types.Task{{
+	tasks, _, err := mgr.Sync(ctx, []*types.Task{{
 		Name: "completed-task",
 		Process: &api.Process{
 			Command: []string{"echo", "done"},
@@ -560,11 +679,14 @@ func TestTaskManager_Sync(t *testing.T) {
 	}
 
 	// Sync triggers soft delete for task1 and creation of task2
-	current, err := mgr.Sync(ctx, []*types.Task{task2})
+	current, actions, err := mgr.Sync(ctx, []*types.Task{task2})
 	if err != nil {
 		t.Fatalf("Sync() failed: %v", err)
 	}
-	defer mgr.Delete(ctx, task2.Name)
+	if actions[task1.Name] != api.SyncActionDeleted || actions[task2.Name] != api.SyncActionCreated {
+		t.Errorf("Sync() actions = %v, want %s deleted and %s created", actions, task1.Name, task2.Name)
+	}
+	defer mgr.Purge(ctx, task2.Name)
 
 	// Verify task1 is marked for deletion in the returned list
 	var task1Found bool
@@ -606,11 +728,78 @@ func TestTaskManager_Sync(t *testing.T) {
 	t.Error("task1 should be deleted after Sync()")
 }
 
+func TestTaskManager_SyncUpdatesChangedTasks(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		DataDir:            t.TempDir(),
+		EnableSidecarMode:  false,
+		ReconcileInterval:  time.Hour,
+		MaxConcurrentTasks: 3,
+	}
+	taskStore, err := store.NewFileStore(cfg.DataDir)
+	require.NoError(t, err)
+	exec := newFakeExecutor()
+	mgrIface, err := NewTaskManager(cfg, taskStore, exec)
+	require.NoError(t, err)
+	mgr := mgrIface.(*taskManager)
+
+	task := func(name string, env ...string) *types.Task {
+		process := &api.Process{Command: []string{"sleep", "3600"}}
+		for _, value := range env {
+			process.Env = append(process.Env, corev1.EnvVar{Name: "MODE", Value: value})
+		}
+		return &types.Task{Name: name, Process: process}
+	}
+	_, actions, err := mgr.Sync(ctx, []*types.Task{task("running"), task("finished"), task("same")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]api.SyncAction{
+		"running":  api.SyncActionCreated,
+		"finished": api.SyncActionCreated,
+		"same":     api.SyncActionCreated,
+	}, actions)
+	require.Equal(t, 3, exec.starts)
+	exec.inspect["finished"].State = types.TaskStateSucceeded
+	mgr.reconcileTasks(ctx)
+
+	tasks, actions, err := mgr.Sync(ctx, []*types.Task{task("running", "v2"), task("finished", "v2"), task("same")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]api.SyncAction{
+		"running":  api.SyncActionUpdated,
+		"finished": api.SyncActionUpdated,
+		"same":     api.SyncActionKept,
+	}, actions)
+	assert.Equal(t, 4, exec.starts, "a finished task is restarted at once")
+	byName := make(map[string]*types.Task)
+	for _, task := range tasks {
+		byName[task.Name] = task
+	}
+	assert.Equal(t, "v2", byName["finished"].Process.Env[0].Value)
+	assert.Equal(t, types.TaskStateRunning, byName["finished"].Status.State)
+	assert.NotNil(t, byName["running"].DeletionTimestamp, "a running task is stopped before it is restarted")
+	assert.Empty(t, byName["running"].Process.Env)
+	assert.Nil(t, byName["same"].DeletionTimestamp)
+
+	// Resending the update while the task stops does not restart it twice.
+	_, actions, err = mgr.Sync(ctx, []*types.Task{task("running", "v3"), task("finished", "v2"), task("same")})
+	require.NoError(t, err)
+	assert.Equal(t, api.SyncActionUpdated, actions["running"])
+	assert.Equal(t, api.SyncActionKept, actions["finished"])
+
+	exec.inspect["running"].State = types.TaskStateSucceeded
+	mgr.reconcileTasks(ctx)
+	restarted, err := mgr.Get(ctx, "running")
+	require.NoError(t, err)
+	assert.Equal(t, 5, exec.starts)
+	assert.Nil(t, restarted.DeletionTimestamp)
+	assert.Equal(t, "v3", restarted.Process.Env[0].Value, "the latest spec wins")
+	assert.Equal(t, types.TaskStateRunning, restarted.Status.State)
+}
+
 func TestTaskManager_SyncNil(t *testing.T) {
 	mgr, _ := setupTestManager(t)
 	ctx := context.Background()
 
-	_, err := mgr.Sync(ctx, nil)
+	_, _, err := mgr.Sync(ctx, nil)
 	if err == nil {
 		t.Error("Sync() should fail for nil desired list")
 	}
@@ -666,17 +855,19 @@ func TestTaskManager_AsyncStopOnDelete(t *testing.T) {
 		t.Errorf("Delete() took too long (%v), should be fast (async stop)", deleteDuration)
 	}
 
-	// Wait for task to be finalized
+	// Wait for task to be stopped; it is retained afterwards
 	deadline := time.Now().Add(15 * time.Second)
 	for time.Now().Before(deadline) {
-		_, err := mgr.Get(ctx, task.Name)
+		got, err := mgr.Get(ctx, task.Name)
 		if err != nil {
-			// Task is gone, success
+			t.Fatalf("Get() of a retained task failed: %v", err)
+		}
+		if isTerminalState(got.Status.State) {
 			return
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
-	t.Error("Task was not finalized within timeout after async stop")
+	t.Error("Task was not stopped within timeout after async stop")
 }
 
 func TestTaskManager_TimeoutHandling(t *testing.T) {
@@ -742,6 +933,14 @@ func TestTaskManager_TimeoutHandling(t *testing.T) {
 	}
 }
 
+// lockedCountActiveTasks counts active tasks under the manager lock, since the reconcile loop is running.
+func lockedCountActiveTasks(mgr TaskManager) int {
+	m := mgr.(*taskManager)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.countActiveTasks()
+}
+
 func TestTaskManager_CountActiveTasks(t *testing.T) {
 	mgr, _ := setupTestManager(t)
 	mgr.Start(context.Background())
@@ -749,7 +948,7 @@ func TestTaskManager_CountActiveTasks(t *testing.T) {
 	ctx := context.Background()
 
 	// Initially empty
-	activeCount := mgr.(*taskManager).countActiveTasks()
+	activeCount := lockedCountActiveTasks(mgr)
 	if activeCount != 0 {
 		t.Errorf("Initial active count = %d, want 0", activeCount)
 	}
@@ -765,13 +964,13 @@ func TestTaskManager_CountActiveTasks(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
-	defer mgr.Delete(ctx, task1.Name)
+	defer mgr.Purge(ctx, task1.Name)
 
 	// Wait for task1 to complete
 	time.Sleep(500 * time.Millisecond)
 
 	// Should have 0 active tasks after task1 completes
-	activeCount = mgr.(*taskManager).countActiveTasks()
+	activeCount = lockedCountActiveTasks(mgr)
 	if activeCount != 0 {
 		t.Errorf("Active count after task1 completion = %d, want 0", activeCount)
 	}
@@ -787,10 +986,10 @@ func TestTaskManager_CountActiveTasks(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
-	defer mgr.Delete(ctx, task2.Name)
+	defer mgr.Purge(ctx, task2.Name)
 
 	// Should have 1 active task
-	activeCount = mgr.(*taskManager).countActiveTasks()
+	activeCount = lockedCountActiveTasks(mgr)
 	if activeCount != 1 {
 		t.Errorf("Active count after create = %d, want 1", activeCount)
 	}
@@ -820,3 +1019,114 @@ func TestIsTerminalState(t *testing.T) {
 		})
 	}
 }
+
+type freezingExecutor struct {
+	*fakeExecutor
+	frozen map[string]bool
+}
+
+func (f *freezingExecutor) Freeze(_ context.Context, task *types.Task) error {
+	f.frozen[task.Name] = true
+	return nil
+}
+
+func (f *freezingExecutor) Thaw(_ context.Context, task *types.Task) error {
+	delete(f.frozen, task.Name)
+	return nil
+}
+
+func TestTaskManager_FreezeThaw(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{DataDir: t.TempDir(), ReconcileInterval: time.Hour}
+	taskStore, err := store.NewFileStore(cfg.DataDir)
+	require.NoError(t, err)
+
+	mgr, err := NewTaskManager(cfg, taskStore, newFakeExecutor())
+	require.NoError(t, err)
+	_, err = mgr.Freeze(ctx)
+	assert.Error(t, err, "executors without freezing support are rejected")
+
+	exec := &freezingExecutor{fakeExecutor: newFakeExecutor(), frozen: map[string]bool{}}
+	mgr, err = NewTaskManager(cfg, taskStore, exec)
+	require.NoError(t, err)
+	_, err = mgr.Create(ctx, &types.Task{Name: "running", Process: &api.Process{Command: []string{"sleep", "3600"}}})
+	require.NoError(t, err)
+
+	frozen, err := mgr.Freeze(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running"}, frozen)
+	assert.True(t, exec.frozen["running"])
+
+	thawed, err := mgr.Thaw(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running"}, thawed)
+	assert.Empty(t, exec.frozen)
+}
+
+func TestTaskManager_PauseResume(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{DataDir: t.TempDir(), ReconcileInterval: time.Hour}
+	taskStore, err := store.NewFileStore(cfg.DataDir)
+	require.NoError(t, err)
+
+	exec := &freezingExecutor{fakeExecutor: newFakeExecutor(), frozen: map[string]bool{}}
+	mgr, err := NewTaskManager(cfg, taskStore, exec)
+	require.NoError(t, err)
+	_, err = mgr.Create(ctx, &types.Task{Name: "running", Process: &api.Process{Command: []string{"sleep", "3600"}}})
+	require.NoError(t, err)
+
+	task, err := mgr.Pause(ctx, "running")
+	require.NoError(t, err)
+	assert.Equal(t, "running", task.Name)
+	assert.True(t, exec.frozen["running"])
+	_, err = mgr.Pause(ctx, "running")
+	assert.NoError(t, err, "pausing a paused task is a no-op")
+
+	_, err = mgr.Resume(ctx, "running")
+	require.NoError(t, err)
+	assert.Empty(t, exec.frozen)
+
+	_, err = mgr.Pause(ctx, "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	exec.inspect["running"].State = types.TaskStateSucceeded
+	mgr.(*taskManager).reconcileTasks(ctx)
+	_, err = mgr.Pause(ctx, "running")
+	assert.ErrorIs(t, err, ErrTaskNotRunning)
+	_, err = mgr.Resume(ctx, "running")
+	assert.ErrorIs(t, err, ErrTaskNotRunning)
+}
+
+func TestTaskManager_Logs(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found, skipping logs test")
+	}
+
+	mgr, _ := setupTestManager(t)
+	mgr.Start(context.Background())
+	defer mgr.Stop()
+	ctx := context.Background()
+
+	_, err := mgr.Logs(ctx, "missing", runtime.LogOptions{})
+	assert.Error(t, err)
+
+	task := &types.Task{
+		Name:    "logs-task",
+		Process: &api.Process{Command: []string{"sh", "-c", "echo first; sleep 1; echo second"}},
+	}
+	_, err = mgr.Create(ctx, task)
+	require.NoError(t, err)
+	defer cleanupTask(t, mgr, task.Name)
+
+	// A followed log ends on its own once the task exits.
+	logs, err := mgr.Logs(ctx, task.Name, runtime.LogOptions{Follow: true})
+	require.NoError(t, err)
+	defer logs.Close()
+	out, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(out))
+
+	got, err := mgr.Get(ctx, task.Name)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateSucceeded, got.Status.State)
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package readiness reports whether the task-executor is serving through the
+// executor-ready condition of its own pod.
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+const (
+	ReasonServing  = "Serving"
+	ReasonStopping = "Stopping"
+)
+
+// Reporter patches the executor-ready condition of a single pod. It only
+// needs patch on pods/status in the pod's namespace.
+type Reporter struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+func NewReporter(client kubernetes.Interface, namespace, name string) *Reporter {
+	return &Reporter{client: client, namespace: namespace, name: name}
+}
+
+// NewInClusterReporter builds a Reporter with the pod service account.
+func NewInClusterReporter(namespace, name string) (*Reporter, error) {
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("pod namespace and name are required")
+	}
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return NewReporter(client, namespace, name), nil
+}
+
+// Set patches the condition. Conditions merge by type, so other conditions
+// on the pod are left alone.
+func (r *Reporter) Set(ctx context.Context, ready bool, reason string) error {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"conditions": []corev1.PodCondition{{
+				Type:               api.PodConditionExecutorReady,
+				Status:             status,
+				Reason:             reason,
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.client.CoreV1().Pods(r.namespace).Patch(ctx, r.name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to patch pod condition %s: %w", api.PodConditionExecutorReady, err)
+	}
+	return nil
+}
+
+// SetWithRetry retries Set with backoff until it succeeds or ctx is done.
+func (r *Reporter) SetWithRetry(ctx context.Context, ready bool, reason string) error {
+	backoff := wait.Backoff{Duration: 500 * time.Millisecond, Factor: 2, Jitter: 0.1, Steps: 8, Cap: 30 * time.Second}
+	return wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		if err := r.Set(ctx, ready, reason); err != nil {
+			klog.ErrorS(err, "failed to report executor readiness, retrying", "ready", ready)
+			return false, nil
+		}
+		return true, nil
+	})
+}
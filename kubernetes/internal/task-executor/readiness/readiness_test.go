@@ -0,0 +1,65 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readiness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+func TestReporter_Set(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	client := fake.NewClientset(pod)
+	r := NewReporter(client, "default", "pod")
+	ctx := context.Background()
+
+	condition := func() (*corev1.PodCondition, int) {
+		got, err := client.CoreV1().Pods("default").Get(ctx, "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		for i := range got.Status.Conditions {
+			if got.Status.Conditions[i].Type == api.PodConditionExecutorReady {
+				return &got.Status.Conditions[i], len(got.Status.Conditions)
+			}
+		}
+		return nil, len(got.Status.Conditions)
+	}
+
+	assert.NoError(t, r.Set(ctx, true, ReasonServing))
+	cond, n := condition()
+	assert.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, ReasonServing, cond.Reason)
+	assert.Equal(t, 2, n)
+
+	assert.NoError(t, r.Set(ctx, false, ReasonStopping))
+	cond, n = condition()
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, ReasonStopping, cond.Reason)
+	assert.Equal(t, 2, n)
+
+	assert.Error(t, NewReporter(client, "default", "missing").Set(ctx, true, ReasonServing))
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recording keeps the task-executor part of the sandbox session record and seals the record
+// when the sandbox is released.
+//
+// The record directory is shared by all containers of the sandbox. execd and egress write their own
+// files into it (see components/internal/recording); the format is the same: one JSON entry per line,
+// each carrying an HMAC-SHA256 that covers the HMAC of the entry before it, so a removed or edited line
+// breaks the chain. The HMAC key is mounted into the sidecars only; the workload can write to the
+// directory but cannot recompute the chain after an edit.
+package recording
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Source is the name the task-executor records under.
+const Source = "task-executor"
+
+// TypeTask is the entry type of task state changes.
+const TypeTask = "task"
+
+// MinKeySize is the minimum length of the recording key.
+const MinKeySize = 32
+
+// Entry is one line of a record file.
+type Entry struct {
+	Seq    uint64         `json:"seq"`
+	Time   time.Time      `json:"time"`
+	Source string         `json:"source"`
+	Type   string         `json:"type"`
+	Fields map[string]any `json:"fields,omitempty"`
+	// Prev is the hash of the previous entry, empty for the first one.
+	Prev string `json:"prev"`
+	// Hash is the hex HMAC-SHA256 of the entry encoded without Hash, keyed with the recording key.
+	Hash string `json:"hash,omitempty"`
+}
+
+func (e Entry) sum(key []byte) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// LoadKey reads the recording key from path. Surrounding whitespace is ignored.
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording key: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("recording key in %s is shorter than %d bytes", path, MinKeySize)
+	}
+	return key, nil
+}
+
+// Recorder appends entries to <dir>/task-executor.jsonl. A nil Recorder records nothing.
+//
+// The file is opened for every entry because Seal moves it away; the next entry starts a new file
+// that continues the chain.
+type Recorder struct {
+	mu   sync.Mutex
+	path string
+	key  []byte
+	seq  uint64
+	prev string
+}
+
+// NewRecorder returns a Recorder writing into dir, keyed with key, continuing the chain of an
+// existing file.
+func NewRecorder(dir string, key []byte) (*Recorder, error) {
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("recording key is shorter than %d bytes", MinKeySize)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create recording dir: %w", err)
+	}
+	r := &Recorder{path: filepath.Join(dir, Source+recordExt), key: key}
+	data, err := os.ReadFile(r.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read record: %w", err)
+	}
+	if lines := bytes.Split(bytes.TrimSpace(data), []byte("\n")); len(lines[len(lines)-1]) > 0 {
+		var last Entry
+		if err := json.Unmarshal(lines[len(lines)-1], &last); err != nil {
+			return nil, fmt.Errorf("failed to read last record entry: %w", err)
+		}
+		r.seq, r.prev = last.Seq, last.Hash
+	}
+	return r, nil
+}
+
+// Record appends an entry of the given type.
+func (r *Recorder) Record(entryType string, fields map[string]any) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := Entry{
+		Seq:    r.seq + 1,
+		Time:   time.Now().UTC(),
+		Source: Source,
+		Type:   entryType,
+		Fields: fields,
+		Prev:   r.prev,
+	}
+	hash, err := entry.sum(r.key)
+	if err != nil {
+		return fmt.Errorf("failed to encode record entry: %w", err)
+	}
+	entry.Hash = hash
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode record entry: %w", err)
+	}
+
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open record: %w", err)
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to close record: %w", err)
+	}
+	r.seq, r.prev = entry.Seq, entry.Hash
+	return nil
+}
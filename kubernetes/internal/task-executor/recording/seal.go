@@ -0,0 +1,278 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	recordExt  = ".jsonl"
+	archiveExt = ".tar.gz"
+	// sealedDir holds records moved out of the way and archives not uploaded yet.
+	sealedDir = ".sealed"
+	// ManifestName is the first file of every archive.
+	ManifestName = "manifest.json"
+)
+
+// Manifest describes a sealed archive.
+type Manifest struct {
+	SandboxID    string         `json:"sandboxId"`
+	PodNamespace string         `json:"podNamespace,omitempty"`
+	PodName      string         `json:"podName,omitempty"`
+	SealedAt     time.Time      `json:"sealedAt"`
+	Files        []ArchivedFile `json:"files"`
+}
+
+// ArchivedFile is a record file in an archive.
+type ArchivedFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Sealer packages the record directory into an archive and uploads it.
+type Sealer struct {
+	Dir       string
+	UploadURL string
+	// UploadToken is sent as a bearer token with every upload.
+	UploadToken  string
+	PodNamespace string
+	PodName      string
+	HTTPClient   *http.Client
+
+	mu sync.Mutex
+}
+
+// NewSealer returns a Sealer uploading to uploadURL, which must be https, with the bearer token.
+func NewSealer(dir, uploadURL, uploadToken, podNamespace, podName string) (*Sealer, error) {
+	u, err := url.Parse(uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upload url: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("upload url %q must be an https URL", uploadURL)
+	}
+	if uploadToken == "" {
+		return nil, fmt.Errorf("an upload token is required")
+	}
+	return &Sealer{
+		Dir:          dir,
+		UploadURL:    uploadURL,
+		UploadToken:  uploadToken,
+		PodNamespace: podNamespace,
+		PodName:      podName,
+		// Seals are requested by the controller through the API, so an upload has to finish well
+		// within the server write timeout; a slow upload is retried on the next seal.
+		HTTPClient: &http.Client{Timeout: 20 * time.Second},
+	}, nil
+}
+
+// Seal archives everything recorded so far for the sandbox id and uploads it, together with archives
+// left over by earlier seals whose upload failed. Records are moved out of the directory before they
+// are archived, so components keep recording into new files while the seal runs. Seal is safe to
+// retry; it returns the names of the uploaded archives.
+func (s *Sealer) Seal(ctx context.Context, id string) ([]string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid sandbox id %q", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed := filepath.Join(s.Dir, sealedDir)
+	if err := os.MkdirAll(sealed, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", sealed, err)
+	}
+	now := time.Now().UTC()
+	if err := s.moveRecords(sealed, now); err != nil {
+		return nil, err
+	}
+	if err := s.archive(sealed, id, now); err != nil {
+		return nil, err
+	}
+
+	archives, err := filepath.Glob(filepath.Join(sealed, "*"+archiveExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(archives)
+	uploaded := make([]string, 0, len(archives))
+	for _, path := range archives {
+		if err := s.upload(ctx, path); err != nil {
+			return uploaded, err
+		}
+		if err := os.Remove(path); err != nil {
+			return uploaded, fmt.Errorf("failed to remove uploaded archive: %w", err)
+		}
+		uploaded = append(uploaded, filepath.Base(path))
+	}
+	return uploaded, nil
+}
+
+// moveRecords renames every record file into the sealed directory. A component that writes after the
+// rename creates a new file, which belongs to the next seal.
+func (s *Sealer) moveRecords(sealed string, now time.Time) error {
+	records, err := filepath.Glob(filepath.Join(s.Dir, "*"+recordExt))
+	if err != nil {
+		return err
+	}
+	suffix := "." + strconv.FormatInt(now.UnixNano(), 10) + recordExt
+	for _, path := range records {
+		source := strings.TrimSuffix(filepath.Base(path), recordExt)
+		if err := os.Rename(path, filepath.Join(sealed, source+suffix)); err != nil {
+			return fmt.Errorf("failed to move record %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// archive packages the moved records into <id>-<time>.tar.gz and removes them. It does nothing when
+// there are no records.
+func (s *Sealer) archive(sealed, id string, now time.Time) error {
+	records, err := filepath.Glob(filepath.Join(sealed, "*"+recordExt))
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	sort.Strings(records)
+
+	manifest := Manifest{SandboxID: id, PodNamespace: s.PodNamespace, PodName: s.PodName, SealedAt: now}
+	for _, path := range records {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read record %s: %w", path, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ArchivedFile{
+			Name:   filepath.Base(path),
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	name := fmt.Sprintf("%s-%s%s", id, now.Format("20060102T150405.000000000Z"), archiveExt)
+	tmp := filepath.Join(sealed, name+".tmp")
+	if err := writeArchive(tmp, manifest, records); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(sealed, name)); err != nil {
+		return fmt.Errorf("failed to stage archive: %w", err)
+	}
+	for _, path := range records {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove archived record %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func writeArchive(path string, manifest Manifest, records []string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, ManifestName, data, manifest.SealedAt); err != nil {
+		return err
+	}
+	for _, path := range records {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read record %s: %w", path, err)
+		}
+		if err := writeTarFile(tw, filepath.Base(path), data, manifest.SealedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return f.Sync()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: modTime}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// upload PUTs the archive to <UploadURL>/<namespace>/<pod>/<archive>.
+func (s *Sealer) upload(ctx context.Context, path string) error {
+	target, err := url.JoinPath(s.UploadURL, s.PodNamespace, s.PodName, filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("invalid upload url: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat archive: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, f)
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("Authorization", "Bearer "+s.UploadToken)
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", filepath.Base(path), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to upload %s: status=%d, body=%s", filepath.Base(path), resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUploadToken = "upload-token"
+
+type uploadServer struct {
+	mu      sync.Mutex
+	fail    bool
+	uploads map[string][]byte
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+testUploadToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPut || s.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	data, _ := io.ReadAll(r.Body)
+	s.uploads[r.URL.Path] = data
+	w.WriteHeader(http.StatusCreated)
+}
+
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = content
+	}
+}
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, testKey)
+	require.NoError(t, err)
+	require.NoError(t, r.Record(TypeTask, map[string]any{"task": "a", "to": "Running"}))
+
+	// A restarted executor continues the chain.
+	r, err = NewRecorder(dir, testKey)
+	require.NoError(t, err)
+	require.NoError(t, r.Record(TypeTask, map[string]any{"task": "a", "to": "Succeeded"}))
+
+	data, err := os.ReadFile(filepath.Join(dir, Source+recordExt))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var first, second Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, uint64(2), second.Seq)
+	assert.Equal(t, first.Hash, second.Prev)
+	hash, err := second.sum(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, second.Hash, hash)
+
+	forged, err := second.sum([]byte("fedcba9876543210fedcba9876543210"))
+	require.NoError(t, err)
+	assert.NotEqual(t, second.Hash, forged, "the chain cannot be recomputed without the key")
+
+	_, err = NewRecorder(dir, []byte("short"))
+	assert.Error(t, err)
+
+	var nilRecorder *Recorder
+	assert.NoError(t, nilRecorder.Record(TypeTask, nil))
+}
+
+func TestSealer_Seal(t *testing.T) {
+	srv := &uploadServer{uploads: map[string][]byte{}}
+	ts := httptest.NewTLSServer(srv)
+	defer ts.Close()
+
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, testKey)
+	require.NoError(t, err)
+	require.NoError(t, r.Record(TypeTask, map[string]any{"task": "a"}))
+	execd := []byte(`{"seq":1,"source":"execd"}` + "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "execd"+recordExt), execd, 0o600))
+
+	sealer, err := NewSealer(dir, ts.URL+"/records", testUploadToken, "default", "pool-abc")
+	require.NoError(t, err)
+	sealer.HTTPClient = ts.Client()
+
+	// A failed upload keeps the archive for the next seal.
+	srv.fail = true
+	_, err = sealer.Seal(context.Background(), "sbx-1")
+	require.Error(t, err)
+	pending, _ := filepath.Glob(filepath.Join(dir, sealedDir, "*"+archiveExt))
+	require.Len(t, pending, 1)
+	remaining, _ := filepath.Glob(filepath.Join(dir, "*"+recordExt))
+	assert.Empty(t, remaining, "records are moved out of the directory")
+
+	// Recording continues while the upload is retried.
+	require.NoError(t, r.Record(TypeTask, map[string]any{"task": "b"}))
+
+	srv.fail = false
+	uploaded, err := sealer.Seal(context.Background(), "sbx-2")
+	require.NoError(t, err)
+	require.Len(t, uploaded, 2)
+	assert.True(t, strings.HasPrefix(uploaded[0], "sbx-1-"))
+	assert.True(t, strings.HasPrefix(uploaded[1], "sbx-2-"))
+	pending, _ = filepath.Glob(filepath.Join(dir, sealedDir, "*"))
+	assert.Empty(t, pending)
+
+	data, ok := srv.uploads["/records/default/pool-abc/"+uploaded[0]]
+	require.True(t, ok, "archive is uploaded under namespace and pod")
+	files := readArchive(t, data)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(files[ManifestName], &manifest))
+	assert.Equal(t, "sbx-1", manifest.SandboxID)
+	assert.Equal(t, "pool-abc", manifest.PodName)
+	require.Len(t, manifest.Files, 2)
+	for _, f := range manifest.Files {
+		assert.Equal(t, int64(len(files[f.Name])), f.Size)
+	}
+	assert.Contains(t, files, manifest.Files[0].Name)
+
+	// Nothing new was recorded, so nothing is uploaded.
+	uploaded, err = sealer.Seal(context.Background(), "sbx-3")
+	require.NoError(t, err)
+	assert.Empty(t, uploaded)
+
+	_, err = sealer.Seal(context.Background(), "../escape")
+	assert.Error(t, err)
+}
+
+func TestNewSealer_RequiresHTTPSAndToken(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewSealer(dir, "http://store.example.com/records", testUploadToken, "default", "pod-a")
+	assert.ErrorContains(t, err, "https")
+	_, err = NewSealer(dir, "https://store.example.com/records", "", "default", "pod-a")
+	assert.ErrorContains(t, err, "token")
+	_, err = NewSealer(dir, "https://store.example.com/records", testUploadToken, "default", "pod-a")
+	assert.NoError(t, err)
+}
+
+func TestSealer_UploadRejected(t *testing.T) {
+	srv := &uploadServer{uploads: map[string][]byte{}}
+	ts := httptest.NewTLSServer(srv)
+	defer ts.Close()
+
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, testKey)
+	require.NoError(t, err)
+	require.NoError(t, r.Record(TypeTask, map[string]any{"task": "a"}))
+
+	sealer, err := NewSealer(dir, ts.URL, "wrong-token", "default", "pod-a")
+	require.NoError(t, err)
+	sealer.HTTPClient = ts.Client()
+	_, err = sealer.Seal(context.Background(), "sbx")
+	assert.ErrorContains(t, err, "status=401")
+	assert.Empty(t, srv.uploads)
+}
@@ -17,6 +17,7 @@ package runtime
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"k8s.io/klog/v2"
 
@@ -89,3 +90,43 @@ func (e *compositeExecutor) Stop(ctx context.Context, task *types.Task) error {
 	}
 	return delegate.Stop(ctx, task)
 }
+
+func (e *compositeExecutor) getFreezer(task *types.Task) (Freezer, error) {
+	delegate, err := e.getDelegate(task)
+	if err != nil {
+		return nil, err
+	}
+	freezer, ok := delegate.(Freezer)
+	if !ok {
+		return nil, fmt.Errorf("executor of task %s does not support freezing", task.Name)
+	}
+	return freezer, nil
+}
+
+func (e *compositeExecutor) Freeze(ctx context.Context, task *types.Task) error {
+	freezer, err := e.getFreezer(task)
+	if err != nil {
+		return err
+	}
+	return freezer.Freeze(ctx, task)
+}
+
+func (e *compositeExecutor) Thaw(ctx context.Context, task *types.Task) error {
+	freezer, err := e.getFreezer(task)
+	if err != nil {
+		return err
+	}
+	return freezer.Thaw(ctx, task)
+}
+
+func (e *compositeExecutor) Logs(ctx context.Context, task *types.Task, opts LogOptions) (io.ReadCloser, error) {
+	delegate, err := e.getDelegate(task)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := delegate.(LogReader)
+	if !ok {
+		return nil, fmt.Errorf("executor of task %s does not support reading logs", task.Name)
+	}
+	return reader.Logs(ctx, task, opts)
+}
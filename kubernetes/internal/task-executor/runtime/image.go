@@ -0,0 +1,733 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	mediaTypeOCIIndex          = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest       = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerList        = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest    = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerHubRegistry          = "registry-1.docker.io"
+	imageRootfsDir             = "rootfs"
+	imageConfigFile            = "config.json"
+	whiteoutPrefix             = ".wh."
+	whiteoutOpaqueDir          = ".wh..wh..opq"
+	maxManifestSize            = 4 << 20
+	maxSymlinksInUnpackedPaths = 40
+	partialSuffix              = ".partial"
+	evictedSuffix              = ".evicted"
+)
+
+// imageReference is a parsed image name.
+type imageReference struct {
+	Registry   string
+	Repository string
+	// Reference is the tag or digest of the image.
+	Reference string
+	// token is the bearer token the registry issued for the repository.
+	token string
+}
+
+// parseImageReference parses image names the way docker does: the first component is the registry
+// when it looks like a host, images without one come from Docker Hub, and the tag defaults to latest.
+func parseImageReference(image string) (*imageReference, error) {
+	name, reference := image, "latest"
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, reference = name[:i], name[i+1:]
+	}
+	ref := &imageReference{Registry: dockerHubRegistry, Repository: name, Reference: reference}
+	if i := strings.Index(name, "/"); i >= 0 {
+		if host := name[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry, ref.Repository = host, name[i+1:]
+		}
+	}
+	if ref.Registry == "docker.io" || ref.Registry == "index.docker.io" {
+		ref.Registry = dockerHubRegistry
+	}
+	if ref.Registry == dockerHubRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" || reference == "" {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+	return ref, nil
+}
+
+type imageDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+// imageManifest holds the fields of an image manifest and of an image index.
+type imageManifest struct {
+	MediaType string            `json:"mediaType"`
+	Config    imageDescriptor   `json:"config"`
+	Layers    []imageDescriptor `json:"layers"`
+	Manifests []imageDescriptor `json:"manifests"`
+}
+
+// imageConfig holds the fields of the image configuration the task runs with.
+type imageConfig struct {
+	Config struct {
+		Env        []string `json:"Env"`
+		WorkingDir string   `json:"WorkingDir"`
+	} `json:"config"`
+}
+
+// unpackedImage is an image whose root filesystem has been unpacked.
+type unpackedImage struct {
+	// Name is the directory of the image under the image directory, named after its manifest digest.
+	Name       string
+	Env        []string
+	WorkingDir string
+}
+
+// imagePuller is a minimal client of the OCI distribution API that unpacks images into directories.
+// It supports anonymous pulls, and the bearer tokens registries hand out for them.
+type imagePuller struct {
+	client *http.Client
+	// scheme is the scheme registries are reached with.
+	scheme string
+	// maxImages is how many unpacked images are kept when no task uses them; 0 keeps all of them.
+	maxImages int
+	// taskDone reports whether a task no longer runs in the root filesystem of its image.
+	taskDone func(task string) bool
+
+	// mu guards locks and users.
+	mu sync.Mutex
+	// locks serializes the pulls of each image, so an image is unpacked once however many tasks use
+	// it, while different images are pulled in parallel.
+	locks map[string]*imageLock
+	// users holds the image directory each task runs in.
+	users map[string]string
+}
+
+// imageLock is the lock of one image directory, a semaphore so waiting for it can be canceled.
+type imageLock struct {
+	sem  chan struct{}
+	refs int
+}
+
+func newImagePuller(maxImages int, taskDone func(task string) bool) *imagePuller {
+	return &imagePuller{
+		client:    http.DefaultClient,
+		scheme:    "https",
+		maxImages: maxImages,
+		taskDone:  taskDone,
+		locks:     map[string]*imageLock{},
+		users:     map[string]string{},
+	}
+}
+
+// lock takes the lock of the image directory name. With wait unset it gives up at once when the
+// lock is held, and returns a nil unlock.
+func (p *imagePuller) lock(ctx context.Context, name string, wait bool) (func(), error) {
+	p.mu.Lock()
+	l := p.locks[name]
+	if l == nil {
+		l = &imageLock{sem: make(chan struct{}, 1)}
+		p.locks[name] = l
+	}
+	l.refs++
+	p.mu.Unlock()
+	release := func() {
+		p.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(p.locks, name)
+		}
+		p.mu.Unlock()
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem; release() }, nil
+	default:
+	}
+	if !wait {
+		release()
+		return nil, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem; release() }, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// Pull unpacks the image for the platform of the executor into a directory under dir named after
+// the digest of its manifest, unless it has been unpacked already, and records that task runs in
+// it. The image is unpacked into a temporary directory first, so an interrupted pull is started over.
+// Once a new image is in place, images beyond maxImages that no task runs in are removed, least
+// recently pulled first.
+func (p *imagePuller) Pull(ctx context.Context, task, image, dir string) (*unpackedImage, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+	manifest, digest, err := p.resolveManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ReplaceAll(digest, ":", "-")
+	target := filepath.Join(dir, name)
+
+	unlock, err := p.lock(ctx, name, true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	configPath := filepath.Join(target, imageConfigFile)
+	if data, err := os.ReadFile(configPath); err == nil {
+		p.use(task, name, configPath)
+		return decodeImageConfig(name, data)
+	}
+
+	partial := target + partialSuffix
+	if err := os.RemoveAll(partial); err != nil {
+		return nil, err
+	}
+	rootfs := filepath.Join(partial, imageRootfsDir)
+	if err := os.MkdirAll(rootfs, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create image directory: %w", err)
+	}
+	config, err := p.fetchBlob(ctx, ref, manifest.Config.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the config of image %s: %w", image, err)
+	}
+	for _, layer := range manifest.Layers {
+		if err := p.unpackBlob(ctx, ref, layer, rootfs); err != nil {
+			return nil, fmt.Errorf("failed to unpack layer %s of image %s: %w", layer.Digest, image, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(partial, imageConfigFile), config, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(partial, target); err != nil {
+		return nil, fmt.Errorf("failed to move image into place: %w", err)
+	}
+	klog.InfoS("Unpacked image", "image", image, "digest", digest, "dir", target)
+	p.use(task, name, configPath)
+	// The lock of the new image is held, so eviction, which only takes free locks, leaves it alone.
+	p.evict(ctx, dir)
+	return decodeImageConfig(name, config)
+}
+
+// use records that task runs in the image directory name, and marks the image as used now.
+func (p *imagePuller) use(task, name, configPath string) {
+	now := time.Now()
+	if err := os.Chtimes(configPath, now, now); err != nil {
+		klog.V(1).InfoS("failed to mark image as used", "dir", filepath.Dir(configPath), "err", err)
+	}
+	p.mu.Lock()
+	p.users[task] = name
+	p.mu.Unlock()
+}
+
+// inUse returns the image directories tasks still run in, forgetting the tasks that are done.
+func (p *imagePuller) inUse() map[string]bool {
+	p.mu.Lock()
+	users := make(map[string]string, len(p.users))
+	for task, name := range p.users {
+		users[task] = name
+	}
+	p.mu.Unlock()
+
+	used := map[string]bool{}
+	for task, name := range users {
+		if p.taskDone == nil || !p.taskDone(task) {
+			used[name] = true
+			continue
+		}
+		p.mu.Lock()
+		if p.users[task] == name {
+			delete(p.users, task)
+		}
+		p.mu.Unlock()
+	}
+	return used
+}
+
+// evict removes the least recently used images under dir beyond maxImages that no task runs in, and
+// leftovers of earlier evictions. Images being pulled are skipped.
+func (p *imagePuller) evict(ctx context.Context, dir string) {
+	if p.maxImages <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		klog.ErrorS(err, "failed to list images", "dir", dir)
+		return
+	}
+	type cachedImage struct {
+		name    string
+		lastUse time.Time
+	}
+	var images []cachedImage
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), partialSuffix) {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), evictedSuffix) {
+			_ = os.RemoveAll(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, entry.Name(), imageConfigFile))
+		if err != nil {
+			continue
+		}
+		images = append(images, cachedImage{name: entry.Name(), lastUse: info.ModTime()})
+	}
+	if len(images) <= p.maxImages {
+		return
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].lastUse.After(images[j].lastUse) })
+
+	used := p.inUse()
+	kept := 0
+	for _, image := range images {
+		if kept < p.maxImages || used[image.name] {
+			kept++
+			continue
+		}
+		unlock, _ := p.lock(ctx, image.name, false)
+		if unlock == nil {
+			kept++
+			continue
+		}
+		// A task may have started using the image since the users were read.
+		p.mu.Lock()
+		var busy bool
+		for _, name := range p.users {
+			busy = busy || name == image.name
+		}
+		p.mu.Unlock()
+		if busy {
+			unlock()
+			kept++
+			continue
+		}
+		// The directory is moved away first, so it is never found half removed.
+		target := filepath.Join(dir, image.name)
+		err := os.Rename(target, target+evictedSuffix)
+		unlock()
+		if err != nil {
+			klog.ErrorS(err, "failed to evict image", "dir", target)
+			continue
+		}
+		if err := os.RemoveAll(target + evictedSuffix); err != nil {
+			klog.ErrorS(err, "failed to remove evicted image", "dir", target)
+		}
+		klog.InfoS("Evicted unused image", "dir", target)
+	}
+}
+
+func decodeImageConfig(name string, data []byte) (*unpackedImage, error) {
+	config := &imageConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to decode image config: %w", err)
+	}
+	return &unpackedImage{Name: name, Env: config.Config.Env, WorkingDir: config.Config.WorkingDir}, nil
+}
+
+// resolveManifest returns the image manifest of ref and its digest, picking the manifest of the
+// platform of the executor from an image index.
+func (p *imagePuller) resolveManifest(ctx context.Context, ref *imageReference) (*imageManifest, string, error) {
+	manifest, digest, err := p.fetchManifest(ctx, ref, ref.Reference)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(manifest.Manifests) == 0 {
+		return manifest, digest, nil
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.Platform != nil && desc.Platform.OS == goruntime.GOOS && desc.Platform.Architecture == goruntime.GOARCH {
+			return p.fetchManifest(ctx, ref, desc.Digest)
+		}
+	}
+	return nil, "", fmt.Errorf("image %s/%s:%s has no manifest for %s/%s", ref.Registry, ref.Repository, ref.Reference, goruntime.GOOS, goruntime.GOARCH)
+}
+
+func (p *imagePuller) fetchManifest(ctx context.Context, ref *imageReference, reference string) (*imageManifest, string, error) {
+	resp, err := p.get(ctx, ref, "manifests/"+reference,
+		mediaTypeOCIIndex, mediaTypeOCIManifest, mediaTypeDockerList, mediaTypeDockerManifest)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if strings.Contains(reference, ":") && reference != digest {
+		return nil, "", fmt.Errorf("manifest %s has digest %s", reference, digest)
+	}
+	manifest := &imageManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return manifest, digest, nil
+}
+
+func (p *imagePuller) fetchBlob(ctx context.Context, ref *imageReference, digest string) ([]byte, error) {
+	verifier, err := newDigestVerifier(digest)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.get(ctx, ref, "blobs/"+digest)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.TeeReader(io.LimitReader(resp.Body, maxManifestSize), verifier))
+	if err != nil {
+		return nil, err
+	}
+	return data, verifier.Verify()
+}
+
+// unpackBlob streams a layer into rootfs, verifying its digest on the way.
+func (p *imagePuller) unpackBlob(ctx context.Context, ref *imageReference, layer imageDescriptor, rootfs string) error {
+	if strings.Contains(layer.MediaType, "zstd") {
+		return fmt.Errorf("unsupported layer media type %s", layer.MediaType)
+	}
+	verifier, err := newDigestVerifier(layer.Digest)
+	if err != nil {
+		return err
+	}
+	resp, err := p.get(ctx, ref, "blobs/"+layer.Digest)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body := bufio.NewReader(io.TeeReader(resp.Body, verifier))
+	var layerReader io.Reader = body
+	if magic, _ := body.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		layerReader = gz
+	}
+	if err := unpackLayer(layerReader, rootfs); err != nil {
+		return err
+	}
+	// Read what is left after the tar trailer, so the whole blob is verified.
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	return verifier.Verify()
+}
+
+// get requests a path under the repository of ref, fetching a bearer token when the registry asks
+// for one.
+func (p *imagePuller) get(ctx context.Context, ref *imageReference, subPath string, accept ...string) (*http.Response, error) {
+	target := fmt.Sprintf("%s://%s/v2/%s/%s", p.scheme, ref.Registry, ref.Repository, subPath)
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		if ref.token != "" {
+			req.Header.Set("Authorization", "Bearer "+ref.token)
+		}
+		return p.client.Do(req)
+	}
+	resp, err := do()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && ref.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if ref.token, err = p.fetchToken(ctx, challenge); err != nil {
+			return nil, fmt.Errorf("failed to authenticate to %s: %w", ref.Registry, err)
+		}
+		if resp, err = do(); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s: %s", target, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// fetchToken requests an anonymous token from the realm of a Bearer challenge.
+func (p *imagePuller) fetchToken(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	values := parseChallengeParams(params)
+	realm := values["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("authentication challenge %q has no realm", challenge)
+	}
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if values[key] != "" {
+			query.Set(key, values[key])
+		}
+	}
+	if len(query) > 0 {
+		realm += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed: %s", resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseChallengeParams parses the comma-separated key="value" parameters of a challenge. Values may
+// contain commas, as scopes with several actions do.
+func parseChallengeParams(params string) map[string]string {
+	values := make(map[string]string)
+	for params != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			value, params = rest[1:end+1], rest[min(end+2, len(rest)):]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+		}
+		values[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return values
+}
+
+// digestVerifier hashes what is written to it and compares the result with a digest.
+type digestVerifier struct {
+	hash.Hash
+	digest string
+}
+
+func newDigestVerifier(digest string) (*digestVerifier, error) {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return nil, fmt.Errorf("unsupported digest %q", digest)
+	}
+	return &digestVerifier{Hash: sha256.New(), digest: digest}, nil
+}
+
+func (v *digestVerifier) Verify() error {
+	if actual := "sha256:" + hex.EncodeToString(v.Sum(nil)); actual != v.digest {
+		return fmt.Errorf("expected digest %s, got %s", v.digest, actual)
+	}
+	return nil
+}
+
+// unpackLayer applies a layer tarball to rootfs, including the whiteouts that delete files of lower
+// layers. Device files are skipped, as the executor may not be allowed to create them.
+func unpackLayer(r io.Reader, rootfs string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := resolveInRootfs(rootfs, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if target == rootfs {
+			continue
+		}
+		parent, base := filepath.Split(target)
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return err
+		}
+		if base == whiteoutOpaqueDir {
+			entries, err := os.ReadDir(parent)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				if err := os.RemoveAll(filepath.Join(parent, entry.Name())); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			if err := os.RemoveAll(filepath.Join(parent, strings.TrimPrefix(base, whiteoutPrefix))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		mode := os.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if info, err := os.Lstat(target); err != nil || !info.IsDir() {
+				if err := os.RemoveAll(target); err != nil {
+					return err
+				}
+				if err := os.Mkdir(target, mode); err != nil {
+					return err
+				}
+			}
+			if err := os.Chmod(target, mode); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			source, err := resolveInRootfs(rootfs, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		// Ownership is kept when the executor may set it.
+		_ = os.Lchown(target, hdr.Uid, hdr.Gid)
+	}
+}
+
+// resolveInRootfs resolves name the way it would be resolved after a chroot into rootfs, so
+// symlinks unpacked from a layer cannot lead outside of it. The last component is not followed.
+func resolveInRootfs(rootfs, name string) (string, error) {
+	resolved := "/"
+	remaining := strings.Split(path.Clean("/"+name), "/")
+	links := 0
+	for len(remaining) > 0 {
+		component := remaining[0]
+		remaining = remaining[1:]
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+		next := path.Join(resolved, component)
+		if len(remaining) == 0 {
+			resolved = next
+			break
+		}
+		info, err := os.Lstat(filepath.Join(rootfs, next))
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if links++; links > maxSymlinksInUnpackedPaths {
+			return "", fmt.Errorf("too many symlinks in %s", name)
+		}
+		link, err := os.Readlink(filepath.Join(rootfs, next))
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(link) {
+			resolved = "/"
+		}
+		remaining = append(strings.Split(link, "/"), remaining...)
+	}
+	return filepath.Join(rootfs, resolved), nil
+}
@@ -0,0 +1,342 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func buildLayer(t *testing.T, entries ...tarEntry) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0755, Size: int64(len(e.body)), Linkname: e.linkname}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if e.body != "" {
+			_, err := tw.Write([]byte(e.body))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// fakeRegistry serves a single repository that requires a bearer token.
+type fakeRegistry struct {
+	*httptest.Server
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	blobGets  atomic.Int32
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	r := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "repository:tools/jq:pull", req.URL.Query().Get("scope"))
+		_, _ = w.Write([]byte(`{"token":"secret"}`))
+	})
+	mux.HandleFunc("/v2/tools/jq/", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+r.URL+`/token",service="registry",scope="repository:tools/jq:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		kind, ref, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/v2/tools/jq/"), "/")
+		var data []byte
+		switch kind {
+		case "manifests":
+			data = r.manifests[ref]
+		case "blobs":
+			r.blobGets.Add(1)
+			data = r.blobs[ref]
+		}
+		if data == nil {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(data)
+	})
+	r.Server = httptest.NewServer(mux)
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeRegistry) addBlob(data []byte) string {
+	digest := digestOf(data)
+	r.blobs[digest] = data
+	return digest
+}
+
+// addImage publishes an image index for the platform of the test under tag.
+func (r *fakeRegistry) addImage(t *testing.T, tag string, config string, layers ...[]byte) {
+	manifest := map[string]any{
+		"mediaType": mediaTypeOCIManifest,
+		"config":    map[string]any{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": r.addBlob([]byte(config))},
+	}
+	var descs []map[string]any
+	for _, layer := range layers {
+		descs = append(descs, map[string]any{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": r.addBlob(layer)})
+	}
+	manifest["layers"] = descs
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	r.manifests[digestOf(data)] = data
+	index, err := json.Marshal(map[string]any{
+		"mediaType": mediaTypeOCIIndex,
+		"manifests": []map[string]any{
+			{"mediaType": mediaTypeOCIManifest, "digest": "sha256:0000", "platform": map[string]string{"os": "plan9", "architecture": goruntime.GOARCH}},
+			{"mediaType": mediaTypeOCIManifest, "digest": digestOf(data), "platform": map[string]string{"os": goruntime.GOOS, "architecture": goruntime.GOARCH}},
+		},
+	})
+	require.NoError(t, err)
+	r.manifests[tag] = index
+}
+
+func (r *fakeRegistry) image(tag string) string {
+	return strings.TrimPrefix(r.URL, "http://") + "/tools/jq:" + tag
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  imageReference
+	}{
+		{"busybox", imageReference{Registry: dockerHubRegistry, Repository: "library/busybox", Reference: "latest"}},
+		{"docker.io/user/tool:1.0", imageReference{Registry: dockerHubRegistry, Repository: "user/tool", Reference: "1.0"}},
+		{"localhost:5000/tool", imageReference{Registry: "localhost:5000", Repository: "tool", Reference: "latest"}},
+		{"ghcr.io/org/tool@sha256:abcd", imageReference{Registry: "ghcr.io", Repository: "org/tool", Reference: "sha256:abcd"}},
+	}
+	for _, tt := range tests {
+		ref, err := parseImageReference(tt.image)
+		require.NoError(t, err, tt.image)
+		assert.Equal(t, tt.want, *ref, tt.image)
+	}
+	_, err := parseImageReference("tool:")
+	assert.Error(t, err)
+}
+
+func TestImagePullerPull(t *testing.T) {
+	registry := newFakeRegistry(t)
+	base := buildLayer(t,
+		tarEntry{name: "bin/", typeflag: tar.TypeDir},
+		tarEntry{name: "bin/jq", typeflag: tar.TypeReg, body: "v1"},
+		tarEntry{name: "etc/old.conf", typeflag: tar.TypeReg, body: "old"},
+		tarEntry{name: "escape", typeflag: tar.TypeSymlink, linkname: "/"},
+	)
+	top := buildLayer(t,
+		tarEntry{name: "bin/jq", typeflag: tar.TypeReg, body: "v2"},
+		tarEntry{name: "etc/.wh.old.conf", typeflag: tar.TypeReg},
+		tarEntry{name: "escape/tmp/pwned", typeflag: tar.TypeReg, body: "x"},
+		tarEntry{name: "bin/jq-link", typeflag: tar.TypeLink, linkname: "bin/jq"},
+	)
+	registry.addImage(t, "1.7", `{"config":{"Env":["PATH=/bin"],"WorkingDir":"/work"}}`, base, top)
+
+	puller := newImagePuller(0, nil)
+	puller.scheme = "http"
+	dir := t.TempDir()
+	image, err := puller.Pull(context.Background(), "task", registry.image("1.7"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/bin"}, image.Env)
+	assert.Equal(t, "/work", image.WorkingDir)
+
+	rootfs := filepath.Join(dir, image.Name, imageRootfsDir)
+	data, err := os.ReadFile(filepath.Join(rootfs, "bin", "jq"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data), "upper layers win")
+	data, err = os.ReadFile(filepath.Join(rootfs, "bin", "jq-link"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+	assert.NoFileExists(t, filepath.Join(rootfs, "etc", "old.conf"), "whiteouts delete lower files")
+	assert.FileExists(t, filepath.Join(rootfs, "tmp", "pwned"), "symlinks resolve inside the rootfs")
+	assert.NoDirExists(t, filepath.Join(dir, image.Name+".partial"))
+
+	gets := registry.blobGets.Load()
+	again, err := puller.Pull(context.Background(), "task", registry.image("1.7"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, image, again)
+	assert.Equal(t, gets, registry.blobGets.Load(), "unpacked images are reused")
+}
+
+func TestImagePullerRejectsCorruptLayer(t *testing.T) {
+	registry := newFakeRegistry(t)
+	layer := buildLayer(t, tarEntry{name: "bin/jq", typeflag: tar.TypeReg, body: "v1"})
+	registry.addImage(t, "bad", `{}`, layer)
+	registry.blobs[digestOf(layer)] = buildLayer(t, tarEntry{name: "bin/jq", typeflag: tar.TypeReg, body: "evil"})
+
+	puller := newImagePuller(0, nil)
+	puller.scheme = "http"
+	dir := t.TempDir()
+	_, err := puller.Pull(context.Background(), "task", registry.image("bad"), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected digest")
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		assert.True(t, strings.HasSuffix(entry.Name(), ".partial"), "a corrupt image is never moved into place")
+	}
+}
+
+func TestImagePullerPullsAnImageOnce(t *testing.T) {
+	registry := newFakeRegistry(t)
+	registry.addImage(t, "1.7", `{}`, buildLayer(t, tarEntry{name: "bin/jq", typeflag: tar.TypeReg, body: "v1"}))
+	puller := newImagePuller(0, nil)
+	puller.scheme = "http"
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := puller.Pull(context.Background(), fmt.Sprintf("task-%d", i), registry.image("1.7"), dir)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), registry.blobGets.Load(), "the config and the layer are fetched once")
+	assert.Empty(t, puller.locks, "locks are dropped once released")
+}
+
+func TestImagePullerEvictsUnusedImages(t *testing.T) {
+	registry := newFakeRegistry(t)
+	for _, tag := range []string{"1", "2", "3"} {
+		registry.addImage(t, tag, `{"config":{"WorkingDir":"/`+tag+`"}}`, buildLayer(t, tarEntry{name: "v", typeflag: tar.TypeReg, body: tag}))
+	}
+	done := map[string]bool{}
+	puller := newImagePuller(1, func(task string) bool { return done[task] })
+	puller.scheme = "http"
+	dir := t.TempDir()
+	pull := func(task, tag string) *unpackedImage {
+		image, err := puller.Pull(context.Background(), task, registry.image(tag), dir)
+		require.NoError(t, err)
+		return image
+	}
+
+	first := pull("a", "1")
+	second := pull("b", "2")
+	assert.DirExists(t, filepath.Join(dir, first.Name), "images tasks run in are kept")
+	assert.DirExists(t, filepath.Join(dir, second.Name))
+
+	done["a"], done["b"] = true, true
+	third := pull("c", "3")
+	assert.DirExists(t, filepath.Join(dir, third.Name))
+	assert.NoDirExists(t, filepath.Join(dir, second.Name), "unused images beyond the limit are evicted")
+	assert.NoDirExists(t, filepath.Join(dir, first.Name))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// An evicted image is pulled again.
+	again := pull("d", "1")
+	assert.Equal(t, first, again)
+	assert.FileExists(t, filepath.Join(dir, first.Name, imageRootfsDir, "v"))
+}
+
+func TestChrootCommand(t *testing.T) {
+	assert.Equal(t, []string{"chroot", "/images/x/rootfs", "jq", "."}, chrootCommand("/images/x/rootfs", "", []string{"jq", "."}))
+	assert.Equal(t, []string{"chroot", "/images/x/rootfs", "/bin/sh", "-c", `cd "$0" && exec "$@"`, "/work", "jq", "."},
+		chrootCommand("/images/x/rootfs", "/work", []string{"jq", "."}))
+}
+
+func TestProcessExecutor_ImagePullFailure(t *testing.T) {
+	registry := newFakeRegistry(t)
+	dataDir := t.TempDir()
+	executor, err := NewProcessExecutor(&config.Config{DataDir: dataDir, ImageDir: t.TempDir()})
+	require.NoError(t, err)
+	executor.(*processExecutor).images.scheme = "http"
+	task := &types.Task{Name: "image-task", Process: &api.Process{Command: []string{"jq"}, Image: registry.image("missing")}}
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, task.Name), 0755))
+
+	require.NoError(t, executor.Start(context.Background(), task))
+	require.Eventually(t, func() bool {
+		status, err := executor.Inspect(context.Background(), task)
+		return err == nil && status.State == types.TaskStateFailed
+	}, 5*time.Second, 20*time.Millisecond)
+	status, err := executor.Inspect(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, ReasonImagePullFailed, status.SubStatuses[0].Reason)
+	assert.Contains(t, status.SubStatuses[0].Message, "404")
+	assert.NotNil(t, status.SubStatuses[0].FinishedAt)
+}
+
+func TestProcessExecutor_StopWhilePullingImage(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+		http.NotFound(w, req)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	dataDir := t.TempDir()
+	executor, err := NewProcessExecutor(&config.Config{DataDir: dataDir, ImageDir: t.TempDir()})
+	require.NoError(t, err)
+	executor.(*processExecutor).images.scheme = "http"
+	task := &types.Task{Name: "slow-image", Process: &api.Process{Command: []string{"jq"}, Image: strings.TrimPrefix(server.URL, "http://") + "/tools/jq"}}
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, task.Name), 0755))
+
+	require.NoError(t, executor.Start(context.Background(), task))
+	status, err := executor.Inspect(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStatePending, status.State)
+	assert.Equal(t, ReasonPullingImage, status.SubStatuses[0].Reason)
+
+	require.NoError(t, executor.Stop(context.Background(), task))
+	require.Eventually(t, func() bool {
+		status, err := executor.Inspect(context.Background(), task)
+		return err == nil && status.State == types.TaskStateFailed
+	}, 5*time.Second, 20*time.Millisecond, "a task stopped while pulling reaches a terminal state")
+}
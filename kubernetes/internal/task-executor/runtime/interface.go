@@ -16,6 +16,7 @@ package runtime
 
 import (
 	"context"
+	"io"
 
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
 )
@@ -28,3 +29,39 @@ type Executor interface {
 
 	Stop(ctx context.Context, task *types.Task) error
 }
+
+// Freezer is implemented by executors that can suspend a running task in place and continue it
+// later without losing its state.
+type Freezer interface {
+	// Freeze stops every process of the task. Freezing a frozen or finished task is a no-op.
+	Freeze(ctx context.Context, task *types.Task) error
+	// Thaw continues a frozen task. Thawing a task that is not frozen is a no-op.
+	Thaw(ctx context.Context, task *types.Task) error
+}
+
+// LogStream names an output stream of a task.
+type LogStream string
+
+const (
+	LogStreamStdout LogStream = "stdout"
+	LogStreamStderr LogStream = "stderr"
+)
+
+// LogOptions selects the output returned by a LogReader.
+type LogOptions struct {
+	// Stream is the output stream to read. Empty means stdout.
+	Stream LogStream
+	// Container picks the container of a container-mode task. Empty means the first container.
+	// Process tasks have a single output and ignore it.
+	Container string
+	// Follow keeps reading output as it is written until ctx is done. The output written up to
+	// that point is still returned before the reader reports io.EOF.
+	Follow bool
+}
+
+// LogReader is implemented by executors that expose the output of their tasks. Every executor
+// returns the raw bytes the task wrote, wherever they are stored, so logs are streamed the same way
+// for every mode.
+type LogReader interface {
+	Logs(ctx context.Context, task *types.Task, opts LogOptions) (io.ReadCloser, error)
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/utils"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+// logPollInterval is how often a followed log file is checked for new output.
+var logPollInterval = 200 * time.Millisecond
+
+func logStream(opts LogOptions) (LogStream, error) {
+	switch opts.Stream {
+	case "", LogStreamStdout:
+		return LogStreamStdout, nil
+	case LogStreamStderr:
+		return LogStreamStderr, nil
+	default:
+		return "", fmt.Errorf("unknown log stream %q", opts.Stream)
+	}
+}
+
+// openLog opens a log file, following it if requested.
+func openLog(ctx context.Context, path string, follow bool) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !follow {
+		return file, nil
+	}
+	return &followReader{ctx: ctx, file: file}, nil
+}
+
+// followReader reads a file that is still being written. At the end of the file it waits for more
+// output until ctx is done, then returns what was written meanwhile and io.EOF.
+type followReader struct {
+	ctx      context.Context
+	file     *os.File
+	draining bool
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		if n > 0 || err != io.EOF || r.draining {
+			return n, err
+		}
+		select {
+		case <-r.ctx.Done():
+			r.draining = true
+		case <-time.After(logPollInterval):
+		}
+	}
+}
+
+func (r *followReader) Close() error {
+	return r.file.Close()
+}
+
+// Logs returns the stdout or stderr file the shim redirects the task output to.
+func (e *processExecutor) Logs(ctx context.Context, task *types.Task, opts LogOptions) (io.ReadCloser, error) {
+	if task == nil {
+		return nil, fmt.Errorf("task cannot be nil")
+	}
+	stream, err := logStream(opts)
+	if err != nil {
+		return nil, err
+	}
+	taskDir, err := utils.SafeJoin(e.rootDir, task.Name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid task name: %w", err)
+	}
+	file := StdoutFile
+	if stream == LogStreamStderr {
+		file = StderrFile
+	}
+	return openLog(ctx, filepath.Join(taskDir, file), opts.Follow)
+}
+
+// containerLogPath is the CRI log path of a container of a container-mode task. The runtime writes
+// both streams of the container to it in the CRI logging format.
+func containerLogPath(rootDir string, task *types.Task, container string) (string, error) {
+	taskDir, err := utils.SafeJoin(rootDir, task.Name)
+	if err != nil {
+		return "", fmt.Errorf("invalid task name: %w", err)
+	}
+	if container == "" {
+		if task.PodTemplateSpec == nil || len(task.PodTemplateSpec.Spec.Containers) == 0 {
+			return "", fmt.Errorf("task %s has no containers", task.Name)
+		}
+		container = task.PodTemplateSpec.Spec.Containers[0].Name
+	}
+	return utils.SafeJoin(filepath.Join(taskDir, "containers"), container+".log")
+}
+
+// Logs decodes the CRI log file of the container, so container tasks return the same raw output as
+// process tasks.
+func (e *containerExecutor) Logs(ctx context.Context, task *types.Task, opts LogOptions) (io.ReadCloser, error) {
+	if task == nil {
+		return nil, fmt.Errorf("task cannot be nil")
+	}
+	stream, err := logStream(opts)
+	if err != nil {
+		return nil, err
+	}
+	path, err := containerLogPath(e.config.DataDir, task, opts.Container)
+	if err != nil {
+		return nil, err
+	}
+	src, err := openLog(ctx, path, opts.Follow)
+	if err != nil {
+		return nil, err
+	}
+	return newCRILogReader(src, stream), nil
+}
+
+// criLogReader decodes the CRI logging format, "<timestamp> <stream> <P|F> <content>" per line, into
+// the raw output of one stream. Partial (P) lines are joined with the line that follows them.
+type criLogReader struct {
+	src    io.ReadCloser
+	lines  *bufio.Reader
+	stream []byte
+	buf    []byte
+}
+
+func newCRILogReader(src io.ReadCloser, stream LogStream) io.ReadCloser {
+	return &criLogReader{src: src, lines: bufio.NewReader(src), stream: []byte(stream)}
+}
+
+func (r *criLogReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		line, err := r.lines.ReadBytes('\n')
+		r.buf = r.decode(line)
+		if err != nil {
+			if len(r.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *criLogReader) decode(line []byte) []byte {
+	fields := bytes.SplitN(bytes.TrimSuffix(line, []byte("\n")), []byte(" "), 4)
+	if len(fields) < 3 || !bytes.Equal(fields[1], r.stream) {
+		return nil
+	}
+	var content []byte
+	if len(fields) == 4 {
+		content = fields[3]
+	}
+	if !bytes.Equal(fields[2], []byte("P")) {
+		content = append(content, '\n')
+	}
+	return content
+}
+
+func (r *criLogReader) Close() error {
+	return r.src.Close()
+}
+
+// captureOutput inlines the end of stdout and stderr in a terminal sub-status when the task asks for it
+// with Process.CaptureOutputBytes.
+func captureOutput(task *types.Task, taskDir string, sub *types.SubStatus) {
+	if task.Process == nil || task.Process.CaptureOutputBytes <= 0 {
+		return
+	}
+	n := min(int64(task.Process.CaptureOutputBytes), api.MaxCaptureOutputBytes)
+	sub.Stdout = tailFile(filepath.Join(taskDir, StdoutFile), n)
+	sub.Stderr = tailFile(filepath.Join(taskDir, StderrFile), n)
+}
+
+// tailFile returns up to the last n bytes of a file, dropping a rune cut at the start; "" if unreadable.
+func tailFile(path string, n int64) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := max(info.Size()-n, 0)
+	buf := make([]byte, info.Size()-offset)
+	read, err := file.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	buf = buf[:read]
+	for i := 0; offset > 0 && i < utf8.UTFMax-1 && len(buf) > 0 && !utf8.RuneStart(buf[0]); i++ {
+		buf = buf[1:]
+	}
+	return string(buf)
+}
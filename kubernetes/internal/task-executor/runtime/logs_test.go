@@ -0,0 +1,107 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+)
+
+func readLogs(t *testing.T, reader LogReader, task *types.Task, opts LogOptions) string {
+	logs, err := reader.Logs(context.Background(), task, opts)
+	require.NoError(t, err)
+	defer logs.Close()
+	out, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestProcessExecutor_Logs(t *testing.T) {
+	executor, dataDir := setupTestExecutor(t)
+	reader := executor.(LogReader)
+	task := &types.Task{Name: "logs"}
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, task.Name), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, task.Name, StdoutFile), []byte("out\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, task.Name, StderrFile), []byte("err\n"), 0644))
+
+	assert.Equal(t, "out\n", readLogs(t, reader, task, LogOptions{}))
+	assert.Equal(t, "err\n", readLogs(t, reader, task, LogOptions{Stream: LogStreamStderr}))
+	_, err := reader.Logs(context.Background(), task, LogOptions{Stream: "stdin"})
+	assert.Error(t, err)
+	_, err = reader.Logs(context.Background(), &types.Task{Name: "missing"}, LogOptions{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFollowReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StdoutFile)
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0644))
+	ctx, cancel := context.WithCancel(context.Background())
+	logs, err := openLog(ctx, path, true)
+	require.NoError(t, err)
+	defer logs.Close()
+
+	go func() {
+		time.Sleep(2 * logPollInterval)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err == nil {
+			file.WriteString("second\n")
+			file.Close()
+		}
+		cancel()
+	}()
+	out, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(out), "output written before the follow ends is returned")
+}
+
+func TestContainerExecutor_Logs(t *testing.T) {
+	dataDir := t.TempDir()
+	executor, err := newContainerExecutor(&config.Config{DataDir: dataDir})
+	require.NoError(t, err)
+	reader := executor.(LogReader)
+	task := &types.Task{Name: "container", PodTemplateSpec: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "main"}, {Name: "sidecar"}},
+	}}}
+	cri := strings.Join([]string{
+		"2025-01-01T00:00:00.000000000Z stdout F hello",
+		"2025-01-01T00:00:00.100000000Z stderr F oops",
+		"2025-01-01T00:00:00.200000000Z stdout P par",
+		"2025-01-01T00:00:00.300000000Z stdout F tial",
+		"2025-01-01T00:00:00.400000000Z stdout F ",
+		"",
+	}, "\n")
+	dir := filepath.Join(dataDir, task.Name, "containers")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.log"), []byte(cri), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sidecar.log"), []byte("2025-01-01T00:00:00Z stdout F side\n"), 0644))
+
+	assert.Equal(t, "hello\npartial\n\n", readLogs(t, reader, task, LogOptions{}))
+	assert.Equal(t, "oops\n", readLogs(t, reader, task, LogOptions{Stream: LogStreamStderr}))
+	assert.Equal(t, "side\n", readLogs(t, reader, task, LogOptions{Container: "sidecar"}))
+	_, err = reader.Logs(context.Background(), task, LogOptions{Container: "../main"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// OOMBaselineFile holds the OOM kill counter of the executor cgroup when the task started.
+	OOMBaselineFile = "oom_baseline"
+	// OOMKilledFile holds the message of a task that was attributed to the OOM killer.
+	OOMKilledFile = "oom_killed"
+
+	ReasonOOMKilled = "OOMKilled"
+
+	// sigkillExitCode is what the shim records when the command was killed with SIGKILL.
+	sigkillExitCode = 128 + 9
+)
+
+var (
+	procRoot   = "/proc"
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// cgroupOOMKills returns how many processes the OOM killer has killed in the memory cgroup of the
+// executor. Tasks run in the same cgroup in host and sidecar mode, as nsenter keeps the cgroup.
+func cgroupOOMKills() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "cgroup"))
+	if err != nil {
+		return 0, err
+	}
+	var v1Path, v2Path string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			v2Path = filepath.Join(cgroupRoot, parts[2], "memory.events")
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			if controller == "memory" {
+				v1Path = filepath.Join(cgroupRoot, "memory", parts[2], "memory.oom_control")
+			}
+		}
+	}
+	// On hybrid hosts the memory controller stays on the v1 hierarchy.
+	path := v1Path
+	if path == "" {
+		path = v2Path
+	}
+	if path == "" {
+		return 0, fmt.Errorf("memory cgroup not found")
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	// Both memory.events (v2) and memory.oom_control (v1) hold an "oom_kill <count>" line.
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[0] == "oom_kill" {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("no oom_kill counter in %s", path)
+}
+
+// processTreeRSS returns the resident memory of the process and its descendants in bytes. The tree
+// is followed through /proc/<pid>/task/<tid>/children, so only the processes of the task are read
+// rather than all of /proc. Processes that detached from the tree, by daemonizing, are not counted.
+func processTreeRSS(pid int) uint64 {
+	var pages uint64
+	seen := map[int]bool{}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		dir := filepath.Join(procRoot, strconv.Itoa(p))
+		// statm holds size, resident, shared, ... in pages.
+		if data, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+			if fields := strings.Fields(string(data)); len(fields) > 1 {
+				if rss, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+					pages += rss
+				}
+			}
+		}
+		threads, _ := filepath.Glob(filepath.Join(dir, "task", "*", "children"))
+		for _, path := range threads {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			for _, field := range strings.Fields(string(data)) {
+				if child, err := strconv.Atoi(field); err == nil {
+					queue = append(queue, child)
+				}
+			}
+		}
+	}
+	return pages * uint64(os.Getpagesize())
+}
+
+// recordOOMBaseline keeps the OOM kill counter at the start of a task, so a kill while it runs can
+// be told apart from earlier ones.
+func recordOOMBaseline(taskDir string) {
+	count, err := cgroupOOMKills()
+	if err != nil {
+		klog.V(1).InfoS("OOM kills cannot be detected", "err", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(taskDir, OOMBaselineFile), []byte(strconv.FormatUint(count, 10)), 0644); err != nil {
+		klog.ErrorS(err, "failed to write OOM baseline", "taskDir", taskDir)
+	}
+}
+
+// oomKilled reports whether a task that was killed with SIGKILL or vanished was killed by the OOM
+// killer, which is assumed when the cgroup counted an OOM kill while the task ran. The counter covers
+// the whole cgroup, so a kill of another task at the same time is attributed to both. The verdict is
+// kept in the task directory, so it does not change on later inspections or after a restart. lastRSS
+// is the resident memory of the task when it was last seen running, 0 if unknown.
+func oomKilled(taskDir string, lastRSS uint64) (string, bool) {
+	killedPath := filepath.Join(taskDir, OOMKilledFile)
+	if message, err := os.ReadFile(killedPath); err == nil {
+		return string(message), true
+	}
+	baselinePath := filepath.Join(taskDir, OOMBaselineFile)
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		return "", false
+	}
+	baseline, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return "", false
+	}
+	count, err := cgroupOOMKills()
+	if err != nil {
+		// The baseline is kept, so a later inspection can still compare the counter.
+		return "", false
+	}
+	// Only the first inspection that reads the counter after the exit compares it.
+	defer os.Remove(baselinePath)
+	if count <= baseline {
+		return "", false
+	}
+
+	message := "Task was killed by the OOM killer"
+	if lastRSS > 0 {
+		message += fmt.Sprintf(", RSS was %dMi when last checked", lastRSS>>20)
+	}
+	if err := os.WriteFile(killedPath, []byte(message), 0644); err != nil {
+		klog.ErrorS(err, "failed to record OOM kill", "taskDir", taskDir)
+	}
+	return message, true
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// fakeCgroup points the OOM detection at a fake cgroup v2 hierarchy and returns its memory.events.
+func fakeCgroup(t *testing.T) string {
+	oldProc, oldCgroup := procRoot, cgroupRoot
+	t.Cleanup(func() { procRoot, cgroupRoot = oldProc, oldCgroup })
+	procRoot, cgroupRoot = t.TempDir(), t.TempDir()
+	writeTestFile(t, filepath.Join(procRoot, "self", "cgroup"), "0::/pod/executor\n")
+	events := filepath.Join(cgroupRoot, "pod", "executor", "memory.events")
+	writeTestFile(t, events, "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n")
+	return events
+}
+
+func TestCgroupOOMKills(t *testing.T) {
+	fakeCgroup(t)
+	count, err := cgroupOOMKills()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	// On hybrid hosts the v1 memory controller wins.
+	writeTestFile(t, filepath.Join(procRoot, "self", "cgroup"), "12:cpu,cpuacct:/pod\n4:memory:/pod/executor\n0::/pod/executor\n")
+	writeTestFile(t, filepath.Join(cgroupRoot, "memory", "pod", "executor", "memory.oom_control"), "oom_kill_disable 0\nunder_oom 0\noom_kill 7\n")
+	count, err = cgroupOOMKills()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), count)
+}
+
+func TestProcessTreeRSS(t *testing.T) {
+	oldProc := procRoot
+	t.Cleanup(func() { procRoot = oldProc })
+	procRoot = t.TempDir()
+	statm := func(rss string) string { return "4000 " + rss + " 100 10 0 300 0\n" }
+	writeTestFile(t, filepath.Join(procRoot, "100", "statm"), statm("10"))
+	writeTestFile(t, filepath.Join(procRoot, "100", "task", "100", "children"), "101 ")
+	writeTestFile(t, filepath.Join(procRoot, "100", "task", "102", "children"), "103 ")
+	writeTestFile(t, filepath.Join(procRoot, "101", "statm"), statm("5"))
+	writeTestFile(t, filepath.Join(procRoot, "101", "task", "101", "children"), "")
+	writeTestFile(t, filepath.Join(procRoot, "103", "statm"), statm("2"))
+	// Processes outside the tree are not read.
+	writeTestFile(t, filepath.Join(procRoot, "200", "statm"), statm("1000"))
+
+	assert.Equal(t, uint64(17*os.Getpagesize()), processTreeRSS(100))
+	// A process that exited counts nothing.
+	assert.Equal(t, uint64(0), processTreeRSS(300))
+}
+
+func TestOOMKilled_KeepsBaselineWhenCounterUnreadable(t *testing.T) {
+	events := fakeCgroup(t)
+	taskDir := t.TempDir()
+	recordOOMBaseline(taskDir)
+	require.NoError(t, os.Remove(events))
+
+	_, ok := oomKilled(taskDir, 0)
+	assert.False(t, ok)
+	assert.FileExists(t, filepath.Join(taskDir, OOMBaselineFile), "the baseline is kept until the counter can be read")
+
+	writeTestFile(t, events, "oom_kill 2\n")
+	_, ok = oomKilled(taskDir, 0)
+	assert.True(t, ok)
+	assert.NoFileExists(t, filepath.Join(taskDir, OOMBaselineFile))
+}
+
+func TestProcessExecutor_OOMKilled(t *testing.T) {
+	events := fakeCgroup(t)
+	executor, dataDir := setupTestExecutor(t)
+	pExecutor := executor.(*processExecutor)
+	ctx := context.Background()
+
+	taskDir := filepath.Join(dataDir, "oom")
+	require.NoError(t, os.MkdirAll(taskDir, 0755))
+	recordOOMBaseline(taskDir)
+	writeTestFile(t, events, "oom 2\noom_kill 2\n")
+	writeTestFile(t, filepath.Join(taskDir, PidFile), "1")
+	writeTestFile(t, filepath.Join(taskDir, ExitFile), "137")
+	task := &types.Task{Name: "oom"}
+	pExecutor.rss.Store(task.Name, uint64(512<<20))
+
+	status, err := executor.Inspect(ctx, task)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateFailed, status.State)
+	assert.Equal(t, ReasonOOMKilled, status.SubStatuses[0].Reason)
+	assert.Contains(t, status.SubStatuses[0].Message, "512Mi")
+
+	// The verdict stays the same after later kills of other tasks.
+	writeTestFile(t, events, "oom_kill 3\n")
+	status, err = executor.Inspect(ctx, task)
+	require.NoError(t, err)
+	assert.Equal(t, ReasonOOMKilled, status.SubStatuses[0].Reason)
+
+	// A task killed with SIGKILL without an OOM kill in the cgroup just failed.
+	otherDir := filepath.Join(dataDir, "killed")
+	require.NoError(t, os.MkdirAll(otherDir, 0755))
+	recordOOMBaseline(otherDir)
+	writeTestFile(t, filepath.Join(otherDir, ExitFile), "137")
+	status, err = executor.Inspect(ctx, &types.Task{Name: "killed"})
+	require.NoError(t, err)
+	assert.Equal(t, "Failed", status.SubStatuses[0].Reason)
+}
@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -37,6 +38,11 @@ const (
 	PidFile    = "pid"
 	StdoutFile = "stdout.log"
 	StderrFile = "stderr.log"
+	// ImagePullErrorFile holds why the image of a task could not be pulled.
+	ImagePullErrorFile = "image-pull-error"
+
+	ReasonPullingImage    = "PullingImage"
+	ReasonImagePullFailed = "ImagePullFailed"
 )
 
 // processExecutor handles both Host and Sidecar modes as they share the same
@@ -44,10 +50,22 @@ const (
 type processExecutor struct {
 	config  *config.Config
 	rootDir string
+	// rss holds the resident memory of each running task when it was last inspected.
+	rss    sync.Map
+	images *imagePuller
+	// pulls holds the *imagePull of each task whose image is being pulled.
+	pulls sync.Map
+}
+
+// imagePull is the pull of the image of a task, canceled when the task is stopped.
+type imagePull struct {
+	cancel context.CancelFunc
 }
 
 func NewProcessExecutor(config *config.Config) (Executor, error) {
-	return &processExecutor{rootDir: config.DataDir, config: config}, nil
+	e := &processExecutor{rootDir: config.DataDir, config: config}
+	e.images = newImagePuller(config.MaxCachedImages, e.taskDone)
+	return e, nil
 }
 
 func (e *processExecutor) Start(ctx context.Context, task *types.Task) error {
@@ -72,7 +90,28 @@ func (e *processExecutor) Start(ctx context.Context, task *types.Task) error {
 		return fmt.Errorf("no command specified in process spec (task name: %s)", task.Name)
 	}
 
-	safeCmdStr := shellEscape(cmdList)
+	_ = os.Remove(filepath.Join(taskDir, OOMKilledFile))
+	_ = os.Remove(filepath.Join(taskDir, FirstOutputFile))
+	recordOOMBaseline(taskDir)
+
+	if task.Process.Image != "" {
+		e.startImageTask(task, cmdList, pidPath, exitPath)
+		return nil
+	}
+	cmd, err := e.newShimCommand(task, cmdList, exitPath)
+	if err != nil {
+		return err
+	}
+	return e.executeCommand(task, cmd, pidPath)
+}
+
+// newShimCommand returns the command that runs cmdList through the shim, in the namespaces of the
+// main container in sidecar mode.
+func (e *processExecutor) newShimCommand(task *types.Task, cmdList []string, exitPath string) (*exec.Cmd, error) {
+	safeCmdStr, err := tuneCommand(task.Process, shellEscape(cmdList))
+	if err != nil {
+		return nil, err
+	}
 	shimScript := e.buildShimScript(exitPath, safeCmdStr)
 
 	var cmd *exec.Cmd
@@ -80,12 +119,12 @@ func (e *processExecutor) Start(ctx context.Context, task *types.Task) error {
 	if e.config.EnableSidecarMode {
 		targetPID, err := e.findPidByEnvVar("SANDBOX_MAIN_CONTAINER", e.config.MainContainerName)
 		if err != nil {
-			return fmt.Errorf("failed to resolve target PID: %w", err)
+			return nil, fmt.Errorf("failed to resolve target PID: %w", err)
 		}
 
 		targetEnv, err := getProcEnviron(targetPID)
 		if err != nil {
-			return fmt.Errorf("failed to read target process environment: %w", err)
+			return nil, fmt.Errorf("failed to read target process environment: %w", err)
 		}
 
 		nsenterArgs := []string{
@@ -101,17 +140,100 @@ func (e *processExecutor) Start(ctx context.Context, task *types.Task) error {
 	} else {
 		cmd = exec.Command("/bin/sh", "-c", shimScript)
 		cmd.Env = os.Environ()
-		klog.InfoS("Starting host task", "name", task.Name, "cmd", safeCmdStr, "exitPath", exitPath)
+		klog.InfoS("Starting host task", "task", task.Name, "cmd", safeCmdStr, "exitPath", exitPath)
 	}
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setpgid: true,
 		Pgid:    0,
 	}
+	return cmd, nil
+}
+
+// startImageTask pulls the image of the task in the background and starts the command chrooted into
+// its root filesystem once it is unpacked, so the task stays Pending while the image is pulled. In
+// sidecar mode the image is unpacked into the main container through /proc, so the command runs in
+// its mount namespace. A pull that fails, or that is canceled by Stop, fails the task.
+func (e *processExecutor) startImageTask(task *types.Task, cmdList []string, pidPath, exitPath string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pull := &imagePull{cancel: cancel}
+	e.pulls.Store(task.Name, pull)
+	task = task.DeepCopy()
+	klog.InfoS("Pulling task image", "task", task.Name, "image", task.Process.Image)
+	go func() {
+		defer e.pulls.CompareAndDelete(task.Name, pull)
+		defer cancel()
+		err := e.runImageTask(ctx, task, cmdList, pidPath, exitPath)
+		if err == nil {
+			return
+		}
+		klog.ErrorS(err, "failed to start image task", "task", task.Name, "image", task.Process.Image)
+		errPath := filepath.Join(filepath.Dir(pidPath), ImagePullErrorFile)
+		if writeErr := os.WriteFile(errPath, []byte(err.Error()), 0644); writeErr != nil {
+			klog.ErrorS(writeErr, "failed to record image pull error", "task", task.Name)
+		}
+	}()
+}
 
+func (e *processExecutor) runImageTask(ctx context.Context, task *types.Task, cmdList []string, pidPath, exitPath string) error {
+	imageDir := e.config.ImageDir
+	pullDir := imageDir
+	if e.config.EnableSidecarMode {
+		targetPID, err := e.findPidByEnvVar("SANDBOX_MAIN_CONTAINER", e.config.MainContainerName)
+		if err != nil {
+			return fmt.Errorf("failed to resolve target PID: %w", err)
+		}
+		pullDir = filepath.Join("/proc", strconv.Itoa(targetPID), "root", imageDir)
+	}
+	image, err := e.images.Pull(ctx, task.Name, task.Process.Image, pullDir)
+	if err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", task.Process.Image, err)
+	}
+	workingDir := task.Process.WorkingDir
+	if workingDir == "" {
+		workingDir = image.WorkingDir
+	}
+	rootfs := filepath.Join(imageDir, image.Name, imageRootfsDir)
+	cmd, err := e.newShimCommand(task, chrootCommand(rootfs, workingDir, cmdList), exitPath)
+	if err != nil {
+		return err
+	}
+	// The environment of the image comes before the one of the task, which executeCommand adds.
+	cmd.Env = append(cmd.Env, image.Env...)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("task stopped while its image was pulled")
+	}
 	return e.executeCommand(task, cmd, pidPath)
 }
 
+// taskDone reports whether the task no longer runs in the root filesystem of its image: it was
+// deleted, exited, or failed to start.
+func (e *processExecutor) taskDone(name string) bool {
+	taskDir, err := utils.SafeJoin(e.rootDir, name)
+	if err != nil {
+		return true
+	}
+	if _, err := os.Stat(taskDir); err != nil {
+		return true
+	}
+	for _, file := range []string{ExitFile, ImagePullErrorFile} {
+		if _, err := os.Stat(filepath.Join(taskDir, file)); err == nil {
+			return true
+		}
+	}
+	pid, err := e.readPID(&types.Task{Name: name})
+	return err == nil && pid > 0 && !isProcessRunning(pid)
+}
+
+// chrootCommand runs cmdList with rootfs as its root directory. chroot starts the command in the new
+// root, so a working directory is changed to by the shell of the image.
+func chrootCommand(rootfs, workingDir string, cmdList []string) []string {
+	if workingDir == "" || workingDir == "/" {
+		return append([]string{"chroot", rootfs}, cmdList...)
+	}
+	return append([]string{"chroot", rootfs, "/bin/sh", "-c", `cd "$0" && exec "$@"`, workingDir}, cmdList...)
+}
+
 // executeCommand handles log setup and process starting
 func (e *processExecutor) executeCommand(task *types.Task, cmd *exec.Cmd, pidPath string) error {
 	if task == nil || cmd == nil {
@@ -147,14 +269,15 @@ func (e *processExecutor) executeCommand(task *types.Task, cmd *exec.Cmd, pidPat
 			}
 		}
 
-		if task.Process.WorkingDir != "" {
+		// The working directory of an image task is inside the image.
+		if task.Process.WorkingDir != "" && task.Process.Image == "" {
 			cmd.Dir = task.Process.WorkingDir
-			klog.InfoS("Set working directory", "name", task.Name, "workingDir", task.Process.WorkingDir)
+			klog.InfoS("Set working directory", "task", task.Name, "workingDir", task.Process.WorkingDir)
 		}
 	}
 
-	if err := cmd.Start(); err != nil {
-		klog.ErrorS(err, "failed to start command", "name", task.Name)
+	if err := startCommand(cmd, task.Process); err != nil {
+		klog.ErrorS(err, "failed to start command", "task", task.Name)
 		stdoutFile.Close()
 		stderrFile.Close()
 		return fmt.Errorf("failed to start cmd: %w", err)
@@ -164,25 +287,28 @@ func (e *processExecutor) executeCommand(task *types.Task, cmd *exec.Cmd, pidPat
 	// This fixes the issue where sidecar tasks would write the container-internal PID
 	pid := cmd.Process.Pid
 	if err := os.WriteFile(pidPath, []byte(strconv.Itoa(pid)), 0644); err != nil {
-		klog.ErrorS(err, "failed to write pid file", "name", task.Name)
+		klog.ErrorS(err, "failed to write pid file", "task", task.Name)
 		_ = cmd.Process.Kill()
 		stdoutFile.Close()
 		stderrFile.Close()
 		return fmt.Errorf("failed to write pid file: %w", err)
 	}
 
-	klog.InfoS("Task command started successfully", "name", task.Name, "pid", pid)
+	klog.InfoS("Task command started successfully", "task", task.Name, "pid", pid)
 
 	stdoutFile.Close()
 	stderrFile.Close()
 
+	exited := make(chan struct{})
 	go func() {
+		defer close(exited)
 		if err := cmd.Wait(); err != nil {
-			klog.ErrorS(err, "task process exited with error", "name", task.Name)
+			klog.ErrorS(err, "task process exited with error", "task", task.Name)
 		} else {
-			klog.InfoS("task process exited successfully", "name", task.Name)
+			klog.InfoS("task process exited successfully", "task", task.Name)
 		}
 	}()
+	go watchFirstOutput(taskDir, exited)
 	return nil
 }
 
@@ -239,7 +365,12 @@ func (e *processExecutor) Inspect(ctx context.Context, task *types.Task) (*types
 		} else {
 			status.State = types.TaskStateFailed
 			subStatus.Reason = "Failed"
+			if exitCode == sigkillExitCode {
+				e.attributeOOMKill(task, taskDir, &subStatus)
+			}
 		}
+		captureOutput(task, taskDir, &subStatus)
+		subStatus.FirstOutputAt = firstOutput(taskDir)
 
 		if pidFileInfo, err := os.Stat(pidPath); err == nil {
 			startedAt := pidFileInfo.ModTime()
@@ -255,9 +386,11 @@ func (e *processExecutor) Inspect(ctx context.Context, task *types.Task) (*types
 		fileInfo, _ := os.Stat(pidPath)
 		startedAt := fileInfo.ModTime()
 		subStatus.StartedAt = &startedAt
+		subStatus.FirstOutputAt = firstOutput(taskDir)
 
 		if isProcessRunning(pid) {
 			status.State = types.TaskStateRunning
+			e.rss.Store(task.Name, processTreeRSS(pid))
 			if task.Process != nil && task.Process.TimeoutSeconds != nil {
 				timeout := time.Duration(*task.Process.TimeoutSeconds) * time.Second
 				elapsed := time.Since(startedAt)
@@ -265,6 +398,7 @@ func (e *processExecutor) Inspect(ctx context.Context, task *types.Task) (*types
 					status.State = types.TaskStateTimeout
 					subStatus.Reason = "TaskTimeout"
 					subStatus.Message = fmt.Sprintf("Task exceeded timeout of %d seconds", *task.Process.TimeoutSeconds)
+					captureOutput(task, taskDir, &subStatus)
 				}
 			}
 		} else {
@@ -273,6 +407,23 @@ func (e *processExecutor) Inspect(ctx context.Context, task *types.Task) (*types
 			subStatus.Reason = "ProcessCrashed"
 			subStatus.Message = "Process exited without writing exit code"
 			subStatus.FinishedAt = &startedAt
+			e.attributeOOMKill(task, taskDir, &subStatus)
+			captureOutput(task, taskDir, &subStatus)
+		}
+		status.SubStatuses = []types.SubStatus{subStatus}
+		return status, nil
+	}
+
+	errPath := filepath.Join(taskDir, ImagePullErrorFile)
+	if message, err := os.ReadFile(errPath); err == nil {
+		status.State = types.TaskStateFailed
+		// The command never ran; a non-zero exit code reports the task as terminated.
+		subStatus.ExitCode = 1
+		subStatus.Reason = ReasonImagePullFailed
+		subStatus.Message = string(message)
+		if info, err := os.Stat(errPath); err == nil {
+			finishedAt := info.ModTime()
+			subStatus.FinishedAt = &finishedAt
 		}
 		status.SubStatuses = []types.SubStatus{subStatus}
 		return status, nil
@@ -280,29 +431,57 @@ func (e *processExecutor) Inspect(ctx context.Context, task *types.Task) (*types
 
 	status.State = types.TaskStatePending
 	subStatus.Reason = "Pending"
+	if task.Process != nil && task.Process.Image != "" {
+		subStatus.Reason = ReasonPullingImage
+	}
 	status.SubStatuses = []types.SubStatus{subStatus}
 
 	return status, nil
 }
 
-func (e *processExecutor) Stop(ctx context.Context, task *types.Task) error {
+// attributeOOMKill reports a task killed by the OOM killer as OOMKilled instead of a plain failure,
+// so a task that needs more memory can be told apart from one that crashed.
+func (e *processExecutor) attributeOOMKill(task *types.Task, taskDir string, subStatus *types.SubStatus) {
+	var lastRSS uint64
+	if rss, ok := e.rss.LoadAndDelete(task.Name); ok {
+		lastRSS = rss.(uint64)
+	}
+	if message, ok := oomKilled(taskDir, lastRSS); ok {
+		subStatus.Reason = ReasonOOMKilled
+		subStatus.Message = message
+	}
+}
+
+// readPID returns the PID written by Start, or 0 when the task was never started.
+func (e *processExecutor) readPID(task *types.Task) (int, error) {
 	taskDir, err := utils.SafeJoin(e.rootDir, task.Name)
 	if err != nil {
-		return fmt.Errorf("invalid task name: %w", err)
+		return 0, fmt.Errorf("invalid task name: %w", err)
 	}
-	pidPath := filepath.Join(taskDir, PidFile)
-	pidData, err := os.ReadFile(pidPath)
+	pidData, err := os.ReadFile(filepath.Join(taskDir, PidFile))
 	if err != nil {
-		return nil
+		return 0, nil
 	}
-	var pid int
-	pid, err = strconv.Atoi(strings.TrimSpace(string(pidData)))
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidData)))
+	if err != nil {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+func (e *processExecutor) Stop(ctx context.Context, task *types.Task) error {
+	if pull, ok := e.pulls.Load(task.Name); ok {
+		pull.(*imagePull).cancel()
+	}
+	pid, err := e.readPID(task)
 	if err != nil || pid == 0 {
-		return nil
+		return err
 	}
-	klog.InfoS("Read PID from pid file", "name", task.Name, "pid", pid)
+	klog.InfoS("Read PID from pid file", "task", task.Name, "pid", pid)
 
 	pgid := -pid
+	// A frozen task cannot handle SIGTERM, so continue it first.
+	_ = syscall.Kill(pgid, syscall.SIGCONT)
 
 	targetPID := 0
 	if e.config.EnableSidecarMode {
@@ -348,6 +527,33 @@ func (e *processExecutor) Stop(ctx context.Context, task *types.Task) error {
 	return nil
 }
 
+// Freeze sends SIGSTOP to the process group of the task. The shim runs the command in the
+// background without job control, so the group holds the shim, the command and its children; in
+// sidecar mode nsenter forks the shim into the same group.
+func (e *processExecutor) Freeze(ctx context.Context, task *types.Task) error {
+	return e.signalGroup(task, syscall.SIGSTOP)
+}
+
+// Thaw sends SIGCONT to the process group of the task.
+func (e *processExecutor) Thaw(ctx context.Context, task *types.Task) error {
+	return e.signalGroup(task, syscall.SIGCONT)
+}
+
+func (e *processExecutor) signalGroup(task *types.Task, sig syscall.Signal) error {
+	if task == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	pid, err := e.readPID(task)
+	if err != nil || pid == 0 {
+		return err
+	}
+	if err := syscall.Kill(-pid, sig); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("failed to send %s to task %s: %w", sig, task.Name, err)
+	}
+	klog.InfoS("Signaled task process group", "task", task.Name, "pgid", pid, "signal", sig)
+	return nil
+}
+
 // getChildrenPIDs reads /proc/<pid>/task/<pid>/children to find direct children
 func getChildrenPIDs(pid int) ([]int, error) {
 	path := fmt.Sprintf("/proc/%d/task/%d/children", pid, pid)
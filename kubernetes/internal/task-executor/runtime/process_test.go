@@ -19,6 +19,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -140,6 +142,53 @@ func TestProcessExecutor_ShortLived(t *testing.T) {
 	if status.SubStatuses[0].ExitCode != 0 {
 		t.Errorf("Exit code should be 0, got %d", status.SubStatuses[0].ExitCode)
 	}
+	assert.Empty(t, status.SubStatuses[0].Stdout, "output is only captured on request")
+}
+
+func TestProcessExecutor_CaptureOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	executor, _ := setupTestExecutor(t)
+	pExecutor := executor.(*processExecutor)
+	ctx := context.Background()
+
+	task := &types.Task{
+		Name: "capture-output",
+		Process: &api.Process{
+			Command:            []string{"sh", "-c", "echo hello world; echo oops >&2; exit 3"},
+			CaptureOutputBytes: 6,
+		},
+	}
+	taskDir, err := utils.SafeJoin(pExecutor.rootDir, task.Name)
+	assert.Nil(t, err)
+	os.MkdirAll(taskDir, 0755)
+
+	if err := executor.Start(ctx, task); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	status, err := executor.Inspect(ctx, task)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	assert.Equal(t, types.TaskStateFailed, status.State)
+	assert.Equal(t, "world\n", status.SubStatuses[0].Stdout)
+	assert.Equal(t, "oops\n", status.SubStatuses[0].Stderr)
+	if assert.NotNil(t, status.SubStatuses[0].FirstOutputAt) && assert.NotNil(t, status.SubStatuses[0].StartedAt) {
+		assert.False(t, status.SubStatuses[0].FirstOutputAt.Before(status.SubStatuses[0].StartedAt.Truncate(time.Second)))
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	assert.NoError(t, os.WriteFile(path, []byte("ab\u00e9cd"), 0644))
+	assert.Equal(t, "ab\u00e9cd", tailFile(path, 100))
+	assert.Equal(t, "cd", tailFile(path, 3), "a rune cut at the start is dropped")
+	assert.Equal(t, "\u00e9cd", tailFile(path, 4))
+	assert.Equal(t, "", tailFile(filepath.Join(t.TempDir(), "missing"), 10))
 }
 
 func TestProcessExecutor_Failure(t *testing.T) {
@@ -413,3 +462,55 @@ func TestProcessExecutor_NoTimeout(t *testing.T) {
 	// Cleanup
 	executor.Stop(ctx, task)
 }
+
+// procState returns the state letter of the process from /proc/<pid>/stat.
+func procState(t *testing.T, pid int) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		t.Fatalf("failed to read stat of %d: %v", pid, err)
+	}
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	return fields[0]
+}
+
+func TestProcessExecutor_FreezeThaw(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("/proc not available")
+	}
+	executor, _ := setupTestExecutor(t)
+	pExecutor := executor.(*processExecutor)
+	freezer := executor.(Freezer)
+	ctx := context.Background()
+
+	task := &types.Task{
+		Name:    "freezable",
+		Process: &api.Process{Command: []string{"sleep", "10"}},
+	}
+	taskDir, err := utils.SafeJoin(pExecutor.rootDir, task.Name)
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(taskDir, 0755))
+
+	// Freezing a task that never started is a no-op.
+	assert.NoError(t, freezer.Freeze(ctx, task))
+
+	assert.NoError(t, executor.Start(ctx, task))
+	time.Sleep(100 * time.Millisecond)
+	pid, err := pExecutor.readPID(task)
+	assert.NoError(t, err)
+
+	assert.NoError(t, freezer.Freeze(ctx, task))
+	assert.Eventually(t, func() bool { return procState(t, pid) == "T" }, time.Second, 20*time.Millisecond)
+	status, err := executor.Inspect(ctx, task)
+	assert.NoError(t, err)
+	assert.Equal(t, types.TaskStateRunning, status.State, "a frozen task is still running")
+
+	assert.NoError(t, freezer.Thaw(ctx, task))
+	assert.Eventually(t, func() bool { return procState(t, pid) != "T" }, time.Second, 20*time.Millisecond)
+
+	// A frozen task still stops promptly.
+	assert.NoError(t, freezer.Freeze(ctx, task))
+	start := time.Now()
+	assert.NoError(t, executor.Stop(ctx, task))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// FirstOutputFile holds when the task first wrote to stdout or stderr, in RFC 3339 format.
+const FirstOutputFile = "first_output"
+
+var (
+	// firstOutputPollMin and firstOutputPollMax bound how often a started task is checked for output:
+	// often at first, when output is expected, and less so the longer the task stays silent.
+	firstOutputPollMin = 5 * time.Millisecond
+	firstOutputPollMax = time.Second
+)
+
+// hasOutput reports whether the task has written to stdout or stderr.
+func hasOutput(taskDir string) bool {
+	for _, name := range []string{StdoutFile, StderrFile} {
+		if info, err := os.Stat(filepath.Join(taskDir, name)); err == nil && info.Size() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// watchFirstOutput records when a started task first writes output. It gives up once exited is closed,
+// recording output written until then.
+func watchFirstOutput(taskDir string, exited <-chan struct{}) {
+	interval := firstOutputPollMin
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-exited:
+			firstOutput(taskDir)
+			return
+		case <-timer.C:
+		}
+		if hasOutput(taskDir) {
+			recordFirstOutput(taskDir, time.Now())
+			return
+		}
+		interval = min(interval*2, firstOutputPollMax)
+		timer.Reset(interval)
+	}
+}
+
+// firstOutput returns when the task first wrote output, nil if it has not. Output no watcher has seen,
+// of a task that outlived a restart of the executor, is recorded when it is found.
+func firstOutput(taskDir string) *time.Time {
+	if at, ok := readFirstOutput(taskDir); ok {
+		return &at
+	}
+	if !hasOutput(taskDir) {
+		return nil
+	}
+	at := recordFirstOutput(taskDir, time.Now())
+	return &at
+}
+
+func readFirstOutput(taskDir string) (time.Time, bool) {
+	data, err := os.ReadFile(filepath.Join(taskDir, FirstOutputFile))
+	if err != nil {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	return at, err == nil
+}
+
+// recordFirstOutput keeps at as the time of the first output unless one is recorded already, and returns
+// the recorded time. The file is linked into place, so readers never see it half written.
+func recordFirstOutput(taskDir string, at time.Time) time.Time {
+	tmp, err := os.CreateTemp(taskDir, FirstOutputFile+".*")
+	if err != nil {
+		klog.ErrorS(err, "failed to record first output", "taskDir", taskDir)
+		return at
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(at.Format(time.RFC3339Nano))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Link(tmp.Name(), filepath.Join(taskDir, FirstOutputFile))
+	}
+	if err != nil {
+		if recorded, ok := readFirstOutput(taskDir); ok {
+			return recorded
+		}
+		klog.ErrorS(err, "failed to record first output", "taskDir", taskDir)
+	}
+	return at
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstOutput(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, firstOutput(dir), "no output yet")
+
+	writeTestFile(t, filepath.Join(dir, StderrFile), "oops")
+	at := firstOutput(dir)
+	require.NotNil(t, at, "output found without a watcher is recorded")
+	again := firstOutput(dir)
+	require.NotNil(t, again)
+	assert.True(t, at.Equal(*again), "the recorded time is kept")
+}
+
+func TestRecordFirstOutput_KeepsFirst(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	assert.True(t, first.Equal(recordFirstOutput(dir, first)))
+	assert.True(t, first.Equal(recordFirstOutput(dir, first.Add(time.Second))), "a later time does not replace the first")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files are removed")
+	assert.Equal(t, FirstOutputFile, entries[0].Name())
+}
+
+func TestWatchFirstOutput(t *testing.T) {
+	dir := t.TempDir()
+	exited := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		watchFirstOutput(dir, exited)
+		close(done)
+	}()
+
+	before := time.Now()
+	writeTestFile(t, filepath.Join(dir, StdoutFile), "hello")
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not see the output")
+	}
+	at, ok := readFirstOutput(dir)
+	require.True(t, ok)
+	assert.False(t, at.Before(before))
+}
+
+func TestWatchFirstOutput_Exited(t *testing.T) {
+	dir := t.TempDir()
+	exited := make(chan struct{})
+	close(exited)
+	watchFirstOutput(dir, exited)
+	_, ok := readFirstOutput(dir)
+	assert.False(t, ok, "a task that exited silently has no first output")
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+var umaskPattern = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+// tuneCommand prefixes the shell command of the process with its umask and open files limit. The shell
+// running the command applies them, in the main container as well in sidecar mode, and a limit it may not
+// set fails the task with the reason in its stderr.
+func tuneCommand(process *api.Process, cmdStr string) (string, error) {
+	var prelude []string
+	if process.Umask != "" {
+		if !umaskPattern.MatchString(process.Umask) {
+			return "", fmt.Errorf("invalid umask %q", process.Umask)
+		}
+		prelude = append(prelude, "umask "+process.Umask)
+	}
+	if n := process.MaxOpenFiles; n != nil {
+		if *n < 1 {
+			return "", fmt.Errorf("invalid maxOpenFiles %d", *n)
+		}
+		prelude = append(prelude, fmt.Sprintf("ulimit -n %d", *n))
+	}
+	if len(prelude) == 0 {
+		return cmdStr, nil
+	}
+	return fmt.Sprintf("(%s && exec %s)", strings.Join(prelude, " && "), cmdStr), nil
+}
+
+// ioPriority returns the ioprio_set(2) value of the I/O class, at the default level within the class.
+func ioPriority(class string) (int, error) {
+	const classShift = 13
+	switch class {
+	case api.IOClassRealTime:
+		return 1<<classShift | 4, nil
+	case api.IOClassBestEffort:
+		return 2<<classShift | 4, nil
+	case api.IOClassIdle:
+		return 3 << classShift, nil
+	}
+	return 0, fmt.Errorf("invalid I/O class %q", class)
+}
+
+// startCommand starts cmd with the niceness and I/O class of the process.
+func startCommand(cmd *exec.Cmd, process *api.Process) error {
+	if process == nil || (process.Nice == nil && process.IOClass == "") {
+		return cmd.Start()
+	}
+	if process.Nice != nil && (*process.Nice < -20 || *process.Nice > 19) {
+		return fmt.Errorf("invalid nice %d", *process.Nice)
+	}
+	return startWithPriority(cmd, process)
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+	"syscall"
+
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+// startWithPriority starts cmd from a thread of its own set to the niceness and I/O class of the process,
+// which the forked command inherits. The thread stays locked, so it exits with its goroutine rather than
+// running other goroutines with those priorities.
+func startWithPriority(cmd *exec.Cmd, process *api.Process) error {
+	errc := make(chan error, 1)
+	go func() {
+		goruntime.LockOSThread()
+		tid := syscall.Gettid()
+		if process.Nice != nil {
+			if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, int(*process.Nice)); err != nil {
+				errc <- fmt.Errorf("failed to set nice %d: %w", *process.Nice, err)
+				return
+			}
+		}
+		if process.IOClass != "" {
+			prio, err := ioPriority(process.IOClass)
+			if err != nil {
+				errc <- err
+				return
+			}
+			const whoProcess = 1 // IOPRIO_WHO_PROCESS, which takes a thread ID
+			if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, whoProcess, uintptr(tid), uintptr(prio)); errno != 0 {
+				errc <- fmt.Errorf("failed to set I/O class %s: %w", process.IOClass, errno)
+				return
+			}
+		}
+		errc <- cmd.Start()
+	}()
+	return <-errc
+}
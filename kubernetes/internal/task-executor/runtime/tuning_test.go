@@ -0,0 +1,91 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/utils"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+func TestTuneCommand(t *testing.T) {
+	cmd, err := tuneCommand(&api.Process{}, "'sleep' '1'")
+	require.NoError(t, err)
+	assert.Equal(t, "'sleep' '1'", cmd)
+
+	cmd, err = tuneCommand(&api.Process{Umask: "027", MaxOpenFiles: ptr.To[int64](4096)}, "'sleep' '1'")
+	require.NoError(t, err)
+	assert.Equal(t, "(umask 027 && ulimit -n 4096 && exec 'sleep' '1')", cmd)
+
+	_, err = tuneCommand(&api.Process{Umask: "0;rm"}, "'sleep' '1'")
+	assert.Error(t, err)
+	_, err = tuneCommand(&api.Process{MaxOpenFiles: ptr.To[int64](0)}, "'sleep' '1'")
+	assert.Error(t, err)
+}
+
+func TestIOPriority(t *testing.T) {
+	prio, err := ioPriority(api.IOClassBestEffort)
+	require.NoError(t, err)
+	assert.Equal(t, 2<<13|4, prio)
+	prio, err = ioPriority(api.IOClassIdle)
+	require.NoError(t, err)
+	assert.Equal(t, 3<<13, prio)
+	_, err = ioPriority("Urgent")
+	assert.Error(t, err)
+}
+
+func TestProcessExecutor_Tuning(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	executor, _ := setupTestExecutor(t)
+	pExecutor := executor.(*processExecutor)
+	ctx := context.Background()
+
+	task := &types.Task{
+		Name: "tuned",
+		Process: &api.Process{
+			// Field 19 of /proc/self/stat is the niceness.
+			Command:            []string{"sh", "-c", "umask; ulimit -n; cut -d ' ' -f 19 /proc/self/stat"},
+			Umask:              "0027",
+			MaxOpenFiles:       ptr.To[int64](512),
+			Nice:               ptr.To[int32](7),
+			IOClass:            api.IOClassIdle,
+			CaptureOutputBytes: 100,
+		},
+	}
+	taskDir, err := utils.SafeJoin(pExecutor.rootDir, task.Name)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(taskDir, 0755))
+
+	require.NoError(t, executor.Start(ctx, task))
+	time.Sleep(200 * time.Millisecond)
+
+	status, err := executor.Inspect(ctx, task)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStateSucceeded, status.State, status.SubStatuses[0].Stderr)
+	assert.Equal(t, "0027\n512\n7\n", status.SubStatuses[0].Stdout)
+}
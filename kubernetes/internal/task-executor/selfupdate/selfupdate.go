@@ -0,0 +1,253 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selfupdate replaces the running task-executor binary in place.
+//
+// A new binary is described by a manifest carrying its version, digest and
+// expiry. The manifest is checked against a detached ed25519 signature, the
+// binary is downloaded into the data directory, checked against the digest
+// and then exec'd over the current process. Only versions newer than the
+// running one are accepted, so an old signed build can't be replayed. The
+// PID does not change, so task processes stay our children, the file store
+// is recovered by the new binary on start, and the listening socket is
+// handed over through an inherited file descriptor so no connection is
+// refused while the new binary starts.
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Version is the semantic version of the running binary, set at build time with
+// -ldflags "-X github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/selfupdate.Version=v1.2.3".
+var Version string
+
+const (
+	// EnvListenFD carries the inherited listener fd across the re-exec.
+	EnvListenFD = "TASK_EXECUTOR_LISTEN_FD"
+	// BinaryFile is the staged binary, kept as a plain file so the task store
+	// does not mistake it for a task directory.
+	BinaryFile = ".task-executor-update"
+	// MaxBinarySize bounds the download.
+	MaxBinarySize = 256 << 20
+)
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size %d, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Manifest describes a signed binary.
+type Manifest struct {
+	// Version is the semantic version of the binary.
+	Version string `json:"version"`
+	// SHA256 is the hex digest of the binary.
+	SHA256 string `json:"sha256"`
+	// ExpiresAt bounds how long the signed manifest can be used.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Updater stages signed binaries and re-execs into them.
+type Updater struct {
+	DataDir    string
+	PublicKey  ed25519.PublicKey
+	HTTPClient *http.Client
+
+	// current is the version of the running binary.
+	current *version.Version
+	now     func() time.Time
+}
+
+// NewUpdater returns an updater replacing a binary of the given semantic
+// version.
+func NewUpdater(dataDir string, publicKey ed25519.PublicKey, currentVersion string) (*Updater, error) {
+	current, err := version.ParseSemantic(currentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q of the running binary: %w", currentVersion, err)
+	}
+	return &Updater{
+		DataDir:    dataDir,
+		PublicKey:  publicKey,
+		HTTPClient: &http.Client{Timeout: 5 * time.Minute},
+		current:    current,
+		now:        time.Now,
+	}, nil
+}
+
+// Verify checks the base64 signature over the base64 manifest and returns
+// the manifest if it is unexpired and names a version newer than the running
+// one.
+func (u *Updater) Verify(manifest, signature string) (*Manifest, error) {
+	raw, err := base64.StdEncoding.DecodeString(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest encoding: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !ed25519.Verify(u.PublicKey, raw, sig) {
+		return nil, fmt.Errorf("signature verification failed")
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(raw, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if m.ExpiresAt.IsZero() || !u.now().Before(m.ExpiresAt) {
+		return nil, fmt.Errorf("manifest expired at %s", m.ExpiresAt.Format(time.RFC3339))
+	}
+	next, err := version.ParseSemantic(m.Version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest version %q: %w", m.Version, err)
+	}
+	if !u.current.LessThan(next) {
+		return nil, fmt.Errorf("version %s is not newer than the running version %s", next, u.current)
+	}
+	if _, err := hex.DecodeString(m.SHA256); err != nil || len(m.SHA256) != 2*sha256.Size {
+		return nil, fmt.Errorf("invalid manifest digest %q", m.SHA256)
+	}
+	return m, nil
+}
+
+// Fetch verifies the manifest, downloads the binary at url, checks it
+// against the digest of the manifest and stages it as an executable in the
+// data directory. It returns the staged path.
+func (u *Updater) Fetch(ctx context.Context, url, manifest, signature string) (string, error) {
+	m, err := u.Verify(manifest, signature)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download binary: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download binary: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxBinarySize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to download binary: %w", err)
+	}
+	if len(data) > MaxBinarySize {
+		return "", fmt.Errorf("binary exceeds %d bytes", MaxBinarySize)
+	}
+	if sum := sha256.Sum256(data); !strings.EqualFold(hex.EncodeToString(sum[:]), m.SHA256) {
+		return "", fmt.Errorf("binary digest does not match the manifest")
+	}
+
+	// Write next to the final path and rename, so a crash never leaves a
+	// truncated binary behind under the staged name.
+	path := filepath.Join(u.DataDir, BinaryFile)
+	tmp, err := os.CreateTemp(u.DataDir, BinaryFile+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to stage binary: %w", err)
+	}
+	if err := tmp.Chmod(0o755); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to stage binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to stage binary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to stage binary: %w", err)
+	}
+	return path, nil
+}
+
+// Listen returns the listener inherited from a previous binary if there is
+// one, otherwise it listens on addr.
+func Listen(addr string) (net.Listener, error) {
+	v := os.Getenv(EnvListenFD)
+	if v == "" {
+		return net.Listen("tcp", addr)
+	}
+	os.Unsetenv(EnvListenFD)
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", EnvListenFD, v, err)
+	}
+	f := os.NewFile(uintptr(fd), "listener")
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inherit listener: %w", err)
+	}
+	return l, nil
+}
+
+// InheritableFD duplicates the listener's socket into a descriptor without
+// close-on-exec, so it survives Exec and closing l.
+func InheritableFD(l net.Listener) (int, error) {
+	sc, ok := l.(syscall.Conn)
+	if !ok {
+		return -1, fmt.Errorf("listener %T does not expose its fd", l)
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return -1, err
+	}
+	fd := -1
+	var dupErr error
+	if err := raw.Control(func(s uintptr) {
+		fd, dupErr = syscall.Dup(int(s))
+	}); err != nil {
+		return -1, err
+	}
+	if dupErr != nil {
+		return -1, fmt.Errorf("failed to dup listener fd: %w", dupErr)
+	}
+	return fd, nil
+}
+
+// Exec replaces the current process with the binary at path, keeping the
+// command line and passing fd as the listener. It only returns on failure.
+func Exec(path string, fd int) error {
+	env := append(os.Environ(), EnvListenFD+"="+strconv.Itoa(fd))
+	return syscall.Exec(path, os.Args, env)
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	got, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	assert.NoError(t, err)
+	assert.Equal(t, pub, got)
+
+	_, err = ParsePublicKey("not base64!")
+	assert.Error(t, err)
+	_, err = ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
+
+func signManifest(t *testing.T, priv ed25519.PrivateKey, m Manifest) (string, string) {
+	raw, err := json.Marshal(m)
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw), base64.StdEncoding.EncodeToString(ed25519.Sign(priv, raw))
+}
+
+func TestNewUpdater(t *testing.T) {
+	_, err := NewUpdater(t.TempDir(), nil, "")
+	assert.ErrorContains(t, err, "invalid version")
+	_, err = NewUpdater(t.TempDir(), nil, "dev")
+	assert.ErrorContains(t, err, "invalid version")
+}
+
+func TestUpdater_Verify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	u, err := NewUpdater(t.TempDir(), pub, "v1.2.0")
+	assert.NoError(t, err)
+	u.now = func() time.Time { return now }
+	digest := strings.Repeat("ab", sha256.Size)
+
+	tests := []struct {
+		name    string
+		m       Manifest
+		wantErr string
+	}{
+		{name: "newer", m: Manifest{Version: "v1.3.0", SHA256: digest, ExpiresAt: now.Add(time.Hour)}},
+		{name: "same version", m: Manifest{Version: "v1.2.0", SHA256: digest, ExpiresAt: now.Add(time.Hour)}, wantErr: "not newer"},
+		{name: "downgrade", m: Manifest{Version: "v1.1.9", SHA256: digest, ExpiresAt: now.Add(time.Hour)}, wantErr: "not newer"},
+		{name: "expired", m: Manifest{Version: "v1.3.0", SHA256: digest, ExpiresAt: now}, wantErr: "expired"},
+		{name: "no expiry", m: Manifest{Version: "v1.3.0", SHA256: digest}, wantErr: "expired"},
+		{name: "invalid version", m: Manifest{Version: "latest", SHA256: digest, ExpiresAt: now.Add(time.Hour)}, wantErr: "invalid manifest version"},
+		{name: "invalid digest", m: Manifest{Version: "v1.3.0", SHA256: "abc", ExpiresAt: now.Add(time.Hour)}, wantErr: "invalid manifest digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, sig := signManifest(t, priv, tt.m)
+			got, err := u.Verify(manifest, sig)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.m.Version, got.Version)
+		})
+	}
+
+	// the signature covers the manifest, not just the binary
+	manifest, _ := signManifest(t, priv, Manifest{Version: "v1.3.0", SHA256: digest, ExpiresAt: now.Add(time.Hour)})
+	_, otherSig := signManifest(t, priv, Manifest{Version: "v1.1.0", SHA256: digest, ExpiresAt: now.Add(time.Hour)})
+	_, err = u.Verify(manifest, otherSig)
+	assert.ErrorContains(t, err, "signature verification failed")
+	_, err = u.Verify("%%%", otherSig)
+	assert.ErrorContains(t, err, "invalid manifest encoding")
+	_, err = u.Verify(manifest, "%%%")
+	assert.ErrorContains(t, err, "invalid signature encoding")
+}
+
+func TestUpdater_Fetch(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	manifest, sig := signManifest(t, priv, Manifest{
+		Version:   "v1.3.0",
+		SHA256:    hex.EncodeToString(sum[:]),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/task-executor":
+			w.Write(binary)
+		case "/tampered":
+			w.Write(append(binary, '#'))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	u, err := NewUpdater(dir, pub, "v1.2.0")
+	assert.NoError(t, err)
+
+	path, err := u.Fetch(context.Background(), ts.URL+"/task-executor", manifest, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, BinaryFile), path)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, binary, data)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	// a manifest signed by another key is rejected and the staged binary is kept
+	_, other, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	_, badSig := signManifest(t, other, Manifest{Version: "v1.3.0", SHA256: hex.EncodeToString(sum[:]), ExpiresAt: time.Now().Add(time.Hour)})
+	_, err = u.Fetch(context.Background(), ts.URL+"/task-executor", manifest, badSig)
+	assert.ErrorContains(t, err, "signature verification failed")
+
+	_, err = u.Fetch(context.Background(), ts.URL+"/tampered", manifest, sig)
+	assert.ErrorContains(t, err, "does not match the manifest")
+	_, err = u.Fetch(context.Background(), ts.URL+"/missing", manifest, sig)
+	assert.ErrorContains(t, err, "unexpected status 404")
+
+	// only the staged binary is left in the data dir
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestListen_InheritsListener(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+
+	fd, err := InheritableFD(l)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Close())
+
+	// the duplicated fd keeps the socket bound after the original is closed
+	t.Setenv(EnvListenFD, strconv.Itoa(fd))
+	inherited, err := Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer inherited.Close()
+	assert.Equal(t, addr, inherited.Addr().String())
+	_, set := os.LookupEnv(EnvListenFD)
+	assert.False(t, set)
+
+	go func() {
+		conn, err := inherited.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(t, err)
+	conn.Close()
+}
@@ -16,8 +16,12 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -25,7 +29,11 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/logging"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/manager"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/recording"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/selfupdate"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
 	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
 )
@@ -37,8 +45,12 @@ type ErrorResponse struct {
 }
 
 type Handler struct {
-	manager manager.TaskManager
-	config  *config.Config
+	manager     manager.TaskManager
+	config      *config.Config
+	updater     *selfupdate.Updater
+	updateToken string
+	staged      chan<- string
+	sealer      *recording.Sealer
 }
 
 func NewHandler(mgr manager.TaskManager, cfg *config.Config) *Handler {
@@ -55,6 +67,7 @@ func NewHandler(mgr manager.TaskManager, cfg *config.Config) *Handler {
 }
 
 func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	receivedAt := time.Now()
 	if h.manager == nil {
 		writeError(w, http.StatusInternalServerError, "task manager not initialized")
 		return
@@ -76,10 +89,11 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "failed to convert task")
 		return
 	}
+	task.Timings = &types.Timings{ReceivedAt: &receivedAt}
 
 	created, err := h.manager.Create(r.Context(), task)
 	if err != nil {
-		klog.ErrorS(err, "failed to create task", "name", apiTask.Name)
+		klog.FromContext(r.Context()).Error(err, "failed to create task", logging.FieldTask, apiTask.Name)
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to create task: %v", err))
 		return
 	}
@@ -90,10 +104,11 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 
-	klog.InfoS("task created via API", "name", apiTask.Name)
+	klog.FromContext(r.Context()).Info("task created via API", logging.FieldTask, apiTask.Name)
 }
 
 func (h *Handler) SyncTasks(w http.ResponseWriter, r *http.Request) {
+	receivedAt := time.Now()
 	if h.manager == nil {
 		writeError(w, http.StatusInternalServerError, "task manager not initialized")
 		return
@@ -112,13 +127,15 @@ func (h *Handler) SyncTasks(w http.ResponseWriter, r *http.Request) {
 		}
 		task := h.convertAPIToInternalTask(&apiTasks[i])
 		if task != nil {
+			// Only tasks the sync creates keep it.
+			task.Timings = &types.Timings{ReceivedAt: &receivedAt}
 			desired = append(desired, task)
 		}
 	}
 
-	current, err := h.manager.Sync(r.Context(), desired)
+	current, actions, err := h.manager.Sync(r.Context(), desired)
 	if err != nil {
-		klog.ErrorS(err, "failed to sync tasks")
+		klog.FromContext(r.Context()).Error(err, "failed to sync tasks")
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to sync tasks: %v", err))
 		return
 	}
@@ -126,14 +143,16 @@ func (h *Handler) SyncTasks(w http.ResponseWriter, r *http.Request) {
 	response := make([]api.Task, 0, len(current))
 	for _, task := range current {
 		if task != nil {
-			response = append(response, *convertInternalToAPITask(task))
+			apiTask := convertInternalToAPITask(task)
+			apiTask.SyncAction = actions[task.Name]
+			response = append(response, *apiTask)
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 
-	klog.V(1).InfoS("tasks synced via API", "count", len(response))
+	klog.FromContext(r.Context()).V(1).Info("tasks synced via API", "count", len(response), "actions", actions)
 }
 
 func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
@@ -151,7 +170,7 @@ func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
 
 	task, err := h.manager.Get(r.Context(), taskID)
 	if err != nil {
-		klog.ErrorS(err, "failed to get task", "id", taskID)
+		klog.FromContext(r.Context()).Error(err, "failed to get task", logging.FieldTask, taskID)
 		writeError(w, http.StatusNotFound, fmt.Sprintf("task not found: %v", err))
 		return
 	}
@@ -170,7 +189,7 @@ func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
 
 	tasks, err := h.manager.List(r.Context())
 	if err != nil {
-		klog.ErrorS(err, "failed to list tasks")
+		klog.FromContext(r.Context()).Error(err, "failed to list tasks")
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list tasks: %v", err))
 		return
 	}
@@ -207,15 +226,180 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.manager.Delete(r.Context(), taskID)
+	// Without purge the stopped task and its logs are kept for inspection until a purge or the
+	// retention TTL removes them.
+	purge := false
+	if v := r.URL.Query().Get("purge"); v != "" {
+		var err error
+		if purge, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid purge value %q", v))
+			return
+		}
+	}
+
+	var err error
+	if purge {
+		err = h.manager.Purge(r.Context(), taskID)
+	} else {
+		err = h.manager.Delete(r.Context(), taskID)
+	}
 	if err != nil {
-		klog.ErrorS(err, "failed to delete task", "id", taskID)
+		klog.FromContext(r.Context()).Error(err, "failed to delete task", logging.FieldTask, taskID)
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to delete task: %v", err))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-	klog.InfoS("task deleted via API", "id", taskID)
+	klog.FromContext(r.Context()).Info("task deleted via API", logging.FieldTask, taskID, "purge", purge)
+}
+
+// GetTaskLogs streams the output of a task. Process and container tasks return the same raw output,
+// so callers do not need to know where the executor keeps it.
+func (h *Handler) GetTaskLogs(w http.ResponseWriter, r *http.Request) {
+	if h.manager == nil {
+		writeError(w, http.StatusInternalServerError, "task manager not initialized")
+		return
+	}
+
+	taskID := r.PathValue("id")
+	if taskID == "" {
+		writeError(w, http.StatusBadRequest, "task id is required")
+		return
+	}
+	query := r.URL.Query()
+	opts := runtime.LogOptions{
+		Stream:    runtime.LogStream(query.Get("stream")),
+		Container: query.Get("container"),
+		Follow:    query.Get("follow") == "true",
+	}
+	if opts.Stream != "" && opts.Stream != runtime.LogStreamStdout && opts.Stream != runtime.LogStreamStderr {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown log stream %q", opts.Stream))
+		return
+	}
+
+	if _, err := h.manager.Get(r.Context(), taskID); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("task not found: %v", err))
+		return
+	}
+	logs, err := h.manager.Logs(r.Context(), taskID, opts)
+	if err != nil {
+		klog.FromContext(r.Context()).Error(err, "failed to read task logs", logging.FieldTask, taskID)
+		code := http.StatusInternalServerError
+		if errors.Is(err, os.ErrNotExist) {
+			// The task has not written any output yet.
+			code = http.StatusNotFound
+		}
+		writeError(w, code, fmt.Sprintf("failed to read task logs: %v", err))
+		return
+	}
+	defer logs.Close()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	var out io.Writer = w
+	if opts.Follow {
+		// A followed log outlives the server write timeout, and every chunk is sent right away.
+		rc := http.NewResponseController(w)
+		_ = rc.SetWriteDeadline(time.Time{})
+		out = &flushWriter{w: w, rc: rc}
+	}
+	if _, err := io.Copy(out, logs); err != nil {
+		klog.FromContext(r.Context()).V(1).Info("task log stream ended", logging.FieldTask, taskID, "error", err)
+	}
+}
+
+// flushWriter flushes every write to the client.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		err = f.rc.Flush()
+	}
+	return n, err
+}
+
+// Freeze suspends the running tasks in place; the controller calls it when a BatchSandbox is frozen.
+func (h *Handler) Freeze(w http.ResponseWriter, r *http.Request) {
+	h.freeze(w, r, true)
+}
+
+// Thaw continues the tasks suspended by Freeze.
+func (h *Handler) Thaw(w http.ResponseWriter, r *http.Request) {
+	h.freeze(w, r, false)
+}
+
+func (h *Handler) freeze(w http.ResponseWriter, r *http.Request, freeze bool) {
+	if h.manager == nil {
+		writeError(w, http.StatusInternalServerError, "task manager not initialized")
+		return
+	}
+
+	op, signal := "thaw", h.manager.Thaw
+	if freeze {
+		op, signal = "freeze", h.manager.Freeze
+	}
+	tasks, err := signal(r.Context())
+	if err != nil {
+		klog.FromContext(r.Context()).Error(err, "failed to "+op+" tasks")
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to %s tasks: %v", op, err))
+		return
+	}
+	if tasks == nil {
+		tasks = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.FreezeResponse{Tasks: tasks})
+	klog.FromContext(r.Context()).Info("tasks signaled via API", "op", op, "tasks", tasks)
+}
+
+// PauseTask suspends a single running task in place, e.g. to throttle it under node pressure.
+func (h *Handler) PauseTask(w http.ResponseWriter, r *http.Request) {
+	h.pauseTask(w, r, true)
+}
+
+// ResumeTask continues a task suspended by PauseTask or Freeze.
+func (h *Handler) ResumeTask(w http.ResponseWriter, r *http.Request) {
+	h.pauseTask(w, r, false)
+}
+
+func (h *Handler) pauseTask(w http.ResponseWriter, r *http.Request, pause bool) {
+	if h.manager == nil {
+		writeError(w, http.StatusInternalServerError, "task manager not initialized")
+		return
+	}
+
+	taskID := r.PathValue("id")
+	if taskID == "" {
+		writeError(w, http.StatusBadRequest, "task id is required")
+		return
+	}
+	if _, err := h.manager.Get(r.Context(), taskID); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("task not found: %v", err))
+		return
+	}
+
+	op, signal := "resume", h.manager.Resume
+	if pause {
+		op, signal = "pause", h.manager.Pause
+	}
+	task, err := signal(r.Context(), taskID)
+	if err != nil {
+		klog.FromContext(r.Context()).Error(err, "failed to "+op+" task", logging.FieldTask, taskID)
+		code := http.StatusInternalServerError
+		if errors.Is(err, manager.ErrTaskNotRunning) {
+			code = http.StatusConflict
+		}
+		writeError(w, code, fmt.Sprintf("failed to %s task: %v", op, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(convertInternalToAPITask(task))
+	klog.FromContext(r.Context()).Info("task signaled via API", "op", op, logging.FieldTask, taskID)
 }
 
 func writeError(w http.ResponseWriter, code int, message string) {
@@ -263,6 +447,8 @@ func convertInternalToAPITask(task *types.Task) *api.Task {
 				ExitCode: 137,
 				Reason:   sub.Reason,
 				Message:  sub.Message,
+				Stdout:   sub.Stdout,
+				Stderr:   sub.Stderr,
 			}
 			if sub.StartedAt != nil {
 				term.StartedAt = metav1.NewTime(*sub.StartedAt)
@@ -274,6 +460,8 @@ func convertInternalToAPITask(task *types.Task) *api.Task {
 				ExitCode: int32(sub.ExitCode),
 				Reason:   sub.Reason,
 				Message:  sub.Message,
+				Stdout:   sub.Stdout,
+				Stderr:   sub.Stderr,
 			}
 			term.FinishedAt = metav1.NewTime(*sub.FinishedAt)
 			if sub.StartedAt != nil {
@@ -293,6 +481,8 @@ func convertInternalToAPITask(task *types.Task) *api.Task {
 		apiTask.ProcessStatus = apiStatus
 	}
 
+	apiTask.Timings = convertInternalToAPITimings(task.Timings)
+
 	if task.PodTemplateSpec != nil {
 		podStatus := &corev1.PodStatus{
 			Phase: corev1.PodUnknown,
@@ -381,3 +571,22 @@ func convertInternalToAPITask(task *types.Task) *api.Task {
 
 	return apiTask
 }
+
+func convertInternalToAPITimings(timings *types.Timings) *api.TaskTimings {
+	if timings == nil {
+		return nil
+	}
+	apiTime := func(at *time.Time) *metav1.Time {
+		if at == nil {
+			return nil
+		}
+		t := metav1.NewTime(*at)
+		return &t
+	}
+	return &api.TaskTimings{
+		ReceivedAt:    apiTime(timings.ReceivedAt),
+		PersistedAt:   apiTime(timings.PersistedAt),
+		SpawnedAt:     apiTime(timings.SpawnedAt),
+		FirstOutputAt: apiTime(timings.FirstOutputAt),
+	}
+}
@@ -17,18 +17,30 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/config"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/manager"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/recording"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/runtime"
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/selfupdate"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
 	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/utils"
 	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
@@ -36,13 +48,17 @@ import (
 
 // MockTaskManager implements manager.TaskManager for testing
 type MockTaskManager struct {
-	tasks map[string]*types.Task
-	err   error
+	tasks  map[string]*types.Task
+	err    error
+	frozen bool
+	paused map[string]bool
+	logs   map[runtime.LogStream]string
 }
 
 func NewMockTaskManager() *MockTaskManager {
 	return &MockTaskManager{
-		tasks: make(map[string]*types.Task),
+		tasks:  make(map[string]*types.Task),
+		paused: make(map[string]bool),
 	}
 }
 
@@ -54,17 +70,19 @@ func (m *MockTaskManager) Create(ctx context.Context, task *types.Task) (*types.
 	return task, nil
 }
 
-func (m *MockTaskManager) Sync(ctx context.Context, desired []*types.Task) ([]*types.Task, error) {
+func (m *MockTaskManager) Sync(ctx context.Context, desired []*types.Task) ([]*types.Task, map[string]api.SyncAction, error) {
 	if m.err != nil {
-		return nil, m.err
+		return nil, nil, m.err
 	}
 	m.tasks = make(map[string]*types.Task)
 	var result []*types.Task
+	actions := make(map[string]api.SyncAction)
 	for _, t := range desired {
 		m.tasks[t.Name] = t
 		result = append(result, t)
+		actions[t.Name] = api.SyncActionCreated
 	}
-	return result, nil
+	return result, actions, nil
 }
 
 func (m *MockTaskManager) Get(ctx context.Context, id string) (*types.Task, error) {
@@ -89,6 +107,18 @@ func (m *MockTaskManager) List(ctx context.Context) ([]*types.Task, error) {
 }
 
 func (m *MockTaskManager) Delete(ctx context.Context, id string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if task, ok := m.tasks[id]; ok {
+		now := time.Now()
+		task.DeletionTimestamp = &now
+		task.Retain = true
+	}
+	return nil
+}
+
+func (m *MockTaskManager) Purge(ctx context.Context, id string) error {
 	if m.err != nil {
 		return m.err
 	}
@@ -96,6 +126,64 @@ func (m *MockTaskManager) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *MockTaskManager) Freeze(ctx context.Context) ([]string, error) {
+	return m.signal(true)
+}
+
+func (m *MockTaskManager) Thaw(ctx context.Context) ([]string, error) {
+	return m.signal(false)
+}
+
+func (m *MockTaskManager) signal(freeze bool) ([]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.frozen = freeze
+	var names []string
+	for name := range m.tasks {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m *MockTaskManager) Pause(ctx context.Context, id string) (*types.Task, error) {
+	return m.pause(id, true)
+}
+
+func (m *MockTaskManager) Resume(ctx context.Context, id string) (*types.Task, error) {
+	return m.pause(id, false)
+}
+
+func (m *MockTaskManager) pause(id string, pause bool) (*types.Task, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	task, ok := m.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	if pause && task.Status.State != types.TaskStateRunning {
+		return nil, fmt.Errorf("%w: task %s is %s", manager.ErrTaskNotRunning, id, task.Status.State)
+	}
+	m.paused[id] = pause
+	return task, nil
+}
+
+func (m *MockTaskManager) Logs(ctx context.Context, id string, opts runtime.LogOptions) (io.ReadCloser, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	stream := opts.Stream
+	if stream == "" {
+		stream = runtime.LogStreamStdout
+	}
+	out, ok := m.logs[stream]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(out)), nil
+}
+
 func (m *MockTaskManager) Start(ctx context.Context) {}
 func (m *MockTaskManager) Stop()                     {}
 
@@ -137,6 +225,12 @@ func TestHandler_CreateTask(t *testing.T) {
 	if _, ok := mgr.tasks["test-task"]; !ok {
 		t.Error("Task was not created in manager")
 	}
+
+	var created api.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotNil(t, created.Timings, "the time the request was received is reported")
+	assert.NotNil(t, created.Timings.ReceivedAt)
+	assert.Nil(t, created.Timings.SpawnedAt)
 }
 
 func TestHandler_GetTask(t *testing.T) {
@@ -178,8 +272,31 @@ func TestHandler_DeleteTask(t *testing.T) {
 		t.Errorf("DeleteTask returned status %d", w.Code)
 	}
 
+	task, ok := mgr.tasks["test-task"]
+	if !ok || !task.Retain || task.DeletionTimestamp == nil {
+		t.Error("Task was not marked for deletion and retained")
+	}
+
+	req = httptest.NewRequest("DELETE", "/tasks/test-task?purge=true", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DeleteTask with purge returned status %d", w.Code)
+	}
+
 	if _, ok := mgr.tasks["test-task"]; ok {
-		t.Error("Task was not deleted from manager")
+		t.Error("Task was not purged from manager")
+	}
+
+	req = httptest.NewRequest("DELETE", "/tasks/test-task?purge=maybe", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("DeleteTask with an invalid purge value returned status %d", w.Code)
 	}
 }
 
@@ -228,6 +345,13 @@ func TestHandler_SyncTasks(t *testing.T) {
 	if _, ok := mgr.tasks["task-1"]; !ok {
 		t.Error("Task was not synced to manager")
 	}
+	var synced []api.Task
+	if err := json.NewDecoder(w.Body).Decode(&synced); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(synced) != 1 || synced[0].SyncAction != api.SyncActionCreated {
+		t.Errorf("SyncTasks response = %+v, want task-1 created", synced)
+	}
 }
 
 func TestHandler_Errors(t *testing.T) {
@@ -247,6 +371,184 @@ func TestHandler_Errors(t *testing.T) {
 	}
 }
 
+func TestHandler_Config(t *testing.T) {
+	cfg := config.NewConfig()
+	router := NewRouter(NewHandler(NewMockTaskManager(), cfg))
+	serve := func(method, body string) (*httptest.ResponseRecorder, map[string]any) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/config", strings.NewReader(body)))
+		var got map[string]any
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		}
+		return w, got
+	}
+
+	w, got := serve("GET", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "500ms", got["reconcileInterval"])
+	assert.Equal(t, "/var/lib/sandbox/tasks", got["dataDir"])
+
+	w, got = serve("PATCH", `{"reconcileInterval":"2s","maxConcurrentTasks":2}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2s", got["reconcileInterval"])
+	assert.Equal(t, 2, cfg.GetMaxConcurrentTasks())
+
+	// Fields outside the tunables and invalid values are rejected without changing anything.
+	w, _ = serve("PATCH", `{"dataDir":"/tmp"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = serve("PATCH", `{"reconcileInterval":"1s","maxConcurrentTasks":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 2*time.Second, cfg.GetReconcileInterval())
+}
+
+func TestHandler_SelfUpdate(t *testing.T) {
+	h := NewHandler(NewMockTaskManager(), &config.Config{})
+	post := func(token, body string) int {
+		req := httptest.NewRequest("POST", "/selfUpdate", bytes.NewReader([]byte(body)))
+		if token != "" {
+			req.Header.Set(api.SelfUpdateTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		h.SelfUpdate(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNotFound, post("", `{}`))
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	binary := []byte("new binary")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	}))
+	defer ts.Close()
+
+	staged := make(chan string, 1)
+	updater, err := selfupdate.NewUpdater(t.TempDir(), pub, "v1.0.0")
+	assert.NoError(t, err)
+	h.EnableSelfUpdate(updater, "secret", staged)
+	sum := sha256.Sum256(binary)
+	raw, _ := json.Marshal(selfupdate.Manifest{Version: "v1.1.0", SHA256: hex.EncodeToString(sum[:]), ExpiresAt: time.Now().Add(time.Hour)})
+	manifest := base64.StdEncoding.EncodeToString(raw)
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, raw))
+	body, _ := json.Marshal(api.SelfUpdateRequest{URL: ts.URL, Manifest: manifest, Signature: sig})
+
+	assert.Equal(t, http.StatusUnauthorized, post("", string(body)))
+	assert.Equal(t, http.StatusUnauthorized, post("wrong", string(body)))
+	assert.Equal(t, http.StatusBadRequest, post("secret", `{"url":"`+ts.URL+`","signature":"`+sig+`"}`))
+	assert.Equal(t, http.StatusBadRequest, post("secret", `{"url":"`+ts.URL+`","manifest":"`+manifest+`","signature":"AAAA"}`))
+	assert.Empty(t, staged)
+	assert.Equal(t, http.StatusAccepted, post("secret", string(body)))
+	assert.Len(t, staged, 1)
+	// a second update is refused until the first one is picked up
+	assert.Equal(t, http.StatusConflict, post("secret", string(body)))
+}
+
+func TestHandler_SealRecording(t *testing.T) {
+	h := NewHandler(NewMockTaskManager(), &config.Config{})
+	executor := httptest.NewServer(NewRouter(h))
+	defer executor.Close()
+	client := api.NewClient(executor.URL)
+
+	_, err := client.SealRecording(context.Background(), "sbx")
+	assert.ErrorContains(t, err, "status=404")
+
+	var uploads []string
+	store := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer upload-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		uploads = append(uploads, r.URL.Path)
+	}))
+	defer store.Close()
+	dir := t.TempDir()
+	recorder, err := recording.NewRecorder(dir, []byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Record(recording.TypeTask, map[string]any{"task": "a"}))
+	sealer, err := recording.NewSealer(dir, store.URL, "upload-token", "default", "pod-a")
+	require.NoError(t, err)
+	sealer.HTTPClient = store.Client()
+	h.EnableRecording(sealer)
+
+	_, err = client.SealRecording(context.Background(), "")
+	assert.ErrorContains(t, err, "status=400")
+	sealed, err := client.SealRecording(context.Background(), "sbx")
+	assert.NoError(t, err)
+	assert.Len(t, sealed.Uploaded, 1)
+	assert.Equal(t, []string{"/default/pod-a/" + sealed.Uploaded[0]}, uploads)
+}
+
+func TestHandler_FreezeThaw(t *testing.T) {
+	mgr := NewMockTaskManager()
+	mgr.tasks["t1"] = &types.Task{Name: "t1"}
+	executor := httptest.NewServer(NewRouter(NewHandler(mgr, &config.Config{})))
+	defer executor.Close()
+	client := api.NewClient(executor.URL)
+
+	frozen, err := client.Freeze(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t1"}, frozen.Tasks)
+	assert.True(t, mgr.frozen)
+
+	thawed, err := client.Thaw(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t1"}, thawed.Tasks)
+	assert.False(t, mgr.frozen)
+
+	mgr.err = errors.New("executor does not support freezing")
+	_, err = client.Freeze(context.Background())
+	assert.ErrorContains(t, err, "status=500")
+}
+
+func TestHandler_PauseResumeTask(t *testing.T) {
+	mgr := NewMockTaskManager()
+	mgr.tasks["running"] = &types.Task{Name: "running", Status: types.Status{State: types.TaskStateRunning}}
+	mgr.tasks["done"] = &types.Task{Name: "done", Status: types.Status{State: types.TaskStateSucceeded}}
+	executor := httptest.NewServer(NewRouter(NewHandler(mgr, &config.Config{})))
+	defer executor.Close()
+	client := api.NewClient(executor.URL)
+
+	task, err := client.Pause(context.Background(), "running")
+	require.NoError(t, err)
+	assert.Equal(t, "running", task.Name)
+	assert.True(t, mgr.paused["running"])
+
+	_, err = client.Resume(context.Background(), "running")
+	require.NoError(t, err)
+	assert.False(t, mgr.paused["running"])
+
+	_, err = client.Pause(context.Background(), "done")
+	assert.ErrorContains(t, err, "status=409")
+	_, err = client.Pause(context.Background(), "missing")
+	assert.ErrorContains(t, err, "status=404")
+}
+
+func TestHandler_GetTaskLogs(t *testing.T) {
+	mgr := NewMockTaskManager()
+	mgr.tasks["t1"] = &types.Task{Name: "t1"}
+	mgr.logs = map[runtime.LogStream]string{runtime.LogStreamStdout: "hello\n"}
+	executor := httptest.NewServer(NewRouter(NewHandler(mgr, &config.Config{})))
+	defer executor.Close()
+	client := api.NewClient(executor.URL)
+
+	for _, follow := range []bool{false, true} {
+		logs, err := client.Logs(context.Background(), "t1", api.LogOptions{Follow: follow})
+		require.NoError(t, err)
+		out, err := io.ReadAll(logs)
+		logs.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "hello\n", string(out))
+	}
+
+	_, err := client.Logs(context.Background(), "t1", api.LogOptions{Stream: "stderr"})
+	assert.ErrorContains(t, err, "status=404", "no output yet")
+	_, err = client.Logs(context.Background(), "t2", api.LogOptions{})
+	assert.ErrorContains(t, err, "status=404")
+	_, err = client.Logs(context.Background(), "t1", api.LogOptions{Stream: "stdin"})
+	assert.ErrorContains(t, err, "status=400")
+}
+
 func TestConvertInternalToAPITask(t *testing.T) {
 	now := time.Now()
 
@@ -261,6 +563,8 @@ func TestConvertInternalToAPITask(t *testing.T) {
 						ExitCode:   0,
 						Reason:     "Completed",
 						FinishedAt: &now,
+						Stdout:     "out\n",
+						Stderr:     "err\n",
 					},
 				},
 			},
@@ -270,6 +574,8 @@ func TestConvertInternalToAPITask(t *testing.T) {
 		assert.NotNil(t, apiTask.ProcessStatus)
 		assert.NotNil(t, apiTask.ProcessStatus.Terminated)
 		assert.Equal(t, int32(0), apiTask.ProcessStatus.Terminated.ExitCode)
+		assert.Equal(t, "out\n", apiTask.ProcessStatus.Terminated.Stdout)
+		assert.Equal(t, "err\n", apiTask.ProcessStatus.Terminated.Stderr)
 		assert.Nil(t, apiTask.PodStatus)
 	})
 
@@ -0,0 +1,61 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/recording"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+// EnableRecording turns on the seal endpoint of session recording.
+func (h *Handler) EnableRecording(s *recording.Sealer) {
+	h.sealer = s
+}
+
+// SealRecording packages the session record for the released sandbox and uploads it. The controller
+// calls it before the pod is recycled and retries until it succeeds.
+func (h *Handler) SealRecording(w http.ResponseWriter, r *http.Request) {
+	if h.sealer == nil {
+		writeError(w, http.StatusNotFound, "session recording is not enabled")
+		return
+	}
+
+	var req api.SealRecordingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if req.SandboxID == "" {
+		writeError(w, http.StatusBadRequest, "sandboxId is required")
+		return
+	}
+
+	uploaded, err := h.sealer.Seal(r.Context(), req.SandboxID)
+	if err != nil {
+		klog.FromContext(r.Context()).Error(err, "failed to seal session record", "sandbox", req.SandboxID)
+		writeError(w, http.StatusBadGateway, fmt.Sprintf("failed to seal session record: %v", err))
+		return
+	}
+	klog.FromContext(r.Context()).Info("session record sealed", "sandbox", req.SandboxID, "archives", uploaded)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.SealRecordingResponse{Uploaded: uploaded})
+}
@@ -16,6 +16,10 @@ package server
 
 import (
 	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/logging"
 )
 
 func NewRouter(h *Handler) http.Handler {
@@ -26,7 +30,19 @@ func NewRouter(h *Handler) http.Handler {
 	mux.HandleFunc("POST /tasks", h.CreateTask)
 	mux.HandleFunc("GET /tasks/{id}", h.GetTask)
 	mux.HandleFunc("DELETE /tasks/{id}", h.DeleteTask)
+	mux.HandleFunc("GET /tasks/{id}/logs", h.GetTaskLogs)
+	mux.HandleFunc("POST /tasks/{id}/pause", h.PauseTask)
+	mux.HandleFunc("POST /tasks/{id}/resume", h.ResumeTask)
 	mux.HandleFunc("GET /health", h.Health)
+	mux.HandleFunc("POST /selfUpdate", h.SelfUpdate)
+	mux.HandleFunc("POST /recording/seal", h.SealRecording)
+	mux.HandleFunc("POST /freeze", h.Freeze)
+	mux.HandleFunc("POST /thaw", h.Thaw)
+	mux.HandleFunc("GET /config", h.GetConfig)
+	mux.HandleFunc("PATCH /config", h.PatchConfig)
+	mux.Handle("GET /loglevel", logging.LevelHandler())
+	mux.Handle("PUT /loglevel", logging.LevelHandler())
+	mux.Handle("GET /metrics", promhttp.Handler())
 
-	return mux
+	return logging.TraceMiddleware(mux)
 }
@@ -0,0 +1,75 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/selfupdate"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+// EnableSelfUpdate turns on the self-update endpoint. Requests must carry
+// token in the SelfUpdateTokenHeader. Verified binaries are sent to staged;
+// the receiver is expected to shut down and re-exec.
+func (h *Handler) EnableSelfUpdate(u *selfupdate.Updater, token string, staged chan<- string) {
+	h.updater = u
+	h.updateToken = token
+	h.staged = staged
+}
+
+func (h *Handler) SelfUpdate(w http.ResponseWriter, r *http.Request) {
+	if h.updater == nil {
+		writeError(w, http.StatusNotFound, "self-update is not enabled")
+		return
+	}
+	token := r.Header.Get(api.SelfUpdateTokenHeader)
+	if h.updateToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.updateToken)) != 1 {
+		writeError(w, http.StatusUnauthorized, "invalid self-update token")
+		return
+	}
+
+	var req api.SelfUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if req.URL == "" || req.Manifest == "" || req.Signature == "" {
+		writeError(w, http.StatusBadRequest, "url, manifest and signature are required")
+		return
+	}
+
+	path, err := h.updater.Fetch(r.Context(), req.URL, req.Manifest, req.Signature)
+	if err != nil {
+		klog.ErrorS(err, "failed to fetch self-update binary", "url", req.URL)
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to fetch binary: %v", err))
+		return
+	}
+
+	select {
+	case h.staged <- path:
+	default:
+		writeError(w, http.StatusConflict, "self-update already in progress")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	klog.InfoS("self-update staged via API", "url", req.URL, "path", path)
+}
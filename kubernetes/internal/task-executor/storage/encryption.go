@@ -0,0 +1,141 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/utils"
+)
+
+// encryptedPrefix marks a task file sealed with AES-GCM: the prefix, the nonce, then the ciphertext of the
+// task JSON. Files without it are plain JSON, which a store with a key does not read.
+var encryptedPrefix = []byte("OSENC1\n")
+
+// ParseKey decodes a base64 AES key of 16, 24 or 32 bytes, for AES-128, AES-192 or AES-256.
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encoding: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, fmt.Errorf("invalid key size %d, want 16, 24 or 32", len(key))
+}
+
+// LoadKey returns the key given inline, else the key in keyFile, such as a mounted Secret. It returns nil
+// when neither is set.
+func LoadKey(key, keyFile string) ([]byte, error) {
+	if key == "" && keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return ParseKey(key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealTaskFile encrypts the task file of the named task. The name is authenticated along with the file, so a file
+// moved to the directory of another task does not decrypt.
+func sealTaskFile(aead cipher.AEAD, name string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out := append(bytes.Clone(encryptedPrefix), nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(name)), nil
+}
+
+// openTaskFile returns the JSON of a task file, decrypting it if it is sealed. With a key, plain files are
+// rejected: the store encrypts the ones it finds when it is opened, so a plain file showing up later was not
+// written by it.
+func openTaskFile(aead cipher.AEAD, name string, data []byte) ([]byte, error) {
+	sealed, ok := bytes.CutPrefix(data, encryptedPrefix)
+	if !ok {
+		if aead != nil {
+			return nil, fmt.Errorf("task file is not encrypted")
+		}
+		return data, nil
+	}
+	if aead == nil {
+		return nil, fmt.Errorf("task file is encrypted but no key is configured")
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted task file is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt task file: %w", err)
+	}
+	return plaintext, nil
+}
+
+// encryptPlainTaskFiles seals, in place, the task files written in plain JSON before the key was configured.
+// Files that cannot be sealed are left as they are and fail to read like any other unreadable task.
+func (s *fileStore) encryptPlainTaskFiles() {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		klog.ErrorS(err, "failed to read data directory for task file encryption", "dataDir", s.dataDir)
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		taskDir, err := utils.SafeJoin(s.dataDir, entry.Name())
+		if err != nil {
+			continue
+		}
+		taskFile := s.getTaskFilePath(taskDir)
+		data, err := os.ReadFile(taskFile)
+		if err != nil || bytes.HasPrefix(data, encryptedPrefix) {
+			continue
+		}
+		sealed, err := sealTaskFile(s.aead, entry.Name(), data)
+		if err == nil {
+			err = writeFileAtomic(taskFile, sealed)
+		}
+		if err != nil {
+			klog.ErrorS(err, "failed to encrypt plain task file", "name", entry.Name())
+			continue
+		}
+		klog.InfoS("encrypted plain task file", "name", entry.Name())
+	}
+}
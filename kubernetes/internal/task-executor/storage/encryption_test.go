@@ -0,0 +1,176 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/OpenSandbox/sandbox-k8s/internal/task-executor/types"
+	api "github.com/alibaba/OpenSandbox/sandbox-k8s/pkg/task-executor"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(testKey(1)) + "\n")
+	if err != nil {
+		t.Fatalf("ParseKey failed: %v", err)
+	}
+	if !bytes.Equal(key, testKey(1)) {
+		t.Errorf("ParseKey = %x, want %x", key, testKey(1))
+	}
+	if _, err := ParseKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("ParseKey should reject a key of the wrong size")
+	}
+	if _, err := ParseKey("not base64!"); err == nil {
+		t.Error("ParseKey should reject a key that is not base64")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(testKey(2))), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := LoadKey("", "")
+	if err != nil || key != nil {
+		t.Errorf("LoadKey without key = %x, %v, want nil", key, err)
+	}
+	key, err = LoadKey("", keyFile)
+	if err != nil || !bytes.Equal(key, testKey(2)) {
+		t.Errorf("LoadKey from file = %x, %v, want %x", key, err, testKey(2))
+	}
+	key, err = LoadKey(base64.StdEncoding.EncodeToString(testKey(3)), keyFile)
+	if err != nil || !bytes.Equal(key, testKey(3)) {
+		t.Errorf("LoadKey inline = %x, %v, want the inline key %x", key, err, testKey(3))
+	}
+	if _, err := LoadKey("", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadKey should fail on a missing key file")
+	}
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	s, err := NewEncryptedFileStore(tmpDir, testKey(1))
+	if err != nil {
+		t.Fatalf("NewEncryptedFileStore failed: %v", err)
+	}
+
+	task := &types.Task{
+		Name: "secret-task",
+		Process: &api.Process{
+			Command: []string{"echo", "hello"},
+			Env:     []corev1.EnvVar{{Name: "API_TOKEN", Value: "s3cr3t"}},
+		},
+	}
+	if err := s.Create(ctx, task); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, task.Name, "task.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("s3cr3t")) || bytes.Contains(data, []byte("API_TOKEN")) {
+		t.Error("task file holds the environment in plain text")
+	}
+	got, err := s.Get(ctx, task.Name)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Process.Env[0].Value != "s3cr3t" {
+		t.Errorf("Get returned env %v, want the stored env", got.Process.Env)
+	}
+
+	// A plain task file written behind the back of the store is rejected.
+	plain, _ := NewFileStore(tmpDir)
+	if err := plain.Create(ctx, &types.Task{Name: "plain-task"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := s.Get(ctx, "plain-task"); err == nil {
+		t.Error("Get of a plain task file should fail once the store is encrypted")
+	}
+	tasks, err := s.List(ctx)
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("List = %d tasks, %v, want 1", len(tasks), err)
+	}
+
+	// Without the key, or with another one, the encrypted task does not read.
+	if _, err := plain.Get(ctx, task.Name); err == nil {
+		t.Error("Get without the key should fail")
+	}
+	other, _ := NewEncryptedFileStore(tmpDir, testKey(2))
+	if _, err := other.Get(ctx, task.Name); err == nil {
+		t.Error("Get with another key should fail")
+	}
+
+	// A task file moved to the directory of another task does not decrypt.
+	if err := os.WriteFile(filepath.Join(tmpDir, "plain-task", "task.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "plain-task"); err == nil {
+		t.Error("Get of a moved task file should fail")
+	}
+
+	if _, err := NewEncryptedFileStore(tmpDir, []byte("short")); err == nil {
+		t.Error("NewEncryptedFileStore should reject an invalid key")
+	}
+}
+
+func TestEncryptedFileStore_EncryptsPlainTaskFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	plain, _ := NewFileStore(tmpDir)
+	task := &types.Task{
+		Name:    "old-task",
+		Process: &api.Process{Env: []corev1.EnvVar{{Name: "API_TOKEN", Value: "s3cr3t"}}},
+	}
+	if err := plain.Create(ctx, task); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Opening the store with a key encrypts the task written before in place.
+	s, err := NewEncryptedFileStore(tmpDir, testKey(1))
+	if err != nil {
+		t.Fatalf("NewEncryptedFileStore failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, task.Name, "task.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, encryptedPrefix) || bytes.Contains(data, []byte("s3cr3t")) {
+		t.Error("the plain task file was not encrypted on open")
+	}
+	got, err := s.Get(ctx, task.Name)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Process.Env[0].Value != "s3cr3t" {
+		t.Errorf("Get returned env %v, want the stored env", got.Process.Env)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, task.Name, "task.json.tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
@@ -16,6 +16,7 @@ package store
 
 import (
 	"context"
+	"crypto/cipher"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -31,9 +32,32 @@ import (
 type fileStore struct {
 	dataDir string
 	locks   sync.Map // key: taskName, value: *sync.RWMutex
+	// aead encrypts task files when set.
+	aead cipher.AEAD
 }
 
 func NewFileStore(dataDir string) (TaskStore, error) {
+	return newFileStore(dataDir)
+}
+
+// NewEncryptedFileStore returns a file store that encrypts task files with AES-GCM under key, since task
+// specs may hold secrets in their environment. Task files written in plain JSON before are encrypted in place,
+// and plain files are rejected from then on.
+func NewEncryptedFileStore(dataDir string, key []byte) (TaskStore, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newFileStore(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	s.aead = aead
+	s.encryptPlainTaskFiles()
+	return s, nil
+}
+
+func newFileStore(dataDir string) (*fileStore, error) {
 	if dataDir == "" {
 		return nil, fmt.Errorf("dataDir cannot be empty")
 	}
@@ -90,7 +114,7 @@ func (s *fileStore) Create(ctx context.Context, task *types.Task) error {
 		return err
 	}
 
-	klog.InfoS("created task", "name", task.Name, "dir", taskDir)
+	klog.InfoS("created task", "task", task.Name, "dir", taskDir)
 	return nil
 }
 
@@ -120,7 +144,7 @@ func (s *fileStore) Update(ctx context.Context, task *types.Task) error {
 		return err
 	}
 
-	klog.V(2).InfoS("updated task", "name", task.Name, "state", task.Status.State)
+	klog.V(2).InfoS("updated task", "task", task.Name, "state", task.Status.State)
 	return nil
 }
 
@@ -219,9 +243,18 @@ func (s *fileStore) writeTaskFile(taskDir string, task *types.Task) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal task: %w", err)
 	}
+	if s.aead != nil {
+		if data, err = sealTaskFile(s.aead, task.Name, data); err != nil {
+			return err
+		}
+	}
 
-	taskFile := s.getTaskFilePath(taskDir)
-	tmpFile := taskFile + ".tmp"
+	return writeFileAtomic(s.getTaskFilePath(taskDir), data)
+}
+
+// writeFileAtomic replaces the file with data through a synced temporary file.
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile := path + ".tmp"
 
 	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write temp file: %w", err)
@@ -239,7 +272,7 @@ func (s *fileStore) writeTaskFile(taskDir string, task *types.Task) error {
 	}
 	f.Close()
 
-	if err := os.Rename(tmpFile, taskFile); err != nil {
+	if err := os.Rename(tmpFile, path); err != nil {
 		os.Remove(tmpFile)
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
@@ -254,6 +287,9 @@ func (s *fileStore) readTaskFile(taskDir, taskName string) (*types.Task, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read task file: %w", err)
 	}
+	if data, err = openTaskFile(s.aead, taskName, data); err != nil {
+		return nil, err
+	}
 
 	var task types.Task
 	if err := json.Unmarshal(data, &task); err != nil {
@@ -49,15 +49,102 @@ type SubStatus struct {
 	ExitCode   int        `json:"exitCode,omitempty"`
 	StartedAt  *time.Time `json:"startedAt,omitempty"`
 	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// FirstOutputAt is when the process first wrote to stdout or stderr.
+	FirstOutputAt *time.Time `json:"firstOutputAt,omitempty"`
+	// Stdout and Stderr are the end of the output of a terminated process, see Process.CaptureOutputBytes.
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
 }
 
 type Task struct {
 	Name              string     `json:"name"`
 	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty"`
+	// Retain keeps a deleted task, with its logs, once it has stopped, until it is purged or its
+	// retention expires.
+	Retain bool `json:"retain,omitempty"`
 
 	Process         *api.Process            `json:"process"`
 	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec"`
 
 	// Status is now a first-class citizen and persisted.
 	Status Status `json:"status"`
+	// Timings record the startup of the task.
+	Timings *Timings `json:"timings,omitempty"`
+}
+
+// Timings are the points in time a task passed on its way from the API to its first output, so the
+// latency of the executor can be told apart from the one of scheduling and allocation.
+type Timings struct {
+	// ReceivedAt is when the API received the request that created the task.
+	ReceivedAt *time.Time `json:"receivedAt,omitempty"`
+	// PersistedAt is when the task was written to the store.
+	PersistedAt *time.Time `json:"persistedAt,omitempty"`
+	// SpawnedAt is when the process of the task was started, after its image was pulled.
+	SpawnedAt *time.Time `json:"spawnedAt,omitempty"`
+	// FirstOutputAt is when the task first wrote to stdout or stderr.
+	FirstOutputAt *time.Time `json:"firstOutputAt,omitempty"`
+}
+
+// DeepCopy returns a copy of the Timings that shares no memory with the original.
+func (t *Timings) DeepCopy() *Timings {
+	if t == nil {
+		return nil
+	}
+	copyTime := func(at *time.Time) *time.Time {
+		if at == nil {
+			return nil
+		}
+		c := *at
+		return &c
+	}
+	return &Timings{
+		ReceivedAt:    copyTime(t.ReceivedAt),
+		PersistedAt:   copyTime(t.PersistedAt),
+		SpawnedAt:     copyTime(t.SpawnedAt),
+		FirstOutputAt: copyTime(t.FirstOutputAt),
+	}
+}
+
+// DeepCopy returns a copy of the Status that shares no memory with the original.
+func (s Status) DeepCopy() Status {
+	out := Status{State: s.State}
+	if s.SubStatuses != nil {
+		out.SubStatuses = make([]SubStatus, len(s.SubStatuses))
+		for i, sub := range s.SubStatuses {
+			out.SubStatuses[i] = sub
+			if sub.StartedAt != nil {
+				t := *sub.StartedAt
+				out.SubStatuses[i].StartedAt = &t
+			}
+			if sub.FinishedAt != nil {
+				t := *sub.FinishedAt
+				out.SubStatuses[i].FinishedAt = &t
+			}
+			if sub.FirstOutputAt != nil {
+				t := *sub.FirstOutputAt
+				out.SubStatuses[i].FirstOutputAt = &t
+			}
+		}
+	}
+	return out
+}
+
+// DeepCopy returns a copy of the Task that shares no memory with the original.
+func (t *Task) DeepCopy() *Task {
+	if t == nil {
+		return nil
+	}
+	out := &Task{
+		Name:            t.Name,
+		Retain:          t.Retain,
+		Process:         t.Process.DeepCopy(),
+		PodTemplateSpec: t.PodTemplateSpec.DeepCopy(),
+		Status:          t.Status.DeepCopy(),
+		Timings:         t.Timings.DeepCopy(),
+	}
+	if t.DeletionTimestamp != nil {
+		ts := *t.DeletionTimestamp
+		out.DeletionTimestamp = &ts
+	}
+	return out
 }
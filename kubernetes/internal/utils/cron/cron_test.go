@@ -0,0 +1,70 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "x * * * *"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, 3, 12, 10, 30, 15, 0, time.UTC)
+	for _, tc := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 12, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 12, 10, 45, 0, 0, time.UTC)},
+		{"0 8 * * MON-FRI", time.Date(2025, 3, 13, 8, 0, 0, 0, time.UTC)},
+		{"0 20 * * 1-5", time.Date(2025, 3, 12, 20, 0, 0, 0, time.UTC)},
+		{"0 9 * * sun", time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 8-18/4 * * *", time.Date(2025, 3, 12, 12, 30, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches.
+		{"0 0 20 * 5", time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	} {
+		s, err := Parse(tc.spec)
+		require.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.want, s.Next(from), tc.spec)
+	}
+
+	never, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+}
+
+func TestNextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	s, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+	next := s.Next(time.Date(2025, 3, 12, 2, 0, 0, 0, time.UTC).In(loc))
+	assert.Equal(t, time.Date(2025, 3, 13, 1, 0, 0, 0, time.UTC), next.UTC())
+}
@@ -28,6 +28,8 @@ import (
 const (
 	IndexNameForOwnerRefUID = "ownerRefUID"
 	IndexNameForPoolRef     = "poolRef"
+	// IndexNameForTemplateConfigMap indexes Pools by the ConfigMap of spec.templateFrom.
+	IndexNameForTemplateConfigMap = "templateConfigMap"
 )
 
 var (
@@ -42,6 +44,14 @@ var OwnerIndexFunc = func(obj client.Object) []string {
 	return owners
 }
 
+var TemplateConfigMapIndexFunc = func(obj client.Object) []string {
+	pool, ok := obj.(*sandboxv1alpha1.Pool)
+	if ok && pool.Spec.TemplateFrom != nil {
+		return []string{pool.Spec.TemplateFrom.ConfigMapKeyRef.Name}
+	}
+	return nil
+}
+
 var PoolRefIndexFunc = func(obj client.Object) []string {
 	batchSandbox, ok := obj.(*sandboxv1alpha1.BatchSandbox)
 	if ok {
@@ -60,6 +70,9 @@ func RegisterFieldIndexes(c cache.Cache) error {
 		if err = c.IndexField(context.TODO(), &sandboxv1alpha1.BatchSandbox{}, IndexNameForPoolRef, PoolRefIndexFunc); err != nil {
 			return
 		}
+		if err = c.IndexField(context.TODO(), &sandboxv1alpha1.Pool{}, IndexNameForTemplateConfigMap, TemplateConfigMapIndexFunc); err != nil {
+			return
+		}
 	})
 	return err
 }
@@ -16,6 +16,7 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -215,10 +216,30 @@ func (m MultiPodSorter) Sort(a, b *v1.Pod) int {
 	return 0
 }
 
+// AnnotationPoolDeletionCost is the pool-specific equivalent of the pod-deletion-cost annotation of
+// ReplicaSets; it takes precedence when a pod carries both.
+const AnnotationPoolDeletionCost = "pool.sandbox.opensandbox.io/deletion-cost"
+
+// PodDeletionCost returns the deletion cost of a pod from AnnotationPoolDeletionCost or
+// controller.kubernetes.io/pod-deletion-cost: pods with a lower cost are deleted first. Missing or
+// invalid values count as 0.
+func PodDeletionCost(pod *v1.Pod) int32 {
+	for _, key := range []string{AnnotationPoolDeletionCost, v1.PodDeletionCost} {
+		if raw, ok := pod.Annotations[key]; ok {
+			cost, err := strconv.ParseInt(raw, 10, 32)
+			if err != nil {
+				return 0
+			}
+			return int32(cost)
+		}
+	}
+	return 0
+}
+
 // ComparePodsForDeletion compares two pods for deletion priority.
 // Returns true if p1 should be deleted before p2.
 // Priority order: Unassigned < Assigned, Pending < Unknown < Running,
-// NotReady < Ready, shorter ready time < longer ready time,
+// NotReady < Ready, lower deletion cost < higher deletion cost, shorter ready time < longer ready time,
 // higher restarts < lower restarts, newer < older, name for tie-breaking.
 func ComparePodsForDeletion(p1, p2 *v1.Pod) bool {
 	if len(p1.Spec.NodeName) != len(p2.Spec.NodeName) && (len(p1.Spec.NodeName) == 0 || len(p2.Spec.NodeName) == 0) {
@@ -240,6 +261,10 @@ func ComparePodsForDeletion(p1, p2 *v1.Pod) bool {
 		return !p1Ready
 	}
 
+	if c1, c2 := PodDeletionCost(p1), PodDeletionCost(p2); c1 != c2 {
+		return c1 < c2
+	}
+
 	if p1Ready && p2Ready {
 		p1Cond := GetPodReadyCondition(p1.Status)
 		p2Cond := GetPodReadyCondition(p2.Status)
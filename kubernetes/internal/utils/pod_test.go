@@ -428,6 +428,18 @@ func TestComparePodsForDeletion(t *testing.T) {
 			}}},
 			want: false,
 		},
+		{
+			name: "lower deletion cost < higher deletion cost",
+			p1:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cheap", CreationTimestamp: older, Annotations: map[string]string{v1.PodDeletionCost: "-1"}}},
+			p2:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "default", CreationTimestamp: newer}},
+			want: true,
+		},
+		{
+			name: "pool deletion cost overrides pod deletion cost",
+			p1:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "overridden", Annotations: map[string]string{v1.PodDeletionCost: "-1", AnnotationPoolDeletionCost: "1"}}},
+			p2:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			want: false,
+		},
 		{
 			name: "newer < older",
 			p1:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "newer", CreationTimestamp: newer}},
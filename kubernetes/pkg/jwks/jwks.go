@@ -0,0 +1,366 @@
+// Copyright 2025 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jwks verifies Kubernetes service-account tokens locally against the issuer's JSON Web
+// Key Set. It backs the jwks auth mode of task-executor and of the execd and egress sidecars.
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// jwksRefreshInterval bounds how often unknown key IDs trigger a refetch.
+	jwksRefreshInterval = 30 * time.Second
+	// jwksMaxAge forces a refetch so retired keys are eventually dropped.
+	jwksMaxAge = time.Hour
+	// jwksFetchTimeout bounds a fetch shared by concurrent callers, which is not tied to any of their contexts.
+	jwksFetchTimeout = 10 * time.Second
+	// clockSkew is tolerated on exp and nbf.
+	clockSkew = 30 * time.Second
+)
+
+// Identity is the caller a token was issued to.
+type Identity struct {
+	Username string
+	UID      string
+	Groups   []string
+}
+
+// TokenSource returns the bearer token sent when fetching the key set.
+type TokenSource func() (string, error)
+
+// Verifier verifies token signatures locally against the issuer's JSON Web Key Set.
+// It does not notice tokens whose pod or service account was deleted before expiry; use a
+// TokenReview when that matters.
+type Verifier struct {
+	client   *http.Client
+	url      string
+	issuer   string
+	audience string
+	token    TokenSource
+
+	// fetches runs one key set fetch at a time for all callers; mu is not held during a fetch.
+	fetches   singleflight.Group
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	now       func() time.Time
+}
+
+// NewVerifier fetches keys from url with client. token, when not nil, authenticates the fetch;
+// the API server only serves its JWKS to authenticated callers. Tokens must carry audience,
+// and issuer unless it is empty.
+func NewVerifier(client *http.Client, url, issuer, audience string, token TokenSource) *Verifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Verifier{
+		client:   client,
+		url:      url,
+		issuer:   issuer,
+		audience: audience,
+		token:    token,
+		now:      time.Now,
+	}
+}
+
+// SameOrigin reports whether a and b are URLs with the same scheme and host. A prefix match is
+// not enough: https://10.0.0.1:443.evil.example starts with https://10.0.0.1:443.
+func SameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Host != "" && strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer    string     `json:"iss"`
+	Subject   string     `json:"sub"`
+	Audience  audience   `json:"aud"`
+	Expiry    int64      `json:"exp"`
+	NotBefore int64      `json:"nbf"`
+	K8s       *k8sClaims `json:"kubernetes.io"`
+}
+
+type k8sClaims struct {
+	Namespace      string `json:"namespace"`
+	ServiceAccount struct {
+		Name string `json:"name"`
+		UID  string `json:"uid"`
+	} `json:"serviceaccount"`
+}
+
+// audience is the aud claim, which may be a string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+	*a = l
+	return nil
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	header := &jwtHeader{}
+	if err := decodeSegment(parts[0], header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	claims := &jwtClaims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if v.audience == "" {
+		// An empty audience would accept tokens issued for any other service.
+		return nil, errors.New("no token audience configured")
+	}
+	now := v.now()
+	if claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(clockSkew)) {
+		return nil, errors.New("token is expired")
+	}
+	if claims.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("token is issued by %q, want %q", claims.Issuer, v.issuer)
+	}
+	if !slices.Contains(claims.Audience, v.audience) {
+		return nil, fmt.Errorf("token is not issued for audience %q", v.audience)
+	}
+	if claims.K8s == nil || claims.K8s.Namespace == "" || claims.K8s.ServiceAccount.Name == "" {
+		return nil, errors.New("token is not a service account token")
+	}
+	// Mirror the user info the API server reports for service accounts.
+	ns := claims.K8s.Namespace
+	return &Identity{
+		Username: claims.Subject,
+		UID:      claims.K8s.ServiceAccount.UID,
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + ns, "system:authenticated"},
+	}, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	digest := sha256.Sum256(signed)
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("token key type does not match RS256")
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("invalid token signature")
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return errors.New("token key type does not match ES256")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+	return nil
+}
+
+// key returns the key with the given ID. An unknown key waits for the key set to be fetched; a known key
+// whose key set is stale is served while the key set is refetched in the background.
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	key, ok := v.keys[kid]
+	age := v.now().Sub(v.fetchedAt)
+	v.mu.Unlock()
+	switch {
+	case ok && age <= jwksMaxAge:
+		return key, nil
+	case ok:
+		// Keep serving the known key while the key set is refetched, or while the issuer is unreachable.
+		v.refresh()
+		return key, nil
+	case age < jwksRefreshInterval:
+		return nil, fmt.Errorf("unknown token key %q", kid)
+	}
+
+	select {
+	case res := <-v.refresh():
+		if res.Err != nil {
+			return nil, res.Err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	v.mu.Lock()
+	key, ok = v.keys[kid]
+	v.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown token key %q", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set, joining a fetch that is already in flight.
+func (v *Verifier) refresh() <-chan singleflight.Result {
+	return v.fetches.DoChan("jwks", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+		defer cancel()
+		keys, err := v.fetch(ctx)
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.fetchedAt = v.now()
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		return nil, nil
+	})
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *Verifier) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if v.token != nil {
+		token, err := v.token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		pub, err := k.publicKey()
+		if err != nil {
+			// Skip keys of unsupported types rather than failing the whole set.
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -21,11 +21,72 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/klog/v2"
 )
 
+// tokenRefreshInterval bounds how long a read token is reused. The kubelet
+// rotates projected tokens well before they expire, so re-reading once a
+// minute is enough to pick up a new one.
+const tokenRefreshInterval = time.Minute
+
+var bearerToken tokenFile
+
+// SetTokenFile makes every client present the token in path as a bearer
+// token. It is meant for a projected service-account token when the
+// task-executor runs with --auth-mode. An empty path disables the header.
+func SetTokenFile(path string) {
+	bearerToken.mu.Lock()
+	defer bearerToken.mu.Unlock()
+	bearerToken.path = path
+	bearerToken.token = ""
+	bearerToken.readAt = time.Time{}
+}
+
+type tokenFile struct {
+	mu     sync.Mutex
+	path   string
+	token  string
+	readAt time.Time
+}
+
+func (t *tokenFile) get() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.path == "" {
+		return ""
+	}
+	if t.token != "" && time.Since(t.readAt) < tokenRefreshInterval {
+		return t.token
+	}
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		// Keep the previous token; it stays valid for a while after rotation.
+		klog.Warningf("failed to read task-executor token file %s: %v", t.path, err)
+		return t.token
+	}
+	t.token = strings.TrimSpace(string(data))
+	t.readAt = time.Now()
+	return t.token
+}
+
+// BearerToken returns the token configured with SetTokenFile, or "" if none
+// is configured.
+func BearerToken() string {
+	return bearerToken.get()
+}
+
+func authorize(req *http.Request) {
+	if token := bearerToken.get(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
@@ -72,6 +133,7 @@ func (c *Client) Set(ctx context.Context, task *Task) (*Task, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	authorize(req)
 
 	// Send request with retry
 	var resp *http.Response
@@ -119,6 +181,7 @@ func (c *Client) Get(ctx context.Context) (*Task, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	authorize(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -145,3 +208,161 @@ func (c *Client) Get(ctx context.Context) (*Task, error) {
 	// No tasks
 	return nil, nil
 }
+
+// SealRecording packages the session record of the pod and uploads it. It is
+// safe to retry until it succeeds.
+func (c *Client) SealRecording(ctx context.Context, sandboxID string) (*SealRecordingResponse, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client is nil")
+	}
+
+	data, err := json.Marshal(SealRecordingRequest{SandboxID: sandboxID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/recording/seal", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	var sealed SealRecordingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sealed); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &sealed, nil
+}
+
+// Freeze suspends the running tasks of the pod in place. It is safe to repeat.
+func (c *Client) Freeze(ctx context.Context) (*FreezeResponse, error) {
+	return c.freeze(ctx, "/freeze")
+}
+
+// Thaw continues the tasks suspended by Freeze. It is safe to repeat.
+func (c *Client) Thaw(ctx context.Context) (*FreezeResponse, error) {
+	return c.freeze(ctx, "/thaw")
+}
+
+// Pause suspends a single running task in place and returns it. It is safe to repeat.
+func (c *Client) Pause(ctx context.Context, name string) (*Task, error) {
+	return c.pause(ctx, name, "pause")
+}
+
+// Resume continues a task suspended by Pause or Freeze and returns it. It is safe to repeat.
+func (c *Client) Resume(ctx context.Context, name string) (*Task, error) {
+	return c.pause(ctx, name, "resume")
+}
+
+// Logs streams the output of a task. The output is the same for process and container tasks. A
+// followed log ends once the task finishes; the caller closes the returned reader.
+func (c *Client) Logs(ctx context.Context, name string, opts LogOptions) (io.ReadCloser, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client is nil")
+	}
+
+	query := url.Values{}
+	if opts.Stream != "" {
+		query.Set("stream", opts.Stream)
+	}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	httpClient := c.httpClient
+	if opts.Follow {
+		query.Set("follow", "true")
+		// The client timeout covers reading the body, which a followed log keeps open.
+		streaming := *c.httpClient
+		streaming.Timeout = 0
+		httpClient = &streaming
+	}
+	target := c.baseURL + "/tasks/" + url.PathEscape(name) + "/logs"
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authorize(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) freeze(ctx context.Context, path string) (*FreezeResponse, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client is nil")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	var frozen FreezeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&frozen); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &frozen, nil
+}
+
+func (c *Client) pause(ctx context.Context, name, op string) (*Task, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client is nil")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/tasks/"+url.PathEscape(name)+"/"+op, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	var task Task
+	if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &task, nil
+}
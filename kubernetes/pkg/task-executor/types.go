@@ -30,6 +30,42 @@ type Task struct {
 
 	ProcessStatus *ProcessStatus    `json:"processStatus,omitempty"`
 	PodStatus     *corev1.PodStatus `json:"podStatus,omitempty"`
+	// Timings report the startup of the task. Set by the task-executor.
+	Timings *TaskTimings `json:"timings,omitempty"`
+	// SyncAction is what POST /setTasks did to the task. Only set in its response.
+	SyncAction SyncAction `json:"syncAction,omitempty"`
+}
+
+// SyncAction is what a sync of the task list did to a task.
+type SyncAction string
+
+const (
+	// SyncActionKept means the task was desired with the spec it runs, and was left alone.
+	SyncActionKept SyncAction = "Kept"
+	// SyncActionUpdated means the task was desired with a different spec. It is restarted with the new
+	// spec: at once if it has finished, else once it has stopped, until when the old task is reported.
+	SyncActionUpdated SyncAction = "Updated"
+	// SyncActionCreated means the task was desired and did not exist.
+	SyncActionCreated SyncAction = "Created"
+	// SyncActionDeleted means the task is no longer desired and is being deleted.
+	SyncActionDeleted SyncAction = "Deleted"
+)
+
+// TaskTimings are the points in time a task passed on its way from the API to its first output. Their
+// differences tell how much of the startup of a sandbox is spent in the task-executor.
+type TaskTimings struct {
+	// ReceivedAt is when the task-executor received the request that created the task.
+	// +optional
+	ReceivedAt *metav1.Time `json:"receivedAt,omitempty"`
+	// PersistedAt is when the task was written to the task store.
+	// +optional
+	PersistedAt *metav1.Time `json:"persistedAt,omitempty"`
+	// SpawnedAt is when the process of the task was started, after its image was pulled.
+	// +optional
+	SpawnedAt *metav1.Time `json:"spawnedAt,omitempty"`
+	// FirstOutputAt is when the task first wrote to stdout or stderr.
+	// +optional
+	FirstOutputAt *metav1.Time `json:"firstOutputAt,omitempty"`
 }
 
 type Process struct {
@@ -43,6 +79,66 @@ type Process struct {
 	WorkingDir string `json:"workingDir,omitempty"`
 	// TimeoutSeconds process timeout seconds.
 	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+	// Image is an OCI image the process runs chrooted in. Empty runs the process in the main container.
+	Image string `json:"image,omitempty"`
+	// CaptureOutputBytes inlines up to this many bytes of the end of stdout and stderr in the terminated
+	// status, at most MaxCaptureOutputBytes. 0 captures nothing.
+	CaptureOutputBytes int32 `json:"captureOutputBytes,omitempty"`
+	// Umask is the file mode creation mask of the process in octal, e.g. "0027". Empty keeps the mask of
+	// the task-executor.
+	Umask string `json:"umask,omitempty"`
+	// MaxOpenFiles sets RLIMIT_NOFILE of the process, both soft and hard. Unset keeps the limit.
+	MaxOpenFiles *int64 `json:"maxOpenFiles,omitempty"`
+	// Nice is the niceness of the process, from -20 to 19. Unset keeps the niceness of the task-executor.
+	Nice *int32 `json:"nice,omitempty"`
+	// IOClass is the I/O scheduling class of the process, one of the IOClass constants. Empty keeps the
+	// class of the task-executor.
+	IOClass string `json:"ioClass,omitempty"`
+}
+
+// I/O scheduling classes of Process.IOClass.
+const (
+	IOClassRealTime   = "RealTime"
+	IOClassBestEffort = "BestEffort"
+	IOClassIdle       = "Idle"
+)
+
+// MaxCaptureOutputBytes bounds Process.CaptureOutputBytes, so statuses stay small.
+const MaxCaptureOutputBytes = 32 * 1024
+
+// DeepCopy returns a copy of the Process that shares no memory with the original.
+func (p *Process) DeepCopy() *Process {
+	if p == nil {
+		return nil
+	}
+	out := &Process{
+		Command:            append([]string(nil), p.Command...),
+		Args:               append([]string(nil), p.Args...),
+		WorkingDir:         p.WorkingDir,
+		Image:              p.Image,
+		CaptureOutputBytes: p.CaptureOutputBytes,
+		Umask:              p.Umask,
+		IOClass:            p.IOClass,
+	}
+	if p.Env != nil {
+		out.Env = make([]corev1.EnvVar, len(p.Env))
+		for i := range p.Env {
+			p.Env[i].DeepCopyInto(&out.Env[i])
+		}
+	}
+	if p.TimeoutSeconds != nil {
+		v := *p.TimeoutSeconds
+		out.TimeoutSeconds = &v
+	}
+	if p.MaxOpenFiles != nil {
+		v := *p.MaxOpenFiles
+		out.MaxOpenFiles = &v
+	}
+	if p.Nice != nil {
+		v := *p.Nice
+		out.Nice = &v
+	}
+	return out
 }
 
 // ProcessStatus holds a possible state of process.
@@ -96,4 +192,55 @@ type Terminated struct {
 	// Time at which the process last terminated
 	// +optional
 	FinishedAt metav1.Time `json:"finishedAt,omitempty"`
+	// Stdout is the end of the standard output, up to Process.CaptureOutputBytes.
+	// +optional
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr is the end of the standard error, up to Process.CaptureOutputBytes.
+	// +optional
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// SelfUpdateTokenHeader carries the token that authorizes a self-update.
+const SelfUpdateTokenHeader = "X-Self-Update-Token"
+
+// SelfUpdateRequest asks the task-executor to replace its own binary.
+type SelfUpdateRequest struct {
+	// URL the new binary is downloaded from.
+	URL string `json:"url"`
+	// Manifest is the base64 JSON manifest of the binary: its version, hex sha256 digest and expiry, e.g.
+	// {"version":"v1.2.3","sha256":"...","expiresAt":"2025-01-01T00:00:00Z"}.
+	Manifest string `json:"manifest"`
+	// Signature is the base64 ed25519 signature of the decoded manifest.
+	Signature string `json:"signature"`
+}
+
+// SealRecordingRequest asks the task-executor to package and upload the session record of a sandbox
+// that is being released.
+type SealRecordingRequest struct {
+	// SandboxID names the archive; it is the BatchSandbox the pod was allocated to.
+	SandboxID string `json:"sandboxId"`
 }
+
+// SealRecordingResponse lists the archives uploaded by a seal.
+type SealRecordingResponse struct {
+	Uploaded []string `json:"uploaded"`
+}
+
+// FreezeResponse lists the tasks frozen or thawed by a freeze or thaw request.
+type FreezeResponse struct {
+	Tasks []string `json:"tasks"`
+}
+
+// LogOptions selects the task output returned by GET /tasks/{name}/logs.
+type LogOptions struct {
+	// Stream is "stdout" (the default) or "stderr".
+	Stream string
+	// Container picks the container of a container task; empty means the first one.
+	Container string
+	// Follow keeps the response open and streams new output until the task finishes.
+	Follow bool
+}
+
+// PodConditionExecutorReady is the pod condition the task-executor sets once it is serving. Listing it in
+// the pod readinessGates keeps the pod out of Pool available counts and Service endpoints until then.
+const PodConditionExecutorReady corev1.PodConditionType = "sandbox.opensandbox.io/executor-ready"
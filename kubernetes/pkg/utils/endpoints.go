@@ -23,7 +23,7 @@ import (
 
 const (
 	// AnnotationEndpoints is the annotation key for storing BatchSandbox endpoints
-	AnnotationEndpoints = "sandbox.opensandbox.io/endpoints"
+	AnnotationEndpoints = sandboxv1alpha1.AnnotationEndpoints
 )
 
 // GetEndpoints extracts endpoint IPs from BatchSandbox annotations
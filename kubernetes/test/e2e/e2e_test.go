@@ -563,6 +563,8 @@ var _ = Describe("Manager", Ordered, func() {
 			_, err = utils.Run(cmd)
 			Expect(err).NotTo(HaveOccurred())
 
+			waitObservedGeneration("pool", poolName, testNamespace, time.Minute)
+
 			By("verifying Pool scales up to meet new poolMin")
 			Eventually(func(g Gomega) {
 				cmd := exec.Command("kubectl", "get", "pool", poolName, "-n", testNamespace,
@@ -596,6 +598,8 @@ var _ = Describe("Manager", Ordered, func() {
 			_, err = utils.Run(cmd)
 			Expect(err).NotTo(HaveOccurred())
 
+			waitObservedGeneration("pool", poolName, testNamespace, time.Minute)
+
 			By("verifying Pool respects new poolMax constraint")
 			Eventually(func(g Gomega) {
 				cmd := exec.Command("kubectl", "get", "pool", poolName, "-n", testNamespace,
@@ -4362,12 +4366,252 @@ var _ = Describe("Manager", Ordered, func() {
 		})
 	})
 
+	// Pool Contention: several BatchSandboxes compete for a pool that cannot serve all of them at
+	// once. These specs pin the contention semantics that allocation features build upon: waiting
+	// sandboxes are served in creation order, their PodSupplement drives scale-up up to poolMax, and a
+	// pod is never held by two sandboxes.
+	Context("Pool Contention", func() {
+		const testNamespace = "default"
+
+		BeforeAll(func() {
+			By("waiting for controller to be ready")
+			Eventually(func(g Gomega) {
+				cmd := exec.Command("kubectl", "get", "pods", "-l", "control-plane=controller-manager",
+					"-n", namespace, "-o", "jsonpath={.items[0].status.phase}")
+				output, err := utils.Run(cmd)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(output).To(Equal("Running"))
+			}, 2*time.Minute).Should(Succeed())
+		})
+
+		It("should serve waiting BatchSandboxes in creation order", func() {
+			const poolName = "test-contention-fifo"
+			const bsHold = "test-contention-fifo-hold"
+			const bsFirst = "test-contention-fifo-1"
+			const bsSecond = "test-contention-fifo-2"
+
+			By("creating a Pool of two pods that keeps released pods (Noop)")
+			applyTemplate("testdata/pool-with-recycle.yaml", map[string]interface{}{
+				"PoolName":     poolName,
+				"SandboxImage": utils.SandboxImage,
+				"Namespace":    testNamespace,
+				"BufferMax":    2,
+				"BufferMin":    2,
+				"PoolMax":      2,
+				"PoolMin":      2,
+				"RecycleType":  "Noop",
+				"Command":      `["sleep", "3600"]`,
+			}, poolName+".yaml")
+			waitPoolStable(poolName, testNamespace, 3*time.Minute)
+
+			By("exhausting the pool")
+			applyTemplate("testdata/batchsandbox-pooled-no-expire.yaml", map[string]interface{}{
+				"BatchSandboxName": bsHold,
+				"Namespace":        testNamespace,
+				"Replicas":         2,
+				"PoolName":         poolName,
+			}, bsHold+".yaml")
+			getAllocatedPods(bsHold, testNamespace, 2)
+
+			By("queueing two BatchSandboxes that each need the whole pool")
+			for _, name := range []string{bsFirst, bsSecond} {
+				applyTemplate("testdata/batchsandbox-pooled-no-expire.yaml", map[string]interface{}{
+					"BatchSandboxName": name,
+					"Namespace":        testNamespace,
+					"Replicas":         2,
+					"PoolName":         poolName,
+				}, name+".yaml")
+				// Creation timestamps have a resolution of one second.
+				time.Sleep(2 * time.Second)
+			}
+			Consistently(func(g Gomega) {
+				allocs := getAllocations(g, testNamespace, bsFirst, bsSecond)
+				g.Expect(allocs[bsFirst]).To(BeEmpty())
+				g.Expect(allocs[bsSecond]).To(BeEmpty())
+			}, 15*time.Second, 3*time.Second).Should(Succeed())
+
+			By("releasing the pool and verifying the older BatchSandbox gets it")
+			cmd := exec.Command("kubectl", "delete", "batchsandbox", bsHold, "-n", testNamespace)
+			_, _ = utils.Run(cmd)
+			getAllocatedPods(bsFirst, testNamespace, 2)
+			Consistently(func(g Gomega) {
+				allocs := getAllocations(g, testNamespace, bsFirst, bsSecond)
+				g.Expect(allocs[bsFirst]).To(HaveLen(2), "the first BatchSandbox keeps its pods")
+				g.Expect(allocs[bsSecond]).To(BeEmpty(), "the second BatchSandbox waits for the first")
+			}, 15*time.Second, 3*time.Second).Should(Succeed())
+
+			By("releasing the first BatchSandbox and verifying the second one is served")
+			cmd = exec.Command("kubectl", "delete", "batchsandbox", bsFirst, "-n", testNamespace)
+			_, _ = utils.Run(cmd)
+			getAllocatedPods(bsSecond, testNamespace, 2)
+
+			By("cleaning up")
+			cmd = exec.Command("kubectl", "delete", "batchsandbox", bsSecond, "-n", testNamespace, "--wait=false")
+			_, _ = utils.Run(cmd)
+			cmd = exec.Command("kubectl", "delete", "pool", poolName, "-n", testNamespace)
+			_, _ = utils.Run(cmd)
+		})
+
+		It("should scale up by the PodSupplement of waiting BatchSandboxes within poolMax", func() {
+			const poolName = "test-contention-supply"
+			bsNames := []string{"test-contention-supply-1", "test-contention-supply-2", "test-contention-supply-3"}
+
+			By("creating a Pool with a buffer of one pod and room for five")
+			applyTemplate("testdata/pool-basic.yaml", map[string]interface{}{
+				"PoolName":     poolName,
+				"SandboxImage": utils.SandboxImage,
+				"Namespace":    testNamespace,
+				"BufferMax":    1,
+				"BufferMin":    1,
+				"PoolMax":      5,
+				"PoolMin":      1,
+			}, poolName+".yaml")
+			waitPoolStable(poolName, testNamespace, 3*time.Minute)
+
+			By("creating three BatchSandboxes asking for six pods in total")
+			for i, name := range bsNames {
+				applyTemplate("testdata/batchsandbox-pooled-no-expire.yaml", map[string]interface{}{
+					"BatchSandboxName": name,
+					"Namespace":        testNamespace,
+					"Replicas":         2,
+					"PoolName":         poolName,
+				}, name+".yaml")
+				if i < len(bsNames)-1 {
+					time.Sleep(2 * time.Second)
+				}
+			}
+
+			By("verifying the pool grows to poolMax and the first two BatchSandboxes are served")
+			Eventually(func(g Gomega) {
+				allocs := getAllocations(g, testNamespace, bsNames...)
+				g.Expect(allocs[bsNames[0]]).To(HaveLen(2))
+				g.Expect(allocs[bsNames[1]]).To(HaveLen(2))
+				g.Expect(poolStatusField(g, poolName, testNamespace, "total")).To(Equal(5))
+			}, 3*time.Minute).Should(Succeed())
+
+			By("verifying the pool stays within poolMax and the last BatchSandbox only gets the spare pod")
+			Consistently(func(g Gomega) {
+				allocs := getAllocations(g, testNamespace, bsNames...)
+				g.Expect(len(allocs[bsNames[2]])).To(BeNumerically("<=", 1))
+				g.Expect(poolStatusField(g, poolName, testNamespace, "total")).To(BeNumerically("<=", 5))
+				expectNoDoubleAllocation(g, allocs)
+			}, 20*time.Second, 2*time.Second).Should(Succeed())
+
+			By("releasing the first BatchSandbox and verifying the last one is completed")
+			cmd := exec.Command("kubectl", "delete", "batchsandbox", bsNames[0], "-n", testNamespace)
+			_, _ = utils.Run(cmd)
+			getAllocatedPods(bsNames[2], testNamespace, 2)
+
+			By("cleaning up")
+			for _, name := range bsNames[1:] {
+				cmd := exec.Command("kubectl", "delete", "batchsandbox", name, "-n", testNamespace, "--wait=false")
+				_, _ = utils.Run(cmd)
+			}
+			cmd = exec.Command("kubectl", "delete", "pool", poolName, "-n", testNamespace)
+			_, _ = utils.Run(cmd)
+		})
+
+		It("should never allocate a pod twice while BatchSandboxes churn on an undersized pool", func() {
+			const poolName = "test-contention-churn"
+			const poolMax = 3
+			var bsNames []string
+			for i := range 5 {
+				bsNames = append(bsNames, fmt.Sprintf("test-contention-churn-%d", i))
+			}
+
+			By("creating a Pool of three pods that keeps released pods (Noop)")
+			applyTemplate("testdata/pool-with-recycle.yaml", map[string]interface{}{
+				"PoolName":     poolName,
+				"SandboxImage": utils.SandboxImage,
+				"Namespace":    testNamespace,
+				"BufferMax":    poolMax,
+				"BufferMin":    poolMax,
+				"PoolMax":      poolMax,
+				"PoolMin":      poolMax,
+				"RecycleType":  "Noop",
+				"Command":      `["sleep", "3600"]`,
+			}, poolName+".yaml")
+			waitPoolStable(poolName, testNamespace, 3*time.Minute)
+
+			By("creating five BatchSandboxes of two pods at once")
+			for _, name := range bsNames {
+				applyTemplate("testdata/batchsandbox-pooled-no-expire.yaml", map[string]interface{}{
+					"BatchSandboxName": name,
+					"Namespace":        testNamespace,
+					"Replicas":         2,
+					"PoolName":         poolName,
+				}, name+".yaml")
+			}
+
+			By("releasing each BatchSandbox once it is served, checking allocations along the way")
+			remaining := map[string]bool{}
+			for _, name := range bsNames {
+				remaining[name] = true
+			}
+			deadline := time.Now().Add(5 * time.Minute)
+			for len(remaining) > 0 {
+				Expect(time.Now()).To(BeTemporally("<", deadline), "BatchSandboxes still waiting: %v", remaining)
+				names := make([]string, 0, len(remaining))
+				for name := range remaining {
+					names = append(names, name)
+				}
+				allocs := getAllocations(Default, testNamespace, names...)
+				total := expectNoDoubleAllocation(Default, allocs)
+				Expect(total).To(BeNumerically("<=", poolMax), "more pods allocated than the pool holds")
+				Expect(poolStatusField(Default, poolName, testNamespace, "total")).To(BeNumerically("<=", poolMax))
+				for name, pods := range allocs {
+					if len(pods) == 2 {
+						cmd := exec.Command("kubectl", "delete", "batchsandbox", name, "-n", testNamespace, "--wait=false")
+						_, err := utils.Run(cmd)
+						Expect(err).NotTo(HaveOccurred())
+						delete(remaining, name)
+					}
+				}
+				time.Sleep(2 * time.Second)
+			}
+
+			By("verifying every pod is back in the pool")
+			Eventually(func(g Gomega) {
+				g.Expect(poolStatusField(g, poolName, testNamespace, "allocated")).To(Equal(0))
+			}, 2*time.Minute).Should(Succeed())
+
+			By("cleaning up")
+			cmd := exec.Command("kubectl", "delete", "pool", poolName, "-n", testNamespace)
+			_, _ = utils.Run(cmd)
+		})
+	})
+
 })
 
+// waitObservedGeneration waits until the controller has observed the latest spec of a Pool or BatchSandbox,
+// so that status counters read afterwards do not describe a previous generation.
+func waitObservedGeneration(resource, name, testNamespace string, timeout time.Duration) {
+	Eventually(func(g Gomega) {
+		g.Expect(observedGeneration(resource, name, testNamespace)).To(Succeed())
+	}, timeout).Should(Succeed())
+}
+
+func observedGeneration(resource, name, testNamespace string) error {
+	cmd := exec.Command("kubectl", "get", resource, name, "-n", testNamespace,
+		"-o", "jsonpath={.metadata.generation} {.status.observedGeneration}")
+	out, err := utils.Run(cmd)
+	if err != nil {
+		return err
+	}
+	var generation, observed int64
+	fmt.Sscanf(out, "%d %d", &generation, &observed)
+	if observed < generation {
+		return fmt.Errorf("%s %s/%s observed generation %d, want %d", resource, testNamespace, name, observed, generation)
+	}
+	return nil
+}
+
 // waitPoolStable waits until pool.status.available + pool.status.allocated == pool.status.total,
 // ensuring all pool pods are either ready or allocated before proceeding.
 func waitPoolStable(poolName, testNamespace string, timeout time.Duration) {
 	Eventually(func(g Gomega) {
+		g.Expect(observedGeneration("pool", poolName, testNamespace)).To(Succeed())
+
 		cmd := exec.Command("kubectl", "get", "pool", poolName, "-n", testNamespace,
 			"-o", "jsonpath={.status.total}")
 		totalStr, err := utils.Run(cmd)
@@ -4413,6 +4657,51 @@ func getAllocatedPods(bsName, testNamespace string, expectedLen int) []string {
 	return pods
 }
 
+// getAllocations reads the alloc-status annotation of each BatchSandbox, with no pods for
+// BatchSandboxes that have none allocated or no longer exist.
+func getAllocations(g Gomega, testNamespace string, bsNames ...string) map[string][]string {
+	allocs := make(map[string][]string, len(bsNames))
+	for _, name := range bsNames {
+		cmd := exec.Command("kubectl", "get", "batchsandbox", name, "-n", testNamespace, "--ignore-not-found",
+			"-o", "jsonpath={.metadata.annotations.sandbox\\.opensandbox\\.io/alloc-status}")
+		out, err := utils.Run(cmd)
+		g.Expect(err).NotTo(HaveOccurred())
+		var alloc struct {
+			Pods []string `json:"pods"`
+		}
+		if out != "" {
+			g.Expect(json.Unmarshal([]byte(out), &alloc)).To(Succeed())
+		}
+		allocs[name] = alloc.Pods
+	}
+	return allocs
+}
+
+// expectNoDoubleAllocation fails when a pod is allocated to more than one BatchSandbox, and returns
+// the number of allocated pods.
+func expectNoDoubleAllocation(g Gomega, allocs map[string][]string) int {
+	owners := make(map[string]string)
+	for name, pods := range allocs {
+		for _, pod := range pods {
+			existing, dup := owners[pod]
+			g.Expect(dup).To(BeFalse(), "pod %s is allocated to both %s and %s", pod, existing, name)
+			owners[pod] = name
+		}
+	}
+	return len(owners)
+}
+
+// poolStatusField reads an integer field of pool.status.
+func poolStatusField(g Gomega, poolName, testNamespace, field string) int {
+	cmd := exec.Command("kubectl", "get", "pool", poolName, "-n", testNamespace,
+		"-o", "jsonpath={.status."+field+"}")
+	out, err := utils.Run(cmd)
+	g.Expect(err).NotTo(HaveOccurred())
+	value := 0
+	fmt.Sscanf(out, "%d", &value)
+	return value
+}
+
 // applyTemplate renders and applies a template, returning a cleanup func.
 func applyTemplate(templateFile string, data map[string]interface{}, tmpFile string) {
 	yaml, err := renderTemplate(templateFile, data)
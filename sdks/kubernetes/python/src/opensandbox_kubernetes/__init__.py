@@ -0,0 +1,94 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+OpenSandbox Kubernetes Python SDK
+
+Drive OpenSandbox on Kubernetes directly: create BatchSandboxes through the
+Kubernetes API, discover their endpoints, submit tasks to the task-executor,
+stream logs and move files in and out of sandbox pods.
+
+## Basic Usage
+
+```python
+from opensandbox_kubernetes import (
+    BatchSandboxClient,
+    PodIO,
+    Process,
+    Task,
+    TaskExecutorClient,
+    load_api_client,
+)
+
+api = load_api_client()
+sandboxes = BatchSandboxClient(api, namespace="agents")
+sandboxes.create("run-1", replicas=1, pool_ref="python-pool")
+endpoint = sandboxes.wait_ready("run-1")[0]
+
+pod = sandboxes.pods("run-1")[0]
+PodIO(api, namespace="agents").upload(
+    pod, b"print('hello')", "/tmp/main.py", container="sandbox"
+)
+
+with TaskExecutorClient.for_endpoint(endpoint) as executor:
+    executor.create_task(
+        Task(name="main", process=Process(command=["python", "/tmp/main.py"]))
+    )
+    print(executor.wait_task("main").process_status)
+
+sandboxes.delete("run-1")
+```
+"""
+
+from opensandbox_kubernetes.batch_sandbox import (
+    BatchSandboxClient,
+    batch_sandbox_manifest,
+    load_api_client,
+)
+from opensandbox_kubernetes.exceptions import (
+    BatchSandboxNotReadyException,
+    KubernetesSandboxException,
+    PodCommandException,
+    TaskExecutorException,
+)
+from opensandbox_kubernetes.models import (
+    BatchSandboxStatus,
+    EnvVar,
+    PodTaskResult,
+    Process,
+    ProcessStatus,
+    Task,
+)
+from opensandbox_kubernetes.pod_io import PodIO
+from opensandbox_kubernetes.task_executor import ManagerTaskClient, TaskExecutorClient
+
+__all__ = [
+    "BatchSandboxClient",
+    "BatchSandboxNotReadyException",
+    "BatchSandboxStatus",
+    "EnvVar",
+    "KubernetesSandboxException",
+    "ManagerTaskClient",
+    "PodCommandException",
+    "PodIO",
+    "PodTaskResult",
+    "Process",
+    "ProcessStatus",
+    "Task",
+    "TaskExecutorClient",
+    "TaskExecutorException",
+    "batch_sandbox_manifest",
+    "load_api_client",
+]
@@ -0,0 +1,230 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+BatchSandbox lifecycle and endpoint discovery through the Kubernetes API.
+"""
+
+from __future__ import annotations
+
+import json
+import time
+from datetime import datetime, timezone
+from typing import Any
+
+from kubernetes import client, config
+
+from opensandbox_kubernetes.constants import (
+    ALLOCATION_PLURAL,
+    ANNOTATION_ALLOC_STATUS,
+    ANNOTATION_ALLOC_STORE,
+    ANNOTATION_ENDPOINTS,
+    BATCH_SANDBOX_PLURAL,
+    GROUP,
+    LABEL_BATCH_SANDBOX_NAME,
+    VERSION,
+)
+from opensandbox_kubernetes.exceptions import (
+    BatchSandboxNotReadyException,
+    KubernetesSandboxException,
+)
+from opensandbox_kubernetes.models import BatchSandboxStatus, Process
+
+
+def load_api_client(context: str | None = None) -> client.ApiClient:
+    """
+    Build an API client from the in-cluster service account, falling back to
+    the local kubeconfig.
+    """
+    try:
+        config.load_incluster_config()
+    except config.ConfigException:
+        config.load_kube_config(context=context)
+    return client.ApiClient()
+
+
+def batch_sandbox_manifest(
+    name: str,
+    *,
+    namespace: str,
+    replicas: int = 1,
+    pool_ref: str | None = None,
+    reservation_ref: str | None = None,
+    template: dict[str, Any] | None = None,
+    task: Process | None = None,
+    expire_time: datetime | None = None,
+    labels: dict[str, str] | None = None,
+    annotations: dict[str, str] | None = None,
+) -> dict[str, Any]:
+    """
+    Build a BatchSandbox manifest. Either `pool_ref` or a pod `template` is
+    required; `task` runs the same process on every pod. `reservation_ref`
+    takes the pods from a Reservation of the pool first.
+    """
+    if (pool_ref is None) == (template is None):
+        raise ValueError("exactly one of pool_ref and template is required")
+    if reservation_ref is not None and pool_ref is None:
+        raise ValueError("reservation_ref requires pool_ref")
+    spec: dict[str, Any] = {"replicas": replicas}
+    if pool_ref is not None:
+        spec["poolRef"] = pool_ref
+    if reservation_ref is not None:
+        spec["reservationRef"] = reservation_ref
+    if template is not None:
+        spec["template"] = template
+    if task is not None:
+        spec["taskTemplate"] = {"spec": {"process": task.to_json_dict()}}
+    if expire_time is not None:
+        if expire_time.tzinfo is None:
+            expire_time = expire_time.replace(tzinfo=timezone.utc)
+        spec["expireTime"] = (
+            expire_time.astimezone(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ")
+        )
+    metadata: dict[str, Any] = {"name": name, "namespace": namespace}
+    if labels:
+        metadata["labels"] = labels
+    if annotations:
+        metadata["annotations"] = annotations
+    return {
+        "apiVersion": f"{GROUP}/{VERSION}",
+        "kind": "BatchSandbox",
+        "metadata": metadata,
+        "spec": spec,
+    }
+
+
+class BatchSandboxClient:
+    """
+    Creates BatchSandboxes and discovers their pods and endpoints.
+
+    ```python
+    sandboxes = BatchSandboxClient(load_api_client(), namespace="agents")
+    sandboxes.create("run-1", replicas=2, pool_ref="python-pool")
+    endpoints = sandboxes.wait_ready("run-1", timeout=120)
+    ```
+    """
+
+    def __init__(
+        self, api_client: client.ApiClient | None = None, namespace: str = "default"
+    ) -> None:
+        self.namespace = namespace
+        self._custom = client.CustomObjectsApi(api_client)
+        self._core = client.CoreV1Api(api_client)
+
+    def create(self, name: str, **kwargs: Any) -> dict[str, Any]:
+        """
+        Create a BatchSandbox. Keyword arguments are those of
+        `batch_sandbox_manifest`.
+        """
+        body = batch_sandbox_manifest(name, namespace=self.namespace, **kwargs)
+        return self._custom.create_namespaced_custom_object(
+            GROUP, VERSION, self.namespace, BATCH_SANDBOX_PLURAL, body
+        )
+
+    def get(self, name: str) -> dict[str, Any]:
+        return self._custom.get_namespaced_custom_object(
+            GROUP, VERSION, self.namespace, BATCH_SANDBOX_PLURAL, name
+        )
+
+    def delete(self, name: str) -> None:
+        self._custom.delete_namespaced_custom_object(
+            GROUP, VERSION, self.namespace, BATCH_SANDBOX_PLURAL, name
+        )
+
+    def status(self, name: str) -> BatchSandboxStatus:
+        return BatchSandboxStatus.model_validate(self.get(name).get("status") or {})
+
+    def endpoints(self, name: str) -> list[str]:
+        """
+        The pod IPs of the BatchSandbox. Empty until the controller has
+        published them.
+        """
+        return _endpoints(self.get(name))
+
+    def pods(self, name: str) -> list[str]:
+        """
+        The pod names of the BatchSandbox: the allocated pool pods for a pooled
+        BatchSandbox, the pods created for it otherwise.
+        """
+        obj = self.get(name)
+        if obj.get("spec", {}).get("poolRef"):
+            if _annotations(obj).get(ANNOTATION_ALLOC_STORE) == "allocation":
+                allocation = self._custom.get_namespaced_custom_object(
+                    GROUP, VERSION, self.namespace, ALLOCATION_PLURAL, name
+                )
+                return list(allocation.get("spec", {}).get("pods") or [])
+            raw = _annotations(obj).get(ANNOTATION_ALLOC_STATUS)
+            if not raw:
+                return []
+            return list(_parse_json(raw, ANNOTATION_ALLOC_STATUS).get("pods") or [])
+        pods = self._core.list_namespaced_pod(
+            self.namespace, label_selector=f"{LABEL_BATCH_SANDBOX_NAME}={name}"
+        )
+        return sorted(p.metadata.name for p in pods.items)
+
+    def wait_ready(
+        self, name: str, timeout: float = 120, interval: float = 1
+    ) -> list[str]:
+        """
+        Wait until all replicas are ready and their endpoints are published,
+        then return the endpoints. The status is only trusted once the
+        controller has observed the current generation of the spec.
+        """
+        deadline = time.monotonic() + timeout
+        while True:
+            obj = self.get(name)
+            replicas = obj.get("spec", {}).get("replicas", 1)
+            status = BatchSandboxStatus.model_validate(obj.get("status") or {})
+            endpoints = _endpoints(obj)
+            observed = _observed(obj, status)
+            if observed and status.phase == "Failed":
+                raise BatchSandboxNotReadyException(f"BatchSandbox {name} failed")
+            if observed and status.ready >= replicas and len(endpoints) >= replicas:
+                return endpoints
+            if time.monotonic() >= deadline:
+                raise BatchSandboxNotReadyException(
+                    f"BatchSandbox {name} not ready after {timeout}s: "
+                    f"{status.ready}/{replicas} ready"
+                )
+            time.sleep(interval)
+
+
+def _observed(obj: dict[str, Any], status: BatchSandboxStatus) -> bool:
+    """
+    Whether the controller has observed the current spec, i.e. the status
+    counters are not left over from a previous generation.
+    """
+    generation = obj.get("metadata", {}).get("generation")
+    if generation is None:
+        return True
+    return (status.observed_generation or 0) >= generation
+
+
+def _annotations(obj: dict[str, Any]) -> dict[str, str]:
+    return obj.get("metadata", {}).get("annotations") or {}
+
+
+def _endpoints(obj: dict[str, Any]) -> list[str]:
+    raw = _annotations(obj).get(ANNOTATION_ENDPOINTS)
+    if not raw:
+        return []
+    return list(_parse_json(raw, ANNOTATION_ENDPOINTS) or [])
+
+
+def _parse_json(raw: str, key: str) -> Any:
+    try:
+        return json.loads(raw)
+    except json.JSONDecodeError as e:
+        raise KubernetesSandboxException(f"malformed {key} annotation", e) from e
@@ -0,0 +1,38 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+Constants shared with the OpenSandbox Kubernetes controller and task-executor.
+"""
+
+GROUP = "sandbox.opensandbox.io"
+VERSION = "v1alpha1"
+BATCH_SANDBOX_PLURAL = "batchsandboxes"
+POOL_PLURAL = "pools"
+ALLOCATION_PLURAL = "allocations"
+
+# Annotations the controller writes on a BatchSandbox.
+ANNOTATION_ENDPOINTS = "sandbox.opensandbox.io/endpoints"
+ANNOTATION_ALLOC_STATUS = "sandbox.opensandbox.io/alloc-status"
+# Set to "allocation" once the allocation lives in the Allocation of the same
+# name instead of ANNOTATION_ALLOC_STATUS.
+ANNOTATION_ALLOC_STORE = "sandbox.opensandbox.io/alloc-store"
+
+# Label the controller puts on the pods it creates for a BatchSandbox.
+LABEL_BATCH_SANDBOX_NAME = "batch-sandbox.sandbox.opensandbox.io/name"
+
+TASK_EXECUTOR_PORT = 5758
+TASK_EXECUTOR_CONTAINER = "task-executor"
+TASK_EXECUTOR_DATA_DIR = "/var/lib/sandbox/tasks"
@@ -0,0 +1,55 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+Exceptions raised by the OpenSandbox Kubernetes SDK.
+"""
+
+
+class KubernetesSandboxException(Exception):
+    """
+    Base exception for all errors raised by this SDK.
+    """
+
+    def __init__(self, message: str | None = None, cause: Exception | None = None) -> None:
+        super().__init__(message)
+        self.__cause__ = cause
+
+
+class BatchSandboxNotReadyException(KubernetesSandboxException):
+    """
+    Raised when a BatchSandbox does not become ready in time.
+    """
+
+
+class TaskExecutorException(KubernetesSandboxException):
+    """
+    Raised when the task-executor API returns an error.
+    """
+
+    def __init__(
+        self,
+        message: str | None = None,
+        status_code: int | None = None,
+        cause: Exception | None = None,
+    ) -> None:
+        super().__init__(message, cause)
+        self.status_code = status_code
+
+
+class PodCommandException(KubernetesSandboxException):
+    """
+    Raised when a command executed in a pod for file transfer or log streaming fails.
+    """
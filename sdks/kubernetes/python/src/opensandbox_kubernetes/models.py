@@ -0,0 +1,111 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+Models mirroring the task-executor API (`pkg/task-executor` in the Kubernetes
+controller) and the BatchSandbox resource.
+"""
+
+from datetime import datetime
+from typing import Any
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class _Model(BaseModel):
+    model_config = ConfigDict(populate_by_name=True, extra="ignore")
+
+    def to_json_dict(self) -> dict[str, Any]:
+        """Serialize with API field names, dropping unset fields."""
+        return self.model_dump(by_alias=True, exclude_none=True, mode="json")
+
+
+class EnvVar(_Model):
+    name: str
+    value: str | None = None
+
+
+class Process(_Model):
+    """A process task run by the task-executor in the sandbox container."""
+
+    command: list[str]
+    args: list[str] | None = None
+    env: list[EnvVar] | None = None
+    working_dir: str | None = Field(default=None, alias="workingDir")
+    timeout_seconds: int | None = Field(default=None, alias="timeoutSeconds")
+
+
+class Waiting(_Model):
+    reason: str | None = None
+    message: str | None = None
+
+
+class Running(_Model):
+    started_at: datetime | None = Field(default=None, alias="startedAt")
+
+
+class Terminated(_Model):
+    exit_code: int = Field(alias="exitCode")
+    signal: int | None = None
+    reason: str | None = None
+    message: str | None = None
+    started_at: datetime | None = Field(default=None, alias="startedAt")
+    finished_at: datetime | None = Field(default=None, alias="finishedAt")
+
+
+class ProcessStatus(_Model):
+    """Only one of the states is set; no state means waiting."""
+
+    waiting: Waiting | None = None
+    running: Running | None = None
+    terminated: Terminated | None = None
+
+
+class Task(_Model):
+    """A task on a single sandbox pod."""
+
+    name: str
+    deletion_timestamp: datetime | None = Field(default=None, alias="deletionTimestamp")
+    process: Process | None = None
+    pod_template_spec: dict[str, Any] | None = Field(default=None, alias="podTemplateSpec")
+    process_status: ProcessStatus | None = Field(default=None, alias="processStatus")
+    pod_status: dict[str, Any] | None = Field(default=None, alias="podStatus")
+
+    @property
+    def finished(self) -> bool:
+        """Whether the process has terminated."""
+        return self.process_status is not None and self.process_status.terminated is not None
+
+
+class PodTaskResult(_Model):
+    """The outcome of a manager API dispatch for one pod."""
+
+    index: int
+    pod: str
+    task: Task | None = None
+    error: str | None = None
+
+
+class BatchSandboxStatus(_Model):
+    observed_generation: int | None = Field(default=None, alias="observedGeneration")
+    replicas: int = 0
+    allocated: int = 0
+    ready: int = 0
+    task_running: int = Field(default=0, alias="taskRunning")
+    task_succeed: int = Field(default=0, alias="taskSucceed")
+    task_failed: int = Field(default=0, alias="taskFailed")
+    task_pending: int = Field(default=0, alias="taskPending")
+    task_unknown: int = Field(default=0, alias="taskUnknown")
+    phase: str | None = None
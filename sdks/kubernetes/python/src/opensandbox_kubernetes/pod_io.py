@@ -0,0 +1,190 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+Log streaming and file transfer for sandbox pods through the Kubernetes API.
+Works from outside the cluster since everything goes through the API server.
+"""
+
+from __future__ import annotations
+
+import base64
+import codecs
+import shlex
+from collections.abc import Iterable, Iterator
+from typing import Any
+
+from kubernetes import client
+from kubernetes.stream import stream
+
+from opensandbox_kubernetes.constants import (
+    TASK_EXECUTOR_CONTAINER,
+    TASK_EXECUTOR_DATA_DIR,
+)
+from opensandbox_kubernetes.exceptions import PodCommandException
+
+# Base64 text written to stdin per websocket frame on upload.
+_UPLOAD_CHUNK = 1 << 16
+
+
+class PodIO:
+    """
+    Streams logs from and copies files to and from sandbox pods.
+
+    ```python
+    io = PodIO(load_api_client(), namespace="agents")
+    io.upload("run-1-0", b"print('hi')", "/workspace/main.py", container="sandbox")
+    for line in io.stream_task_logs("run-1-0", "build"):
+        print(line)
+    ```
+    """
+
+    def __init__(
+        self, api_client: client.ApiClient | None = None, namespace: str = "default"
+    ) -> None:
+        self.namespace = namespace
+        self._core = client.CoreV1Api(api_client)
+        self._stream = stream
+
+    def stream_logs(
+        self,
+        pod: str,
+        container: str | None = None,
+        follow: bool = True,
+        since_seconds: int | None = None,
+    ) -> Iterator[str]:
+        """Yield the log lines of a pod container."""
+        kwargs: dict[str, Any] = {"follow": follow, "_preload_content": False}
+        if container:
+            kwargs["container"] = container
+        if since_seconds is not None:
+            kwargs["since_seconds"] = since_seconds
+        resp = self._core.read_namespaced_pod_log(pod, self.namespace, **kwargs)
+        try:
+            yield from _lines(resp.stream(amt=_UPLOAD_CHUNK, decode_content=True))
+        finally:
+            resp.release_conn()
+
+    def stream_task_logs(
+        self,
+        pod: str,
+        task: str,
+        follow: bool = True,
+        stderr: bool = False,
+        container: str = TASK_EXECUTOR_CONTAINER,
+        data_dir: str = TASK_EXECUTOR_DATA_DIR,
+    ) -> Iterator[str]:
+        """
+        Yield the output lines of a task-executor process task. The
+        task-executor keeps task output in its data directory, so this tails
+        the file in the executor container.
+        """
+        name = "stderr.log" if stderr else "stdout.log"
+        path = f"{data_dir.rstrip('/')}/{task}/{name}"
+        command = ["tail", "-n", "+1"] + (["-F"] if follow else []) + [path]
+        resp = self._exec(pod, container, command)
+        try:
+
+            def chunks() -> Iterator[str]:
+                while resp.is_open():
+                    resp.update(timeout=1)
+                    if resp.peek_stdout():
+                        yield resp.read_stdout()
+                yield resp.read_stdout()
+
+            yield from _lines(chunks())
+        finally:
+            resp.close()
+        _check_exit(resp, command)
+
+    def upload(
+        self, pod: str, data: bytes, remote_path: str, container: str | None = None
+    ) -> None:
+        """Write `data` to `remote_path` in the pod, replacing the file."""
+        encoded = base64.b64encode(data).decode("ascii")
+        # head reads exactly the bytes we send, so stdin never needs to be
+        # closed, which older exec protocols cannot signal.
+        script = 'head -c "$0" | base64 -d > "$1"'
+        command = ["sh", "-c", script, str(len(encoded)), remote_path]
+        resp = self._exec(pod, container, command, stdin=True)
+        try:
+            for i in range(0, len(encoded), _UPLOAD_CHUNK):
+                resp.write_stdin(encoded[i : i + _UPLOAD_CHUNK])
+            while resp.is_open():
+                resp.update(timeout=1)
+        finally:
+            resp.close()
+        _check_exit(resp, command)
+
+    def download(
+        self, pod: str, remote_path: str, container: str | None = None
+    ) -> bytes:
+        """Read `remote_path` from the pod."""
+        command = ["base64", remote_path]
+        resp = self._exec(pod, container, command)
+        out: list[str] = []
+        try:
+            while resp.is_open():
+                resp.update(timeout=1)
+                if resp.peek_stdout():
+                    out.append(resp.read_stdout())
+            out.append(resp.read_stdout())
+        finally:
+            resp.close()
+        _check_exit(resp, command)
+        return base64.b64decode("".join(out))
+
+    def _exec(
+        self,
+        pod: str,
+        container: str | None,
+        command: list[str],
+        stdin: bool = False,
+    ) -> Any:
+        kwargs: dict[str, Any] = {
+            "command": command,
+            "stdin": stdin,
+            "stdout": True,
+            "stderr": True,
+            "tty": False,
+            "_preload_content": False,
+        }
+        if container:
+            kwargs["container"] = container
+        return self._stream(
+            self._core.connect_get_namespaced_pod_exec, pod, self.namespace, **kwargs
+        )
+
+
+def _check_exit(resp: Any, command: list[str]) -> None:
+    code = resp.returncode
+    if code:
+        raise PodCommandException(
+            f"{shlex.join(command)} exited with {code}: {resp.read_stderr().strip()}"
+        )
+
+
+def _lines(chunks: Iterable[str | bytes]) -> Iterator[str]:
+    """Reassemble lines from arbitrarily split chunks."""
+    decoder = codecs.getincrementaldecoder("utf-8")(errors="replace")
+    pending = ""
+    for chunk in chunks:
+        if isinstance(chunk, bytes):
+            chunk = decoder.decode(chunk)
+        pending += chunk
+        *lines, pending = pending.split("\n")
+        yield from lines
+    if pending:
+        yield pending
@@ -0,0 +1,188 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""
+Clients for submitting tasks: directly to the task-executor in a sandbox pod,
+or through the manager API for callers that cannot reach pod IPs.
+"""
+
+from __future__ import annotations
+
+import time
+from typing import Any
+
+import httpx
+
+from opensandbox_kubernetes.constants import TASK_EXECUTOR_PORT
+from opensandbox_kubernetes.exceptions import TaskExecutorException
+from opensandbox_kubernetes.models import PodTaskResult, Task
+
+
+def _check(resp: httpx.Response) -> httpx.Response:
+    if resp.is_success:
+        return resp
+    message = resp.text
+    try:
+        # task-executor errors carry "message", manager API errors "error"
+        body = resp.json()
+        message = body.get("message") or body.get("error") or message
+    except (ValueError, AttributeError):
+        pass
+    raise TaskExecutorException(
+        f"{resp.request.method} {resp.request.url.path} failed: {message}",
+        status_code=resp.status_code,
+    )
+
+
+class TaskExecutorClient:
+    """
+    Client for the task-executor HTTP API of a single sandbox pod.
+
+    ```python
+    with TaskExecutorClient.for_endpoint("10.244.1.5") as executor:
+        executor.create_task(Task(name="build", process=Process(command=["make"])))
+        task = executor.wait_task("build", timeout=300)
+    ```
+    """
+
+    def __init__(
+        self,
+        base_url: str,
+        *,
+        timeout: float = 30,
+        transport: httpx.BaseTransport | None = None,
+    ) -> None:
+        self._client = httpx.Client(
+            base_url=base_url, timeout=timeout, transport=transport
+        )
+
+    @classmethod
+    def for_endpoint(cls, ip: str, **kwargs: Any) -> TaskExecutorClient:
+        """Client for the executor serving on a BatchSandbox endpoint."""
+        host = f"[{ip}]" if ":" in ip else ip
+        return cls(f"http://{host}:{TASK_EXECUTOR_PORT}", **kwargs)
+
+    def close(self) -> None:
+        self._client.close()
+
+    def __enter__(self) -> TaskExecutorClient:
+        return self
+
+    def __exit__(self, *exc: object) -> None:
+        self.close()
+
+    def create_task(self, task: Task) -> Task:
+        resp = _check(self._client.post("/tasks", json=task.to_json_dict()))
+        return Task.model_validate(resp.json())
+
+    def get_task(self, name: str) -> Task | None:
+        """Return the task, or None if the executor does not know it."""
+        resp = self._client.get(f"/tasks/{name}")
+        if resp.status_code == httpx.codes.NOT_FOUND:
+            return None
+        return Task.model_validate(_check(resp).json())
+
+    def list_tasks(self) -> list[Task]:
+        resp = _check(self._client.get("/getTasks"))
+        return [Task.model_validate(t) for t in resp.json() or []]
+
+    def sync_tasks(self, tasks: list[Task]) -> list[Task]:
+        """
+        Replace the desired tasks of the pod. Tasks missing from `tasks` are
+        stopped and removed.
+        """
+        resp = _check(
+            self._client.post("/setTasks", json=[t.to_json_dict() for t in tasks])
+        )
+        return [Task.model_validate(t) for t in resp.json() or []]
+
+    def delete_task(self, name: str) -> None:
+        _check(self._client.delete(f"/tasks/{name}"))
+
+    def health(self) -> bool:
+        try:
+            return self._client.get("/health").is_success
+        except httpx.HTTPError:
+            return False
+
+    def wait_task(self, name: str, timeout: float = 300, interval: float = 1) -> Task:
+        """Poll until the task has terminated and return it."""
+        deadline = time.monotonic() + timeout
+        while True:
+            task = self.get_task(name)
+            if task is None:
+                raise TaskExecutorException(f"task {name} not found", status_code=404)
+            if task.finished:
+                return task
+            if time.monotonic() >= deadline:
+                raise TaskExecutorException(
+                    f"task {name} did not finish after {timeout}s"
+                )
+            time.sleep(interval)
+
+
+class ManagerTaskClient:
+    """
+    Client for the task dispatch routes of the controller manager API. Each
+    call fans out to the task-executors of a BatchSandbox's pods; entry `i`
+    of `tasks` goes to pod `i`.
+    """
+
+    def __init__(
+        self,
+        base_url: str,
+        token: str,
+        *,
+        timeout: float = 60,
+        transport: httpx.BaseTransport | None = None,
+    ) -> None:
+        self._client = httpx.Client(
+            base_url=base_url,
+            timeout=timeout,
+            transport=transport,
+            headers={"Authorization": f"Bearer {token}"},
+        )
+
+    def close(self) -> None:
+        self._client.close()
+
+    def __enter__(self) -> ManagerTaskClient:
+        return self
+
+    def __exit__(self, *exc: object) -> None:
+        self.close()
+
+    def dispatch(
+        self, namespace: str, name: str, tasks: list[Task | None]
+    ) -> list[PodTaskResult]:
+        """
+        Submit tasks to the pods of a BatchSandbox. A None entry leaves its pod
+        untouched.
+        """
+        body = {"tasks": [t.to_json_dict() if t else None for t in tasks]}
+        resp = _check(self._client.post(self._path(namespace, name), json=body))
+        return _results(resp)
+
+    def sync(self, namespace: str, name: str) -> list[PodTaskResult]:
+        """Read the current task of every pod of a BatchSandbox."""
+        return _results(_check(self._client.get(self._path(namespace, name))))
+
+    @staticmethod
+    def _path(namespace: str, name: str) -> str:
+        return f"/v1/namespaces/{namespace}/batchsandboxes/{name}/tasks"
+
+
+def _results(resp: httpx.Response) -> list[PodTaskResult]:
+    return [PodTaskResult.model_validate(p) for p in resp.json().get("pods") or []]
@@ -0,0 +1,178 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+from datetime import datetime, timedelta, timezone
+from types import SimpleNamespace
+from typing import Any
+
+import pytest
+
+from opensandbox_kubernetes import (
+    BatchSandboxClient,
+    BatchSandboxNotReadyException,
+    Process,
+    batch_sandbox_manifest,
+)
+
+
+class FakeCustomObjects:
+    def __init__(self, objects: list[dict[str, Any]]) -> None:
+        self.objects = objects
+        self.created: list[dict[str, Any]] = []
+
+    def create_namespaced_custom_object(self, group, version, namespace, plural, body):
+        self.created.append(body)
+        return body
+
+    def get_namespaced_custom_object(self, group, version, namespace, plural, name):
+        return self.objects.pop(0) if len(self.objects) > 1 else self.objects[0]
+
+
+class FakeCore:
+    def __init__(self, names: list[str]) -> None:
+        self.names = names
+        self.selector = ""
+
+    def list_namespaced_pod(self, namespace, label_selector):
+        self.selector = label_selector
+        return SimpleNamespace(
+            items=[SimpleNamespace(metadata=SimpleNamespace(name=n)) for n in self.names]
+        )
+
+
+def _client(objects: list[dict[str, Any]], pods: list[str] | None = None):
+    c = BatchSandboxClient.__new__(BatchSandboxClient)
+    c.namespace = "agents"
+    c._custom = FakeCustomObjects(objects)
+    c._core = FakeCore(pods or [])
+    return c
+
+
+def test_manifest() -> None:
+    expire = datetime(2026, 1, 1, 8, 0, tzinfo=timezone(timedelta(hours=8)))
+    body = batch_sandbox_manifest(
+        "run-1",
+        namespace="agents",
+        replicas=2,
+        pool_ref="pool",
+        task=Process(command=["echo", "hi"]),
+        expire_time=expire,
+    )
+    assert body["apiVersion"] == "sandbox.opensandbox.io/v1alpha1"
+    assert body["metadata"] == {"name": "run-1", "namespace": "agents"}
+    assert body["spec"] == {
+        "replicas": 2,
+        "poolRef": "pool",
+        "taskTemplate": {"spec": {"process": {"command": ["echo", "hi"]}}},
+        "expireTime": "2026-01-01T00:00:00Z",
+    }
+
+
+def test_manifest_requires_pool_or_template() -> None:
+    with pytest.raises(ValueError):
+        batch_sandbox_manifest("run-1", namespace="agents")
+    with pytest.raises(ValueError):
+        batch_sandbox_manifest(
+            "run-1", namespace="agents", pool_ref="pool", template={"spec": {}}
+        )
+
+
+def test_manifest_reservation_ref() -> None:
+    body = batch_sandbox_manifest(
+        "run-1", namespace="agents", pool_ref="pool", reservation_ref="ci"
+    )
+    assert body["spec"] == {"replicas": 1, "poolRef": "pool", "reservationRef": "ci"}
+    with pytest.raises(ValueError):
+        batch_sandbox_manifest(
+            "run-1", namespace="agents", template={"spec": {}}, reservation_ref="ci"
+        )
+
+
+def test_pods_of_pooled_batch_sandbox_come_from_allocation() -> None:
+    obj = {
+        "metadata": {
+            "annotations": {
+                "sandbox.opensandbox.io/alloc-status": '{"pods":["pod-a","pod-b"]}'
+            }
+        },
+        "spec": {"poolRef": "pool"},
+    }
+    assert _client([obj]).pods("run-1") == ["pod-a", "pod-b"]
+
+
+def test_pods_of_migrated_batch_sandbox_come_from_allocation_object() -> None:
+    obj = {
+        "metadata": {
+            "annotations": {"sandbox.opensandbox.io/alloc-store": "allocation"}
+        },
+        "spec": {"poolRef": "pool"},
+    }
+    allocation = {"spec": {"poolRef": "pool", "pods": ["pod-a", "pod-c"]}}
+    assert _client([obj, allocation]).pods("run-1") == ["pod-a", "pod-c"]
+
+
+def test_pods_of_templated_batch_sandbox_come_from_labels() -> None:
+    c = _client([{"spec": {"template": {}}}], pods=["run-1-1", "run-1-0"])
+    assert c.pods("run-1") == ["run-1-0", "run-1-1"]
+    assert c._core.selector == "batch-sandbox.sandbox.opensandbox.io/name=run-1"
+
+
+def test_wait_ready_returns_endpoints() -> None:
+    pending = {"spec": {"replicas": 2}, "status": {"ready": 1}}
+    ready = {
+        "metadata": {
+            "annotations": {
+                "sandbox.opensandbox.io/endpoints": '["10.0.0.1","10.0.0.2"]'
+            }
+        },
+        "spec": {"replicas": 2},
+        "status": {"ready": 2, "phase": "Succeed"},
+    }
+    assert _client([pending, ready]).wait_ready("run-1", interval=0) == [
+        "10.0.0.1",
+        "10.0.0.2",
+    ]
+
+
+def test_wait_ready_waits_for_observed_generation() -> None:
+    annotations = {"sandbox.opensandbox.io/endpoints": '["10.0.0.1"]'}
+    stale = {
+        "metadata": {"generation": 2, "annotations": annotations},
+        "spec": {"replicas": 2},
+        "status": {"observedGeneration": 1, "ready": 1, "phase": "Failed"},
+    }
+    with pytest.raises(BatchSandboxNotReadyException, match="not ready"):
+        _client([stale]).wait_ready("run-1", timeout=0, interval=0)
+
+    annotations = {"sandbox.opensandbox.io/endpoints": '["10.0.0.1","10.0.0.2"]'}
+    observed = {
+        "metadata": {"generation": 2, "annotations": annotations},
+        "spec": {"replicas": 2},
+        "status": {"observedGeneration": 2, "ready": 2, "phase": "Succeed"},
+    }
+    assert _client([stale, observed]).wait_ready("run-1", interval=0) == [
+        "10.0.0.1",
+        "10.0.0.2",
+    ]
+
+
+def test_wait_ready_times_out_and_fails_fast() -> None:
+    pending = {"spec": {"replicas": 1}, "status": {"ready": 0}}
+    with pytest.raises(BatchSandboxNotReadyException):
+        _client([pending]).wait_ready("run-1", timeout=0, interval=0)
+
+    failed = {"spec": {"replicas": 1}, "status": {"phase": "Failed"}}
+    with pytest.raises(BatchSandboxNotReadyException, match="failed"):
+        _client([failed]).wait_ready("run-1", timeout=60, interval=0)
@@ -0,0 +1,51 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+from opensandbox_kubernetes import EnvVar, Process, Task
+
+
+def test_task_round_trip_uses_api_field_names() -> None:
+    raw = {
+        "name": "build",
+        "process": {
+            "command": ["make"],
+            "env": [{"name": "CI", "value": "1"}],
+            "workingDir": "/src",
+            "timeoutSeconds": 30,
+        },
+        "processStatus": {
+            "terminated": {
+                "exitCode": 0,
+                "reason": "Succeeded",
+                "startedAt": "2026-01-01T00:00:00Z",
+                "finishedAt": "2026-01-01T00:00:01Z",
+            }
+        },
+    }
+    task = Task.model_validate(raw)
+    assert task.finished
+    assert task.process == Process(
+        command=["make"],
+        env=[EnvVar(name="CI", value="1")],
+        working_dir="/src",
+        timeout_seconds=30,
+    )
+    assert task.to_json_dict() == raw
+
+
+def test_unknown_fields_are_ignored() -> None:
+    task = Task.model_validate({"name": "t", "futureField": True})
+    assert task.to_json_dict() == {"name": "t"}
+    assert not task.finished
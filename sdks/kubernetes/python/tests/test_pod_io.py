@@ -0,0 +1,114 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+import base64
+
+import pytest
+
+from opensandbox_kubernetes import PodCommandException, PodIO
+from opensandbox_kubernetes.pod_io import _lines
+
+
+class FakeExec:
+    """Mimics the WSClient returned by kubernetes.stream with _preload_content=False."""
+
+    def __init__(self, stdout: list[str], returncode: int = 0, stderr: str = "") -> None:
+        self._stdout = stdout
+        self._open = True
+        self.returncode = returncode
+        self.stderr = stderr
+        self.stdin: list[str] = []
+
+    def is_open(self) -> bool:
+        return self._open
+
+    def update(self, timeout: float = 0) -> None:
+        if not self._stdout:
+            self._open = False
+
+    def peek_stdout(self) -> bool:
+        return bool(self._stdout)
+
+    def read_stdout(self) -> str:
+        return self._stdout.pop(0) if self._stdout else ""
+
+    def read_stderr(self) -> str:
+        return self.stderr
+
+    def write_stdin(self, data: str) -> None:
+        self.stdin.append(data)
+
+    def close(self) -> None:
+        self._open = False
+
+
+def _pod_io(resp: FakeExec) -> tuple[PodIO, list[dict]]:
+    calls: list[dict] = []
+
+    def fake_stream(fn, pod, namespace, **kwargs):
+        calls.append({"pod": pod, "namespace": namespace, **kwargs})
+        return resp
+
+    io = PodIO.__new__(PodIO)
+    io.namespace = "agents"
+    io._core = type("Core", (), {"connect_get_namespaced_pod_exec": None})()
+    io._stream = fake_stream
+    return io, calls
+
+
+def test_lines_reassembles_split_chunks() -> None:
+    assert list(_lines(["a\nb", "c\n", "d"])) == ["a", "bc", "d"]
+    # a multi-byte character split across chunks
+    assert list(_lines([b"\xe4\xbd", b"\xa0\n"])) == ["你"]
+
+
+def test_download_decodes_base64() -> None:
+    encoded = base64.b64encode(b"hello\x00world").decode()
+    io, calls = _pod_io(FakeExec([encoded[:5], encoded[5:]]))
+    assert io.download("pod-a", "/tmp/f", container="sandbox") == b"hello\x00world"
+    assert calls[0]["command"] == ["base64", "/tmp/f"]
+    assert calls[0]["container"] == "sandbox"
+
+
+def test_upload_sends_exact_length() -> None:
+    resp = FakeExec([])
+    io, calls = _pod_io(resp)
+    io.upload("pod-a", b"data", "/tmp/f")
+    encoded = base64.b64encode(b"data").decode()
+    assert "".join(resp.stdin) == encoded
+    assert calls[0]["command"][-2:] == [str(len(encoded)), "/tmp/f"]
+    assert calls[0]["stdin"] is True
+    assert "container" not in calls[0]
+
+
+def test_command_failure_raises() -> None:
+    io, _ = _pod_io(FakeExec([], returncode=1, stderr="No such file\n"))
+    with pytest.raises(PodCommandException, match="No such file"):
+        io.download("pod-a", "/missing")
+
+
+def test_stream_task_logs_tails_executor_file() -> None:
+    io, calls = _pod_io(FakeExec(["line 1\nli", "ne 2\n"]))
+    assert list(io.stream_task_logs("pod-a", "build", follow=False)) == [
+        "line 1",
+        "line 2",
+    ]
+    assert calls[0]["container"] == "task-executor"
+    assert calls[0]["command"] == [
+        "tail",
+        "-n",
+        "+1",
+        "/var/lib/sandbox/tasks/build/stdout.log",
+    ]
@@ -0,0 +1,151 @@
+#
+# Copyright 2025 Alibaba Group Holding Ltd.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+import json
+
+import httpx
+import pytest
+
+from opensandbox_kubernetes import (
+    ManagerTaskClient,
+    Process,
+    Task,
+    TaskExecutorClient,
+    TaskExecutorException,
+)
+
+
+def _executor(handler) -> TaskExecutorClient:
+    return TaskExecutorClient("http://executor", transport=httpx.MockTransport(handler))
+
+
+def test_for_endpoint_uses_executor_port() -> None:
+    assert str(TaskExecutorClient.for_endpoint("10.0.0.1")._client.base_url) == (
+        "http://10.0.0.1:5758"
+    )
+    assert str(TaskExecutorClient.for_endpoint("fd00::1")._client.base_url) == (
+        "http://[fd00::1]:5758"
+    )
+
+
+def test_create_task_sends_api_field_names() -> None:
+    seen = {}
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        seen["path"] = request.url.path
+        seen["body"] = json.loads(request.content)
+        return httpx.Response(201, json=seen["body"])
+
+    task = Task(
+        name="build",
+        process=Process(command=["make"], working_dir="/src", timeout_seconds=60),
+    )
+    created = _executor(handler).create_task(task)
+
+    assert seen["path"] == "/tasks"
+    assert seen["body"] == {
+        "name": "build",
+        "process": {"command": ["make"], "workingDir": "/src", "timeoutSeconds": 60},
+    }
+    assert created.process is not None
+    assert created.process.working_dir == "/src"
+
+
+def test_get_task_returns_none_when_missing() -> None:
+    def handler(request: httpx.Request) -> httpx.Response:
+        return httpx.Response(404, json={"code": "Not Found", "message": "task not found"})
+
+    assert _executor(handler).get_task("missing") is None
+
+
+def test_errors_carry_status_and_message() -> None:
+    def handler(request: httpx.Request) -> httpx.Response:
+        return httpx.Response(
+            500, json={"code": "Internal Server Error", "message": "disk full"}
+        )
+
+    with pytest.raises(TaskExecutorException) as exc:
+        _executor(handler).list_tasks()
+    assert exc.value.status_code == 500
+    assert "disk full" in str(exc.value)
+
+
+def test_wait_task_polls_until_terminated() -> None:
+    responses = [
+        {"name": "t", "processStatus": {"running": {"startedAt": "2026-01-01T00:00:00Z"}}},
+        {
+            "name": "t",
+            "processStatus": {
+                "terminated": {
+                    "exitCode": 2,
+                    "startedAt": "2026-01-01T00:00:00Z",
+                    "finishedAt": "2026-01-01T00:00:05Z",
+                }
+            },
+        },
+    ]
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        return httpx.Response(200, json=responses.pop(0))
+
+    task = _executor(handler).wait_task("t", interval=0)
+    assert task.process_status is not None
+    assert task.process_status.terminated is not None
+    assert task.process_status.terminated.exit_code == 2
+
+
+def test_sync_tasks_posts_list() -> None:
+    def handler(request: httpx.Request) -> httpx.Response:
+        assert request.url.path == "/setTasks"
+        return httpx.Response(200, json=json.loads(request.content))
+
+    synced = _executor(handler).sync_tasks([Task(name="a"), Task(name="b")])
+    assert [t.name for t in synced] == ["a", "b"]
+
+
+def test_manager_dispatch() -> None:
+    def handler(request: httpx.Request) -> httpx.Response:
+        assert request.headers["Authorization"] == "Bearer token"
+        assert request.url.path == "/v1/namespaces/default/batchsandboxes/sbx/tasks"
+        assert json.loads(request.content) == {"tasks": [{"name": "t0"}, None]}
+        return httpx.Response(
+            200,
+            json={
+                "pods": [
+                    {"index": 0, "pod": "pod-a", "task": {"name": "t0"}},
+                    {"index": 1, "pod": "pod-b", "error": "connection refused"},
+                ]
+            },
+        )
+
+    client = ManagerTaskClient(
+        "http://manager", "token", transport=httpx.MockTransport(handler)
+    )
+    results = client.dispatch("default", "sbx", [Task(name="t0"), None])
+    assert results[0].task is not None and results[0].task.name == "t0"
+    assert results[1].error == "connection refused"
+
+
+def test_manager_errors_use_error_field() -> None:
+    def handler(request: httpx.Request) -> httpx.Response:
+        return httpx.Response(403, json={"error": "forbidden"})
+
+    client = ManagerTaskClient(
+        "http://manager", "token", transport=httpx.MockTransport(handler)
+    )
+    with pytest.raises(TaskExecutorException) as exc:
+        client.sync("default", "sbx")
+    assert exc.value.status_code == 403
+    assert "forbidden" in str(exc.value)